@@ -0,0 +1,154 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	"github.com/aws/smithy-go/middleware"
+)
+
+// The defaults of the adaptive section of prefetch.
+const (
+	defaultAdaptiveMinDownloads  = 1
+	defaultAdaptiveMaxDownloads  = 32
+	defaultAdaptiveTargetLatency = 500 * time.Millisecond
+)
+
+// downloadController adapts the number of objects retrieved at the same time:
+// the limit is raised by one once as many retrievals as the limit have completed
+// on time, halved when a retrieval is throttled or slower than the target
+// latency, and lowered by one when the retrieved objects wait for the consumer.
+// The retrievals started before the limit was lowered do not lower it again, so
+// that a burst of slow retrievals only halves it once.
+type downloadController struct {
+	minDownloads  int
+	maxDownloads  int
+	targetLatency time.Duration
+
+	mu   sync.Mutex
+	cond *sync.Cond
+	// limit is the number of retrievals allowed at the same time, active the
+	// number of retrievals running.
+	limit  int
+	active int
+	// onTime is the number of retrievals completed on time since the limit last
+	// changed.
+	onTime int
+	// generation is incremented each time the limit is lowered.
+	generation int
+}
+
+// downloadSample is the outcome of a retrieval reported to the controller.
+type downloadSample struct {
+	// latency is the time until the response to the retrieval was received.
+	latency time.Duration
+	// throttled is set when S3 throttled an attempt of the retrieval.
+	throttled bool
+	// backpressured is set when the retrieved objects wait for the consumer.
+	backpressured bool
+}
+
+func newDownloadController(cfg *S3AdaptiveDownloadsConfig, downloads int) *downloadController {
+	if cfg == nil {
+		return nil
+	}
+	c := &downloadController{minDownloads: cfg.MinDownloads, maxDownloads: cfg.MaxDownloads, targetLatency: cfg.TargetLatency}
+	if c.minDownloads == 0 {
+		c.minDownloads = defaultAdaptiveMinDownloads
+	}
+	if c.maxDownloads == 0 {
+		c.maxDownloads = max(defaultAdaptiveMaxDownloads, c.minDownloads)
+	}
+	if c.targetLatency == 0 {
+		c.targetLatency = defaultAdaptiveTargetLatency
+	}
+	c.limit = min(max(downloads, c.minDownloads), c.maxDownloads)
+	c.cond = sync.NewCond(&c.mu)
+	return c
+}
+
+// acquire waits for a retrieval to be allowed, returning the generation of the
+// limit it is allowed by, or false once ctx is done.
+func (c *downloadController) acquire(ctx context.Context) (int, bool) {
+	stop := context.AfterFunc(ctx, func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		c.cond.Broadcast()
+	})
+	defer stop()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for c.active >= c.limit {
+		if ctx.Err() != nil {
+			return 0, false
+		}
+		c.cond.Wait()
+	}
+	if ctx.Err() != nil {
+		return 0, false
+	}
+	c.active++
+	return c.generation, true
+}
+
+// release ends a retrieval allowed by the given generation of the limit, and
+// adapts the limit to its outcome, if any.
+func (c *downloadController) release(generation int, sample *downloadSample) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.active--
+	defer c.cond.Broadcast()
+	if sample == nil {
+		return
+	}
+	switch {
+	case sample.throttled || sample.latency > c.targetLatency:
+		c.lower(generation, c.limit/2)
+	case sample.backpressured:
+		c.lower(generation, c.limit-1)
+	default:
+		c.onTime++
+		if c.onTime >= c.limit && c.limit < c.maxDownloads {
+			c.limit++
+			c.onTime = 0
+		}
+	}
+}
+
+func (c *downloadController) lower(generation int, limit int) {
+	if generation != c.generation {
+		return
+	}
+	c.limit = max(limit, c.minDownloads)
+	c.onTime = 0
+	c.generation++
+}
+
+// downloads returns the number of retrievals allowed at the same time.
+func (c *downloadController) downloads() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.limit
+}
+
+// isThrottled reports whether S3 throttled an attempt of the request whose result
+// metadata is given, the request having then been retried.
+func isThrottled(metadata middleware.Metadata) bool {
+	results, ok := retry.GetAttemptResults(metadata)
+	if !ok {
+		return false
+	}
+	throttles := retry.IsErrorThrottles(retry.DefaultThrottles)
+	for _, result := range results.Results {
+		if result.Err != nil && throttles.IsErrorThrottle(result.Err) == aws.TrueTernary {
+			return true
+		}
+	}
+	return false
+}
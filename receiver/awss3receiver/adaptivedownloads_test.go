@@ -0,0 +1,137 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_newDownloadController(t *testing.T) {
+	require.Nil(t, newDownloadController(nil, 4))
+	c := newDownloadController(&S3AdaptiveDownloadsConfig{}, 4)
+	require.Equal(t, defaultAdaptiveMinDownloads, c.minDownloads)
+	require.Equal(t, defaultAdaptiveMaxDownloads, c.maxDownloads)
+	require.Equal(t, defaultAdaptiveTargetLatency, c.targetLatency)
+	require.Equal(t, 4, c.downloads())
+	// The downloads start within the bounds.
+	require.Equal(t, 8, newDownloadController(&S3AdaptiveDownloadsConfig{MaxDownloads: 8}, 16).downloads())
+	require.Equal(t, 2, newDownloadController(&S3AdaptiveDownloadsConfig{MinDownloads: 2}, 1).downloads())
+}
+
+func Test_downloadController_release(t *testing.T) {
+	c := newDownloadController(&S3AdaptiveDownloadsConfig{MinDownloads: 2, MaxDownloads: 10, TargetLatency: time.Second}, 4)
+	onTime := &downloadSample{latency: 100 * time.Millisecond}
+	sample := func(s *downloadSample) {
+		generation, ok := c.acquire(context.Background())
+		require.True(t, ok)
+		c.release(generation, s)
+	}
+
+	// The limit is raised once as many retrievals as the limit completed on time.
+	for i := 0; i < 4; i++ {
+		sample(onTime)
+	}
+	require.Equal(t, 5, c.downloads())
+	sample(nil)
+	require.Equal(t, 5, c.downloads())
+
+	// The slow and throttled retrievals halve the limit.
+	sample(&downloadSample{latency: 2 * time.Second})
+	require.Equal(t, 2, c.downloads())
+	for i := 0; i < 5; i++ {
+		sample(onTime)
+	}
+	require.Equal(t, 4, c.downloads())
+	sample(&downloadSample{latency: onTime.latency, throttled: true})
+	require.Equal(t, 2, c.downloads())
+	sample(&downloadSample{latency: onTime.latency, backpressured: true})
+	require.Equal(t, 2, c.downloads())
+
+	for i := 0; i < 2; i++ {
+		sample(onTime)
+	}
+	require.Equal(t, 3, c.downloads())
+	sample(&downloadSample{latency: onTime.latency, backpressured: true})
+	require.Equal(t, 2, c.downloads())
+
+	// The retrievals started before the limit was lowered do not lower it again.
+	for i := 0; i < 5; i++ {
+		sample(onTime)
+	}
+	require.Equal(t, 4, c.downloads())
+	first, _ := c.acquire(context.Background())
+	second, _ := c.acquire(context.Background())
+	c.release(first, &downloadSample{latency: 2 * time.Second})
+	c.release(second, &downloadSample{latency: 2 * time.Second})
+	require.Equal(t, 2, c.downloads())
+}
+
+func Test_downloadController_acquire(t *testing.T) {
+	c := newDownloadController(&S3AdaptiveDownloadsConfig{MaxDownloads: 1}, 1)
+	generation, ok := c.acquire(context.Background())
+	require.True(t, ok)
+
+	acquired := make(chan struct{})
+	go func() {
+		defer close(acquired)
+		_, ok := c.acquire(context.Background())
+		require.True(t, ok)
+	}()
+	select {
+	case <-acquired:
+		t.Fatal("more retrievals allowed than the limit")
+	case <-time.After(20 * time.Millisecond):
+	}
+	c.release(generation, nil)
+	<-acquired
+
+	// The retrievals waiting for the limit stop once their context is done.
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(10*time.Millisecond, cancel)
+	_, ok = c.acquire(ctx)
+	require.False(t, ok)
+}
+
+func Test_isThrottled(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		// The first attempt is throttled.
+		if requests.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = w.Write([]byte("<Error><Code>SlowDown</Code></Error>"))
+			return
+		}
+		_, _ = w.Write([]byte("object"))
+	}))
+	defer server.Close()
+
+	client := s3.New(s3.Options{
+		Region:       "us-east-1",
+		BaseEndpoint: aws.String(server.URL),
+		UsePathStyle: true,
+		Credentials:  aws.AnonymousCredentials{},
+		Retryer: retry.NewStandard(func(o *retry.StandardOptions) {
+			o.Backoff = retry.BackoffDelayerFunc(func(int, error) (time.Duration, error) {
+				return 0, nil
+			})
+		}),
+	})
+	params := &s3.GetObjectInput{Bucket: aws.String("bucket"), Key: aws.String("key")}
+	_, info, err := getObject(context.Background(), client, params)
+	require.NoError(t, err)
+	require.True(t, info.throttled)
+	_, info, err = getObject(context.Background(), client, params)
+	require.NoError(t, err)
+	require.False(t, info.throttled)
+}
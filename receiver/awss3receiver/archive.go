@@ -0,0 +1,112 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// zipMagic starts the zip archives, with the local header of their first file.
+var zipMagic = []byte("PK\x03\x04")
+
+// tarMagic is the magic of the ustar headers, at tarMagicOffset in the header of
+// the first file of tar archives.
+var tarMagic = []byte("ustar")
+
+const tarMagicOffset = 257
+
+// hasArchiveExtension reports whether key, without the extension of its
+// compression if any, is named after an archive.
+func hasArchiveExtension(key string) bool {
+	for _, c := range codecs {
+		key = c.trimExtension(key)
+	}
+	return strings.HasSuffix(key, ".tar") || strings.HasSuffix(key, ".tgz") || strings.HasSuffix(key, ".zip")
+}
+
+// archiveMember is a regular file of an archive.
+type archiveMember struct {
+	name string
+	data []byte
+}
+
+// archiveMembers returns the regular files of the uncompressed contents of an
+// object, in their order in the archive, if the object is a tar archive, named
+// .tar or .tgz or holding ustar headers, or a zip archive, named .zip or starting
+// with a zip file header. It reports whether the object is an archive.
+func archiveMembers(key string, data []byte) ([]archiveMember, bool, error) {
+	switch {
+	case strings.HasSuffix(key, ".tar"), strings.HasSuffix(key, ".tgz"),
+		len(data) >= tarMagicOffset+len(tarMagic) && bytes.Equal(data[tarMagicOffset:tarMagicOffset+len(tarMagic)], tarMagic):
+		members, err := tarMembers(data)
+		if err != nil {
+			return nil, true, fmt.Errorf("unable to read tar archive: %w", err)
+		}
+		return members, true, nil
+	case strings.HasSuffix(key, ".zip"), bytes.HasPrefix(data, zipMagic):
+		members, err := zipMembers(data)
+		if err != nil {
+			return nil, true, fmt.Errorf("unable to read zip archive: %w", err)
+		}
+		return members, true, nil
+	default:
+		return nil, false, nil
+	}
+}
+
+func tarMembers(data []byte) ([]archiveMember, error) {
+	var members []archiveMember
+	reader := tar.NewReader(bytes.NewReader(data))
+	for {
+		header, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			return members, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		contents, err := io.ReadAll(reader)
+		if err != nil {
+			return nil, err
+		}
+		members = append(members, archiveMember{name: header.Name, data: contents})
+	}
+}
+
+func zipMembers(data []byte) ([]archiveMember, error) {
+	reader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, err
+	}
+	members := make([]archiveMember, 0, len(reader.File))
+	for _, file := range reader.File {
+		if !file.Mode().IsRegular() {
+			continue
+		}
+		contents, err := readZipFile(file)
+		if err != nil {
+			return nil, err
+		}
+		members = append(members, archiveMember{name: file.Name, data: contents})
+	}
+	return members, nil
+}
+
+func readZipFile(file *zip.File) ([]byte, error) {
+	reader, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return io.ReadAll(reader)
+}
@@ -0,0 +1,109 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.uber.org/zap"
+)
+
+func tarArchive(t *testing.T, members ...archiveMember) []byte {
+	var buf bytes.Buffer
+	writer := tar.NewWriter(&buf)
+	require.NoError(t, writer.WriteHeader(&tar.Header{Name: "logs/", Typeflag: tar.TypeDir, Mode: 0o755}))
+	for _, member := range members {
+		require.NoError(t, writer.WriteHeader(&tar.Header{Name: member.name, Typeflag: tar.TypeReg, Mode: 0o644, Size: int64(len(member.data))}))
+		_, err := writer.Write(member.data)
+		require.NoError(t, err)
+	}
+	require.NoError(t, writer.Close())
+	return buf.Bytes()
+}
+
+func zipArchive(t *testing.T, members ...archiveMember) []byte {
+	var buf bytes.Buffer
+	writer := zip.NewWriter(&buf)
+	for _, member := range members {
+		file, err := writer.Create(member.name)
+		require.NoError(t, err)
+		_, err = file.Write(member.data)
+		require.NoError(t, err)
+	}
+	require.NoError(t, writer.Close())
+	return buf.Bytes()
+}
+
+func Test_archiveMembers(t *testing.T) {
+	members := []archiveMember{{name: "logs/b.log", data: []byte("b")}, {name: "logs/a.log", data: []byte("a")}}
+	for name, tt := range map[string]struct {
+		key  string
+		data []byte
+	}{
+		"tar":             {key: "backup.tar", data: tarArchive(t, members...)},
+		"tar without ext": {key: "backup", data: tarArchive(t, members...)},
+		"zip":             {key: "backup.zip", data: zipArchive(t, members...)},
+		"zip without ext": {key: "backup", data: zipArchive(t, members...)},
+	} {
+		t.Run(name, func(t *testing.T) {
+			got, isArchive, err := archiveMembers(tt.key, tt.data)
+			require.NoError(t, err)
+			require.True(t, isArchive)
+			require.Equal(t, members, got)
+		})
+	}
+
+	_, isArchive, err := archiveMembers("logs_1.log", []byte("test"))
+	require.NoError(t, err)
+	require.False(t, isArchive)
+
+	_, isArchive, err = archiveMembers("backup.zip", []byte("test"))
+	require.ErrorContains(t, err, "unable to read zip archive: ")
+	require.True(t, isArchive)
+}
+
+func Test_receiveObject_Archive(t *testing.T) {
+	sink := &consumertest.LogsSink{}
+	newProcessor := func(format string) telemetryProcessor {
+		return newLogsProcessor(sink, LogsConfig{SignalConfig: SignalConfig{Format: format}}, zap.NewNop())
+	}
+	r := &awss3Receiver{
+		dataProcessor: newProcessor(FormatText),
+		formatRules: []formatRule{
+			{FormatRule: FormatRule{Pattern: "*.jsonl.gz", Format: FormatJSONLines}, processor: newProcessor(FormatJSONLines)},
+		},
+		logger: zap.NewNop(),
+	}
+	archive := gzipCompress(tarArchive(t,
+		archiveMember{name: "logs/app.jsonl.gz", data: gzipCompress([]byte(`{"message":"a"}`))},
+		archiveMember{name: "logs/nested.zip", data: zipArchive(t, archiveMember{name: "web.log", data: []byte("b")})},
+		archiveMember{name: "logs/db.log", data: []byte("c")},
+	))
+	ctx := contextWithObjectInfo(context.Background(), objectInfo{bucket: "bucket", key: "backups/2024/01/01/10.tgz"})
+	require.NoError(t, r.receiveObject(ctx, "backups/2024/01/01/10.tgz", bytes.NewReader(archive)))
+
+	require.Len(t, sink.AllLogs(), 3)
+	record := func(logs plog.Logs) plog.LogRecord {
+		return logs.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0)
+	}
+	require.Equal(t, map[string]any{"message": "a"}, record(sink.AllLogs()[0]).Body().AsRaw())
+	require.Equal(t, map[string]any{
+		"aws.s3.bucket":         "bucket",
+		"aws.s3.key":            "backups/2024/01/01/10.tgz",
+		"aws.s3.archive.member": "logs/app.jsonl.gz",
+	}, record(sink.AllLogs()[0]).Attributes().AsRaw())
+	require.Equal(t, "b", record(sink.AllLogs()[1]).Body().Str())
+	member, _ := record(sink.AllLogs()[1]).Attributes().Get("aws.s3.archive.member")
+	require.Equal(t, "logs/nested.zip/web.log", member.Str())
+	require.Equal(t, "c", record(sink.AllLogs()[2]).Body().Str())
+
+	require.ErrorContains(t, r.receiveObject(ctx, "backups/2024/01/01/11.tar", bytes.NewReader([]byte("not a tar archive"))), "unable to read tar archive: ")
+}
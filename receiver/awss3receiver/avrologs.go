@@ -0,0 +1,181 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"slices"
+	"time"
+
+	"github.com/linkedin/goavro/v2"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.uber.org/zap"
+)
+
+// avroPrimitiveTypes are the names of the Avro primitive types.
+var avroPrimitiveTypes = []string{"null", "boolean", "int", "long", "float", "double", "bytes", "string"}
+
+// avroDecoder decodes Avro object container files, such as the ones written by the
+// Kafka Connect S3 sinks, into a log record per Avro record.
+type avroDecoder struct {
+	cfg        AvroConfig
+	timestamps timestampParser
+	logger     *zap.Logger
+}
+
+// newAvroDecoder returns the decoder of the avro objects, whose timestamp layout
+// has been validated.
+func newAvroDecoder(cfg AvroConfig, logger *zap.Logger) *avroDecoder {
+	timestamps, _ := cfg.parser()
+	return &avroDecoder{cfg: cfg, timestamps: timestamps, logger: logger}
+}
+
+func (d *avroDecoder) decodeLogs(info objectInfo, data []byte) (plog.Logs, error) {
+	logs, records := newObjectLogs()
+	reader, err := goavro.NewOCFReader(bytes.NewReader(data))
+	if err != nil {
+		return logs, fmt.Errorf("unable to read Avro container file: %w", err)
+	}
+	typeNames := avroTypeNames(reader.Codec().Schema())
+	for reader.Scan() {
+		native, err := reader.Read()
+		if err != nil {
+			return logs, fmt.Errorf("unable to read Avro record: %w", err)
+		}
+		fields, ok := avroRawValue(native, typeNames).(map[string]any)
+		if !ok {
+			fields = map[string]any{"value": avroRawValue(native, typeNames)}
+		}
+		record := appendObjectRecord(records, info)
+		if err := d.setRecord(record, fields); err != nil {
+			d.logger.Warn("Unable to parse the timestamp of an Avro record", zap.String("key", info.key), zap.Error(err))
+		}
+	}
+	return logs, reader.Err()
+}
+
+// setRecord sets the body, attributes and timestamp of a record from the fields
+// of an Avro record.
+func (d *avroDecoder) setRecord(record plog.LogRecord, fields map[string]any) error {
+	var timestampErr error
+	if value, ok := fields[d.cfg.TimestampField]; ok && d.cfg.TimestampField != "" {
+		timestamp, isTime := value.(time.Time)
+		if !isTime {
+			timestamp, timestampErr = d.timestamps.parse(value)
+		}
+		if timestampErr == nil {
+			record.SetTimestamp(pcommon.NewTimestampFromTime(timestamp))
+		}
+	}
+	if d.cfg.BodyField == "" {
+		_ = record.Body().FromRaw(avroPdataValue(fields))
+	} else if value, ok := fields[d.cfg.BodyField]; ok {
+		_ = record.Body().FromRaw(avroPdataValue(value))
+	}
+	for name, value := range fields {
+		if value != nil && d.isAttribute(name) {
+			_ = record.Attributes().PutEmpty(name).FromRaw(avroPdataValue(value))
+		}
+	}
+	return timestampErr
+}
+
+func (d *avroDecoder) isAttribute(field string) bool {
+	if len(d.cfg.AttributeFields) > 0 {
+		return slices.Contains(d.cfg.AttributeFields, field)
+	}
+	return d.cfg.BodyField != "" && field != d.cfg.BodyField && field != d.cfg.TimestampField
+}
+
+// avroTypeNames returns the names of the primitive types and of the named types of
+// a schema, the keys of the values of unions.
+func avroTypeNames(schema string) map[string]bool {
+	names := map[string]bool{}
+	for _, name := range avroPrimitiveTypes {
+		names[name] = true
+	}
+	var definition any
+	if err := json.Unmarshal([]byte(schema), &definition); err != nil {
+		return names
+	}
+	var walk func(definition any, namespace string)
+	walk = func(definition any, namespace string) {
+		switch d := definition.(type) {
+		case []any:
+			for _, item := range d {
+				walk(item, namespace)
+			}
+		case map[string]any:
+			if ns, ok := d["namespace"].(string); ok {
+				namespace = ns
+			}
+			if name, ok := d["name"].(string); ok && slices.Contains([]any{"record", "enum", "fixed"}, d["type"]) {
+				names[name] = true
+				if namespace != "" {
+					names[namespace+"."+name] = true
+				}
+			}
+			for _, key := range []string{"type", "fields", "items", "values"} {
+				walk(d[key], namespace)
+			}
+		}
+	}
+	walk(definition, "")
+	return names
+}
+
+// avroRawValue returns a native value of goavro with the unions, decoded as maps of
+// their type name to their value, replaced by their value.
+func avroRawValue(value any, typeNames map[string]bool) any {
+	switch v := value.(type) {
+	case map[string]any:
+		if len(v) == 1 {
+			for name, item := range v {
+				if typeNames[name] {
+					return avroRawValue(item, typeNames)
+				}
+			}
+		}
+		for name, item := range v {
+			v[name] = avroRawValue(item, typeNames)
+		}
+	case []any:
+		for i, item := range v {
+			v[i] = avroRawValue(item, typeNames)
+		}
+	}
+	return value
+}
+
+// avroPdataValue converts the values of the logical types, which FromRaw does not
+// support, the times to RFC 3339 strings, the durations to milliseconds and the
+// decimals to strings.
+func avroPdataValue(value any) any {
+	switch v := value.(type) {
+	case time.Time:
+		return v.UTC().Format(time.RFC3339Nano)
+	case time.Duration:
+		return v.Milliseconds()
+	case *big.Rat:
+		return v.FloatString(10)
+	case map[string]any:
+		converted := make(map[string]any, len(v))
+		for name, item := range v {
+			converted[name] = avroPdataValue(item)
+		}
+		return converted
+	case []any:
+		converted := make([]any, len(v))
+		for i, item := range v {
+			converted[i] = avroPdataValue(item)
+		}
+		return converted
+	default:
+		return value
+	}
+}
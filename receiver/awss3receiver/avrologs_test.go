@@ -0,0 +1,72 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/linkedin/goavro/v2"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+const testAvroSchema = `{
+  "type": "record",
+  "name": "Event",
+  "namespace": "com.example",
+  "fields": [
+    {"name": "time", "type": {"type": "long", "logicalType": "timestamp-millis"}},
+    {"name": "user", "type": ["null", "string"]},
+    {"name": "message", "type": "string"},
+    {"name": "retries", "type": "int"}
+  ]
+}`
+
+func Test_avroDecoder(t *testing.T) {
+	var buf bytes.Buffer
+	writer, err := goavro.NewOCFWriter(goavro.OCFConfig{W: &buf, Schema: testAvroSchema})
+	require.NoError(t, err)
+	require.NoError(t, writer.Append([]map[string]any{
+		{"time": time.Date(2021, 2, 1, 17, 0, 0, 0, time.UTC), "user": goavro.Union("string", "alice"), "message": "login", "retries": int32(0)},
+		{"time": time.Date(2021, 2, 1, 17, 5, 0, 0, time.UTC), "user": nil, "message": "logout", "retries": int32(2)},
+	}))
+	info := objectInfo{bucket: "bucket", key: "topics/events/partition=0/events+0+0000000000.avro"}
+
+	decoder := newAvroDecoder(AvroConfig{BodyField: "message", TimestampField: "time"}, zap.NewNop())
+	logs, err := decoder.decodeLogs(info, buf.Bytes())
+	require.NoError(t, err)
+	records := logs.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords()
+	require.Equal(t, 2, records.Len())
+	require.Equal(t, "login", records.At(0).Body().Str())
+	require.Equal(t, time.Date(2021, 2, 1, 17, 0, 0, 0, time.UTC), records.At(0).Timestamp().AsTime())
+	require.Equal(t, map[string]any{
+		"aws.s3.bucket": "bucket",
+		"aws.s3.key":    info.key,
+		"user":          "alice",
+		"retries":       int64(0),
+	}, records.At(0).Attributes().AsRaw())
+	require.Equal(t, map[string]any{
+		"aws.s3.bucket": "bucket",
+		"aws.s3.key":    info.key,
+		"retries":       int64(2),
+	}, records.At(1).Attributes().AsRaw())
+
+	decoder = newAvroDecoder(AvroConfig{AttributeFields: []string{"user"}}, zap.NewNop())
+	logs, err = decoder.decodeLogs(info, buf.Bytes())
+	require.NoError(t, err)
+	record := logs.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0)
+	require.Equal(t, map[string]any{
+		"time":    "2021-02-01T17:00:00Z",
+		"user":    "alice",
+		"message": "login",
+		"retries": int64(0),
+	}, record.Body().Map().AsRaw())
+	require.Equal(t, "alice", record.Attributes().AsRaw()["user"])
+	require.Zero(t, record.Timestamp())
+
+	_, err = decoder.decodeLogs(info, []byte("not avro"))
+	require.ErrorContains(t, err, "unable to read Avro container file")
+}
@@ -0,0 +1,187 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	"go.opentelemetry.io/collector/component"
+	"go.uber.org/zap"
+)
+
+// awsConfigExtension is implemented by the extensions sharing an AWS SDK
+// configuration, built and cached once for the components referencing them.
+type awsConfigExtension interface {
+	AWSConfig(ctx context.Context) (aws.Config, error)
+}
+
+// extensionConfig is the AWS configuration of an extension. The AWS clients are
+// created along with the receiver, before the extensions are started, so that
+// they are given the credentials, the HTTP client and the retryer of
+// extensionConfig, which use the ones of the configuration of the extension
+// once the receiver has been started.
+type extensionConfig struct {
+	id component.ID
+	// region is the region of the requests to S3, which the region of the
+	// extension does not override.
+	region string
+	logger *zap.Logger
+
+	mu         sync.RWMutex
+	provider   aws.CredentialsProvider
+	httpClient aws.HTTPClient
+	retryer    aws.RetryerV2
+}
+
+func newExtensionConfig(id component.ID, region string, logger *zap.Logger) *extensionConfig {
+	return &extensionConfig{
+		id:         id,
+		region:     region,
+		logger:     logger,
+		httpClient: awshttp.NewBuildableClient(),
+		retryer:    retry.NewStandard(),
+	}
+}
+
+// start retrieves the AWS configuration of the extension.
+func (c *extensionConfig) start(ctx context.Context, host component.Host) error {
+	extension, ok := host.GetExtensions()[c.id]
+	if !ok {
+		return fmt.Errorf("unknown auth extension %q", c.id)
+	}
+	configExtension, ok := extension.(awsConfigExtension)
+	if !ok {
+		return fmt.Errorf("extension %q does not provide an AWS configuration", c.id)
+	}
+	awsCfg, err := configExtension.AWSConfig(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to get the AWS configuration of the auth extension %q: %w", c.id, err)
+	}
+	if awsCfg.Credentials == nil {
+		return fmt.Errorf("the AWS configuration of the auth extension %q has no credentials", c.id)
+	}
+	if awsCfg.Region != "" && awsCfg.Region != c.region {
+		c.logger.Warn("The requests to S3 are sent to the region of s3downloader rather than to the one of the auth extension",
+			zap.String("region", c.region), zap.String("extension_region", awsCfg.Region))
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.provider = awsCfg.Credentials
+	if awsCfg.HTTPClient != nil {
+		c.httpClient = awsCfg.HTTPClient
+	}
+	if awsCfg.Retryer != nil {
+		c.retryer = retryerV2(awsCfg.Retryer())
+	}
+	return nil
+}
+
+// apply makes the clients of awsCfg use the configuration of the extension, the
+// HTTP client only if s3downloader does not set its own.
+func (c *extensionConfig) apply(awsCfg *aws.Config, httpClient bool) {
+	awsCfg.Credentials = c
+	if !httpClient {
+		awsCfg.HTTPClient = extensionHTTPClient{config: c}
+	}
+	awsCfg.Retryer = func() aws.Retryer {
+		return extensionRetryer{config: c}
+	}
+}
+
+// Invalidate discards the cached credentials of the extension, if any, once S3
+// has rejected them.
+func (c *extensionConfig) Invalidate() {
+	c.mu.RLock()
+	provider := c.provider
+	c.mu.RUnlock()
+	if invalidator, ok := provider.(credentialsInvalidator); ok {
+		invalidator.Invalidate()
+	}
+}
+
+// Retrieve implements aws.CredentialsProvider.
+func (c *extensionConfig) Retrieve(ctx context.Context) (aws.Credentials, error) {
+	c.mu.RLock()
+	provider := c.provider
+	c.mu.RUnlock()
+	if provider == nil {
+		return aws.Credentials{}, errors.New("the auth extension has not been started")
+	}
+	return provider.Retrieve(ctx)
+}
+
+func (c *extensionConfig) currentHTTPClient() aws.HTTPClient {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.httpClient
+}
+
+func (c *extensionConfig) currentRetryer() aws.RetryerV2 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.retryer
+}
+
+// retryerV2 returns retryer, with the attempt tokens of its initial token if it
+// does not implement aws.RetryerV2, as the AWS SDK does.
+func retryerV2(retryer aws.Retryer) aws.RetryerV2 {
+	if v2, ok := retryer.(aws.RetryerV2); ok {
+		return v2
+	}
+	return initialTokenRetryer{Retryer: retryer}
+}
+
+type initialTokenRetryer struct {
+	aws.Retryer
+}
+
+func (r initialTokenRetryer) GetAttemptToken(context.Context) (func(error) error, error) {
+	return r.GetInitialToken(), nil
+}
+
+// extensionHTTPClient sends the requests with the HTTP client of the extension.
+type extensionHTTPClient struct {
+	config *extensionConfig
+}
+
+func (c extensionHTTPClient) Do(req *http.Request) (*http.Response, error) {
+	return c.config.currentHTTPClient().Do(req)
+}
+
+// extensionRetryer retries the requests with the retryer of the extension.
+type extensionRetryer struct {
+	config *extensionConfig
+}
+
+func (r extensionRetryer) IsErrorRetryable(err error) bool {
+	return r.config.currentRetryer().IsErrorRetryable(err)
+}
+
+func (r extensionRetryer) MaxAttempts() int {
+	return r.config.currentRetryer().MaxAttempts()
+}
+
+func (r extensionRetryer) RetryDelay(attempt int, opErr error) (time.Duration, error) {
+	return r.config.currentRetryer().RetryDelay(attempt, opErr)
+}
+
+func (r extensionRetryer) GetRetryToken(ctx context.Context, opErr error) (func(error) error, error) {
+	return r.config.currentRetryer().GetRetryToken(ctx, opErr)
+}
+
+func (r extensionRetryer) GetInitialToken() func(error) error {
+	return r.config.currentRetryer().GetInitialToken()
+}
+
+func (r extensionRetryer) GetAttemptToken(ctx context.Context) (func(error) error, error) {
+	return r.config.currentRetryer().GetAttemptToken(ctx)
+}
@@ -0,0 +1,210 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/extension"
+	"go.opentelemetry.io/collector/extension/extensiontest"
+	"go.opentelemetry.io/collector/receiver/receivertest"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+type mockAWSConfigExtension struct {
+	component.StartFunc
+	component.ShutdownFunc
+	awsCfg aws.Config
+}
+
+func (e mockAWSConfigExtension) AWSConfig(context.Context) (aws.Config, error) {
+	return e.awsCfg, nil
+}
+
+// testAWSConfigExtensionConfig is the configuration of the AWS configuration
+// extension of the tests, standing for the ones of custom distributions.
+type testAWSConfigExtensionConfig struct {
+	Region          string `mapstructure:"region"`
+	AccessKeyID     string `mapstructure:"access_key_id"`
+	SecretAccessKey string `mapstructure:"secret_access_key"`
+	MaxAttempts     int    `mapstructure:"max_attempts"`
+}
+
+// testAWSConfigExtension builds its AWS configuration once started, sending the
+// requests with httpClient.
+type testAWSConfigExtension struct {
+	component.ShutdownFunc
+	cfg        *testAWSConfigExtensionConfig
+	httpClient aws.HTTPClient
+
+	awsCfg aws.Config
+}
+
+func newTestAWSConfigExtensionFactory(httpClient aws.HTTPClient) extension.Factory {
+	return extension.NewFactory(component.MustNewType("awsconfig"),
+		func() component.Config {
+			return &testAWSConfigExtensionConfig{}
+		},
+		func(_ context.Context, _ extension.CreateSettings, cfg component.Config) (extension.Extension, error) {
+			return &testAWSConfigExtension{cfg: cfg.(*testAWSConfigExtensionConfig), httpClient: httpClient}, nil
+		},
+		component.StabilityLevelDevelopment)
+}
+
+func (e *testAWSConfigExtension) Start(ctx context.Context, _ component.Host) error {
+	awsCfg, err := config.LoadDefaultConfig(ctx,
+		config.WithRegion(e.cfg.Region),
+		config.WithCredentialsProvider(aws.NewCredentialsCache(credentials.NewStaticCredentialsProvider(e.cfg.AccessKeyID, e.cfg.SecretAccessKey, ""))),
+		config.WithRetryer(func() aws.Retryer {
+			return retry.NewStandard(func(o *retry.StandardOptions) {
+				o.MaxAttempts = e.cfg.MaxAttempts
+			})
+		}))
+	awsCfg.HTTPClient = e.httpClient
+	e.awsCfg = awsCfg
+	return err
+}
+
+func (e *testAWSConfigExtension) AWSConfig(context.Context) (aws.Config, error) {
+	return e.awsCfg, nil
+}
+
+// countingHTTPClient counts the requests it sends.
+type countingHTTPClient struct {
+	client   aws.HTTPClient
+	requests atomic.Int32
+}
+
+func (c *countingHTTPClient) Do(req *http.Request) (*http.Response, error) {
+	c.requests.Add(1)
+	return c.client.Do(req)
+}
+
+func Test_extensionConfig(t *testing.T) {
+	authID := component.MustNewID("awsauth")
+	c := newExtensionConfig(authID, "us-east-1", zap.NewNop())
+	_, err := c.Retrieve(context.Background())
+	require.EqualError(t, err, "the auth extension has not been started")
+
+	host := mockHost{Host: componenttest.NewNopHost()}
+	require.EqualError(t, c.start(context.Background(), host), `unknown auth extension "awsauth"`)
+	host.extensions = map[component.ID]component.Component{authID: struct {
+		component.StartFunc
+		component.ShutdownFunc
+	}{}}
+	require.EqualError(t, c.start(context.Background(), host), `extension "awsauth" does not provide an AWS configuration`)
+	host.extensions = map[component.ID]component.Component{authID: mockAWSConfigExtension{}}
+	require.EqualError(t, c.start(context.Background(), host), `the AWS configuration of the auth extension "awsauth" has no credentials`)
+
+	host.extensions = map[component.ID]component.Component{authID: mockAWSConfigExtension{
+		awsCfg: aws.Config{Credentials: credentials.NewStaticCredentialsProvider("AKID", "SECRET", "")},
+	}}
+	require.NoError(t, c.start(context.Background(), host))
+	value, err := c.Retrieve(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "AKID", value.AccessKeyID)
+}
+
+func Test_awss3Receiver_Auth(t *testing.T) {
+	authID := component.MustNewID("awsauth")
+	cfg := createDefaultConfig().(*Config)
+	cfg.S3Downloader.S3Bucket = "abucket"
+	cfg.S3Downloader.Auth = &authID
+	cfg.StartTime = "2024-01-01"
+	cfg.EndTime = "2024-01-02"
+	r, err := newAWSS3TraceReceiver(context.Background(), cfg, &consumertest.TracesSink{}, receivertest.NewNopCreateSettings())
+	require.NoError(t, err)
+	// The configuration of the receiver is left as is.
+	require.Nil(t, cfg.S3Downloader.authConfig)
+	getObjectClient := r.reader.(*s3Reader).getObjectClient.(*s3.Client)
+	r.reader = mockTelemetryReader(func(ctx context.Context, _ string, _ s3ReaderDataCallback) error {
+		<-ctx.Done()
+		return nil
+	})
+
+	host := mockHost{Host: componenttest.NewNopHost(), extensions: map[component.ID]component.Component{authID: mockAWSConfigExtension{
+		awsCfg: aws.Config{Credentials: credentials.NewStaticCredentialsProvider("AKID", "SECRET", "")},
+	}}}
+	require.NoError(t, r.Start(context.Background(), host))
+	value, err := getObjectClient.Options().Credentials.Retrieve(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "AKID", value.AccessKeyID)
+	require.NoError(t, r.Shutdown(context.Background()))
+}
+
+func Test_awss3Receiver_AuthExtension(t *testing.T) {
+	var mu sync.Mutex
+	var authorizations []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		authorizations = append(authorizations, r.Header.Get("Authorization"))
+		mu.Unlock()
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+	httpClient := &countingHTTPClient{client: awshttp.NewBuildableClient()}
+	factory := newTestAWSConfigExtensionFactory(httpClient)
+	extensionCfg := factory.CreateDefaultConfig().(*testAWSConfigExtensionConfig)
+	extensionCfg.Region = "us-west-2"
+	extensionCfg.AccessKeyID = "EXTENSIONAKID"
+	extensionCfg.SecretAccessKey = "SECRET"
+	extensionCfg.MaxAttempts = 1
+	ext, err := factory.CreateExtension(context.Background(), extensiontest.NewNopCreateSettings(), extensionCfg)
+	require.NoError(t, err)
+	require.NoError(t, ext.Start(context.Background(), componenttest.NewNopHost()))
+	defer func() {
+		require.NoError(t, ext.Shutdown(context.Background()))
+	}()
+
+	authID := component.NewID(factory.Type())
+	cfg := createDefaultConfig().(*Config)
+	cfg.S3Downloader.S3Bucket = "abucket"
+	cfg.S3Downloader.Endpoint = server.URL
+	cfg.S3Downloader.S3ForcePathStyle = true
+	cfg.S3Downloader.Auth = &authID
+	cfg.StartTime = "2024-01-01"
+	cfg.EndTime = "2024-01-02"
+	core, logs := observer.New(zap.WarnLevel)
+	settings := receivertest.NewNopCreateSettings()
+	settings.Logger = zap.New(core)
+	r, err := newAWSS3TraceReceiver(context.Background(), cfg, &consumertest.TracesSink{}, settings)
+	require.NoError(t, err)
+	getObjectClient := r.reader.(*s3Reader).getObjectClient.(*s3.Client)
+	r.reader = mockTelemetryReader(func(ctx context.Context, _ string, _ s3ReaderDataCallback) error {
+		<-ctx.Done()
+		return nil
+	})
+	require.NoError(t, r.Start(context.Background(), mockHost{Host: componenttest.NewNopHost(), extensions: map[component.ID]component.Component{authID: ext}}))
+	defer func() {
+		require.NoError(t, r.Shutdown(context.Background()))
+	}()
+	// The requests keep the region of s3downloader.
+	require.Equal(t, 1, logs.FilterMessage("The requests to S3 are sent to the region of s3downloader rather than to the one of the auth extension").Len())
+
+	// The request is signed with the credentials of the extension, sent with its
+	// HTTP client and not retried by its retryer.
+	_, err = getObjectClient.GetObject(context.Background(), &s3.GetObjectInput{Bucket: aws.String("abucket"), Key: aws.String("key")})
+	require.Error(t, err)
+	require.Equal(t, int32(1), httpClient.requests.Load())
+	require.Len(t, authorizations, 1)
+	require.True(t, strings.Contains(authorizations[0], "Credential=EXTENSIONAKID/"), authorizations[0])
+	require.True(t, strings.Contains(authorizations[0], "/us-east-1/s3/"), authorizations[0])
+}
@@ -0,0 +1,133 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+)
+
+// awsPartitionRegionPrefixes are the prefixes of the regions of the AWS
+// partitions other than aws, the longest first.
+var awsPartitionRegionPrefixes = []struct {
+	prefix    string
+	partition string
+}{
+	{prefix: "us-gov-", partition: "aws-us-gov"},
+	{prefix: "us-isob-", partition: "aws-iso-b"},
+	{prefix: "us-isof-", partition: "aws-iso-f"},
+	{prefix: "eu-isoe-", partition: "aws-iso-e"},
+	{prefix: "us-iso-", partition: "aws-iso"},
+	{prefix: "cn-", partition: "aws-cn"},
+}
+
+// awsRegionPrefixes are the prefixes of the regions of the aws partition.
+var awsRegionPrefixes = []string{"us-", "eu-", "ap-", "ca-", "sa-", "me-", "af-", "il-", "mx-"}
+
+// awsPartitionDefaultRegions are the regions the requests are sent to when no
+// region is set nor can be inferred, by partition. The other partitions have no
+// default region.
+var awsPartitionDefaultRegions = map[string]string{
+	"aws":        "us-east-1",
+	"aws-cn":     "cn-north-1",
+	"aws-us-gov": "us-gov-west-1",
+	"aws-iso":    "us-iso-east-1",
+	"aws-iso-b":  "us-isob-east-1",
+	"aws-iso-e":  "eu-isoe-west-1",
+	"aws-iso-f":  "us-isof-south-1",
+}
+
+// awsPartition returns the partition of region, such as aws-cn for cn-north-1,
+// or false if the partition of region is not known, such as of the partitions
+// newer than the receiver.
+func awsPartition(region string) (string, bool) {
+	if partition := strings.TrimSuffix(region, "-global"); partition != region {
+		if _, ok := awsPartitionDefaultRegions[partition]; ok {
+			return partition, true
+		}
+		return "", false
+	}
+	for _, p := range awsPartitionRegionPrefixes {
+		if strings.HasPrefix(region, p.prefix) {
+			return p.partition, true
+		}
+	}
+	for _, prefix := range awsRegionPrefixes {
+		if strings.HasPrefix(region, prefix) {
+			return "aws", true
+		}
+	}
+	return "", false
+}
+
+// arnSetting is a setting which may hold an ARN.
+type arnSetting struct {
+	name  string
+	value string
+}
+
+// arnSettings returns the settings of cfg which may hold an ARN.
+func arnSettings(cfg S3DownloaderConfig) []arnSetting {
+	settings := []arnSetting{{name: "s3_bucket", value: cfg.S3Bucket}, {name: "role_arn", value: cfg.RoleARN}}
+	if cfg.Credentials != nil {
+		settings = append(settings, arnSetting{name: "credentials web_identity_role_arn", value: cfg.Credentials.WebIdentityRoleARN})
+	}
+	if cfg.ClientSideEncryption != nil {
+		settings = append(settings, arnSetting{name: "client_side_encryption kms_key_id", value: cfg.ClientSideEncryption.KMSKeyID})
+	}
+	return settings
+}
+
+// inferredRegion returns the region of the requests of cfg when no region is
+// set: the region of the bucket ARN, or else the default region of the partition
+// of its ARNs, or of endpoint_partition_id.
+func inferredRegion(cfg S3DownloaderConfig) string {
+	if bucketARN, err := arn.Parse(cfg.S3Bucket); err == nil && bucketARN.Region != "" {
+		return bucketARN.Region
+	}
+	partition := cfg.EndpointPartitionID
+	for _, setting := range arnSettings(cfg) {
+		if parsed, err := arn.Parse(setting.value); err == nil {
+			partition = parsed.Partition
+			break
+		}
+	}
+	return awsPartitionDefaultRegions[partition]
+}
+
+// validatePartitions checks that the ARNs of cfg are all in the same partition,
+// the one of the region if set and known, as the requests cannot cross
+// partitions.
+func validatePartitions(cfg S3DownloaderConfig) error {
+	partition, source := "", ""
+	if regionPartition, ok := awsPartition(cfg.Region); ok {
+		partition, source = regionPartition, "region "+cfg.Region
+	}
+	if cfg.STS != nil {
+		if stsPartition, ok := awsPartition(cfg.STS.Region); ok {
+			if partition != "" && stsPartition != partition {
+				return fmt.Errorf("sts region %s is in the %s partition, but %s is in the %s partition", cfg.STS.Region, stsPartition, source, partition)
+			}
+			if partition == "" {
+				partition, source = stsPartition, "sts region "+cfg.STS.Region
+			}
+		}
+	}
+	for _, setting := range arnSettings(cfg) {
+		parsed, err := arn.Parse(setting.value)
+		if err != nil {
+			continue
+		}
+		if partition == "" {
+			partition, source = parsed.Partition, setting.name
+			continue
+		}
+		if parsed.Partition != partition {
+			return fmt.Errorf("%s is in the %s partition, but %s is in the %s partition", setting.name, parsed.Partition, source, partition)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,162 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"context"
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_awsPartition(t *testing.T) {
+	for region, partition := range map[string]string{
+		"":                   "",
+		"eusc-de-east-1":     "",
+		"us-east-1":          "aws",
+		"ap-southeast-5":     "aws",
+		"aws-global":         "aws",
+		"cn-north-1":         "aws-cn",
+		"cn-northwest-1":     "aws-cn",
+		"aws-cn-global":      "aws-cn",
+		"us-gov-west-1":      "aws-us-gov",
+		"aws-us-gov-global":  "aws-us-gov",
+		"us-iso-east-1":      "aws-iso",
+		"us-isob-east-1":     "aws-iso-b",
+		"eu-isoe-west-1":     "aws-iso-e",
+		"us-isof-south-1":    "aws-iso-f",
+		"unknown-global":     "",
+		"us-east-1-fips":     "aws",
+		"us-gov-east-1-fips": "aws-us-gov",
+	} {
+		got, ok := awsPartition(region)
+		require.Equal(t, partition, got, region)
+		require.Equal(t, partition != "", ok, region)
+	}
+}
+
+func Test_inferredRegion(t *testing.T) {
+	for _, tt := range []struct {
+		cfg    S3DownloaderConfig
+		region string
+	}{
+		{cfg: S3DownloaderConfig{S3Bucket: "bucket"}, region: ""},
+		{cfg: S3DownloaderConfig{S3Bucket: "bucket", EndpointPartitionID: "aws"}, region: "us-east-1"},
+		{cfg: S3DownloaderConfig{S3Bucket: "bucket", EndpointPartitionID: "aws-iso-f"}, region: "us-isof-south-1"},
+		{cfg: S3DownloaderConfig{S3Bucket: "bucket", EndpointPartitionID: "minio"}, region: ""},
+		{cfg: S3DownloaderConfig{S3Bucket: "arn:aws-cn:s3:cn-northwest-1:123456789012:accesspoint/traces", EndpointPartitionID: "aws"}, region: "cn-northwest-1"},
+		// Multi-Region Access Points have no region, but their partition.
+		{cfg: S3DownloaderConfig{S3Bucket: "arn:aws-us-gov:s3::123456789012:accesspoint/mfzwi23gnjvgw.mrap", EndpointPartitionID: "aws"}, region: "us-gov-west-1"},
+		{cfg: S3DownloaderConfig{S3Bucket: "bucket", RoleARN: "arn:aws-iso-b:iam::123456789012:role/reader", EndpointPartitionID: "aws"}, region: "us-isob-east-1"},
+		{
+			cfg: S3DownloaderConfig{
+				S3Bucket:             "bucket",
+				Credentials:          &S3CredentialsConfig{WebIdentityRoleARN: "arn:aws-cn:iam::123456789012:role/reader"},
+				ClientSideEncryption: &S3ClientSideEncryptionConfig{KMSKeyID: "alias/archive"},
+			},
+			region: "cn-north-1",
+		},
+	} {
+		require.Equal(t, tt.region, inferredRegion(tt.cfg), tt.cfg)
+	}
+}
+
+func Test_validatePartitions(t *testing.T) {
+	require.NoError(t, validatePartitions(S3DownloaderConfig{Region: "cn-north-1", S3Bucket: "bucket", RoleARN: "arn:aws-cn:iam::123456789012:role/reader"}))
+	require.NoError(t, validatePartitions(S3DownloaderConfig{
+		S3Bucket:             "arn:aws-us-gov:s3:us-gov-east-1:123456789012:accesspoint/traces",
+		ClientSideEncryption: &S3ClientSideEncryptionConfig{KMSKeyID: "arn:aws-us-gov:kms:us-gov-east-1:123456789012:key/archive"},
+	}))
+	require.EqualError(t, validatePartitions(S3DownloaderConfig{Region: "us-east-1", S3Bucket: "bucket", RoleARN: "arn:aws-cn:iam::123456789012:role/reader"}),
+		"role_arn is in the aws-cn partition, but region us-east-1 is in the aws partition")
+	require.EqualError(t, validatePartitions(S3DownloaderConfig{
+		S3Bucket:    "arn:aws-us-gov:s3:us-gov-west-1:123456789012:accesspoint/traces",
+		Credentials: &S3CredentialsConfig{WebIdentityRoleARN: "arn:aws:iam::123456789012:role/reader"},
+	}), "credentials web_identity_role_arn is in the aws partition, but s3_bucket is in the aws-us-gov partition")
+
+	// The ARNs of the partitions newer than the receiver are checked against each
+	// other only, the partition of their regions not being known.
+	require.NoError(t, validatePartitions(S3DownloaderConfig{
+		Region:   "eusc-de-east-1",
+		S3Bucket: "arn:aws-eusc:s3:eusc-de-east-1:123456789012:accesspoint/traces",
+		RoleARN:  "arn:aws-eusc:iam::123456789012:role/reader",
+		STS:      &S3STSConfig{Region: "eusc-de-east-1"},
+	}))
+	require.EqualError(t, validatePartitions(S3DownloaderConfig{
+		Region:   "eusc-de-east-1",
+		S3Bucket: "arn:aws-eusc:s3:eusc-de-east-1:123456789012:accesspoint/traces",
+		RoleARN:  "arn:aws:iam::123456789012:role/reader",
+	}), "role_arn is in the aws partition, but s3_bucket is in the aws-eusc partition")
+	require.EqualError(t, validatePartitions(S3DownloaderConfig{Region: "us-east-1", S3Bucket: "arn:aws-eusc:s3:::bucket"}),
+		"s3_bucket is in the aws-eusc partition, but region us-east-1 is in the aws partition")
+}
+
+// hostRecorder records the hosts and the signatures of the requests, without
+// sending them.
+type hostRecorder struct {
+	hosts          []string
+	authorizations []string
+}
+
+func (r *hostRecorder) Do(req *http.Request) (*http.Response, error) {
+	r.hosts = append(r.hosts, req.URL.Host)
+	r.authorizations = append(r.authorizations, req.Header.Get("Authorization"))
+	return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: http.NoBody, Request: req}, nil
+}
+
+func Test_newS3Client_Partitions(t *testing.T) {
+	setTestCredentialsEnv(t, nil)
+	t.Setenv("AWS_REGION", "")
+	t.Setenv("AWS_DEFAULT_REGION", "")
+	t.Setenv("AWS_CONFIG_FILE", filepath.Join(t.TempDir(), "config"))
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", filepath.Join(t.TempDir(), "credentials"))
+	credentials := &S3CredentialsConfig{AccessKeyID: "AKID", SecretAccessKey: "secret"}
+	for _, tt := range []struct {
+		name string
+		cfg  S3DownloaderConfig
+		host string
+	}{
+		{
+			name: "china",
+			cfg:  S3DownloaderConfig{Region: "cn-northwest-1", S3Bucket: "bucket"},
+			host: "bucket.s3.cn-northwest-1.amazonaws.com.cn",
+		},
+		{
+			name: "govcloud_fips",
+			cfg:  S3DownloaderConfig{Region: "us-gov-east-1", S3Bucket: "bucket", UseFIPSEndpoint: true},
+			host: "bucket.s3-fips.us-gov-east-1.amazonaws.com",
+		},
+		{
+			name: "china_access_point",
+			cfg:  S3DownloaderConfig{S3Bucket: "arn:aws-cn:s3:cn-north-1:123456789012:accesspoint/traces", EndpointPartitionID: "aws"},
+			host: "traces-123456789012.s3-accesspoint.cn-north-1.amazonaws.com.cn",
+		},
+		{
+			name: "govcloud_access_point",
+			cfg:  S3DownloaderConfig{Region: "us-gov-west-1", S3Bucket: "arn:aws-us-gov:s3:us-gov-east-1:123456789012:accesspoint/traces"},
+			host: "traces-123456789012.s3-accesspoint.us-gov-east-1.amazonaws.com",
+		},
+		{
+			name: "partition",
+			cfg:  S3DownloaderConfig{S3Bucket: "bucket", EndpointPartitionID: "aws-cn"},
+			host: "bucket.s3.cn-north-1.amazonaws.com.cn",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.cfg.Credentials = credentials
+			_, getObjectClient, err := newS3Client(context.Background(), tt.cfg)
+			require.NoError(t, err)
+			recorder := &hostRecorder{}
+			_, err = getObjectClient.GetObject(context.Background(), &s3.GetObjectInput{Bucket: aws.String(tt.cfg.S3Bucket), Key: aws.String("key")}, func(o *s3.Options) {
+				o.HTTPClient = recorder
+			})
+			require.NoError(t, err)
+			require.Equal(t, []string{tt.host}, recorder.hosts)
+		})
+	}
+}
@@ -0,0 +1,125 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/consumer/consumererror"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultBackpressureInitialInterval = time.Second
+	defaultBackpressureMaxInterval     = 30 * time.Second
+)
+
+// backpressure retries the telemetry refused by the next consumer, such as a
+// memory limiter, with an exponential backoff. The objects are read one after the
+// other, so that the receiver stops retrieving objects while the telemetry is
+// retried.
+type backpressure struct {
+	initialInterval time.Duration
+	maxInterval     time.Duration
+	maxElapsedTime  time.Duration
+	// stopped is closed on shutdown, for the telemetry being retried to be left to
+	// the next run.
+	stopped  chan struct{}
+	stopOnce sync.Once
+	logger   *zap.Logger
+}
+
+// newBackpressure returns the backpressure of cfg, or nil if the refused
+// telemetry is not retried.
+func newBackpressure(cfg *BackpressureConfig, logger *zap.Logger) *backpressure {
+	if cfg == nil {
+		return nil
+	}
+	b := &backpressure{
+		initialInterval: cfg.InitialInterval,
+		maxInterval:     cfg.MaxInterval,
+		maxElapsedTime:  cfg.MaxElapsedTime,
+		stopped:         make(chan struct{}),
+		logger:          logger,
+	}
+	if b.initialInterval == 0 {
+		b.initialInterval = defaultBackpressureInitialInterval
+	}
+	if b.maxInterval == 0 {
+		b.maxInterval = max(defaultBackpressureMaxInterval, b.initialInterval)
+	}
+	return b
+}
+
+// consume calls consume until it succeeds, or fails with a permanent error,
+// doubling the time between the calls up to the max interval. The last error is
+// returned once the max elapsed time would be exceeded or ctx is done, and
+// errStopping once the backpressure is stopped.
+func (b *backpressure) consume(ctx context.Context, consume func(context.Context) error) error {
+	err := consume(ctx)
+	if b == nil {
+		return err
+	}
+	start := time.Now()
+	interval := b.initialInterval
+	for err != nil && !consumererror.IsPermanent(err) {
+		if b.maxElapsedTime > 0 && time.Since(start)+interval > b.maxElapsedTime {
+			return err
+		}
+		b.logger.Warn("The next consumer refused the telemetry, retrying", zap.Duration("interval", interval), zap.Error(err))
+		timer := time.NewTimer(interval)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-b.stopped:
+			timer.Stop()
+			return errStopping
+		}
+		interval = min(2*interval, b.maxInterval)
+		err = consume(ctx)
+	}
+	return err
+}
+
+// stop stops retrying the refused telemetry.
+func (b *backpressure) stop() {
+	if b == nil {
+		return
+	}
+	b.stopOnce.Do(func() {
+		close(b.stopped)
+	})
+}
+
+func (b *backpressure) traces(next consumer.Traces) (consumer.Traces, error) {
+	return consumer.NewTraces(func(ctx context.Context, td ptrace.Traces) error {
+		return b.consume(ctx, func(ctx context.Context) error {
+			return next.ConsumeTraces(ctx, td)
+		})
+	}, consumer.WithCapabilities(next.Capabilities()))
+}
+
+func (b *backpressure) logs(next consumer.Logs) (consumer.Logs, error) {
+	return consumer.NewLogs(func(ctx context.Context, ld plog.Logs) error {
+		return b.consume(ctx, func(ctx context.Context) error {
+			return next.ConsumeLogs(ctx, ld)
+		})
+	}, consumer.WithCapabilities(next.Capabilities()))
+}
+
+func (b *backpressure) metrics(next consumer.Metrics) (consumer.Metrics, error) {
+	return consumer.NewMetrics(func(ctx context.Context, md pmetric.Metrics) error {
+		return b.consume(ctx, func(ctx context.Context) error {
+			return next.ConsumeMetrics(ctx, md)
+		})
+	}, consumer.WithCapabilities(next.Capabilities()))
+}
@@ -0,0 +1,101 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/consumer/consumererror"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.uber.org/zap"
+)
+
+var errRefused = errors.New("data refused due to high memory usage")
+
+// refusingConsumer returns a function failing with err the first refusals calls.
+func refusingConsumer(refusals int, err error) (func(context.Context) error, *int) {
+	calls := 0
+	return func(context.Context) error {
+		calls++
+		if calls <= refusals {
+			return err
+		}
+		return nil
+	}, &calls
+}
+
+func Test_newBackpressure(t *testing.T) {
+	require.Nil(t, newBackpressure(nil, zap.NewNop()))
+	b := newBackpressure(&BackpressureConfig{}, zap.NewNop())
+	require.Equal(t, defaultBackpressureInitialInterval, b.initialInterval)
+	require.Equal(t, defaultBackpressureMaxInterval, b.maxInterval)
+	b = newBackpressure(&BackpressureConfig{InitialInterval: time.Minute}, zap.NewNop())
+	require.Equal(t, time.Minute, b.maxInterval)
+}
+
+func Test_backpressure_consume(t *testing.T) {
+	var nilBackpressure *backpressure
+	consume, calls := refusingConsumer(1, errRefused)
+	require.ErrorIs(t, nilBackpressure.consume(context.Background(), consume), errRefused)
+	require.Equal(t, 1, *calls)
+
+	b := newBackpressure(&BackpressureConfig{InitialInterval: time.Millisecond, MaxInterval: 4 * time.Millisecond}, zap.NewNop())
+	consume, calls = refusingConsumer(5, errRefused)
+	require.NoError(t, b.consume(context.Background(), consume))
+	require.Equal(t, 6, *calls)
+
+	// The permanent errors are not retried.
+	consume, calls = refusingConsumer(1, consumererror.NewPermanent(errRefused))
+	require.ErrorIs(t, b.consume(context.Background(), consume), errRefused)
+	require.Equal(t, 1, *calls)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	consume, calls = refusingConsumer(5, errRefused)
+	require.ErrorIs(t, b.consume(ctx, consume), errRefused)
+	require.Equal(t, 1, *calls)
+}
+
+func Test_backpressure_consume_MaxElapsedTime(t *testing.T) {
+	b := newBackpressure(&BackpressureConfig{InitialInterval: 10 * time.Millisecond, MaxElapsedTime: 25 * time.Millisecond}, zap.NewNop())
+	consume, calls := refusingConsumer(5, errRefused)
+	require.ErrorIs(t, b.consume(context.Background(), consume), errRefused)
+	// The retry after 10ms then 20ms would exceed the max elapsed time.
+	require.Equal(t, 2, *calls)
+}
+
+func Test_backpressure_stop(t *testing.T) {
+	b := newBackpressure(&BackpressureConfig{InitialInterval: time.Hour}, zap.NewNop())
+	consume, calls := refusingConsumer(5, errRefused)
+	time.AfterFunc(10*time.Millisecond, b.stop)
+	require.ErrorIs(t, b.consume(context.Background(), consume), errStopping)
+	require.Equal(t, 1, *calls)
+	b.stop()
+}
+
+func Test_backpressure_logs(t *testing.T) {
+	sink := &consumertest.LogsSink{}
+	refusals := 2
+	next, err := consumer.NewLogs(func(ctx context.Context, ld plog.Logs) error {
+		if refusals > 0 {
+			refusals--
+			return errRefused
+		}
+		return sink.ConsumeLogs(ctx, ld)
+	})
+	require.NoError(t, err)
+	b := newBackpressure(&BackpressureConfig{InitialInterval: time.Millisecond}, zap.NewNop())
+	logs, err := b.logs(next)
+	require.NoError(t, err)
+	ld := plog.NewLogs()
+	ld.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords().AppendEmpty().Body().SetStr("line")
+	require.NoError(t, logs.ConsumeLogs(context.Background(), ld))
+	require.Equal(t, 1, sink.LogRecordCount())
+}
@@ -0,0 +1,200 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultBatchMaxSize = 8192
+	defaultBatchTimeout = time.Second
+)
+
+// batcher merges the telemetry decoded from the objects, sending it to the next
+// consumer once it holds max size items, max bytes bytes, or once the timeout has
+// elapsed since the first of them was added. The telemetry of an object is not
+// split, so that a batch may exceed the thresholds.
+type batcher struct {
+	maxSize  int
+	maxBytes int
+	timeout  time.Duration
+	logger   *zap.Logger
+
+	mu sync.Mutex
+	// send sends the batched telemetry to the next consumer, and starts a new batch
+	// once it has been sent.
+	send  func(ctx context.Context) error
+	items int
+	bytes int
+	// timer, if set, sends the batch once the timeout has elapsed.
+	timer *time.Timer
+}
+
+// newBatcher returns the batcher of cfg, or nil if the telemetry of each object
+// is sent on its own.
+func newBatcher(cfg *BatchConfig, logger *zap.Logger) *batcher {
+	if cfg == nil {
+		return nil
+	}
+	b := &batcher{maxSize: cfg.MaxSize, maxBytes: cfg.MaxBytes, timeout: cfg.Timeout, logger: logger}
+	if b.maxSize == 0 {
+		b.maxSize = defaultBatchMaxSize
+	}
+	if b.timeout == 0 {
+		b.timeout = defaultBatchTimeout
+	}
+	return b
+}
+
+// add adds the telemetry of size bytes made of items to the batch by calling
+// move, first sending the batch if the telemetry would make it exceed the max
+// bytes, and then if it holds the max size items. The telemetry without any item
+// is dropped. The telemetry is not added if the batch could not be sent first.
+// Once it is, the batch failing to be sent is kept and sent again along with the
+// next telemetry, once the timeout has elapsed again, or on shutdown.
+func (b *batcher) add(ctx context.Context, items, size int, move func()) error {
+	if items == 0 {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.maxBytes > 0 && b.items > 0 && b.bytes+size > b.maxBytes {
+		if err := b.sendLocked(ctx); err != nil {
+			return err
+		}
+	}
+	move()
+	b.items += items
+	b.bytes += size
+	if b.items >= b.maxSize || (b.maxBytes > 0 && b.bytes >= b.maxBytes) {
+		if err := b.sendLocked(ctx); err != nil {
+			b.logger.Warn("Failed to send the batched telemetry, keeping it to send it again", zap.Error(err))
+			b.startTimerLocked()
+		}
+		return nil
+	}
+	b.startTimerLocked()
+	return nil
+}
+
+// startTimerLocked sends the batch once the timeout has elapsed, unless the timer
+// is already started.
+func (b *batcher) startTimerLocked() {
+	if b.timer != nil {
+		return
+	}
+	var timer *time.Timer
+	timer = time.AfterFunc(b.timeout, func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		// The batch was sent meanwhile, the timer stopped too late.
+		if b.timer != timer {
+			return
+		}
+		b.timer = nil
+		if err := b.sendLocked(context.Background()); err != nil {
+			b.logger.Warn("Failed to send the batched telemetry, keeping it to send it again", zap.Error(err))
+			b.startTimerLocked()
+		}
+	})
+	b.timer = timer
+}
+
+// flush sends the batch, if any.
+func (b *batcher) flush(ctx context.Context) error {
+	if b == nil {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.sendLocked(ctx)
+}
+
+// sendLocked sends the batch, which is only reset once sent.
+func (b *batcher) sendLocked(ctx context.Context) error {
+	if b.timer != nil {
+		b.timer.Stop()
+		b.timer = nil
+	}
+	if b.items == 0 {
+		return nil
+	}
+	if err := b.send(ctx); err != nil {
+		return err
+	}
+	b.items, b.bytes = 0, 0
+	return nil
+}
+
+func (b *batcher) traces(next consumer.Traces) (consumer.Traces, error) {
+	batch := ptrace.NewTraces()
+	sizer := &ptrace.ProtoMarshaler{}
+	b.send = func(ctx context.Context) error {
+		if err := next.ConsumeTraces(ctx, batch); err != nil {
+			return err
+		}
+		batch = ptrace.NewTraces()
+		return nil
+	}
+	return consumer.NewTraces(func(ctx context.Context, td ptrace.Traces) error {
+		size := 0
+		if b.maxBytes > 0 {
+			size = sizer.TracesSize(td)
+		}
+		return b.add(ctx, td.SpanCount(), size, func() {
+			td.ResourceSpans().MoveAndAppendTo(batch.ResourceSpans())
+		})
+	}, consumer.WithCapabilities(consumer.Capabilities{MutatesData: true}))
+}
+
+func (b *batcher) logs(next consumer.Logs) (consumer.Logs, error) {
+	batch := plog.NewLogs()
+	sizer := &plog.ProtoMarshaler{}
+	b.send = func(ctx context.Context) error {
+		if err := next.ConsumeLogs(ctx, batch); err != nil {
+			return err
+		}
+		batch = plog.NewLogs()
+		return nil
+	}
+	return consumer.NewLogs(func(ctx context.Context, ld plog.Logs) error {
+		size := 0
+		if b.maxBytes > 0 {
+			size = sizer.LogsSize(ld)
+		}
+		return b.add(ctx, ld.LogRecordCount(), size, func() {
+			ld.ResourceLogs().MoveAndAppendTo(batch.ResourceLogs())
+		})
+	}, consumer.WithCapabilities(consumer.Capabilities{MutatesData: true}))
+}
+
+func (b *batcher) metrics(next consumer.Metrics) (consumer.Metrics, error) {
+	batch := pmetric.NewMetrics()
+	sizer := &pmetric.ProtoMarshaler{}
+	b.send = func(ctx context.Context) error {
+		if err := next.ConsumeMetrics(ctx, batch); err != nil {
+			return err
+		}
+		batch = pmetric.NewMetrics()
+		return nil
+	}
+	return consumer.NewMetrics(func(ctx context.Context, md pmetric.Metrics) error {
+		size := 0
+		if b.maxBytes > 0 {
+			size = sizer.MetricsSize(md)
+		}
+		return b.add(ctx, md.DataPointCount(), size, func() {
+			md.ResourceMetrics().MoveAndAppendTo(batch.ResourceMetrics())
+		})
+	}, consumer.WithCapabilities(consumer.Capabilities{MutatesData: true}))
+}
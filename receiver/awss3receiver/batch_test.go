@@ -0,0 +1,142 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.uber.org/zap"
+)
+
+func testLogs(bodies ...string) plog.Logs {
+	ld := plog.NewLogs()
+	records := ld.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords()
+	for _, body := range bodies {
+		records.AppendEmpty().Body().SetStr(body)
+	}
+	return ld
+}
+
+func Test_newBatcher(t *testing.T) {
+	require.Nil(t, newBatcher(nil, zap.NewNop()))
+	b := newBatcher(&BatchConfig{}, zap.NewNop())
+	require.Equal(t, defaultBatchMaxSize, b.maxSize)
+	require.Zero(t, b.maxBytes)
+	require.Equal(t, defaultBatchTimeout, b.timeout)
+
+	var nilBatcher *batcher
+	require.NoError(t, nilBatcher.flush(context.Background()))
+}
+
+func Test_batcher_MaxSize(t *testing.T) {
+	sink := &consumertest.LogsSink{}
+	b := newBatcher(&BatchConfig{MaxSize: 3, Timeout: time.Hour}, zap.NewNop())
+	logs, err := b.logs(sink)
+	require.NoError(t, err)
+	for _, body := range []string{"a", "b", "c", "d"} {
+		require.NoError(t, logs.ConsumeLogs(context.Background(), testLogs(body)))
+	}
+	// The telemetry without any record is dropped.
+	require.NoError(t, logs.ConsumeLogs(context.Background(), plog.NewLogs()))
+	require.Len(t, sink.AllLogs(), 1)
+	require.Equal(t, 3, sink.AllLogs()[0].LogRecordCount())
+	require.Equal(t, 3, sink.AllLogs()[0].ResourceLogs().Len())
+
+	require.NoError(t, b.flush(context.Background()))
+	require.Len(t, sink.AllLogs(), 2)
+	require.Equal(t, 1, sink.AllLogs()[1].LogRecordCount())
+	require.NoError(t, b.flush(context.Background()))
+	require.Len(t, sink.AllLogs(), 2)
+}
+
+func Test_batcher_MaxBytes(t *testing.T) {
+	sink := &consumertest.TracesSink{}
+	large := strings.Repeat("a", 100)
+	b := newBatcher(&BatchConfig{MaxBytes: 250, Timeout: time.Hour}, zap.NewNop())
+	traces, err := b.traces(sink)
+	require.NoError(t, err)
+	for i := 0; i < 3; i++ {
+		td := ptrace.NewTraces()
+		td.ResourceSpans().AppendEmpty().ScopeSpans().AppendEmpty().Spans().AppendEmpty().SetName(large)
+		require.NoError(t, traces.ConsumeTraces(context.Background(), td))
+	}
+	// The batch is sent before the third span makes it exceed the max bytes.
+	require.Len(t, sink.AllTraces(), 1)
+	require.Equal(t, 2, sink.AllTraces()[0].SpanCount())
+	require.NoError(t, b.flush(context.Background()))
+	require.Equal(t, 3, sink.SpanCount())
+}
+
+func Test_batcher_Timeout(t *testing.T) {
+	sink := &consumertest.MetricsSink{}
+	b := newBatcher(&BatchConfig{Timeout: 10 * time.Millisecond}, zap.NewNop())
+	metrics, err := b.metrics(sink)
+	require.NoError(t, err)
+	md := pmetric.NewMetrics()
+	md.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics().AppendEmpty().SetEmptyGauge().DataPoints().AppendEmpty().SetIntValue(1)
+	require.NoError(t, metrics.ConsumeMetrics(context.Background(), md))
+	require.Eventually(t, func() bool {
+		return sink.DataPointCount() == 1
+	}, time.Second, 5*time.Millisecond)
+}
+
+func Test_batcher_SendFailure(t *testing.T) {
+	sink := &consumertest.LogsSink{}
+	failing := true
+	next, err := consumer.NewLogs(func(ctx context.Context, ld plog.Logs) error {
+		if failing {
+			return errors.New("refused")
+		}
+		return sink.ConsumeLogs(ctx, ld)
+	})
+	require.NoError(t, err)
+	b := newBatcher(&BatchConfig{MaxSize: 2, MaxBytes: 1 << 20, Timeout: time.Hour}, zap.NewNop())
+	logs, err := b.logs(next)
+	require.NoError(t, err)
+
+	// The batch failing to be sent once full is kept.
+	require.NoError(t, logs.ConsumeLogs(context.Background(), testLogs("a")))
+	require.NoError(t, logs.ConsumeLogs(context.Background(), testLogs("b")))
+	require.ErrorContains(t, b.flush(context.Background()), "refused")
+
+	// The telemetry is not added when the batch cannot be sent first.
+	large := testLogs(strings.Repeat("c", 1<<20))
+	require.ErrorContains(t, logs.ConsumeLogs(context.Background(), large), "refused")
+
+	failing = false
+	require.NoError(t, b.flush(context.Background()))
+	require.Len(t, sink.AllLogs(), 1)
+	require.Equal(t, 2, sink.LogRecordCount())
+}
+
+func Test_batcher_Timeout_SendFailure(t *testing.T) {
+	sink := &consumertest.LogsSink{}
+	var failures atomic.Int32
+	next, err := consumer.NewLogs(func(ctx context.Context, ld plog.Logs) error {
+		if failures.Add(1) <= 2 {
+			return errors.New("refused")
+		}
+		return sink.ConsumeLogs(ctx, ld)
+	})
+	require.NoError(t, err)
+	b := newBatcher(&BatchConfig{Timeout: 10 * time.Millisecond}, zap.NewNop())
+	logs, err := b.logs(next)
+	require.NoError(t, err)
+	require.NoError(t, logs.ConsumeLogs(context.Background(), testLogs("a")))
+	// The batch is sent again once the timeout has elapsed again.
+	require.Eventually(t, func() bool {
+		return sink.LogRecordCount() == 1
+	}, time.Second, 5*time.Millisecond)
+}
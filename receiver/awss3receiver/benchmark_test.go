@@ -0,0 +1,234 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/stretchr/testify/require"
+)
+
+// The environment variables of the benchmarks. With AWSS3RECEIVER_BENCHMARK_ENDPOINT
+// set, such as to http://localhost:9000 for a local MinIO server, the objects
+// are written to and read from the AWSS3RECEIVER_BENCHMARK_BUCKET bucket of that
+// endpoint, with the credentials of the environment, instead of an in-memory fake.
+// AWSS3RECEIVER_BENCHMARK_LATENCY sets the latency of the retrievals from the fake.
+const (
+	benchmarkEndpointEnv = "AWSS3RECEIVER_BENCHMARK_ENDPOINT"
+	benchmarkBucketEnv   = "AWSS3RECEIVER_BENCHMARK_BUCKET"
+	benchmarkLatencyEnv  = "AWSS3RECEIVER_BENCHMARK_LATENCY"
+)
+
+// benchmarkPartitions is the number of minute partitions the objects of a
+// benchmark are spread over.
+const benchmarkPartitions = 4
+
+// fakeS3 is an in-memory S3 bucket, listed by pages in the order of the keys, and
+// whose objects are retrieved after latency.
+type fakeS3 struct {
+	keys    []string
+	objects map[string][]byte
+	latency time.Duration
+}
+
+func newFakeS3(latency time.Duration) *fakeS3 {
+	return &fakeS3{objects: map[string][]byte{}, latency: latency}
+}
+
+func (f *fakeS3) PutObject(_ context.Context, params *s3.PutObjectInput, _ ...func(*s3.Options)) (*s3.PutObjectOutput, error) {
+	data, err := io.ReadAll(params.Body)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := f.objects[*params.Key]; !ok {
+		f.keys = append(f.keys, *params.Key)
+		sort.Strings(f.keys)
+	}
+	f.objects[*params.Key] = data
+	return &s3.PutObjectOutput{}, nil
+}
+
+func (f *fakeS3) ListObjectsV2(_ context.Context, params *s3.ListObjectsV2Input, _ ...func(*s3.Options)) (*s3.ListObjectsV2Output, error) {
+	after := aws.ToString(params.StartAfter)
+	if token := aws.ToString(params.ContinuationToken); token > after {
+		after = token
+	}
+	maxKeys := aws.ToInt32(params.MaxKeys)
+	if maxKeys == 0 {
+		maxKeys = 1000
+	}
+	prefix := aws.ToString(params.Prefix)
+	output := &s3.ListObjectsV2Output{}
+	for i := sort.SearchStrings(f.keys, max(after, prefix)); i < len(f.keys) && strings.HasPrefix(f.keys[i], prefix); i++ {
+		key := f.keys[i]
+		if key == after {
+			continue
+		}
+		if len(output.Contents) == int(maxKeys) {
+			output.IsTruncated = aws.Bool(true)
+			output.NextContinuationToken = output.Contents[len(output.Contents)-1].Key
+			break
+		}
+		output.Contents = append(output.Contents, types.Object{Key: aws.String(key), Size: aws.Int64(int64(len(f.objects[key])))})
+	}
+	return output, nil
+}
+
+func (f *fakeS3) NewListObjectsV2Paginator(params *s3.ListObjectsV2Input) ListObjectsV2Pager {
+	return s3.NewListObjectsV2Paginator(f, params)
+}
+
+func (f *fakeS3) GetObject(ctx context.Context, params *s3.GetObjectInput, _ ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
+	if f.latency > 0 {
+		timer := time.NewTimer(f.latency)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		}
+	}
+	data, ok := f.objects[*params.Key]
+	if !ok {
+		return nil, &types.NoSuchKey{}
+	}
+	return &s3.GetObjectOutput{Body: io.NopCloser(bytes.NewReader(data)), ContentLength: aws.Int64(int64(len(data)))}, nil
+}
+
+// putObjectAPI writes the objects read by the benchmarks.
+type putObjectAPI interface {
+	PutObject(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error)
+}
+
+// benchmarkBucket returns the clients of the bucket of the benchmarks, and the
+// name of the bucket.
+func benchmarkBucket(b *testing.B) (ListObjectsAPI, GetObjectAPI, putObjectAPI, string) {
+	endpoint := os.Getenv(benchmarkEndpointEnv)
+	if endpoint == "" {
+		var latency time.Duration
+		if env := os.Getenv(benchmarkLatencyEnv); env != "" {
+			var err error
+			latency, err = time.ParseDuration(env)
+			require.NoError(b, err)
+		}
+		fake := newFakeS3(latency)
+		return fake, fake, fake, "bucket"
+	}
+	bucket := os.Getenv(benchmarkBucketEnv)
+	if bucket == "" {
+		bucket = "awss3receiver-benchmark"
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// The connections are closed for the leak check of the tests.
+	b.Cleanup(transport.CloseIdleConnections)
+	awsCfg, err := config.LoadDefaultConfig(context.Background(),
+		config.WithRegion(defaultS3CompatibleSigningRegion), config.WithHTTPClient(&http.Client{Transport: transport}))
+	require.NoError(b, err)
+	client := s3.NewFromConfig(awsCfg, func(o *s3.Options) {
+		o.BaseEndpoint = aws.String(endpoint)
+		o.UsePathStyle = true
+	})
+	_, err = client.CreateBucket(context.Background(), &s3.CreateBucketInput{Bucket: aws.String(bucket)})
+	var owned *types.BucketAlreadyOwnedByYou
+	if err != nil && !errors.As(err, &owned) {
+		require.NoError(b, err)
+	}
+	return &s3ListObjectsAPIImpl{client: client}, client, client, bucket
+}
+
+// benchmarkReader writes count objects of size bytes to the bucket of the
+// benchmarks, spread over the partitions from testTime, and returns the reader
+// of these objects.
+func benchmarkReader(b *testing.B, count, size int) *s3Reader {
+	listObjectsClient, getObjectClient, putObjectClient, bucket := benchmarkBucket(b)
+	reader := &s3Reader{
+		listObjectsClient: listObjectsClient,
+		getObjectClient:   getObjectClient,
+		s3Bucket:          bucket,
+		s3Prefix:          fmt.Sprintf("benchmark/%d-%d", count, size),
+		s3Partition:       S3PartitionMinute,
+		startTime:         testTime,
+		endTime:           testTime.Add(benchmarkPartitions * time.Minute),
+		now:               time.Now,
+	}
+	contents := bytes.Repeat([]byte("a"), size)
+	for i := 0; i < count; i++ {
+		prefix := reader.getObjectPrefixForTime(testTime.Add(time.Duration(i%benchmarkPartitions)*time.Minute), "traces")
+		_, err := putObjectClient.PutObject(context.Background(), &s3.PutObjectInput{
+			Bucket: aws.String(bucket),
+			Key:    aws.String(prefix + strconv.Itoa(i)),
+			Body:   bytes.NewReader(contents),
+		})
+		require.NoError(b, err)
+	}
+	return reader
+}
+
+// BenchmarkS3Reader_readAll measures the objects and the bytes read per second
+// from the time range, for a few sizes of objects and settings of the reader.
+func BenchmarkS3Reader_readAll(b *testing.B) {
+	datasets := []struct {
+		name  string
+		count int
+		size  int
+	}{
+		{name: "1000x4KiB", count: 1000, size: 4 << 10},
+		{name: "200x256KiB", count: 200, size: 256 << 10},
+		{name: "20x4MiB", count: 20, size: 4 << 20},
+	}
+	settings := []struct {
+		name      string
+		configure func(reader *s3Reader)
+	}{
+		{name: "sequential", configure: func(*s3Reader) {}},
+		{name: "prefetch", configure: func(reader *s3Reader) {
+			reader.prefetcher = newPrefetcher(&S3PrefetchConfig{Objects: 16, Downloads: 8})
+		}},
+		{name: "adaptive", configure: func(reader *s3Reader) {
+			reader.prefetcher = newPrefetcher(&S3PrefetchConfig{Objects: 16, Downloads: 8, Adaptive: &S3AdaptiveDownloadsConfig{}})
+		}},
+		{name: "partition_concurrency", configure: func(reader *s3Reader) {
+			reader.partitionConcurrency = benchmarkPartitions
+		}},
+	}
+	for _, dataset := range datasets {
+		b.Run(dataset.name, func(b *testing.B) {
+			reader := benchmarkReader(b, dataset.count, dataset.size)
+			for _, setting := range settings {
+				b.Run(setting.name, func(b *testing.B) {
+					setting.configure(reader)
+					b.SetBytes(int64(dataset.count * dataset.size))
+					b.ReportAllocs()
+					b.ResetTimer()
+					for i := 0; i < b.N; i++ {
+						objects := 0
+						require.NoError(b, reader.readAll(context.Background(), "traces", func(_ context.Context, _ string, body io.Reader) error {
+							objects++
+							_, err := io.Copy(io.Discard, body)
+							return err
+						}))
+						require.Equal(b, dataset.count, objects)
+					}
+					b.ReportMetric(float64(dataset.count*b.N)/b.Elapsed().Seconds(), "objects/s")
+					reader.prefetcher, reader.partitionConcurrency = nil, 0
+				})
+			}
+		})
+	}
+}
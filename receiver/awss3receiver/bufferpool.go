@@ -0,0 +1,53 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"bytes"
+	"io"
+	"sync"
+	"sync/atomic"
+)
+
+// minPooledBufferSize is the capacity of the buffers always kept for reuse,
+// whatever the sizes of the recent objects.
+const minPooledBufferSize = 64 << 10
+
+// bufferPool reuses the buffers the contents of the objects are read into, so
+// that replaying many objects does not allocate a buffer for each of them. The
+// new buffers are sized after the recent objects, and the buffers much larger
+// than the recent objects are left to the garbage collector rather than held.
+type bufferPool struct {
+	pool sync.Pool
+	// size is the moving average of the sizes of the contents read, updated
+	// without synchronization as it only sizes the buffers.
+	size atomic.Int64
+}
+
+// objectBuffers is the pool of the buffers of the contents of the objects.
+var objectBuffers = &bufferPool{}
+
+// readAll reads r in full into a buffer of the pool, to be returned with put
+// once its contents are no longer referenced.
+func (p *bufferPool) readAll(r io.Reader) (*bytes.Buffer, error) {
+	buffer, ok := p.pool.Get().(*bytes.Buffer)
+	if !ok {
+		buffer = bytes.NewBuffer(make([]byte, 0, p.size.Load()))
+	}
+	_, err := buffer.ReadFrom(r)
+	return buffer, err
+}
+
+// put returns buffer to the pool, unless it is much larger than the recent
+// objects.
+func (p *bufferPool) put(buffer *bytes.Buffer) {
+	average := p.size.Load()
+	average += (int64(buffer.Len()) - average) / 8
+	p.size.Store(average)
+	if int64(buffer.Cap()) > max(4*average, minPooledBufferSize) {
+		return
+	}
+	buffer.Reset()
+	p.pool.Put(buffer)
+}
@@ -0,0 +1,46 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_bufferPool(t *testing.T) {
+	p := &bufferPool{}
+	buffer, err := p.readAll(strings.NewReader("object_1"))
+	require.NoError(t, err)
+	require.Equal(t, "object_1", buffer.String())
+	p.put(buffer)
+	require.Equal(t, int64(1), p.size.Load())
+
+	// The buffers are empty when reused.
+	buffer, err = p.readAll(strings.NewReader("object_2"))
+	require.NoError(t, err)
+	require.Equal(t, "object_2", buffer.String())
+	p.put(buffer)
+
+	// The buffers much larger than the recent objects are not kept.
+	p = &bufferPool{}
+	p.put(bytes.NewBuffer(make([]byte, 0, 4*minPooledBufferSize)))
+	require.Nil(t, p.pool.Get())
+}
+
+func Test_newGzipReader_Pooled(t *testing.T) {
+	for _, contents := range []string{"object_1", "object_2"} {
+		_, data, err := decompress("logs_1.json.gz", gzipCompress([]byte(contents)))
+		require.NoError(t, err)
+		require.Equal(t, contents, string(data))
+	}
+	// The readers failing to read the gzip header are also reused.
+	_, _, err := decompressAs(CompressionGzip, "logs_1.json", []byte("not compressed"))
+	require.Error(t, err)
+	_, data, err := decompress("logs_1.json.gz", gzipCompress([]byte("object_3")))
+	require.NoError(t, err)
+	require.Equal(t, "object_3", string(data))
+}
@@ -0,0 +1,224 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"go.uber.org/zap"
+)
+
+// defaultCheckpointInterval is the time between the writes of the checkpoint by
+// default.
+const defaultCheckpointInterval = time.Minute
+
+type PutObjectAPI interface {
+	PutObject(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error)
+}
+
+// checkpoint is the position of a reader, written as JSON to the checkpoint
+// object.
+type checkpoint struct {
+	// Position is the start of the partition being read.
+	Position time.Time `json:"position"`
+	// Key is the key of the last object read from the partition, if any.
+	Key string `json:"key,omitempty"`
+}
+
+// resumeTokenPrefix starts the resume tokens, the checkpoints encoded to be set
+// as starttime, or as endtime when reading the newest objects first.
+const resumeTokenPrefix = "resume:"
+
+// resumeToken returns the checkpoint encoded as a resume token.
+func (c checkpoint) resumeToken() string {
+	data, _ := json.Marshal(c)
+	return resumeTokenPrefix + base64.RawURLEncoding.EncodeToString(data)
+}
+
+// parseResumeToken decodes the checkpoint of a resume token. It reports whether
+// value is a resume token at all, rather than a time.
+func parseResumeToken(value string) (checkpoint, bool, error) {
+	encoded, ok := strings.CutPrefix(value, resumeTokenPrefix)
+	if !ok {
+		return checkpoint{}, false, nil
+	}
+	var c checkpoint
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err == nil {
+		err = json.Unmarshal(data, &c)
+	}
+	if err == nil && c.Position.IsZero() {
+		err = errors.New("the position is missing")
+	}
+	return c, true, err
+}
+
+// checkpointReader is implemented by the readers able to resume from a
+// checkpoint.
+type checkpointReader interface {
+	checkpoint() checkpoint
+	resumeFrom(checkpoint) bool
+}
+
+// checkpointer loads the checkpoint of a reader at startup, then writes it
+// back on an interval and on shutdown.
+type checkpointer struct {
+	getObjectClient GetObjectAPI
+	putObjectClient PutObjectAPI
+	bucket          string
+	key             string
+	interval        time.Duration
+	reader          checkpointReader
+	logger          *zap.Logger
+
+	// saved is the checkpoint last written.
+	saved  checkpoint
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func newCheckpointer(ctx context.Context, cfg *Config, telemetryType string, reader telemetryReader, logger *zap.Logger) (*checkpointer, error) {
+	resumable, ok := reader.(checkpointReader)
+	if !ok {
+		return nil, errors.New("checkpoint is only supported when reading a time range")
+	}
+	downloaderCfg := cfg.S3Downloader
+	downloaderCfg.S3Bucket = cfg.Checkpoint.S3Bucket
+	if cfg.Checkpoint.Region != "" {
+		downloaderCfg.Region = cfg.Checkpoint.Region
+	}
+	_, getObjectClient, err := newS3Client(ctx, downloaderCfg)
+	if err != nil {
+		return nil, err
+	}
+	putObjectClient, ok := getObjectClient.(PutObjectAPI)
+	if !ok {
+		return nil, errors.New("writing the checkpoint is not supported by the S3 client")
+	}
+	return newS3Checkpointer(getObjectClient, putObjectClient, *cfg.Checkpoint, telemetryType, resumable, logger), nil
+}
+
+func newS3Checkpointer(getObjectClient GetObjectAPI, putObjectClient PutObjectAPI, cfg CheckpointConfig, telemetryType string, reader checkpointReader, logger *zap.Logger) *checkpointer {
+	interval := cfg.Interval
+	if interval == 0 {
+		interval = defaultCheckpointInterval
+	}
+	return &checkpointer{
+		getObjectClient: getObjectClient,
+		putObjectClient: putObjectClient,
+		bucket:          cfg.S3Bucket,
+		key:             path.Join(cfg.S3Prefix, "checkpoint_"+telemetryType+".json"),
+		interval:        interval,
+		reader:          reader,
+		logger:          logger,
+	}
+}
+
+// start resumes the reader from the checkpoint object, if any, then writes the
+// checkpoint on the interval.
+func (c *checkpointer) start(ctx context.Context) error {
+	output, err := c.getObjectClient.GetObject(ctx, &s3.GetObjectInput{Bucket: &c.bucket, Key: &c.key})
+	var noSuchKey *types.NoSuchKey
+	switch {
+	case errors.As(err, &noSuchKey):
+		c.logger.Info("No checkpoint found, reading from the start of the time range", zap.String("bucket", c.bucket), zap.String("key", c.key))
+	case err != nil:
+		return fmt.Errorf("unable to read the checkpoint: %w", err)
+	default:
+		defer output.Body.Close()
+		data, err := io.ReadAll(output.Body)
+		if err != nil {
+			return fmt.Errorf("unable to read the checkpoint: %w", err)
+		}
+		if err := json.Unmarshal(data, &c.saved); err != nil {
+			return fmt.Errorf("invalid checkpoint: %w", err)
+		}
+		if c.reader.resumeFrom(c.saved) {
+			c.logger.Info("Resuming from the checkpoint", zap.Time("position", c.saved.Position), zap.String("key", c.saved.Key))
+		} else {
+			c.logger.Warn("Ignoring the checkpoint outside of the time range", zap.Time("position", c.saved.Position))
+		}
+	}
+	ctx, c.cancel = context.WithCancel(context.Background())
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		ticker := time.NewTicker(c.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := c.save(ctx); err != nil {
+					c.logger.Warn("Failed to write the checkpoint", zap.Error(err))
+				}
+			}
+		}
+	}()
+	return nil
+}
+
+// save writes the checkpoint of the reader, if it changed since it was last
+// written.
+func (c *checkpointer) save(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	current := c.reader.checkpoint()
+	if current.Position.IsZero() || (current.Position.Equal(c.saved.Position) && current.Key == c.saved.Key) {
+		return nil
+	}
+	data, err := json.Marshal(current)
+	if err != nil {
+		return err
+	}
+	if _, err := c.putObjectClient.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:      &c.bucket,
+		Key:         &c.key,
+		Body:        bytes.NewReader(data),
+		ContentType: aws.String("application/json"),
+	}); err != nil {
+		return err
+	}
+	c.saved = current
+	return nil
+}
+
+// stop stops writing the checkpoint on the interval, and reports whether it was
+// being written.
+func (c *checkpointer) stop() bool {
+	if c.cancel == nil {
+		return false
+	}
+	c.cancel()
+	c.wg.Wait()
+	c.cancel = nil
+	return true
+}
+
+// shutdown stops writing the checkpoint on the interval and writes it one last
+// time.
+func (c *checkpointer) shutdown(ctx context.Context) error {
+	if !c.stop() {
+		return nil
+	}
+	if err := c.save(ctx); err != nil {
+		return fmt.Errorf("unable to write the checkpoint: %w", err)
+	}
+	return nil
+}
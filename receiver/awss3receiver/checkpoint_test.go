@@ -0,0 +1,142 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// mockCheckpointStore holds the checkpoint objects written to S3.
+type mockCheckpointStore struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+}
+
+func (m *mockCheckpointStore) GetObject(_ context.Context, params *s3.GetObjectInput, _ ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	data, ok := m.objects[*params.Bucket+"/"+*params.Key]
+	if !ok {
+		return nil, &types.NoSuchKey{}
+	}
+	return &s3.GetObjectOutput{Body: io.NopCloser(bytes.NewReader(data))}, nil
+}
+
+func (m *mockCheckpointStore) PutObject(_ context.Context, params *s3.PutObjectInput, _ ...func(*s3.Options)) (*s3.PutObjectOutput, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	data, err := io.ReadAll(params.Body)
+	if err != nil {
+		return nil, err
+	}
+	m.objects[*params.Bucket+"/"+*params.Key] = data
+	return &s3.PutObjectOutput{}, nil
+}
+
+// newCheckpointTestReader returns a reader of three minutes holding two objects
+// each, listed after StartAfter.
+func newCheckpointTestReader() *s3Reader {
+	return &s3Reader{
+		listObjectsClient: mockListObjectsAPI(func(params *s3.ListObjectsV2Input) ListObjectsV2Pager {
+			var contents []types.Object
+			for _, name := range []string{"1", "2"} {
+				if key := *params.Prefix + name; key > aws.ToString(params.StartAfter) {
+					contents = append(contents, types.Object{Key: aws.String(key)})
+				}
+			}
+			return &mockListObjectsV2Pager{Pages: []*s3.ListObjectsV2Output{{Contents: contents}}}
+		}),
+		getObjectClient: mockGetObjectAPI(func(_ context.Context, _ *s3.GetObjectInput, _ ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
+			return &s3.GetObjectOutput{Body: io.NopCloser(bytes.NewReader([]byte("this is the body of the object")))}, nil
+		}),
+		s3Bucket:    "bucket",
+		s3Partition: "minute",
+		startTime:   testTime,
+		endTime:     testTime.Add(3 * time.Minute),
+	}
+}
+
+func Test_checkpointer(t *testing.T) {
+	store := &mockCheckpointStore{objects: map[string][]byte{}}
+	cfg := CheckpointConfig{S3Bucket: "checkpoints", S3Prefix: "replay", Interval: time.Hour}
+
+	reader := newCheckpointTestReader()
+	c := newS3Checkpointer(store, store, cfg, "traces", reader, zap.NewNop())
+	require.NoError(t, c.start(context.Background()))
+	require.Error(t, reader.readAll(context.Background(), "traces", func(_ context.Context, key string, _ io.Reader) error {
+		if strings.HasSuffix(key, "minute=33/traces_2") {
+			return errors.New("failed to consume")
+		}
+		return nil
+	}))
+	require.NoError(t, c.shutdown(context.Background()))
+	require.JSONEq(t, `{"position":"2021-02-01T17:33:00Z","key":"year=2021/month=02/day=01/hour=17/minute=33/traces_1"}`,
+		string(store.objects["checkpoints/replay/checkpoint_traces.json"]))
+
+	// The next run resumes after the last object read.
+	reader = newCheckpointTestReader()
+	c = newS3Checkpointer(store, store, cfg, "traces", reader, zap.NewNop())
+	require.NoError(t, c.start(context.Background()))
+	var keys []string
+	require.NoError(t, reader.readAll(context.Background(), "traces", func(_ context.Context, key string, _ io.Reader) error {
+		keys = append(keys, key)
+		return nil
+	}))
+	require.NoError(t, c.shutdown(context.Background()))
+	require.Equal(t, []string{
+		"year=2021/month=02/day=01/hour=17/minute=33/traces_2",
+		"year=2021/month=02/day=01/hour=17/minute=34/traces_1",
+		"year=2021/month=02/day=01/hour=17/minute=34/traces_2",
+	}, keys)
+	require.JSONEq(t, `{"position":"2021-02-01T17:34:00Z","key":"year=2021/month=02/day=01/hour=17/minute=34/traces_2"}`,
+		string(store.objects["checkpoints/replay/checkpoint_traces.json"]))
+}
+
+func Test_checkpointer_Invalid(t *testing.T) {
+	store := &mockCheckpointStore{objects: map[string][]byte{
+		"checkpoints/checkpoint_logs.json":    []byte(`{"position":"2021-02-02T00:00:00Z"}`),
+		"checkpoints/checkpoint_metrics.json": []byte(`not a checkpoint`),
+	}}
+	cfg := CheckpointConfig{S3Bucket: "checkpoints"}
+
+	// A checkpoint outside of the time range is ignored.
+	reader := newCheckpointTestReader()
+	c := newS3Checkpointer(store, store, cfg, "logs", reader, zap.NewNop())
+	require.Equal(t, defaultCheckpointInterval, c.interval)
+	require.NoError(t, c.start(context.Background()))
+	require.Equal(t, testTime, reader.startTime)
+	require.NoError(t, c.shutdown(context.Background()))
+
+	c = newS3Checkpointer(store, store, cfg, "metrics", newCheckpointTestReader(), zap.NewNop())
+	require.ErrorContains(t, c.start(context.Background()), "invalid checkpoint: ")
+}
+
+func Test_s3Reader_resumeFrom_NewestFirst(t *testing.T) {
+	reader := newCheckpointTestReader()
+	reader.newestFirst = true
+	require.True(t, reader.resumeFrom(checkpoint{Position: testTime.Add(time.Minute), Key: "year=2021/month=02/day=01/hour=17/minute=33/traces_1"}))
+	var keys []string
+	require.NoError(t, reader.readAll(context.Background(), "traces", func(_ context.Context, key string, _ io.Reader) error {
+		keys = append(keys, key)
+		return nil
+	}))
+	require.Equal(t, []string{
+		"year=2021/month=02/day=01/hour=17/minute=33/traces_2",
+		"year=2021/month=02/day=01/hour=17/minute=32/traces_1",
+		"year=2021/month=02/day=01/hour=17/minute=32/traces_2",
+	}, keys)
+}
@@ -0,0 +1,266 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// The metadata of the objects encrypted with the Amazon S3 Encryption Client,
+// without their x-amz-meta- prefix. The v1 clients store the encrypted data key
+// as x-amz-key, the v2 clients as x-amz-key-v2.
+const (
+	cseKeyV1Metadata      = "x-amz-key"
+	cseKeyV2Metadata      = "x-amz-key-v2"
+	cseIVMetadata         = "x-amz-iv"
+	cseContentAlgMetadata = "x-amz-cek-alg"
+	cseWrapAlgMetadata    = "x-amz-wrap-alg"
+	cseMatDescMetadata    = "x-amz-matdesc"
+	cseTagLenMetadata     = "x-amz-tag-len"
+)
+
+// The algorithms of the encryption of the contents of the objects and of the
+// wrapping of their data keys.
+const (
+	cseContentAESGCM = "AES/GCM/NoPadding"
+	cseContentAESCBC = "AES/CBC/PKCS5Padding"
+	cseWrapKMS       = "kms"
+	cseWrapKMSCtx    = "kms+context"
+	cseWrapAESGCM    = "AES/GCM"
+	cseWrapAESWrap   = "AESWrap"
+)
+
+// cseContentAlgContextKey is the key of the encryption context of the data keys
+// wrapped with kms+context holding the algorithm of the contents.
+const cseContentAlgContextKey = "aws:x-amz-cek-alg"
+
+// KMSDecryptAPI decrypts the data keys wrapped with a KMS key.
+type KMSDecryptAPI interface {
+	Decrypt(ctx context.Context, params *kms.DecryptInput, optFns ...func(*kms.Options)) (*kms.DecryptOutput, error)
+}
+
+// objectDecrypter decrypts the objects encrypted with the Amazon S3 Encryption
+// Client, their data keys being wrapped with a KMS key or with a symmetric key.
+type objectDecrypter struct {
+	kmsClient KMSDecryptAPI
+	kmsKeyID  string
+	key       []byte
+}
+
+func newObjectDecrypter(ctx context.Context, cfg S3DownloaderConfig) (*objectDecrypter, error) {
+	if cfg.ClientSideEncryption == nil {
+		return nil, nil
+	}
+	d := &objectDecrypter{kmsKeyID: cfg.ClientSideEncryption.KMSKeyID}
+	if cfg.ClientSideEncryption.Key != "" {
+		key, err := base64.StdEncoding.DecodeString(string(cfg.ClientSideEncryption.Key))
+		if err != nil {
+			return nil, fmt.Errorf("invalid client_side_encryption key: %w", err)
+		}
+		d.key = key
+		return d, nil
+	}
+	awsCfg, err := loadAWSConfig(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+	d.kmsClient = kms.NewFromConfig(awsCfg, func(o *kms.Options) {
+		if keyARN, err := arn.Parse(d.kmsKeyID); err == nil {
+			o.Region = keyARN.Region
+		}
+	})
+	return d, nil
+}
+
+// wrap returns a client decrypting the encrypted objects retrieved with client,
+// or client itself without an objectDecrypter.
+func (d *objectDecrypter) wrap(client GetObjectAPI) GetObjectAPI {
+	if d == nil {
+		return client
+	}
+	return &decryptingGetObjectAPI{client: client, decrypter: d}
+}
+
+// decryptingGetObjectAPI retrieves objects with client, decrypting the encrypted
+// ones in full before their contents are read.
+type decryptingGetObjectAPI struct {
+	client    GetObjectAPI
+	decrypter *objectDecrypter
+}
+
+func (c *decryptingGetObjectAPI) GetObject(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
+	output, err := c.client.GetObject(ctx, params, optFns...)
+	if err != nil || !isClientSideEncrypted(output.Metadata) {
+		return output, err
+	}
+	ciphertext, err := io.ReadAll(output.Body)
+	output.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := c.decrypter.decrypt(ctx, output.Metadata, ciphertext)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decrypt %s: %w", aws.ToString(params.Key), err)
+	}
+	output.Body = io.NopCloser(bytes.NewReader(plaintext))
+	output.ContentLength = aws.Int64(int64(len(plaintext)))
+	return output, nil
+}
+
+// isClientSideEncrypted reports whether the metadata of an object holds the data
+// key it was encrypted with by the Amazon S3 Encryption Client.
+func isClientSideEncrypted(metadata map[string]string) bool {
+	_, v1 := metadata[cseKeyV1Metadata]
+	_, v2 := metadata[cseKeyV2Metadata]
+	return v1 || v2
+}
+
+// decrypt decrypts the contents of an object according to its metadata.
+func (d *objectDecrypter) decrypt(ctx context.Context, metadata map[string]string, ciphertext []byte) ([]byte, error) {
+	encryptedKey, ok := metadata[cseKeyV2Metadata]
+	if !ok {
+		encryptedKey = metadata[cseKeyV1Metadata]
+	}
+	wrappedKey, err := base64.StdEncoding.DecodeString(encryptedKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid encrypted data key: %w", err)
+	}
+	iv, err := base64.StdEncoding.DecodeString(metadata[cseIVMetadata])
+	if err != nil {
+		return nil, fmt.Errorf("invalid initialization vector: %w", err)
+	}
+	contentAlg := metadata[cseContentAlgMetadata]
+	if contentAlg == "" {
+		contentAlg = cseContentAESCBC
+	}
+	dataKey, err := d.unwrapKey(ctx, metadata[cseWrapAlgMetadata], contentAlg, metadata[cseMatDescMetadata], wrappedKey)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(dataKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid data key: %w", err)
+	}
+	switch contentAlg {
+	case cseContentAESGCM:
+		if tagLen := metadata[cseTagLenMetadata]; tagLen != "" && tagLen != "128" {
+			return nil, fmt.Errorf("unsupported tag length %s", tagLen)
+		}
+		aead, err := cipher.NewGCMWithNonceSize(block, len(iv))
+		if err != nil {
+			return nil, err
+		}
+		return aead.Open(ciphertext[:0], iv, ciphertext, nil)
+	case cseContentAESCBC:
+		if len(iv) != aes.BlockSize || len(ciphertext) == 0 || len(ciphertext)%aes.BlockSize != 0 {
+			return nil, errors.New("invalid AES/CBC ciphertext")
+		}
+		cipher.NewCBCDecrypter(block, iv).CryptBlocks(ciphertext, ciphertext)
+		padding := int(ciphertext[len(ciphertext)-1])
+		if padding == 0 || padding > aes.BlockSize || !bytes.Equal(ciphertext[len(ciphertext)-padding:], bytes.Repeat([]byte{byte(padding)}, padding)) {
+			return nil, errors.New("invalid AES/CBC padding")
+		}
+		return ciphertext[:len(ciphertext)-padding], nil
+	}
+	return nil, fmt.Errorf("unsupported content encryption algorithm %q", contentAlg)
+}
+
+// unwrapKey decrypts the data key of an object wrapped with wrapAlg.
+func (d *objectDecrypter) unwrapKey(ctx context.Context, wrapAlg, contentAlg, matDesc string, wrappedKey []byte) ([]byte, error) {
+	switch wrapAlg {
+	case cseWrapKMS, cseWrapKMSCtx:
+		if d.kmsClient == nil {
+			return nil, fmt.Errorf("the data key is wrapped with %s, which requires kms_key_id", wrapAlg)
+		}
+		encryptionContext := map[string]string{}
+		if matDesc != "" {
+			if err := json.Unmarshal([]byte(matDesc), &encryptionContext); err != nil {
+				return nil, fmt.Errorf("invalid material description: %w", err)
+			}
+		}
+		if wrapAlg == cseWrapKMSCtx && encryptionContext[cseContentAlgContextKey] != contentAlg {
+			return nil, errors.New("the encryption context does not match the content encryption algorithm")
+		}
+		output, err := d.kmsClient.Decrypt(ctx, &kms.DecryptInput{
+			CiphertextBlob:    wrappedKey,
+			EncryptionContext: encryptionContext,
+			KeyId:             &d.kmsKeyID,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("unable to decrypt the data key: %w", err)
+		}
+		return output.Plaintext, nil
+	case cseWrapAESGCM, cseWrapAESWrap:
+		if d.key == nil {
+			return nil, fmt.Errorf("the data key is wrapped with %s, which requires key", wrapAlg)
+		}
+		block, err := aes.NewCipher(d.key)
+		if err != nil {
+			return nil, err
+		}
+		if wrapAlg == cseWrapAESWrap {
+			return aesKeyUnwrap(block, wrappedKey)
+		}
+		// The wrapped key is prefixed with its nonce, and authenticated along with
+		// the algorithm of the contents.
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, err
+		}
+		if len(wrappedKey) < aead.NonceSize() {
+			return nil, errors.New("invalid wrapped data key")
+		}
+		dataKey, err := aead.Open(nil, wrappedKey[:aead.NonceSize()], wrappedKey[aead.NonceSize():], []byte(contentAlg))
+		if err != nil {
+			return nil, fmt.Errorf("unable to decrypt the data key: %w", err)
+		}
+		return dataKey, nil
+	}
+	return nil, fmt.Errorf("unsupported key wrapping algorithm %q", wrapAlg)
+}
+
+// aesKeyDefaultIV is the initial value of the AES key wrap of RFC 3394.
+var aesKeyDefaultIV = []byte{0xa6, 0xa6, 0xa6, 0xa6, 0xa6, 0xa6, 0xa6, 0xa6}
+
+// aesKeyUnwrap unwraps a key wrapped with the AES key wrap of RFC 3394.
+func aesKeyUnwrap(block cipher.Block, wrapped []byte) ([]byte, error) {
+	if len(wrapped) < 24 || len(wrapped)%8 != 0 {
+		return nil, errors.New("invalid wrapped data key")
+	}
+	n := len(wrapped)/8 - 1
+	a := make([]byte, 8)
+	copy(a, wrapped[:8])
+	r := make([]byte, n*8)
+	copy(r, wrapped[8:])
+	b := make([]byte, aes.BlockSize)
+	for j := 5; j >= 0; j-- {
+		for i := n; i >= 1; i-- {
+			t := uint64(n*j + i)
+			binary.BigEndian.PutUint64(b[:8], binary.BigEndian.Uint64(a)^t)
+			copy(b[8:], r[(i-1)*8:i*8])
+			block.Decrypt(b, b)
+			copy(a, b[:8])
+			copy(r[(i-1)*8:i*8], b[8:])
+		}
+	}
+	if subtle.ConstantTimeCompare(a, aesKeyDefaultIV) != 1 {
+		return nil, errors.New("unable to decrypt the data key: integrity check failed")
+	}
+	return r, nil
+}
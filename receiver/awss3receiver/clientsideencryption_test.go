@@ -0,0 +1,170 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/stretchr/testify/require"
+)
+
+type mockKMSDecryptAPI func(ctx context.Context, params *kms.DecryptInput, optFns ...func(*kms.Options)) (*kms.DecryptOutput, error)
+
+func (m mockKMSDecryptAPI) Decrypt(ctx context.Context, params *kms.DecryptInput, optFns ...func(*kms.Options)) (*kms.DecryptOutput, error) {
+	return m(ctx, params, optFns...)
+}
+
+var (
+	testCSEWrappingKey = []byte("0123456789abcdef0123456789abcdef")
+	testCSEDataKey     = []byte("fedcba9876543210fedcba9876543210")
+)
+
+// encryptGCM encrypts plaintext with AES/GCM/NoPadding as the v2 clients do,
+// returning the ciphertext and the metadata of the object, without the data key.
+func encryptGCM(t *testing.T, plaintext []byte) ([]byte, map[string]string) {
+	block, err := aes.NewCipher(testCSEDataKey)
+	require.NoError(t, err)
+	aead, err := cipher.NewGCM(block)
+	require.NoError(t, err)
+	iv := bytes.Repeat([]byte{1}, aead.NonceSize())
+	return aead.Seal(nil, iv, plaintext, nil), map[string]string{
+		cseIVMetadata:         base64.StdEncoding.EncodeToString(iv),
+		cseContentAlgMetadata: cseContentAESGCM,
+		cseTagLenMetadata:     "128",
+	}
+}
+
+// aesKeyWrap wraps a key with the AES key wrap of RFC 3394.
+func aesKeyWrap(t *testing.T, kek, key []byte) []byte {
+	block, err := aes.NewCipher(kek)
+	require.NoError(t, err)
+	n := len(key) / 8
+	a := append([]byte{}, aesKeyDefaultIV...)
+	r := append([]byte{}, key...)
+	b := make([]byte, aes.BlockSize)
+	for j := 0; j <= 5; j++ {
+		for i := 1; i <= n; i++ {
+			copy(b, a)
+			copy(b[8:], r[(i-1)*8:i*8])
+			block.Encrypt(b, b)
+			binary.BigEndian.PutUint64(a, binary.BigEndian.Uint64(b[:8])^uint64(n*j+i))
+			copy(r[(i-1)*8:i*8], b[8:])
+		}
+	}
+	return append(a, r...)
+}
+
+func Test_objectDecrypter_KMS(t *testing.T) {
+	ciphertext, metadata := encryptGCM(t, []byte("telemetry"))
+	metadata[cseKeyV2Metadata] = base64.StdEncoding.EncodeToString([]byte("wrapped"))
+	metadata[cseWrapAlgMetadata] = cseWrapKMSCtx
+	metadata[cseMatDescMetadata] = `{"aws:x-amz-cek-alg":"AES/GCM/NoPadding","tenant":"a"}`
+	decrypter := &objectDecrypter{
+		kmsKeyID: "alias/archive",
+		kmsClient: mockKMSDecryptAPI(func(_ context.Context, params *kms.DecryptInput, _ ...func(*kms.Options)) (*kms.DecryptOutput, error) {
+			require.Equal(t, "alias/archive", aws.ToString(params.KeyId))
+			require.Equal(t, []byte("wrapped"), params.CiphertextBlob)
+			require.Equal(t, map[string]string{"aws:x-amz-cek-alg": "AES/GCM/NoPadding", "tenant": "a"}, params.EncryptionContext)
+			return &kms.DecryptOutput{Plaintext: append([]byte{}, testCSEDataKey...)}, nil
+		}),
+	}
+	plaintext, err := decrypter.decrypt(context.Background(), metadata, ciphertext)
+	require.NoError(t, err)
+	require.Equal(t, "telemetry", string(plaintext))
+
+	metadata[cseMatDescMetadata] = `{"aws:x-amz-cek-alg":"AES/CBC/PKCS5Padding"}`
+	_, err = decrypter.decrypt(context.Background(), metadata, ciphertext)
+	require.EqualError(t, err, "the encryption context does not match the content encryption algorithm")
+
+	// The data keys wrapped with KMS cannot be unwrapped with a symmetric key.
+	_, err = (&objectDecrypter{key: testCSEWrappingKey}).decrypt(context.Background(), metadata, ciphertext)
+	require.EqualError(t, err, "the data key is wrapped with kms+context, which requires kms_key_id")
+}
+
+func Test_objectDecrypter_AESGCM(t *testing.T) {
+	ciphertext, metadata := encryptGCM(t, []byte("telemetry"))
+	block, err := aes.NewCipher(testCSEWrappingKey)
+	require.NoError(t, err)
+	aead, err := cipher.NewGCM(block)
+	require.NoError(t, err)
+	nonce := bytes.Repeat([]byte{2}, aead.NonceSize())
+	metadata[cseKeyV2Metadata] = base64.StdEncoding.EncodeToString(aead.Seal(nonce, nonce, testCSEDataKey, []byte(cseContentAESGCM)))
+	metadata[cseWrapAlgMetadata] = cseWrapAESGCM
+
+	decrypter := &objectDecrypter{key: testCSEWrappingKey}
+	plaintext, err := decrypter.decrypt(context.Background(), metadata, append([]byte{}, ciphertext...))
+	require.NoError(t, err)
+	require.Equal(t, "telemetry", string(plaintext))
+
+	_, err = (&objectDecrypter{key: testCSEDataKey}).decrypt(context.Background(), metadata, ciphertext)
+	require.ErrorContains(t, err, "unable to decrypt the data key")
+}
+
+func Test_objectDecrypter_AESWrapCBC(t *testing.T) {
+	block, err := aes.NewCipher(testCSEDataKey)
+	require.NoError(t, err)
+	iv := bytes.Repeat([]byte{3}, aes.BlockSize)
+	// "telemetry" padded to a block.
+	ciphertext := append([]byte("telemetry"), bytes.Repeat([]byte{7}, 7)...)
+	cipher.NewCBCEncrypter(block, iv).CryptBlocks(ciphertext, ciphertext)
+	metadata := map[string]string{
+		cseKeyV1Metadata:   base64.StdEncoding.EncodeToString(aesKeyWrap(t, testCSEWrappingKey, testCSEDataKey)),
+		cseIVMetadata:      base64.StdEncoding.EncodeToString(iv),
+		cseWrapAlgMetadata: cseWrapAESWrap,
+	}
+
+	decrypter := &objectDecrypter{key: testCSEWrappingKey}
+	plaintext, err := decrypter.decrypt(context.Background(), metadata, ciphertext)
+	require.NoError(t, err)
+	require.Equal(t, "telemetry", string(plaintext))
+
+	metadata[cseWrapAlgMetadata] = "RSA"
+	_, err = decrypter.decrypt(context.Background(), metadata, ciphertext)
+	require.EqualError(t, err, `unsupported key wrapping algorithm "RSA"`)
+}
+
+func Test_objectDecrypter_wrap(t *testing.T) {
+	client := mockGetObjectAPI(func(_ context.Context, params *s3.GetObjectInput, _ ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
+		if *params.Key == "missing" {
+			return nil, errors.New("no such key")
+		}
+		if *params.Key == "plain" {
+			return &s3.GetObjectOutput{Body: io.NopCloser(bytes.NewReader([]byte("plain")))}, nil
+		}
+		ciphertext, metadata := encryptGCM(t, []byte("telemetry"))
+		metadata[cseKeyV1Metadata] = base64.StdEncoding.EncodeToString(aesKeyWrap(t, testCSEWrappingKey, testCSEDataKey))
+		metadata[cseWrapAlgMetadata] = cseWrapAESWrap
+		return &s3.GetObjectOutput{Body: io.NopCloser(bytes.NewReader(ciphertext)), Metadata: metadata}, nil
+	})
+	var decrypter *objectDecrypter
+	require.IsType(t, client, decrypter.wrap(client))
+
+	decrypter = &objectDecrypter{key: testCSEWrappingKey}
+	wrapped := decrypter.wrap(client)
+	data, _, err := getObject(context.Background(), wrapped, &s3.GetObjectInput{Bucket: aws.String("bucket"), Key: aws.String("encrypted")})
+	require.NoError(t, err)
+	require.Equal(t, "telemetry", string(data))
+
+	// The objects without encryption metadata are read as they are.
+	data, _, err = getObject(context.Background(), wrapped, &s3.GetObjectInput{Bucket: aws.String("bucket"), Key: aws.String("plain")})
+	require.NoError(t, err)
+	require.Equal(t, "plain", string(data))
+
+	_, _, err = getObject(context.Background(), wrapped, &s3.GetObjectInput{Bucket: aws.String("bucket"), Key: aws.String("missing")})
+	require.EqualError(t, err, "no such key")
+
+	_, _, err = getObject(context.Background(), (&objectDecrypter{key: testCSEDataKey}).wrap(client), &s3.GetObjectInput{Bucket: aws.String("bucket"), Key: aws.String("encrypted")})
+	require.ErrorContains(t, err, "unable to decrypt encrypted: ")
+}
@@ -0,0 +1,137 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	conventions "go.opentelemetry.io/collector/semconv/v1.25.0"
+	"go.uber.org/zap"
+)
+
+// cloudFrontFieldsDirective starts the line naming the fields of the entries of a
+// CloudFront standard log file.
+const cloudFrontFieldsDirective = "#Fields:"
+
+// cloudFrontAttributes are the semantic convention attributes of the CloudFront
+// log fields that have one. The other fields are set as aws.cloudfront.<field>.
+var cloudFrontAttributes = map[string]string{
+	"c-ip":           conventions.AttributeClientAddress,
+	"c-port":         conventions.AttributeClientPort,
+	"cs-method":      conventions.AttributeHTTPRequestMethod,
+	"x-host-header":  conventions.AttributeServerAddress,
+	"cs-protocol":    conventions.AttributeURLScheme,
+	"cs-uri-stem":    conventions.AttributeURLPath,
+	"cs-uri-query":   conventions.AttributeURLQuery,
+	"sc-status":      conventions.AttributeHTTPResponseStatusCode,
+	"cs(User-Agent)": conventions.AttributeUserAgentOriginal,
+	"ssl-cipher":     conventions.AttributeTLSCipher,
+}
+
+// cloudFrontIntegerFields and cloudFrontDecimalFields are the CloudFront log
+// fields holding numbers.
+var (
+	cloudFrontIntegerFields = map[string]bool{
+		"sc-bytes": true, "cs-bytes": true, "sc-status": true, "c-port": true,
+		"sc-content-len": true, "sc-range-start": true, "sc-range-end": true,
+	}
+	cloudFrontDecimalFields = map[string]bool{
+		"time-taken": true, "time-to-first-byte": true,
+	}
+)
+
+// cloudFrontDecoder decodes CloudFront standard log files, holding tab separated
+// entries whose fields are named by a #Fields directive, into a log record per
+// entry.
+type cloudFrontDecoder struct {
+	logger *zap.Logger
+}
+
+func (d *cloudFrontDecoder) decodeLogs(info objectInfo, data []byte) (plog.Logs, error) {
+	logs, records := newObjectLogs()
+	var names []string
+	for index, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimRight(line, "\r")
+		if fields, ok := strings.CutPrefix(line, cloudFrontFieldsDirective); ok {
+			names = strings.Fields(fields)
+			continue
+		}
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		values := strings.Split(line, "\t")
+		if names == nil || len(values) != len(names) {
+			d.logger.Warn("Skipping invalid CloudFront log entry", zap.String("key", info.key), zap.Int("line", index+1))
+			continue
+		}
+		record := appendObjectRecord(records, info)
+		record.Body().SetStr(line)
+		setCloudFrontRecord(record, names, values)
+	}
+	return logs, nil
+}
+
+// setCloudFrontRecord sets the attributes and timestamp of a record from the
+// fields of an entry, the timestamp being the end of the request.
+func setCloudFrontRecord(record plog.LogRecord, names, values []string) {
+	attributes := record.Attributes()
+	var date, clock string
+	for i, name := range names {
+		value := values[i]
+		if value == "-" || value == "" {
+			continue
+		}
+		switch name {
+		case "date":
+			date = value
+			continue
+		case "time":
+			clock = value
+			continue
+		case "cs-protocol-version":
+			if protocol, version, ok := strings.Cut(value, "/"); ok {
+				attributes.PutStr(conventions.AttributeNetworkProtocolName, strings.ToLower(protocol))
+				attributes.PutStr(conventions.AttributeNetworkProtocolVersion, version)
+				continue
+			}
+		case "cs(User-Agent)", "cs(Referer)", "cs(Cookie)":
+			// CloudFront URL encodes the spaces and some other characters of the headers.
+			if unescaped, err := url.PathUnescape(value); err == nil {
+				value = unescaped
+			}
+		}
+		attribute, ok := cloudFrontAttributes[name]
+		if !ok {
+			attribute = "aws.cloudfront." + cloudFrontAttributeName(name)
+		}
+		switch {
+		case cloudFrontIntegerFields[name]:
+			if integer, err := strconv.ParseInt(value, 10, 64); err == nil {
+				attributes.PutInt(attribute, integer)
+				continue
+			}
+		case cloudFrontDecimalFields[name]:
+			if decimal, err := strconv.ParseFloat(value, 64); err == nil {
+				attributes.PutDouble(attribute, decimal)
+				continue
+			}
+		}
+		attributes.PutStr(attribute, value)
+	}
+	if timestamp, err := time.Parse("2006-01-02 15:04:05", date+" "+clock); err == nil {
+		record.SetTimestamp(pcommon.NewTimestampFromTime(timestamp))
+	}
+}
+
+// cloudFrontAttributeName returns the attribute name of a field, in lower case
+// with underscores, cs(Host) becoming cs_host.
+func cloudFrontAttributeName(field string) string {
+	field = strings.TrimSuffix(strings.ToLower(field), ")")
+	return strings.NewReplacer("-", "_", "(", "_").Replace(field)
+}
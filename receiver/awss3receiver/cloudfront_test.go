@@ -0,0 +1,66 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func Test_cloudFrontDecoder(t *testing.T) {
+	info := objectInfo{bucket: "bucket", key: "EDFDVBD6EXAMPLE.2021-02-01-17.a103fd5a.gz"}
+	entry := "2021-02-01\t17:01:02\tSEA19-C1\t392\t192.0.2.100\tGET\td111111abcdef8.cloudfront.net\t/index.html\t200\t-\t" +
+		"Mozilla/5.0%20(Windows%20NT%2010.0)\t-\t-\tHit\tSOX4xwn4XV6Q4rgb7XiVGOHms_BGlTAC4KyHmureZmBNrjGdRLiNIQ==\t" +
+		"www.example.com\thttps\t157\t0.001\t-\tTLSv1.3\tTLS_AES_128_GCM_SHA256\tHit\tHTTP/2.0\t-\t-\t11040\t0.001\tHit\ttext/html\t78\t-\t-"
+	data := []byte("#Version: 1.0\n" +
+		"#Fields: date time x-edge-location sc-bytes c-ip cs-method cs(Host) cs-uri-stem sc-status cs(Referer) cs(User-Agent) " +
+		"cs-uri-query cs(Cookie) x-edge-result-type x-edge-request-id x-host-header cs-protocol cs-bytes time-taken " +
+		"x-forwarded-for ssl-protocol ssl-cipher x-edge-response-result-type cs-protocol-version fle-status " +
+		"fle-encrypted-fields c-port time-to-first-byte x-edge-detailed-result-type sc-content-type sc-content-len " +
+		"sc-range-start sc-range-end\n" +
+		entry + "\n" +
+		"invalid\tentry\n")
+
+	logs, err := (&cloudFrontDecoder{logger: zap.NewNop()}).decodeLogs(info, data)
+	require.NoError(t, err)
+	records := logs.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords()
+	require.Equal(t, 1, records.Len())
+	require.Equal(t, entry, records.At(0).Body().Str())
+	require.Equal(t, time.Date(2021, 2, 1, 17, 1, 2, 0, time.UTC), records.At(0).Timestamp().AsTime())
+	require.Equal(t, map[string]any{
+		"aws.s3.bucket":                              "bucket",
+		"aws.s3.key":                                 info.key,
+		"aws.cloudfront.x_edge_location":             "SEA19-C1",
+		"aws.cloudfront.sc_bytes":                    int64(392),
+		"client.address":                             "192.0.2.100",
+		"http.request.method":                        "GET",
+		"aws.cloudfront.cs_host":                     "d111111abcdef8.cloudfront.net",
+		"url.path":                                   "/index.html",
+		"http.response.status_code":                  int64(200),
+		"user_agent.original":                        "Mozilla/5.0 (Windows NT 10.0)",
+		"aws.cloudfront.x_edge_result_type":          "Hit",
+		"aws.cloudfront.x_edge_request_id":           "SOX4xwn4XV6Q4rgb7XiVGOHms_BGlTAC4KyHmureZmBNrjGdRLiNIQ==",
+		"server.address":                             "www.example.com",
+		"url.scheme":                                 "https",
+		"aws.cloudfront.cs_bytes":                    int64(157),
+		"aws.cloudfront.time_taken":                  0.001,
+		"aws.cloudfront.ssl_protocol":                "TLSv1.3",
+		"tls.cipher":                                 "TLS_AES_128_GCM_SHA256",
+		"aws.cloudfront.x_edge_response_result_type": "Hit",
+		"network.protocol.name":                      "http",
+		"network.protocol.version":                   "2.0",
+		"client.port":                                int64(11040),
+		"aws.cloudfront.time_to_first_byte":          0.001,
+		"aws.cloudfront.x_edge_detailed_result_type": "Hit",
+		"aws.cloudfront.sc_content_type":             "text/html",
+		"aws.cloudfront.sc_content_len":              int64(78),
+	}, records.At(0).Attributes().AsRaw())
+
+	logs, err = (&cloudFrontDecoder{logger: zap.NewNop()}).decodeLogs(info, []byte(entry+"\n"))
+	require.NoError(t, err)
+	require.Equal(t, 0, logs.LogRecordCount())
+}
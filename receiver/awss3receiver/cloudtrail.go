@@ -0,0 +1,109 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	conventions "go.opentelemetry.io/collector/semconv/v1.25.0"
+	"go.uber.org/zap"
+)
+
+// cloudTrailDecoder decodes CloudTrail log files, holding a JSON document with
+// a Records array of events, into a log record per event. The records are
+// grouped by the account and region of their events.
+type cloudTrailDecoder struct {
+	logger *zap.Logger
+}
+
+// cloudTrailFile is the layout of a CloudTrail log file.
+type cloudTrailFile struct {
+	Records []json.RawMessage `json:"Records"`
+}
+
+func (d *cloudTrailDecoder) decodeLogs(info objectInfo, data []byte) (plog.Logs, error) {
+	var file cloudTrailFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return plog.NewLogs(), fmt.Errorf("unable to parse CloudTrail log file: %w", err)
+	}
+	logs := newCloudResourceLogs()
+	for index, rawEvent := range file.Records {
+		document, err := parseJSONDocument(rawEvent)
+		event, isObject := document.(map[string]any)
+		if err != nil || !isObject {
+			d.logger.Warn("Skipping invalid CloudTrail event", zap.String("key", info.key), zap.Int("index", index), zap.Error(err))
+			continue
+		}
+		records := logs.records(stringField(event, "recipientAccountId"), stringField(event, "awsRegion"))
+		record := appendObjectRecord(records, info)
+		if err := setCloudTrailRecord(record, event); err != nil {
+			d.logger.Warn("Unable to parse the time of a CloudTrail event", zap.String("key", info.key), zap.Int("index", index), zap.Error(err))
+		}
+	}
+	return logs.logs, nil
+}
+
+// setCloudTrailRecord sets the body, attributes and timestamp of a record from a
+// CloudTrail event, the body being the whole event.
+func setCloudTrailRecord(record plog.LogRecord, event map[string]any) error {
+	attributes := record.Attributes()
+	if source := stringField(event, "eventSource"); source != "" {
+		attributes.PutStr("aws.cloudtrail.event_source", source)
+		attributes.PutStr(conventions.AttributeRPCService, strings.TrimSuffix(source, ".amazonaws.com"))
+	}
+	if name := stringField(event, "eventName"); name != "" {
+		attributes.PutStr(conventions.AttributeRPCSystem, "aws-api")
+		attributes.PutStr(conventions.AttributeRPCMethod, name)
+	}
+	putStringField(attributes, "aws.cloudtrail.event_id", event, "eventID")
+	putStringField(attributes, "aws.cloudtrail.event_type", event, "eventType")
+	putStringField(attributes, "aws.cloudtrail.event_category", event, "eventCategory")
+	putStringField(attributes, "aws.cloudtrail.error_code", event, "errorCode")
+	putStringField(attributes, "aws.cloudtrail.error_message", event, "errorMessage")
+	putStringField(attributes, conventions.AttributeAWSRequestID, event, "requestID")
+	putStringField(attributes, conventions.AttributeSourceAddress, event, "sourceIPAddress")
+	putStringField(attributes, conventions.AttributeUserAgentOriginal, event, "userAgent")
+	if readOnly, ok := event["readOnly"].(bool); ok {
+		attributes.PutBool("aws.cloudtrail.read_only", readOnly)
+	}
+	if identity, ok := event["userIdentity"].(map[string]any); ok {
+		putStringField(attributes, conventions.AttributeEnduserID, identity, "arn")
+		putStringField(attributes, "aws.cloudtrail.user_identity.type", identity, "type")
+		putStringField(attributes, "aws.cloudtrail.user_identity.principal_id", identity, "principalId")
+		putStringField(attributes, "aws.cloudtrail.user_identity.account_id", identity, "accountId")
+		putStringField(attributes, "aws.cloudtrail.user_identity.user_name", identity, "userName")
+		putStringField(attributes, "aws.cloudtrail.user_identity.access_key_id", identity, "accessKeyId")
+	}
+	_ = record.Body().FromRaw(event)
+	eventTime := stringField(event, "eventTime")
+	if eventTime == "" {
+		return nil
+	}
+	timestamp, err := time.Parse(time.RFC3339, eventTime)
+	if err != nil {
+		return err
+	}
+	record.SetTimestamp(pcommon.NewTimestampFromTime(timestamp))
+	return nil
+}
+
+// stringField returns the string value of a field of a document, or an empty
+// string if the field is missing or is not a string.
+func stringField(document map[string]any, name string) string {
+	value, _ := document[name].(string)
+	return value
+}
+
+// putStringField sets an attribute to the string value of a field of a document,
+// unless the field is missing or empty.
+func putStringField(attributes pcommon.Map, attribute string, document map[string]any, name string) {
+	if value := stringField(document, name); value != "" {
+		attributes.PutStr(attribute, value)
+	}
+}
@@ -0,0 +1,72 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func Test_cloudTrailDecoder(t *testing.T) {
+	info := objectInfo{bucket: "bucket", key: "AWSLogs/123456789012/CloudTrail/us-east-1/2021/02/01/trail.json"}
+	data := []byte(`{"Records": [
+		{"eventVersion": "1.08", "eventTime": "2021-02-01T17:00:00Z", "eventSource": "s3.amazonaws.com", "eventName": "GetObject",
+		 "awsRegion": "us-east-1", "recipientAccountId": "123456789012", "sourceIPAddress": "192.0.2.1", "userAgent": "aws-cli/2.0",
+		 "requestID": "REQ1", "eventID": "EV1", "readOnly": true, "eventType": "AwsApiCall", "eventCategory": "Data",
+		 "userIdentity": {"type": "IAMUser", "principalId": "AIDA1", "arn": "arn:aws:iam::123456789012:user/alice",
+		  "accountId": "123456789012", "accessKeyId": "AKIA1", "userName": "alice"}},
+		"invalid",
+		{"eventTime": "2021-02-01T17:05:00Z", "eventSource": "ec2.amazonaws.com", "eventName": "RunInstances",
+		 "awsRegion": "eu-west-1", "recipientAccountId": "123456789012", "errorCode": "AccessDenied", "errorMessage": "denied"},
+		{"eventTime": "2021-02-01T17:10:00Z", "eventSource": "s3.amazonaws.com", "eventName": "PutObject",
+		 "awsRegion": "us-east-1", "recipientAccountId": "123456789012"}
+	]}`)
+
+	logs, err := (&cloudTrailDecoder{logger: zap.NewNop()}).decodeLogs(info, data)
+	require.NoError(t, err)
+	require.Equal(t, 2, logs.ResourceLogs().Len())
+	require.Equal(t, map[string]any{
+		"cloud.provider":   "aws",
+		"cloud.account.id": "123456789012",
+		"cloud.region":     "us-east-1",
+	}, logs.ResourceLogs().At(0).Resource().Attributes().AsRaw())
+	require.Equal(t, "eu-west-1", logs.ResourceLogs().At(1).Resource().Attributes().AsRaw()["cloud.region"])
+
+	records := logs.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords()
+	require.Equal(t, 2, records.Len())
+	require.Equal(t, time.Date(2021, 2, 1, 17, 0, 0, 0, time.UTC), records.At(0).Timestamp().AsTime())
+	require.Equal(t, "GetObject", records.At(0).Body().Map().AsRaw()["eventName"])
+	require.Equal(t, map[string]any{
+		"aws.s3.bucket":                              "bucket",
+		"aws.s3.key":                                 info.key,
+		"rpc.system":                                 "aws-api",
+		"rpc.service":                                "s3",
+		"rpc.method":                                 "GetObject",
+		"aws.request_id":                             "REQ1",
+		"source.address":                             "192.0.2.1",
+		"user_agent.original":                        "aws-cli/2.0",
+		"enduser.id":                                 "arn:aws:iam::123456789012:user/alice",
+		"aws.cloudtrail.event_source":                "s3.amazonaws.com",
+		"aws.cloudtrail.event_id":                    "EV1",
+		"aws.cloudtrail.event_type":                  "AwsApiCall",
+		"aws.cloudtrail.event_category":              "Data",
+		"aws.cloudtrail.read_only":                   true,
+		"aws.cloudtrail.user_identity.type":          "IAMUser",
+		"aws.cloudtrail.user_identity.principal_id":  "AIDA1",
+		"aws.cloudtrail.user_identity.account_id":    "123456789012",
+		"aws.cloudtrail.user_identity.user_name":     "alice",
+		"aws.cloudtrail.user_identity.access_key_id": "AKIA1",
+	}, records.At(0).Attributes().AsRaw())
+	require.Equal(t, "PutObject", records.At(1).Attributes().AsRaw()["rpc.method"])
+
+	record := logs.ResourceLogs().At(1).ScopeLogs().At(0).LogRecords().At(0)
+	require.Equal(t, "AccessDenied", record.Attributes().AsRaw()["aws.cloudtrail.error_code"])
+	require.Equal(t, "denied", record.Attributes().AsRaw()["aws.cloudtrail.error_message"])
+
+	_, err = (&cloudTrailDecoder{logger: zap.NewNop()}).decodeLogs(info, []byte("not json"))
+	require.ErrorContains(t, err, "unable to parse CloudTrail log file")
+}
@@ -0,0 +1,50 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"os"
+	"sync"
+)
+
+// completionGroup tracks the receivers that shut down the collector once they have
+// finished reading, so that the collector is only asked to shut down once all of
+// them have finished.
+type completionGroup struct {
+	mu      sync.Mutex
+	pending int
+	// stop asks the collector to shut down.
+	stop func() error
+}
+
+// collectorCompletion is the completion group of all the receivers of the collector.
+var collectorCompletion = &completionGroup{stop: interruptProcess}
+
+func (g *completionGroup) add() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.pending++
+}
+
+// done records that a receiver has finished and asks the collector to shut down
+// if it was the last one. It reports whether the collector was asked to shut down.
+func (g *completionGroup) done() (bool, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.pending--
+	if g.pending > 0 {
+		return false, nil
+	}
+	return true, g.stop()
+}
+
+// interruptProcess interrupts the collector process, which the collector handles
+// by shutting down gracefully, draining its pipelines before exiting.
+func interruptProcess() error {
+	process, err := os.FindProcess(os.Getpid())
+	if err != nil {
+		return err
+	}
+	return process.Signal(os.Interrupt)
+}
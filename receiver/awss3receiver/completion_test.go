@@ -0,0 +1,30 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_completionGroup(t *testing.T) {
+	stops := 0
+	group := &completionGroup{stop: func() error {
+		stops++
+		return nil
+	}}
+	group.add()
+	group.add()
+
+	stopped, err := group.done()
+	require.NoError(t, err)
+	require.False(t, stopped)
+	require.Equal(t, 0, stops)
+
+	stopped, err = group.done()
+	require.NoError(t, err)
+	require.True(t, stopped)
+	require.Equal(t, 1, stops)
+}
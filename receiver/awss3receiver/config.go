@@ -4,38 +4,862 @@
 package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
 
 import (
+	"encoding/base64"
 	"errors"
 	"fmt"
+	"net/url"
+	"path"
+	"regexp"
+	"slices"
 	"strings"
 	"time"
+	"unicode/utf8"
 
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/lestrrat-go/strftime"
 	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config/configopaque"
+	"go.opentelemetry.io/collector/config/configtls"
 	"go.uber.org/multierr"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/stanza/operator"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/stanza/split"
 )
 
 // S3DownloaderConfig contains aws s3 downloader related config to controls things
 // like bucket, prefix, batching, connections, retries, etc.
 type S3DownloaderConfig struct {
-	Region              string `mapstructure:"region"`
+	Region               string              `mapstructure:"region"`
+	S3Bucket             string              `mapstructure:"s3_bucket"`
+	S3Prefix             string              `mapstructure:"s3_prefix"`
+	S3Partition          string              `mapstructure:"s3_partition"`
+	S3PartitionFormat    string              `mapstructure:"s3_partition_format"`
+	FilePrefix           string              `mapstructure:"file_prefix"`
+	Endpoint             string              `mapstructure:"endpoint"`
+	EndpointPartitionID  string              `mapstructure:"endpoint_partition_id"`
+	S3ForcePathStyle     bool                `mapstructure:"s3_force_path_style"`
+	UseFIPSEndpoint      bool                `mapstructure:"use_fips_endpoint"`
+	UseDualStackEndpoint bool                `mapstructure:"use_dualstack_endpoint"`
+	RoleARN              string              `mapstructure:"role_arn"`
+	ExternalID           string              `mapstructure:"external_id"`
+	RoleSessionName      string              `mapstructure:"role_session_name"`
+	RoleSessionDuration  time.Duration       `mapstructure:"role_session_duration"`
+	Inventory            *S3InventoryConfig  `mapstructure:"inventory"`
+	Versions             *S3VersionsConfig   `mapstructure:"versions"`
+	Restore              *S3RestoreConfig    `mapstructure:"restore"`
+	CompatibilityProfile string              `mapstructure:"compatibility_profile"`
+	SkipEmptyPartitions  bool                `mapstructure:"skip_empty_partitions"`
+	Buckets              []S3BucketConfig    `mapstructure:"buckets"`
+	BucketConcurrency    int                 `mapstructure:"bucket_concurrency"`
+	Prefetch             *S3PrefetchConfig   `mapstructure:"prefetch"`
+	PartitionConcurrency int                 `mapstructure:"partition_concurrency"`
+	RangedGet            *S3RangedGetConfig  `mapstructure:"ranged_get"`
+	Select               *S3SelectConfig     `mapstructure:"select"`
+	RateLimit            *S3RateLimitConfig  `mapstructure:"rate_limit"`
+	HTTPClient           *S3HTTPClientConfig `mapstructure:"http_client"`
+	// MaxKeys, if not zero, is the number of objects listed in each page of the
+	// listing of a partition, up to 1000.
+	MaxKeys int32 `mapstructure:"max_keys"`
+	// StartAfter, if set, skips the objects of the first partition read whose key
+	// is not after it, such as the objects read by an interrupted run.
+	StartAfter string `mapstructure:"start_after"`
+	// Credentials, if set, selects the source of the credentials of the requests
+	// to S3 instead of the default credential chain.
+	Credentials *S3CredentialsConfig `mapstructure:"credentials"`
+	// Auth, if set, is the ID of the extension whose AWS configuration provides
+	// the credentials, the HTTP client and the retryer of the requests to AWS
+	// instead of the credentials section.
+	Auth *component.ID `mapstructure:"auth"`
+	// Retry, if set, overrides the retry policy of the AWS SDK for the requests
+	// to S3.
+	Retry *S3RetryConfig `mapstructure:"retry"`
+	// IMDS, if set, configures the lookups of the credentials of the EC2 instance
+	// role from the instance metadata service.
+	IMDS *S3IMDSConfig `mapstructure:"imds"`
+	// SDKLog, if set, lists the events of the requests to S3 logged by the AWS SDK
+	// at the debug level of the logger of the receiver.
+	SDKLog []string `mapstructure:"sdk_log"`
+	// StartupCheck, if set, checks when the receiver starts that the bucket is in
+	// the region and that its objects can be listed and read.
+	StartupCheck bool `mapstructure:"startup_check"`
+	// Timeouts, if set, bounds the time of each call listing or retrieving
+	// objects, so that a hung request fails rather than stalls the run.
+	Timeouts *S3TimeoutsConfig `mapstructure:"timeouts"`
+	// ExpectedBucketOwner, if set, is the ID of the AWS account the buckets must
+	// belong to, the requests to the buckets of other accounts being denied.
+	ExpectedBucketOwner string `mapstructure:"expected_bucket_owner"`
+	// STS, if set, selects the region and the endpoint of the requests to STS
+	// assuming role_arn or the role of the web_identity credentials source.
+	STS *S3STSConfig `mapstructure:"sts"`
+
+	// SSECustomerKey, if set, is the customer-provided key the objects were
+	// encrypted with.
+	SSECustomerKey *S3SSECustomerKeyConfig `mapstructure:"sse_customer_key"`
+	// ClientSideEncryption, if set, decrypts the objects encrypted with the Amazon
+	// S3 Encryption Client before they are read.
+	ClientSideEncryption *S3ClientSideEncryptionConfig `mapstructure:"client_side_encryption"`
+
+	// authConfig is the AWS configuration of the Auth extension, set once the
+	// receiver is created.
+	authConfig *extensionConfig
+	// sdkLogger is the logger of the events of SDKLog, set once the receiver is
+	// created.
+	sdkLogger *zap.Logger
+}
+
+// S3BucketConfig is one of several buckets to retrieve data from. Settings that
+// are not set are inherited from the s3downloader configuration, except for the
+// external_id of a bucket with its own role_arn.
+type S3BucketConfig struct {
 	S3Bucket            string `mapstructure:"s3_bucket"`
 	S3Prefix            string `mapstructure:"s3_prefix"`
-	S3Partition         string `mapstructure:"s3_partition"`
-	FilePrefix          string `mapstructure:"file_prefix"`
+	Region              string `mapstructure:"region"`
+	RoleARN             string `mapstructure:"role_arn"`
+	ExternalID          string `mapstructure:"external_id"`
+	ExpectedBucketOwner string `mapstructure:"expected_bucket_owner"`
+}
+
+// bucketConfigs returns the downloader configuration of each bucket to retrieve data from.
+func (c S3DownloaderConfig) bucketConfigs() []S3DownloaderConfig {
+	if len(c.Buckets) == 0 {
+		return []S3DownloaderConfig{c}
+	}
+	configs := make([]S3DownloaderConfig, 0, len(c.Buckets))
+	for _, bucket := range c.Buckets {
+		bucketCfg := c
+		bucketCfg.Buckets = nil
+		bucketCfg.S3Bucket = bucket.S3Bucket
+		if bucket.S3Prefix != "" {
+			bucketCfg.S3Prefix = bucket.S3Prefix
+		}
+		if bucket.Region != "" {
+			bucketCfg.Region = bucket.Region
+		}
+		if bucket.RoleARN != "" {
+			bucketCfg.RoleARN = bucket.RoleARN
+			bucketCfg.ExternalID = bucket.ExternalID
+		}
+		if bucket.ExpectedBucketOwner != "" {
+			bucketCfg.ExpectedBucketOwner = bucket.ExpectedBucketOwner
+		}
+		configs = append(configs, bucketCfg)
+	}
+	return configs
+}
+
+// S3InventoryConfig identifies the S3 Inventory report listing the objects of the bucket.
+// Either the key of the report manifest or the prefix under which the reports are
+// delivered, in which case the most recent report is used, must be set.
+type S3InventoryConfig struct {
+	Bucket      string `mapstructure:"bucket"`
+	ManifestKey string `mapstructure:"manifest_key"`
+	Prefix      string `mapstructure:"prefix"`
+}
+
+// S3VersionsConfig enables reading, from a versioned bucket, the versions of the
+// objects that were current at a given time rather than the current objects.
+// The time defaults to endtime, or to the time of each listing in continuous mode.
+type S3VersionsConfig struct {
+	AsOf string `mapstructure:"as_of"`
+}
+
+// S3RestoreConfig enables restoring the objects archived in the S3 Glacier Flexible
+// Retrieval and S3 Glacier Deep Archive storage classes before retrieving them,
+// instead of failing to retrieve them.
+type S3RestoreConfig struct {
+	Tier         string        `mapstructure:"tier"`
+	Days         int32         `mapstructure:"days"`
+	Timeout      time.Duration `mapstructure:"timeout"`
+	PollInterval time.Duration `mapstructure:"poll_interval"`
+}
+
+// S3PrefetchConfig contains the configuration for retrieving the objects ahead of
+// their consumption.
+type S3PrefetchConfig struct {
+	Objects   int `mapstructure:"objects"`
+	Downloads int `mapstructure:"downloads"`
+	// Adaptive, if set, adapts the number of objects retrieved at the same time,
+	// starting from downloads.
+	Adaptive *S3AdaptiveDownloadsConfig `mapstructure:"adaptive"`
+}
+
+// S3AdaptiveDownloadsConfig contains the configuration for adapting the number of
+// objects retrieved at the same time to the latency of S3, to its throttling and
+// to the pace of the consumer.
+type S3AdaptiveDownloadsConfig struct {
+	// MinDownloads is the lowest number of objects retrieved at the same time, 1
+	// by default.
+	MinDownloads int `mapstructure:"min_downloads"`
+	// MaxDownloads is the highest number of objects retrieved at the same time, 32
+	// by default.
+	MaxDownloads int `mapstructure:"max_downloads"`
+	// TargetLatency is the time to receive the response to a retrieval above which
+	// fewer objects are retrieved at the same time, 500ms by default.
+	TargetLatency time.Duration `mapstructure:"target_latency"`
+}
+
+// S3RangedGetConfig contains the configuration for retrieving the large objects
+// in ranges fetched concurrently. The sizes are in bytes.
+type S3RangedGetConfig struct {
+	Threshold   int64 `mapstructure:"threshold"`
+	PartSize    int64 `mapstructure:"part_size"`
+	Concurrency int   `mapstructure:"concurrency"`
+}
+
+// S3SelectConfig contains the configuration for retrieving only the records of
+// the JSON and CSV objects matching an S3 Select SQL expression, such as
+// "SELECT * FROM S3Object s WHERE s.level = 'ERROR'".
+type S3SelectConfig struct {
+	Expression string `mapstructure:"expression"`
+	// InputFormat is the format of the selected objects, json_lines by default.
+	InputFormat string `mapstructure:"input_format"`
+}
+
+// S3RateLimitConfig contains the configuration for limiting the rate of the
+// requests to S3 and of the data downloaded, so that reading does not starve the
+// other clients of the bucket or saturate the network.
+type S3RateLimitConfig struct {
+	RequestsPerSecond float64 `mapstructure:"requests_per_second"`
+	// Burst is the number of requests sent at once after a pause, the number of
+	// requests allowed per second by default.
+	Burst          int   `mapstructure:"burst"`
+	BytesPerSecond int64 `mapstructure:"bytes_per_second"`
+}
+
+// S3HTTPClientConfig contains the settings of the HTTP client of the requests to
+// S3, overriding the defaults of the AWS SDK.
+type S3HTTPClientConfig struct {
+	// Timeout, if not zero, is the limit of the time of each request, the reading
+	// of the object included.
+	Timeout time.Duration `mapstructure:"timeout"`
+	// ConnectTimeout is the limit of the time to connect to S3, 30s by default.
+	ConnectTimeout time.Duration `mapstructure:"connect_timeout"`
+	// ResponseHeaderTimeout, if not zero, is the limit of the time waited for the
+	// headers of the response once the request has been sent.
+	ResponseHeaderTimeout time.Duration `mapstructure:"response_header_timeout"`
+	// IdleConnTimeout is the time the idle connections are kept open for, 90s by
+	// default.
+	IdleConnTimeout time.Duration `mapstructure:"idle_conn_timeout"`
+	// MaxIdleConnsPerHost is the number of idle connections kept open to each
+	// host, 10 by default.
+	MaxIdleConnsPerHost int `mapstructure:"max_idle_conns_per_host"`
+	// MaxConnsPerHost, if not zero, limits the number of connections to each host.
+	MaxConnsPerHost int `mapstructure:"max_conns_per_host"`
+	// DisableKeepAlives opens a new connection for each request.
+	DisableKeepAlives bool `mapstructure:"disable_keep_alives"`
+	// ProxyURL, if set, is the URL of the proxy the requests are sent through
+	// instead of the proxy of the HTTP_PROXY and HTTPS_PROXY environment variables.
+	ProxyURL string `mapstructure:"proxy_url"`
+	// NoProxy is the comma-separated list of the hosts, domains and networks
+	// reached without the proxy_url proxy, as in NO_PROXY.
+	NoProxy string `mapstructure:"no_proxy"`
+	// TLS, if set, overrides the TLS settings of the connections, such as to trust
+	// the private CA of an S3 gateway or to present a client certificate.
+	TLS *configtls.ClientConfig `mapstructure:"tls"`
+}
+
+// S3RetryConfig contains the retry policy of the requests to S3, overriding the
+// defaults of the AWS SDK.
+type S3RetryConfig struct {
+	// MaxAttempts is the number of attempts of each request, the first one
+	// included, 3 by default.
+	MaxAttempts int `mapstructure:"max_attempts"`
+	// Mode is standard, or adaptive to also delay the requests when S3 throttles
+	// them, standard by default.
+	Mode string `mapstructure:"mode"`
+	// MaxBackoff is the limit of the time waited before each retry, 20s by default.
+	MaxBackoff time.Duration `mapstructure:"max_backoff"`
+}
+
+// S3STSConfig contains the region and the endpoint of the requests to STS,
+// overriding the regional endpoint of the region of the requests to S3.
+type S3STSConfig struct {
+	// Region, if set, is the region of the regional endpoint of STS, or
+	// aws-global for the global endpoint.
+	Region string `mapstructure:"region"`
+	// Endpoint, if set, is the URL of the requests to STS instead of the endpoint
+	// of the region, such as the URL of an interface VPC endpoint.
+	Endpoint string `mapstructure:"endpoint"`
+}
+
+// S3TimeoutsConfig contains the limits of the time of the calls to S3, each
+// bounding a call with all its attempts and the waits between them.
+type S3TimeoutsConfig struct {
+	// ListObjects, if not zero, is the limit of the time of each page of the
+	// listings of objects.
+	ListObjects time.Duration `mapstructure:"list_objects"`
+	// GetObject, if not zero, is the limit of the time of each retrieval of an
+	// object, the reading of its body included.
+	GetObject time.Duration `mapstructure:"get_object"`
+}
+
+// S3IMDSConfig contains the settings of the lookups of the EC2 instance metadata
+// service (IMDS), the last source of the default credential chain, overriding the
+// defaults of the AWS SDK.
+type S3IMDSConfig struct {
+	// Disabled skips the lookups, such as on hosts which are not EC2 instances.
+	Disabled bool `mapstructure:"disabled"`
+	// Endpoint, if set, is the endpoint of the service instead of
+	// http://169.254.169.254, or of AWS_EC2_METADATA_SERVICE_ENDPOINT.
+	Endpoint string `mapstructure:"endpoint"`
+	// Timeout, if not zero, is the limit of the time of each attempt of the
+	// requests to the service.
+	Timeout time.Duration `mapstructure:"timeout"`
+	// MaxAttempts is the number of attempts of each request, the first one
+	// included, 3 by default.
+	MaxAttempts int `mapstructure:"max_attempts"`
+}
+
+// S3CredentialsConfig selects the source of the credentials of the requests to S3,
+// the role_arn role being assumed with these credentials, if set.
+type S3CredentialsConfig struct {
+	// Source is one of default, environment, static, profile, web_identity or
+	// container, static by default when an access key is set, and profile when a
+	// profile is set.
+	Source string `mapstructure:"source"`
+	// AccessKeyID, SecretAccessKey and SessionToken are the credentials of the
+	// static source, such as the keys issued by S3-compatible services.
+	AccessKeyID     configopaque.String `mapstructure:"access_key_id"`
+	SecretAccessKey configopaque.String `mapstructure:"secret_access_key"`
+	SessionToken    configopaque.String `mapstructure:"session_token"`
+	// AccessKeyIDFile, SecretAccessKeyFile and SessionTokenFile, if set, are the
+	// files the credentials of the static source are read from instead, again
+	// every ReloadInterval, 1m by default, so that rotated keys are used without
+	// restarting the receiver.
+	AccessKeyIDFile     string        `mapstructure:"access_key_id_file"`
+	SecretAccessKeyFile string        `mapstructure:"secret_access_key_file"`
+	SessionTokenFile    string        `mapstructure:"session_token_file"`
+	ReloadInterval      time.Duration `mapstructure:"reload_interval"`
+	// Profile is the name of the shared profile of the profile source, read from
+	// SharedCredentialsFiles and SharedConfigFiles, if set, instead of the default
+	// shared files.
+	Profile                string   `mapstructure:"profile"`
+	SharedCredentialsFiles []string `mapstructure:"shared_credentials_files"`
+	SharedConfigFiles      []string `mapstructure:"shared_config_files"`
+	// WebIdentityTokenFile and WebIdentityRoleARN are the token file and the role of
+	// the web_identity source, AWS_WEB_IDENTITY_TOKEN_FILE and AWS_ROLE_ARN by
+	// default, as set for IAM roles for service accounts.
+	WebIdentityTokenFile string `mapstructure:"web_identity_token_file"`
+	WebIdentityRoleARN   string `mapstructure:"web_identity_role_arn"`
+}
+
+// S3SSECustomerKeyConfig is the key of the objects encrypted with a customer-provided
+// key (SSE-C), sent along with the requests reading the objects.
+type S3SSECustomerKeyConfig struct {
+	// Key is the base64-encoded 256-bit key.
+	Key configopaque.String `mapstructure:"key"`
+	// KeyMD5 is the base64-encoded MD5 digest of the key, computed from the key
+	// if not set.
+	KeyMD5 string `mapstructure:"key_md5"`
+	// Algorithm is the encryption algorithm, AES256 by default.
+	Algorithm string `mapstructure:"algorithm"`
+}
+
+// S3ClientSideEncryptionConfig is the key wrapping the data keys of the objects
+// encrypted with the Amazon S3 Encryption Client. The objects without encryption
+// metadata are read as they are.
+type S3ClientSideEncryptionConfig struct {
+	// KMSKeyID is the ID, alias or ARN of the KMS key the data keys are
+	// encrypted with.
+	KMSKeyID string `mapstructure:"kms_key_id"`
+	// Key is the base64-encoded AES key the data keys are encrypted with,
+	// instead of a KMS key.
+	Key configopaque.String `mapstructure:"key"`
+}
+
+// SQSConfig contains the configuration for receiving S3 event notifications
+// from an SQS queue instead of retrieving data for a time range. The queue is
+// read with the credentials and transport of s3downloader.
+type SQSConfig struct {
+	QueueURL string `mapstructure:"queue_url"`
+	// Region is the region of the queue, the one of s3downloader by default.
+	Region              string `mapstructure:"region"`
 	Endpoint            string `mapstructure:"endpoint"`
-	EndpointPartitionID string `mapstructure:"endpoint_partition_id"`
-	S3ForcePathStyle    bool   `mapstructure:"s3_force_path_style"`
+	MaxNumberOfMessages int32  `mapstructure:"max_number_of_messages"`
+	WaitTimeSeconds     int32  `mapstructure:"wait_time_seconds"`
+	VisibilityTimeout   int32  `mapstructure:"visibility_timeout"`
+}
+
+// ManifestConfig identifies an object listing the objects to retrieve, instead
+// of retrieving data for a time range.
+type ManifestConfig struct {
+	Bucket string `mapstructure:"bucket"`
+	Key    string `mapstructure:"key"`
+	Format string `mapstructure:"format"`
+}
+
+// SignalConfig enables the ingestion of a telemetry type and overrides, for that
+// type, the layout of the keys of the objects to retrieve:
+// {s3_prefix}/{partition}/{file_prefix}{telemetry_name}{separator}.
+type SignalConfig struct {
+	// Enabled, if false, disables the ingestion of the telemetry type, which is
+	// enabled by default in the pipelines using the receiver.
+	Enabled           *bool   `mapstructure:"enabled"`
+	S3Prefix          string  `mapstructure:"s3_prefix"`
+	S3Partition       string  `mapstructure:"s3_partition"`
+	S3PartitionFormat string  `mapstructure:"s3_partition_format"`
+	FilePrefix        string  `mapstructure:"file_prefix"`
+	TelemetryName     string  `mapstructure:"telemetry_name"`
+	Separator         *string `mapstructure:"separator"`
+	// StartTime, if set, overrides starttime for the telemetry type, so that each
+	// telemetry type can resume from its own position.
+	StartTime string `mapstructure:"starttime"`
+	// Format is the format of the contents of the objects, otherwise selected
+	// according to their extension.
+	Format string `mapstructure:"format"`
+	// Framing, if set, splits the contents of the objects, holding several records,
+	// into records decoded one at a time.
+	Framing string `mapstructure:"framing"`
+	// BatchSize, if not zero, is the number of lines of the objects in a line
+	// delimited format decoded and sent at a time, their contents being
+	// decompressed and read as a stream instead of as a whole.
+	BatchSize int `mapstructure:"batch_size"`
+	// Encoding is the ID of the encoding extension unmarshaling the contents of the
+	// objects, which are otherwise decoded as OTLP.
+	Encoding *component.ID `mapstructure:"encoding"`
+	// FormatRules set the format and compression of the objects whose key matches
+	// their pattern, the first matching rule taking precedence over Format.
+	FormatRules []FormatRule `mapstructure:"format_rules"`
+}
+
+// FormatRule sets the format and compression of the objects whose key matches a
+// pattern.
+type FormatRule struct {
+	// Pattern is matched, as a shell file name pattern, against the whole key of
+	// the objects if it holds a slash, and against their base name otherwise.
+	Pattern string `mapstructure:"pattern"`
+	// Format is the format of the objects, selected as for the objects without a
+	// configured format if empty.
+	Format string `mapstructure:"format"`
+	// Compression is the compression of the objects, gzip, zstd, snappy, lz4 or
+	// none, detected from their extension and contents if empty.
+	Compression string `mapstructure:"compression"`
+}
+
+// LogsConfig is the SignalConfig of logs, along with the settings of the logs formats.
+type LogsConfig struct {
+	SignalConfig `mapstructure:",squash"`
+	// JSONLines maps the documents of the objects in the json_lines format to log records.
+	JSONLines JSONLinesConfig `mapstructure:"json_lines"`
+	// CSV maps the rows of the objects in the csv or tsv formats to log records.
+	CSV CSVConfig `mapstructure:"csv"`
+	// Avro maps the records of the objects in the avro format to log records.
+	Avro AvroConfig `mapstructure:"avro"`
+	// Text groups the lines of the objects in the text format into log records.
+	Text TextConfig `mapstructure:"text"`
+	// Syslog configures the parsing of the objects in the syslog format.
+	Syslog SyslogConfig `mapstructure:"syslog"`
+	// Operators, if any, are the stanza operators, as configured for the filelog
+	// receiver, the log records decoded from each object are run through.
+	Operators []operator.Config `mapstructure:"operators"`
+}
+
+// JSONLinesConfig maps each line of an object, an arbitrary JSON document, to a
+// log record.
+type JSONLinesConfig struct {
+	// BodyField is the field holding the body of the log records, whose other fields
+	// become attributes. The whole document is the body if empty.
+	BodyField string `mapstructure:"body_field"`
+	// TimestampField is the field holding the time of the log records, either a
+	// string or a number of seconds since the epoch.
+	TimestampField        string `mapstructure:"timestamp_field"`
+	TimestampLayoutConfig `mapstructure:",squash"`
+}
+
+// CSVConfig maps the rows of the objects in the csv or tsv formats to log records.
+type CSVConfig struct {
+	// Delimiter is the single character separating the fields, a comma for csv
+	// and a tab for tsv by default.
+	Delimiter string `mapstructure:"delimiter"`
+	// Columns are the names of the columns, read from the first row if empty.
+	Columns []string `mapstructure:"columns"`
+	// BodyColumn is the column holding the body of the log records. The body is
+	// the map of the columns to their values if empty.
+	BodyColumn string `mapstructure:"body_column"`
+	// AttributeColumns are the columns set as attributes, all the columns but
+	// the body and timestamp columns if empty and BodyColumn is set.
+	AttributeColumns []string `mapstructure:"attribute_columns"`
+	// TimestampColumn is the column holding the time of the log records.
+	TimestampColumn       string `mapstructure:"timestamp_column"`
+	TimestampLayoutConfig `mapstructure:",squash"`
+}
+
+// TextConfig groups the lines of text objects into log records, a record per
+// non-empty line by default.
+type TextConfig struct {
+	// Multiline, if it sets a line_start_pattern or line_end_pattern, recombines
+	// the lines between two matches of the pattern, such as the lines of a stack
+	// trace, into a single log record, as the multiline setting of the filelog
+	// receiver does.
+	Multiline split.Config `mapstructure:"multiline"`
+	// TimestampPattern, if set, is the regular expression whose first capturing
+	// group, in the first match in each log record, is its time.
+	TimestampPattern      string `mapstructure:"timestamp_pattern"`
+	TimestampLayoutConfig `mapstructure:",squash"`
+}
+
+// SyslogConfig configures the parsing of the lines of syslog objects.
+type SyslogConfig struct {
+	// Protocol is the protocol of the messages, rfc3164 or rfc5424. It is detected
+	// for each line if empty, the RFC 5424 messages having a version after their
+	// priority.
+	Protocol string `mapstructure:"protocol"`
+	// Location is the time zone of the RFC 3164 timestamps, UTC by default.
+	Location string `mapstructure:"location"`
+}
+
+// AvroConfig maps the records of Avro object container files to log records.
+type AvroConfig struct {
+	// BodyField is the field holding the body of the log records. The body is the
+	// whole record if empty.
+	BodyField string `mapstructure:"body_field"`
+	// AttributeFields are the fields set as attributes, all the fields but the
+	// body and timestamp fields if empty and BodyField is set.
+	AttributeFields []string `mapstructure:"attribute_fields"`
+	// TimestampField is the field holding the time of the log records, either of a
+	// timestamp logical type, a string or a number of seconds since the epoch.
+	TimestampField        string `mapstructure:"timestamp_field"`
+	TimestampLayoutConfig `mapstructure:",squash"`
+}
+
+// TimestampLayoutConfig sets how the timestamps of the log records of a format
+// are parsed.
+type TimestampLayoutConfig struct {
+	// TimestampLayout is the layout of the string timestamps, RFC 3339 if empty,
+	// or the unit of the epoch timestamps, s, ms, us or ns, seconds if empty.
+	TimestampLayout string `mapstructure:"timestamp_layout"`
+	// TimestampLayoutType is the type of TimestampLayout: gotime for a Go layout,
+	// strptime for strptime directives or epoch for a unit. gotime if empty.
+	TimestampLayoutType string `mapstructure:"timestamp_layout_type"`
+	// TimestampLocation is the IANA name of the time zone of the string timestamps
+	// without one, UTC if empty.
+	TimestampLocation string `mapstructure:"timestamp_location"`
+}
+
+// ScheduleConfig restricts the retrieval of objects to daily time windows, outside
+// of which the receiver pauses.
+type ScheduleConfig struct {
+	// Timezone is the IANA name of the time zone of the windows, the local time zone if empty.
+	Timezone string                 `mapstructure:"timezone"`
+	Windows  []ScheduleWindowConfig `mapstructure:"windows"`
+}
+
+// ScheduleWindowConfig is a time window, with start and end times in the 15:04 format,
+// starting on the given days of the week or every day if none are set.
+type ScheduleWindowConfig struct {
+	Start string   `mapstructure:"start"`
+	End   string   `mapstructure:"end"`
+	Days  []string `mapstructure:"days"`
+}
+
+// LoopConfig replays the objects repeatedly, for example to generate a sustained
+// load of production-shaped data.
+type LoopConfig struct {
+	// Count is the number of times the objects are replayed, unlimited if zero.
+	Count int `mapstructure:"count"`
+	// ShiftTimestamps shifts the timestamps of the telemetry of each pass by the
+	// time elapsed between starttime and the start of the pass.
+	ShiftTimestamps bool `mapstructure:"shift_timestamps"`
+}
+
+// CompletionConfig controls what the receiver does once all the objects have been read.
+type CompletionConfig struct {
+	// ShutdownCollector asks the collector to shut down, draining its pipelines,
+	// once all the objects have been read, so that one-shot replay jobs terminate.
+	ShutdownCollector bool `mapstructure:"shutdown_collector"`
+}
+
+// BackpressureConfig retries the telemetry refused by the next consumer, such as
+// a memory limiter, with an exponential backoff, pausing the retrieval of the
+// objects meanwhile rather than failing the read.
+type BackpressureConfig struct {
+	// InitialInterval is the time waited before the first retry, 1s by default.
+	InitialInterval time.Duration `mapstructure:"initial_interval"`
+	// MaxInterval is the limit of the time between the retries, doubled after
+	// each of them, 30s by default.
+	MaxInterval time.Duration `mapstructure:"max_interval"`
+	// MaxElapsedTime is the time after which the refused telemetry fails the
+	// read, the telemetry being retried until shutdown if zero.
+	MaxElapsedTime time.Duration `mapstructure:"max_elapsed_time"`
+}
+
+// BatchConfig merges the telemetry decoded from the objects before sending it to
+// the next consumer, for the buckets holding many small objects.
+type BatchConfig struct {
+	// MaxSize is the number of spans, log records or data points the batch is
+	// sent at, 8192 by default.
+	MaxSize int `mapstructure:"max_size"`
+	// MaxBytes, if not zero, is the size of the batch, encoded as OTLP protobuf,
+	// the batch is sent at.
+	MaxBytes int `mapstructure:"max_bytes"`
+	// Timeout is the time after which the batch is sent regardless of its size,
+	// 1s by default.
+	Timeout time.Duration `mapstructure:"timeout"`
+}
+
+// DeduplicationConfig skips the objects already processed within a sliding
+// window, such as the objects listed again because of overlapping time ranges or
+// of the lookback, or delivered again by SQS.
+type DeduplicationConfig struct {
+	// Storage is the storage extension persisting the processed objects across
+	// restarts. The processed objects are only held in memory without it.
+	Storage *component.ID `mapstructure:"storage"`
+	// Window is the time the processed objects are remembered for, 24h by
+	// default.
+	Window time.Duration `mapstructure:"window"`
+}
+
+// CheckpointConfig periodically writes the position of the receiver to a JSON
+// object in S3, read at startup to resume reading the time range from where the
+// previous run stopped.
+type CheckpointConfig struct {
+	S3Bucket string `mapstructure:"s3_bucket"`
+	// S3Prefix is the prefix of the checkpoint objects, one for each signal.
+	S3Prefix string `mapstructure:"s3_prefix"`
+	// Region is the region of the bucket, the one of s3downloader by default.
+	Region string `mapstructure:"region"`
+	// Interval is the time between the writes of the checkpoint, 1m by default.
+	// The checkpoint is also written on shutdown.
+	Interval time.Duration `mapstructure:"interval"`
+}
+
+// LeaseConfig coordinates the collectors running the same receiver for high
+// availability through a lease held in a DynamoDB table, so that only the
+// collector holding the lease reads the objects while the others stand by.
+type LeaseConfig struct {
+	// DynamoDB is the table holding the lease, whose partition key is the string
+	// attribute id.
+	DynamoDB DynamoDBConfig `mapstructure:"dynamodb"`
+	// Duration is the time the lease is held for without being renewed, 30s by
+	// default.
+	Duration time.Duration `mapstructure:"duration"`
+	// RenewInterval is the time between the renewals of the lease, and between the
+	// attempts of the standby collectors to acquire it, a third of the duration by
+	// default.
+	RenewInterval time.Duration `mapstructure:"renew_interval"`
+	// Owner identifies the collector holding the lease, its host name followed by
+	// a random suffix by default.
+	Owner string `mapstructure:"owner"`
+}
+
+// StateStoreConfig configures where the processed objects of the deduplication are
+// persisted instead of a storage extension, for the collectors without a persistent
+// volume. The checkpoints are not, being written to S3.
+type StateStoreConfig struct {
+	DynamoDB *DynamoDBConfig `mapstructure:"dynamodb"`
+}
+
+// DynamoDBConfig persists the state in a DynamoDB table, whose partition key is
+// the string attribute id. The processed objects are kept in an item each, which
+// claims the object and expires at the end of the window according to its ttl
+// attribute. The table is written with the credentials and transport of
+// s3downloader.
+type DynamoDBConfig struct {
+	TableName string `mapstructure:"table_name"`
+	// Region is the region of the table, the one of s3downloader by default.
+	Region   string `mapstructure:"region"`
+	Endpoint string `mapstructure:"endpoint"`
+	// KeyPrefix prefixes the ids of the items, for several receivers to share the
+	// table.
+	KeyPrefix string `mapstructure:"key_prefix"`
+}
+
+// NotificationsConfig configures the notification of the status of the ingest.
+type NotificationsConfig struct {
+	// OpAMP is the ID of the OpAMP extension the status notifications are sent through.
+	OpAMP *component.ID `mapstructure:"opampextension"`
+	// ProgressInterval, if set, is the interval the ingesting status is sent on
+	// while reading, along with the progress of the ingest.
+	ProgressInterval time.Duration `mapstructure:"progress_interval"`
+	// HeartbeatInterval, if set, is the interval the status is sent on whatever
+	// the state of the ingest, for the OpAMP server to tell a stalled receiver
+	// from a slow one.
+	HeartbeatInterval time.Duration `mapstructure:"heartbeat_interval"`
+	// Jobs accepts the time ranges submitted by the OpAMP server, read one after
+	// the other once the time range of the configuration, if any, has been read.
+	Jobs bool `mapstructure:"jobs"`
+	// ObjectFailures, if set, sends the failures of the objects which could not be
+	// processed.
+	ObjectFailures *ObjectFailuresConfig `mapstructure:"object_failures"`
+}
+
+// ObjectFailuresConfig limits the rate of the notifications of the objects which
+// failed to be processed.
+type ObjectFailuresConfig struct {
+	// PerSecond is the number of failures notified per second, 1 by default.
+	PerSecond float64 `mapstructure:"per_second"`
+	// Burst is the number of failures notified at once, 10 by default.
+	Burst int `mapstructure:"burst"`
 }
 
 // Config defines the configuration for the file receiver.
 type Config struct {
-	S3Downloader S3DownloaderConfig `mapstructure:"s3downloader"`
-	StartTime    string             `mapstructure:"starttime"`
-	EndTime      string             `mapstructure:"endtime"`
+	S3Downloader  S3DownloaderConfig   `mapstructure:"s3downloader"`
+	SQS           *SQSConfig           `mapstructure:"sqs"`
+	Manifest      *ManifestConfig      `mapstructure:"manifest"`
+	Logs          LogsConfig           `mapstructure:"logs"`
+	Metrics       SignalConfig         `mapstructure:"metrics"`
+	Traces        SignalConfig         `mapstructure:"traces"`
+	StartTime     string               `mapstructure:"starttime"`
+	EndTime       string               `mapstructure:"endtime"`
+	PollInterval  time.Duration        `mapstructure:"poll_interval"`
+	Lookback      time.Duration        `mapstructure:"lookback"`
+	ReplayOrder   string               `mapstructure:"replay_order"`
+	Schedule      *ScheduleConfig      `mapstructure:"schedule"`
+	Loop          *LoopConfig          `mapstructure:"loop"`
+	MaxDuration   time.Duration        `mapstructure:"max_duration"`
+	Completion    *CompletionConfig    `mapstructure:"completion"`
+	Backpressure  *BackpressureConfig  `mapstructure:"backpressure"`
+	Batch         *BatchConfig         `mapstructure:"batch"`
+	Deduplication *DeduplicationConfig `mapstructure:"deduplication"`
+	StateStore    *StateStoreConfig    `mapstructure:"state_store"`
+	Checkpoint    *CheckpointConfig    `mapstructure:"checkpoint"`
+	Lease         *LeaseConfig         `mapstructure:"lease"`
+	Notifications NotificationsConfig  `mapstructure:"notifications"`
 }
 
 const (
 	S3PartitionMinute = "minute"
 	S3PartitionHour   = "hour"
+	S3PartitionDay    = "day"
+)
+
+// errInvalidPartition is returned for an s3_partition other than the supported
+// granularities.
+var errInvalidPartition = errors.New("s3_partition must be one of 'day', 'hour' or 'minute'")
+
+func isValidPartition(partition string) bool {
+	return partition == S3PartitionDay || partition == S3PartitionHour || partition == S3PartitionMinute
+}
+
+const (
+	// CompatibilityProfileAWS targets Amazon S3.
+	CompatibilityProfileAWS = "aws"
+	// CompatibilityProfileS3Compatible targets S3-compatible storage services such as
+	// MinIO, Ceph or Cloudflare R2, which do not implement the AWS-only features of S3.
+	CompatibilityProfileS3Compatible = "s3_compatible"
+)
+
+const (
+	// CredentialsSourceDefault uses the default credential chain of the AWS SDK.
+	CredentialsSourceDefault = "default"
+	// CredentialsSourceEnvironment uses the access keys of the environment.
+	CredentialsSourceEnvironment = "environment"
+	// CredentialsSourceStatic uses the access keys of the configuration.
+	CredentialsSourceStatic = "static"
+	// CredentialsSourceProfile uses a profile of the shared configuration files.
+	CredentialsSourceProfile = "profile"
+	// CredentialsSourceWebIdentity assumes a role with a web identity token, such as
+	// the token of an EKS service account.
+	CredentialsSourceWebIdentity = "web_identity"
+	// CredentialsSourceContainer uses the credentials of the ECS task or the EKS pod.
+	CredentialsSourceContainer = "container"
+)
+
+const (
+	TimestampLayoutTypeGotime   = "gotime"
+	TimestampLayoutTypeStrptime = "strptime"
+	TimestampLayoutTypeEpoch    = "epoch"
+)
+
+const (
+	// RetryModeStandard retries the failed requests with an exponential backoff.
+	RetryModeStandard = "standard"
+	// RetryModeAdaptive also delays the requests once S3 throttles them.
+	RetryModeAdaptive = "adaptive"
+)
+
+const (
+	// SDKLogSigning logs the canonical requests and the strings to sign.
+	SDKLogSigning = "signing"
+	// SDKLogRetries logs the attempts of the requests and their retries.
+	SDKLogRetries = "retries"
+	// SDKLogRequest logs the requests without their bodies.
+	SDKLogRequest = "request"
+	// SDKLogRequestWithBody logs the requests with their bodies.
+	SDKLogRequestWithBody = "request_with_body"
+	// SDKLogResponse logs the responses, their request IDs included, without their bodies.
+	SDKLogResponse = "response"
+	// SDKLogResponseWithBody logs the responses with their bodies.
+	SDKLogResponseWithBody = "response_with_body"
+)
+
+const (
+	ReplayOrderOldestFirst = "oldest_first"
+	ReplayOrderNewestFirst = "newest_first"
+)
+
+const (
+	FormatOTLPJSON          = "otlp_json"
+	FormatOTLPProto         = "otlp_proto"
+	FormatOTLPParquet       = "otlp_parquet"
+	FormatOTelArrow         = "otel_arrow"
+	FormatJSONLines         = "json_lines"
+	FormatText              = "text"
+	FormatCSV               = "csv"
+	FormatTSV               = "tsv"
+	FormatAvro              = "avro"
+	FormatCloudTrail        = "cloudtrail"
+	FormatVPCFlowLogs       = "vpc_flow_logs"
+	FormatELBAccessLogs     = "elb_access_logs"
+	FormatCloudFront        = "cloudfront"
+	FormatS3AccessLogs      = "s3_access_logs"
+	FormatRoute53Resolver   = "route53_resolver"
+	FormatOCSF              = "ocsf"
+	FormatRDS               = "rds"
+	FormatKubernetesAudit   = "kubernetes_audit"
+	FormatSyslog            = "syslog"
+	FormatFluentBit         = "fluent_bit"
+	FormatMetricStreamsJSON = "metric_streams_json"
+	FormatMetricStreamsOTLP = "metric_streams_otlp"
+	FormatEMF               = "emf"
+	FormatCUR               = "cur"
+	FormatStorageLens       = "storage_lens"
+	FormatXRay              = "xray"
+	FormatJaegerProto       = "jaeger_proto"
+	FormatJaegerJSON        = "jaeger_json"
+	FormatZipkinJSON        = "zipkin_json"
+)
+
+const (
+	CompressionGzip   = "gzip"
+	CompressionZstd   = "zstd"
+	CompressionSnappy = "snappy"
+	CompressionLZ4    = "lz4"
+	CompressionNone   = "none"
+)
+
+const (
+	FramingNewline       = "newline"
+	FramingJSON          = "json"
+	FramingSizeDelimited = "size_delimited"
+)
+
+// tracesFormats are the formats of traces objects, besides the OTLP formats.
+var tracesFormats = []string{FormatOTelArrow, FormatXRay, FormatJaegerProto, FormatJaegerJSON, FormatZipkinJSON}
+
+// metricsFormats are the formats of metrics objects, besides the OTLP formats.
+var metricsFormats = []string{FormatOTLPParquet, FormatOTelArrow, FormatMetricStreamsJSON, FormatMetricStreamsOTLP, FormatEMF, FormatCUR, FormatStorageLens}
+
+// logsFormats are the formats of logs objects, besides the OTLP formats.
+var logsFormats = []string{
+	FormatOTLPParquet, FormatOTelArrow, FormatJSONLines, FormatText, FormatCSV, FormatTSV, FormatAvro,
+	FormatCloudTrail, FormatVPCFlowLogs, FormatELBAccessLogs, FormatCloudFront, FormatS3AccessLogs,
+	FormatRoute53Resolver, FormatOCSF, FormatRDS, FormatKubernetesAudit, FormatSyslog, FormatFluentBit,
+}
+
+const (
+	ManifestFormatJSON        = "json"
+	ManifestFormatCSV         = "csv"
+	ManifestFormatCUR         = "cur"
+	ManifestFormatStorageLens = "storage_lens"
+)
+
+const (
+	defaultSQSMaxNumberOfMessages = 10
+	defaultSQSWaitTimeSeconds     = 20
 )
 
 func createDefaultConfig() component.Config {
@@ -48,23 +872,274 @@ func createDefaultConfig() component.Config {
 	}
 }
 
+func (c *Config) signalConfig(telemetryType string) SignalConfig {
+	switch telemetryType {
+	case "logs":
+		return c.Logs.SignalConfig
+	case "metrics":
+		return c.Metrics
+	default:
+		return c.Traces
+	}
+}
+
+// forTelemetryType returns the configuration with the s3_prefix, s3_partition,
+// s3_partition_format and file_prefix overrides of the telemetry type applied.
+func (c *Config) forTelemetryType(telemetryType string) *Config {
+	signalCfg := c.signalConfig(telemetryType)
+	cfg := *c
+	if signalCfg.S3Prefix != "" {
+		cfg.S3Downloader.S3Prefix = signalCfg.S3Prefix
+	}
+	if signalCfg.S3Partition != "" {
+		cfg.S3Downloader.S3Partition = signalCfg.S3Partition
+	}
+	if signalCfg.S3PartitionFormat != "" {
+		cfg.S3Downloader.S3PartitionFormat = signalCfg.S3PartitionFormat
+	}
+	if signalCfg.FilePrefix != "" {
+		cfg.S3Downloader.FilePrefix = signalCfg.FilePrefix
+	}
+	if signalCfg.StartTime != "" {
+		cfg.StartTime = signalCfg.StartTime
+	}
+	return &cfg
+}
+
+// lineFormats returns the formats of the objects of the telemetry type whose
+// lines are decoded independently of each other, which can be read batch_size
+// lines at a time. The text lines are not when they are recombined into records.
+func (c *Config) lineFormats(telemetryType string) []string {
+	formats := []string{FormatOTLPJSON}
+	if telemetryType != "logs" {
+		return formats
+	}
+	formats = append(formats, FormatJSONLines, FormatSyslog, FormatFluentBit)
+	if c.Logs.Text.Multiline.LineStartPattern == "" && c.Logs.Text.Multiline.LineEndPattern == "" {
+		formats = append(formats, FormatText)
+	}
+	return formats
+}
+
+func (c *Config) objectNaming(telemetryType string) objectNaming {
+	signalCfg := c.signalConfig(telemetryType)
+	return objectNaming{
+		telemetryName: signalCfg.TelemetryName,
+		separator:     signalCfg.Separator,
+	}
+}
+
 func (c Config) Validate() error {
 	var errs error
-	if c.S3Downloader.S3Bucket == "" {
+	if c.SQS != nil && c.Manifest != nil {
+		errs = multierr.Append(errs, errors.New("sqs and manifest cannot be used together"))
+	}
+	if c.Schedule != nil {
+		errs = multierr.Append(errs, c.Schedule.validate())
+	}
+	if c.Loop != nil {
+		errs = multierr.Append(errs, c.Loop.validate(c))
+	}
+	if c.MaxDuration < 0 {
+		errs = multierr.Append(errs, errors.New("max_duration must not be negative"))
+	}
+	if c.Completion != nil {
+		errs = multierr.Append(errs, c.Completion.validate(c))
+	}
+	errs = multierr.Append(errs, c.Notifications.validate(c))
+	if c.Backpressure != nil {
+		errs = multierr.Append(errs, c.Backpressure.validate())
+	}
+	if c.Batch != nil {
+		errs = multierr.Append(errs, c.Batch.validate(c))
+	}
+	if c.Deduplication != nil {
+		errs = multierr.Append(errs, c.Deduplication.validate(c))
+	}
+	if c.StateStore != nil {
+		errs = multierr.Append(errs, c.StateStore.validate(c))
+	}
+	if c.Checkpoint != nil {
+		errs = multierr.Append(errs, c.Checkpoint.validate(c))
+	}
+	if c.Lease != nil {
+		errs = multierr.Append(errs, c.Lease.validate())
+	}
+	if c.S3Downloader.Prefetch != nil {
+		errs = multierr.Append(errs, c.S3Downloader.Prefetch.validate(c))
+	}
+	if c.S3Downloader.RangedGet != nil {
+		errs = multierr.Append(errs, c.S3Downloader.RangedGet.validate())
+	}
+	if c.S3Downloader.Select != nil {
+		errs = multierr.Append(errs, c.S3Downloader.Select.validate())
+	}
+	if c.S3Downloader.RateLimit != nil {
+		errs = multierr.Append(errs, c.S3Downloader.RateLimit.validate())
+	}
+	if c.S3Downloader.HTTPClient != nil {
+		errs = multierr.Append(errs, c.S3Downloader.HTTPClient.validate())
+	}
+	if c.S3Downloader.Retry != nil {
+		errs = multierr.Append(errs, c.S3Downloader.Retry.validate())
+	}
+	if c.S3Downloader.IMDS != nil {
+		errs = multierr.Append(errs, c.S3Downloader.IMDS.validate())
+	}
+	if c.S3Downloader.Timeouts != nil {
+		errs = multierr.Append(errs, c.S3Downloader.Timeouts.validate())
+	}
+	sdkLogEvents := []string{SDKLogSigning, SDKLogRetries, SDKLogRequest, SDKLogRequestWithBody, SDKLogResponse, SDKLogResponseWithBody}
+	for _, event := range c.S3Downloader.SDKLog {
+		if !slices.Contains(sdkLogEvents, event) {
+			errs = multierr.Append(errs, fmt.Errorf("sdk_log events must be one of '%s', '%s', '%s', '%s', '%s' or '%s'", SDKLogSigning,
+				SDKLogRetries, SDKLogRequest, SDKLogRequestWithBody, SDKLogResponse, SDKLogResponseWithBody))
+			break
+		}
+	}
+	if c.S3Downloader.Credentials != nil {
+		errs = multierr.Append(errs, c.S3Downloader.Credentials.validate())
+	}
+	if c.S3Downloader.Auth != nil && c.S3Downloader.Credentials != nil {
+		errs = multierr.Append(errs, errors.New("auth and credentials cannot be used together"))
+	}
+	if c.S3Downloader.SSECustomerKey != nil {
+		errs = multierr.Append(errs, c.S3Downloader.SSECustomerKey.validate())
+	}
+	if c.S3Downloader.ClientSideEncryption != nil {
+		errs = multierr.Append(errs, c.S3Downloader.ClientSideEncryption.validate())
+		if c.S3Downloader.RangedGet != nil || c.S3Downloader.Select != nil {
+			errs = multierr.Append(errs, errors.New("client_side_encryption cannot be used together with ranged_get or select"))
+		}
+	}
+	for _, bucketCfg := range c.S3Downloader.bucketConfigs() {
+		errs = multierr.Append(errs, validatePartitions(bucketCfg))
+		errs = multierr.Append(errs, validateOutposts(bucketCfg))
+		if bucketCfg.ExpectedBucketOwner != "" && !isAccountID(bucketCfg.ExpectedBucketOwner) {
+			errs = multierr.Append(errs, fmt.Errorf("expected_bucket_owner %s is not a 12-digit AWS account ID", bucketCfg.ExpectedBucketOwner))
+		}
+	}
+	if c.S3Downloader.StartupCheck && (c.SQS != nil || c.Manifest != nil) {
+		errs = multierr.Append(errs, errors.New("startup_check cannot be used together with sqs or manifest"))
+	}
+	if c.S3Downloader.StartAfter != "" && (c.SQS != nil || c.Manifest != nil || c.S3Downloader.Inventory != nil || len(c.S3Downloader.Buckets) > 0) {
+		errs = multierr.Append(errs, errors.New("start_after cannot be used together with sqs, manifest, inventory or buckets"))
+	}
+	if err := c.Traces.validate(tracesFormats); err != nil {
+		errs = multierr.Append(errs, fmt.Errorf("traces: %w", err))
+	}
+	if err := c.Metrics.validate(metricsFormats); err != nil {
+		errs = multierr.Append(errs, fmt.Errorf("metrics: %w", err))
+	}
+	if err := c.Logs.validate(); err != nil {
+		errs = multierr.Append(errs, fmt.Errorf("logs: %w", err))
+	}
+	if len(c.S3Downloader.Buckets) > 0 && (c.SQS != nil || c.Manifest != nil || c.S3Downloader.Inventory != nil) {
+		errs = multierr.Append(errs, errors.New("buckets cannot be used together with sqs, manifest or inventory"))
+	}
+	errs = multierr.Append(errs, c.S3Downloader.validateCompatibilityProfile())
+	errs = multierr.Append(errs, c.S3Downloader.validateAssumeRole())
+	if (c.S3Downloader.UseFIPSEndpoint || c.S3Downloader.UseDualStackEndpoint) && c.S3Downloader.Endpoint != "" {
+		errs = multierr.Append(errs, errors.New("use_fips_endpoint and use_dualstack_endpoint cannot be used together with endpoint"))
+	}
+	// The objects of sqs and manifest are not listed, from a time range.
+	if c.SQS != nil {
+		return multierr.Append(errs, c.SQS.validate())
+	}
+	if c.Manifest != nil {
+		return multierr.Append(errs, c.Manifest.validate(c.S3Downloader))
+	}
+	switch {
+	case len(c.S3Downloader.Buckets) > 0:
+		if c.S3Downloader.S3Bucket != "" {
+			errs = multierr.Append(errs, errors.New("s3_bucket and buckets cannot be used together"))
+		}
+		for i, bucket := range c.S3Downloader.Buckets {
+			if bucket.S3Bucket == "" {
+				errs = multierr.Append(errs, fmt.Errorf("buckets[%d]: bucket is required", i))
+			}
+		}
+	case c.S3Downloader.S3Bucket == "":
 		errs = multierr.Append(errs, errors.New("bucket is required"))
 	}
-	if c.S3Downloader.S3Partition != S3PartitionHour && c.S3Downloader.S3Partition != S3PartitionMinute {
-		errs = multierr.Append(errs, errors.New("s3_partition must be either 'hour' or 'minute'"))
+	if err := validateBucketARN(c.S3Downloader.S3Bucket, c.S3Downloader.S3ForcePathStyle); err != nil {
+		errs = multierr.Append(errs, err)
+	}
+	for i, bucket := range c.S3Downloader.Buckets {
+		if err := validateBucketARN(bucket.S3Bucket, c.S3Downloader.S3ForcePathStyle); err != nil {
+			errs = multierr.Append(errs, fmt.Errorf("buckets[%d]: %w", i, err))
+		}
+	}
+	if c.S3Downloader.BucketConcurrency < 0 {
+		errs = multierr.Append(errs, errors.New("bucket_concurrency must not be negative"))
+	}
+	if c.S3Downloader.PartitionConcurrency < 0 {
+		errs = multierr.Append(errs, errors.New("partition_concurrency must not be negative"))
+	}
+	if c.S3Downloader.PartitionConcurrency > 1 && c.PollInterval > 0 {
+		errs = multierr.Append(errs, errors.New("partition_concurrency cannot be used with poll_interval"))
+	}
+	if !isValidPartition(c.S3Downloader.S3Partition) {
+		errs = multierr.Append(errs, errInvalidPartition)
+	}
+	if err := validatePartitionFormat(c.S3Downloader.S3PartitionFormat); err != nil {
+		errs = multierr.Append(errs, err)
+	}
+	if c.S3Downloader.SkipEmptyPartitions && c.usesPartitionFormat() {
+		errs = multierr.Append(errs, errors.New("skip_empty_partitions cannot be used together with s3_partition_format"))
+	}
+	if c.S3Downloader.Inventory != nil {
+		errs = multierr.Append(errs, c.S3Downloader.Inventory.validate())
+	}
+	if c.S3Downloader.Versions != nil {
+		if c.S3Downloader.Inventory != nil {
+			errs = multierr.Append(errs, errors.New("versions and inventory cannot be used together"))
+		}
+		if c.S3Downloader.Versions.AsOf != "" {
+			if _, err := parseTime(c.S3Downloader.Versions.AsOf, "versions as_of"); err != nil {
+				errs = multierr.Append(errs, err)
+			}
+		}
+	}
+	if c.S3Downloader.Restore != nil {
+		errs = multierr.Append(errs, c.S3Downloader.Restore.validate())
 	}
+	if c.S3Downloader.MaxKeys < 0 || c.S3Downloader.MaxKeys > 1000 {
+		errs = multierr.Append(errs, errors.New("max_keys must be between 0 and 1000"))
+	}
+	// With jobs, the time range of the configuration is optional.
+	jobsOnly := c.Notifications.Jobs && c.StartTime == ""
 	if c.StartTime == "" {
-		errs = multierr.Append(errs, errors.New("starttime is required"))
+		if !jobsOnly {
+			errs = multierr.Append(errs, errors.New("starttime is required"))
+		}
 	} else {
 		if _, err := parseTime(c.StartTime, "starttime"); err != nil {
 			errs = multierr.Append(errs, err)
 		}
 	}
+	if c.PollInterval < 0 {
+		errs = multierr.Append(errs, errors.New("poll_interval must not be negative"))
+	}
+	if c.Lookback < 0 {
+		errs = multierr.Append(errs, errors.New("lookback must not be negative"))
+	}
+	if c.Lookback > 0 && c.PollInterval == 0 {
+		errs = multierr.Append(errs, errors.New("lookback requires poll_interval"))
+	}
+	switch c.ReplayOrder {
+	case "", ReplayOrderOldestFirst:
+	case ReplayOrderNewestFirst:
+		if c.PollInterval > 0 {
+			errs = multierr.Append(errs, errors.New("replay_order newest_first cannot be used with poll_interval"))
+		}
+	default:
+		errs = multierr.Append(errs, fmt.Errorf("replay_order must be either '%s' or '%s'", ReplayOrderOldestFirst, ReplayOrderNewestFirst))
+	}
 	if c.EndTime == "" {
-		errs = multierr.Append(errs, errors.New("endtime is required"))
+		if c.PollInterval == 0 && !jobsOnly {
+			errs = multierr.Append(errs, errors.New("endtime is required"))
+		}
 	} else {
 		if _, err := parseTime(c.EndTime, "endtime"); err != nil {
 			errs = multierr.Append(errs, err)
@@ -73,7 +1148,682 @@ func (c Config) Validate() error {
 	return errs
 }
 
+// usesPartitionFormat reports whether the objects of any telemetry type are listed
+// according to an s3_partition_format.
+func (c Config) usesPartitionFormat() bool {
+	return c.S3Downloader.S3PartitionFormat != "" || c.Traces.S3PartitionFormat != "" ||
+		c.Metrics.S3PartitionFormat != "" || c.Logs.S3PartitionFormat != ""
+}
+
+func validatePartitionFormat(format string) error {
+	if format == "" {
+		return nil
+	}
+	if _, err := strftime.New(format); err != nil {
+		return fmt.Errorf("invalid s3_partition_format: %w", err)
+	}
+	return nil
+}
+
+func (c SignalConfig) enabled() bool {
+	return c.Enabled == nil || *c.Enabled
+}
+
+// validate validates the signal configuration, whose format is either an OTLP
+// format or one of the given formats.
+func (c SignalConfig) validate(formats []string) error {
+	if c.S3Partition != "" && !isValidPartition(c.S3Partition) {
+		return errInvalidPartition
+	}
+	if c.StartTime != "" {
+		if _, err := parseTime(c.StartTime, "starttime"); err != nil {
+			return err
+		}
+	}
+	if err := validatePartitionFormat(c.S3PartitionFormat); err != nil {
+		return err
+	}
+	formats = append([]string{FormatOTLPJSON, FormatOTLPProto}, formats...)
+	if c.Format != "" && !slices.Contains(formats, c.Format) {
+		return fmt.Errorf("format must be one of '%s'", strings.Join(formats, "', '"))
+	}
+	if c.Format != "" && c.Encoding != nil {
+		return errors.New("format and encoding cannot be used together")
+	}
+	if c.Framing != "" && !slices.Contains([]string{FramingNewline, FramingJSON, FramingSizeDelimited}, c.Framing) {
+		return fmt.Errorf("framing must be one of '%s', '%s' or '%s'", FramingNewline, FramingJSON, FramingSizeDelimited)
+	}
+	if c.BatchSize < 0 {
+		return errors.New("batch_size must not be negative")
+	}
+	for i, rule := range c.FormatRules {
+		if err := rule.validate(formats); err != nil {
+			return fmt.Errorf("format_rules[%d]: %w", i, err)
+		}
+	}
+	return nil
+}
+
+func (r FormatRule) validate(formats []string) error {
+	if r.Pattern == "" {
+		return errors.New("pattern is required")
+	}
+	if _, err := path.Match(r.Pattern, ""); err != nil {
+		return fmt.Errorf("invalid pattern %q: %w", r.Pattern, err)
+	}
+	if r.Format != "" && !slices.Contains(formats, r.Format) {
+		return fmt.Errorf("format must be one of '%s'", strings.Join(formats, "', '"))
+	}
+	if r.Compression != "" && !slices.Contains([]string{CompressionGzip, CompressionZstd, CompressionSnappy, CompressionLZ4, CompressionNone}, r.Compression) {
+		return fmt.Errorf("compression must be one of '%s', '%s', '%s', '%s' or '%s'", CompressionGzip, CompressionZstd, CompressionSnappy, CompressionLZ4, CompressionNone)
+	}
+	return nil
+}
+
+func (c LogsConfig) validate() error {
+	if err := c.SignalConfig.validate(logsFormats); err != nil {
+		return err
+	}
+	if len(c.Operators) > 0 {
+		if _, _, err := buildOperators(c.Operators, component.TelemetrySettings{Logger: zap.NewNop()}); err != nil {
+			return fmt.Errorf("operators: %w", err)
+		}
+	}
+	if c.usesFormat(FormatJSONLines) {
+		if _, err := c.JSONLines.parser(); err != nil {
+			return fmt.Errorf("json_lines: %w", err)
+		}
+	}
+	if c.usesFormat(FormatAvro) {
+		if _, err := c.Avro.parser(); err != nil {
+			return fmt.Errorf("avro: %w", err)
+		}
+	}
+	if c.usesFormat(FormatText) {
+		if _, err := c.Text.splitFunc(); err != nil {
+			return fmt.Errorf("text multiline: %w", err)
+		}
+		if err := c.Text.validate(); err != nil {
+			return fmt.Errorf("text: %w", err)
+		}
+	}
+	if c.usesFormat(FormatSyslog) {
+		if _, err := newSyslogDecoder(c.Syslog, zap.NewNop()).buildParsers(); err != nil {
+			return fmt.Errorf("syslog: %w", err)
+		}
+	}
+	if c.usesFormat(FormatCSV) || c.usesFormat(FormatTSV) {
+		return c.CSV.validate()
+	}
+	return nil
+}
+
+// usesFormat reports whether the format of the signal, or of any of its format
+// rules, is format.
+func (c SignalConfig) usesFormat(format string) bool {
+	return c.Format == format || slices.ContainsFunc(c.FormatRules, func(rule FormatRule) bool {
+		return rule.Format == format
+	})
+}
+
+func (c CSVConfig) validate() error {
+	if c.Delimiter != "" && utf8.RuneCountInString(c.Delimiter) != 1 {
+		return errors.New("csv delimiter must be a single character")
+	}
+	if _, err := c.parser(); err != nil {
+		return fmt.Errorf("csv: %w", err)
+	}
+	return nil
+}
+
+func (c TextConfig) validate() error {
+	if _, err := regexp.Compile(c.TimestampPattern); err != nil {
+		return fmt.Errorf("invalid timestamp_pattern: %w", err)
+	}
+	_, err := c.parser()
+	return err
+}
+
+func (c ScheduleConfig) validate() error {
+	if len(c.Windows) == 0 {
+		return errors.New("schedule windows are required")
+	}
+	_, err := newIngestSchedule(c)
+	return err
+}
+
+func (c LoopConfig) validate(cfg Config) error {
+	if cfg.SQS != nil || cfg.PollInterval > 0 {
+		return errors.New("loop cannot be used together with sqs or poll_interval")
+	}
+	if c.Count < 0 {
+		return errors.New("loop count must not be negative")
+	}
+	if c.ShiftTimestamps && cfg.StartTime == "" {
+		return errors.New("loop shift_timestamps requires starttime")
+	}
+	return nil
+}
+
+func (c CompletionConfig) validate(cfg Config) error {
+	if cfg.SQS != nil || (cfg.PollInterval > 0 && cfg.EndTime == "") {
+		return errors.New("completion cannot be used together with sqs, or with poll_interval without endtime")
+	}
+	if cfg.Loop != nil && cfg.Loop.Count == 0 {
+		return errors.New("completion requires a loop count")
+	}
+	return nil
+}
+
+func (c NotificationsConfig) validate(cfg Config) error {
+	if c.ProgressInterval < 0 {
+		return errors.New("notifications progress_interval must not be negative")
+	}
+	if c.ProgressInterval > 0 && c.OpAMP == nil {
+		return errors.New("notifications progress_interval requires opampextension")
+	}
+	if c.HeartbeatInterval < 0 {
+		return errors.New("notifications heartbeat_interval must not be negative")
+	}
+	if c.HeartbeatInterval > 0 && c.OpAMP == nil {
+		return errors.New("notifications heartbeat_interval requires opampextension")
+	}
+	if c.ObjectFailures != nil {
+		if c.OpAMP == nil {
+			return errors.New("notifications object_failures requires opampextension")
+		}
+		if c.ObjectFailures.PerSecond < 0 || c.ObjectFailures.Burst < 0 {
+			return errors.New("notifications object_failures per_second and burst must not be negative")
+		}
+	}
+	if !c.Jobs {
+		return nil
+	}
+	if c.OpAMP == nil {
+		return errors.New("notifications jobs requires opampextension")
+	}
+	if cfg.SQS != nil || cfg.Manifest != nil || cfg.Checkpoint != nil || cfg.Lease != nil || cfg.Loop != nil || cfg.Completion != nil || cfg.PollInterval > 0 {
+		return errors.New("notifications jobs cannot be used together with sqs, manifest, checkpoint, lease, loop, completion or poll_interval")
+	}
+	return nil
+}
+
+func (c BackpressureConfig) validate() error {
+	if c.InitialInterval < 0 || c.MaxInterval < 0 || c.MaxElapsedTime < 0 {
+		return errors.New("backpressure initial_interval, max_interval and max_elapsed_time must not be negative")
+	}
+	if c.MaxInterval > 0 && c.InitialInterval > c.MaxInterval {
+		return errors.New("backpressure initial_interval must not be longer than the max_interval")
+	}
+	return nil
+}
+
+func (c BatchConfig) validate(cfg Config) error {
+	// The objects count as read once their telemetry is added to the batch, before
+	// it is sent.
+	if cfg.SQS != nil || cfg.Deduplication != nil || cfg.Checkpoint != nil {
+		return errors.New("batch cannot be used together with sqs, deduplication or checkpoint")
+	}
+	if c.MaxSize < 0 || c.MaxBytes < 0 || c.Timeout < 0 {
+		return errors.New("batch max_size, max_bytes and timeout must not be negative")
+	}
+	return nil
+}
+
+func (c DeduplicationConfig) validate(cfg Config) error {
+	if cfg.Loop != nil {
+		return errors.New("deduplication cannot be used together with loop")
+	}
+	if c.Window < 0 {
+		return errors.New("deduplication window must not be negative")
+	}
+	return nil
+}
+
+func (c CheckpointConfig) validate(cfg Config) error {
+	if c.S3Bucket == "" {
+		return errors.New("checkpoint s3_bucket is required")
+	}
+	if c.Interval < 0 {
+		return errors.New("checkpoint interval must not be negative")
+	}
+	if cfg.SQS != nil || cfg.Manifest != nil || len(cfg.S3Downloader.Buckets) > 0 || cfg.Loop != nil {
+		return errors.New("checkpoint cannot be used together with sqs, manifest, buckets or loop")
+	}
+	return nil
+}
+
+func (c LeaseConfig) validate() error {
+	if c.DynamoDB.TableName == "" {
+		return errors.New("lease dynamodb table_name is required")
+	}
+	if c.Duration < 0 || c.RenewInterval < 0 {
+		return errors.New("lease duration and renew_interval must not be negative")
+	}
+	if c.RenewInterval > 0 && c.RenewInterval >= c.leaseDuration() {
+		return errors.New("lease renew_interval must be shorter than the duration")
+	}
+	return nil
+}
+
+// leaseDuration returns the duration of the lease, defaulted.
+func (c LeaseConfig) leaseDuration() time.Duration {
+	if c.Duration == 0 {
+		return defaultLeaseDuration
+	}
+	return c.Duration
+}
+
+func (c StateStoreConfig) validate(cfg Config) error {
+	if c.DynamoDB == nil {
+		return errors.New("state_store requires dynamodb")
+	}
+	if c.DynamoDB.TableName == "" {
+		return errors.New("state_store dynamodb table_name is required")
+	}
+	if cfg.Deduplication == nil {
+		return errors.New("state_store requires deduplication")
+	}
+	if cfg.Deduplication.Storage != nil {
+		return errors.New("state_store cannot be used together with deduplication storage")
+	}
+	return nil
+}
+
+func (c SQSConfig) validate() error {
+	var errs error
+	if c.QueueURL == "" {
+		errs = multierr.Append(errs, errors.New("sqs queue_url is required"))
+	}
+	if c.MaxNumberOfMessages < 0 || c.MaxNumberOfMessages > 10 {
+		errs = multierr.Append(errs, errors.New("sqs max_number_of_messages must be between 1 and 10"))
+	}
+	if c.WaitTimeSeconds < 0 || c.WaitTimeSeconds > 20 {
+		errs = multierr.Append(errs, errors.New("sqs wait_time_seconds must be between 0 and 20"))
+	}
+	if c.VisibilityTimeout < 0 {
+		errs = multierr.Append(errs, errors.New("sqs visibility_timeout must not be negative"))
+	}
+	return errs
+}
+
+func (c ManifestConfig) validate(downloader S3DownloaderConfig) error {
+	var errs error
+	if c.Bucket == "" && downloader.S3Bucket == "" {
+		errs = multierr.Append(errs, errors.New("manifest bucket or s3_bucket is required"))
+	}
+	if c.Key == "" {
+		errs = multierr.Append(errs, errors.New("manifest key is required"))
+	}
+	if _, err := c.format(); err != nil {
+		errs = multierr.Append(errs, err)
+	}
+	return errs
+}
+
+// format returns the format of the manifest, inferred from the extension of its key if not configured.
+func (c ManifestConfig) format() (string, error) {
+	switch {
+	case c.Format == ManifestFormatJSON || c.Format == ManifestFormatCSV || c.Format == ManifestFormatCUR || c.Format == ManifestFormatStorageLens:
+		return c.Format, nil
+	case c.Format != "":
+		return "", fmt.Errorf("manifest format must be one of '%s', '%s', '%s' or '%s'", ManifestFormatJSON, ManifestFormatCSV, ManifestFormatCUR, ManifestFormatStorageLens)
+	case strings.HasSuffix(c.Key, "-Manifest.json"):
+		return ManifestFormatCUR, nil
+	case strings.HasSuffix(c.Key, ".json"):
+		return ManifestFormatJSON, nil
+	case strings.HasSuffix(c.Key, ".csv"):
+		return ManifestFormatCSV, nil
+	}
+	return "", errors.New("manifest format must be set when the key has neither a .json nor a .csv extension")
+}
+
+func (c S3DownloaderConfig) validateCompatibilityProfile() error {
+	switch c.CompatibilityProfile {
+	case "", CompatibilityProfileAWS:
+		return nil
+	case CompatibilityProfileS3Compatible:
+		if c.Endpoint == "" {
+			return errors.New("endpoint is required with the s3_compatible compatibility_profile")
+		}
+		return nil
+	}
+	return fmt.Errorf("compatibility_profile must be either '%s' or '%s'", CompatibilityProfileAWS, CompatibilityProfileS3Compatible)
+}
+
+// The bounds of the duration of the sessions of an assumed role.
+const (
+	minRoleSessionDuration = 15 * time.Minute
+	maxRoleSessionDuration = 12 * time.Hour
+)
+
+func (c S3DownloaderConfig) validateAssumeRole() error {
+	roleARN := c.RoleARN != ""
+	for i, bucket := range c.Buckets {
+		if bucket.ExternalID != "" && bucket.RoleARN == "" {
+			return fmt.Errorf("buckets[%d]: external_id requires role_arn", i)
+		}
+		roleARN = roleARN || bucket.RoleARN != ""
+	}
+	if !roleARN && (c.ExternalID != "" || c.RoleSessionName != "" || c.RoleSessionDuration != 0) {
+		return errors.New("external_id, role_session_name and role_session_duration require role_arn")
+	}
+	if c.STS != nil {
+		if !roleARN && (c.Credentials == nil || c.Credentials.source() != CredentialsSourceWebIdentity) {
+			return errors.New("sts requires role_arn or the web_identity credentials source")
+		}
+		if err := c.STS.validate(); err != nil {
+			return err
+		}
+	}
+	if c.RoleSessionDuration != 0 && (c.RoleSessionDuration < minRoleSessionDuration || c.RoleSessionDuration > maxRoleSessionDuration) {
+		return fmt.Errorf("role_session_duration must be between %v and %v", minRoleSessionDuration, maxRoleSessionDuration)
+	}
+	return nil
+}
+
+func (c S3RestoreConfig) validate() error {
+	var errs error
+	switch c.Tier {
+	case "", string(types.TierStandard), string(types.TierBulk), string(types.TierExpedited):
+	default:
+		errs = multierr.Append(errs, fmt.Errorf("restore tier must be one of '%s', '%s' or '%s'", types.TierStandard, types.TierBulk, types.TierExpedited))
+	}
+	if c.Days < 0 {
+		errs = multierr.Append(errs, errors.New("restore days must not be negative"))
+	}
+	if c.Timeout < 0 {
+		errs = multierr.Append(errs, errors.New("restore timeout must not be negative"))
+	}
+	if c.PollInterval < 0 {
+		errs = multierr.Append(errs, errors.New("restore poll_interval must not be negative"))
+	}
+	return errs
+}
+
+func (c S3PrefetchConfig) validate(cfg Config) error {
+	var errs error
+	if c.Objects < 0 {
+		errs = multierr.Append(errs, errors.New("prefetch objects must not be negative"))
+	}
+	if c.Downloads < 0 {
+		errs = multierr.Append(errs, errors.New("prefetch downloads must not be negative"))
+	}
+	if c.Adaptive != nil {
+		errs = multierr.Append(errs, c.Adaptive.validate())
+	}
+	if cfg.SQS != nil || cfg.Manifest != nil {
+		errs = multierr.Append(errs, errors.New("prefetch cannot be used together with sqs or manifest"))
+	}
+	return errs
+}
+
+func (c S3AdaptiveDownloadsConfig) validate() error {
+	if c.MinDownloads < 0 || c.MaxDownloads < 0 || c.TargetLatency < 0 {
+		return errors.New("prefetch adaptive min_downloads, max_downloads and target_latency must not be negative")
+	}
+	if c.MaxDownloads > 0 && c.MinDownloads > c.MaxDownloads {
+		return errors.New("prefetch adaptive min_downloads must not be greater than max_downloads")
+	}
+	return nil
+}
+
+func (c S3RangedGetConfig) validate() error {
+	var errs error
+	if c.Threshold < 0 || c.PartSize < 0 || c.Concurrency < 0 {
+		errs = multierr.Append(errs, errors.New("ranged_get threshold, part_size and concurrency must not be negative"))
+	}
+	if c.Threshold > 0 && c.PartSize > c.Threshold {
+		errs = multierr.Append(errs, errors.New("ranged_get part_size must not be greater than the threshold"))
+	}
+	return errs
+}
+
+func (c S3SelectConfig) validate() error {
+	var errs error
+	if c.Expression == "" {
+		errs = multierr.Append(errs, errors.New("select expression is required"))
+	}
+	switch c.InputFormat {
+	case "", SelectInputJSONLines, SelectInputJSON, SelectInputCSV:
+	default:
+		errs = multierr.Append(errs, fmt.Errorf("select input_format must be one of '%s', '%s' or '%s'", SelectInputJSONLines, SelectInputJSON, SelectInputCSV))
+	}
+	return errs
+}
+
+func (c S3RateLimitConfig) validate() error {
+	var errs error
+	if c.RequestsPerSecond < 0 || c.Burst < 0 || c.BytesPerSecond < 0 {
+		errs = multierr.Append(errs, errors.New("rate_limit requests_per_second, burst and bytes_per_second must not be negative"))
+	}
+	if c.RequestsPerSecond == 0 && c.BytesPerSecond == 0 {
+		errs = multierr.Append(errs, errors.New("rate_limit requires requests_per_second or bytes_per_second"))
+	}
+	if c.Burst > 0 && c.RequestsPerSecond == 0 {
+		errs = multierr.Append(errs, errors.New("rate_limit burst requires requests_per_second"))
+	}
+	return errs
+}
+
+func (c S3HTTPClientConfig) validate() error {
+	if c.Timeout < 0 || c.ConnectTimeout < 0 || c.ResponseHeaderTimeout < 0 || c.IdleConnTimeout < 0 {
+		return errors.New("http_client timeout, connect_timeout, response_header_timeout and idle_conn_timeout must not be negative")
+	}
+	if c.MaxIdleConnsPerHost < 0 || c.MaxConnsPerHost < 0 {
+		return errors.New("http_client max_idle_conns_per_host and max_conns_per_host must not be negative")
+	}
+	if c.ProxyURL != "" {
+		proxyURL, err := url.Parse(c.ProxyURL)
+		if err != nil || (proxyURL.Scheme != "http" && proxyURL.Scheme != "https" && proxyURL.Scheme != "socks5") || proxyURL.Host == "" {
+			return errors.New("http_client proxy_url must be an http, https or socks5 URL")
+		}
+	} else if c.NoProxy != "" {
+		return errors.New("http_client no_proxy requires proxy_url")
+	}
+	if c.TLS != nil {
+		if err := c.TLS.Validate(); err != nil {
+			return fmt.Errorf("http_client tls: %w", err)
+		}
+	}
+	return nil
+}
+
+func (c S3RetryConfig) validate() error {
+	if c.MaxAttempts < 0 || c.MaxBackoff < 0 {
+		return errors.New("retry max_attempts and max_backoff must not be negative")
+	}
+	if c.Mode != "" && c.Mode != RetryModeStandard && c.Mode != RetryModeAdaptive {
+		return fmt.Errorf("retry mode must be '%s' or '%s'", RetryModeStandard, RetryModeAdaptive)
+	}
+	return nil
+}
+
+func (c S3STSConfig) validate() error {
+	if c.Endpoint != "" {
+		endpoint, err := url.Parse(c.Endpoint)
+		if err != nil || (endpoint.Scheme != "http" && endpoint.Scheme != "https") || endpoint.Host == "" {
+			return errors.New("sts endpoint must be an http or https URL")
+		}
+	}
+	return nil
+}
+
+func (c S3TimeoutsConfig) validate() error {
+	if c.ListObjects < 0 || c.GetObject < 0 {
+		return errors.New("timeouts list_objects and get_object must not be negative")
+	}
+	return nil
+}
+
+func (c S3IMDSConfig) validate() error {
+	if c.Timeout < 0 || c.MaxAttempts < 0 {
+		return errors.New("imds timeout and max_attempts must not be negative")
+	}
+	if c.Endpoint != "" {
+		endpoint, err := url.Parse(c.Endpoint)
+		if err != nil || (endpoint.Scheme != "http" && endpoint.Scheme != "https") || endpoint.Host == "" {
+			return errors.New("imds endpoint must be an http or https URL")
+		}
+	}
+	return nil
+}
+
+// source returns the source of the credentials.
+func (c S3CredentialsConfig) source() string {
+	switch {
+	case c.Source != "":
+		return c.Source
+	case c.AccessKeyID != "" || c.AccessKeyIDFile != "":
+		return CredentialsSourceStatic
+	case c.Profile != "":
+		return CredentialsSourceProfile
+	}
+	return CredentialsSourceDefault
+}
+
+// hasFiles tells whether credentials of the static source are read from files.
+func (c S3CredentialsConfig) hasFiles() bool {
+	return c.AccessKeyIDFile != "" || c.SecretAccessKeyFile != "" || c.SessionTokenFile != ""
+}
+
+func (c S3CredentialsConfig) validate() error {
+	source := c.source()
+	switch source {
+	case CredentialsSourceDefault, CredentialsSourceEnvironment, CredentialsSourceStatic, CredentialsSourceProfile,
+		CredentialsSourceWebIdentity, CredentialsSourceContainer:
+	default:
+		return fmt.Errorf("credentials source must be one of '%s', '%s', '%s', '%s', '%s' or '%s'", CredentialsSourceDefault,
+			CredentialsSourceEnvironment, CredentialsSourceStatic, CredentialsSourceProfile, CredentialsSourceWebIdentity, CredentialsSourceContainer)
+	}
+	if source == CredentialsSourceStatic {
+		if (c.AccessKeyID == "" && c.AccessKeyIDFile == "") || (c.SecretAccessKey == "" && c.SecretAccessKeyFile == "") {
+			return errors.New("credentials access_key_id and secret_access_key are required with the static source")
+		}
+		if (c.AccessKeyID != "" && c.AccessKeyIDFile != "") || (c.SecretAccessKey != "" && c.SecretAccessKeyFile != "") ||
+			(c.SessionToken != "" && c.SessionTokenFile != "") {
+			return errors.New("credentials access_key_id, secret_access_key and session_token cannot be used together with their file")
+		}
+		if c.ReloadInterval < 0 {
+			return errors.New("credentials reload_interval must not be negative")
+		}
+		if c.ReloadInterval > 0 && !c.hasFiles() {
+			return errors.New("credentials reload_interval requires access_key_id_file, secret_access_key_file or session_token_file")
+		}
+	} else if c.AccessKeyID != "" || c.SecretAccessKey != "" || c.SessionToken != "" {
+		return errors.New("credentials access_key_id, secret_access_key and session_token require the static source")
+	} else if c.hasFiles() || c.ReloadInterval != 0 {
+		return errors.New("credentials access_key_id_file, secret_access_key_file, session_token_file and reload_interval require the static source")
+	}
+	if source == CredentialsSourceProfile {
+		if c.Profile == "" {
+			return errors.New("credentials profile is required with the profile source")
+		}
+	} else if c.Profile != "" || len(c.SharedCredentialsFiles) > 0 || len(c.SharedConfigFiles) > 0 {
+		return errors.New("credentials profile, shared_credentials_files and shared_config_files require the profile source")
+	}
+	if source != CredentialsSourceWebIdentity && (c.WebIdentityTokenFile != "" || c.WebIdentityRoleARN != "") {
+		return errors.New("credentials web_identity_token_file and web_identity_role_arn require the web_identity source")
+	}
+	return nil
+}
+
+func (c S3SSECustomerKeyConfig) validate() error {
+	key, err := base64.StdEncoding.DecodeString(string(c.Key))
+	if err != nil || len(key) != 32 {
+		return errors.New("sse_customer_key key must be a base64-encoded 256-bit key")
+	}
+	if c.KeyMD5 != "" && c.KeyMD5 != sseCustomerKeyMD5(string(c.Key)) {
+		return errors.New("sse_customer_key key_md5 is not the base64-encoded MD5 digest of the key")
+	}
+	if c.Algorithm != "" && c.Algorithm != defaultSSECustomerAlgorithm {
+		return fmt.Errorf("sse_customer_key algorithm must be '%s'", defaultSSECustomerAlgorithm)
+	}
+	return nil
+}
+
+func (c S3ClientSideEncryptionConfig) validate() error {
+	if (c.KMSKeyID == "") == (c.Key == "") {
+		return errors.New("client_side_encryption requires either kms_key_id or key")
+	}
+	if c.Key != "" {
+		key, err := base64.StdEncoding.DecodeString(string(c.Key))
+		if err != nil || (len(key) != 16 && len(key) != 24 && len(key) != 32) {
+			return errors.New("client_side_encryption key must be a base64-encoded 128, 192 or 256-bit key")
+		}
+	}
+	return nil
+}
+
+func (c S3InventoryConfig) validate() error {
+	var errs error
+	if c.Bucket == "" {
+		errs = multierr.Append(errs, errors.New("inventory bucket is required"))
+	}
+	if (c.ManifestKey == "") == (c.Prefix == "") {
+		errs = multierr.Append(errs, errors.New("exactly one of inventory manifest_key and prefix must be set"))
+	}
+	return errs
+}
+
+// validateBucketARN checks that a bucket given as an ARN is an access point, Object
+// Lambda access point, Multi-Region Access Point or S3 on Outposts access point
+// ARN, which S3 accepts in place of a bucket name. Such ARNs are addressed with
+// virtual hosted-style requests only.
+func validateBucketARN(bucket string, forcePathStyle bool) error {
+	if !arn.IsARN(bucket) {
+		return nil
+	}
+	bucketARN, err := arn.Parse(bucket)
+	if err != nil {
+		return fmt.Errorf("invalid bucket ARN %s: %w", bucket, err)
+	}
+	if bucketARN.Service == "s3-outposts" {
+		// The objects of an Outposts bucket are only reached through the access
+		// points of the bucket, outpost/{outpost ID}/accesspoint/{name}.
+		resource := strings.FieldsFunc(bucketARN.Resource, func(r rune) bool { return r == '/' || r == ':' })
+		if len(resource) != 4 || resource[0] != "outpost" || resource[2] != "accesspoint" {
+			return fmt.Errorf("bucket ARN %s is not an S3 on Outposts access point ARN", bucket)
+		}
+	} else if (bucketARN.Service != "s3" && bucketARN.Service != "s3-object-lambda") ||
+		(!strings.HasPrefix(bucketARN.Resource, "accesspoint/") && !strings.HasPrefix(bucketARN.Resource, "accesspoint:")) {
+		return fmt.Errorf("bucket ARN %s is not an access point ARN", bucket)
+	}
+	if forcePathStyle {
+		return fmt.Errorf("s3_force_path_style cannot be used with the access point ARN %s", bucket)
+	}
+	return nil
+}
+
+// isOutpostsARN tells whether bucket is the ARN of an S3 on Outposts access
+// point.
+func isOutpostsARN(bucket string) bool {
+	bucketARN, err := arn.Parse(bucket)
+	return err == nil && bucketARN.Service == "s3-outposts"
+}
+
+// validateOutposts checks that the features of cfg are supported by S3 on
+// Outposts, if its bucket is an S3 on Outposts access point ARN.
+func validateOutposts(cfg S3DownloaderConfig) error {
+	if !isOutpostsARN(cfg.S3Bucket) {
+		return nil
+	}
+	if cfg.UseFIPSEndpoint || cfg.UseDualStackEndpoint {
+		return fmt.Errorf("use_fips_endpoint and use_dualstack_endpoint cannot be used with the S3 on Outposts access point ARN %s", cfg.S3Bucket)
+	}
+	if cfg.Select != nil || cfg.Restore != nil {
+		return fmt.Errorf("select and restore cannot be used with the S3 on Outposts access point ARN %s", cfg.S3Bucket)
+	}
+	return nil
+}
+
 func parseTime(timeStr, configName string) (time.Time, error) {
+	if c, ok, err := parseResumeToken(timeStr); ok {
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid resume token for %s: %w", configName, err)
+		}
+		return c.Position, nil
+	}
 	layouts := []string{"2006-01-02 15:04", time.DateOnly}
 
 	for _, layout := range layouts {
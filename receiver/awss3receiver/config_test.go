@@ -6,10 +6,12 @@ package awss3receiver
 import (
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config/configtls"
 	"go.opentelemetry.io/collector/confmap/confmaptest"
 
 	"github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver/internal/metadata"
@@ -38,9 +40,635 @@ func TestConfig_Validate_Valid(t *testing.T) {
 	assert.NoError(t, cfg.Validate())
 }
 
+func TestConfig_Validate_PollInterval(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.S3Downloader.S3Bucket = "abucket"
+	cfg.StartTime = "2024-01-01"
+	assert.EqualError(t, cfg.Validate(), "endtime is required")
+
+	cfg.PollInterval = time.Minute
+	assert.NoError(t, cfg.Validate())
+
+	cfg.PollInterval = -time.Minute
+	assert.EqualError(t, cfg.Validate(), "poll_interval must not be negative")
+}
+
+func TestConfig_Validate_Lookback(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.S3Downloader.S3Bucket = "abucket"
+	cfg.StartTime = "2024-01-01"
+	cfg.PollInterval = time.Minute
+	cfg.Lookback = 15 * time.Minute
+	assert.NoError(t, cfg.Validate())
+
+	cfg.Lookback = -time.Minute
+	assert.EqualError(t, cfg.Validate(), "lookback must not be negative")
+
+	cfg.Lookback = time.Minute
+	cfg.PollInterval = 0
+	cfg.EndTime = "2024-01-02"
+	assert.EqualError(t, cfg.Validate(), "lookback requires poll_interval")
+}
+
+func TestConfig_Validate_ReplayOrder(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.S3Downloader.S3Bucket = "abucket"
+	cfg.StartTime = "2024-01-01"
+	cfg.EndTime = "2024-01-02"
+	cfg.ReplayOrder = ReplayOrderNewestFirst
+	assert.NoError(t, cfg.Validate())
+
+	cfg.ReplayOrder = "random"
+	assert.EqualError(t, cfg.Validate(), "replay_order must be either 'oldest_first' or 'newest_first'")
+
+	cfg.ReplayOrder = ReplayOrderNewestFirst
+	cfg.PollInterval = time.Minute
+	assert.EqualError(t, cfg.Validate(), "replay_order newest_first cannot be used with poll_interval")
+}
+
+func TestConfig_Validate_Loop(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.S3Downloader.S3Bucket = "abucket"
+	cfg.StartTime = "2024-01-01"
+	cfg.EndTime = "2024-01-02"
+	cfg.Loop = &LoopConfig{Count: 3, ShiftTimestamps: true}
+	assert.NoError(t, cfg.Validate())
+
+	cfg.Loop.Count = -1
+	assert.EqualError(t, cfg.Validate(), "loop count must not be negative")
+
+	cfg.Loop.Count = 0
+	cfg.PollInterval = time.Minute
+	assert.EqualError(t, cfg.Validate(), "loop cannot be used together with sqs or poll_interval")
+
+	cfg.PollInterval = 0
+	cfg.StartTime = ""
+	cfg.Manifest = &ManifestConfig{Key: "manifest.json"}
+	assert.EqualError(t, cfg.Validate(), "loop shift_timestamps requires starttime")
+}
+
+func TestConfig_Validate_MaxDuration(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.S3Downloader.S3Bucket = "abucket"
+	cfg.StartTime = "2024-01-01"
+	cfg.EndTime = "2024-01-02"
+	cfg.MaxDuration = 4 * time.Hour
+	assert.NoError(t, cfg.Validate())
+
+	cfg.MaxDuration = -time.Hour
+	assert.EqualError(t, cfg.Validate(), "max_duration must not be negative")
+}
+
+func TestConfig_Validate_Prefetch(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.S3Downloader.S3Bucket = "abucket"
+	cfg.StartTime = "2024-01-01"
+	cfg.EndTime = "2024-01-02"
+	cfg.S3Downloader.Prefetch = &S3PrefetchConfig{}
+	assert.NoError(t, cfg.Validate())
+
+	cfg.S3Downloader.Prefetch = &S3PrefetchConfig{Objects: -1, Downloads: -1}
+	assert.EqualError(t, cfg.Validate(), "prefetch objects must not be negative; prefetch downloads must not be negative")
+
+	cfg.S3Downloader.Prefetch = &S3PrefetchConfig{Adaptive: &S3AdaptiveDownloadsConfig{MinDownloads: 2, MaxDownloads: 64}}
+	assert.NoError(t, cfg.Validate())
+
+	cfg.S3Downloader.Prefetch.Adaptive = &S3AdaptiveDownloadsConfig{MinDownloads: 8, MaxDownloads: 4}
+	assert.EqualError(t, cfg.Validate(), "prefetch adaptive min_downloads must not be greater than max_downloads")
+
+	cfg.S3Downloader.Prefetch.Adaptive = &S3AdaptiveDownloadsConfig{TargetLatency: -time.Second}
+	assert.EqualError(t, cfg.Validate(), "prefetch adaptive min_downloads, max_downloads and target_latency must not be negative")
+
+	cfg.S3Downloader.Prefetch = &S3PrefetchConfig{}
+	cfg.Manifest = &ManifestConfig{Key: "manifest.json"}
+	assert.EqualError(t, cfg.Validate(), "prefetch cannot be used together with sqs or manifest")
+}
+
+func TestConfig_Validate_PartitionConcurrency(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.S3Downloader.S3Bucket = "abucket"
+	cfg.StartTime = "2024-01-01"
+	cfg.EndTime = "2024-01-02"
+	cfg.S3Downloader.PartitionConcurrency = 4
+	assert.NoError(t, cfg.Validate())
+
+	cfg.PollInterval = time.Minute
+	assert.EqualError(t, cfg.Validate(), "partition_concurrency cannot be used with poll_interval")
+
+	cfg.PollInterval = 0
+	cfg.S3Downloader.PartitionConcurrency = -1
+	assert.EqualError(t, cfg.Validate(), "partition_concurrency must not be negative")
+}
+
+func TestConfig_Validate_RangedGet(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.S3Downloader.S3Bucket = "abucket"
+	cfg.StartTime = "2024-01-01"
+	cfg.EndTime = "2024-01-02"
+	cfg.S3Downloader.RangedGet = &S3RangedGetConfig{Threshold: 64 << 20, PartSize: 8 << 20}
+	assert.NoError(t, cfg.Validate())
+
+	cfg.S3Downloader.RangedGet = &S3RangedGetConfig{Concurrency: -1}
+	assert.EqualError(t, cfg.Validate(), "ranged_get threshold, part_size and concurrency must not be negative")
+
+	cfg.S3Downloader.RangedGet = &S3RangedGetConfig{Threshold: 8 << 20, PartSize: 16 << 20}
+	assert.EqualError(t, cfg.Validate(), "ranged_get part_size must not be greater than the threshold")
+}
+
+func TestConfig_Validate_Select(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.S3Downloader.S3Bucket = "abucket"
+	cfg.StartTime = "2024-01-01"
+	cfg.EndTime = "2024-01-02"
+	cfg.S3Downloader.Select = &S3SelectConfig{Expression: "SELECT * FROM S3Object s WHERE s.level = 'ERROR'", InputFormat: SelectInputCSV}
+	assert.NoError(t, cfg.Validate())
+
+	cfg.S3Downloader.Select = &S3SelectConfig{InputFormat: "parquet"}
+	assert.EqualError(t, cfg.Validate(), "select expression is required; select input_format must be one of 'json_lines', 'json' or 'csv'")
+}
+
+func TestConfig_Validate_RateLimit(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.S3Downloader.S3Bucket = "abucket"
+	cfg.StartTime = "2024-01-01"
+	cfg.EndTime = "2024-01-02"
+	cfg.S3Downloader.RateLimit = &S3RateLimitConfig{RequestsPerSecond: 50, Burst: 100}
+	assert.NoError(t, cfg.Validate())
+
+	cfg.S3Downloader.RateLimit = &S3RateLimitConfig{BytesPerSecond: 10 << 20}
+	assert.NoError(t, cfg.Validate())
+
+	cfg.S3Downloader.RateLimit = &S3RateLimitConfig{RequestsPerSecond: -1}
+	assert.EqualError(t, cfg.Validate(), "rate_limit requests_per_second, burst and bytes_per_second must not be negative")
+
+	cfg.S3Downloader.RateLimit = &S3RateLimitConfig{}
+	assert.EqualError(t, cfg.Validate(), "rate_limit requires requests_per_second or bytes_per_second")
+
+	cfg.S3Downloader.RateLimit = &S3RateLimitConfig{Burst: 10, BytesPerSecond: 10 << 20}
+	assert.EqualError(t, cfg.Validate(), "rate_limit burst requires requests_per_second")
+}
+
+func TestConfig_Validate_HTTPClient(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.S3Downloader.S3Bucket = "abucket"
+	cfg.StartTime = "2024-01-01"
+	cfg.EndTime = "2024-01-02"
+	cfg.S3Downloader.HTTPClient = &S3HTTPClientConfig{ResponseHeaderTimeout: 30 * time.Second, MaxIdleConnsPerHost: 100}
+	assert.NoError(t, cfg.Validate())
+
+	cfg.S3Downloader.HTTPClient = &S3HTTPClientConfig{ConnectTimeout: -time.Second}
+	assert.EqualError(t, cfg.Validate(), "http_client timeout, connect_timeout, response_header_timeout and idle_conn_timeout must not be negative")
+
+	cfg.S3Downloader.HTTPClient = &S3HTTPClientConfig{MaxConnsPerHost: -1}
+	assert.EqualError(t, cfg.Validate(), "http_client max_idle_conns_per_host and max_conns_per_host must not be negative")
+
+	cfg.S3Downloader.HTTPClient = &S3HTTPClientConfig{ProxyURL: "http://proxy.internal:3128", NoProxy: "169.254.169.254,.internal"}
+	assert.NoError(t, cfg.Validate())
+
+	cfg.S3Downloader.HTTPClient = &S3HTTPClientConfig{ProxyURL: "proxy.internal:3128"}
+	assert.EqualError(t, cfg.Validate(), "http_client proxy_url must be an http, https or socks5 URL")
+
+	cfg.S3Downloader.HTTPClient = &S3HTTPClientConfig{NoProxy: ".internal"}
+	assert.EqualError(t, cfg.Validate(), "http_client no_proxy requires proxy_url")
+
+	cfg.S3Downloader.HTTPClient = &S3HTTPClientConfig{TLS: &configtls.ClientConfig{Config: configtls.Config{MinVersion: "1.4"}}}
+	assert.ErrorContains(t, cfg.Validate(), "http_client tls: invalid TLS min_version")
+}
+
+func TestConfig_Validate_ListOptions(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.S3Downloader.S3Bucket = "abucket"
+	cfg.StartTime = "2024-01-01"
+	cfg.EndTime = "2024-01-02"
+	cfg.S3Downloader.MaxKeys = 100
+	cfg.S3Downloader.StartAfter = "year=2024/month=01/day=01/hour=00/minute=00/traces_1"
+	assert.NoError(t, cfg.Validate())
+
+	cfg.S3Downloader.MaxKeys = 1001
+	assert.EqualError(t, cfg.Validate(), "max_keys must be between 0 and 1000")
+
+	cfg.S3Downloader.MaxKeys = 0
+	cfg.Manifest = &ManifestConfig{}
+	// All the errors of the configuration are reported at once.
+	assert.EqualError(t, cfg.Validate(), "start_after cannot be used together with sqs, manifest, inventory or buckets; "+
+		"manifest key is required; manifest format must be set when the key has neither a .json nor a .csv extension")
+}
+
+func TestConfig_Validate_AssumeRole(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.S3Downloader.S3Bucket = "abucket"
+	cfg.StartTime = "2024-01-01"
+	cfg.EndTime = "2024-01-02"
+	cfg.S3Downloader.ExternalID = "replay"
+	cfg.S3Downloader.RoleSessionName = "otel-replay"
+	cfg.S3Downloader.RoleSessionDuration = time.Hour
+	assert.EqualError(t, cfg.Validate(), "external_id, role_session_name and role_session_duration require role_arn")
+
+	cfg.S3Downloader.RoleARN = "arn:aws:iam::123456789012:role/reader"
+	assert.NoError(t, cfg.Validate())
+
+	cfg.S3Downloader.RoleSessionDuration = time.Minute
+	assert.EqualError(t, cfg.Validate(), "role_session_duration must be between 15m0s and 12h0m0s")
+
+	cfg.S3Downloader.RoleSessionDuration = 0
+	cfg.S3Downloader.RoleARN = ""
+	cfg.S3Downloader.ExternalID = ""
+	cfg.S3Downloader.RoleSessionName = ""
+	cfg.S3Downloader.S3Bucket = ""
+	cfg.S3Downloader.Buckets = []S3BucketConfig{{S3Bucket: "abucket", ExternalID: "reader"}}
+	assert.EqualError(t, cfg.Validate(), "buckets[0]: external_id requires role_arn")
+
+	cfg.S3Downloader.Buckets[0].RoleARN = "arn:aws:iam::123456789012:role/reader"
+	cfg.S3Downloader.RoleSessionName = "otel-replay"
+	assert.NoError(t, cfg.Validate())
+}
+
+func TestConfig_Validate_Credentials(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.S3Downloader.S3Bucket = "abucket"
+	cfg.StartTime = "2024-01-01"
+	cfg.EndTime = "2024-01-02"
+	cfg.S3Downloader.Credentials = &S3CredentialsConfig{Source: CredentialsSourceProfile, Profile: "tenant"}
+	assert.NoError(t, cfg.Validate())
+
+	cfg.S3Downloader.Credentials = &S3CredentialsConfig{Source: CredentialsSourceProfile}
+	assert.EqualError(t, cfg.Validate(), "credentials profile is required with the profile source")
+
+	cfg.S3Downloader.Credentials = &S3CredentialsConfig{Source: CredentialsSourceEnvironment, Profile: "tenant"}
+	assert.EqualError(t, cfg.Validate(), "credentials profile, shared_credentials_files and shared_config_files require the profile source")
+
+	cfg.S3Downloader.Credentials = &S3CredentialsConfig{Source: CredentialsSourceContainer, WebIdentityRoleARN: "arn:aws:iam::123456789012:role/reader"}
+	assert.EqualError(t, cfg.Validate(), "credentials web_identity_token_file and web_identity_role_arn require the web_identity source")
+
+	cfg.S3Downloader.Credentials = &S3CredentialsConfig{Source: "instance"}
+	assert.EqualError(t, cfg.Validate(), "credentials source must be one of 'default', 'environment', 'static', 'profile', 'web_identity' or 'container'")
+
+	// The static source is used by default when an access key is set.
+	cfg.S3Downloader.Credentials = &S3CredentialsConfig{AccessKeyID: "AKID", SecretAccessKey: "SECRET"}
+	assert.NoError(t, cfg.Validate())
+
+	cfg.S3Downloader.Credentials = &S3CredentialsConfig{Source: CredentialsSourceStatic, AccessKeyID: "AKID"}
+	assert.EqualError(t, cfg.Validate(), "credentials access_key_id and secret_access_key are required with the static source")
+
+	cfg.S3Downloader.Credentials = &S3CredentialsConfig{Source: CredentialsSourceEnvironment, SecretAccessKey: "SECRET"}
+	assert.EqualError(t, cfg.Validate(), "credentials access_key_id, secret_access_key and session_token require the static source")
+
+	// The static credentials can be read from files, such as mounted secrets.
+	cfg.S3Downloader.Credentials = &S3CredentialsConfig{
+		AccessKeyIDFile:     "/var/run/secrets/s3/access_key_id",
+		SecretAccessKeyFile: "/var/run/secrets/s3/secret_access_key",
+		ReloadInterval:      30 * time.Second,
+	}
+	assert.NoError(t, cfg.Validate())
+	cfg.S3Downloader.Credentials = &S3CredentialsConfig{AccessKeyID: "AKID", SecretAccessKeyFile: "/var/run/secrets/s3/secret_access_key"}
+	assert.NoError(t, cfg.Validate())
+
+	cfg.S3Downloader.Credentials.SecretAccessKey = "SECRET"
+	assert.EqualError(t, cfg.Validate(), "credentials access_key_id, secret_access_key and session_token cannot be used together with their file")
+
+	cfg.S3Downloader.Credentials = &S3CredentialsConfig{AccessKeyID: "AKID", SecretAccessKey: "SECRET", ReloadInterval: time.Minute}
+	assert.EqualError(t, cfg.Validate(), "credentials reload_interval requires access_key_id_file, secret_access_key_file or session_token_file")
+
+	cfg.S3Downloader.Credentials = &S3CredentialsConfig{Source: CredentialsSourceProfile, Profile: "tenant", SessionTokenFile: "/var/run/secrets/s3/session_token"}
+	assert.EqualError(t, cfg.Validate(), "credentials access_key_id_file, secret_access_key_file, session_token_file and reload_interval require the static source")
+
+	cfg.S3Downloader.Credentials = &S3CredentialsConfig{Profile: "tenant", SharedCredentialsFiles: []string{"/etc/otelcol/credentials"}}
+	assert.NoError(t, cfg.Validate())
+
+	authID := component.MustNewID("awsauth")
+	cfg.S3Downloader.Auth = &authID
+	assert.EqualError(t, cfg.Validate(), "auth and credentials cannot be used together")
+
+	cfg.S3Downloader.Credentials = nil
+	assert.NoError(t, cfg.Validate())
+}
+
+func TestConfig_Validate_FIPSAndDualStack(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.S3Downloader.S3Bucket = "abucket"
+	cfg.StartTime = "2024-01-01"
+	cfg.EndTime = "2024-01-02"
+	cfg.S3Downloader.UseFIPSEndpoint = true
+	cfg.S3Downloader.UseDualStackEndpoint = true
+	assert.NoError(t, cfg.Validate())
+
+	cfg.S3Downloader.Endpoint = "https://s3.example.com"
+	assert.EqualError(t, cfg.Validate(), "use_fips_endpoint and use_dualstack_endpoint cannot be used together with endpoint")
+}
+
+func TestConfig_Validate_Completion(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.S3Downloader.S3Bucket = "abucket"
+	cfg.StartTime = "2024-01-01"
+	cfg.EndTime = "2024-01-02"
+	cfg.Completion = &CompletionConfig{ShutdownCollector: true}
+	assert.NoError(t, cfg.Validate())
+
+	cfg.Loop = &LoopConfig{}
+	assert.EqualError(t, cfg.Validate(), "completion requires a loop count")
+
+	cfg.Loop = nil
+	cfg.EndTime = ""
+	cfg.PollInterval = time.Minute
+	assert.EqualError(t, cfg.Validate(), "completion cannot be used together with sqs, or with poll_interval without endtime")
+}
+
+func TestConfig_Validate_Deduplication(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.S3Downloader.S3Bucket = "abucket"
+	cfg.StartTime = "2024-01-01"
+	cfg.EndTime = "2024-01-02"
+	cfg.Deduplication = &DeduplicationConfig{Window: time.Hour}
+	assert.NoError(t, cfg.Validate())
+
+	cfg.Deduplication.Window = -time.Hour
+	assert.EqualError(t, cfg.Validate(), "deduplication window must not be negative")
+
+	cfg.Loop = &LoopConfig{Count: 2}
+	assert.EqualError(t, cfg.Validate(), "deduplication cannot be used together with loop")
+}
+
+func TestConfig_Validate_Checkpoint(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.S3Downloader.S3Bucket = "abucket"
+	cfg.StartTime = "2024-01-01"
+	cfg.EndTime = "2024-01-02"
+	cfg.Checkpoint = &CheckpointConfig{S3Bucket: "checkpoints", Interval: time.Minute}
+	assert.NoError(t, cfg.Validate())
+
+	cfg.Checkpoint.Interval = -time.Minute
+	assert.EqualError(t, cfg.Validate(), "checkpoint interval must not be negative")
+
+	cfg.Checkpoint.S3Bucket = ""
+	assert.EqualError(t, cfg.Validate(), "checkpoint s3_bucket is required")
+
+	cfg.Checkpoint = &CheckpointConfig{S3Bucket: "checkpoints"}
+	cfg.Loop = &LoopConfig{Count: 2}
+	assert.EqualError(t, cfg.Validate(), "checkpoint cannot be used together with sqs, manifest, buckets or loop")
+}
+
+func TestConfig_Validate_Lease(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.S3Downloader.S3Bucket = "abucket"
+	cfg.StartTime = "2024-01-01"
+	cfg.PollInterval = time.Minute
+	cfg.Lease = &LeaseConfig{DynamoDB: DynamoDBConfig{TableName: "awss3-leases"}, RenewInterval: 10 * time.Second}
+	assert.NoError(t, cfg.Validate())
+
+	cfg.Lease.RenewInterval = time.Minute
+	assert.EqualError(t, cfg.Validate(), "lease renew_interval must be shorter than the duration")
+
+	cfg.Lease.Duration = -time.Minute
+	assert.EqualError(t, cfg.Validate(), "lease duration and renew_interval must not be negative")
+
+	cfg.Lease.DynamoDB.TableName = ""
+	assert.EqualError(t, cfg.Validate(), "lease dynamodb table_name is required")
+}
+
+func TestConfig_Validate_Backpressure(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.S3Downloader.S3Bucket = "abucket"
+	cfg.StartTime = "2024-01-01"
+	cfg.EndTime = "2024-01-02"
+	cfg.Backpressure = &BackpressureConfig{InitialInterval: time.Second, MaxInterval: time.Minute}
+	assert.NoError(t, cfg.Validate())
+
+	cfg.Backpressure.InitialInterval = 2 * time.Minute
+	assert.EqualError(t, cfg.Validate(), "backpressure initial_interval must not be longer than the max_interval")
+
+	cfg.Backpressure.MaxElapsedTime = -time.Minute
+	assert.EqualError(t, cfg.Validate(), "backpressure initial_interval, max_interval and max_elapsed_time must not be negative")
+}
+
+func TestConfig_Validate_Batch(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.S3Downloader.S3Bucket = "abucket"
+	cfg.StartTime = "2024-01-01"
+	cfg.EndTime = "2024-01-02"
+	cfg.Batch = &BatchConfig{MaxSize: 1000, MaxBytes: 1 << 20}
+	assert.NoError(t, cfg.Validate())
+
+	cfg.Batch.Timeout = -time.Second
+	assert.EqualError(t, cfg.Validate(), "batch max_size, max_bytes and timeout must not be negative")
+
+	cfg.Batch.Timeout = 0
+	cfg.Deduplication = &DeduplicationConfig{}
+	assert.EqualError(t, cfg.Validate(), "batch cannot be used together with sqs, deduplication or checkpoint")
+}
+
+func TestConfig_Validate_StateStore(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.S3Downloader.S3Bucket = "abucket"
+	cfg.StartTime = "2024-01-01"
+	cfg.PollInterval = time.Minute
+	cfg.StateStore = &StateStoreConfig{DynamoDB: &DynamoDBConfig{TableName: "awss3-state"}}
+	assert.EqualError(t, cfg.Validate(), "state_store requires deduplication")
+
+	cfg.Deduplication = &DeduplicationConfig{}
+	assert.NoError(t, cfg.Validate())
+
+	storageID := component.MustNewID("file_storage")
+	cfg.Deduplication.Storage = &storageID
+	assert.EqualError(t, cfg.Validate(), "state_store cannot be used together with deduplication storage")
+
+	cfg.StateStore.DynamoDB.TableName = ""
+	assert.EqualError(t, cfg.Validate(), "state_store dynamodb table_name is required")
+
+	cfg.StateStore.DynamoDB = nil
+	assert.EqualError(t, cfg.Validate(), "state_store requires dynamodb")
+}
+
+func TestConfig_Validate_Format(t *testing.T) {
+	encodingID := component.MustNewID("text_encoding")
+	cfg := createDefaultConfig().(*Config)
+	cfg.S3Downloader.S3Bucket = "abucket"
+	cfg.StartTime = "2024-01-01"
+	cfg.EndTime = "2024-01-02"
+	cfg.Logs.Format = FormatOTLPJSON
+	cfg.Traces.Encoding = &encodingID
+	assert.NoError(t, cfg.Validate())
+
+	cfg.Metrics.Format = "csv"
+	assert.EqualError(t, cfg.Validate(), "metrics: format must be one of 'otlp_json', 'otlp_proto', 'otlp_parquet', 'otel_arrow', 'metric_streams_json', 'metric_streams_otlp', 'emf', 'cur', 'storage_lens'")
+
+	cfg.Metrics.Format = ""
+	cfg.Traces.Format = FormatOTLPProto
+	assert.EqualError(t, cfg.Validate(), "traces: format and encoding cannot be used together")
+
+	cfg.Traces.Encoding = nil
+	cfg.Traces.Format = "json_lines"
+	assert.EqualError(t, cfg.Validate(), "traces: format must be one of 'otlp_json', 'otlp_proto', 'otel_arrow', 'xray', 'jaeger_proto', 'jaeger_json', 'zipkin_json'")
+
+	cfg.Traces.Format = FormatXRay
+	assert.NoError(t, cfg.Validate())
+
+	cfg.Traces.Format = ""
+	cfg.Logs.S3Partition = "second"
+	assert.EqualError(t, cfg.Validate(), "logs: s3_partition must be one of 'day', 'hour' or 'minute'")
+
+	cfg.Logs.S3Partition = ""
+	cfg.Logs.Format = FormatTSV
+	cfg.Logs.CSV.Delimiter = "||"
+	assert.EqualError(t, cfg.Validate(), "logs: csv delimiter must be a single character")
+
+	cfg.Logs.Format = ""
+	cfg.Logs.CSV.Delimiter = ""
+	cfg.Metrics.Framing = "length"
+	assert.EqualError(t, cfg.Validate(), "metrics: framing must be one of 'newline', 'json' or 'size_delimited'")
+
+	cfg.Metrics.Framing = ""
+	cfg.Traces.BatchSize = -1
+	assert.EqualError(t, cfg.Validate(), "traces: batch_size must not be negative")
+
+	cfg.Traces.BatchSize = 0
+	cfg.Logs.FormatRules = []FormatRule{
+		{Pattern: "*.jsonl.gz", Format: FormatJSONLines, Compression: CompressionGzip},
+		{Pattern: "app/*_traces_*.binpb", Format: FormatOTLPProto},
+		{Pattern: "*.log", Compression: CompressionNone},
+	}
+	assert.NoError(t, cfg.Validate())
+
+	cfg.Logs.FormatRules[1].Pattern = ""
+	assert.EqualError(t, cfg.Validate(), "logs: format_rules[1]: pattern is required")
+
+	cfg.Logs.FormatRules[1].Pattern = "[a-"
+	assert.EqualError(t, cfg.Validate(), `logs: format_rules[1]: invalid pattern "[a-": syntax error in pattern`)
+
+	cfg.Logs.FormatRules[1].Pattern = "*.binpb"
+	cfg.Logs.FormatRules[2].Format = FormatXRay
+	assert.ErrorContains(t, cfg.Validate(), "logs: format_rules[2]: format must be one of 'otlp_json', 'otlp_proto', ")
+
+	cfg.Logs.FormatRules[2].Format = FormatTSV
+	cfg.Logs.FormatRules[2].Compression = "bzip2"
+	assert.EqualError(t, cfg.Validate(), "logs: format_rules[2]: compression must be one of 'gzip', 'zstd', 'snappy', 'lz4' or 'none'")
+
+	cfg.Logs.FormatRules[2].Compression = ""
+	cfg.Logs.CSV.Delimiter = "||"
+	assert.EqualError(t, cfg.Validate(), "logs: csv delimiter must be a single character")
+}
+
+func TestConfig_Validate_PartitionFormat(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.S3Downloader.S3Bucket = "abucket"
+	cfg.StartTime = "2024-01-01"
+	cfg.EndTime = "2024-01-02"
+	cfg.Logs.S3PartitionFormat = "%Y/%m/%d/123456789012_elasticloadbalancing_us-east-1_app.my-lb_%Y%m%dT%H"
+	assert.NoError(t, cfg.Validate())
+
+	cfg.S3Downloader.SkipEmptyPartitions = true
+	assert.EqualError(t, cfg.Validate(), "skip_empty_partitions cannot be used together with s3_partition_format")
+
+	cfg.S3Downloader.SkipEmptyPartitions = false
+	cfg.Logs.S3PartitionFormat = "%Y/%"
+	assert.ErrorContains(t, cfg.Validate(), "logs: invalid s3_partition_format")
+}
+
+func TestConfig_forTelemetryType(t *testing.T) {
+	separator := "-"
+	cfg := createDefaultConfig().(*Config)
+	cfg.S3Downloader.S3Prefix = "otel"
+	cfg.S3Downloader.FilePrefix = "file"
+	cfg.Logs = LogsConfig{SignalConfig: SignalConfig{
+		S3Prefix:      "applogs",
+		TelemetryName: "log",
+		Separator:     &separator,
+	}}
+	cfg.Traces = SignalConfig{
+		S3Partition: S3PartitionHour,
+		FilePrefix:  "collector-",
+		StartTime:   "2024-01-01 05:00",
+	}
+	cfg.StartTime = "2024-01-01 01:00"
+
+	logsCfg := cfg.forTelemetryType("logs")
+	assert.Equal(t, "applogs", logsCfg.S3Downloader.S3Prefix)
+	assert.Equal(t, "file", logsCfg.S3Downloader.FilePrefix)
+	assert.Equal(t, "file"+"log-", cfg.objectNaming("logs").namePrefix(logsCfg.S3Downloader.FilePrefix, "logs"))
+
+	tracesCfg := cfg.forTelemetryType("traces")
+	assert.Equal(t, "otel", tracesCfg.S3Downloader.S3Prefix)
+	assert.Equal(t, "collector-", tracesCfg.S3Downloader.FilePrefix)
+	assert.Equal(t, S3PartitionHour, tracesCfg.S3Downloader.S3Partition)
+	assert.Equal(t, S3PartitionMinute, logsCfg.S3Downloader.S3Partition)
+	assert.Equal(t, "collector-traces_", cfg.objectNaming("traces").namePrefix(tracesCfg.S3Downloader.FilePrefix, "traces"))
+	assert.Equal(t, "2024-01-01 05:00", tracesCfg.StartTime)
+	assert.Equal(t, "2024-01-01 01:00", logsCfg.StartTime)
+
+	metricsCfg := cfg.forTelemetryType("metrics")
+	assert.Equal(t, cfg.S3Downloader, metricsCfg.S3Downloader)
+	assert.Equal(t, "otel", cfg.S3Downloader.S3Prefix)
+}
+
+func TestConfig_Validate_SignalStartTime(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.S3Downloader.S3Bucket = "abucket"
+	cfg.StartTime = "2024-01-01"
+	cfg.EndTime = "2024-01-02"
+	cfg.Logs.StartTime = "2024-01-01 05:32"
+	assert.NoError(t, cfg.Validate())
+
+	cfg.Logs.StartTime = "05:32"
+	assert.EqualError(t, cfg.Validate(), "logs: unable to parse starttime (05:32), accepted formats: 2006-01-02 15:04, 2006-01-02")
+}
+
+func TestConfig_Validate_ResumeToken(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.S3Downloader.S3Bucket = "abucket"
+	cfg.StartTime = checkpoint{Position: time.Date(2024, 1, 1, 5, 32, 0, 0, time.UTC), Key: "logs_1"}.resumeToken()
+	cfg.EndTime = "2024-01-02"
+	assert.NoError(t, cfg.Validate())
+
+	cfg.StartTime = resumeTokenPrefix + "e30"
+	assert.EqualError(t, cfg.Validate(), "invalid resume token for starttime: the position is missing")
+}
+
+func TestValidateBucketARN(t *testing.T) {
+	tests := []struct {
+		bucket         string
+		forcePathStyle bool
+		errorMessage   string
+	}{
+		{bucket: "abucket"},
+		{bucket: "my-access-point-hrzrlukc5m36ft7okagglf3gmwluquse1b-s3alias"},
+		{bucket: "arn:aws:s3:us-east-1:123456789012:accesspoint/my-access-point"},
+		{bucket: "arn:aws:s3::123456789012:accesspoint/mfzwi23gnjvgw.mrap"},
+		{bucket: "arn:aws:s3-object-lambda:us-east-1:123456789012:accesspoint/my-transform"},
+		{bucket: "arn:aws:s3-outposts:us-west-2:123456789012:outpost/op-01234567890123456/accesspoint/my-access-point"},
+		{
+			bucket:       "arn:aws:s3-outposts:us-west-2:123456789012:outpost/op-01234567890123456/bucket/abucket",
+			errorMessage: "bucket ARN arn:aws:s3-outposts:us-west-2:123456789012:outpost/op-01234567890123456/bucket/abucket is not an S3 on Outposts access point ARN",
+		},
+		{
+			bucket:       "arn:aws:s3:::abucket",
+			errorMessage: "bucket ARN arn:aws:s3:::abucket is not an access point ARN",
+		},
+		{
+			bucket:       "arn:aws:sqs:us-east-1:123456789012:accesspoint/queue",
+			errorMessage: "bucket ARN arn:aws:sqs:us-east-1:123456789012:accesspoint/queue is not an access point ARN",
+		},
+		{
+			bucket:         "arn:aws:s3:us-east-1:123456789012:accesspoint/my-access-point",
+			forcePathStyle: true,
+			errorMessage:   "s3_force_path_style cannot be used with the access point ARN arn:aws:s3:us-east-1:123456789012:accesspoint/my-access-point",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.bucket, func(t *testing.T) {
+			err := validateBucketARN(tt.bucket, tt.forcePathStyle)
+			if tt.errorMessage == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, tt.errorMessage)
+		})
+	}
+}
+
 func TestLoadConfig(t *testing.T) {
 	cm, err := confmaptest.LoadConf(filepath.Join("testdata", "config.yaml"))
 	require.NoError(t, err)
+	dash := "-"
+	opampExtensionID := component.MustNewID("opamp")
 
 	tests := []struct {
 		id           component.ID
@@ -53,7 +681,7 @@ func TestLoadConfig(t *testing.T) {
 		},
 		{
 			id:           component.NewIDWithName(metadata.Type, "1"),
-			errorMessage: "s3_partition must be either 'hour' or 'minute'; unable to parse starttime (a date), accepted formats: 2006-01-02 15:04, 2006-01-02; unable to parse endtime (2024-02-03a), accepted formats: 2006-01-02 15:04, 2006-01-02",
+			errorMessage: "s3_partition must be one of 'day', 'hour' or 'minute'; unable to parse starttime (a date), accepted formats: 2006-01-02 15:04, 2006-01-02; unable to parse endtime (2024-02-03a), accepted formats: 2006-01-02 15:04, 2006-01-02",
 		},
 		{
 			id: component.NewIDWithName(metadata.Type, "2"),
@@ -68,6 +696,229 @@ func TestLoadConfig(t *testing.T) {
 				EndTime:   "2024-02-03",
 			},
 		},
+		{
+			id: component.NewIDWithName(metadata.Type, "3"),
+			expected: &Config{
+				S3Downloader: S3DownloaderConfig{
+					Region:              "us-west-2",
+					S3Partition:         "minute",
+					EndpointPartitionID: "aws",
+				},
+				SQS: &SQSConfig{
+					QueueURL: "https://sqs.us-west-2.amazonaws.com/123456789012/queue",
+					Region:   "us-west-2",
+				},
+			},
+		},
+		{
+			id:           component.NewIDWithName(metadata.Type, "4"),
+			errorMessage: "sqs queue_url is required; sqs max_number_of_messages must be between 1 and 10; sqs wait_time_seconds must be between 0 and 20",
+		},
+		{
+			id:           component.NewIDWithName(metadata.Type, "5"),
+			errorMessage: "inventory bucket is required; exactly one of inventory manifest_key and prefix must be set",
+		},
+		{
+			id: component.NewIDWithName(metadata.Type, "6"),
+			expected: &Config{
+				S3Downloader: S3DownloaderConfig{
+					Region:              "us-east-1",
+					S3Bucket:            "abucket",
+					S3Partition:         "minute",
+					EndpointPartitionID: "aws",
+					Inventory: &S3InventoryConfig{
+						Bucket: "inventorybucket",
+						Prefix: "inventory/abucket/all",
+					},
+				},
+				StartTime: "2024-01-31 15:00",
+				EndTime:   "2024-02-03",
+			},
+		},
+		{
+			id: component.NewIDWithName(metadata.Type, "7"),
+			expected: &Config{
+				S3Downloader: S3DownloaderConfig{
+					Region:              "us-east-1",
+					S3Bucket:            "abucket",
+					S3Partition:         "minute",
+					EndpointPartitionID: "aws",
+				},
+				Manifest: &ManifestConfig{
+					Key: "retry/manifest.csv",
+				},
+			},
+		},
+		{
+			id:           component.NewIDWithName(metadata.Type, "8"),
+			errorMessage: "sqs and manifest cannot be used together",
+		},
+		{
+			id:           component.NewIDWithName(metadata.Type, "9"),
+			errorMessage: "manifest bucket or s3_bucket is required; manifest format must be set when the key has neither a .json nor a .csv extension",
+		},
+		{
+			id: component.NewIDWithName(metadata.Type, "10"),
+			expected: &Config{
+				S3Downloader: S3DownloaderConfig{
+					Region:              "us-east-1",
+					S3Prefix:            "traces",
+					S3Partition:         "minute",
+					EndpointPartitionID: "aws",
+					BucketConcurrency:   2,
+					Buckets: []S3BucketConfig{
+						{S3Bucket: "abucket"},
+						{S3Bucket: "anotherbucket", S3Prefix: "otel", Region: "eu-west-1", RoleARN: "arn:aws:iam::123456789012:role/reader"},
+					},
+				},
+				StartTime: "2024-01-31 15:00",
+				EndTime:   "2024-02-03",
+			},
+		},
+		{
+			id:           component.NewIDWithName(metadata.Type, "11"),
+			errorMessage: "s3_bucket and buckets cannot be used together; buckets[0]: bucket is required",
+		},
+		{
+			id: component.NewIDWithName(metadata.Type, "12"),
+			expected: &Config{
+				S3Downloader: S3DownloaderConfig{
+					Region:              "us-east-1",
+					S3Bucket:            "abucket",
+					S3Prefix:            "otel",
+					S3Partition:         "minute",
+					EndpointPartitionID: "aws",
+				},
+				Logs: LogsConfig{SignalConfig: SignalConfig{
+					S3Prefix:      "applogs",
+					TelemetryName: "log",
+					Separator:     &dash,
+				}},
+				Traces: SignalConfig{
+					FilePrefix: "collector-",
+				},
+				StartTime: "2024-01-31 15:00",
+				EndTime:   "2024-02-03",
+			},
+		},
+		{
+			id: component.NewIDWithName(metadata.Type, "13"),
+			expected: &Config{
+				S3Downloader: S3DownloaderConfig{
+					Region:              "us-east-1",
+					S3Bucket:            "abucket",
+					S3Partition:         "minute",
+					EndpointPartitionID: "aws",
+					Versions: &S3VersionsConfig{
+						AsOf: "2024-02-05 12:00",
+					},
+					SkipEmptyPartitions: true,
+				},
+				StartTime: "2024-01-31 15:00",
+				EndTime:   "2024-02-03",
+			},
+		},
+		{
+			id: component.NewIDWithName(metadata.Type, "15"),
+			expected: &Config{
+				S3Downloader: S3DownloaderConfig{
+					Region:              "us-east-1",
+					S3Bucket:            "abucket",
+					S3Partition:         "minute",
+					EndpointPartitionID: "aws",
+					Restore: &S3RestoreConfig{
+						Tier:         "Bulk",
+						Days:         2,
+						Timeout:      12 * time.Hour,
+						PollInterval: 5 * time.Minute,
+					},
+				},
+				StartTime: "2024-01-31 15:00",
+				EndTime:   "2024-02-03",
+			},
+		},
+		{
+			id:           component.NewIDWithName(metadata.Type, "16"),
+			errorMessage: "restore tier must be one of 'Standard', 'Bulk' or 'Expedited'; restore days must not be negative",
+		},
+		{
+			id: component.NewIDWithName(metadata.Type, "17"),
+			expected: &Config{
+				S3Downloader: S3DownloaderConfig{
+					Region:              "us-west-2",
+					S3Bucket:            "arn:aws:s3-object-lambda:us-west-2:123456789012:accesspoint/traces-transform",
+					S3Prefix:            "otel",
+					S3Partition:         "minute",
+					EndpointPartitionID: "aws",
+				},
+				StartTime: "2024-01-31 15:00",
+				EndTime:   "2024-02-03",
+			},
+		},
+		{
+			id: component.NewIDWithName(metadata.Type, "18"),
+			expected: &Config{
+				S3Downloader: S3DownloaderConfig{
+					S3Bucket:             "abucket",
+					S3Partition:          "minute",
+					Endpoint:             "http://minio.local:9000",
+					EndpointPartitionID:  "aws",
+					CompatibilityProfile: CompatibilityProfileS3Compatible,
+				},
+				StartTime: "2024-01-31 15:00",
+				EndTime:   "2024-02-03",
+			},
+		},
+		{
+			id:           component.NewIDWithName(metadata.Type, "19"),
+			errorMessage: "compatibility_profile must be either 'aws' or 's3_compatible'",
+		},
+		{
+			id: component.NewIDWithName(metadata.Type, "20"),
+			expected: &Config{
+				S3Downloader: S3DownloaderConfig{
+					Region:              "us-east-1",
+					S3Bucket:            "abucket",
+					S3Partition:         "minute",
+					EndpointPartitionID: "aws",
+				},
+				Schedule: &ScheduleConfig{
+					Timezone: "UTC",
+					Windows: []ScheduleWindowConfig{
+						{Start: "01:00", End: "06:00", Days: []string{"sat", "sun"}},
+					},
+				},
+				StartTime: "2024-01-31 15:00",
+				EndTime:   "2024-02-03",
+			},
+		},
+		{
+			id:           component.NewIDWithName(metadata.Type, "21"),
+			errorMessage: "schedule windows are required",
+		},
+		{
+			id: component.NewIDWithName(metadata.Type, "22"),
+			expected: &Config{
+				S3Downloader: S3DownloaderConfig{
+					Region:              "us-east-1",
+					S3Bucket:            "abucket",
+					S3Partition:         "minute",
+					EndpointPartitionID: "aws",
+				},
+				Completion: &CompletionConfig{ShutdownCollector: true},
+				Notifications: NotificationsConfig{
+					OpAMP:             &opampExtensionID,
+					ProgressInterval:  30 * time.Second,
+					HeartbeatInterval: time.Minute,
+				},
+				StartTime: "2024-01-31 15:00",
+				EndTime:   "2024-02-03",
+			},
+		},
+		{
+			id:           component.NewIDWithName(metadata.Type, "14"),
+			errorMessage: "versions and inventory cannot be used together; unable to parse versions as_of (yesterday), accepted formats: 2006-01-02 15:04, 2006-01-02",
+		},
 	}
 
 	for _, tt := range tests {
@@ -89,3 +940,262 @@ func TestLoadConfig(t *testing.T) {
 		})
 	}
 }
+
+func TestManifestConfig_format(t *testing.T) {
+	format, err := ManifestConfig{Key: "cur/report/20240101-20240201/report-Manifest.json"}.format()
+	require.NoError(t, err)
+	assert.Equal(t, ManifestFormatCUR, format)
+
+	_, err = ManifestConfig{Key: "manifest.json", Format: "xml"}.format()
+	assert.EqualError(t, err, "manifest format must be one of 'json', 'csv', 'cur' or 'storage_lens'")
+}
+
+func TestConfig_Validate_SSECustomerKey(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.S3Downloader.S3Bucket = "abucket"
+	cfg.StartTime = "2024-01-01"
+	cfg.EndTime = "2024-01-02"
+	cfg.S3Downloader.SSECustomerKey = &S3SSECustomerKeyConfig{Key: testSSECustomerKey, KeyMD5: testSSECustomerKeyMD5}
+	assert.NoError(t, cfg.Validate())
+
+	cfg.S3Downloader.SSECustomerKey.KeyMD5 = "bWQ1"
+	assert.EqualError(t, cfg.Validate(), "sse_customer_key key_md5 is not the base64-encoded MD5 digest of the key")
+
+	cfg.S3Downloader.SSECustomerKey.KeyMD5 = ""
+	cfg.S3Downloader.SSECustomerKey.Algorithm = "aws:kms"
+	assert.EqualError(t, cfg.Validate(), "sse_customer_key algorithm must be 'AES256'")
+
+	cfg.S3Downloader.SSECustomerKey = &S3SSECustomerKeyConfig{Key: "c2hvcnQ="}
+	assert.EqualError(t, cfg.Validate(), "sse_customer_key key must be a base64-encoded 256-bit key")
+}
+
+func TestConfig_Validate_ClientSideEncryption(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.S3Downloader.S3Bucket = "abucket"
+	cfg.StartTime = "2024-01-01"
+	cfg.EndTime = "2024-01-02"
+	cfg.S3Downloader.ClientSideEncryption = &S3ClientSideEncryptionConfig{KMSKeyID: "alias/archive"}
+	assert.NoError(t, cfg.Validate())
+
+	cfg.S3Downloader.ClientSideEncryption.Key = "c2hvcnQ="
+	assert.EqualError(t, cfg.Validate(), "client_side_encryption requires either kms_key_id or key")
+
+	cfg.S3Downloader.ClientSideEncryption.KMSKeyID = ""
+	assert.EqualError(t, cfg.Validate(), "client_side_encryption key must be a base64-encoded 128, 192 or 256-bit key")
+
+	cfg.S3Downloader.ClientSideEncryption.Key = testSSECustomerKey
+	assert.NoError(t, cfg.Validate())
+
+	cfg.S3Downloader.RangedGet = &S3RangedGetConfig{}
+	assert.EqualError(t, cfg.Validate(), "client_side_encryption cannot be used together with ranged_get or select")
+}
+
+func TestConfig_Validate_Retry(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.S3Downloader.S3Bucket = "abucket"
+	cfg.StartTime = "2024-01-01"
+	cfg.EndTime = "2024-01-02"
+	cfg.S3Downloader.Retry = &S3RetryConfig{MaxAttempts: 10, Mode: RetryModeAdaptive, MaxBackoff: time.Minute}
+	assert.NoError(t, cfg.Validate())
+
+	cfg.S3Downloader.Retry.Mode = "legacy"
+	assert.EqualError(t, cfg.Validate(), "retry mode must be 'standard' or 'adaptive'")
+
+	cfg.S3Downloader.Retry = &S3RetryConfig{MaxAttempts: -1}
+	assert.EqualError(t, cfg.Validate(), "retry max_attempts and max_backoff must not be negative")
+}
+
+func TestConfig_Validate_IMDS(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.S3Downloader.S3Bucket = "abucket"
+	cfg.StartTime = "2024-01-01"
+	cfg.EndTime = "2024-01-02"
+	cfg.S3Downloader.IMDS = &S3IMDSConfig{Disabled: true}
+	assert.NoError(t, cfg.Validate())
+
+	cfg.S3Downloader.IMDS = &S3IMDSConfig{Endpoint: "http://[fd00:ec2::254]", Timeout: time.Second, MaxAttempts: 1}
+	assert.NoError(t, cfg.Validate())
+
+	cfg.S3Downloader.IMDS.Endpoint = "169.254.169.254"
+	assert.EqualError(t, cfg.Validate(), "imds endpoint must be an http or https URL")
+
+	cfg.S3Downloader.IMDS = &S3IMDSConfig{Timeout: -time.Second}
+	assert.EqualError(t, cfg.Validate(), "imds timeout and max_attempts must not be negative")
+}
+
+func TestConfig_Validate_Partitions(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.S3Downloader.S3Bucket = "arn:aws-cn:s3:cn-north-1:123456789012:accesspoint/traces"
+	cfg.StartTime = "2024-01-01"
+	cfg.EndTime = "2024-01-02"
+	cfg.S3Downloader.RoleARN = "arn:aws-cn:iam::123456789012:role/reader"
+	// The default region is in the aws partition.
+	assert.EqualError(t, cfg.Validate(), "s3_bucket is in the aws-cn partition, but region us-east-1 is in the aws partition")
+
+	cfg.S3Downloader.Region = ""
+	assert.NoError(t, cfg.Validate())
+	cfg.S3Downloader.Region = "cn-north-1"
+	assert.NoError(t, cfg.Validate())
+
+	// The partitions unknown to the receiver, such as of custom endpoints, have no
+	// default region.
+	cfg.S3Downloader.EndpointPartitionID = "aws-eu"
+	assert.NoError(t, cfg.Validate())
+
+	cfg.S3Downloader.EndpointPartitionID = "aws-us-gov"
+	cfg.S3Downloader.Region = "us-gov-west-1"
+	assert.EqualError(t, cfg.Validate(), "s3_bucket is in the aws-cn partition, but region us-gov-west-1 is in the aws-us-gov partition")
+
+	// Each bucket is checked with its own region and role.
+	cfg.S3Downloader.Region = ""
+	cfg.S3Downloader.EndpointPartitionID = "aws"
+	cfg.S3Downloader.RoleARN = ""
+	cfg.S3Downloader.S3Bucket = ""
+	cfg.S3Downloader.Buckets = []S3BucketConfig{
+		{S3Bucket: "logs", Region: "cn-north-1", RoleARN: "arn:aws-cn:iam::123456789012:role/reader"},
+		{S3Bucket: "archive", Region: "us-east-1", RoleARN: "arn:aws-cn:iam::123456789012:role/reader"},
+	}
+	assert.EqualError(t, cfg.Validate(), "role_arn is in the aws-cn partition, but region us-east-1 is in the aws partition")
+}
+
+func TestConfig_Validate_StartupCheck(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.S3Downloader.S3Bucket = "abucket"
+	cfg.StartTime = "2024-01-01"
+	cfg.EndTime = "2024-01-02"
+	cfg.S3Downloader.StartupCheck = true
+	assert.NoError(t, cfg.Validate())
+
+	cfg.Manifest = &ManifestConfig{Bucket: "manifests", Key: "replay.json"}
+	assert.EqualError(t, cfg.Validate(), "startup_check cannot be used together with sqs or manifest")
+}
+
+func TestConfig_Validate_SDKLog(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.S3Downloader.S3Bucket = "abucket"
+	cfg.StartTime = "2024-01-01"
+	cfg.EndTime = "2024-01-02"
+	cfg.S3Downloader.SDKLog = []string{SDKLogSigning, SDKLogRetries, SDKLogResponse}
+	assert.NoError(t, cfg.Validate())
+
+	cfg.S3Downloader.SDKLog = []string{SDKLogRequest, "headers"}
+	assert.EqualError(t, cfg.Validate(), "sdk_log events must be one of 'signing', 'retries', 'request', 'request_with_body', 'response' or 'response_with_body'")
+}
+
+func TestConfig_Validate_Timeouts(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.S3Downloader.S3Bucket = "abucket"
+	cfg.StartTime = "2024-01-01"
+	cfg.EndTime = "2024-01-02"
+	cfg.S3Downloader.Timeouts = &S3TimeoutsConfig{ListObjects: 30 * time.Second, GetObject: 5 * time.Minute}
+	assert.NoError(t, cfg.Validate())
+
+	cfg.S3Downloader.Timeouts.GetObject = -time.Second
+	assert.EqualError(t, cfg.Validate(), "timeouts list_objects and get_object must not be negative")
+}
+
+func TestConfig_Validate_Notifications(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.S3Downloader.S3Bucket = "abucket"
+	cfg.StartTime = "2024-01-01"
+	cfg.EndTime = "2024-01-02"
+	opampExtensionID := component.MustNewID("opamp")
+	cfg.Notifications = NotificationsConfig{OpAMP: &opampExtensionID, ProgressInterval: time.Minute}
+	assert.NoError(t, cfg.Validate())
+
+	cfg.Notifications.ProgressInterval = -time.Minute
+	assert.EqualError(t, cfg.Validate(), "notifications progress_interval must not be negative")
+
+	cfg.Notifications = NotificationsConfig{ProgressInterval: time.Minute}
+	assert.EqualError(t, cfg.Validate(), "notifications progress_interval requires opampextension")
+
+	cfg.Notifications = NotificationsConfig{HeartbeatInterval: 30 * time.Second}
+	assert.EqualError(t, cfg.Validate(), "notifications heartbeat_interval requires opampextension")
+	cfg.Notifications.OpAMP = &opampExtensionID
+	assert.NoError(t, cfg.Validate())
+	cfg.Notifications.HeartbeatInterval = -time.Second
+	assert.EqualError(t, cfg.Validate(), "notifications heartbeat_interval must not be negative")
+
+	cfg.Notifications = NotificationsConfig{ObjectFailures: &ObjectFailuresConfig{}}
+	assert.EqualError(t, cfg.Validate(), "notifications object_failures requires opampextension")
+	cfg.Notifications.OpAMP = &opampExtensionID
+	assert.NoError(t, cfg.Validate())
+	cfg.Notifications.ObjectFailures.Burst = -1
+	assert.EqualError(t, cfg.Validate(), "notifications object_failures per_second and burst must not be negative")
+
+	// With jobs, the time range of the configuration is optional.
+	cfg.Notifications = NotificationsConfig{Jobs: true}
+	assert.EqualError(t, cfg.Validate(), "notifications jobs requires opampextension")
+	cfg.Notifications.OpAMP = &opampExtensionID
+	assert.NoError(t, cfg.Validate())
+	cfg.StartTime, cfg.EndTime = "", ""
+	assert.NoError(t, cfg.Validate())
+	cfg.Notifications.Jobs = false
+	assert.EqualError(t, cfg.Validate(), "starttime is required; endtime is required")
+
+	cfg.Notifications.Jobs = true
+	cfg.Loop = &LoopConfig{Count: 2}
+	assert.EqualError(t, cfg.Validate(), "notifications jobs cannot be used together with sqs, manifest, checkpoint, lease, loop, completion or poll_interval")
+}
+
+func TestConfig_Validate_ExpectedBucketOwner(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.S3Downloader.S3Bucket = "abucket"
+	cfg.StartTime = "2024-01-01"
+	cfg.EndTime = "2024-01-02"
+	cfg.S3Downloader.ExpectedBucketOwner = "123456789012"
+	assert.NoError(t, cfg.Validate())
+
+	cfg.S3Downloader.ExpectedBucketOwner = "1234-5678-9012"
+	assert.EqualError(t, cfg.Validate(), "expected_bucket_owner 1234-5678-9012 is not a 12-digit AWS account ID")
+
+	// Each bucket may belong to its own account.
+	cfg.S3Downloader.ExpectedBucketOwner = "123456789012"
+	cfg.S3Downloader.S3Bucket = ""
+	cfg.S3Downloader.Buckets = []S3BucketConfig{{S3Bucket: "abucket"}, {S3Bucket: "another", ExpectedBucketOwner: "210987654321"}}
+	assert.NoError(t, cfg.Validate())
+	configs := cfg.S3Downloader.bucketConfigs()
+	assert.Equal(t, "123456789012", configs[0].ExpectedBucketOwner)
+	assert.Equal(t, "210987654321", configs[1].ExpectedBucketOwner)
+
+	cfg.S3Downloader.Buckets[1].ExpectedBucketOwner = "another"
+	assert.EqualError(t, cfg.Validate(), "expected_bucket_owner another is not a 12-digit AWS account ID")
+}
+
+func TestConfig_Validate_STS(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.S3Downloader.S3Bucket = "abucket"
+	cfg.StartTime = "2024-01-01"
+	cfg.EndTime = "2024-01-02"
+	cfg.S3Downloader.STS = &S3STSConfig{Region: "us-west-2", Endpoint: "https://vpce-0123456789abcdef0-abcdefgh.sts.us-west-2.vpce.amazonaws.com"}
+	assert.EqualError(t, cfg.Validate(), "sts requires role_arn or the web_identity credentials source")
+
+	cfg.S3Downloader.RoleARN = "arn:aws:iam::123456789012:role/reader"
+	assert.NoError(t, cfg.Validate())
+	cfg.S3Downloader.RoleARN = ""
+	cfg.S3Downloader.Credentials = &S3CredentialsConfig{Source: CredentialsSourceWebIdentity}
+	assert.NoError(t, cfg.Validate())
+
+	cfg.S3Downloader.STS.Endpoint = "sts.us-west-2.amazonaws.com"
+	assert.EqualError(t, cfg.Validate(), "sts endpoint must be an http or https URL")
+
+	cfg.S3Downloader.STS = &S3STSConfig{Region: "cn-north-1"}
+	assert.EqualError(t, cfg.Validate(), "sts region cn-north-1 is in the aws-cn partition, but region us-east-1 is in the aws partition")
+	cfg.S3Downloader.STS.Region = "aws-global"
+	assert.NoError(t, cfg.Validate())
+}
+
+func TestConfig_Validate_Outposts(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.S3Downloader.S3Bucket = "arn:aws:s3-outposts:us-west-2:123456789012:outpost/op-01234567890123456/accesspoint/traces"
+	cfg.StartTime = "2024-01-01"
+	cfg.EndTime = "2024-01-02"
+	assert.NoError(t, cfg.Validate())
+
+	cfg.S3Downloader.UseDualStackEndpoint = true
+	assert.EqualError(t, cfg.Validate(), "use_fips_endpoint and use_dualstack_endpoint cannot be used with the S3 on Outposts access point ARN "+cfg.S3Downloader.S3Bucket)
+
+	cfg.S3Downloader.UseDualStackEndpoint = false
+	cfg.S3Downloader.Select = &S3SelectConfig{Expression: "SELECT * FROM S3Object s"}
+	assert.EqualError(t, cfg.Validate(), "select and restore cannot be used with the S3 on Outposts access point ARN "+cfg.S3Downloader.S3Bucket)
+}
@@ -0,0 +1,164 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"context"
+	"slices"
+	"sync"
+)
+
+const (
+	commandPause  = "pause"
+	commandResume = "resume"
+	commandCancel = "cancel"
+	commandSubmit = "submit"
+)
+
+// ingestCommand is a command of the OpAMP server to the ingest of the telemetry
+// types, or of all the telemetry types if TelemetryTypes is empty.
+type ingestCommand struct {
+	Command        string
+	TelemetryTypes []string
+	// CommandID, if set, is sent back along with the acknowledgment of the command.
+	CommandID string
+	// StartTime and EndTime are the time range of the job of a submit command.
+	StartTime string
+	EndTime   string
+}
+
+// appliesTo reports whether the command applies to the ingest of telemetryType.
+func (c ingestCommand) appliesTo(telemetryType string) bool {
+	return len(c.TelemetryTypes) == 0 || slices.Contains(c.TelemetryTypes, telemetryType)
+}
+
+// commandHandler applies the commands received by a notifier.
+type commandHandler func(ctx context.Context, command ingestCommand)
+
+// ingestControl pauses, resumes and cancels the read on the commands of the OpAMP
+// server. The zero value is ready to use.
+type ingestControl struct {
+	mu sync.Mutex
+	// cancel is set while reading, to cancel the read.
+	cancel context.CancelFunc
+	// cancelCommand is the command which cancelled the read, if any.
+	cancelCommand *ingestCommand
+	// resumed is set while paused, and closed once resumed.
+	resumed chan struct{}
+	// status is the last status of the ingest notified.
+	status string
+}
+
+// start returns the context of a read, cancelled by a cancel command.
+func (c *ingestControl) start(ctx context.Context) context.Context {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ctx, c.cancel = context.WithCancel(ctx)
+	c.cancelCommand = nil
+	return ctx
+}
+
+// stop records the end of a read, which is no longer paused.
+func (c *ingestControl) stop() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.cancel != nil {
+		c.cancel()
+		c.cancel = nil
+	}
+	c.resumeLocked()
+}
+
+// reading reports whether the objects are being read.
+func (c *ingestControl) reading() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.cancel != nil
+}
+
+// paused reports whether the read is paused.
+func (c *ingestControl) paused() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.resumed != nil
+}
+
+// pause pauses the read, unless the objects are not being read.
+func (c *ingestControl) pause() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.cancel == nil {
+		return false
+	}
+	if c.resumed == nil {
+		c.resumed = make(chan struct{})
+	}
+	return true
+}
+
+// resume resumes the read if paused.
+func (c *ingestControl) resume() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.resumeLocked()
+}
+
+func (c *ingestControl) resumeLocked() {
+	if c.resumed != nil {
+		close(c.resumed)
+		c.resumed = nil
+	}
+}
+
+// cancelRead cancels the read on command, unless the objects are not being read.
+func (c *ingestControl) cancelRead(command ingestCommand) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.cancel == nil {
+		return false
+	}
+	c.cancelCommand = &command
+	c.cancel()
+	return true
+}
+
+// cancelledBy returns the command which cancelled the read, if any.
+func (c *ingestControl) cancelledBy() (ingestCommand, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.cancelCommand == nil {
+		return ingestCommand{}, false
+	}
+	return *c.cancelCommand, true
+}
+
+// recordStatus records the last status of the ingest notified, acknowledging
+// the commands rejected once the objects are no longer read.
+func (c *ingestControl) recordStatus(status string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.status = status
+}
+
+func (c *ingestControl) lastStatus() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.status
+}
+
+// wait waits for the read to be resumed if paused, unless ctx is done first.
+func (c *ingestControl) wait(ctx context.Context) error {
+	c.mu.Lock()
+	resumed := c.resumed
+	c.mu.Unlock()
+	if resumed == nil {
+		return nil
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-resumed:
+		return nil
+	}
+}
@@ -0,0 +1,210 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/credentials/endpointcreds"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+)
+
+const (
+	// containerCredentialsHost is the host the relative URI of the ECS container
+	// credentials is resolved against.
+	containerCredentialsHost = "http://169.254.170.2"
+	// containerAuthorizationTokenFileEnvVar is the file of the authorization token of
+	// the container credentials, as set by EKS Pod Identity.
+	containerAuthorizationTokenFileEnvVar = "AWS_CONTAINER_AUTHORIZATION_TOKEN_FILE"
+	// credentialsExpiryWindow is how long before their expiry the temporary
+	// credentials are renewed, so that the requests signed with them do not reach
+	// S3 once they have expired.
+	credentialsExpiryWindow = 5 * time.Minute
+	// defaultCredentialsReloadInterval is the time between the reads of the files
+	// of the static credentials.
+	defaultCredentialsReloadInterval = time.Minute
+)
+
+// credentialsLoadOptions returns the load options of the SDK configuration
+// selecting the shared profile of cfg.
+func credentialsLoadOptions(cfg *S3CredentialsConfig) []func(*config.LoadOptions) error {
+	if cfg == nil || cfg.source() != CredentialsSourceProfile {
+		return nil
+	}
+	optionsFuncs := []func(*config.LoadOptions) error{config.WithSharedConfigProfile(cfg.Profile)}
+	if len(cfg.SharedCredentialsFiles) > 0 {
+		optionsFuncs = append(optionsFuncs, config.WithSharedCredentialsFiles(cfg.SharedCredentialsFiles))
+	}
+	if len(cfg.SharedConfigFiles) > 0 {
+		optionsFuncs = append(optionsFuncs, config.WithSharedConfigFiles(cfg.SharedConfigFiles))
+	}
+	return optionsFuncs
+}
+
+// newSTSClient returns the client of the requests to STS assuming roles with the
+// credentials of awsCfg, sent to the region and the endpoint of cfg, if set.
+func newSTSClient(awsCfg aws.Config, cfg *S3STSConfig) *sts.Client {
+	return sts.NewFromConfig(awsCfg, func(o *sts.Options) {
+		if cfg == nil {
+			return
+		}
+		if cfg.Region != "" {
+			o.Region = cfg.Region
+		}
+		if cfg.Endpoint != "" {
+			o.BaseEndpoint = aws.String(cfg.Endpoint)
+		}
+	})
+}
+
+// credentialsProvider returns the provider of the credentials of the source of
+// cfg, or nil for the credentials resolved when loading awsCfg: those of the
+// default chain, or of the profile. Unlike the default chain, the source is not
+// skipped when its settings are missing from the environment. The roles are
+// assumed with the requests to STS of stsCfg.
+func credentialsProvider(awsCfg aws.Config, cfg *S3CredentialsConfig, stsCfg *S3STSConfig) (aws.CredentialsProvider, error) {
+	if cfg == nil {
+		return nil, nil
+	}
+	envConfig, err := config.NewEnvConfig()
+	if err != nil {
+		return nil, err
+	}
+	switch cfg.source() {
+	case CredentialsSourceStatic:
+		if cfg.hasFiles() {
+			return aws.NewCredentialsCache(newFileCredentialsProvider(*cfg)), nil
+		}
+		return credentials.NewStaticCredentialsProvider(string(cfg.AccessKeyID), string(cfg.SecretAccessKey), string(cfg.SessionToken)), nil
+	case CredentialsSourceEnvironment:
+		if !envConfig.Credentials.HasKeys() {
+			return nil, errors.New("the environment credentials source requires AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY")
+		}
+		return credentials.StaticCredentialsProvider{Value: envConfig.Credentials}, nil
+	case CredentialsSourceWebIdentity:
+		tokenFile, roleARN := cfg.WebIdentityTokenFile, cfg.WebIdentityRoleARN
+		if tokenFile == "" {
+			tokenFile = envConfig.WebIdentityTokenFilePath
+		}
+		if roleARN == "" {
+			roleARN = envConfig.RoleARN
+		}
+		if tokenFile == "" || roleARN == "" {
+			return nil, errors.New("the web_identity credentials source requires a token file and a role ARN, set in the configuration or by AWS_WEB_IDENTITY_TOKEN_FILE and AWS_ROLE_ARN")
+		}
+		provider := stscreds.NewWebIdentityRoleProvider(newSTSClient(awsCfg, stsCfg), roleARN, stscreds.IdentityTokenFile(tokenFile), func(o *stscreds.WebIdentityRoleOptions) {
+			if envConfig.RoleSessionName != "" {
+				o.RoleSessionName = envConfig.RoleSessionName
+			}
+		})
+		return aws.NewCredentialsCache(provider, func(o *aws.CredentialsCacheOptions) {
+			o.ExpiryWindow = credentialsExpiryWindow
+		}), nil
+	case CredentialsSourceContainer:
+		endpoint := envConfig.ContainerCredentialsEndpoint
+		if endpoint == "" && envConfig.ContainerCredentialsRelativePath != "" {
+			endpoint = containerCredentialsHost + envConfig.ContainerCredentialsRelativePath
+		}
+		if endpoint == "" {
+			return nil, errors.New("the container credentials source requires AWS_CONTAINER_CREDENTIALS_FULL_URI or AWS_CONTAINER_CREDENTIALS_RELATIVE_URI")
+		}
+		provider := endpointcreds.New(endpoint, func(o *endpointcreds.Options) {
+			o.AuthorizationToken = envConfig.ContainerAuthorizationToken
+			if tokenFile := os.Getenv(containerAuthorizationTokenFileEnvVar); tokenFile != "" {
+				o.AuthorizationTokenProvider = endpointcreds.TokenProviderFunc(func() (string, error) {
+					token, err := os.ReadFile(tokenFile)
+					if err != nil {
+						return "", fmt.Errorf("failed to read the container authorization token: %w", err)
+					}
+					return string(token), nil
+				})
+			}
+			o.APIOptions = awsCfg.APIOptions
+		})
+		return aws.NewCredentialsCache(provider, func(o *aws.CredentialsCacheOptions) {
+			o.ExpiryWindow = credentialsExpiryWindow
+		}), nil
+	}
+	return nil, nil
+}
+
+// fileCredentialsProvider reads the static credentials from their files, each
+// credential without a file being the one of the configuration. The credentials
+// expire after the reload interval, so that the credentials cache reads the
+// files again, or as soon as S3 rejects them.
+type fileCredentialsProvider struct {
+	accessKeyID     credentialFile
+	secretAccessKey credentialFile
+	sessionToken    credentialFile
+	reloadInterval  time.Duration
+	now             func() time.Time
+}
+
+// credentialFile is a credential read from path, if set, or else value.
+type credentialFile struct {
+	value string
+	path  string
+}
+
+func newFileCredentialsProvider(cfg S3CredentialsConfig) *fileCredentialsProvider {
+	reloadInterval := cfg.ReloadInterval
+	if reloadInterval == 0 {
+		reloadInterval = defaultCredentialsReloadInterval
+	}
+	return &fileCredentialsProvider{
+		accessKeyID:     credentialFile{value: string(cfg.AccessKeyID), path: cfg.AccessKeyIDFile},
+		secretAccessKey: credentialFile{value: string(cfg.SecretAccessKey), path: cfg.SecretAccessKeyFile},
+		sessionToken:    credentialFile{value: string(cfg.SessionToken), path: cfg.SessionTokenFile},
+		reloadInterval:  reloadInterval,
+		now:             time.Now,
+	}
+}
+
+// Retrieve implements aws.CredentialsProvider.
+func (p *fileCredentialsProvider) Retrieve(_ context.Context) (aws.Credentials, error) {
+	accessKeyID, err := p.accessKeyID.read()
+	if err != nil {
+		return aws.Credentials{}, err
+	}
+	secretAccessKey, err := p.secretAccessKey.read()
+	if err != nil {
+		return aws.Credentials{}, err
+	}
+	sessionToken, err := p.sessionToken.read()
+	if err != nil {
+		return aws.Credentials{}, err
+	}
+	if accessKeyID == "" || secretAccessKey == "" {
+		return aws.Credentials{}, errors.New("the static credentials files hold no access key")
+	}
+	return aws.Credentials{
+		AccessKeyID:     accessKeyID,
+		SecretAccessKey: secretAccessKey,
+		SessionToken:    sessionToken,
+		Source:          "FileCredentials",
+		CanExpire:       true,
+		Expires:         p.now().Add(p.reloadInterval),
+	}, nil
+}
+
+// read returns the credential, without the trailing newline of its file.
+func (f credentialFile) read() (string, error) {
+	if f.path == "" {
+		return f.value, nil
+	}
+	data, err := os.ReadFile(f.path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read the static credentials: %w", err)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
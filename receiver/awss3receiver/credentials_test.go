@@ -0,0 +1,212 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/stretchr/testify/require"
+)
+
+// setTestCredentialsEnv clears the environment variables of the credential sources,
+// then sets the given ones.
+func setTestCredentialsEnv(t *testing.T, env map[string]string) {
+	for _, name := range []string{
+		"AWS_ACCESS_KEY_ID", "AWS_SECRET_ACCESS_KEY", "AWS_SESSION_TOKEN", "AWS_PROFILE",
+		"AWS_WEB_IDENTITY_TOKEN_FILE", "AWS_ROLE_ARN", "AWS_ROLE_SESSION_NAME",
+		"AWS_CONTAINER_CREDENTIALS_FULL_URI", "AWS_CONTAINER_CREDENTIALS_RELATIVE_URI",
+		"AWS_CONTAINER_AUTHORIZATION_TOKEN", containerAuthorizationTokenFileEnvVar,
+	} {
+		t.Setenv(name, env[name])
+	}
+}
+
+func Test_credentialsProvider(t *testing.T) {
+	setTestCredentialsEnv(t, nil)
+	provider, err := credentialsProvider(aws.Config{}, nil, nil)
+	require.NoError(t, err)
+	require.Nil(t, provider)
+	provider, err = credentialsProvider(aws.Config{}, &S3CredentialsConfig{Source: CredentialsSourceDefault}, nil)
+	require.NoError(t, err)
+	require.Nil(t, provider)
+
+	// The sources are not skipped when their settings are missing.
+	for _, source := range []string{CredentialsSourceEnvironment, CredentialsSourceWebIdentity, CredentialsSourceContainer} {
+		_, err = credentialsProvider(aws.Config{}, &S3CredentialsConfig{Source: source}, nil)
+		require.ErrorContains(t, err, fmt.Sprintf("the %s credentials source requires", source))
+	}
+
+	setTestCredentialsEnv(t, map[string]string{"AWS_ACCESS_KEY_ID": "AKID", "AWS_SECRET_ACCESS_KEY": "SECRET"})
+	provider, err = credentialsProvider(aws.Config{}, &S3CredentialsConfig{Source: CredentialsSourceEnvironment}, nil)
+	require.NoError(t, err)
+	credentials, err := provider.Retrieve(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "AKID", credentials.AccessKeyID)
+	require.Equal(t, "SECRET", credentials.SecretAccessKey)
+
+	provider, err = credentialsProvider(aws.Config{}, &S3CredentialsConfig{AccessKeyID: "STATICAKID", SecretAccessKey: "STATICSECRET", SessionToken: "TOKEN"}, nil)
+	require.NoError(t, err)
+	credentials, err = provider.Retrieve(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "STATICAKID", credentials.AccessKeyID)
+	require.Equal(t, "STATICSECRET", credentials.SecretAccessKey)
+	require.Equal(t, "TOKEN", credentials.SessionToken)
+
+	provider, err = credentialsProvider(aws.Config{}, &S3CredentialsConfig{
+		Source:               CredentialsSourceWebIdentity,
+		WebIdentityTokenFile: filepath.Join(t.TempDir(), "token"),
+		WebIdentityRoleARN:   "arn:aws:iam::123456789012:role/reader",
+	}, nil)
+	require.NoError(t, err)
+	require.NotNil(t, provider)
+}
+
+func Test_credentialsProvider_Container(t *testing.T) {
+	var authorization string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		fmt.Fprintf(w, `{"AccessKeyId":"AKID","SecretAccessKey":"SECRET","Token":"TOKEN","Expiration":%q}`,
+			time.Now().Add(time.Hour).UTC().Format(time.RFC3339))
+	}))
+	defer server.Close()
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(tokenFile, []byte("pod-token"), 0o600))
+	setTestCredentialsEnv(t, map[string]string{
+		"AWS_ACCESS_KEY_ID":                   "ENVAKID",
+		"AWS_SECRET_ACCESS_KEY":               "ENVSECRET",
+		"AWS_CONTAINER_CREDENTIALS_FULL_URI":  server.URL,
+		containerAuthorizationTokenFileEnvVar: tokenFile,
+	})
+
+	provider, err := credentialsProvider(aws.Config{}, &S3CredentialsConfig{Source: CredentialsSourceContainer}, nil)
+	require.NoError(t, err)
+	credentials, err := provider.Retrieve(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "AKID", credentials.AccessKeyID)
+	require.Equal(t, "pod-token", authorization)
+	server.CloseClientConnections()
+}
+
+func Test_credentialsLoadOptions(t *testing.T) {
+	require.Nil(t, credentialsLoadOptions(nil))
+	require.Nil(t, credentialsLoadOptions(&S3CredentialsConfig{Source: CredentialsSourceEnvironment}))
+
+	credentialsFile := filepath.Join(t.TempDir(), "credentials")
+	require.NoError(t, os.WriteFile(credentialsFile, []byte("[tenant]\naws_access_key_id = TENANTAKID\naws_secret_access_key = TENANTSECRET\n"), 0o600))
+	// The profile is used even when the environment holds credentials.
+	setTestCredentialsEnv(t, map[string]string{"AWS_ACCESS_KEY_ID": "AKID", "AWS_SECRET_ACCESS_KEY": "SECRET"})
+	awsCfg, err := config.LoadDefaultConfig(context.Background(), credentialsLoadOptions(&S3CredentialsConfig{
+		Profile:                "tenant",
+		SharedCredentialsFiles: []string{credentialsFile},
+		SharedConfigFiles:      []string{filepath.Join(t.TempDir(), "config")},
+	})...)
+	require.NoError(t, err)
+	credentials, err := awsCfg.Credentials.Retrieve(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "TENANTAKID", credentials.AccessKeyID)
+}
+
+func Test_loadAWSConfig_STS(t *testing.T) {
+	var mu sync.Mutex
+	var actions, scopes []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		action := r.Form.Get("Action")
+		mu.Lock()
+		actions = append(actions, action)
+		// The scope of the signature, if any, tells the region of the request.
+		if authorization := r.Header.Get("Authorization"); authorization != "" {
+			scopes = append(scopes, strings.Split(authorization, "/")[2])
+		}
+		mu.Unlock()
+		fmt.Fprintf(w, `<%[1]sResponse><%[1]sResult><Credentials><AccessKeyId>ASIA%[1]s</AccessKeyId><SecretAccessKey>SECRET</SecretAccessKey>`+
+			`<SessionToken>TOKEN</SessionToken><Expiration>%[2]s</Expiration></Credentials></%[1]sResult></%[1]sResponse>`,
+			action, time.Now().Add(time.Hour).UTC().Format(time.RFC3339))
+	}))
+	defer server.Close()
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(tokenFile, []byte("pod-token"), 0o600))
+	setTestCredentialsEnv(t, nil)
+
+	// The web identity role and role_arn are both assumed through the endpoint.
+	cfg := S3DownloaderConfig{
+		Region:  "eu-west-1",
+		RoleARN: "arn:aws:iam::123456789012:role/reader",
+		Credentials: &S3CredentialsConfig{
+			Source:               CredentialsSourceWebIdentity,
+			WebIdentityTokenFile: tokenFile,
+			WebIdentityRoleARN:   "arn:aws:iam::123456789012:role/pod",
+		},
+		STS: &S3STSConfig{Region: "eu-central-1", Endpoint: server.URL},
+	}
+	awsCfg, err := loadAWSConfig(context.Background(), cfg)
+	require.NoError(t, err)
+	credentials, err := awsCfg.Credentials.Retrieve(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "ASIAAssumeRole", credentials.AccessKeyID)
+	require.Equal(t, []string{"AssumeRoleWithWebIdentity", "AssumeRole"}, actions)
+	require.Equal(t, []string{"eu-central-1"}, scopes)
+	// The requests to S3 keep their region.
+	require.Equal(t, "eu-west-1", awsCfg.Region)
+
+	// Without sts, the requests to STS are sent to the endpoint of the region.
+	require.Equal(t, "https://sts.eu-west-1.amazonaws.com", stsEndpoint(t, awsCfg, nil))
+	require.Equal(t, "https://sts.eu-central-1.amazonaws.com", stsEndpoint(t, awsCfg, &S3STSConfig{Region: "eu-central-1"}))
+	require.Equal(t, "https://sts.amazonaws.com", stsEndpoint(t, awsCfg, &S3STSConfig{Region: "aws-global"}))
+}
+
+// stsEndpoint returns the endpoint the STS client of cfg sends its requests to.
+func stsEndpoint(t *testing.T, awsCfg aws.Config, cfg *S3STSConfig) string {
+	client := newSTSClient(awsCfg, cfg)
+	options := client.Options()
+	endpoint, err := options.EndpointResolverV2.ResolveEndpoint(context.Background(), sts.EndpointParameters{
+		Region:            aws.String(options.Region),
+		UseFIPS:           aws.Bool(false),
+		UseDualStack:      aws.Bool(false),
+		UseGlobalEndpoint: aws.Bool(false),
+		Endpoint:          options.BaseEndpoint,
+	})
+	require.NoError(t, err)
+	return endpoint.URI.String()
+}
+
+func Test_serviceClients_Credentials(t *testing.T) {
+	setTestCredentialsEnv(t, nil)
+	cfg := S3DownloaderConfig{
+		Region:      "eu-west-1",
+		Credentials: &S3CredentialsConfig{AccessKeyID: "STATICAKID", SecretAccessKey: "STATICSECRET"},
+	}
+
+	// The queue and the tables are read with the credentials of s3downloader, in
+	// their own region or else in the one of s3downloader.
+	sqsClient, err := newSQSClient(context.Background(), cfg, SQSConfig{Region: "eu-central-1"})
+	require.NoError(t, err)
+	sqsOptions := sqsClient.(*sqs.Client).Options()
+	require.Equal(t, "eu-central-1", sqsOptions.Region)
+	credentials, err := sqsOptions.Credentials.Retrieve(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "STATICAKID", credentials.AccessKeyID)
+
+	dynamoDBClient, err := newDynamoDBClient(context.Background(), cfg, DynamoDBConfig{TableName: "state"})
+	require.NoError(t, err)
+	dynamoDBOptions := dynamoDBClient.(*dynamodb.Client).Options()
+	require.Equal(t, "eu-west-1", dynamoDBOptions.Region)
+	credentials, err = dynamoDBOptions.Credentials.Retrieve(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "STATICAKID", credentials.AccessKeyID)
+}
@@ -0,0 +1,84 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"context"
+	"errors"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/smithy-go"
+	"github.com/aws/smithy-go/middleware"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+)
+
+// credentialsInvalidator is implemented by the cached credentials providers, such
+// as aws.CredentialsCache, whose credentials can be discarded to retrieve new
+// ones.
+type credentialsInvalidator interface {
+	Invalidate()
+}
+
+// rejectedCredentialsErrorCodes are the error codes of the requests to S3 signed
+// with credentials which have expired, such as the credentials of an assumed
+// role whose session ended, or have been revoked, such as rotated keys.
+var rejectedCredentialsErrorCodes = map[string]struct{}{
+	"ExpiredToken":          {},
+	"ExpiredTokenException": {},
+	"TokenRefreshRequired":  {},
+	"InvalidAccessKeyId":    {},
+}
+
+// credentialsRefreshOptions returns the options of the S3 client sending again,
+// once, the requests whose credentials are rejected, after invalidating the cached
+// credentials, so that a long run outliving its credentials goes on with new
+// ones rather than failing in the middle of a listing.
+func credentialsRefreshOptions(invalidator credentialsInvalidator) func(*s3.Options) {
+	return func(o *s3.Options) {
+		o.APIOptions = append(o.APIOptions, credentialsRefreshMiddleware(invalidator))
+	}
+}
+
+// credentialsRefreshMiddleware returns the API option retrying the requests
+// whose credentials are rejected. The retries of the retry policy are signed with
+// the credentials of the first attempt, so that the request is sent again from
+// before the credentials are retrieved, with all the retries of the policy.
+func credentialsRefreshMiddleware(invalidator credentialsInvalidator) func(*middleware.Stack) error {
+	return func(stack *middleware.Stack) error {
+		refresh := middleware.FinalizeMiddlewareFunc("RefreshCredentials", func(ctx context.Context, in middleware.FinalizeInput, next middleware.FinalizeHandler) (middleware.FinalizeOutput, middleware.Metadata, error) {
+			req, ok := in.Request.(*smithyhttp.Request)
+			if !ok {
+				return next.HandleFinalize(ctx, in)
+			}
+			// The following middlewares update the request, which is sent again as it
+			// was before them.
+			attempt := in
+			attempt.Request = req.Clone()
+			out, metadata, err := next.HandleFinalize(ctx, attempt)
+			if !isRejectedCredentials(err) {
+				return out, metadata, err
+			}
+			// The body of the request, such as of a checkpoint, is sent again from
+			// its start.
+			if rewindErr := req.RewindStream(); rewindErr != nil {
+				return out, metadata, err
+			}
+			invalidator.Invalidate()
+			attempt.Request = req.Clone()
+			return next.HandleFinalize(ctx, attempt)
+		})
+		return stack.Finalize.Insert(refresh, "GetIdentity", middleware.Before)
+	}
+}
+
+// isRejectedCredentials tells whether err is the error of a request whose
+// credentials were rejected.
+func isRejectedCredentials(err error) bool {
+	var apiErr smithy.APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	_, ok := rejectedCredentialsErrorCodes[apiErr.ErrorCode()]
+	return ok
+}
@@ -0,0 +1,168 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/stretchr/testify/require"
+)
+
+// expiringCredentialsServer serves a listing of two pages, the credentials of
+// the first page expiring once it has been served.
+type expiringCredentialsServer struct {
+	mu sync.Mutex
+	// expired are the access keys rejected as expired.
+	expired map[string]bool
+	// onFirstPage is called once the first page has been served.
+	onFirstPage func(accessKeyID string)
+	rejected    atomic.Int32
+}
+
+func (s *expiringCredentialsServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	accessKeyID := strings.TrimPrefix(strings.Split(r.Header.Get("Authorization"), "/")[0], "AWS4-HMAC-SHA256 Credential=")
+	s.mu.Lock()
+	expired := s.expired[accessKeyID]
+	s.mu.Unlock()
+	if expired {
+		s.rejected.Add(1)
+		writeError(http.StatusBadRequest, "ExpiredToken")(w)
+		return
+	}
+	if r.URL.Query().Get("continuation-token") == "" {
+		_, _ = w.Write([]byte("<ListBucketResult><Name>bucket</Name><IsTruncated>true</IsTruncated><NextContinuationToken>page2</NextContinuationToken>" +
+			"<Contents><Key>otel/traces_1</Key></Contents></ListBucketResult>"))
+		s.mu.Lock()
+		s.expired[accessKeyID] = true
+		s.mu.Unlock()
+		s.onFirstPage(accessKeyID)
+		return
+	}
+	_, _ = w.Write([]byte("<ListBucketResult><Name>bucket</Name><IsTruncated>false</IsTruncated><Contents><Key>otel/traces_2</Key></Contents></ListBucketResult>"))
+}
+
+// listAll lists all the objects of the bucket with client.
+func listAll(ctx context.Context, client ListObjectsAPI) ([]string, error) {
+	var keys []string
+	pager := client.NewListObjectsV2Paginator(&s3.ListObjectsV2Input{Bucket: aws.String("bucket")})
+	for pager.HasMorePages() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return keys, err
+		}
+		for _, object := range page.Contents {
+			keys = append(keys, aws.ToString(object.Key))
+		}
+	}
+	return keys, nil
+}
+
+func Test_newS3Client_RotatedCredentialsFiles(t *testing.T) {
+	dir := t.TempDir()
+	accessKeyIDFile, secretAccessKeyFile := filepath.Join(dir, "access_key_id"), filepath.Join(dir, "secret_access_key")
+	require.NoError(t, os.WriteFile(accessKeyIDFile, []byte("AKID1\n"), 0o600))
+	require.NoError(t, os.WriteFile(secretAccessKeyFile, []byte("SECRET1\n"), 0o600))
+	server := &expiringCredentialsServer{expired: map[string]bool{}, onFirstPage: func(string) {
+		// The keys are rotated in the middle of the listing.
+		require.NoError(t, os.WriteFile(accessKeyIDFile, []byte("AKID2\n"), 0o600))
+		require.NoError(t, os.WriteFile(secretAccessKeyFile, []byte("SECRET2\n"), 0o600))
+	}}
+	httpServer := httptest.NewServer(server)
+	defer httpServer.Close()
+
+	cfg := startupCheckConfig(httpServer.URL)
+	cfg.Credentials = &S3CredentialsConfig{
+		AccessKeyIDFile:     accessKeyIDFile,
+		SecretAccessKeyFile: secretAccessKeyFile,
+		ReloadInterval:      time.Hour,
+	}
+	listObjectsClient, _, err := newS3Client(context.Background(), cfg)
+	require.NoError(t, err)
+	// The request rejected with the expired keys is sent again with the keys read
+	// from the files, although retry max_attempts is 1.
+	keys, err := listAll(context.Background(), listObjectsClient)
+	require.NoError(t, err)
+	require.Equal(t, []string{"otel/traces_1", "otel/traces_2"}, keys)
+	require.Equal(t, int32(1), server.rejected.Load())
+}
+
+func Test_newS3Client_ExpiredSession(t *testing.T) {
+	var retrievals atomic.Int32
+	// The credentials of the session stand for the ones of an assumed role,
+	// expired by the server before the end of their session.
+	session := aws.NewCredentialsCache(aws.CredentialsProviderFunc(func(context.Context) (aws.Credentials, error) {
+		n := retrievals.Add(1)
+		return aws.Credentials{
+			AccessKeyID:     "ASIA" + string(rune('0'+n)),
+			SecretAccessKey: "SECRET",
+			SessionToken:    "TOKEN",
+			CanExpire:       true,
+			Expires:         time.Now().Add(time.Hour),
+		}, nil
+	}))
+	server := &expiringCredentialsServer{expired: map[string]bool{}, onFirstPage: func(string) {}}
+	httpServer := httptest.NewServer(server)
+	defer httpServer.Close()
+
+	newClient := func(credentials aws.CredentialsProvider, optFns ...func(*s3.Options)) ListObjectsAPI {
+		client := s3.New(s3.Options{
+			Region:           "us-east-1",
+			BaseEndpoint:     aws.String(httpServer.URL),
+			UsePathStyle:     true,
+			Credentials:      credentials,
+			RetryMaxAttempts: 1,
+		}, optFns...)
+		return &s3ListObjectsAPIImpl{client: client}
+	}
+	keys, err := listAll(context.Background(), newClient(session, credentialsRefreshOptions(session)))
+	require.NoError(t, err)
+	require.Equal(t, []string{"otel/traces_1", "otel/traces_2"}, keys)
+	require.Equal(t, int32(2), retrievals.Load())
+	require.Equal(t, int32(1), server.rejected.Load())
+
+	// Without refresh, the listing fails once the credentials have expired.
+	server.expired = map[string]bool{}
+	session.Invalidate()
+	keys, err = listAll(context.Background(), newClient(session))
+	require.ErrorContains(t, err, "ExpiredToken")
+	require.Equal(t, []string{"otel/traces_1"}, keys)
+}
+
+func Test_fileCredentialsProvider(t *testing.T) {
+	dir := t.TempDir()
+	secretAccessKeyFile := filepath.Join(dir, "secret_access_key")
+	require.NoError(t, os.WriteFile(secretAccessKeyFile, []byte("SECRET\n"), 0o600))
+	provider := newFileCredentialsProvider(S3CredentialsConfig{AccessKeyID: "AKID", SecretAccessKeyFile: secretAccessKeyFile, SessionToken: "TOKEN"})
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	provider.now = func() time.Time { return now }
+	credentials, err := provider.Retrieve(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, aws.Credentials{
+		AccessKeyID:     "AKID",
+		SecretAccessKey: "SECRET",
+		SessionToken:    "TOKEN",
+		Source:          "FileCredentials",
+		CanExpire:       true,
+		Expires:         now.Add(defaultCredentialsReloadInterval),
+	}, credentials)
+
+	require.NoError(t, os.WriteFile(secretAccessKeyFile, nil, 0o600))
+	_, err = provider.Retrieve(context.Background())
+	require.EqualError(t, err, "the static credentials files hold no access key")
+
+	require.NoError(t, os.Remove(secretAccessKeyFile))
+	_, err = provider.Retrieve(context.Background())
+	require.ErrorContains(t, err, "failed to read the static credentials: ")
+}
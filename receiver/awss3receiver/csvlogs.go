@@ -0,0 +1,107 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"io"
+	"slices"
+	"unicode/utf8"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.uber.org/zap"
+)
+
+// csvDecoder decodes objects holding delimiter separated values into a log record
+// per row.
+type csvDecoder struct {
+	cfg        CSVConfig
+	delimiter  rune
+	timestamps timestampParser
+	logger     *zap.Logger
+}
+
+func newCSVDecoder(cfg CSVConfig, format string, logger *zap.Logger) *csvDecoder {
+	delimiter := ','
+	if format == FormatTSV {
+		delimiter = '\t'
+	}
+	if cfg.Delimiter != "" {
+		delimiter, _ = utf8.DecodeRuneInString(cfg.Delimiter)
+	}
+	timestamps, _ := cfg.parser()
+	return &csvDecoder{cfg: cfg, delimiter: delimiter, timestamps: timestamps, logger: logger}
+}
+
+func (d *csvDecoder) decodeLogs(info objectInfo, data []byte) (plog.Logs, error) {
+	logs, records := newObjectLogs()
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.Comma = d.delimiter
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	columns := d.cfg.Columns
+	for {
+		row, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return logs, nil
+		}
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			d.logger.Warn("Skipping invalid CSV row", zap.String("key", info.key), zap.Error(err))
+			continue
+		}
+		if err != nil {
+			return logs, err
+		}
+		if len(columns) == 0 {
+			columns = row
+			continue
+		}
+		d.setRecord(appendObjectRecord(records, info), columns, row, info)
+	}
+}
+
+// setRecord sets the body, attributes and timestamp of a record from a row.
+func (d *csvDecoder) setRecord(record plog.LogRecord, columns, row []string, info objectInfo) {
+	values := make(map[string]string, len(columns))
+	for i, column := range columns {
+		if i < len(row) {
+			values[column] = row[i]
+		}
+	}
+	if d.cfg.TimestampColumn != "" {
+		if value, ok := values[d.cfg.TimestampColumn]; ok {
+			timestamp, err := d.timestamps.parse(value)
+			if err != nil {
+				d.logger.Warn("Unable to parse the timestamp of a CSV row", zap.String("key", info.key), zap.Error(err))
+			} else {
+				record.SetTimestamp(pcommon.NewTimestampFromTime(timestamp))
+			}
+		}
+	}
+	if d.cfg.BodyColumn == "" {
+		body := record.Body().SetEmptyMap()
+		for column, value := range values {
+			body.PutStr(column, value)
+		}
+	} else {
+		record.Body().SetStr(values[d.cfg.BodyColumn])
+	}
+	for column, value := range values {
+		if d.isAttribute(column) {
+			record.Attributes().PutStr(column, value)
+		}
+	}
+}
+
+// isAttribute reports whether a column is set as an attribute of the records.
+func (d *csvDecoder) isAttribute(column string) bool {
+	if len(d.cfg.AttributeColumns) > 0 {
+		return slices.Contains(d.cfg.AttributeColumns, column)
+	}
+	return d.cfg.BodyColumn != "" && column != d.cfg.BodyColumn && column != d.cfg.TimestampColumn
+}
@@ -0,0 +1,49 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func Test_csvDecoder(t *testing.T) {
+	info := objectInfo{bucket: "bucket", key: "exports/logs_1.csv"}
+	data := []byte("time,user,action,detail\n2021-02-01T17:00:00Z,alice,login,\"from \"\"web\"\"\"\n2021-02-01T17:05:00Z,bob,logout\n")
+
+	decoder := newCSVDecoder(CSVConfig{BodyColumn: "action", TimestampColumn: "time"}, FormatCSV, zap.NewNop())
+	logs, err := decoder.decodeLogs(info, data)
+	require.NoError(t, err)
+	records := logs.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords()
+	require.Equal(t, 2, records.Len())
+	require.Equal(t, "login", records.At(0).Body().Str())
+	require.Equal(t, time.Date(2021, 2, 1, 17, 0, 0, 0, time.UTC), records.At(0).Timestamp().AsTime())
+	require.Equal(t, map[string]any{
+		"aws.s3.bucket": "bucket",
+		"aws.s3.key":    "exports/logs_1.csv",
+		"user":          "alice",
+		"detail":        `from "web"`,
+	}, records.At(0).Attributes().AsRaw())
+	require.Equal(t, map[string]any{
+		"aws.s3.bucket": "bucket",
+		"aws.s3.key":    "exports/logs_1.csv",
+		"user":          "bob",
+	}, records.At(1).Attributes().AsRaw())
+
+	decoder = newCSVDecoder(CSVConfig{Columns: []string{"user", "action"}, AttributeColumns: []string{"user"}}, FormatTSV, zap.NewNop())
+	logs, err = decoder.decodeLogs(info, []byte("alice\tlogin\n"))
+	require.NoError(t, err)
+	record := logs.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0)
+	require.Equal(t, map[string]any{"user": "alice", "action": "login"}, record.Body().Map().AsRaw())
+	require.Equal(t, "alice", record.Attributes().AsRaw()["user"])
+
+	decoder = newCSVDecoder(CSVConfig{Columns: []string{"user", "action"}, Delimiter: ";"}, FormatCSV, zap.NewNop())
+	logs, err = decoder.decodeLogs(info, []byte("alice;login\n"))
+	require.NoError(t, err)
+	record = logs.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0)
+	require.Equal(t, map[string]any{"user": "alice", "action": "login"}, record.Body().Map().AsRaw())
+}
@@ -0,0 +1,269 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	conventions "go.opentelemetry.io/collector/semconv/v1.25.0"
+)
+
+// curCostMetrics are the cost columns of the Cost and Usage Reports and the names
+// of the metrics of their sums.
+var curCostMetrics = []struct {
+	column string
+	name   string
+}{
+	{column: "line_item_unblended_cost", name: "aws.cur.unblended_cost"},
+	{column: "line_item_blended_cost", name: "aws.cur.blended_cost"},
+	{column: "line_item_net_unblended_cost", name: "aws.cur.net_unblended_cost"},
+}
+
+// curAttributes are the columns of the line items set as attributes of the data
+// points.
+var curAttributes = []struct {
+	column    string
+	attribute string
+}{
+	{column: "line_item_product_code", attribute: "aws.cur.product_code"},
+	{column: "line_item_line_item_type", attribute: "aws.cur.line_item_type"},
+	{column: "line_item_usage_type", attribute: "aws.cur.usage_type"},
+	{column: "product_region_code", attribute: conventions.AttributeCloudRegion},
+	{column: "product_region", attribute: conventions.AttributeCloudRegion},
+}
+
+const curResourceTagsColumn = "resource_tags"
+
+// curResource identifies the payer and usage accounts of line items.
+type curResource struct {
+	payerAccountID string
+	accountID      string
+}
+
+// curUnmarshaler unmarshals the CSV or Parquet files of the AWS Cost and Usage
+// Reports, legacy or 2.0, into gauges summing the costs of their line items by
+// usage account, service, line item and usage type, region, resource tags and
+// usage period, a data point per group. The metric of each cost column is named
+// after it, its unit being the currency of the line items.
+type curUnmarshaler struct{}
+
+func (curUnmarshaler) UnmarshalMetrics(buf []byte) (pmetric.Metrics, error) {
+	metrics := &curMetrics{
+		metrics:    pmetric.NewMetrics(),
+		resources:  map[curResource]pmetric.MetricSlice{},
+		gauges:     map[curResource]map[string]pmetric.Metric{},
+		dataPoints: map[string]pmetric.NumberDataPoint{},
+	}
+	err := forEachTableRow(buf, curColumnName, metrics.appendLineItem)
+	return metrics.metrics, err
+}
+
+// curMetrics groups the costs of line items in resources by account, and in data
+// points by attributes and usage period.
+type curMetrics struct {
+	metrics    pmetric.Metrics
+	resources  map[curResource]pmetric.MetricSlice
+	gauges     map[curResource]map[string]pmetric.Metric
+	dataPoints map[string]pmetric.NumberDataPoint
+}
+
+func (m *curMetrics) appendLineItem(lineItem map[string]any) {
+	resource := curResource{
+		payerAccountID: curString(lineItem["bill_payer_account_id"]),
+		accountID:      curString(lineItem["line_item_usage_account_id"]),
+	}
+	attributes := pcommon.NewMap()
+	for _, column := range curAttributes {
+		if _, ok := attributes.Get(column.attribute); !ok {
+			putNonEmptyStr(attributes, column.attribute, curString(lineItem[column.column]))
+		}
+	}
+	for name, value := range curResourceTags(lineItem) {
+		attributes.PutStr("aws.cur.resource_tags."+name, value)
+	}
+	start, end := curTime(lineItem["line_item_usage_start_date"]), curTime(lineItem["line_item_usage_end_date"])
+	unit := "{" + curString(lineItem["line_item_currency_code"]) + "}"
+	if unit == "{}" {
+		unit = "{USD}"
+	}
+	key := curDataPointKey(resource, attributes, start, end)
+	for _, metric := range curCostMetrics {
+		cost, ok := curNumber(lineItem[metric.column])
+		if !ok {
+			continue
+		}
+		dataPointKey := metric.name + "\x00" + unit + "\x00" + key
+		dataPoint, ok := m.dataPoints[dataPointKey]
+		if !ok {
+			dataPoint = m.gauge(resource, metric.name, unit).Gauge().DataPoints().AppendEmpty()
+			attributes.CopyTo(dataPoint.Attributes())
+			if !start.IsZero() {
+				dataPoint.SetStartTimestamp(pcommon.NewTimestampFromTime(start))
+			}
+			if !end.IsZero() {
+				dataPoint.SetTimestamp(pcommon.NewTimestampFromTime(end))
+			}
+			m.dataPoints[dataPointKey] = dataPoint
+		}
+		dataPoint.SetDoubleValue(dataPoint.DoubleValue() + cost)
+	}
+}
+
+// gauge returns the gauge of the data points of a metric of a resource.
+func (m *curMetrics) gauge(resource curResource, name, unit string) pmetric.Metric {
+	metricSlice, ok := m.resources[resource]
+	if !ok {
+		resourceMetrics := m.metrics.ResourceMetrics().AppendEmpty()
+		attributes := resourceMetrics.Resource().Attributes()
+		attributes.PutStr(conventions.AttributeCloudProvider, conventions.AttributeCloudProviderAWS)
+		putNonEmptyStr(attributes, conventions.AttributeCloudAccountID, resource.accountID)
+		putNonEmptyStr(attributes, "aws.cur.payer_account_id", resource.payerAccountID)
+		metricSlice = resourceMetrics.ScopeMetrics().AppendEmpty().Metrics()
+		m.resources[resource] = metricSlice
+		m.gauges[resource] = map[string]pmetric.Metric{}
+	}
+	gauge, ok := m.gauges[resource][name+"\x00"+unit]
+	if !ok {
+		gauge = metricSlice.AppendEmpty()
+		gauge.SetName(name)
+		gauge.SetUnit(unit)
+		gauge.SetEmptyGauge()
+		m.gauges[resource][name+"\x00"+unit] = gauge
+	}
+	return gauge
+}
+
+// curDataPointKey returns the key identifying the data points of a resource with
+// the given attributes and usage period.
+func curDataPointKey(resource curResource, attributes pcommon.Map, start, end time.Time) string {
+	parts := make([]string, 0, attributes.Len()+4)
+	attributes.Range(func(k string, v pcommon.Value) bool {
+		parts = append(parts, k+"="+v.Str())
+		return true
+	})
+	sort.Strings(parts)
+	parts = append(parts, resource.payerAccountID, resource.accountID,
+		strconv.FormatInt(start.UnixNano(), 10), strconv.FormatInt(end.UnixNano(), 10))
+	return strings.Join(parts, "\x00")
+}
+
+// curResourceTags returns the non-empty resource tags of a line item, held by the
+// resource_tags_<name> columns of the legacy reports or by the resource_tags map
+// of the 2.0 ones, a JSON object in their CSV files.
+func curResourceTags(lineItem map[string]any) map[string]string {
+	tags := map[string]string{}
+	for column, value := range lineItem {
+		if name, ok := strings.CutPrefix(column, curResourceTagsColumn+"_"); ok {
+			putNonEmptyTag(tags, name, curString(value))
+		}
+	}
+	switch value := lineItem[curResourceTagsColumn].(type) {
+	case map[string]any:
+		for name, tag := range value {
+			putNonEmptyTag(tags, name, curString(tag))
+		}
+	case string:
+		var object map[string]string
+		if json.Unmarshal([]byte(value), &object) == nil {
+			for name, tag := range object {
+				putNonEmptyTag(tags, name, tag)
+			}
+		}
+	}
+	return tags
+}
+
+func putNonEmptyTag(tags map[string]string, name, value string) {
+	if value != "" {
+		tags[curColumnName(name)] = value
+	}
+}
+
+// curColumnName returns the name of a column of the Cost and Usage Reports as in
+// their Parquet files, in snake case: lineItem/UsageAccountId, as named in the
+// CSV files of the legacy reports, becomes line_item_usage_account_id, and
+// resourceTags/user:Team becomes resource_tags_user_team.
+func curColumnName(name string) string {
+	var b strings.Builder
+	runes := []rune(name)
+	previous := '_'
+	for i, r := range runes {
+		switch {
+		case unicode.IsUpper(r):
+			// An upper case letter starts a word after a lower case one, and at the
+			// end of an acronym, as the B of MPUBytes.
+			next := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(previous) || unicode.IsDigit(previous) || (unicode.IsUpper(previous) && next) {
+				b.WriteByte('_')
+			}
+			b.WriteRune(unicode.ToLower(r))
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			b.WriteRune(r)
+		default:
+			r = '_'
+			if previous != '_' {
+				b.WriteRune(r)
+			}
+		}
+		previous = r
+	}
+	return b.String()
+}
+
+// curString returns a value of a line item as a string.
+func curString(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	default:
+		encoded, _ := json.Marshal(v)
+		return string(encoded)
+	}
+}
+
+// curNumber returns a numeric value of a line item, held as a string in the CSV
+// files.
+func curNumber(value any) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case int64:
+		return float64(v), true
+	case string:
+		number, err := strconv.ParseFloat(v, 64)
+		return number, err == nil
+	default:
+		return 0, false
+	}
+}
+
+// curTime returns a time of a line item, an RFC 3339 time in the CSV files and a
+// timestamp, in milliseconds since the epoch once read, in the Parquet files. The
+// zero time is returned for invalid times.
+func curTime(value any) time.Time {
+	switch v := value.(type) {
+	case int64:
+		return time.UnixMilli(v).UTC()
+	case string:
+		for _, layout := range []string{time.RFC3339, "2006-01-02T15:04Z", "2006-01-02 15:04:05"} {
+			if t, err := time.Parse(layout, v); err == nil {
+				return t
+			}
+		}
+	}
+	return time.Time{}
+}
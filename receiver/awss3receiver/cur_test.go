@@ -0,0 +1,128 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow/go/v15/arrow"
+	"github.com/apache/arrow/go/v15/arrow/array"
+	"github.com/apache/arrow/go/v15/arrow/memory"
+	"github.com/apache/arrow/go/v15/parquet/pqarrow"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+func Test_curUnmarshaler_CSV(t *testing.T) {
+	data := []byte(`identity/LineItemId,bill/PayerAccountId,lineItem/UsageAccountId,lineItem/LineItemType,lineItem/UsageStartDate,lineItem/UsageEndDate,lineItem/ProductCode,lineItem/UsageType,lineItem/CurrencyCode,lineItem/UnblendedCost,lineItem/BlendedCost,product/region,resourceTags/user:Team
+1,111111111111,222222222222,Usage,2024-01-01T00:00:00Z,2024-01-01T01:00:00Z,AmazonEC2,BoxUsage:t3.micro,USD,0.0104,0.0104,us-east-1,web
+2,111111111111,222222222222,Usage,2024-01-01T00:00:00Z,2024-01-01T01:00:00Z,AmazonEC2,BoxUsage:t3.micro,USD,0.0104,0.0100,us-east-1,web
+3,111111111111,222222222222,Usage,2024-01-01T00:00:00Z,2024-01-01T01:00:00Z,AmazonS3,TimedStorage-ByteHrs,USD,0.5,,us-east-1,
+4,111111111111,333333333333,Tax,2024-01-01T00:00:00Z,2024-02-01T00:00:00Z,AmazonEC2,,USD,1.25,1.25,,
+`)
+
+	metrics, err := curUnmarshaler{}.UnmarshalMetrics(data)
+	require.NoError(t, err)
+	require.Equal(t, 2, metrics.ResourceMetrics().Len())
+	resourceMetrics := metrics.ResourceMetrics().At(0)
+	require.Equal(t, map[string]any{
+		"cloud.provider":           "aws",
+		"cloud.account.id":         "222222222222",
+		"aws.cur.payer_account_id": "111111111111",
+	}, resourceMetrics.Resource().Attributes().AsRaw())
+
+	metricSlice := resourceMetrics.ScopeMetrics().At(0).Metrics()
+	require.Equal(t, 2, metricSlice.Len())
+	unblended := metricSlice.At(0)
+	require.Equal(t, "aws.cur.unblended_cost", unblended.Name())
+	require.Equal(t, "{USD}", unblended.Unit())
+	require.Equal(t, pmetric.MetricTypeGauge, unblended.Type())
+	dataPoints := unblended.Gauge().DataPoints()
+	require.Equal(t, 2, dataPoints.Len())
+	require.InDelta(t, 0.0208, dataPoints.At(0).DoubleValue(), 1e-9)
+	require.Equal(t, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), dataPoints.At(0).StartTimestamp().AsTime())
+	require.Equal(t, time.Date(2024, 1, 1, 1, 0, 0, 0, time.UTC), dataPoints.At(0).Timestamp().AsTime())
+	require.Equal(t, map[string]any{
+		"aws.cur.product_code":            "AmazonEC2",
+		"aws.cur.line_item_type":          "Usage",
+		"aws.cur.usage_type":              "BoxUsage:t3.micro",
+		"cloud.region":                    "us-east-1",
+		"aws.cur.resource_tags.user_team": "web",
+	}, dataPoints.At(0).Attributes().AsRaw())
+	require.Equal(t, 0.5, dataPoints.At(1).DoubleValue())
+	require.Equal(t, "AmazonS3", dataPoints.At(1).Attributes().AsRaw()["aws.cur.product_code"])
+
+	blended := metricSlice.At(1)
+	require.Equal(t, "aws.cur.blended_cost", blended.Name())
+	require.Equal(t, 1, blended.Gauge().DataPoints().Len())
+	require.InDelta(t, 0.0204, blended.Gauge().DataPoints().At(0).DoubleValue(), 1e-9)
+
+	tax := metrics.ResourceMetrics().At(1).ScopeMetrics().At(0).Metrics().At(0).Gauge().DataPoints().At(0)
+	require.Equal(t, 1.25, tax.DoubleValue())
+	require.Equal(t, map[string]any{
+		"aws.cur.product_code":   "AmazonEC2",
+		"aws.cur.line_item_type": "Tax",
+	}, tax.Attributes().AsRaw())
+
+	_, err = curUnmarshaler{}.UnmarshalMetrics([]byte("a,\"b\n"))
+	require.Error(t, err)
+}
+
+func Test_curUnmarshaler_Parquet(t *testing.T) {
+	schema := arrow.NewSchema([]arrow.Field{
+		{Name: "line_item_usage_account_id", Type: arrow.BinaryTypes.String},
+		{Name: "line_item_usage_start_date", Type: &arrow.TimestampType{Unit: arrow.Millisecond}},
+		{Name: "line_item_usage_end_date", Type: &arrow.TimestampType{Unit: arrow.Millisecond}},
+		{Name: "line_item_product_code", Type: arrow.BinaryTypes.String},
+		{Name: "line_item_unblended_cost", Type: arrow.PrimitiveTypes.Float64, Nullable: true},
+		{Name: "product_region_code", Type: arrow.BinaryTypes.String, Nullable: true},
+		{Name: "resource_tags", Type: arrow.MapOf(arrow.BinaryTypes.String, arrow.BinaryTypes.String), Nullable: true},
+	}, nil)
+	builder := array.NewRecordBuilder(memory.DefaultAllocator, schema)
+	defer builder.Release()
+	start := arrow.Timestamp(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC).UnixMilli())
+	end := arrow.Timestamp(time.Date(2024, 1, 1, 1, 0, 0, 0, time.UTC).UnixMilli())
+	builder.Field(0).(*array.StringBuilder).AppendValues([]string{"222222222222", "222222222222"}, nil)
+	builder.Field(1).(*array.TimestampBuilder).AppendValues([]arrow.Timestamp{start, start}, nil)
+	builder.Field(2).(*array.TimestampBuilder).AppendValues([]arrow.Timestamp{end, end}, nil)
+	builder.Field(3).(*array.StringBuilder).AppendValues([]string{"AWSLambda", "AWSLambda"}, nil)
+	builder.Field(4).(*array.Float64Builder).AppendValues([]float64{0.25, 0}, []bool{true, false})
+	builder.Field(5).(*array.StringBuilder).AppendValues([]string{"eu-west-1", ""}, []bool{true, false})
+	tags := builder.Field(6).(*array.MapBuilder)
+	tags.Append(true)
+	tags.KeyBuilder().(*array.StringBuilder).Append("user_team")
+	tags.ItemBuilder().(*array.StringBuilder).Append("api")
+	tags.AppendNull()
+	record := builder.NewRecord()
+	defer record.Release()
+	table := array.NewTableFromRecords(schema, []arrow.Record{record})
+	defer table.Release()
+	var buf bytes.Buffer
+	require.NoError(t, pqarrow.WriteTable(table, &buf, 1024, nil, pqarrow.DefaultWriterProps()))
+
+	metrics, err := curUnmarshaler{}.UnmarshalMetrics(buf.Bytes())
+	require.NoError(t, err)
+	require.Equal(t, 1, metrics.ResourceMetrics().Len())
+	metricSlice := metrics.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics()
+	require.Equal(t, 1, metricSlice.Len())
+	dataPoints := metricSlice.At(0).Gauge().DataPoints()
+	require.Equal(t, 1, dataPoints.Len())
+	require.Equal(t, 0.25, dataPoints.At(0).DoubleValue())
+	require.Equal(t, time.Date(2024, 1, 1, 1, 0, 0, 0, time.UTC), dataPoints.At(0).Timestamp().AsTime())
+	require.Equal(t, map[string]any{
+		"aws.cur.product_code":            "AWSLambda",
+		"cloud.region":                    "eu-west-1",
+		"aws.cur.resource_tags.user_team": "api",
+	}, dataPoints.At(0).Attributes().AsRaw())
+}
+
+func Test_curColumnName(t *testing.T) {
+	require.Equal(t, "line_item_usage_account_id", curColumnName("lineItem/UsageAccountId"))
+	require.Equal(t, "resource_tags_user_team", curColumnName("resourceTags/user:Team"))
+	require.Equal(t, "resource_tags_aws_created_by", curColumnName("resourceTags/aws:createdBy"))
+	require.Equal(t, "line_item_unblended_cost", curColumnName("line_item_unblended_cost"))
+	require.Equal(t, "incomplete_mpu_bytes", curColumnName("IncompleteMPUBytes"))
+}
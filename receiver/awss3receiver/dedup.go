@@ -0,0 +1,256 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/extension/experimental/storage"
+	"go.uber.org/multierr"
+	"go.uber.org/zap"
+)
+
+// processedObjectsKey is the storage key of the processed objects.
+const processedObjectsKey = "processed_objects"
+
+// processedObjectKeyPrefix prefixes the identifiers of the objects in the keys of
+// the processed objects persisted one at a time.
+const processedObjectKeyPrefix = "processed_object/"
+
+// defaultDeduplicationWindow is the time processed objects are remembered for by
+// default.
+const defaultDeduplicationWindow = 24 * time.Hour
+
+// processedObjectsFlushInterval is the interval the objects processed before the
+// window are forgotten on, and the processed objects persisted on in the state
+// clients holding them all under processedObjectsKey.
+const processedObjectsFlushInterval = 10 * time.Second
+
+// processedObjectClaimDuration is the time an object claimed by a collector sharing
+// the state store is skipped by the others while it is processed, after which it
+// may be claimed again should the collector have failed to record it.
+const processedObjectClaimDuration = 15 * time.Minute
+
+// expiringClient is implemented by the state clients expiring the items they hold,
+// which persist the processed objects in an item each rather than all of them under
+// processedObjectsKey, so that the size of the items and of their writes does not
+// grow with the number of objects processed within the window.
+type expiringClient interface {
+	// setExpiring writes the item of key, expiring at expiresAt.
+	setExpiring(ctx context.Context, key string, value []byte, expiresAt time.Time) error
+	// claim writes the item of key, expiring at expiresAt, unless it exists and has
+	// not expired at now, in which case it reports false.
+	claim(ctx context.Context, key string, value []byte, expiresAt, now time.Time) (bool, error)
+}
+
+// processedObjects remembers the objects processed within a sliding window, so
+// that the objects listed again, because of overlapping time ranges or of the
+// lookback, or notified again by SQS, are skipped. The objects are identified by
+// their bucket, key and ETag, so that the objects overwritten with different
+// contents are processed again. They are persisted in the state client if any.
+type processedObjects struct {
+	window time.Duration
+	client storage.Client
+	now    func() time.Time
+	logger *zap.Logger
+
+	mu sync.Mutex
+	// processed maps the identifiers of the objects to the time they were
+	// processed.
+	processed map[string]time.Time
+	// claimed are the identifiers of the objects being processed.
+	claimed map[string]struct{}
+	// dirty reports whether processed changed since it was last persisted under
+	// processedObjectsKey.
+	dirty bool
+	// saveMu serializes the writes of processedObjectsKey, made outside of mu.
+	saveMu sync.Mutex
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func newProcessedObjects(cfg DeduplicationConfig, logger *zap.Logger) *processedObjects {
+	window := cfg.Window
+	if window == 0 {
+		window = defaultDeduplicationWindow
+	}
+	return &processedObjects{window: window, now: time.Now, logger: logger, processed: map[string]time.Time{}, claimed: map[string]struct{}{}}
+}
+
+// start loads the objects persisted in client, if any, and starts flushing the
+// processed objects on the interval. The objects persisted in an item each are only
+// read when they are claimed.
+func (p *processedObjects) start(ctx context.Context, client storage.Client) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.client = client
+	if _, ok := client.(expiringClient); client != nil && !ok {
+		if err := p.load(ctx); err != nil {
+			return err
+		}
+	}
+	ctx, p.cancel = context.WithCancel(context.Background())
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		ticker := time.NewTicker(processedObjectsFlushInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := p.flush(ctx); err != nil {
+					p.logger.Warn("Failed to persist the processed objects", zap.Error(err))
+				}
+			}
+		}
+	}()
+	return nil
+}
+
+// load merges the persisted objects into the processed ones.
+func (p *processedObjects) load(ctx context.Context) error {
+	data, err := p.client.Get(ctx, processedObjectsKey)
+	if err != nil || data == nil {
+		return err
+	}
+	var processed map[string]time.Time
+	if err := json.Unmarshal(data, &processed); err != nil {
+		return fmt.Errorf("unable to load the processed objects: %w", err)
+	}
+	for id, processedAt := range processed {
+		if processedAt.After(p.processed[id]) {
+			p.processed[id] = processedAt
+		}
+	}
+	return nil
+}
+
+// objectID returns the identifier of an object.
+func objectID(info objectInfo) string {
+	return info.bucket + "/" + info.key + "@" + info.etag
+}
+
+// claim reports whether the object is to be processed, neither processed within
+// the window nor being processed, and claims it until done is called. In the state
+// clients persisting an item each, the object is claimed in the item for the
+// collectors sharing the state store, so that one of them only processes it. The
+// object is not claimed if an error is returned.
+func (p *processedObjects) claim(ctx context.Context, info objectInfo) (bool, error) {
+	id := objectID(info)
+	p.mu.Lock()
+	now := p.now()
+	_, claimed := p.claimed[id]
+	if processedAt, ok := p.processed[id]; claimed || (ok && now.Sub(processedAt) < p.window) {
+		p.mu.Unlock()
+		return false, nil
+	}
+	p.claimed[id] = struct{}{}
+	client, expiring := p.client.(expiringClient)
+	p.mu.Unlock()
+	if !expiring {
+		return true, nil
+	}
+	// The item is written outside of the lock, for the objects to be claimed
+	// concurrently.
+	data, err := json.Marshal(now)
+	if err == nil {
+		claimed, err = client.claim(ctx, processedObjectKeyPrefix+id, data, now.Add(min(processedObjectClaimDuration, p.window)), now)
+	}
+	if err != nil || !claimed {
+		p.mu.Lock()
+		delete(p.claimed, id)
+		p.mu.Unlock()
+	}
+	return claimed, err
+}
+
+// done records the processing of a claimed object if processed, persisted with the
+// others on the next flush, or on its own in the state clients persisting an item
+// each, the item expiring at the end of the window. The objects failing to be
+// processed are released to be claimed again, their item deleted.
+func (p *processedObjects) done(ctx context.Context, info objectInfo, processed bool) error {
+	id := objectID(info)
+	p.mu.Lock()
+	delete(p.claimed, id)
+	now := p.now()
+	if processed {
+		p.processed[id] = now
+		p.dirty = true
+	}
+	stateClient := p.client
+	p.mu.Unlock()
+	client, expiring := stateClient.(expiringClient)
+	if !expiring {
+		return nil
+	}
+	if !processed {
+		return stateClient.Delete(ctx, processedObjectKeyPrefix+id)
+	}
+	data, err := json.Marshal(now)
+	if err != nil {
+		return err
+	}
+	return client.setExpiring(ctx, processedObjectKeyPrefix+id, data, now.Add(p.window))
+}
+
+// flush forgets the objects processed before the window, and persists the others
+// under processedObjectsKey if they changed since they were last, unless the state
+// client persists an item each.
+func (p *processedObjects) flush(ctx context.Context) error {
+	p.saveMu.Lock()
+	defer p.saveMu.Unlock()
+	p.mu.Lock()
+	p.evict(p.now())
+	client := p.client
+	if _, expiring := client.(expiringClient); client == nil || expiring || !p.dirty {
+		p.mu.Unlock()
+		return nil
+	}
+	data, err := json.Marshal(p.processed)
+	p.dirty = false
+	p.mu.Unlock()
+	if err == nil {
+		err = client.Set(ctx, processedObjectsKey, data)
+	}
+	if err != nil {
+		p.mu.Lock()
+		p.dirty = true
+		p.mu.Unlock()
+	}
+	return err
+}
+
+// evict forgets the objects processed before the window.
+func (p *processedObjects) evict(now time.Time) {
+	for id, processedAt := range p.processed {
+		if now.Sub(processedAt) >= p.window {
+			delete(p.processed, id)
+		}
+	}
+}
+
+// shutdown stops flushing the processed objects on the interval, and flushes them
+// one last time.
+func (p *processedObjects) shutdown(ctx context.Context) error {
+	if p.cancel != nil {
+		p.cancel()
+		p.wg.Wait()
+		p.cancel = nil
+	}
+	err := p.flush(ctx)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.client == nil {
+		return err
+	}
+	err = multierr.Append(err, p.client.Close(ctx))
+	p.client = nil
+	return err
+}
@@ -0,0 +1,187 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"bytes"
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/storage/storagetest"
+)
+
+func claimObject(t *testing.T, p *processedObjects, info objectInfo) bool {
+	claimed, err := p.claim(context.Background(), info)
+	require.NoError(t, err)
+	return claimed
+}
+
+// processObject claims the object and records it as processed.
+func processObject(t *testing.T, p *processedObjects, info objectInfo) {
+	require.True(t, claimObject(t, p, info))
+	require.NoError(t, p.done(context.Background(), info, true))
+}
+
+func Test_processedObjects(t *testing.T) {
+	p := newProcessedObjects(DeduplicationConfig{Window: time.Hour}, zap.NewNop())
+	now := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	p.now = func() time.Time { return now }
+	require.NoError(t, p.start(context.Background(), nil))
+
+	info := objectInfo{bucket: "bucket", key: "logs/2024/01/01/10/app.log", etag: `"1"`}
+	require.True(t, claimObject(t, p, info))
+	// The object is skipped while being processed.
+	require.False(t, claimObject(t, p, info))
+	require.NoError(t, p.done(context.Background(), info, true))
+	require.False(t, claimObject(t, p, info))
+	// An object overwritten with different contents is processed again.
+	require.True(t, claimObject(t, p, objectInfo{bucket: "bucket", key: "logs/2024/01/01/10/app.log", etag: `"2"`}))
+	other := objectInfo{bucket: "other", key: "logs/2024/01/01/10/app.log", etag: `"1"`}
+	require.True(t, claimObject(t, p, other))
+	// The object failing to be processed is released.
+	require.NoError(t, p.done(context.Background(), other, false))
+	require.True(t, claimObject(t, p, other))
+
+	now = now.Add(time.Hour)
+	require.True(t, claimObject(t, p, info))
+	require.NoError(t, p.done(context.Background(), info, true))
+	// The objects processed before the window are forgotten on the flushes.
+	processObject(t, p, objectInfo{bucket: "bucket", key: "logs/2024/01/01/11/app.log"})
+	require.Len(t, p.processed, 2)
+	now = now.Add(time.Hour)
+	processObject(t, p, objectInfo{bucket: "bucket", key: "logs/2024/01/01/12/app.log"})
+	require.NoError(t, p.flush(context.Background()))
+	require.Len(t, p.processed, 1)
+	require.NoError(t, p.shutdown(context.Background()))
+
+	require.Equal(t, defaultDeduplicationWindow, newProcessedObjects(DeduplicationConfig{}, zap.NewNop()).window)
+}
+
+func Test_processedObjects_Storage(t *testing.T) {
+	storageID := storagetest.NewStorageID("dedup")
+	host := storagetest.NewStorageHost().WithFileBackedStorageExtension("dedup", t.TempDir())
+	id := component.MustNewID("awss3")
+	info := objectInfo{bucket: "bucket", key: "logs/2024/01/01/10/app.log", etag: `"1"`}
+	start := func(telemetryType string) *processedObjects {
+		client, err := newStateClient(context.Background(), host, stateConfig{storage: &storageID}, id, telemetryType)
+		require.NoError(t, err)
+		p := newProcessedObjects(DeduplicationConfig{Storage: &storageID}, zap.NewNop())
+		require.NoError(t, p.start(context.Background(), client))
+		return p
+	}
+
+	p := start("logs")
+	processObject(t, p, info)
+	// The processed objects are persisted on the flushes, rather than on each object.
+	data, err := p.client.Get(context.Background(), processedObjectsKey)
+	require.NoError(t, err)
+	require.Nil(t, data)
+	require.NoError(t, p.flush(context.Background()))
+	data, err = p.client.Get(context.Background(), processedObjectsKey)
+	require.NoError(t, err)
+	require.NotNil(t, data)
+	require.NoError(t, p.shutdown(context.Background()))
+
+	restarted := start("logs")
+	require.False(t, claimObject(t, restarted, info))
+	require.NoError(t, restarted.shutdown(context.Background()))
+
+	// The processed objects are kept apart for each telemetry type.
+	traces := start("traces")
+	require.True(t, claimObject(t, traces, info))
+	require.NoError(t, traces.shutdown(context.Background()))
+}
+
+func Test_processedObjects_StateStore(t *testing.T) {
+	api := newMockDynamoDBAPI()
+	info := objectInfo{bucket: "bucket", key: "logs/2024/01/01/10/app.log", etag: `"1"`}
+	other := objectInfo{bucket: "bucket", key: "logs/2024/01/01/10/web.log", etag: `"1"`}
+	now := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	start := func() *processedObjects {
+		p := newProcessedObjects(DeduplicationConfig{Window: time.Hour}, zap.NewNop())
+		p.now = func() time.Time { return now }
+		require.NoError(t, p.start(context.Background(), newDynamoDBStorageClient(api, "state", "")))
+		t.Cleanup(func() { require.NoError(t, p.shutdown(context.Background())) })
+		return p
+	}
+	ttl := func(info objectInfo) string {
+		return api.items[processedObjectKeyPrefix+objectID(info)][dynamoDBTTLAttribute].(*types.AttributeValueMemberN).Value
+	}
+
+	p := start()
+	standby := start()
+	// The object claimed by a collector sharing the table is skipped by the others
+	// while it is processed, for the claim duration.
+	require.True(t, claimObject(t, p, info))
+	require.Equal(t, strconv.FormatInt(now.Add(processedObjectClaimDuration).Unix(), 10), ttl(info))
+	require.False(t, claimObject(t, standby, info))
+	require.NoError(t, p.done(context.Background(), info, true))
+	require.False(t, claimObject(t, standby, info))
+	processObject(t, standby, other)
+	require.False(t, claimObject(t, p, other))
+
+	// Each object is persisted in its own item, expiring at the end of the window.
+	require.Len(t, api.items, 2)
+	require.Equal(t, strconv.FormatInt(now.Add(time.Hour).Unix(), 10), ttl(info))
+
+	restarted := start()
+	require.False(t, claimObject(t, restarted, info))
+	require.False(t, claimObject(t, restarted, other))
+
+	// The object failing to be processed is released for the others.
+	failed := objectInfo{bucket: "bucket", key: "logs/2024/01/01/10/db.log", etag: `"1"`}
+	require.True(t, claimObject(t, p, failed))
+	require.NoError(t, p.done(context.Background(), failed, false))
+	require.True(t, claimObject(t, standby, failed))
+
+	// The items not yet deleted by DynamoDB are claimed again once expired.
+	now = now.Add(time.Hour + time.Second)
+	require.True(t, claimObject(t, start(), info))
+}
+
+func Test_newStateClient(t *testing.T) {
+	id := component.MustNewID("awss3")
+	client, err := newStateClient(context.Background(), componenttest.NewNopHost(), stateConfig{}, id, "logs")
+	require.NoError(t, err)
+	require.Nil(t, client)
+
+	client, err = newStateClient(context.Background(), componenttest.NewNopHost(), stateConfig{store: &StateStoreConfig{DynamoDB: &DynamoDBConfig{TableName: "state", Region: "us-east-1", KeyPrefix: "fleet/"}}}, id, "logs")
+	require.NoError(t, err)
+	require.Equal(t, "fleet/receiver_awss3_logs_", client.(*dynamoDBStorageClient).prefix)
+
+	unknownID := storagetest.NewStorageID("unknown")
+	_, err = newStateClient(context.Background(), storagetest.NewStorageHost(), stateConfig{storage: &unknownID}, id, "logs")
+	require.ErrorContains(t, err, `storage extension "test_storage/unknown" not found`)
+	nonStorageID := storagetest.NewNonStorageID("non_storage")
+	nonStorageHost := storagetest.NewStorageHost().WithNonStorageExtension("non_storage")
+	_, err = newStateClient(context.Background(), nonStorageHost, stateConfig{storage: &nonStorageID}, id, "logs")
+	require.ErrorContains(t, err, "is not a storage extension")
+}
+
+func Test_receiveObject_Deduplication(t *testing.T) {
+	sink := &consumertest.LogsSink{}
+	r := &awss3Receiver{
+		dataProcessor: newLogsProcessor(sink, LogsConfig{SignalConfig: SignalConfig{Format: FormatText}}, zap.NewNop()),
+		processed:     newProcessedObjects(DeduplicationConfig{}, zap.NewNop()),
+		logger:        zap.NewNop(),
+	}
+	info := objectInfo{bucket: "bucket", key: "logs/2024/01/01/10/app.log", etag: `"1"`}
+	ctx := contextWithObjectInfo(context.Background(), info)
+	require.NoError(t, r.receiveObject(ctx, info.key, bytes.NewReader([]byte("a"))))
+	require.NoError(t, r.receiveObject(ctx, info.key, bytes.NewReader([]byte("a"))))
+	require.Len(t, sink.AllLogs(), 1)
+
+	info.etag = `"2"`
+	require.NoError(t, r.receiveObject(contextWithObjectInfo(context.Background(), info), info.key, bytes.NewReader([]byte("b"))))
+	require.Len(t, sink.AllLogs(), 2)
+}
@@ -0,0 +1,251 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"io"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/golang/snappy"
+	"github.com/klauspost/compress/zstd"
+	"github.com/pierrec/lz4/v4"
+)
+
+// zstdMagic starts the Zstandard compressed data.
+var zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+
+// snappyMagic starts the data in the Snappy framing format, with its stream
+// identifier chunk.
+var snappyMagic = []byte("\xff\x06\x00\x00sNaPpY")
+
+// lz4Magic starts the LZ4 frames.
+var lz4Magic = []byte{0x04, 0x22, 0x4d, 0x18}
+
+// codec decompresses the objects of a compression, named after one of its
+// extensions or starting with its magic bytes.
+type codec struct {
+	compression string
+	extensions  []string
+	magic       []byte
+	// newReader returns the reader of the uncompressed contents read from r.
+	newReader func(r *bufio.Reader) (io.ReadCloser, error)
+}
+
+// codecs are the codecs of the compressions besides none.
+var codecs = []codec{
+	{compression: CompressionGzip, extensions: []string{".gz"}, magic: gzipMagic, newReader: newGzipReader},
+	{compression: CompressionZstd, extensions: []string{".zst"}, magic: zstdMagic, newReader: newZstdReader},
+	{compression: CompressionSnappy, extensions: []string{".snappy", ".sz"}, magic: snappyMagic, newReader: newSnappyReader},
+	{compression: CompressionLZ4, extensions: []string{".lz4"}, magic: lz4Magic, newReader: newLZ4Reader},
+}
+
+// trimExtension returns key without the extension of the codec, if it has one.
+func (c codec) trimExtension(key string) string {
+	for _, extension := range c.extensions {
+		if strings.HasSuffix(key, extension) {
+			return strings.TrimSuffix(key, extension)
+		}
+	}
+	return key
+}
+
+// decode returns the uncompressed contents of data.
+func (c codec) decode(data []byte) ([]byte, error) {
+	reader, err := c.newReader(bufio.NewReader(bytes.NewReader(data)))
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return io.ReadAll(reader)
+}
+
+// decompress returns the uncompressed contents of an object, along with its key
+// without the extension of the compression. The contents are decompressed
+// according to the extension of the key, such as .gz or .zst, or otherwise to
+// their magic bytes, objects not always being named after their compression.
+func decompress(key string, data []byte) (string, []byte, error) {
+	key, reader, err := decompressReader("", key, bytes.NewReader(data))
+	if err != nil {
+		return key, nil, err
+	}
+	defer reader.Close()
+	data, err = io.ReadAll(reader)
+	if err != nil {
+		return key, nil, err
+	}
+	return key, data, nil
+}
+
+// decompressAs returns the contents of an object decompressed with compression,
+// or decompressed as by decompress if compression is empty, along with its key
+// without the extension of the compression.
+func decompressAs(compression, key string, data []byte) (string, []byte, error) {
+	if compression == "" {
+		return decompress(key, data)
+	}
+	key, reader, err := decompressReader(compression, key, bytes.NewReader(data))
+	if err != nil {
+		return key, nil, err
+	}
+	defer reader.Close()
+	data, err = io.ReadAll(reader)
+	return key, data, err
+}
+
+// decompressReader returns the reader of the contents of an object read from r,
+// decompressed as by decompressAs, for them to be read as a stream rather than
+// held in memory as a whole, along with its key without the extension of the
+// compression.
+func decompressReader(compression, key string, r io.Reader) (string, io.ReadCloser, error) {
+	buffered := bufio.NewReader(r)
+	for _, c := range codecs {
+		matches := c.compression == compression
+		if compression == "" {
+			magic, _ := buffered.Peek(len(c.magic))
+			matches = c.trimExtension(key) != key || bytes.Equal(magic, c.magic)
+		}
+		if matches {
+			reader, err := c.newReader(buffered)
+			return c.trimExtension(key), reader, err
+		}
+	}
+	return key, io.NopCloser(buffered), nil
+}
+
+// gzipReaders are the gzip readers reused across objects, each allocating its
+// decompression window.
+var gzipReaders sync.Pool
+
+// newGzipReader returns the reader of gzip compressed data, made of one or
+// several concatenated gzip members, returned to the pool once closed.
+func newGzipReader(r *bufio.Reader) (io.ReadCloser, error) {
+	reader, ok := gzipReaders.Get().(*gzip.Reader)
+	var err error
+	if ok {
+		err = reader.Reset(r)
+	} else {
+		reader, err = gzip.NewReader(r)
+	}
+	if err != nil {
+		if ok {
+			gzipReaders.Put(reader)
+		}
+		return nil, err
+	}
+	reader.Multistream(true)
+	return &pooledGzipReader{Reader: reader}, nil
+}
+
+// pooledGzipReader is a gzip reader of the pool, not to be read once closed.
+type pooledGzipReader struct {
+	*gzip.Reader
+}
+
+func (r *pooledGzipReader) Close() error {
+	if r.Reader == nil {
+		return nil
+	}
+	err := r.Reader.Close()
+	gzipReaders.Put(r.Reader)
+	r.Reader = nil
+	return err
+}
+
+// newZstdReader returns the reader of Zstandard compressed data, made of one or
+// several frames.
+func newZstdReader(r *bufio.Reader) (io.ReadCloser, error) {
+	decoder, err := zstd.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	return decoder.IOReadCloser(), nil
+}
+
+// newSnappyReader returns the reader of Snappy compressed data, either in the
+// framing format or, without its stream identifier, a single block, which can
+// only be decoded as a whole.
+func newSnappyReader(r *bufio.Reader) (io.ReadCloser, error) {
+	if magic, _ := r.Peek(len(snappyMagic)); !bytes.Equal(magic, snappyMagic) {
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return nil, err
+		}
+		block, err := snappy.Decode(nil, data)
+		if err != nil {
+			return nil, err
+		}
+		return io.NopCloser(bytes.NewReader(block)), nil
+	}
+	return io.NopCloser(snappy.NewReader(r)), nil
+}
+
+// newLZ4Reader returns the reader of LZ4 compressed data, made of one or several
+// frames.
+func newLZ4Reader(r *bufio.Reader) (io.ReadCloser, error) {
+	return io.NopCloser(lz4.NewReader(r)), nil
+}
+
+// objectFormat returns the format of the uncompressed contents of an object, the
+// configured format if any, otherwise the one matching the extension of its key
+// and, for the keys without a known extension, the one its contents look like.
+// It returns an empty string if the format is unknown.
+func objectFormat(key string, data []byte, format string) string {
+	if format != "" {
+		return format
+	}
+	switch {
+	case strings.HasSuffix(key, ".json"), strings.HasSuffix(key, ".jsonl"), strings.HasSuffix(key, ".ndjson"):
+		return FormatOTLPJSON
+	case strings.HasSuffix(key, ".binpb"):
+		return FormatOTLPProto
+	case strings.HasSuffix(key, ".parquet"):
+		return FormatOTLPParquet
+	default:
+		return sniffFormat(data)
+	}
+}
+
+// sniffFormat returns the format the contents of an object look like: Parquet
+// files start with their magic bytes, OTLP JSON messages with an object, and OTLP
+// protobuf requests with the size of their first resource.
+func sniffFormat(data []byte) string {
+	switch trimmed := bytes.TrimLeft(data, " \t\r\n"); {
+	case bytes.HasPrefix(data, parquetMagic):
+		return FormatOTLPParquet
+	case bytes.HasPrefix(trimmed, []byte("{")):
+		return FormatOTLPJSON
+	case len(data) > 1 && data[0] == 0x0a:
+		size, n := binary.Uvarint(data[1:])
+		if n > 0 && size <= uint64(len(data)-1-n) {
+			return FormatOTLPProto
+		}
+	}
+	return ""
+}
+
+// formatRule is a FormatRule along with the processor of the objects it matches.
+type formatRule struct {
+	FormatRule
+	processor telemetryProcessor
+}
+
+// matchFormatRule returns the first of rules matching key, or nil if none does.
+func matchFormatRule(rules []formatRule, key string) *formatRule {
+	for i, rule := range rules {
+		name := key
+		if !strings.Contains(rule.Pattern, "/") {
+			name = path.Base(key)
+		}
+		if matched, _ := path.Match(rule.Pattern, name); matched {
+			return &rules[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/golang/snappy"
+	"github.com/klauspost/compress/zstd"
+	"github.com/pierrec/lz4/v4"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+)
+
+func zstdCompress(data []byte) []byte {
+	encoder, _ := zstd.NewWriter(nil)
+	defer encoder.Close()
+	return encoder.EncodeAll(data, nil)
+}
+
+func snappyCompress(data []byte) []byte {
+	var buf bytes.Buffer
+	writer := snappy.NewBufferedWriter(&buf)
+	_, _ = writer.Write(data)
+	_ = writer.Close()
+	return buf.Bytes()
+}
+
+func lz4Compress(data []byte) []byte {
+	var buf bytes.Buffer
+	writer := lz4.NewWriter(&buf)
+	_, _ = writer.Write(data)
+	_ = writer.Close()
+	return buf.Bytes()
+}
+
+func Test_decompress(t *testing.T) {
+	for _, tt := range []struct {
+		key     string
+		data    []byte
+		wantKey string
+	}{
+		{key: "logs_1.json.gz", data: gzipCompress([]byte("test")), wantKey: "logs_1.json"},
+		{key: "logs_1.json.zst", data: zstdCompress([]byte("test")), wantKey: "logs_1.json"},
+		{key: "logs_1", data: gzipCompress([]byte("test")), wantKey: "logs_1"},
+		{key: "logs_1", data: zstdCompress([]byte("test")), wantKey: "logs_1"},
+		{key: "logs_1.json.snappy", data: snappyCompress([]byte("test")), wantKey: "logs_1.json"},
+		{key: "logs_1.json.sz", data: snappy.Encode(nil, []byte("test")), wantKey: "logs_1.json"},
+		{key: "logs_1", data: snappyCompress([]byte("test")), wantKey: "logs_1"},
+		{key: "logs_1.json.lz4", data: lz4Compress([]byte("test")), wantKey: "logs_1.json"},
+		{key: "logs_1", data: lz4Compress([]byte("test")), wantKey: "logs_1"},
+		{key: "logs_1.txt", data: []byte("test"), wantKey: "logs_1.txt"},
+	} {
+		t.Run(tt.key, func(t *testing.T) {
+			key, data, err := decompress(tt.key, tt.data)
+			require.NoError(t, err)
+			require.Equal(t, tt.wantKey, key)
+			require.Equal(t, "test", string(data))
+		})
+	}
+
+	_, _, err := decompress("logs_1.zst", []byte("test"))
+	require.Error(t, err)
+	_, _, err = decompress("logs_1.lz4", []byte("test"))
+	require.Error(t, err)
+}
+
+func Test_objectFormat(t *testing.T) {
+	protobufTraces, err := (&ptrace.ProtoMarshaler{}).MarshalTraces(generateTraceData())
+	require.NoError(t, err)
+
+	require.Equal(t, FormatOTLPJSON, objectFormat("traces_1.json", nil, ""))
+	require.Equal(t, FormatOTLPJSON, objectFormat("traces_1.jsonl", nil, ""))
+	require.Equal(t, FormatOTLPJSON, objectFormat("traces_1.ndjson", nil, ""))
+	require.Equal(t, FormatOTLPProto, objectFormat("traces_1.binpb", nil, ""))
+	require.Equal(t, FormatOTLPParquet, objectFormat("metrics_1.parquet", nil, ""))
+	require.Equal(t, "", objectFormat("traces_1.txt", []byte("test"), ""))
+	require.Equal(t, FormatOTLPProto, objectFormat("traces_1.json", nil, FormatOTLPProto))
+
+	require.Equal(t, FormatOTLPJSON, objectFormat("traces_1", []byte(` {"resourceSpans":[]}`), ""))
+	require.Equal(t, FormatOTLPProto, objectFormat("traces_1", protobufTraces, ""))
+	require.Equal(t, FormatOTLPParquet, objectFormat("metrics_1", []byte("PAR1"), ""))
+	require.Equal(t, "", objectFormat("logs_1", []byte("\n\xff\xff\xff\xff"), ""))
+	require.Equal(t, FormatOTLPJSON, objectFormat("logs_1", []byte(`{}`), FormatOTLPJSON))
+}
+
+func Test_decompressAs(t *testing.T) {
+	key, data, err := decompressAs(CompressionGzip, "logs_1.log", gzipCompress([]byte("test")))
+	require.NoError(t, err)
+	require.Equal(t, "logs_1.log", key)
+	require.Equal(t, "test", string(data))
+
+	key, data, err = decompressAs(CompressionZstd, "logs_1.log.zst", zstdCompress([]byte("test")))
+	require.NoError(t, err)
+	require.Equal(t, "logs_1.log", key)
+	require.Equal(t, "test", string(data))
+
+	key, data, err = decompressAs(CompressionSnappy, "logs_1.log", snappyCompress([]byte("test")))
+	require.NoError(t, err)
+	require.Equal(t, "logs_1.log", key)
+	require.Equal(t, "test", string(data))
+
+	key, data, err = decompressAs(CompressionLZ4, "logs_1.log.lz4", lz4Compress([]byte("test")))
+	require.NoError(t, err)
+	require.Equal(t, "logs_1.log", key)
+	require.Equal(t, "test", string(data))
+
+	// Objects stored with a gzip content encoding may be decompressed on retrieval.
+	key, data, err = decompressAs(CompressionNone, "logs_1.log.gz", []byte("test"))
+	require.NoError(t, err)
+	require.Equal(t, "logs_1.log.gz", key)
+	require.Equal(t, "test", string(data))
+
+	_, _, err = decompressAs(CompressionGzip, "logs_1.log", []byte("test"))
+	require.Error(t, err)
+}
+
+func Test_matchFormatRule(t *testing.T) {
+	rules := []formatRule{
+		{FormatRule: FormatRule{Pattern: "*.jsonl.gz", Format: FormatJSONLines}},
+		{FormatRule: FormatRule{Pattern: "*_traces_*.binpb", Format: FormatOTLPProto}},
+		{FormatRule: FormatRule{Pattern: "app/*/*.log", Format: FormatText}},
+	}
+	require.Equal(t, FormatJSONLines, matchFormatRule(rules, "year=2024/app.jsonl.gz").Format)
+	require.Equal(t, FormatOTLPProto, matchFormatRule(rules, "2024/01/01/svc_traces_1.binpb").Format)
+	require.Equal(t, FormatText, matchFormatRule(rules, "app/2024/api.log").Format)
+	require.Nil(t, matchFormatRule(rules, "other/2024/api.log"))
+	require.Nil(t, matchFormatRule(rules, "app.jsonl"))
+	require.Nil(t, matchFormatRule(nil, "app.jsonl.gz"))
+}
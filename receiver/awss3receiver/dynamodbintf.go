@@ -0,0 +1,35 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+)
+
+type DynamoDBAPI interface {
+	GetItem(ctx context.Context, params *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error)
+	PutItem(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error)
+	DeleteItem(ctx context.Context, params *dynamodb.DeleteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DeleteItemOutput, error)
+}
+
+// newDynamoDBClient returns the client of the table of cfg, with the credentials
+// and transport of the clients of downloaderCfg.
+func newDynamoDBClient(ctx context.Context, downloaderCfg S3DownloaderConfig, cfg DynamoDBConfig) (DynamoDBAPI, error) {
+	if cfg.Region != "" {
+		downloaderCfg.Region = cfg.Region
+	}
+	awsCfg, err := loadAWSConfig(ctx, downloaderCfg)
+	if err != nil {
+		return nil, err
+	}
+	dynamoDBOptionFuncs := make([]func(options *dynamodb.Options), 0)
+	if cfg.Endpoint != "" {
+		dynamoDBOptionFuncs = append(dynamoDBOptionFuncs, func(o *dynamodb.Options) {
+			o.BaseEndpoint = &cfg.Endpoint
+		})
+	}
+	return dynamodb.NewFromConfig(awsCfg, dynamoDBOptionFuncs...), nil
+}
@@ -0,0 +1,199 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"go.opentelemetry.io/collector/extension/experimental/storage"
+)
+
+// errStateConflict is returned when an item of the state store was written by
+// another collector since it was read.
+var errStateConflict = errors.New("the state was modified by another collector")
+
+// The attributes of the items of the state store.
+const (
+	dynamoDBIDAttribute      = "id"
+	dynamoDBValueAttribute   = "value"
+	dynamoDBVersionAttribute = "version"
+	dynamoDBTTLAttribute     = "ttl"
+)
+
+// dynamoDBStorageClient is a storage client persisting each key in an item of a
+// DynamoDB table. Every write increments the version of the item, and is only
+// applied if the item still has the version last read or written by the client,
+// or does not exist if the client has not seen it, so that the writes of several
+// collectors sharing the item fail with errStateConflict rather than overwrite
+// each other.
+type dynamoDBStorageClient struct {
+	client DynamoDBAPI
+	table  string
+	// prefix prefixes the keys in the ids of the items.
+	prefix string
+
+	mu sync.Mutex
+	// versions are the versions of the items last read or written by the client,
+	// zero for the items known not to exist.
+	versions map[string]int64
+}
+
+var _ storage.Client = (*dynamoDBStorageClient)(nil)
+
+func newDynamoDBStorageClient(client DynamoDBAPI, table, prefix string) *dynamoDBStorageClient {
+	return &dynamoDBStorageClient{client: client, table: table, prefix: prefix, versions: map[string]int64{}}
+}
+
+func (c *dynamoDBStorageClient) itemKey(key string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{dynamoDBIDAttribute: &types.AttributeValueMemberS{Value: c.prefix + key}}
+}
+
+// condition returns the condition of the writes of key, on the version of its
+// item last seen by the client.
+func (c *dynamoDBStorageClient) condition(key string) (string, map[string]string, map[string]types.AttributeValue) {
+	version := c.versions[key]
+	if version == 0 {
+		return "attribute_not_exists(#id)", map[string]string{"#id": dynamoDBIDAttribute}, nil
+	}
+	return "#version = :version", map[string]string{"#version": dynamoDBVersionAttribute}, map[string]types.AttributeValue{
+		":version": &types.AttributeValueMemberN{Value: strconv.FormatInt(version, 10)},
+	}
+}
+
+func (c *dynamoDBStorageClient) Get(ctx context.Context, key string) ([]byte, error) {
+	output, err := c.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName:      aws.String(c.table),
+		Key:            c.itemKey(key),
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to get %q from DynamoDB table %q: %w", key, c.table, err)
+	}
+	var value []byte
+	var version int64
+	if attribute, ok := output.Item[dynamoDBValueAttribute].(*types.AttributeValueMemberB); ok {
+		value = attribute.Value
+	}
+	if attribute, ok := output.Item[dynamoDBVersionAttribute].(*types.AttributeValueMemberN); ok {
+		if version, err = strconv.ParseInt(attribute.Value, 10, 64); err != nil {
+			return nil, fmt.Errorf("invalid version of %q in DynamoDB table %q: %w", key, c.table, err)
+		}
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.versions[key] = version
+	return value, nil
+}
+
+func (c *dynamoDBStorageClient) Set(ctx context.Context, key string, value []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	condition, names, values := c.condition(key)
+	version := c.versions[key] + 1
+	item := c.itemKey(key)
+	item[dynamoDBValueAttribute] = &types.AttributeValueMemberB{Value: value}
+	item[dynamoDBVersionAttribute] = &types.AttributeValueMemberN{Value: strconv.FormatInt(version, 10)}
+	_, err := c.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName:                 aws.String(c.table),
+		Item:                      item,
+		ConditionExpression:       aws.String(condition),
+		ExpressionAttributeNames:  names,
+		ExpressionAttributeValues: values,
+	})
+	var conditionalCheckFailed *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionalCheckFailed) {
+		// The version of the item is unknown until it is read again.
+		delete(c.versions, key)
+		return fmt.Errorf("unable to set %q in DynamoDB table %q: %w", key, c.table, errStateConflict)
+	}
+	if err != nil {
+		return fmt.Errorf("unable to set %q in DynamoDB table %q: %w", key, c.table, err)
+	}
+	c.versions[key] = version
+	return nil
+}
+
+// setExpiring writes the item of key whatever its version, with the time it
+// expires at in its ttl attribute, for DynamoDB to delete the item once expired if
+// the time to live of the table is enabled on the attribute.
+func (c *dynamoDBStorageClient) setExpiring(ctx context.Context, key string, value []byte, expiresAt time.Time) error {
+	item := c.itemKey(key)
+	item[dynamoDBValueAttribute] = &types.AttributeValueMemberB{Value: value}
+	item[dynamoDBTTLAttribute] = &types.AttributeValueMemberN{Value: strconv.FormatInt(expiresAt.Unix(), 10)}
+	_, err := c.client.PutItem(ctx, &dynamodb.PutItemInput{TableName: aws.String(c.table), Item: item})
+	if err != nil {
+		return fmt.Errorf("unable to set %q in DynamoDB table %q: %w", key, c.table, err)
+	}
+	return nil
+}
+
+// claim writes the item of key like setExpiring, unless it exists and its ttl
+// attribute is not before now, in which case it reports false.
+func (c *dynamoDBStorageClient) claim(ctx context.Context, key string, value []byte, expiresAt, now time.Time) (bool, error) {
+	item := c.itemKey(key)
+	item[dynamoDBValueAttribute] = &types.AttributeValueMemberB{Value: value}
+	item[dynamoDBTTLAttribute] = &types.AttributeValueMemberN{Value: strconv.FormatInt(expiresAt.Unix(), 10)}
+	_, err := c.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName:                aws.String(c.table),
+		Item:                     item,
+		ConditionExpression:      aws.String("attribute_not_exists(#id) OR #ttl < :now"),
+		ExpressionAttributeNames: map[string]string{"#id": dynamoDBIDAttribute, "#ttl": dynamoDBTTLAttribute},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":now": &types.AttributeValueMemberN{Value: strconv.FormatInt(now.Unix(), 10)},
+		},
+	})
+	var conditionalCheckFailed *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionalCheckFailed) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("unable to claim %q in DynamoDB table %q: %w", key, c.table, err)
+	}
+	return true, nil
+}
+
+// Delete deletes the item of key whatever its version, deleting the state being
+// meant to reset it.
+func (c *dynamoDBStorageClient) Delete(ctx context.Context, key string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, err := c.client.DeleteItem(ctx, &dynamodb.DeleteItemInput{TableName: aws.String(c.table), Key: c.itemKey(key)})
+	if err != nil {
+		return fmt.Errorf("unable to delete %q from DynamoDB table %q: %w", key, c.table, err)
+	}
+	c.versions[key] = 0
+	return nil
+}
+
+func (c *dynamoDBStorageClient) Batch(ctx context.Context, ops ...storage.Operation) error {
+	for _, op := range ops {
+		var err error
+		switch op.Type {
+		case storage.Get:
+			op.Value, err = c.Get(ctx, op.Key)
+		case storage.Set:
+			err = c.Set(ctx, op.Key, op.Value)
+		case storage.Delete:
+			err = c.Delete(ctx, op.Key)
+		default:
+			err = errors.New("wrong operation type")
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *dynamoDBStorageClient) Close(context.Context) error {
+	return nil
+}
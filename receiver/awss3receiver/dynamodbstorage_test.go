@@ -0,0 +1,131 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/extension/experimental/storage"
+)
+
+// mockDynamoDBAPI is a DynamoDB table evaluating the conditions of the writes of
+// the dynamoDBStorageClient and of the lease.
+type mockDynamoDBAPI struct {
+	mu    sync.Mutex
+	items map[string]map[string]types.AttributeValue
+}
+
+func newMockDynamoDBAPI() *mockDynamoDBAPI {
+	return &mockDynamoDBAPI{items: map[string]map[string]types.AttributeValue{}}
+}
+
+func mockItemID(key map[string]types.AttributeValue) string {
+	return key[dynamoDBIDAttribute].(*types.AttributeValueMemberS).Value
+}
+
+func (m *mockDynamoDBAPI) GetItem(_ context.Context, params *dynamodb.GetItemInput, _ ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return &dynamodb.GetItemOutput{Item: m.items[mockItemID(params.Key)]}, nil
+}
+
+func (m *mockDynamoDBAPI) PutItem(_ context.Context, params *dynamodb.PutItemInput, _ ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	id := mockItemID(params.Item)
+	item, exists := m.items[id]
+	switch aws.ToString(params.ConditionExpression) {
+	case "":
+	case "attribute_not_exists(#id)":
+		if exists {
+			return nil, &types.ConditionalCheckFailedException{}
+		}
+	case "#version = :version":
+		expected := params.ExpressionAttributeValues[":version"].(*types.AttributeValueMemberN).Value
+		if !exists || item[dynamoDBVersionAttribute].(*types.AttributeValueMemberN).Value != expected {
+			return nil, &types.ConditionalCheckFailedException{}
+		}
+	case "attribute_not_exists(#id) OR #ttl < :now":
+		if exists {
+			ttl, _ := strconv.ParseInt(item[dynamoDBTTLAttribute].(*types.AttributeValueMemberN).Value, 10, 64)
+			now, _ := strconv.ParseInt(params.ExpressionAttributeValues[":now"].(*types.AttributeValueMemberN).Value, 10, 64)
+			if ttl >= now {
+				return nil, &types.ConditionalCheckFailedException{}
+			}
+		}
+	case "attribute_not_exists(#id) OR #owner = :owner OR #expires_at < :now":
+		if exists && !mockOwnedBy(item, params.ExpressionAttributeValues) {
+			expiresAt, _ := strconv.ParseInt(item[leaseExpiresAtAttribute].(*types.AttributeValueMemberN).Value, 10, 64)
+			now, _ := strconv.ParseInt(params.ExpressionAttributeValues[":now"].(*types.AttributeValueMemberN).Value, 10, 64)
+			if expiresAt >= now {
+				return nil, &types.ConditionalCheckFailedException{}
+			}
+		}
+	default:
+		return nil, errors.New("unexpected condition")
+	}
+	m.items[id] = params.Item
+	return &dynamodb.PutItemOutput{}, nil
+}
+
+// mockOwnedBy reports whether the lease item is owned by the :owner value.
+func mockOwnedBy(item map[string]types.AttributeValue, values map[string]types.AttributeValue) bool {
+	return item[leaseOwnerAttribute].(*types.AttributeValueMemberS).Value == values[":owner"].(*types.AttributeValueMemberS).Value
+}
+
+func (m *mockDynamoDBAPI) DeleteItem(_ context.Context, params *dynamodb.DeleteItemInput, _ ...func(*dynamodb.Options)) (*dynamodb.DeleteItemOutput, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	id := mockItemID(params.Key)
+	if aws.ToString(params.ConditionExpression) == "#owner = :owner" {
+		if item, ok := m.items[id]; !ok || !mockOwnedBy(item, params.ExpressionAttributeValues) {
+			return nil, &types.ConditionalCheckFailedException{}
+		}
+	}
+	delete(m.items, id)
+	return &dynamodb.DeleteItemOutput{}, nil
+}
+
+func Test_dynamoDBStorageClient(t *testing.T) {
+	api := newMockDynamoDBAPI()
+	client := newDynamoDBStorageClient(api, "state", "receiver_awss3_logs_")
+	ctx := context.Background()
+
+	value, err := client.Get(ctx, "key")
+	require.NoError(t, err)
+	require.Nil(t, value)
+	require.NoError(t, client.Set(ctx, "key", []byte("1")))
+	require.NoError(t, client.Set(ctx, "key", []byte("2")))
+	require.Contains(t, api.items, "receiver_awss3_logs_key")
+
+	other := newDynamoDBStorageClient(api, "state", "receiver_awss3_logs_")
+	value, err = other.Get(ctx, "key")
+	require.NoError(t, err)
+	require.Equal(t, []byte("2"), value)
+	require.NoError(t, other.Set(ctx, "key", []byte("3")))
+
+	// The write of a stale version fails rather than overwrite the other one.
+	require.ErrorIs(t, client.Set(ctx, "key", []byte("4")), errStateConflict)
+	require.ErrorIs(t, client.Set(ctx, "key", []byte("4")), errStateConflict)
+	value, err = client.Get(ctx, "key")
+	require.NoError(t, err)
+	require.Equal(t, []byte("3"), value)
+	require.NoError(t, client.Set(ctx, "key", []byte("4")))
+
+	get := storage.GetOperation("key")
+	require.NoError(t, client.Batch(ctx, get, storage.DeleteOperation("key"), storage.SetOperation("key", []byte("5"))))
+	require.Equal(t, []byte("4"), get.Value)
+	value, err = other.Get(ctx, "key")
+	require.NoError(t, err)
+	require.Equal(t, []byte("5"), value)
+	require.NoError(t, client.Close(ctx))
+}
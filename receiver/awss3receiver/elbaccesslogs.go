@@ -0,0 +1,197 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	conventions "go.opentelemetry.io/collector/semconv/v1.25.0"
+	"go.uber.org/zap"
+)
+
+// The fields of the access log entries of each type of load balancer, in order.
+// Application Load Balancers may append fields to their entries over time, so
+// entries with more fields than known are accepted.
+var (
+	albAccessLogFields = []string{
+		"type", "time", "elb", "client:port", "target:port", "request_processing_time", "target_processing_time",
+		"response_processing_time", "elb_status_code", "target_status_code", "received_bytes", "sent_bytes", "request",
+		"user_agent", "ssl_cipher", "ssl_protocol", "target_group_arn", "trace_id", "domain_name", "chosen_cert_arn",
+		"matched_rule_priority", "request_creation_time", "actions_executed", "redirect_url", "error_reason",
+		"target:port_list", "target_status_code_list", "classification", "classification_reason", "conn_trace_id",
+	}
+	nlbAccessLogFields = []string{
+		"type", "version", "time", "elb", "listener", "client:port", "destination:port", "connection_time",
+		"tls_handshake_time", "received_bytes", "sent_bytes", "incoming_tls_alert", "chosen_cert_arn",
+		"chosen_cert_serial", "tls_cipher", "tls_protocol_version", "tls_named_group", "domain_name",
+		"alpn_fe_protocol", "alpn_be_protocol", "alpn_client_preference_list", "tls_connection_creation_time",
+	}
+	classicELBAccessLogFields = []string{
+		"time", "elb", "client:port", "backend:port", "request_processing_time", "backend_processing_time",
+		"response_processing_time", "elb_status_code", "backend_status_code", "received_bytes", "sent_bytes",
+		"request", "user_agent", "ssl_cipher", "ssl_protocol",
+	}
+)
+
+// elbAccessLogsDecoder decodes the access logs of Elastic Load Balancing, of
+// Application, Network, or Classic Load Balancers, into a log record per entry.
+type elbAccessLogsDecoder struct {
+	logger *zap.Logger
+}
+
+func (d *elbAccessLogsDecoder) decodeLogs(info objectInfo, data []byte) (plog.Logs, error) {
+	logs, records := newObjectLogs()
+	for index, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		values := splitLogFields(line)
+		names, timestamp, ok := elbAccessLogLayout(values)
+		if !ok {
+			d.logger.Warn("Skipping invalid load balancer access log entry", zap.String("key", info.key), zap.Int("line", index+1))
+			continue
+		}
+		record := appendObjectRecord(records, info)
+		record.SetTimestamp(pcommon.NewTimestampFromTime(timestamp))
+		record.Body().SetStr(line)
+		for i, value := range values {
+			if i >= len(names) {
+				break
+			}
+			if value == "-" || value == "" {
+				continue
+			}
+			setELBAccessLogField(record.Attributes(), names[i], value)
+		}
+	}
+	return logs, nil
+}
+
+// elbAccessLogLayout returns the names of the fields of an entry, according to the
+// type of load balancer that wrote it, along with the time of the entry. It
+// reports whether the entry has a valid time.
+func elbAccessLogLayout(values []string) ([]string, time.Time, bool) {
+	names, timeIndex := albAccessLogFields, 1
+	switch {
+	case len(values) > 0 && values[0] == "tls":
+		names, timeIndex = nlbAccessLogFields, 2
+	case len(values) > 0 && !isELBType(values[0]):
+		names, timeIndex = classicELBAccessLogFields, 0
+	}
+	if len(values) <= timeIndex {
+		return nil, time.Time{}, false
+	}
+	// The times of the Network Load Balancers entries are UTC without a time zone.
+	timestamp, err := time.Parse(time.RFC3339Nano, values[timeIndex])
+	if err != nil {
+		timestamp, err = time.Parse("2006-01-02T15:04:05", values[timeIndex])
+	}
+	return names, timestamp, err == nil
+}
+
+// isELBType reports whether value is the request type starting the entries of
+// Application Load Balancers.
+func isELBType(value string) bool {
+	switch value {
+	case "http", "https", "h2", "grpcs", "ws", "wss":
+		return true
+	default:
+		return false
+	}
+}
+
+// setELBAccessLogField sets the attribute of a field of an entry. The fields
+// without a semantic convention attribute are set as aws.elb.<field>.
+func setELBAccessLogField(attributes pcommon.Map, name, value string) {
+	switch name {
+	case "time":
+		return
+	case "elb":
+		attributes.PutStr("aws.elb.name", value)
+		return
+	case "client:port":
+		putAddressAttributes(attributes, conventions.AttributeClientAddress, conventions.AttributeClientPort, value)
+		return
+	case "destination:port":
+		putAddressAttributes(attributes, conventions.AttributeDestinationAddress, conventions.AttributeDestinationPort, value)
+		return
+	case "request":
+		if putHTTPRequestAttributes(attributes, value) {
+			return
+		}
+	case "elb_status_code":
+		if code, err := strconv.ParseInt(value, 10, 64); err == nil {
+			attributes.PutInt(conventions.AttributeHTTPResponseStatusCode, code)
+			return
+		}
+	case "user_agent":
+		attributes.PutStr(conventions.AttributeUserAgentOriginal, value)
+		return
+	case "ssl_cipher", "tls_cipher":
+		attributes.PutStr(conventions.AttributeTLSCipher, value)
+		return
+	case "domain_name":
+		attributes.PutStr(conventions.AttributeServerAddress, value)
+		return
+	}
+	name = "aws.elb." + strings.ReplaceAll(name, ":", "_")
+	switch {
+	case strings.HasSuffix(name, "_processing_time"), strings.HasSuffix(name, "_handshake_time"), name == "aws.elb.connection_time":
+		if seconds, err := strconv.ParseFloat(value, 64); err == nil {
+			attributes.PutDouble(name, seconds)
+			return
+		}
+	case strings.HasSuffix(name, "_bytes"), strings.HasSuffix(name, "_status_code"):
+		if integer, err := strconv.ParseInt(value, 10, 64); err == nil {
+			attributes.PutInt(name, integer)
+			return
+		}
+	}
+	attributes.PutStr(name, value)
+}
+
+// putAddressAttributes sets the address and port attributes from an address:port
+// field.
+func putAddressAttributes(attributes pcommon.Map, addressAttribute, portAttribute, value string) {
+	i := strings.LastIndexByte(value, ':')
+	if i < 0 {
+		attributes.PutStr(addressAttribute, value)
+		return
+	}
+	address, port := value[:i], value[i+1:]
+	attributes.PutStr(addressAttribute, address)
+	if number, err := strconv.ParseInt(port, 10, 64); err == nil {
+		attributes.PutInt(portAttribute, number)
+	}
+}
+
+// putHTTPRequestAttributes sets the HTTP attributes of a request line, such as
+// "GET http://example.com:80/ HTTP/1.1", and reports whether it is one.
+func putHTTPRequestAttributes(attributes pcommon.Map, request string) bool {
+	method, rest, ok := strings.Cut(request, " ")
+	if !ok {
+		return false
+	}
+	target, protocol, _ := strings.Cut(rest, " ")
+	attributes.PutStr(conventions.AttributeHTTPRequestMethod, method)
+	if strings.Contains(target, "://") {
+		attributes.PutStr(conventions.AttributeURLFull, target)
+	} else {
+		path, query, _ := strings.Cut(target, "?")
+		attributes.PutStr(conventions.AttributeURLPath, path)
+		if query != "" {
+			attributes.PutStr(conventions.AttributeURLQuery, query)
+		}
+	}
+	if name, version, ok := strings.Cut(protocol, "/"); ok {
+		attributes.PutStr(conventions.AttributeNetworkProtocolName, strings.ToLower(name))
+		attributes.PutStr(conventions.AttributeNetworkProtocolVersion, version)
+	}
+	return true
+}
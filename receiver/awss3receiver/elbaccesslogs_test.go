@@ -0,0 +1,83 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func Test_elbAccessLogsDecoder(t *testing.T) {
+	info := objectInfo{bucket: "bucket", key: "AWSLogs/123456789012/elasticloadbalancing/us-east-1/2021/02/01/access.log"}
+	alb := `https 2021-02-01T17:00:00.123456Z app/my-lb/50dc6c495c0c9188 192.168.131.39:2817 10.0.0.1:80 0.086 0.048 0.037 200 200 0 57 ` +
+		`"GET https://www.example.com:443/ HTTP/1.1" "curl/7.46.0" ECDHE-RSA-AES128-GCM-SHA256 TLSv1.2 ` +
+		`arn:aws:elasticloadbalancing:us-east-2:123456789012:targetgroup/my-targets/73e2d6bc24d8a067 "Root=1-58337281-1d84f3d73c47ec4e58577259" ` +
+		`"www.example.com" "arn:aws:acm:us-east-2:123456789012:certificate/12345678-1234-1234-1234-123456789012" 1 2021-02-01T16:59:59.999Z ` +
+		`"authenticate,forward" "-" "-" "10.0.0.1:80" "200" "-" "-" TID_1234`
+	nlb := `tls 2.0 2021-02-01T17:05:00 net/my-network-lb/c6e77e28c25b2234 g3d4b5e8bb8464cd 72.21.218.154:51341 172.100.100.185:443 5 2 98 246 - ` +
+		`arn:aws:acm:us-east-2:671290407336:certificate/2a108f19-aded-46b0-8493-c63eb1ef4a99 - ECDHE-RSA-AES128-SHA tlsv12 - my-network-lb-c6e77e28c25b2234.elb.us-east-2.amazonaws.com - - - 2021-02-01T17:04:59`
+	classic := `2021-02-01T17:10:00.000000Z my-lb 192.168.131.39:2817 10.0.0.1:80 0.000073 0.001048 0.000057 404 404 0 29 "GET http://www.example.com:80/missing HTTP/1.1" "curl/7.38.0" - -`
+	data := []byte(alb + "\n" + nlb + "\n" + classic + "\ninvalid\n")
+
+	logs, err := (&elbAccessLogsDecoder{logger: zap.NewNop()}).decodeLogs(info, data)
+	require.NoError(t, err)
+	records := logs.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords()
+	require.Equal(t, 3, records.Len())
+	require.Equal(t, alb, records.At(0).Body().Str())
+	require.Equal(t, time.Date(2021, 2, 1, 17, 0, 0, 123456000, time.UTC), records.At(0).Timestamp().AsTime())
+	require.Equal(t, map[string]any{
+		"aws.s3.bucket":                    "bucket",
+		"aws.s3.key":                       info.key,
+		"aws.elb.type":                     "https",
+		"aws.elb.name":                     "app/my-lb/50dc6c495c0c9188",
+		"client.address":                   "192.168.131.39",
+		"client.port":                      int64(2817),
+		"aws.elb.target_port":              "10.0.0.1:80",
+		"aws.elb.request_processing_time":  0.086,
+		"aws.elb.target_processing_time":   0.048,
+		"aws.elb.response_processing_time": 0.037,
+		"http.response.status_code":        int64(200),
+		"aws.elb.target_status_code":       int64(200),
+		"aws.elb.received_bytes":           int64(0),
+		"aws.elb.sent_bytes":               int64(57),
+		"http.request.method":              "GET",
+		"url.full":                         "https://www.example.com:443/",
+		"network.protocol.name":            "http",
+		"network.protocol.version":         "1.1",
+		"user_agent.original":              "curl/7.46.0",
+		"tls.cipher":                       "ECDHE-RSA-AES128-GCM-SHA256",
+		"aws.elb.ssl_protocol":             "TLSv1.2",
+		"aws.elb.target_group_arn":         "arn:aws:elasticloadbalancing:us-east-2:123456789012:targetgroup/my-targets/73e2d6bc24d8a067",
+		"aws.elb.trace_id":                 "Root=1-58337281-1d84f3d73c47ec4e58577259",
+		"server.address":                   "www.example.com",
+		"aws.elb.chosen_cert_arn":          "arn:aws:acm:us-east-2:123456789012:certificate/12345678-1234-1234-1234-123456789012",
+		"aws.elb.matched_rule_priority":    "1",
+		"aws.elb.request_creation_time":    "2021-02-01T16:59:59.999Z",
+		"aws.elb.actions_executed":         "authenticate,forward",
+		"aws.elb.target_port_list":         "10.0.0.1:80",
+		"aws.elb.target_status_code_list":  "200",
+		"aws.elb.conn_trace_id":            "TID_1234",
+	}, records.At(0).Attributes().AsRaw())
+
+	attributes := records.At(1).Attributes().AsRaw()
+	require.Equal(t, time.Date(2021, 2, 1, 17, 5, 0, 0, time.UTC), records.At(1).Timestamp().AsTime())
+	require.Equal(t, "net/my-network-lb/c6e77e28c25b2234", attributes["aws.elb.name"])
+	require.Equal(t, "72.21.218.154", attributes["client.address"])
+	require.Equal(t, "172.100.100.185", attributes["destination.address"])
+	require.Equal(t, int64(443), attributes["destination.port"])
+	require.Equal(t, "ECDHE-RSA-AES128-SHA", attributes["tls.cipher"])
+	require.Equal(t, "my-network-lb-c6e77e28c25b2234.elb.us-east-2.amazonaws.com", attributes["server.address"])
+	require.Equal(t, int64(98), attributes["aws.elb.received_bytes"])
+	require.InDelta(t, 5.0, attributes["aws.elb.connection_time"], 0)
+
+	attributes = records.At(2).Attributes().AsRaw()
+	require.Equal(t, time.Date(2021, 2, 1, 17, 10, 0, 0, time.UTC), records.At(2).Timestamp().AsTime())
+	require.Equal(t, "my-lb", attributes["aws.elb.name"])
+	require.Equal(t, int64(404), attributes["http.response.status_code"])
+	require.Equal(t, int64(404), attributes["aws.elb.backend_status_code"])
+	require.Equal(t, "http://www.example.com:80/missing", attributes["url.full"])
+}
@@ -0,0 +1,232 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	conventions "go.opentelemetry.io/collector/semconv/v1.25.0"
+)
+
+// emfUnits are the UCUM units of the CloudWatch units.
+var emfUnits = map[string]string{
+	"Seconds":          "s",
+	"Microseconds":     "us",
+	"Milliseconds":     "ms",
+	"Bytes":            "By",
+	"Kilobytes":        "kBy",
+	"Megabytes":        "MBy",
+	"Gigabytes":        "GBy",
+	"Terabytes":        "TBy",
+	"Bits":             "bit",
+	"Kilobits":         "kbit",
+	"Megabits":         "Mbit",
+	"Gigabits":         "Gbit",
+	"Terabits":         "Tbit",
+	"Percent":          "%",
+	"Count":            "1",
+	"Bytes/Second":     "By/s",
+	"Kilobytes/Second": "kBy/s",
+	"Megabytes/Second": "MBy/s",
+	"Gigabytes/Second": "GBy/s",
+	"Terabytes/Second": "TBy/s",
+	"Bits/Second":      "bit/s",
+	"Kilobits/Second":  "kbit/s",
+	"Megabits/Second":  "Mbit/s",
+	"Gigabits/Second":  "Gbit/s",
+	"Terabits/Second":  "Tbit/s",
+	"Count/Second":     "1/s",
+	"None":             "",
+}
+
+// emfMetadata is the _aws member of a document in the Embedded Metric Format, see
+// https://docs.aws.amazon.com/AmazonCloudWatch/latest/monitoring/CloudWatch_Embedded_Metric_Format_Specification.html.
+type emfMetadata struct {
+	Timestamp         int64 `json:"Timestamp"`
+	CloudWatchMetrics []struct {
+		Namespace  string     `json:"Namespace"`
+		Dimensions [][]string `json:"Dimensions"`
+		Metrics    []struct {
+			Name string `json:"Name"`
+			Unit string `json:"Unit"`
+		} `json:"Metrics"`
+	} `json:"CloudWatchMetrics"`
+}
+
+// emfResource identifies the namespace, and the log group the documents were sent
+// to through a subscription, of EMF metrics.
+type emfResource struct {
+	namespace string
+	emfLogsSource
+}
+
+type emfLogsSource struct {
+	accountID string
+	logGroup  string
+	logStream string
+}
+
+// emfUnmarshaler unmarshals JSON documents in the Embedded Metric Format,
+// concatenated or newline delimited, or the CloudWatch Logs subscription data
+// whose log messages are such documents, into a gauge data point per value and
+// dimension set of their metrics. The documents without metrics are skipped.
+type emfUnmarshaler struct{}
+
+func (emfUnmarshaler) UnmarshalMetrics(buf []byte) (pmetric.Metrics, error) {
+	metrics := &emfMetrics{
+		metrics:   pmetric.NewMetrics(),
+		resources: map[emfResource]pmetric.MetricSlice{},
+		gauges:    map[emfResource]map[string]pmetric.Metric{},
+	}
+	err := forEachJSONMessage(buf, func(message []byte) error {
+		var document map[string]any
+		if err := json.Unmarshal(message, &document); err != nil {
+			return err
+		}
+		if _, ok := document["logEvents"]; !ok {
+			return metrics.appendDocument(document, message, emfLogsSource{})
+		}
+		var data cloudWatchLogsData
+		if err := json.Unmarshal(message, &data); err != nil {
+			return err
+		}
+		source := emfLogsSource{accountID: data.Owner, logGroup: data.LogGroup, logStream: data.LogStream}
+		for _, event := range data.LogEvents {
+			// The log groups hold other messages than EMF documents.
+			var eventDocument map[string]any
+			if json.Unmarshal([]byte(event.Message), &eventDocument) != nil {
+				continue
+			}
+			if err := metrics.appendDocument(eventDocument, []byte(event.Message), source); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return metrics.metrics, err
+}
+
+// emfMetrics groups the metrics of EMF documents in resources by namespace, and
+// their data points in a gauge per name and unit.
+type emfMetrics struct {
+	metrics   pmetric.Metrics
+	resources map[emfResource]pmetric.MetricSlice
+	gauges    map[emfResource]map[string]pmetric.Metric
+}
+
+func (m *emfMetrics) appendDocument(document map[string]any, message []byte, source emfLogsSource) error {
+	if _, ok := document["_aws"]; !ok {
+		return nil
+	}
+	var fields struct {
+		AWS emfMetadata `json:"_aws"`
+	}
+	if err := json.Unmarshal(message, &fields); err != nil {
+		return fmt.Errorf("invalid _aws metadata: %w", err)
+	}
+	timestamp := pcommon.NewTimestampFromTime(time.UnixMilli(fields.AWS.Timestamp))
+	for _, directive := range fields.AWS.CloudWatchMetrics {
+		resource := emfResource{namespace: directive.Namespace, emfLogsSource: source}
+		dimensionSets := directive.Dimensions
+		if len(dimensionSets) == 0 {
+			dimensionSets = [][]string{nil}
+		}
+		for _, definition := range directive.Metrics {
+			values := emfValues(document[definition.Name])
+			if len(values) == 0 {
+				continue
+			}
+			gauge := m.gauge(resource, definition.Name, definition.Unit)
+			for _, dimensions := range dimensionSets {
+				for _, value := range values {
+					dataPoint := gauge.Gauge().DataPoints().AppendEmpty()
+					dataPoint.SetTimestamp(timestamp)
+					dataPoint.SetDoubleValue(value)
+					for _, dimension := range dimensions {
+						if dimensionValue, ok := document[dimension]; ok {
+							dataPoint.Attributes().PutStr(dimension, emfDimensionValue(dimensionValue))
+						}
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// gauge returns the gauge of the data points of a metric of a resource.
+func (m *emfMetrics) gauge(resource emfResource, name, unit string) pmetric.Metric {
+	metricSlice, ok := m.resources[resource]
+	if !ok {
+		resourceMetrics := m.metrics.ResourceMetrics().AppendEmpty()
+		setEMFResource(resourceMetrics.Resource(), resource)
+		metricSlice = resourceMetrics.ScopeMetrics().AppendEmpty().Metrics()
+		m.resources[resource] = metricSlice
+		m.gauges[resource] = map[string]pmetric.Metric{}
+	}
+	gauge, ok := m.gauges[resource][name+"\x00"+unit]
+	if !ok {
+		gauge = metricSlice.AppendEmpty()
+		gauge.SetName(name)
+		if ucum, known := emfUnits[unit]; known {
+			gauge.SetUnit(ucum)
+		} else {
+			gauge.SetUnit(unit)
+		}
+		gauge.SetEmptyGauge()
+		m.gauges[resource][name+"\x00"+unit] = gauge
+	}
+	return gauge
+}
+
+// setEMFResource sets the attributes of the resource of the metrics of a namespace,
+// set as the service.name attribute, and of the log group they were sent to.
+func setEMFResource(resource pcommon.Resource, r emfResource) {
+	attributes := resource.Attributes()
+	attributes.PutStr(conventions.AttributeCloudProvider, conventions.AttributeCloudProviderAWS)
+	if r.namespace != "" {
+		attributes.PutStr(conventions.AttributeServiceName, r.namespace)
+	}
+	if r.accountID != "" {
+		attributes.PutStr(conventions.AttributeCloudAccountID, r.accountID)
+	}
+	if r.logGroup != "" {
+		attributes.PutEmptySlice(conventions.AttributeAWSLogGroupNames).AppendEmpty().SetStr(r.logGroup)
+	}
+	if r.logStream != "" {
+		attributes.PutEmptySlice(conventions.AttributeAWSLogStreamNames).AppendEmpty().SetStr(r.logStream)
+	}
+}
+
+// emfValues returns the values of a metric, a number or an array of numbers.
+func emfValues(value any) []float64 {
+	switch v := value.(type) {
+	case float64:
+		return []float64{v}
+	case []any:
+		values := make([]float64, 0, len(v))
+		for _, item := range v {
+			if number, ok := item.(float64); ok {
+				values = append(values, number)
+			}
+		}
+		return values
+	default:
+		return nil
+	}
+}
+
+// emfDimensionValue returns the value of a dimension, a string unless the member
+// of the document holds another JSON value.
+func emfDimensionValue(value any) string {
+	if s, ok := value.(string); ok {
+		return s
+	}
+	encoded, _ := json.Marshal(value)
+	return string(encoded)
+}
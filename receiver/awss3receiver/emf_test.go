@@ -0,0 +1,72 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+const emfDocument = `{"_aws":{"Timestamp":1704070800000,"CloudWatchMetrics":[{"Namespace":"checkout",` +
+	`"Dimensions":[["Service"],["Service","Operation"]],"Metrics":[{"Name":"Latency","Unit":"Milliseconds"},` +
+	`{"Name":"Missing","Unit":"Count"}]}]},"Service":"cart","Operation":"pay","Latency":[12.5,20],"RequestId":"abc"}`
+
+func Test_emfUnmarshaler(t *testing.T) {
+	data := []byte(emfDocument + "\n" + `{"message":"not EMF"}` + "\n" +
+		`{"_aws":{"Timestamp":1704070860000,"CloudWatchMetrics":[{"Namespace":"checkout","Metrics":[{"Name":"Latency","Unit":"Milliseconds"}]}]},"Latency":8}`)
+	metrics, err := emfUnmarshaler{}.UnmarshalMetrics(data)
+	require.NoError(t, err)
+	require.Equal(t, 1, metrics.ResourceMetrics().Len())
+	resourceMetrics := metrics.ResourceMetrics().At(0)
+	require.Equal(t, map[string]any{"cloud.provider": "aws", "service.name": "checkout"}, resourceMetrics.Resource().Attributes().AsRaw())
+	metricSlice := resourceMetrics.ScopeMetrics().At(0).Metrics()
+	require.Equal(t, 1, metricSlice.Len())
+	latency := metricSlice.At(0)
+	require.Equal(t, "Latency", latency.Name())
+	require.Equal(t, "ms", latency.Unit())
+
+	dataPoints := latency.Gauge().DataPoints()
+	require.Equal(t, 5, dataPoints.Len())
+	require.Equal(t, pcommon.NewTimestampFromTime(time.UnixMilli(1704070800000)), dataPoints.At(0).Timestamp())
+	require.Equal(t, 12.5, dataPoints.At(0).DoubleValue())
+	require.Equal(t, map[string]any{"Service": "cart"}, dataPoints.At(0).Attributes().AsRaw())
+	require.Equal(t, 20.0, dataPoints.At(1).DoubleValue())
+	require.Equal(t, map[string]any{"Service": "cart", "Operation": "pay"}, dataPoints.At(3).Attributes().AsRaw())
+	require.Equal(t, 8.0, dataPoints.At(4).DoubleValue())
+	require.Equal(t, 0, dataPoints.At(4).Attributes().Len())
+
+	_, err = emfUnmarshaler{}.UnmarshalMetrics([]byte(`{"_aws":{"Timestamp":"now"}}`))
+	require.ErrorContains(t, err, "invalid _aws metadata: ")
+}
+
+func Test_emfUnmarshaler_LogsSubscription(t *testing.T) {
+	data, err := json.Marshal(map[string]any{
+		"messageType": "DATA_MESSAGE",
+		"owner":       "123456789012",
+		"logGroup":    "/aws/lambda/checkout",
+		"logStream":   "2024/01/01/[$LATEST]abc",
+		"logEvents": []map[string]any{
+			{"id": "1", "timestamp": 1704070800000, "message": "START RequestId: abc Version: $LATEST"},
+			{"id": "2", "timestamp": 1704070800000, "message": emfDocument},
+		},
+	})
+	require.NoError(t, err)
+	metrics, err := emfUnmarshaler{}.UnmarshalMetrics(data)
+	require.NoError(t, err)
+	require.Equal(t, 1, metrics.ResourceMetrics().Len())
+	require.Equal(t, map[string]any{
+		"cloud.provider":       "aws",
+		"cloud.account.id":     "123456789012",
+		"service.name":         "checkout",
+		"aws.log.group.names":  []any{"/aws/lambda/checkout"},
+		"aws.log.stream.names": []any{"2024/01/01/[$LATEST]abc"},
+	}, metrics.ResourceMetrics().At(0).Resource().Attributes().AsRaw())
+	require.Equal(t, 4, metrics.DataPointCount())
+	require.Equal(t, pmetric.MetricTypeGauge, metrics.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(0).Type())
+}
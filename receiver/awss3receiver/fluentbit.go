@@ -0,0 +1,222 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"bytes"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	conventions "go.opentelemetry.io/collector/semconv/v1.25.0"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/stanza/adapter"
+)
+
+// fluentBitTimeLayouts are the layouts of the string times of Fluent Bit
+// records, the ones of the iso8601 and java_sql_timestamp date formats of the S3
+// output.
+var fluentBitTimeLayouts = []string{time.RFC3339Nano, "2006-01-02 15:04:05.999999999"}
+
+// fluentBitECSFields are the fields FireLens adds to the records of the
+// containers of ECS tasks.
+var fluentBitECSFields = []string{"ecs_cluster", "ecs_task_arn", "ecs_task_definition", "container_id", "container_name"}
+
+// fluentBitDecoder decodes the objects written by the S3 output of Fluent Bit,
+// holding a JSON record per line, into a log record per record. The log field is
+// the body, the date or time field the timestamp and the other fields the
+// attributes, but for the Kubernetes metadata of the kubernetes filter and the
+// ECS metadata of FireLens, which set the attributes of the resources the
+// records are grouped in.
+type fluentBitDecoder struct {
+	logger *zap.Logger
+}
+
+func (d *fluentBitDecoder) decodeLogs(info objectInfo, data []byte) (plog.Logs, error) {
+	logs := plog.NewLogs()
+	resources := map[uint64]plog.LogRecordSlice{}
+	records := func(resource map[string]any) plog.LogRecordSlice {
+		id := adapter.HashResource(resource)
+		if records, ok := resources[id]; ok {
+			return records
+		}
+		resourceLogs := logs.ResourceLogs().AppendEmpty()
+		_ = resourceLogs.Resource().Attributes().FromRaw(resource)
+		resources[id] = resourceLogs.ScopeLogs().AppendEmpty().LogRecords()
+		return resources[id]
+	}
+	for index, line := range bytes.Split(data, []byte("\n")) {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		err := forEachJSONMessage(line, func(message []byte) error {
+			document, err := parseJSONDocument(message)
+			if err != nil {
+				return err
+			}
+			var fields []any
+			switch document := document.(type) {
+			case map[string]any:
+				fields = []any{document}
+			case []any:
+				fields = document
+			}
+			for _, field := range fields {
+				object, ok := field.(map[string]any)
+				if !ok {
+					return errors.New("not a JSON object")
+				}
+				record := appendObjectRecord(records(fluentBitResource(object)), info)
+				if err := setFluentBitRecord(record, object); err != nil {
+					d.logger.Warn("Unable to parse the time of a Fluent Bit record", zap.String("key", info.key), zap.Int("line", index+1), zap.Error(err))
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			d.logger.Warn("Skipping invalid Fluent Bit record", zap.String("key", info.key), zap.Int("line", index+1), zap.Error(err))
+		}
+	}
+	return logs, nil
+}
+
+// fluentBitResource returns the resource attributes of the Kubernetes and ECS
+// metadata of a record.
+func fluentBitResource(fields map[string]any) map[string]any {
+	resource := map[string]any{}
+	if metadata, ok := fields["kubernetes"].(map[string]any); ok {
+		for name, attribute := range map[string]string{
+			"pod_name":       conventions.AttributeK8SPodName,
+			"pod_id":         conventions.AttributeK8SPodUID,
+			"namespace_name": conventions.AttributeK8SNamespaceName,
+			"container_name": conventions.AttributeK8SContainerName,
+			"host":           conventions.AttributeK8SNodeName,
+			"docker_id":      conventions.AttributeContainerID,
+		} {
+			if value := stringField(metadata, name); value != "" {
+				resource[attribute] = value
+			}
+		}
+		if image := stringField(metadata, "container_image"); image != "" {
+			name, tag := splitContainerImage(image)
+			resource[conventions.AttributeContainerImageName] = name
+			if tag != "" {
+				resource[conventions.AttributeContainerImageTags] = []any{tag}
+			}
+		}
+		for _, group := range []string{"labels", "annotations"} {
+			values, _ := metadata[group].(map[string]any)
+			for name, value := range values {
+				resource["k8s.pod."+group+"."+name] = value
+			}
+		}
+	}
+	if taskARN := stringField(fields, "ecs_task_arn"); taskARN != "" {
+		resource[conventions.AttributeCloudProvider] = conventions.AttributeCloudProviderAWS
+		resource[conventions.AttributeCloudPlatform] = conventions.AttributeCloudPlatformAWSECS
+		resource[conventions.AttributeAWSECSTaskARN] = taskARN
+		if parsed, err := arn.Parse(taskARN); err == nil {
+			resource[conventions.AttributeCloudAccountID] = parsed.AccountID
+			resource[conventions.AttributeCloudRegion] = parsed.Region
+			if cluster := stringField(fields, "ecs_cluster"); cluster != "" {
+				parsed.Resource = "cluster/" + cluster
+				resource[conventions.AttributeAWSECSClusterARN] = parsed.String()
+			}
+		}
+	}
+	if definition := stringField(fields, "ecs_task_definition"); definition != "" {
+		family, revision, _ := strings.Cut(definition, ":")
+		resource[conventions.AttributeAWSECSTaskFamily] = family
+		if revision != "" {
+			resource[conventions.AttributeAWSECSTaskRevision] = revision
+		}
+	}
+	if id := stringField(fields, "container_id"); id != "" {
+		resource[conventions.AttributeContainerID] = id
+	}
+	if name := stringField(fields, "container_name"); name != "" {
+		resource[conventions.AttributeContainerName] = name
+	}
+	return resource
+}
+
+// splitContainerImage splits the reference of a container image into its name
+// and tag, if any.
+func splitContainerImage(image string) (string, string) {
+	if name, _, ok := strings.Cut(image, "@"); ok {
+		return name, ""
+	}
+	if i := strings.LastIndexByte(image, ':'); i > strings.LastIndexByte(image, '/') {
+		return image[:i], image[i+1:]
+	}
+	return image, ""
+}
+
+// setFluentBitRecord sets the body, attributes and timestamp of a record from
+// the fields of a Fluent Bit record. The body is the map of the fields but the
+// metadata if the record has no log field.
+func setFluentBitRecord(record plog.LogRecord, fields map[string]any) error {
+	body := map[string]any{}
+	for name, value := range fields {
+		switch name {
+		case "kubernetes", "date", "time":
+		case "log":
+			if message, ok := value.(string); ok {
+				value = strings.TrimRight(message, "\r\n")
+			}
+			_ = record.Body().FromRaw(value)
+		case "stream", "source":
+			if stream, ok := value.(string); ok && (stream == "stdout" || stream == "stderr") {
+				record.Attributes().PutStr(conventions.AttributeLogIostream, stream)
+				continue
+			}
+			body[name] = value
+		default:
+			if !slices.Contains(fluentBitECSFields, name) {
+				body[name] = value
+			}
+		}
+	}
+	if _, ok := fields["log"]; ok {
+		for name, value := range body {
+			_ = record.Attributes().PutEmpty(name).FromRaw(value)
+		}
+	} else {
+		_ = record.Body().FromRaw(body)
+	}
+	value, ok := fields["date"]
+	if !ok {
+		if value, ok = fields["time"]; !ok {
+			return nil
+		}
+	}
+	timestamp, err := parseFluentBitTime(value)
+	if err != nil {
+		return err
+	}
+	record.SetTimestamp(pcommon.NewTimestampFromTime(timestamp))
+	return nil
+}
+
+// parseFluentBitTime parses the time of a record, a number of seconds since the
+// epoch or a string in one of fluentBitTimeLayouts.
+func parseFluentBitTime(value any) (time.Time, error) {
+	text, ok := value.(string)
+	if !ok {
+		return timestampParser{}.parse(value)
+	}
+	var err error
+	for _, layout := range fluentBitTimeLayouts {
+		var timestamp time.Time
+		if timestamp, err = time.Parse(layout, text); err == nil {
+			return timestamp, nil
+		}
+	}
+	return time.Time{}, err
+}
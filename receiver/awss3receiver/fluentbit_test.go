@@ -0,0 +1,82 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func Test_fluentBitDecoder(t *testing.T) {
+	info := objectInfo{bucket: "bucket", key: "fluent-bit-logs/2024/01/01/10/00/00-objectkey"}
+	data := []byte(`{"date":"2024-01-01T10:00:00.123456Z","log":"GET /healthz 200\n","stream":"stdout","kubernetes":{"pod_name":"web-0","namespace_name":"default","pod_id":"0f4cb4b2","labels":{"app":"web"},"host":"node-1","container_name":"nginx","docker_id":"abc123","container_image":"docker.io/library/nginx:1.25"}}
+not json
+{"date":1704103201.5,"log":"GET / 500","stream":"stderr","kubernetes":{"pod_name":"web-0","namespace_name":"default","pod_id":"0f4cb4b2","labels":{"app":"web"},"host":"node-1","container_name":"nginx","docker_id":"abc123","container_image":"docker.io/library/nginx:1.25"}}
+{"time":"2024-01-01 10:00:02.000000","log":"started","source":"stderr","container_id":"def456","container_name":"app","ecs_cluster":"prod","ecs_task_arn":"arn:aws:ecs:us-east-1:123456789012:task/prod/8f2b","ecs_task_definition":"app:7"}
+{"date":"2024-01-01T10:00:03Z","level":"info","msg":"no log field"}
+`)
+
+	logs, err := (&fluentBitDecoder{logger: zap.NewNop()}).decodeLogs(info, data)
+	require.NoError(t, err)
+	require.Equal(t, 3, logs.ResourceLogs().Len())
+
+	require.Equal(t, map[string]any{
+		"k8s.pod.name":         "web-0",
+		"k8s.namespace.name":   "default",
+		"k8s.pod.uid":          "0f4cb4b2",
+		"k8s.pod.labels.app":   "web",
+		"k8s.node.name":        "node-1",
+		"k8s.container.name":   "nginx",
+		"container.id":         "abc123",
+		"container.image.name": "docker.io/library/nginx",
+		"container.image.tags": []any{"1.25"},
+	}, logs.ResourceLogs().At(0).Resource().Attributes().AsRaw())
+	records := logs.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords()
+	require.Equal(t, 2, records.Len())
+	record := records.At(0)
+	require.Equal(t, "GET /healthz 200", record.Body().Str())
+	require.Equal(t, time.Date(2024, 1, 1, 10, 0, 0, 123456000, time.UTC), record.Timestamp().AsTime())
+	require.Equal(t, map[string]any{"aws.s3.bucket": "bucket", "aws.s3.key": info.key, "log.iostream": "stdout"}, record.Attributes().AsRaw())
+	record = records.At(1)
+	require.Equal(t, time.Date(2024, 1, 1, 10, 0, 1, 500000000, time.UTC), record.Timestamp().AsTime())
+	require.Equal(t, "stderr", record.Attributes().AsRaw()["log.iostream"])
+
+	require.Equal(t, map[string]any{
+		"cloud.provider":        "aws",
+		"cloud.platform":        "aws_ecs",
+		"cloud.account.id":      "123456789012",
+		"cloud.region":          "us-east-1",
+		"aws.ecs.task.arn":      "arn:aws:ecs:us-east-1:123456789012:task/prod/8f2b",
+		"aws.ecs.cluster.arn":   "arn:aws:ecs:us-east-1:123456789012:cluster/prod",
+		"aws.ecs.task.family":   "app",
+		"aws.ecs.task.revision": "7",
+		"container.id":          "def456",
+		"container.name":        "app",
+	}, logs.ResourceLogs().At(1).Resource().Attributes().AsRaw())
+	record = logs.ResourceLogs().At(1).ScopeLogs().At(0).LogRecords().At(0)
+	require.Equal(t, "started", record.Body().Str())
+	require.Equal(t, time.Date(2024, 1, 1, 10, 0, 2, 0, time.UTC), record.Timestamp().AsTime())
+	require.Equal(t, map[string]any{"aws.s3.bucket": "bucket", "aws.s3.key": info.key, "log.iostream": "stderr"}, record.Attributes().AsRaw())
+
+	require.Equal(t, 0, logs.ResourceLogs().At(2).Resource().Attributes().Len())
+	record = logs.ResourceLogs().At(2).ScopeLogs().At(0).LogRecords().At(0)
+	require.Equal(t, map[string]any{"level": "info", "msg": "no log field"}, record.Body().Map().AsRaw())
+	require.Equal(t, time.Date(2024, 1, 1, 10, 0, 3, 0, time.UTC), record.Timestamp().AsTime())
+}
+
+func Test_splitContainerImage(t *testing.T) {
+	for image, expected := range map[string][2]string{
+		"nginx":                            {"nginx", ""},
+		"nginx:1.25":                       {"nginx", "1.25"},
+		"registry:5000/team/app":           {"registry:5000/team/app", ""},
+		"registry:5000/team/app:v2":        {"registry:5000/team/app", "v2"},
+		"public.ecr.aws/app@sha256:abcdef": {"public.ecr.aws/app", ""},
+	} {
+		name, tag := splitContainerImage(image)
+		require.Equal(t, expected, [2]string{name, tag}, image)
+	}
+}
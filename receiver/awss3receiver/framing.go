@@ -0,0 +1,70 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// gzipMagic starts the gzip compressed data.
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// gunzip decompresses gzip compressed data, made of one or several concatenated
+// gzip members, such as the objects Firehose and log shippers append compressed
+// batches to. The members are read until the end of the data, so that none of
+// them is dropped.
+func gunzip(data []byte) ([]byte, error) {
+	reader, err := newGzipReader(bufio.NewReader(bytes.NewReader(data)))
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return io.ReadAll(reader)
+}
+
+// splitRecords splits the contents of an object holding several records, such as
+// the objects delivered by Firehose, into its records according to framing.
+// Firehose compresses the whole delivery, so the contents are decompressed first
+// if they are gzip compressed, whatever the extension of the object.
+func splitRecords(framing string, data []byte) ([][]byte, error) {
+	if bytes.HasPrefix(data, gzipMagic) {
+		var err error
+		if data, err = gunzip(data); err != nil {
+			return nil, err
+		}
+	}
+	var records [][]byte
+	switch framing {
+	case FramingNewline:
+		for _, line := range bytes.Split(data, []byte("\n")) {
+			if line = bytes.TrimSuffix(line, []byte("\r")); len(line) > 0 {
+				records = append(records, line)
+			}
+		}
+	case FramingJSON:
+		err := forEachJSONMessage(data, func(message []byte) error {
+			records = append(records, message)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	case FramingSizeDelimited:
+		for index := 0; len(data) > 0; index++ {
+			size, n := binary.Uvarint(data)
+			if n <= 0 || uint64(len(data)-n) < size {
+				return nil, fmt.Errorf("unable to read record %d: invalid size", index)
+			}
+			records = append(records, data[n:n+int(size)])
+			data = data[n+int(size):]
+		}
+	default:
+		records = [][]byte{data}
+	}
+	return records, nil
+}
@@ -0,0 +1,52 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_gunzip(t *testing.T) {
+	// The fixture concatenates two gzip members, holding the first two lines and
+	// the third one.
+	data, err := os.ReadFile(filepath.Join("testdata", "concatenated.jsonl.gz"))
+	require.NoError(t, err)
+	uncompressed, err := gunzip(data)
+	require.NoError(t, err)
+	require.Equal(t, "{\"message\":\"first\"}\n{\"message\":\"second\"}\n{\"message\":\"third\"}\n", string(uncompressed))
+
+	records, err := splitRecords(FramingNewline, data)
+	require.NoError(t, err)
+	require.Len(t, records, 3)
+
+	_, err = gunzip(append(data, 0x1f, 0x8b, 0x08))
+	require.Error(t, err)
+}
+
+func Test_splitRecords(t *testing.T) {
+	records, err := splitRecords(FramingNewline, []byte("{\"a\":1}\r\n\n{\"a\":2}"))
+	require.NoError(t, err)
+	require.Equal(t, [][]byte{[]byte(`{"a":1}`), []byte(`{"a":2}`)}, records)
+
+	records, err = splitRecords(FramingJSON, gzipCompress([]byte(`{"a":1}{"a":2} {"a":3}`)))
+	require.NoError(t, err)
+	require.Equal(t, [][]byte{[]byte(`{"a":1}`), []byte(`{"a":2}`), []byte(`{"a":3}`)}, records)
+
+	_, err = splitRecords(FramingJSON, []byte(`{"a":1}{"a"`))
+	require.ErrorContains(t, err, "unable to read JSON message 1")
+
+	records, err = splitRecords(FramingSizeDelimited, []byte{3, 'a', 'b', 'c', 0, 1, 'd'})
+	require.NoError(t, err)
+	require.Equal(t, [][]byte{[]byte("abc"), {}, []byte("d")}, records)
+
+	_, err = splitRecords(FramingSizeDelimited, []byte{3, 'a', 'b', 'c', 2, 'd'})
+	require.EqualError(t, err, "unable to read record 1: invalid size")
+
+	_, err = splitRecords(FramingNewline, []byte{0x1f, 0x8b, 0x00})
+	require.Error(t, err)
+}
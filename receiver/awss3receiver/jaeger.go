@@ -0,0 +1,71 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+
+	"github.com/gogo/protobuf/jsonpb"
+	jaegerproto "github.com/jaegertracing/jaeger/model"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/translator/jaeger"
+)
+
+// jaegerProtoTracesUnmarshaler unmarshals Jaeger protobuf spans, each preceded by
+// its size as a varint.
+type jaegerProtoTracesUnmarshaler struct{}
+
+func (jaegerProtoTracesUnmarshaler) UnmarshalTraces(buf []byte) (ptrace.Traces, error) {
+	var batch jaegerproto.Batch
+	for index := 0; len(buf) > 0; index++ {
+		size, n := binary.Uvarint(buf)
+		if n <= 0 || uint64(len(buf)-n) < size {
+			return ptrace.NewTraces(), fmt.Errorf("unable to read Jaeger span %d: invalid size", index)
+		}
+		span := &jaegerproto.Span{}
+		if err := span.Unmarshal(buf[n : n+int(size)]); err != nil {
+			return ptrace.NewTraces(), fmt.Errorf("unable to unmarshal Jaeger span %d: %w", index, err)
+		}
+		batch.Spans = append(batch.Spans, span)
+		buf = buf[n+int(size):]
+	}
+	return jaeger.ProtoToTraces([]*jaegerproto.Batch{&batch})
+}
+
+// jaegerJSONTracesUnmarshaler unmarshals one or several newline delimited Jaeger
+// JSON messages, in the JSON mapping of the protobuf model, each holding either a
+// span or a batch of spans, such as the responses of the Jaeger query service.
+type jaegerJSONTracesUnmarshaler struct{}
+
+func (jaegerJSONTracesUnmarshaler) UnmarshalTraces(buf []byte) (ptrace.Traces, error) {
+	var batches []*jaegerproto.Batch
+	err := forEachJSONMessage(buf, func(message []byte) error {
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(message, &fields); err != nil {
+			return err
+		}
+		if _, ok := fields["spans"]; ok {
+			batch := &jaegerproto.Batch{}
+			if err := jsonpb.Unmarshal(bytes.NewReader(message), batch); err != nil {
+				return err
+			}
+			batches = append(batches, batch)
+			return nil
+		}
+		span := &jaegerproto.Span{}
+		if err := jsonpb.Unmarshal(bytes.NewReader(message), span); err != nil {
+			return err
+		}
+		batches = append(batches, &jaegerproto.Batch{Spans: []*jaegerproto.Span{span}})
+		return nil
+	})
+	if err != nil {
+		return ptrace.NewTraces(), err
+	}
+	return jaeger.ProtoToTraces(batches)
+}
@@ -0,0 +1,73 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"encoding/binary"
+	"testing"
+	"time"
+
+	jaegerproto "github.com/jaegertracing/jaeger/model"
+	"github.com/stretchr/testify/require"
+)
+
+func testJaegerSpan(operation string) *jaegerproto.Span {
+	return &jaegerproto.Span{
+		TraceID:       jaegerproto.NewTraceID(1, 2),
+		SpanID:        jaegerproto.NewSpanID(3),
+		OperationName: operation,
+		StartTime:     time.Date(2021, 2, 1, 17, 0, 0, 0, time.UTC),
+		Duration:      time.Second,
+		Process:       jaegerproto.NewProcess("checkout", nil),
+	}
+}
+
+func Test_jaegerProtoTracesUnmarshaler(t *testing.T) {
+	var buf []byte
+	for _, operation := range []string{"GET /cart", "POST /checkout"} {
+		data, err := testJaegerSpan(operation).Marshal()
+		require.NoError(t, err)
+		buf = binary.AppendUvarint(buf, uint64(len(data)))
+		buf = append(buf, data...)
+	}
+
+	traces, err := jaegerProtoTracesUnmarshaler{}.UnmarshalTraces(buf)
+	require.NoError(t, err)
+	require.Equal(t, 1, traces.ResourceSpans().Len())
+	resourceSpans := traces.ResourceSpans().At(0)
+	require.Equal(t, "checkout", resourceSpans.Resource().Attributes().AsRaw()["service.name"])
+	spans := resourceSpans.ScopeSpans().At(0).Spans()
+	require.Equal(t, 2, spans.Len())
+	require.Equal(t, "GET /cart", spans.At(0).Name())
+	require.Equal(t, time.Date(2021, 2, 1, 17, 0, 1, 0, time.UTC), spans.At(0).EndTimestamp().AsTime())
+	require.Equal(t, "POST /checkout", spans.At(1).Name())
+
+	_, err = jaegerProtoTracesUnmarshaler{}.UnmarshalTraces(buf[:len(buf)-1])
+	require.EqualError(t, err, "unable to read Jaeger span 1: invalid size")
+}
+
+func Test_jaegerJSONTracesUnmarshaler(t *testing.T) {
+	data := []byte(`{"traceId": "AAAAAAAAAAEAAAAAAAAAAg==", "spanId": "AAAAAAAAAAM=", "operationName": "GET /cart", "startTime": "2021-02-01T17:00:00Z", "duration": "1s", "process": {"serviceName": "checkout"}}
+{"spans": [{"traceId": "AAAAAAAAAAEAAAAAAAAAAg==", "spanId": "AAAAAAAAAAQ=", "operationName": "SELECT", "startTime": "2021-02-01T17:00:00.2Z", "duration": "0.5s"}], "process": {"serviceName": "orders"}}
+`)
+	traces, err := jaegerJSONTracesUnmarshaler{}.UnmarshalTraces(data)
+	require.NoError(t, err)
+	require.Equal(t, 2, traces.ResourceSpans().Len())
+	// The spans of the messages without a batch are grouped by process in no
+	// particular order.
+	checkout, orders := traces.ResourceSpans().At(0), traces.ResourceSpans().At(1)
+	if checkout.Resource().Attributes().AsRaw()["service.name"] != "checkout" {
+		checkout, orders = orders, checkout
+	}
+	require.Equal(t, "checkout", checkout.Resource().Attributes().AsRaw()["service.name"])
+	span := checkout.ScopeSpans().At(0).Spans().At(0)
+	require.Equal(t, "GET /cart", span.Name())
+	require.Equal(t, [16]byte{7: 1, 15: 2}, [16]byte(span.TraceID()))
+	require.Equal(t, time.Date(2021, 2, 1, 17, 0, 0, 0, time.UTC), span.StartTimestamp().AsTime())
+	require.Equal(t, "orders", orders.Resource().Attributes().AsRaw()["service.name"])
+	require.Equal(t, "SELECT", orders.ScopeSpans().At(0).Spans().At(0).Name())
+
+	_, err = jaegerJSONTracesUnmarshaler{}.UnmarshalTraces([]byte(`{"operationName": 1}`))
+	require.ErrorContains(t, err, "unable to unmarshal JSON message 0")
+}
@@ -0,0 +1,84 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ingestJob is a time range submitted by the OpAMP server, read once the time
+// range of the configuration and the jobs submitted before have been read.
+type ingestJob struct {
+	// ID is the command_id of the submit command, sent along with the status of
+	// the job.
+	ID string
+	// StartTime and EndTime accept the same values as starttime and endtime.
+	StartTime string
+	EndTime   string
+}
+
+// validate checks that the time range of the job can be read.
+func (j ingestJob) validate() error {
+	if j.StartTime == "" || j.EndTime == "" {
+		return errors.New("the starttime and endtime of the job are required")
+	}
+	if _, err := parseTime(j.StartTime, "starttime"); err != nil {
+		return err
+	}
+	_, err := parseTime(j.EndTime, "endtime")
+	return err
+}
+
+// jobQueue holds the jobs submitted and not yet started, in submission order.
+type jobQueue struct {
+	mu   sync.Mutex
+	jobs []ingestJob
+	// submitted is signaled once a job is submitted, waking up next.
+	submitted chan struct{}
+}
+
+func newJobQueue() *jobQueue {
+	return &jobQueue{submitted: make(chan struct{}, 1)}
+}
+
+// submit appends job to the queue, and returns the number of jobs queued.
+func (q *jobQueue) submit(job ingestJob) int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.jobs = append(q.jobs, job)
+	select {
+	case q.submitted <- struct{}{}:
+	default:
+	}
+	return len(q.jobs)
+}
+
+// next removes the first job from the queue, waiting for a job to be submitted
+// if none is queued, unless ctx is done first.
+func (q *jobQueue) next(ctx context.Context) (ingestJob, error) {
+	for {
+		q.mu.Lock()
+		if len(q.jobs) > 0 {
+			job := q.jobs[0]
+			q.jobs = q.jobs[1:]
+			q.mu.Unlock()
+			return job, nil
+		}
+		q.mu.Unlock()
+		select {
+		case <-ctx.Done():
+			return ingestJob{}, ctx.Err()
+		case <-q.submitted:
+		}
+	}
+}
+
+// len returns the number of jobs queued.
+func (q *jobQueue) len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.jobs)
+}
@@ -0,0 +1,46 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_jobQueue(t *testing.T) {
+	queue := newJobQueue()
+	require.Equal(t, 1, queue.submit(ingestJob{ID: "1"}))
+	require.Equal(t, 2, queue.submit(ingestJob{ID: "2"}))
+	job, err := queue.next(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "1", job.ID)
+	require.Equal(t, 1, queue.len())
+	job, err = queue.next(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "2", job.ID)
+
+	// next waits for the next job to be submitted.
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		queue.submit(ingestJob{ID: "3"})
+	}()
+	job, err = queue.next(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "3", job.ID)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = queue.next(ctx)
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+func Test_ingestJob_validate(t *testing.T) {
+	require.NoError(t, ingestJob{StartTime: "2024-01-01 01:00", EndTime: "2024-01-02"}.validate())
+	require.EqualError(t, ingestJob{StartTime: "2024-01-01"}.validate(), "the starttime and endtime of the job are required")
+	require.ErrorContains(t, ingestJob{StartTime: "yesterday", EndTime: "2024-01-02"}.validate(), "unable to parse starttime (yesterday)")
+	require.ErrorContains(t, ingestJob{StartTime: "2024-01-01", EndTime: "tomorrow"}.validate(), "unable to parse endtime (tomorrow)")
+}
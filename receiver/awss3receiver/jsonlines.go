@@ -0,0 +1,115 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.uber.org/zap"
+)
+
+// jsonLinesDecoder decodes objects holding a JSON document per line, such as
+// application logs, into a log record per document.
+type jsonLinesDecoder struct {
+	cfg        JSONLinesConfig
+	timestamps timestampParser
+	logger     *zap.Logger
+}
+
+// newJSONLinesDecoder returns the decoder of the json_lines objects, whose
+// timestamp layout has been validated.
+func newJSONLinesDecoder(cfg JSONLinesConfig, logger *zap.Logger) *jsonLinesDecoder {
+	timestamps, _ := cfg.parser()
+	return &jsonLinesDecoder{cfg: cfg, timestamps: timestamps, logger: logger}
+}
+
+func (d *jsonLinesDecoder) decodeLogs(info objectInfo, data []byte) (plog.Logs, error) {
+	logs, records := newObjectLogs()
+	for index, line := range bytes.Split(data, []byte("\n")) {
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+		document, err := parseJSONDocument(line)
+		if err != nil {
+			d.logger.Warn("Skipping invalid JSON line", zap.String("key", info.key), zap.Int("line", index+1), zap.Error(err))
+			continue
+		}
+		record := appendObjectRecord(records, info)
+		if err := d.setRecord(record, document); err != nil {
+			d.logger.Warn("Unable to parse the timestamp of a JSON line", zap.String("key", info.key), zap.Int("line", index+1), zap.Error(err))
+		}
+	}
+	return logs, nil
+}
+
+// setRecord sets the body, attributes and timestamp of a record from a document.
+func (d *jsonLinesDecoder) setRecord(record plog.LogRecord, document any) error {
+	fields, isObject := document.(map[string]any)
+	var timestampErr error
+	if isObject && d.cfg.TimestampField != "" {
+		if value, ok := fields[d.cfg.TimestampField]; ok {
+			timestamp, err := d.timestamps.parse(value)
+			if err != nil {
+				timestampErr = err
+			} else {
+				record.SetTimestamp(pcommon.NewTimestampFromTime(timestamp))
+			}
+		}
+	}
+	if !isObject || d.cfg.BodyField == "" {
+		_ = record.Body().FromRaw(document)
+		return timestampErr
+	}
+	for name, value := range fields {
+		switch name {
+		case d.cfg.BodyField:
+			_ = record.Body().FromRaw(value)
+		case d.cfg.TimestampField:
+		default:
+			_ = record.Attributes().PutEmpty(name).FromRaw(value)
+		}
+	}
+	return timestampErr
+}
+
+// parseJSONDocument parses a JSON document, keeping integers as int64 values.
+func parseJSONDocument(data []byte) (any, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var document any
+	if err := decoder.Decode(&document); err != nil {
+		return nil, err
+	}
+	if decoder.More() {
+		return nil, errors.New("unexpected data after the JSON document")
+	}
+	return normalizeJSONNumbers(document), nil
+}
+
+// normalizeJSONNumbers replaces the json.Number values of a document by int64,
+// or float64 values if they are not integers.
+func normalizeJSONNumbers(value any) any {
+	switch v := value.(type) {
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+		f, _ := v.Float64()
+		return f
+	case map[string]any:
+		for key, item := range v {
+			v[key] = normalizeJSONNumbers(item)
+		}
+	case []any:
+		for i, item := range v {
+			v[i] = normalizeJSONNumbers(item)
+		}
+	}
+	return value
+}
@@ -0,0 +1,76 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.uber.org/zap"
+)
+
+func Test_jsonLinesDecoder(t *testing.T) {
+	info := objectInfo{bucket: "bucket", key: "app/logs_1.jsonl", lastModified: testTime}
+	data := []byte(`{"time":"2021-02-01T17:00:00Z","message":"started","level":"info","pid":42,"tags":{"env":"prod"}}
+
+not json
+{"time":1612198800.5,"message":"stopped","ratio":0.5}
+`)
+
+	decoder := newJSONLinesDecoder(JSONLinesConfig{BodyField: "message", TimestampField: "time"}, zap.NewNop())
+	logs, err := decoder.decodeLogs(info, data)
+	require.NoError(t, err)
+	records := logs.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords()
+	require.Equal(t, 2, records.Len())
+
+	first := records.At(0)
+	require.Equal(t, "started", first.Body().Str())
+	require.Equal(t, time.Date(2021, 2, 1, 17, 0, 0, 0, time.UTC), first.Timestamp().AsTime())
+	require.Equal(t, pcommon.NewTimestampFromTime(testTime), first.ObservedTimestamp())
+	require.Equal(t, map[string]any{
+		"aws.s3.bucket": "bucket",
+		"aws.s3.key":    "app/logs_1.jsonl",
+		"level":         "info",
+		"pid":           int64(42),
+		"tags":          map[string]any{"env": "prod"},
+	}, first.Attributes().AsRaw())
+
+	second := records.At(1)
+	require.Equal(t, "stopped", second.Body().Str())
+	require.Equal(t, time.Unix(1612198800, 5e8).UTC(), second.Timestamp().AsTime())
+	require.Equal(t, 0.5, second.Attributes().AsRaw()["ratio"])
+
+	decoder = newJSONLinesDecoder(JSONLinesConfig{TimestampField: "time", TimestampLayoutConfig: TimestampLayoutConfig{TimestampLayout: time.DateTime}}, zap.NewNop())
+	logs, err = decoder.decodeLogs(info, []byte(`{"time":"2021-02-01 17:00:00","message":"started"}`))
+	require.NoError(t, err)
+	record := logs.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0)
+	require.Equal(t, map[string]any{"time": "2021-02-01 17:00:00", "message": "started"}, record.Body().Map().AsRaw())
+	require.Equal(t, time.Date(2021, 2, 1, 17, 0, 0, 0, time.UTC), record.Timestamp().AsTime())
+}
+
+func Test_receiveObject_JSONLines(t *testing.T) {
+	sink := &consumertest.LogsSink{}
+	r := &awss3Receiver{
+		dataProcessor: newLogsProcessor(sink, LogsConfig{SignalConfig: SignalConfig{Format: FormatJSONLines}}, zap.NewNop()),
+		logger:        zap.NewNop(),
+	}
+	ctx := contextWithObjectInfo(context.Background(), objectInfo{bucket: "bucket", key: "app/logs_1.jsonl.gz"})
+	require.NoError(t, r.receiveObject(ctx, "app/logs_1.jsonl.gz", bytes.NewReader(gzipCompress([]byte("{\"message\":\"a\"}\n{\"message\":\"b\"}\n")))))
+	require.Equal(t, 2, sink.LogRecordCount())
+	key, _ := sink.AllLogs()[0].ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0).Attributes().Get("aws.s3.key")
+	require.Equal(t, "app/logs_1.jsonl.gz", key.Str())
+
+	data, err := os.ReadFile(filepath.Join("testdata", "concatenated.jsonl.gz"))
+	require.NoError(t, err)
+	require.NoError(t, r.receiveObject(ctx, "app/logs_2.jsonl.gz", bytes.NewReader(data)))
+	require.Equal(t, 5, sink.LogRecordCount())
+	require.Equal(t, "third", sink.AllLogs()[1].ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(2).Body().Map().AsRaw()["message"])
+}
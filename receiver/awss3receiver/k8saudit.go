@@ -0,0 +1,182 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"regexp"
+	"time"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	conventions "go.opentelemetry.io/collector/semconv/v1.25.0"
+	"go.uber.org/zap"
+)
+
+// eksLogGroupPattern matches the name of the log group EKS publishes the control
+// plane logs of a cluster to.
+var eksLogGroupPattern = regexp.MustCompile(`^/aws/eks/([^/]+)/cluster$`)
+
+// kubernetesAuditDecoder decodes Kubernetes API server audit logs, holding an
+// audit.k8s.io Event, or EventList, per line, into a log record per event. The
+// CloudWatch Logs subscription data of the control plane logs of EKS clusters,
+// delivered by Firehose, are decoded into the records of their audit events,
+// grouped by the account and cluster of their log group.
+type kubernetesAuditDecoder struct {
+	logger *zap.Logger
+}
+
+// kubernetesAuditResource identifies the account and cluster audit events come
+// from, when known.
+type kubernetesAuditResource struct {
+	accountID   string
+	clusterName string
+}
+
+func (d *kubernetesAuditDecoder) decodeLogs(info objectInfo, data []byte) (plog.Logs, error) {
+	logs := plog.NewLogs()
+	resources := map[kubernetesAuditResource]plog.LogRecordSlice{}
+	records := func(resource kubernetesAuditResource) plog.LogRecordSlice {
+		if records, ok := resources[resource]; ok {
+			return records
+		}
+		resourceLogs := logs.ResourceLogs().AppendEmpty()
+		attributes := resourceLogs.Resource().Attributes()
+		if resource != (kubernetesAuditResource{}) {
+			attributes.PutStr(conventions.AttributeCloudProvider, conventions.AttributeCloudProviderAWS)
+			attributes.PutStr(conventions.AttributeCloudPlatform, conventions.AttributeCloudPlatformAWSEKS)
+		}
+		putNonEmptyStr(attributes, conventions.AttributeCloudAccountID, resource.accountID)
+		putNonEmptyStr(attributes, conventions.AttributeK8SClusterName, resource.clusterName)
+		resources[resource] = resourceLogs.ScopeLogs().AppendEmpty().LogRecords()
+		return resources[resource]
+	}
+	for index, line := range bytes.Split(data, []byte("\n")) {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		// Firehose concatenates the subscription data without separating them.
+		err := forEachJSONMessage(line, func(message []byte) error {
+			document, err := parseJSONDocument(message)
+			if err != nil {
+				return err
+			}
+			object, ok := document.(map[string]any)
+			if !ok {
+				return errors.New("not a JSON object")
+			}
+			if _, ok := object["logEvents"]; !ok {
+				for _, event := range kubernetesAuditEvents(object) {
+					d.appendEvent(records(kubernetesAuditResource{}), info, event, index)
+				}
+				return nil
+			}
+			var subscription cloudWatchLogsData
+			if err := json.Unmarshal(message, &subscription); err != nil {
+				return err
+			}
+			resource := kubernetesAuditResource{accountID: subscription.Owner}
+			if match := eksLogGroupPattern.FindStringSubmatch(subscription.LogGroup); match != nil {
+				resource.clusterName = match[1]
+			}
+			for _, logEvent := range subscription.LogEvents {
+				// The log group holds the other control plane logs of the cluster.
+				document, err := parseJSONDocument([]byte(logEvent.Message))
+				if err != nil {
+					continue
+				}
+				event, _ := document.(map[string]any)
+				if stringField(event, "kind") != "Event" {
+					continue
+				}
+				d.appendEvent(records(resource), info, event, index)
+			}
+			return nil
+		})
+		if err != nil {
+			d.logger.Warn("Skipping invalid Kubernetes audit log", zap.String("key", info.key), zap.Int("line", index+1), zap.Error(err))
+		}
+	}
+	return logs, nil
+}
+
+func (d *kubernetesAuditDecoder) appendEvent(records plog.LogRecordSlice, info objectInfo, event map[string]any, index int) {
+	record := appendObjectRecord(records, info)
+	if err := setKubernetesAuditRecord(record, event); err != nil {
+		d.logger.Warn("Unable to parse the time of a Kubernetes audit event", zap.String("key", info.key), zap.Int("line", index+1), zap.Error(err))
+	}
+}
+
+// kubernetesAuditEvents returns the events of a document, an Event or an
+// EventList.
+func kubernetesAuditEvents(document map[string]any) []map[string]any {
+	if stringField(document, "kind") != "EventList" {
+		return []map[string]any{document}
+	}
+	items, _ := document["items"].([]any)
+	events := make([]map[string]any, 0, len(items))
+	for _, item := range items {
+		if event, ok := item.(map[string]any); ok {
+			events = append(events, event)
+		}
+	}
+	return events
+}
+
+// setKubernetesAuditRecord sets the body, attributes and timestamp of a record
+// from an audit event, the body being the whole event and the timestamp the time
+// the request was received.
+func setKubernetesAuditRecord(record plog.LogRecord, event map[string]any) error {
+	attributes := record.Attributes()
+	putStringField(attributes, "k8s.audit.id", event, "auditID")
+	putStringField(attributes, "k8s.audit.level", event, "level")
+	putStringField(attributes, "k8s.audit.stage", event, "stage")
+	putStringField(attributes, "k8s.audit.verb", event, "verb")
+	putStringField(attributes, "k8s.audit.request_uri", event, "requestURI")
+	putStringField(attributes, conventions.AttributeUserAgentOriginal, event, "userAgent")
+	if sourceIPs, ok := event["sourceIPs"].([]any); ok && len(sourceIPs) > 0 {
+		if address, ok := sourceIPs[0].(string); ok {
+			attributes.PutStr(conventions.AttributeSourceAddress, address)
+		}
+	}
+	if user, ok := event["user"].(map[string]any); ok {
+		putStringField(attributes, conventions.AttributeEnduserID, user, "username")
+		if groups, ok := user["groups"].([]any); ok {
+			_ = attributes.PutEmptySlice("k8s.audit.user.groups").FromRaw(groups)
+		}
+	}
+	if user, ok := event["impersonatedUser"].(map[string]any); ok {
+		putStringField(attributes, "k8s.audit.impersonated_user.username", user, "username")
+	}
+	if objectRef, ok := event["objectRef"].(map[string]any); ok {
+		putStringField(attributes, conventions.AttributeK8SNamespaceName, objectRef, "namespace")
+		putStringField(attributes, "k8s.audit.object_ref.resource", objectRef, "resource")
+		putStringField(attributes, "k8s.audit.object_ref.subresource", objectRef, "subresource")
+		putStringField(attributes, "k8s.audit.object_ref.name", objectRef, "name")
+		putStringField(attributes, "k8s.audit.object_ref.api_group", objectRef, "apiGroup")
+		putStringField(attributes, "k8s.audit.object_ref.api_version", objectRef, "apiVersion")
+	}
+	if status, ok := event["responseStatus"].(map[string]any); ok {
+		if code, ok := status["code"].(int64); ok {
+			attributes.PutInt(conventions.AttributeHTTPResponseStatusCode, code)
+		}
+		putStringField(attributes, "k8s.audit.response_status.reason", status, "reason")
+	}
+	_ = record.Body().FromRaw(event)
+	receivedTime := stringField(event, "requestReceivedTimestamp")
+	if receivedTime == "" {
+		receivedTime = stringField(event, "stageTimestamp")
+	}
+	if receivedTime == "" {
+		return nil
+	}
+	timestamp, err := time.Parse(time.RFC3339Nano, receivedTime)
+	if err != nil {
+		return err
+	}
+	record.SetTimestamp(pcommon.NewTimestampFromTime(timestamp))
+	return nil
+}
@@ -0,0 +1,93 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+const testKubernetesAuditEvent = `{"kind":"Event","apiVersion":"audit.k8s.io/v1","level":"Metadata","auditID":"7d0d5e3a","stage":"ResponseComplete","requestURI":"/api/v1/namespaces/default/pods/web-0","verb":"delete","user":{"username":"alice","groups":["developers","system:authenticated"]},"sourceIPs":["10.0.0.12"],"userAgent":"kubectl/v1.29.0","objectRef":{"resource":"pods","namespace":"default","name":"web-0","apiVersion":"v1"},"responseStatus":{"metadata":{},"code":200},"requestReceivedTimestamp":"2024-01-01T10:00:00.123456Z","stageTimestamp":"2024-01-01T10:00:00.200000Z"}`
+
+func Test_kubernetesAuditDecoder(t *testing.T) {
+	info := objectInfo{bucket: "bucket", key: "audit/2024/01/01/audit.log"}
+	data := []byte(testKubernetesAuditEvent + `
+not json
+{"kind":"EventList","apiVersion":"audit.k8s.io/v1","items":[{"kind":"Event","auditID":"a1","verb":"get","responseStatus":{"code":404,"reason":"NotFound"},"stageTimestamp":"2024-01-01T10:00:01Z"},{"kind":"Event","auditID":"a2","verb":"list"}]}
+`)
+
+	logs, err := (&kubernetesAuditDecoder{logger: zap.NewNop()}).decodeLogs(info, data)
+	require.NoError(t, err)
+	require.Equal(t, 1, logs.ResourceLogs().Len())
+	require.Equal(t, 0, logs.ResourceLogs().At(0).Resource().Attributes().Len())
+	records := logs.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords()
+	require.Equal(t, 3, records.Len())
+
+	record := records.At(0)
+	require.Equal(t, time.Date(2024, 1, 1, 10, 0, 0, 123456000, time.UTC), record.Timestamp().AsTime())
+	require.Equal(t, "7d0d5e3a", record.Body().Map().AsRaw()["auditID"])
+	require.Equal(t, map[string]any{
+		"aws.s3.bucket":                    "bucket",
+		"aws.s3.key":                       info.key,
+		"k8s.audit.id":                     "7d0d5e3a",
+		"k8s.audit.level":                  "Metadata",
+		"k8s.audit.stage":                  "ResponseComplete",
+		"k8s.audit.verb":                   "delete",
+		"k8s.audit.request_uri":            "/api/v1/namespaces/default/pods/web-0",
+		"user_agent.original":              "kubectl/v1.29.0",
+		"source.address":                   "10.0.0.12",
+		"enduser.id":                       "alice",
+		"k8s.audit.user.groups":            []any{"developers", "system:authenticated"},
+		"k8s.namespace.name":               "default",
+		"k8s.audit.object_ref.resource":    "pods",
+		"k8s.audit.object_ref.name":        "web-0",
+		"k8s.audit.object_ref.api_version": "v1",
+		"http.response.status_code":        int64(200),
+	}, record.Attributes().AsRaw())
+
+	record = records.At(1)
+	require.Equal(t, time.Date(2024, 1, 1, 10, 0, 1, 0, time.UTC), record.Timestamp().AsTime())
+	require.Equal(t, int64(404), record.Attributes().AsRaw()["http.response.status_code"])
+	require.Equal(t, "NotFound", record.Attributes().AsRaw()["k8s.audit.response_status.reason"])
+	require.Equal(t, "list", records.At(2).Attributes().AsRaw()["k8s.audit.verb"])
+}
+
+func Test_kubernetesAuditDecoder_EKS(t *testing.T) {
+	subscription := func(logGroup string, messages ...string) string {
+		events := make([]map[string]any, len(messages))
+		for i, message := range messages {
+			events[i] = map[string]any{"timestamp": 1704103200000, "message": message}
+		}
+		data, err := json.Marshal(map[string]any{
+			"messageType": "DATA_MESSAGE",
+			"owner":       "123456789012",
+			"logGroup":    logGroup,
+			"logStream":   "kube-apiserver-audit-0123",
+			"logEvents":   events,
+		})
+		require.NoError(t, err)
+		return string(data)
+	}
+	info := objectInfo{key: "eks/2024/01/01/10/stream-1"}
+	data := []byte(subscription("/aws/eks/prod/cluster", testKubernetesAuditEvent, "I0101 10:00:00.000000 1 controller.go:1] not an audit event") +
+		subscription("/aws/eks/staging/cluster", `{"kind":"Event","auditID":"s1","verb":"watch"}`))
+
+	logs, err := (&kubernetesAuditDecoder{logger: zap.NewNop()}).decodeLogs(info, data)
+	require.NoError(t, err)
+	require.Equal(t, 2, logs.ResourceLogs().Len())
+	require.Equal(t, map[string]any{
+		"cloud.provider":   "aws",
+		"cloud.platform":   "aws_eks",
+		"cloud.account.id": "123456789012",
+		"k8s.cluster.name": "prod",
+	}, logs.ResourceLogs().At(0).Resource().Attributes().AsRaw())
+	records := logs.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords()
+	require.Equal(t, 1, records.Len())
+	require.Equal(t, "delete", records.At(0).Attributes().AsRaw()["k8s.audit.verb"])
+	require.Equal(t, "staging", logs.ResourceLogs().At(1).Resource().Attributes().AsRaw()["k8s.cluster.name"])
+}
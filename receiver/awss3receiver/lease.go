@@ -0,0 +1,211 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/collector/component"
+	"go.uber.org/zap"
+)
+
+// defaultLeaseDuration is the time the lease is held for without being renewed
+// by default.
+const defaultLeaseDuration = 30 * time.Second
+
+// The attributes of the lease item, besides its id.
+const (
+	leaseOwnerAttribute     = "owner"
+	leaseExpiresAtAttribute = "expires_at"
+)
+
+// lease is held by a single one of the collectors running the same receiver.
+// It is acquired by writing its item with the collector as owner, on condition
+// it does not exist, is already owned by the collector, or has expired, then
+// renewed the same way until it is released.
+type lease struct {
+	client        DynamoDBAPI
+	table         string
+	id            string
+	owner         string
+	duration      time.Duration
+	renewInterval time.Duration
+	now           func() time.Time
+	logger        *zap.Logger
+
+	mu sync.Mutex
+	// held is set while the collector holds the lease.
+	held bool
+	wg   sync.WaitGroup
+}
+
+func newLease(ctx context.Context, downloaderCfg S3DownloaderConfig, cfg LeaseConfig, id component.ID, telemetryType string, logger *zap.Logger) (*lease, error) {
+	client, err := newDynamoDBClient(ctx, downloaderCfg, cfg.DynamoDB)
+	if err != nil {
+		return nil, err
+	}
+	owner := cfg.Owner
+	if owner == "" {
+		hostname, err := os.Hostname()
+		if err != nil {
+			return nil, fmt.Errorf("unable to name the owner of the lease: %w", err)
+		}
+		owner = hostname + "-" + uuid.NewString()
+	}
+	return newDynamoDBLease(client, cfg, fmt.Sprintf("%slease_%s_%s", cfg.DynamoDB.KeyPrefix, id, telemetryType), owner, logger), nil
+}
+
+func newDynamoDBLease(client DynamoDBAPI, cfg LeaseConfig, id, owner string, logger *zap.Logger) *lease {
+	duration := cfg.leaseDuration()
+	renewInterval := cfg.RenewInterval
+	if renewInterval == 0 {
+		renewInterval = duration / 3
+	}
+	return &lease{
+		client:        client,
+		table:         cfg.DynamoDB.TableName,
+		id:            id,
+		owner:         owner,
+		duration:      duration,
+		renewInterval: renewInterval,
+		now:           time.Now,
+		logger:        logger,
+	}
+}
+
+// tryAcquire acquires or renews the lease, and reports whether the collector
+// holds it.
+func (l *lease) tryAcquire(ctx context.Context) (bool, error) {
+	now := l.now()
+	_, err := l.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(l.table),
+		Item: map[string]types.AttributeValue{
+			dynamoDBIDAttribute:     &types.AttributeValueMemberS{Value: l.id},
+			leaseOwnerAttribute:     &types.AttributeValueMemberS{Value: l.owner},
+			leaseExpiresAtAttribute: &types.AttributeValueMemberN{Value: strconv.FormatInt(now.Add(l.duration).UnixMilli(), 10)},
+		},
+		ConditionExpression: aws.String("attribute_not_exists(#id) OR #owner = :owner OR #expires_at < :now"),
+		ExpressionAttributeNames: map[string]string{
+			"#id":         dynamoDBIDAttribute,
+			"#owner":      leaseOwnerAttribute,
+			"#expires_at": leaseExpiresAtAttribute,
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":owner": &types.AttributeValueMemberS{Value: l.owner},
+			":now":   &types.AttributeValueMemberN{Value: strconv.FormatInt(now.UnixMilli(), 10)},
+		},
+	})
+	var conditionalCheckFailed *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionalCheckFailed) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("unable to acquire the lease %q in DynamoDB table %q: %w", l.id, l.table, err)
+	}
+	return true, nil
+}
+
+// acquire waits for the collector to acquire the lease, then renews it until ctx
+// is done. It returns a context cancelled once the lease is lost, either taken
+// over by another collector or not renewed before it could expire.
+func (l *lease) acquire(ctx context.Context) (context.Context, error) {
+	for standby := false; ; standby = true {
+		acquired, err := l.tryAcquire(ctx)
+		if err != nil {
+			l.logger.Warn("Failed to acquire the lease", zap.Error(err))
+		}
+		if acquired {
+			break
+		}
+		if !standby {
+			l.logger.Info("The lease is held by another collector, standing by", zap.String("lease", l.id))
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(l.renewInterval):
+		}
+	}
+	l.logger.Info("Acquired the lease", zap.String("lease", l.id), zap.String("owner", l.owner))
+	l.mu.Lock()
+	l.held = true
+	l.mu.Unlock()
+	leaseCtx, cancel := context.WithCancel(ctx)
+	l.wg.Add(1)
+	go func() {
+		defer l.wg.Done()
+		defer cancel()
+		expiresAt := l.now().Add(l.duration)
+		ticker := time.NewTicker(l.renewInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-leaseCtx.Done():
+				return
+			case <-ticker.C:
+			}
+			renewed, err := l.tryAcquire(leaseCtx)
+			switch {
+			case renewed:
+				expiresAt = l.now().Add(l.duration)
+				continue
+			case err == nil:
+				l.logger.Error("The lease was taken over by another collector, stopping reading", zap.String("lease", l.id))
+			case l.now().Add(l.renewInterval).Before(expiresAt):
+				l.logger.Warn("Failed to renew the lease", zap.Error(err))
+				continue
+			default:
+				// The lease could expire before the next renewal, and be acquired by
+				// another collector meanwhile.
+				l.logger.Error("Failed to renew the lease before its expiry, stopping reading", zap.Error(err))
+			}
+			l.mu.Lock()
+			l.held = false
+			l.mu.Unlock()
+			return
+		}
+	}()
+	return leaseCtx, nil
+}
+
+// isHeld reports whether the collector holds the lease.
+func (l *lease) isHeld() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.held
+}
+
+// release stops renewing the lease and deletes it, if the collector holds it, for
+// a standby collector to take over without waiting for its expiry.
+func (l *lease) release(ctx context.Context) error {
+	l.wg.Wait()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if !l.held {
+		return nil
+	}
+	l.held = false
+	_, err := l.client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName:                 aws.String(l.table),
+		Key:                       map[string]types.AttributeValue{dynamoDBIDAttribute: &types.AttributeValueMemberS{Value: l.id}},
+		ConditionExpression:       aws.String("#owner = :owner"),
+		ExpressionAttributeNames:  map[string]string{"#owner": leaseOwnerAttribute},
+		ExpressionAttributeValues: map[string]types.AttributeValue{":owner": &types.AttributeValueMemberS{Value: l.owner}},
+	})
+	var conditionalCheckFailed *types.ConditionalCheckFailedException
+	if err != nil && !errors.As(err, &conditionalCheckFailed) {
+		return fmt.Errorf("unable to release the lease %q in DynamoDB table %q: %w", l.id, l.table, err)
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func Test_lease_tryAcquire(t *testing.T) {
+	api := newMockDynamoDBAPI()
+	cfg := LeaseConfig{DynamoDB: DynamoDBConfig{TableName: "leases"}}
+	active := newDynamoDBLease(api, cfg, "lease_awss3_logs", "a", zap.NewNop())
+	standby := newDynamoDBLease(api, cfg, "lease_awss3_logs", "b", zap.NewNop())
+	require.Equal(t, defaultLeaseDuration, active.duration)
+	require.Equal(t, defaultLeaseDuration/3, active.renewInterval)
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	active.now = func() time.Time { return now }
+	standby.now = func() time.Time { return now }
+
+	acquired, err := active.tryAcquire(context.Background())
+	require.NoError(t, err)
+	require.True(t, acquired)
+	acquired, err = standby.tryAcquire(context.Background())
+	require.NoError(t, err)
+	require.False(t, acquired)
+	// The lease is renewed by its owner.
+	now = now.Add(20 * time.Second)
+	acquired, err = active.tryAcquire(context.Background())
+	require.NoError(t, err)
+	require.True(t, acquired)
+
+	// The standby takes over once the lease has expired.
+	now = now.Add(time.Minute)
+	acquired, err = standby.tryAcquire(context.Background())
+	require.NoError(t, err)
+	require.True(t, acquired)
+	acquired, err = active.tryAcquire(context.Background())
+	require.NoError(t, err)
+	require.False(t, acquired)
+}
+
+func Test_lease_acquire(t *testing.T) {
+	api := newMockDynamoDBAPI()
+	cfg := LeaseConfig{DynamoDB: DynamoDBConfig{TableName: "leases"}, Duration: time.Minute, RenewInterval: 10 * time.Millisecond}
+	active := newDynamoDBLease(api, cfg, "lease_awss3_logs", "a", zap.NewNop())
+	standby := newDynamoDBLease(api, cfg, "lease_awss3_logs", "b", zap.NewNop())
+
+	activeCtx, cancelActive := context.WithCancel(context.Background())
+	leaseCtx, err := active.acquire(activeCtx)
+	require.NoError(t, err)
+
+	acquired := make(chan context.Context)
+	standbyCtx, cancelStandby := context.WithCancel(context.Background())
+	defer cancelStandby()
+	go func() {
+		ctx, err := standby.acquire(standbyCtx)
+		require.NoError(t, err)
+		acquired <- ctx
+	}()
+	select {
+	case <-acquired:
+		t.Fatal("the standby acquired the lease held by the active collector")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// Releasing the lease lets the standby take over without waiting for its expiry.
+	cancelActive()
+	<-leaseCtx.Done()
+	require.NoError(t, active.release(context.Background()))
+	var standbyLeaseCtx context.Context
+	select {
+	case standbyLeaseCtx = <-acquired:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the standby did not acquire the released lease")
+	}
+
+	// The lease context is cancelled once the lease is taken over.
+	api.mu.Lock()
+	api.items["lease_awss3_logs"][leaseOwnerAttribute] = &types.AttributeValueMemberS{Value: "c"}
+	api.mu.Unlock()
+	select {
+	case <-standbyLeaseCtx.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("the lease context was not cancelled once the lease was lost")
+	}
+	require.NoError(t, standby.release(context.Background()))
+	require.Contains(t, api.items, "lease_awss3_logs")
+}
+
+func Test_awss3Receiver_Lease(t *testing.T) {
+	api := newMockDynamoDBAPI()
+	cfg := LeaseConfig{DynamoDB: DynamoDBConfig{TableName: "leases"}, Duration: time.Minute, RenewInterval: 10 * time.Millisecond}
+	reading := make(chan string, 2)
+	newReceiver := func(owner string) *awss3Receiver {
+		return &awss3Receiver{
+			reader: mockTelemetryReader(func(ctx context.Context, _ string, _ s3ReaderDataCallback) error {
+				reading <- owner
+				<-ctx.Done()
+				return nil
+			}),
+			telemetryType: "logs",
+			passes:        1,
+			lease:         newDynamoDBLease(api, cfg, "lease_awss3_logs", owner, zap.NewNop()),
+			logger:        zap.NewNop(),
+		}
+	}
+	active, standby := newReceiver("a"), newReceiver("b")
+	require.NoError(t, active.Start(context.Background(), nil))
+	require.Equal(t, "a", <-reading)
+	require.NoError(t, standby.Start(context.Background(), nil))
+	select {
+	case owner := <-reading:
+		t.Fatalf("%s read while the lease was held", owner)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	// The standby takes over once the active collector shuts down.
+	require.NoError(t, active.Shutdown(context.Background()))
+	select {
+	case owner := <-reading:
+		require.Equal(t, "b", owner)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the standby did not take over")
+	}
+	require.NoError(t, standby.Shutdown(context.Background()))
+	require.Empty(t, api.items)
+}
@@ -0,0 +1,172 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"strings"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	conventions "go.opentelemetry.io/collector/semconv/v1.25.0"
+	"go.uber.org/zap"
+)
+
+// logsDecoder decodes the contents of an object in a logs format other than OTLP.
+type logsDecoder interface {
+	decodeLogs(info objectInfo, data []byte) (plog.Logs, error)
+}
+
+// newLogsDecoder returns the decoder of the configured logs format, or nil if
+// the objects hold OTLP.
+func newLogsDecoder(cfg LogsConfig, logger *zap.Logger) logsDecoder {
+	switch cfg.Format {
+	case FormatJSONLines:
+		return newJSONLinesDecoder(cfg.JSONLines, logger)
+	case FormatText:
+		return newTextDecoder(cfg.Text, logger)
+	case FormatCSV, FormatTSV:
+		return newCSVDecoder(cfg.CSV, cfg.Format, logger)
+	case FormatAvro:
+		return newAvroDecoder(cfg.Avro, logger)
+	case FormatCloudTrail:
+		return &cloudTrailDecoder{logger: logger}
+	case FormatVPCFlowLogs:
+		return &vpcFlowLogsDecoder{logger: logger}
+	case FormatELBAccessLogs:
+		return &elbAccessLogsDecoder{logger: logger}
+	case FormatCloudFront:
+		return &cloudFrontDecoder{logger: logger}
+	case FormatS3AccessLogs:
+		return &s3AccessLogsDecoder{logger: logger}
+	case FormatRoute53Resolver:
+		return &route53ResolverDecoder{logger: logger}
+	case FormatOCSF:
+		return ocsfDecoder{}
+	case FormatRDS:
+		return rdsDecoder{}
+	case FormatKubernetesAudit:
+		return &kubernetesAuditDecoder{logger: logger}
+	case FormatSyslog:
+		return newSyslogDecoder(cfg.Syslog, logger)
+	case FormatFluentBit:
+		return &fluentBitDecoder{logger: logger}
+	default:
+		return nil
+	}
+}
+
+// cloudWatchLogsData is the data CloudWatch Logs subscriptions deliver, through
+// Firehose, for the log events of a log stream.
+type cloudWatchLogsData struct {
+	MessageType string `json:"messageType"`
+	Owner       string `json:"owner"`
+	LogGroup    string `json:"logGroup"`
+	LogStream   string `json:"logStream"`
+	LogEvents   []struct {
+		Timestamp int64  `json:"timestamp"`
+		Message   string `json:"message"`
+	} `json:"logEvents"`
+}
+
+// newObjectLogs returns the logs to append the records decoded from an object to.
+func newObjectLogs() (plog.Logs, plog.LogRecordSlice) {
+	logs := plog.NewLogs()
+	records := logs.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords()
+	return logs, records
+}
+
+// cloudResourceLogs groups the records decoded from an object in resources by the
+// account and region they come from.
+type cloudResourceLogs struct {
+	logs      plog.Logs
+	resources map[cloudResource]plog.LogRecordSlice
+}
+
+// cloudResource identifies the account and region records come from.
+type cloudResource struct {
+	accountID string
+	region    string
+}
+
+func newCloudResourceLogs() *cloudResourceLogs {
+	return &cloudResourceLogs{logs: plog.NewLogs(), resources: map[cloudResource]plog.LogRecordSlice{}}
+}
+
+// records returns the records of the resource with the cloud.provider,
+// cloud.account.id and cloud.region attributes of the given account and region.
+func (l *cloudResourceLogs) records(accountID, region string) plog.LogRecordSlice {
+	resource := cloudResource{accountID: accountID, region: region}
+	if records, ok := l.resources[resource]; ok {
+		return records
+	}
+	resourceLogs := l.logs.ResourceLogs().AppendEmpty()
+	attributes := resourceLogs.Resource().Attributes()
+	attributes.PutStr(conventions.AttributeCloudProvider, conventions.AttributeCloudProviderAWS)
+	if accountID != "" {
+		attributes.PutStr(conventions.AttributeCloudAccountID, accountID)
+	}
+	if region != "" {
+		attributes.PutStr(conventions.AttributeCloudRegion, region)
+	}
+	records := resourceLogs.ScopeLogs().AppendEmpty().LogRecords()
+	l.resources[resource] = records
+	return records
+}
+
+// appendObjectRecord appends a log record with attributes identifying the object,
+// and archive member, it was decoded from, observed at the last modification of
+// the object.
+func appendObjectRecord(records plog.LogRecordSlice, info objectInfo) plog.LogRecord {
+	record := records.AppendEmpty()
+	if !info.lastModified.IsZero() {
+		record.SetObservedTimestamp(pcommon.NewTimestampFromTime(info.lastModified))
+	}
+	if info.bucket != "" {
+		record.Attributes().PutStr(conventions.AttributeAWSS3Bucket, info.bucket)
+	}
+	record.Attributes().PutStr(conventions.AttributeAWSS3Key, info.key)
+	if info.member != "" {
+		record.Attributes().PutStr("aws.s3.archive.member", info.member)
+	}
+	return record
+}
+
+// splitLogFields splits a line of an access log into its space separated fields.
+// Fields enclosed in double quotes, in which backslashes escape the next
+// character, or in square brackets may contain spaces. The enclosing characters
+// are removed.
+func splitLogFields(line string) []string {
+	var fields []string
+	for i := 0; i < len(line); {
+		switch line[i] {
+		case ' ':
+			i++
+		case '"':
+			var field strings.Builder
+			for i++; i < len(line) && line[i] != '"'; i++ {
+				if line[i] == '\\' && i+1 < len(line) {
+					i++
+				}
+				field.WriteByte(line[i])
+			}
+			fields = append(fields, field.String())
+			i++
+		case '[':
+			end := strings.IndexByte(line[i:], ']')
+			if end < 0 {
+				end = len(line) - i
+			}
+			fields = append(fields, line[i+1:i+end])
+			i += end + 1
+		default:
+			end := strings.IndexByte(line[i:], ' ')
+			if end < 0 {
+				end = len(line) - i
+			}
+			fields = append(fields, line[i:i+end])
+			i += end
+		}
+	}
+	return fields
+}
@@ -0,0 +1,18 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_splitLogFields(t *testing.T) {
+	require.Equal(t, []string{
+		"http", "2021-02-01T17:00:00Z", "GET http://example.com/ HTTP/1.1", `curl "7.46"`, "06/Feb/2019:00:00:38 +0000", "-", "",
+	}, splitLogFields(`http  2021-02-01T17:00:00Z "GET http://example.com/ HTTP/1.1" "curl \"7.46\"" [06/Feb/2019:00:00:38 +0000] - ""`))
+	require.Equal(t, []string{"unterminated quote"}, splitLogFields(`"unterminated quote`))
+	require.Equal(t, []string{"unterminated bracket"}, splitLogFields(`[unterminated bracket`))
+}
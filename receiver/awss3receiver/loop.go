@@ -0,0 +1,127 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"sync/atomic"
+	"time"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+)
+
+// timestampShift is the offset added to the timestamps of the telemetry replayed
+// during a pass of the loop mode, so that the data appears current.
+type timestampShift struct {
+	offset atomic.Int64
+}
+
+func (s *timestampShift) set(offset time.Duration) {
+	s.offset.Store(int64(offset))
+}
+
+func (s *timestampShift) get() time.Duration {
+	return time.Duration(s.offset.Load())
+}
+
+func shiftTimestamp(ts pcommon.Timestamp, offset time.Duration) pcommon.Timestamp {
+	if ts == 0 {
+		return ts
+	}
+	return pcommon.NewTimestampFromTime(ts.AsTime().Add(offset))
+}
+
+func shiftTraces(td ptrace.Traces, offset time.Duration) {
+	for i := 0; i < td.ResourceSpans().Len(); i++ {
+		scopeSpans := td.ResourceSpans().At(i).ScopeSpans()
+		for j := 0; j < scopeSpans.Len(); j++ {
+			spans := scopeSpans.At(j).Spans()
+			for k := 0; k < spans.Len(); k++ {
+				span := spans.At(k)
+				span.SetStartTimestamp(shiftTimestamp(span.StartTimestamp(), offset))
+				span.SetEndTimestamp(shiftTimestamp(span.EndTimestamp(), offset))
+				for l := 0; l < span.Events().Len(); l++ {
+					event := span.Events().At(l)
+					event.SetTimestamp(shiftTimestamp(event.Timestamp(), offset))
+				}
+			}
+		}
+	}
+}
+
+func shiftLogs(ld plog.Logs, offset time.Duration) {
+	for i := 0; i < ld.ResourceLogs().Len(); i++ {
+		scopeLogs := ld.ResourceLogs().At(i).ScopeLogs()
+		for j := 0; j < scopeLogs.Len(); j++ {
+			records := scopeLogs.At(j).LogRecords()
+			for k := 0; k < records.Len(); k++ {
+				record := records.At(k)
+				record.SetTimestamp(shiftTimestamp(record.Timestamp(), offset))
+				record.SetObservedTimestamp(shiftTimestamp(record.ObservedTimestamp(), offset))
+			}
+		}
+	}
+}
+
+func shiftMetrics(md pmetric.Metrics, offset time.Duration) {
+	for i := 0; i < md.ResourceMetrics().Len(); i++ {
+		scopeMetrics := md.ResourceMetrics().At(i).ScopeMetrics()
+		for j := 0; j < scopeMetrics.Len(); j++ {
+			metrics := scopeMetrics.At(j).Metrics()
+			for k := 0; k < metrics.Len(); k++ {
+				shiftMetric(metrics.At(k), offset)
+			}
+		}
+	}
+}
+
+func shiftMetric(metric pmetric.Metric, offset time.Duration) {
+	switch metric.Type() {
+	case pmetric.MetricTypeGauge:
+		shiftNumberDataPoints(metric.Gauge().DataPoints(), offset)
+	case pmetric.MetricTypeSum:
+		shiftNumberDataPoints(metric.Sum().DataPoints(), offset)
+	case pmetric.MetricTypeHistogram:
+		dataPoints := metric.Histogram().DataPoints()
+		for i := 0; i < dataPoints.Len(); i++ {
+			dp := dataPoints.At(i)
+			dp.SetStartTimestamp(shiftTimestamp(dp.StartTimestamp(), offset))
+			dp.SetTimestamp(shiftTimestamp(dp.Timestamp(), offset))
+			shiftExemplars(dp.Exemplars(), offset)
+		}
+	case pmetric.MetricTypeExponentialHistogram:
+		dataPoints := metric.ExponentialHistogram().DataPoints()
+		for i := 0; i < dataPoints.Len(); i++ {
+			dp := dataPoints.At(i)
+			dp.SetStartTimestamp(shiftTimestamp(dp.StartTimestamp(), offset))
+			dp.SetTimestamp(shiftTimestamp(dp.Timestamp(), offset))
+			shiftExemplars(dp.Exemplars(), offset)
+		}
+	case pmetric.MetricTypeSummary:
+		dataPoints := metric.Summary().DataPoints()
+		for i := 0; i < dataPoints.Len(); i++ {
+			dp := dataPoints.At(i)
+			dp.SetStartTimestamp(shiftTimestamp(dp.StartTimestamp(), offset))
+			dp.SetTimestamp(shiftTimestamp(dp.Timestamp(), offset))
+		}
+	}
+}
+
+func shiftNumberDataPoints(dataPoints pmetric.NumberDataPointSlice, offset time.Duration) {
+	for i := 0; i < dataPoints.Len(); i++ {
+		dp := dataPoints.At(i)
+		dp.SetStartTimestamp(shiftTimestamp(dp.StartTimestamp(), offset))
+		dp.SetTimestamp(shiftTimestamp(dp.Timestamp(), offset))
+		shiftExemplars(dp.Exemplars(), offset)
+	}
+}
+
+func shiftExemplars(exemplars pmetric.ExemplarSlice, offset time.Duration) {
+	for i := 0; i < exemplars.Len(); i++ {
+		exemplar := exemplars.At(i)
+		exemplar.SetTimestamp(shiftTimestamp(exemplar.Timestamp(), offset))
+	}
+}
@@ -0,0 +1,82 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.uber.org/zap"
+)
+
+func Test_shiftTraces(t *testing.T) {
+	td := generateTraceData()
+	span := td.ResourceSpans().At(0).ScopeSpans().At(0).Spans().At(0)
+	span.Events().AppendEmpty().SetTimestamp(1581452772500000000)
+	shiftTraces(td, time.Hour)
+	require.Equal(t, pcommon.Timestamp(1581452772000000000+uint64(time.Hour)), span.StartTimestamp())
+	require.Equal(t, pcommon.Timestamp(1581452773000000000+uint64(time.Hour)), span.EndTimestamp())
+	require.Equal(t, pcommon.Timestamp(1581452772500000000+uint64(time.Hour)), span.Events().At(0).Timestamp())
+}
+
+func Test_shiftLogs(t *testing.T) {
+	ld := generateLogsData()
+	record := ld.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0)
+	shiftLogs(ld, time.Hour)
+	require.Equal(t, pcommon.Timestamp(1581452772000000000+uint64(time.Hour)), record.Timestamp())
+	// Unset timestamps are left unset.
+	require.Equal(t, pcommon.Timestamp(0), record.ObservedTimestamp())
+}
+
+func Test_shiftMetrics(t *testing.T) {
+	md := generateMetricsData()
+	metrics := md.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics()
+	histogram := metrics.AppendEmpty().SetEmptyHistogram().DataPoints().AppendEmpty()
+	histogram.SetStartTimestamp(1581452771000000000)
+	histogram.SetTimestamp(1581452772000000000)
+	histogram.Exemplars().AppendEmpty().SetTimestamp(1581452771500000000)
+	summary := metrics.AppendEmpty().SetEmptySummary().DataPoints().AppendEmpty()
+	summary.SetTimestamp(1581452772000000000)
+	shiftMetrics(md, -time.Hour)
+
+	require.Equal(t, pcommon.Timestamp(1581452772000000000-uint64(time.Hour)), metrics.At(0).Gauge().DataPoints().At(0).Timestamp())
+	require.Equal(t, pcommon.Timestamp(1581452771000000000-uint64(time.Hour)), histogram.StartTimestamp())
+	require.Equal(t, pcommon.Timestamp(1581452772000000000-uint64(time.Hour)), histogram.Timestamp())
+	require.Equal(t, pcommon.Timestamp(1581452771500000000-uint64(time.Hour)), histogram.Exemplars().At(0).Timestamp())
+	require.Equal(t, pcommon.Timestamp(1581452772000000000-uint64(time.Hour)), summary.Timestamp())
+	require.Equal(t, pmetric.MetricTypeSummary, metrics.At(2).Type())
+}
+
+func Test_awss3Receiver_Loop(t *testing.T) {
+	passes := 0
+	done := make(chan struct{})
+	shift := &timestampShift{}
+	rangeStart := time.Now().Add(-24 * time.Hour)
+	r := &awss3Receiver{
+		reader: mockTelemetryReader(func(ctx context.Context, _ string, dataCallback s3ReaderDataCallback) error {
+			passes++
+			if passes == 3 {
+				defer close(done)
+			}
+			return dataCallback(ctx, "traces_1.binpb", nil)
+		}),
+		telemetryType: "traces",
+		dataProcessor: func(_ context.Context, _ string, _ []byte) error {
+			return nil
+		},
+		passes:     3,
+		shift:      shift,
+		rangeStart: rangeStart,
+		logger:     zap.NewNop(),
+	}
+	require.NoError(t, r.Start(context.Background(), nil))
+	<-done
+	require.NoError(t, r.Shutdown(context.Background()))
+	require.Equal(t, 3, passes)
+	require.GreaterOrEqual(t, shift.get(), 24*time.Hour)
+}
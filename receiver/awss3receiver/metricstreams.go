@@ -0,0 +1,153 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	conventions "go.opentelemetry.io/collector/semconv/v1.25.0"
+)
+
+const attributeAWSCloudWatchMetricStreamName = "aws.cloudwatch.metric_stream_name"
+
+// metricStreamsJSONMetric is a metric of the JSON output of CloudWatch Metric
+// Streams, see https://docs.aws.amazon.com/AmazonCloudWatch/latest/monitoring/CloudWatch-metric-streams-formats-json.html.
+type metricStreamsJSONMetric struct {
+	MetricStreamName string             `json:"metric_stream_name"`
+	AccountID        string             `json:"account_id"`
+	Region           string             `json:"region"`
+	Namespace        string             `json:"namespace"`
+	MetricName       string             `json:"metric_name"`
+	Dimensions       map[string]string  `json:"dimensions"`
+	Timestamp        int64              `json:"timestamp"`
+	Value            map[string]float64 `json:"value"`
+	Unit             string             `json:"unit"`
+}
+
+// metricStreamsResource identifies the stream, account, region and namespace of
+// CloudWatch metrics.
+type metricStreamsResource struct {
+	streamName string
+	accountID  string
+	region     string
+	namespace  string
+}
+
+// metricStreamsJSONUnmarshaler unmarshals the JSON output of CloudWatch Metric
+// Streams, concatenated or newline delimited metrics, into a summary metric per
+// CloudWatch metric, with the minimum and maximum as the 0 and 1 quantiles. The
+// metrics are grouped in resources by stream, account, region and namespace.
+type metricStreamsJSONUnmarshaler struct{}
+
+func (metricStreamsJSONUnmarshaler) UnmarshalMetrics(buf []byte) (pmetric.Metrics, error) {
+	metrics := pmetric.NewMetrics()
+	resources := map[metricStreamsResource]pmetric.MetricSlice{}
+	summaries := map[metricStreamsResource]map[string]pmetric.Metric{}
+	err := forEachJSONMessage(buf, func(message []byte) error {
+		var metric metricStreamsJSONMetric
+		if err := json.Unmarshal(message, &metric); err != nil {
+			return err
+		}
+		if metric.MetricName == "" || metric.Value == nil {
+			return errors.New("missing metric_name or value")
+		}
+		resource := metricStreamsResource{
+			streamName: metric.MetricStreamName,
+			accountID:  metric.AccountID,
+			region:     metric.Region,
+			namespace:  metric.Namespace,
+		}
+		metricSlice, ok := resources[resource]
+		if !ok {
+			resourceMetrics := metrics.ResourceMetrics().AppendEmpty()
+			setMetricStreamsResource(resourceMetrics.Resource(), resource)
+			metricSlice = resourceMetrics.ScopeMetrics().AppendEmpty().Metrics()
+			resources[resource] = metricSlice
+			summaries[resource] = map[string]pmetric.Metric{}
+		}
+		summary, ok := summaries[resource][metric.MetricName+"\x00"+metric.Unit]
+		if !ok {
+			summary = metricSlice.AppendEmpty()
+			summary.SetName(metric.MetricName)
+			summary.SetUnit(metric.Unit)
+			summary.SetEmptySummary()
+			summaries[resource][metric.MetricName+"\x00"+metric.Unit] = summary
+		}
+		setMetricStreamsDataPoint(summary.Summary().DataPoints().AppendEmpty(), metric)
+		return nil
+	})
+	return metrics, err
+}
+
+// setMetricStreamsResource sets the attributes of the resource of the metrics of a
+// stream, the namespace of the AWS services, AWS/<service>, setting both the
+// service.namespace and service.name attributes.
+func setMetricStreamsResource(resource pcommon.Resource, r metricStreamsResource) {
+	attributes := resource.Attributes()
+	attributes.PutStr(conventions.AttributeCloudProvider, conventions.AttributeCloudProviderAWS)
+	attributes.PutStr(conventions.AttributeCloudAccountID, r.accountID)
+	attributes.PutStr(conventions.AttributeCloudRegion, r.region)
+	if namespace, name, ok := strings.Cut(r.namespace, "/"); ok && strings.EqualFold(namespace, conventions.AttributeCloudProviderAWS) {
+		attributes.PutStr(conventions.AttributeServiceNamespace, namespace)
+		attributes.PutStr(conventions.AttributeServiceName, name)
+	} else {
+		attributes.PutStr(conventions.AttributeServiceName, r.namespace)
+	}
+	attributes.PutStr(attributeAWSCloudWatchMetricStreamName, r.streamName)
+}
+
+func setMetricStreamsDataPoint(dataPoint pmetric.SummaryDataPoint, metric metricStreamsJSONMetric) {
+	dataPoint.SetTimestamp(pcommon.NewTimestampFromTime(time.UnixMilli(metric.Timestamp)))
+	dataPoint.SetCount(uint64(metric.Value["count"]))
+	dataPoint.SetSum(metric.Value["sum"])
+	minimum := dataPoint.QuantileValues().AppendEmpty()
+	minimum.SetQuantile(0)
+	minimum.SetValue(metric.Value["min"])
+	maximum := dataPoint.QuantileValues().AppendEmpty()
+	maximum.SetQuantile(1)
+	maximum.SetValue(metric.Value["max"])
+	names := make([]string, 0, len(metric.Dimensions))
+	for name := range metric.Dimensions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		attribute := name
+		if name == "InstanceId" {
+			attribute = conventions.AttributeServiceInstanceID
+		}
+		dataPoint.Attributes().PutStr(attribute, metric.Dimensions[name])
+	}
+}
+
+// metricStreamsOTLPUnmarshaler unmarshals the OpenTelemetry 1.0 output of
+// CloudWatch Metric Streams, OTLP protobuf export requests each preceded by its
+// size as a varint.
+type metricStreamsOTLPUnmarshaler struct{}
+
+func (metricStreamsOTLPUnmarshaler) UnmarshalMetrics(buf []byte) (pmetric.Metrics, error) {
+	metrics := pmetric.NewMetrics()
+	unmarshaler := &pmetric.ProtoUnmarshaler{}
+	for index := 0; len(buf) > 0; index++ {
+		size, n := binary.Uvarint(buf)
+		if n <= 0 || uint64(len(buf)-n) < size {
+			return metrics, fmt.Errorf("unable to read OTLP message %d: invalid size", index)
+		}
+		messageMetrics, err := unmarshaler.UnmarshalMetrics(buf[n : n+int(size)])
+		if err != nil {
+			return metrics, fmt.Errorf("unable to unmarshal OTLP message %d: %w", index, err)
+		}
+		messageMetrics.ResourceMetrics().MoveAndAppendTo(metrics.ResourceMetrics())
+		buf = buf[n+int(size):]
+	}
+	return metrics, nil
+}
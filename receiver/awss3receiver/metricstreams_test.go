@@ -0,0 +1,70 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+func Test_metricStreamsJSONUnmarshaler(t *testing.T) {
+	data := []byte(`{"metric_stream_name":"stream","account_id":"123456789012","region":"us-east-1","namespace":"AWS/EC2","metric_name":"CPUUtilization","dimensions":{"InstanceId":"i-0123"},"timestamp":1612200720000,"value":{"max":40,"min":10,"sum":100,"count":4},"unit":"Percent"}
+{"metric_stream_name":"stream","account_id":"123456789012","region":"us-east-1","namespace":"AWS/EC2","metric_name":"CPUUtilization","dimensions":{"InstanceId":"i-4567"},"timestamp":1612200720000,"value":{"max":5,"min":1,"sum":6,"count":2},"unit":"Percent"}{"metric_stream_name":"stream","account_id":"123456789012","region":"us-east-1","namespace":"Custom","metric_name":"Requests","dimensions":{},"timestamp":1612200780000,"value":{"max":1,"min":1,"sum":3,"count":3},"unit":"Count"}
+`)
+	metrics, err := metricStreamsJSONUnmarshaler{}.UnmarshalMetrics(data)
+	require.NoError(t, err)
+	require.Equal(t, 2, metrics.ResourceMetrics().Len())
+	require.Equal(t, map[string]any{
+		"cloud.provider":                    "aws",
+		"cloud.account.id":                  "123456789012",
+		"cloud.region":                      "us-east-1",
+		"service.namespace":                 "AWS",
+		"service.name":                      "EC2",
+		"aws.cloudwatch.metric_stream_name": "stream",
+	}, metrics.ResourceMetrics().At(0).Resource().Attributes().AsRaw())
+	require.Equal(t, "Custom", metrics.ResourceMetrics().At(1).Resource().Attributes().AsRaw()["service.name"])
+
+	metricSlice := metrics.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics()
+	require.Equal(t, 1, metricSlice.Len())
+	metric := metricSlice.At(0)
+	require.Equal(t, "CPUUtilization", metric.Name())
+	require.Equal(t, "Percent", metric.Unit())
+	require.Equal(t, pmetric.MetricTypeSummary, metric.Type())
+	require.Equal(t, 2, metric.Summary().DataPoints().Len())
+	dataPoint := metric.Summary().DataPoints().At(0)
+	require.Equal(t, time.Date(2021, 2, 1, 17, 32, 0, 0, time.UTC), dataPoint.Timestamp().AsTime())
+	require.Equal(t, uint64(4), dataPoint.Count())
+	require.Equal(t, 100.0, dataPoint.Sum())
+	require.Equal(t, 10.0, dataPoint.QuantileValues().At(0).Value())
+	require.Equal(t, 1.0, dataPoint.QuantileValues().At(1).Quantile())
+	require.Equal(t, 40.0, dataPoint.QuantileValues().At(1).Value())
+	require.Equal(t, map[string]any{"service.instance.id": "i-0123"}, dataPoint.Attributes().AsRaw())
+
+	_, err = metricStreamsJSONUnmarshaler{}.UnmarshalMetrics([]byte(`{"metric_stream_name":"stream"}`))
+	require.EqualError(t, err, "unable to unmarshal JSON message 0: missing metric_name or value")
+}
+
+func Test_metricStreamsOTLPUnmarshaler(t *testing.T) {
+	var data []byte
+	for _, name := range []string{"first", "second"} {
+		metrics := pmetric.NewMetrics()
+		metrics.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics().AppendEmpty().SetName(name)
+		message, err := (&pmetric.ProtoMarshaler{}).MarshalMetrics(metrics)
+		require.NoError(t, err)
+		data = binary.AppendUvarint(data, uint64(len(message)))
+		data = append(data, message...)
+	}
+
+	metrics, err := metricStreamsOTLPUnmarshaler{}.UnmarshalMetrics(data)
+	require.NoError(t, err)
+	require.Equal(t, 2, metrics.ResourceMetrics().Len())
+	require.Equal(t, "second", metrics.ResourceMetrics().At(1).ScopeMetrics().At(0).Metrics().At(0).Name())
+
+	_, err = metricStreamsOTLPUnmarshaler{}.UnmarshalMetrics(data[:len(data)-1])
+	require.EqualError(t, err, "unable to read OTLP message 1: invalid size")
+}
@@ -0,0 +1,322 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/open-telemetry/opamp-go/client/types"
+	"github.com/open-telemetry/opamp-go/protobufs"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/opampextension"
+)
+
+const (
+	ingestStatusIngesting = "ingesting"
+	ingestStatusCompleted = "completed"
+	ingestStatusFailed    = "failed"
+	ingestStatusStopped   = "stopped"
+	ingestStatusPaused    = "paused"
+	ingestStatusCancelled = "cancelled"
+	ingestStatusIdle      = "idle"
+)
+
+// customCapability is the OpAMP custom capability the status notifications are sent with.
+const customCapability = "io.opentelemetry.collector.receiver.awss3"
+
+// statusMessageType is the type of the OpAMP custom messages holding a status notification.
+const statusMessageType = "TimeBasedIngestStatus"
+
+// commandMessageType is the type of the OpAMP custom messages holding a command
+// to the ingest.
+const commandMessageType = "TimeBasedIngestCommand"
+
+// objectFailureMessageType is the type of the OpAMP custom messages holding the
+// failure of an object.
+const objectFailureMessageType = "TimeBasedIngestObjectFailure"
+
+const maxNotificationAttempts = 3
+
+// statusNotification is the status of the ingest of a telemetry type.
+type statusNotification struct {
+	TelemetryType  string
+	IngestStatus   string
+	StartTime      time.Time
+	EndTime        time.Time
+	FailureMessage string
+	// ResumeToken, if set, is the starttime resuming the ingest stopped on shutdown.
+	ResumeToken string
+	// ObjectsProcessed, BytesDownloaded and Failures are the number of objects
+	// processed, of bytes read from them and of objects which failed to be
+	// processed since the receiver started.
+	ObjectsProcessed int64
+	BytesDownloaded  int64
+	Failures         int64
+	// PercentComplete, if set, is the percentage of the time range of the current
+	// pass read in full. It is not known in continuous mode, nor for the objects
+	// of a manifest or of SQS notifications.
+	PercentComplete *float64
+	// ETA, if set, is the estimated time the current pass completes at.
+	ETA time.Time
+	// Command and CommandID, if set, are the command the notification
+	// acknowledges, along with the error of the command if it was rejected.
+	Command      string
+	CommandID    string
+	CommandError string
+	// JobID, if set, is the ID of the job submitted by the OpAMP server whose time
+	// range is being read.
+	JobID string
+	// LastProgress, if set, is the time the last object was processed or failed
+	// at.
+	LastProgress time.Time
+	// Heartbeat is set on the notifications sent on the heartbeat interval.
+	Heartbeat bool
+}
+
+// statusNotifier sends the status of the ingest to a backend.
+type statusNotifier interface {
+	Start(ctx context.Context, host component.Host) error
+	Shutdown(ctx context.Context) error
+	SendStatus(ctx context.Context, notification statusNotification)
+	SendObjectFailure(ctx context.Context, failure objectFailure)
+}
+
+// opampNotifier sends the status notifications as OpAMP custom messages, through
+// the custom capability registry of an OpAMP extension, and hands the commands
+// received from the OpAMP server to the command handler. The notifications and
+// commands are encoded as a protobuf log record whose attributes hold the status
+// or the command.
+type opampNotifier struct {
+	logger     *zap.Logger
+	opampExtID component.ID
+	handler    opampextension.CustomCapabilityHandler
+	commands   commandHandler
+	// stop is closed on shutdown, for the commands to no longer be received, and
+	// stopped once they no longer are.
+	stop    chan struct{}
+	stopped chan struct{}
+}
+
+func newNotifier(cfg *Config, logger *zap.Logger, commands commandHandler) statusNotifier {
+	if cfg.Notifications.OpAMP == nil {
+		return nil
+	}
+	return &opampNotifier{logger: logger, opampExtID: *cfg.Notifications.OpAMP, commands: commands}
+}
+
+func (n *opampNotifier) Start(_ context.Context, host component.Host) error {
+	ext, ok := host.GetExtensions()[n.opampExtID]
+	if !ok {
+		return fmt.Errorf("extension %v not found", n.opampExtID)
+	}
+	registry, ok := ext.(opampextension.CustomCapabilityRegistry)
+	if !ok {
+		return fmt.Errorf("extension %v is not a custom capability registry", n.opampExtID)
+	}
+	handler, err := registry.Register(customCapability)
+	if err != nil {
+		return err
+	}
+	n.handler = handler
+	if n.commands != nil {
+		n.stop, n.stopped = make(chan struct{}), make(chan struct{})
+		go n.receiveCommands(handler.Message())
+	}
+	return nil
+}
+
+func (n *opampNotifier) Shutdown(_ context.Context) error {
+	if n.stop != nil {
+		close(n.stop)
+		<-n.stopped
+		n.stop = nil
+	}
+	if n.handler != nil {
+		n.handler.Unregister()
+	}
+	return nil
+}
+
+// receiveCommands hands the commands among messages to the command handler until
+// shutdown, which cancels the context of the commands being handled, or until
+// messages is closed.
+func (n *opampNotifier) receiveCommands(messages <-chan *protobufs.CustomMessage) {
+	defer close(n.stopped)
+	stop := n.stop
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-stop:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	for {
+		select {
+		case <-stop:
+			return
+		case message, ok := <-messages:
+			if !ok {
+				// The extension no longer hands the messages of the OpAMP server.
+				return
+			}
+			if message.Type != commandMessageType {
+				continue
+			}
+			command, err := commandFromLogs(message.Data)
+			if err != nil {
+				n.logger.Warn("Failed to decode the ingest command", zap.Error(err))
+				continue
+			}
+			n.commands(ctx, command)
+		}
+	}
+}
+
+func (n *opampNotifier) SendStatus(ctx context.Context, notification statusNotification) {
+	n.send(ctx, statusMessageType, "status notification", notificationToLogs(notification))
+}
+
+func (n *opampNotifier) SendObjectFailure(ctx context.Context, failure objectFailure) {
+	n.send(ctx, objectFailureMessageType, "object failure notification", objectFailureToLogs(failure))
+}
+
+// send sends logs as a custom message of the given type, described as name in
+// the error logs, waiting for the previous message to be sent unless ctx is done
+// first.
+func (n *opampNotifier) send(ctx context.Context, messageType, name string, logs plog.Logs) {
+	data, err := (&plog.ProtoMarshaler{}).MarshalLogs(logs)
+	if err != nil {
+		n.logger.Error("Failed to marshal the "+name, zap.Error(err))
+		return
+	}
+	for attempt := 0; attempt < maxNotificationAttempts; attempt++ {
+		sendingChannel, err := n.handler.SendMessage(messageType, data)
+		switch {
+		case err == nil:
+			return
+		case errors.Is(err, types.ErrCustomMessagePending):
+			select {
+			case <-sendingChannel:
+			case <-ctx.Done():
+				n.logger.Error("Failed to send the "+name+", a previous message is still pending", zap.Error(ctx.Err()))
+				return
+			}
+		default:
+			n.logger.Error("Failed to send the "+name, zap.Error(err))
+			return
+		}
+	}
+	n.logger.Error("Failed to send the " + name + ", a previous message is still pending")
+}
+
+func notificationToLogs(notification statusNotification) plog.Logs {
+	logs := plog.NewLogs()
+	record := logs.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords().AppendEmpty()
+	record.SetTimestamp(pcommon.NewTimestampFromTime(time.Now()))
+	record.Body().SetStr("status")
+	attributes := record.Attributes()
+	attributes.PutStr("telemetry_type", notification.TelemetryType)
+	attributes.PutStr("ingest_status", notification.IngestStatus)
+	if !notification.StartTime.IsZero() {
+		attributes.PutInt("start_time", notification.StartTime.UnixNano())
+	}
+	if !notification.EndTime.IsZero() {
+		attributes.PutInt("end_time", notification.EndTime.UnixNano())
+	}
+	if notification.FailureMessage != "" {
+		attributes.PutStr("failure_message", notification.FailureMessage)
+	}
+	if notification.ResumeToken != "" {
+		attributes.PutStr("resume_token", notification.ResumeToken)
+	}
+	attributes.PutInt("objects_processed", notification.ObjectsProcessed)
+	attributes.PutInt("bytes_downloaded", notification.BytesDownloaded)
+	attributes.PutInt("failures", notification.Failures)
+	if notification.PercentComplete != nil {
+		attributes.PutDouble("percent_complete", *notification.PercentComplete)
+	}
+	if !notification.ETA.IsZero() {
+		attributes.PutInt("eta", notification.ETA.UnixNano())
+	}
+	if notification.Command != "" {
+		attributes.PutStr("command", notification.Command)
+	}
+	if notification.CommandID != "" {
+		attributes.PutStr("command_id", notification.CommandID)
+	}
+	if notification.CommandError != "" {
+		attributes.PutStr("command_error", notification.CommandError)
+	}
+	if notification.JobID != "" {
+		attributes.PutStr("job_id", notification.JobID)
+	}
+	if !notification.LastProgress.IsZero() {
+		attributes.PutInt("last_progress", notification.LastProgress.UnixNano())
+	}
+	if notification.Heartbeat {
+		attributes.PutBool("heartbeat", true)
+	}
+	return logs
+}
+
+// commandFromLogs decodes the command held by the attributes of the first log
+// record of data.
+func commandFromLogs(data []byte) (ingestCommand, error) {
+	logs, err := (&plog.ProtoUnmarshaler{}).UnmarshalLogs(data)
+	if err != nil {
+		return ingestCommand{}, err
+	}
+	record, ok := firstLogRecord(logs)
+	if !ok {
+		return ingestCommand{}, errors.New("the command holds no log record")
+	}
+	attributes := record.Attributes()
+	attribute := func(name string) string {
+		if value, ok := attributes.Get(name); ok {
+			return value.AsString()
+		}
+		return ""
+	}
+	command := ingestCommand{
+		Command:   attribute("command"),
+		CommandID: attribute("command_id"),
+		StartTime: attribute("starttime"),
+		EndTime:   attribute("endtime"),
+	}
+	// The telemetry types are either a single one or a list of them.
+	if value, ok := attributes.Get("telemetry_type"); ok {
+		if value.Type() == pcommon.ValueTypeSlice {
+			for i := 0; i < value.Slice().Len(); i++ {
+				command.TelemetryTypes = append(command.TelemetryTypes, value.Slice().At(i).AsString())
+			}
+		} else {
+			command.TelemetryTypes = []string{value.AsString()}
+		}
+	}
+	if command.Command == "" {
+		return ingestCommand{}, errors.New("the command attribute is required")
+	}
+	return command, nil
+}
+
+func firstLogRecord(logs plog.Logs) (plog.LogRecord, bool) {
+	for i := 0; i < logs.ResourceLogs().Len(); i++ {
+		scopeLogs := logs.ResourceLogs().At(i).ScopeLogs()
+		for j := 0; j < scopeLogs.Len(); j++ {
+			if records := scopeLogs.At(j).LogRecords(); records.Len() > 0 {
+				return records.At(0), true
+			}
+		}
+	}
+	return plog.LogRecord{}, false
+}
@@ -0,0 +1,271 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/open-telemetry/opamp-go/client/types"
+	"github.com/open-telemetry/opamp-go/protobufs"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/extension/opampextension"
+)
+
+type mockCustomCapabilityHandler struct {
+	messages     [][]byte
+	messageTypes []string
+	pending      int
+	// stuck is set for the pending messages to never be sent.
+	stuck        bool
+	sendErr      error
+	unregistered bool
+	// received are the messages of the OpAMP server.
+	received chan *protobufs.CustomMessage
+}
+
+func (h *mockCustomCapabilityHandler) Message() <-chan *protobufs.CustomMessage {
+	return h.received
+}
+
+func (h *mockCustomCapabilityHandler) SendMessage(messageType string, message []byte) (chan struct{}, error) {
+	sent := make(chan struct{})
+	close(sent)
+	if h.sendErr != nil {
+		return nil, h.sendErr
+	}
+	if h.stuck {
+		return make(chan struct{}), types.ErrCustomMessagePending
+	}
+	if h.pending > 0 {
+		h.pending--
+		return sent, types.ErrCustomMessagePending
+	}
+	if messageType != statusMessageType && messageType != objectFailureMessageType {
+		return nil, errors.New("unexpected message type")
+	}
+	h.messages = append(h.messages, message)
+	h.messageTypes = append(h.messageTypes, messageType)
+	return sent, nil
+}
+
+func (h *mockCustomCapabilityHandler) Unregister() {
+	h.unregistered = true
+}
+
+type mockOpAMPExtension struct {
+	component.StartFunc
+	component.ShutdownFunc
+	handler *mockCustomCapabilityHandler
+}
+
+func (e *mockOpAMPExtension) Register(capability string, _ ...opampextension.CustomCapabilityRegisterOption) (opampextension.CustomCapabilityHandler, error) {
+	if capability != customCapability {
+		return nil, errors.New("unexpected capability")
+	}
+	return e.handler, nil
+}
+
+type mockHost struct {
+	component.Host
+	extensions map[component.ID]component.Component
+}
+
+func (h mockHost) GetExtensions() map[component.ID]component.Component {
+	return h.extensions
+}
+
+func Test_opampNotifier_Start(t *testing.T) {
+	opampID := component.MustNewID("opamp")
+	notifier := &opampNotifier{logger: zap.NewNop(), opampExtID: opampID}
+
+	require.EqualError(t, notifier.Start(context.Background(), mockHost{Host: componenttest.NewNopHost()}), "extension opamp not found")
+
+	host := mockHost{extensions: map[component.ID]component.Component{opampID: struct {
+		component.StartFunc
+		component.ShutdownFunc
+	}{}}}
+	require.EqualError(t, notifier.Start(context.Background(), host), "extension opamp is not a custom capability registry")
+
+	handler := &mockCustomCapabilityHandler{}
+	host = mockHost{extensions: map[component.ID]component.Component{opampID: &mockOpAMPExtension{handler: handler}}}
+	require.NoError(t, notifier.Start(context.Background(), host))
+	require.NoError(t, notifier.Shutdown(context.Background()))
+	require.True(t, handler.unregistered)
+}
+
+func Test_opampNotifier_SendStatus(t *testing.T) {
+	handler := &mockCustomCapabilityHandler{pending: 1}
+	notifier := &opampNotifier{logger: zap.NewNop(), handler: handler}
+	percentComplete := 37.5
+	notifier.SendStatus(context.Background(), statusNotification{
+		TelemetryType:    "traces",
+		IngestStatus:     ingestStatusFailed,
+		StartTime:        testTime,
+		EndTime:          testTime.Add(time.Hour),
+		FailureMessage:   "failure",
+		ObjectsProcessed: 3,
+		BytesDownloaded:  1024,
+		Failures:         1,
+		PercentComplete:  &percentComplete,
+		ETA:              testTime.Add(time.Minute),
+	})
+	require.Len(t, handler.messages, 1)
+
+	logs, err := (&plog.ProtoUnmarshaler{}).UnmarshalLogs(handler.messages[0])
+	require.NoError(t, err)
+	record := logs.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0)
+	require.Equal(t, "status", record.Body().Str())
+	require.Equal(t, map[string]any{
+		"telemetry_type":    "traces",
+		"ingest_status":     "failed",
+		"start_time":        testTime.UnixNano(),
+		"end_time":          testTime.Add(time.Hour).UnixNano(),
+		"failure_message":   "failure",
+		"objects_processed": int64(3),
+		"bytes_downloaded":  int64(1024),
+		"failures":          int64(1),
+		"percent_complete":  37.5,
+		"eta":               testTime.Add(time.Minute).UnixNano(),
+	}, record.Attributes().AsRaw())
+
+	handler.pending = maxNotificationAttempts
+	notifier.SendStatus(context.Background(), statusNotification{TelemetryType: "traces", IngestStatus: ingestStatusIngesting})
+	require.Len(t, handler.messages, 1)
+
+	// The notifications are given up once ctx is done, if the OpAMP server no
+	// longer acknowledges the messages.
+	handler.stuck = true
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	notifier.SendStatus(ctx, statusNotification{TelemetryType: "traces", IngestStatus: ingestStatusIngesting})
+	notifier.SendObjectFailure(ctx, objectFailure{TelemetryType: "traces", Key: "key"})
+	require.Len(t, handler.messages, 1)
+}
+
+// commandMessage returns the custom message holding a command with the given
+// attributes.
+func commandMessage(t *testing.T, attributes map[string]any) *protobufs.CustomMessage {
+	logs := plog.NewLogs()
+	require.NoError(t, logs.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords().AppendEmpty().Attributes().FromRaw(attributes))
+	data, err := (&plog.ProtoMarshaler{}).MarshalLogs(logs)
+	require.NoError(t, err)
+	return &protobufs.CustomMessage{Capability: customCapability, Type: commandMessageType, Data: data}
+}
+
+func Test_opampNotifier_Commands(t *testing.T) {
+	opampID := component.MustNewID("opamp")
+	handler := &mockCustomCapabilityHandler{received: make(chan *protobufs.CustomMessage)}
+	commands := make(chan ingestCommand)
+	notifier := &opampNotifier{logger: zap.NewNop(), opampExtID: opampID, commands: func(_ context.Context, command ingestCommand) {
+		commands <- command
+	}}
+	host := mockHost{extensions: map[component.ID]component.Component{opampID: &mockOpAMPExtension{handler: handler}}}
+	require.NoError(t, notifier.Start(context.Background(), host))
+
+	// The messages of other types, and the commands which cannot be decoded, are
+	// ignored.
+	handler.received <- &protobufs.CustomMessage{Capability: customCapability, Type: statusMessageType}
+	handler.received <- &protobufs.CustomMessage{Capability: customCapability, Type: commandMessageType, Data: []byte("pause")}
+	handler.received <- commandMessage(t, map[string]any{"telemetry_type": "traces"})
+	handler.received <- commandMessage(t, map[string]any{"command": "pause", "telemetry_type": "traces", "command_id": "42"})
+	require.Equal(t, ingestCommand{Command: commandPause, TelemetryTypes: []string{"traces"}, CommandID: "42"}, <-commands)
+	require.NoError(t, notifier.Shutdown(context.Background()))
+	require.True(t, handler.unregistered)
+}
+
+func Test_opampNotifier_Commands_Closed(t *testing.T) {
+	opampID := component.MustNewID("opamp")
+	handler := &mockCustomCapabilityHandler{received: make(chan *protobufs.CustomMessage)}
+	notifier := &opampNotifier{logger: zap.NewNop(), opampExtID: opampID, commands: func(context.Context, ingestCommand) {
+		t.Fail()
+	}}
+	host := mockHost{extensions: map[component.ID]component.Component{opampID: &mockOpAMPExtension{handler: handler}}}
+	require.NoError(t, notifier.Start(context.Background(), host))
+
+	// The commands are no longer received once the extension closes the channel.
+	close(handler.received)
+	<-notifier.stopped
+	require.NoError(t, notifier.Shutdown(context.Background()))
+}
+
+func Test_opampNotifier_Commands_ShutdownWhilePending(t *testing.T) {
+	opampID := component.MustNewID("opamp")
+	handler := &mockCustomCapabilityHandler{received: make(chan *protobufs.CustomMessage), stuck: true}
+	handling := make(chan struct{})
+	notifier := &opampNotifier{logger: zap.NewNop(), opampExtID: opampID}
+	notifier.commands = func(ctx context.Context, _ ingestCommand) {
+		close(handling)
+		notifier.SendStatus(ctx, statusNotification{TelemetryType: "traces", IngestStatus: ingestStatusIdle})
+	}
+	host := mockHost{extensions: map[component.ID]component.Component{opampID: &mockOpAMPExtension{handler: handler}}}
+	require.NoError(t, notifier.Start(context.Background(), host))
+
+	// The acknowledgment the OpAMP server never accepts does not hold up the
+	// shutdown.
+	handler.received <- commandMessage(t, map[string]any{"command": "pause"})
+	<-handling
+	require.NoError(t, notifier.Shutdown(context.Background()))
+	require.Empty(t, handler.messages)
+}
+
+func Test_commandFromLogs(t *testing.T) {
+	command, err := commandFromLogs(commandMessage(t, map[string]any{"command": "cancel"}).Data)
+	require.NoError(t, err)
+	require.Equal(t, ingestCommand{Command: commandCancel}, command)
+
+	command, err = commandFromLogs(commandMessage(t, map[string]any{
+		"command":        "submit",
+		"telemetry_type": []any{"traces", "logs"},
+		"starttime":      "2024-01-01",
+		"endtime":        "2024-01-02",
+	}).Data)
+	require.NoError(t, err)
+	require.Equal(t, ingestCommand{Command: commandSubmit, TelemetryTypes: []string{"traces", "logs"}, StartTime: "2024-01-01", EndTime: "2024-01-02"}, command)
+
+	_, err = commandFromLogs(commandMessage(t, map[string]any{"command_id": "42"}).Data)
+	require.EqualError(t, err, "the command attribute is required")
+
+	data, err := (&plog.ProtoMarshaler{}).MarshalLogs(plog.NewLogs())
+	require.NoError(t, err)
+	_, err = commandFromLogs(data)
+	require.EqualError(t, err, "the command holds no log record")
+}
+
+func Test_notificationToLogs_Command(t *testing.T) {
+	logs := notificationToLogs(statusNotification{
+		TelemetryType: "logs",
+		IngestStatus:  ingestStatusCompleted,
+		Command:       commandPause,
+		CommandID:     "42",
+		CommandError:  "the objects are not being read",
+		JobID:         "7",
+	})
+	attributes := logs.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0).Attributes().AsRaw()
+	require.Equal(t, "pause", attributes["command"])
+	require.Equal(t, "42", attributes["command_id"])
+	require.Equal(t, "the objects are not being read", attributes["command_error"])
+	require.Equal(t, "7", attributes["job_id"])
+	require.NotContains(t, attributes, "heartbeat")
+	require.NotContains(t, attributes, "last_progress")
+}
+
+func Test_notificationToLogs_Heartbeat(t *testing.T) {
+	logs := notificationToLogs(statusNotification{
+		TelemetryType: "logs",
+		IngestStatus:  ingestStatusIdle,
+		LastProgress:  testTime,
+		Heartbeat:     true,
+	})
+	attributes := logs.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0).Attributes().AsRaw()
+	require.Equal(t, true, attributes["heartbeat"])
+	require.Equal(t, testTime.UnixNano(), attributes["last_progress"])
+}
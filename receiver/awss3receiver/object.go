@@ -0,0 +1,75 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// objectInfo describes the object whose contents are processed, for the decoders
+// recording where the telemetry comes from.
+type objectInfo struct {
+	bucket       string
+	key          string
+	lastModified time.Time
+	// etag is the entity tag of the object, changing along with its contents.
+	etag string
+	// throttled is set when S3 throttled an attempt of the retrieval of the object.
+	throttled bool
+	// ranged is set when the object was retrieved in ranges.
+	ranged bool
+	// selected is set when the contents are the records of the object selected
+	// with S3 Select, which are not compressed whatever the key of the object.
+	selected bool
+	// member is the name of the file of the archive whose contents are processed,
+	// if the object is an archive.
+	member string
+}
+
+type objectInfoKey struct{}
+
+// contextWithObjectInfo returns a context carrying the description of the object
+// whose contents are handed to the data callback.
+func contextWithObjectInfo(ctx context.Context, info objectInfo) context.Context {
+	return context.WithValue(ctx, objectInfoKey{}, info)
+}
+
+// objectInfoFromContext returns the description of the object carried by ctx, with
+// only the key set if ctx does not carry one.
+func objectInfoFromContext(ctx context.Context, key string) objectInfo {
+	if info, ok := ctx.Value(objectInfoKey{}).(objectInfo); ok {
+		return info
+	}
+	return objectInfo{key: key}
+}
+
+// getObject retrieves the contents of an object along with its description.
+func getObject(ctx context.Context, client GetObjectAPI, params *s3.GetObjectInput) ([]byte, objectInfo, error) {
+	body, info, err := openObject(ctx, client, params)
+	if err != nil {
+		return nil, info, err
+	}
+	defer body.Close()
+	data, err := io.ReadAll(body)
+	return data, info, err
+}
+
+// openObject retrieves an object along with its description, its contents being
+// read from the returned body as they are received. The body must be closed.
+func openObject(ctx context.Context, client GetObjectAPI, params *s3.GetObjectInput) (io.ReadCloser, objectInfo, error) {
+	info := objectInfo{bucket: aws.ToString(params.Bucket), key: aws.ToString(params.Key)}
+	output, err := client.GetObject(ctx, params)
+	if err != nil {
+		return nil, info, err
+	}
+	info.lastModified = aws.ToTime(output.LastModified)
+	info.etag = aws.ToString(output.ETag)
+	info.throttled = isThrottled(output.ResultMetadata)
+	return output.Body, info, nil
+}
@@ -0,0 +1,32 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_getObject(t *testing.T) {
+	client := mockGetObjectAPI(func(_ context.Context, _ *s3.GetObjectInput, _ ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
+		return &s3.GetObjectOutput{
+			Body:         io.NopCloser(bytes.NewReader([]byte("contents"))),
+			LastModified: aws.Time(testTime),
+		}, nil
+	})
+	data, info, err := getObject(context.Background(), client, &s3.GetObjectInput{Bucket: aws.String("bucket"), Key: aws.String("key")})
+	require.NoError(t, err)
+	require.Equal(t, "contents", string(data))
+	require.Equal(t, objectInfo{bucket: "bucket", key: "key", lastModified: testTime}, info)
+
+	ctx := contextWithObjectInfo(context.Background(), info)
+	require.Equal(t, info, objectInfoFromContext(ctx, "other"))
+	require.Equal(t, objectInfo{key: "other"}, objectInfoFromContext(context.Background(), "other"))
+}
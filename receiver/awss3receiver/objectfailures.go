@@ -0,0 +1,176 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"golang.org/x/time/rate"
+)
+
+// The classes of the errors of the objects which failed to be processed.
+const (
+	// failureClassRetrieval is the class of the errors of the read of the body of
+	// the objects from S3.
+	failureClassRetrieval = "retrieval"
+	// failureClassDecode is the class of the errors of the decompression and the
+	// decoding of the contents of the objects.
+	failureClassDecode = "decode"
+	// failureClassConsumer is the class of the errors of the next consumer
+	// refusing the telemetry of the objects.
+	failureClassConsumer = "consumer"
+)
+
+const (
+	defaultObjectFailuresPerSecond = 1
+	defaultObjectFailuresBurst     = 10
+)
+
+// objectFailure is the failure of the processing of an object.
+type objectFailure struct {
+	TelemetryType string
+	Bucket        string
+	Key           string
+	ErrorClass    string
+	ErrorMessage  string
+	// JobID, if set, is the ID of the job whose time range holds the object.
+	JobID string
+	// Suppressed is the number of failures not notified since the previous one,
+	// beyond the rate of the notifications.
+	Suppressed int64
+}
+
+// consumerError is the error of the next consumer refusing the telemetry of an
+// object.
+type consumerError struct {
+	err error
+}
+
+func (e *consumerError) Error() string {
+	return e.err.Error()
+}
+
+func (e *consumerError) Unwrap() error {
+	return e.err
+}
+
+// retrievalError is the error of the read of the body of an object.
+type retrievalError struct {
+	err error
+}
+
+func (e *retrievalError) Error() string {
+	return e.err.Error()
+}
+
+func (e *retrievalError) Unwrap() error {
+	return e.err
+}
+
+// failureClass returns the class of the error of the processing of an object.
+func failureClass(err error) string {
+	var consumerErr *consumerError
+	if errors.As(err, &consumerErr) {
+		return failureClassConsumer
+	}
+	var retrievalErr *retrievalError
+	if errors.As(err, &retrievalErr) {
+		return failureClassRetrieval
+	}
+	return failureClassDecode
+}
+
+// consumerErrorTraces returns the consumer marking the errors of next as
+// consumer errors.
+func consumerErrorTraces(next consumer.Traces) (consumer.Traces, error) {
+	return consumer.NewTraces(func(ctx context.Context, td ptrace.Traces) error {
+		if err := next.ConsumeTraces(ctx, td); err != nil {
+			return &consumerError{err: err}
+		}
+		return nil
+	}, consumer.WithCapabilities(next.Capabilities()))
+}
+
+// consumerErrorMetrics returns the consumer marking the errors of next as
+// consumer errors.
+func consumerErrorMetrics(next consumer.Metrics) (consumer.Metrics, error) {
+	return consumer.NewMetrics(func(ctx context.Context, md pmetric.Metrics) error {
+		if err := next.ConsumeMetrics(ctx, md); err != nil {
+			return &consumerError{err: err}
+		}
+		return nil
+	}, consumer.WithCapabilities(next.Capabilities()))
+}
+
+// consumerErrorLogs returns the consumer marking the errors of next as consumer
+// errors.
+func consumerErrorLogs(next consumer.Logs) (consumer.Logs, error) {
+	return consumer.NewLogs(func(ctx context.Context, ld plog.Logs) error {
+		if err := next.ConsumeLogs(ctx, ld); err != nil {
+			return &consumerError{err: err}
+		}
+		return nil
+	}, consumer.WithCapabilities(next.Capabilities()))
+}
+
+// objectFailures limits the rate of the notifications of the objects which failed
+// to be processed, counting the failures not notified.
+type objectFailures struct {
+	limiter    *rate.Limiter
+	suppressed atomic.Int64
+}
+
+func newObjectFailures(cfg *ObjectFailuresConfig) *objectFailures {
+	if cfg == nil {
+		return nil
+	}
+	perSecond, burst := cfg.PerSecond, cfg.Burst
+	if perSecond == 0 {
+		perSecond = defaultObjectFailuresPerSecond
+	}
+	if burst == 0 {
+		burst = defaultObjectFailuresBurst
+	}
+	return &objectFailures{limiter: rate.NewLimiter(rate.Limit(perSecond), burst)}
+}
+
+// allow reports whether a failure can be notified, along with the number of
+// failures suppressed since the previous one.
+func (f *objectFailures) allow(now time.Time) (int64, bool) {
+	if !f.limiter.AllowN(now, 1) {
+		f.suppressed.Add(1)
+		return 0, false
+	}
+	return f.suppressed.Swap(0), true
+}
+
+func objectFailureToLogs(failure objectFailure) plog.Logs {
+	logs := plog.NewLogs()
+	record := logs.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords().AppendEmpty()
+	record.SetTimestamp(pcommon.NewTimestampFromTime(time.Now()))
+	record.Body().SetStr("object_failure")
+	attributes := record.Attributes()
+	attributes.PutStr("telemetry_type", failure.TelemetryType)
+	if failure.Bucket != "" {
+		attributes.PutStr("bucket", failure.Bucket)
+	}
+	attributes.PutStr("key", failure.Key)
+	attributes.PutStr("error_class", failure.ErrorClass)
+	attributes.PutStr("error_message", failure.ErrorMessage)
+	if failure.JobID != "" {
+		attributes.PutStr("job_id", failure.JobID)
+	}
+	if failure.Suppressed > 0 {
+		attributes.PutInt("suppressed_failures", failure.Suppressed)
+	}
+	return logs
+}
@@ -0,0 +1,94 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.uber.org/zap"
+)
+
+type failingReader struct {
+	err error
+}
+
+func (r failingReader) Read([]byte) (int, error) {
+	return 0, r.err
+}
+
+func Test_failureClass(t *testing.T) {
+	traces, err := consumerErrorTraces(consumertest.NewErr(errors.New("refused")))
+	require.NoError(t, err)
+	consumeErr := traces.ConsumeTraces(context.Background(), ptrace.NewTraces())
+	require.EqualError(t, consumeErr, "refused")
+	require.Equal(t, failureClassConsumer, failureClass(fmt.Errorf("bucket bucket: %w", consumeErr)))
+
+	var progress ingestProgress
+	_, readErr := io.ReadAll(progress.countBytes(failingReader{err: errors.New("connection reset by peer")}))
+	require.EqualError(t, readErr, "connection reset by peer")
+	require.Equal(t, failureClassRetrieval, failureClass(readErr))
+
+	require.Equal(t, failureClassDecode, failureClass(errors.New("unable to decode")))
+}
+
+func Test_objectFailures(t *testing.T) {
+	require.Nil(t, newObjectFailures(nil))
+	failures := newObjectFailures(&ObjectFailuresConfig{})
+	require.Equal(t, 10, failures.limiter.Burst())
+
+	failures = newObjectFailures(&ObjectFailuresConfig{PerSecond: 1, Burst: 2})
+	now := testTime
+	for i := 0; i < 2; i++ {
+		suppressed, ok := failures.allow(now)
+		require.True(t, ok)
+		require.Zero(t, suppressed)
+	}
+	_, ok := failures.allow(now)
+	require.False(t, ok)
+	_, ok = failures.allow(now)
+	require.False(t, ok)
+	// The next failure notified tells the number of failures suppressed since
+	// the previous one.
+	suppressed, ok := failures.allow(now.Add(time.Second))
+	require.True(t, ok)
+	require.Equal(t, int64(2), suppressed)
+}
+
+func Test_opampNotifier_SendObjectFailure(t *testing.T) {
+	handler := &mockCustomCapabilityHandler{}
+	notifier := &opampNotifier{logger: zap.NewNop(), handler: handler}
+	notifier.SendObjectFailure(context.Background(), objectFailure{
+		TelemetryType: "logs",
+		Bucket:        "bucket",
+		Key:           "year=2021/month=02/day=01/hour=17/minute=32/logs_1.json.gz",
+		ErrorClass:    failureClassDecode,
+		ErrorMessage:  "gzip: invalid header",
+		JobID:         "7",
+		Suppressed:    3,
+	})
+	require.Equal(t, []string{objectFailureMessageType}, handler.messageTypes)
+
+	logs, err := (&plog.ProtoUnmarshaler{}).UnmarshalLogs(handler.messages[0])
+	require.NoError(t, err)
+	record := logs.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0)
+	require.Equal(t, "object_failure", record.Body().Str())
+	require.Equal(t, map[string]any{
+		"telemetry_type":      "logs",
+		"bucket":              "bucket",
+		"key":                 "year=2021/month=02/day=01/hour=17/minute=32/logs_1.json.gz",
+		"error_class":         "decode",
+		"error_message":       "gzip: invalid header",
+		"job_id":              "7",
+		"suppressed_failures": int64(3),
+	}, record.Attributes().AsRaw())
+}
@@ -0,0 +1,113 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"time"
+
+	"github.com/apache/arrow/go/v15/arrow"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	conventions "go.opentelemetry.io/collector/semconv/v1.25.0"
+)
+
+// ocsfAttributes are the attributes of the OCSF event fields, by path, set on the
+// records when the events have them.
+var ocsfAttributes = []struct {
+	path      []string
+	attribute string
+}{
+	{[]string{"class_uid"}, "ocsf.class_uid"},
+	{[]string{"class_name"}, "ocsf.class_name"},
+	{[]string{"category_uid"}, "ocsf.category_uid"},
+	{[]string{"category_name"}, "ocsf.category_name"},
+	{[]string{"activity_id"}, "ocsf.activity_id"},
+	{[]string{"activity_name"}, "ocsf.activity_name"},
+	{[]string{"type_uid"}, "ocsf.type_uid"},
+	{[]string{"type_name"}, "ocsf.type_name"},
+	{[]string{"status"}, "ocsf.status"},
+	{[]string{"metadata", "product", "name"}, "ocsf.metadata.product.name"},
+	{[]string{"metadata", "version"}, "ocsf.metadata.version"},
+	{[]string{"src_endpoint", "ip"}, conventions.AttributeSourceAddress},
+	{[]string{"src_endpoint", "port"}, conventions.AttributeSourcePort},
+	{[]string{"dst_endpoint", "ip"}, conventions.AttributeDestinationAddress},
+	{[]string{"dst_endpoint", "port"}, conventions.AttributeDestinationPort},
+	{[]string{"actor", "user", "uid"}, conventions.AttributeEnduserID},
+	{[]string{"api", "service", "name"}, conventions.AttributeRPCService},
+	{[]string{"api", "operation"}, conventions.AttributeRPCMethod},
+	{[]string{"api", "request", "uid"}, conventions.AttributeAWSRequestID},
+	{[]string{"http_request", "http_method"}, conventions.AttributeHTTPRequestMethod},
+	{[]string{"http_request", "url", "url_string"}, conventions.AttributeURLFull},
+	{[]string{"http_request", "user_agent"}, conventions.AttributeUserAgentOriginal},
+	{[]string{"http_response", "code"}, conventions.AttributeHTTPResponseStatusCode},
+	{[]string{"query", "hostname"}, conventions.AttributeDNSQuestionName},
+}
+
+// ocsfSeverities are the severity numbers of the OCSF severity IDs.
+var ocsfSeverities = map[int64]plog.SeverityNumber{
+	1: plog.SeverityNumberInfo,
+	2: plog.SeverityNumberInfo4,
+	3: plog.SeverityNumberWarn,
+	4: plog.SeverityNumberError,
+	5: plog.SeverityNumberError4,
+	6: plog.SeverityNumberFatal,
+}
+
+// ocsfDecoder decodes the Apache Parquet objects of Amazon Security Lake, holding
+// events of the Open Cybersecurity Schema Framework, into a log record per event.
+// The records are grouped by the cloud account and region of their events.
+type ocsfDecoder struct{}
+
+func (ocsfDecoder) decodeLogs(info objectInfo, data []byte) (plog.Logs, error) {
+	logs := newCloudResourceLogs()
+	err := forEachParquetRecord(data, func(rec arrow.Record) {
+		for i := 0; i < int(rec.NumRows()); i++ {
+			event := make(map[string]any, rec.NumCols())
+			for c, column := range rec.Columns() {
+				if !column.IsNull(i) {
+					event[rec.ColumnName(c)] = arrowRawValue(column, i)
+				}
+			}
+			accountID, _ := nestedField(event, "cloud", "account", "uid").(string)
+			region, _ := nestedField(event, "cloud", "region").(string)
+			setOCSFRecord(appendObjectRecord(logs.records(accountID, region), info), event)
+		}
+	})
+	return logs.logs, err
+}
+
+// setOCSFRecord sets the body, attributes, severity and timestamp of a record from
+// an OCSF event, the body being the whole event.
+func setOCSFRecord(record plog.LogRecord, event map[string]any) {
+	for _, field := range ocsfAttributes {
+		if value := nestedField(event, field.path...); value != nil {
+			_ = record.Attributes().PutEmpty(field.attribute).FromRaw(value)
+		}
+	}
+	if severityID, ok := event["severity_id"].(int64); ok {
+		record.SetSeverityNumber(ocsfSeverities[severityID])
+	}
+	if severity, ok := event["severity"].(string); ok {
+		record.SetSeverityText(severity)
+	}
+	// The OCSF times are milliseconds since the epoch.
+	if eventTime, ok := event["time"].(int64); ok {
+		record.SetTimestamp(pcommon.NewTimestampFromTime(time.UnixMilli(eventTime)))
+	}
+	_ = record.Body().FromRaw(event)
+}
+
+// nestedField returns the value of a field of nested documents, or nil if the
+// field is missing.
+func nestedField(document map[string]any, path ...string) any {
+	var value any = document
+	for _, name := range path {
+		fields, ok := value.(map[string]any)
+		if !ok {
+			return nil
+		}
+		value = fields[name]
+	}
+	return value
+}
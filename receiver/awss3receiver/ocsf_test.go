@@ -0,0 +1,119 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow/go/v15/arrow"
+	"github.com/apache/arrow/go/v15/arrow/array"
+	"github.com/apache/arrow/go/v15/arrow/memory"
+	"github.com/apache/arrow/go/v15/parquet/pqarrow"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/plog"
+)
+
+func Test_ocsfDecoder(t *testing.T) {
+	accountType := arrow.StructOf(arrow.Field{Name: "uid", Type: arrow.BinaryTypes.String, Nullable: true})
+	cloudType := arrow.StructOf(
+		arrow.Field{Name: "account", Type: accountType, Nullable: true},
+		arrow.Field{Name: "region", Type: arrow.BinaryTypes.String, Nullable: true},
+	)
+	endpointType := arrow.StructOf(
+		arrow.Field{Name: "ip", Type: arrow.BinaryTypes.String, Nullable: true},
+		arrow.Field{Name: "port", Type: arrow.PrimitiveTypes.Int32, Nullable: true},
+	)
+	schema := arrow.NewSchema([]arrow.Field{
+		{Name: "time", Type: arrow.PrimitiveTypes.Int64},
+		{Name: "class_uid", Type: arrow.PrimitiveTypes.Int32},
+		{Name: "class_name", Type: arrow.BinaryTypes.String, Nullable: true},
+		{Name: "severity_id", Type: arrow.PrimitiveTypes.Int32, Nullable: true},
+		{Name: "severity", Type: arrow.BinaryTypes.String, Nullable: true},
+		{Name: "cloud", Type: cloudType, Nullable: true},
+		{Name: "src_endpoint", Type: endpointType, Nullable: true},
+		{Name: "resources", Type: arrow.ListOf(arrow.BinaryTypes.String), Nullable: true},
+	}, nil)
+	builder := array.NewRecordBuilder(memory.DefaultAllocator, schema)
+	defer builder.Release()
+	builder.Field(0).(*array.Int64Builder).AppendValues([]int64{1612198800000, 1612198860000}, nil)
+	builder.Field(1).(*array.Int32Builder).AppendValues([]int32{4001, 3002}, nil)
+	builder.Field(2).(*array.StringBuilder).AppendValues([]string{"Network Activity", "Authentication"}, nil)
+	builder.Field(3).(*array.Int32Builder).AppendValues([]int32{1, 4}, nil)
+	builder.Field(4).(*array.StringBuilder).AppendValues([]string{"Informational", "High"}, nil)
+
+	cloud := builder.Field(5).(*array.StructBuilder)
+	account := cloud.FieldBuilder(0).(*array.StructBuilder)
+	region := cloud.FieldBuilder(1).(*array.StringBuilder)
+	for _, r := range []string{"us-east-1", "eu-west-1"} {
+		cloud.Append(true)
+		account.Append(true)
+		account.FieldBuilder(0).(*array.StringBuilder).Append("123456789012")
+		region.Append(r)
+	}
+
+	endpoint := builder.Field(6).(*array.StructBuilder)
+	endpoint.Append(true)
+	endpoint.FieldBuilder(0).(*array.StringBuilder).Append("172.31.16.139")
+	endpoint.FieldBuilder(1).(*array.Int32Builder).Append(20641)
+	endpoint.AppendNull()
+
+	resources := builder.Field(7).(*array.ListBuilder)
+	resources.Append(true)
+	resources.ValueBuilder().(*array.StringBuilder).AppendValues([]string{"i-0123456789abcdef0"}, nil)
+	resources.AppendNull()
+
+	record := builder.NewRecord()
+	defer record.Release()
+	table := array.NewTableFromRecords(schema, []arrow.Record{record})
+	defer table.Release()
+	var buf bytes.Buffer
+	require.NoError(t, pqarrow.WriteTable(table, &buf, 1024, nil, pqarrow.DefaultWriterProps()))
+
+	info := objectInfo{bucket: "aws-security-data-lake", key: "ext/VPC_FLOW/1.0/region=us-east-1/accountId=123456789012/eventDay=20210201/part.gz.parquet"}
+	logs, err := ocsfDecoder{}.decodeLogs(info, buf.Bytes())
+	require.NoError(t, err)
+	require.Equal(t, 2, logs.ResourceLogs().Len())
+
+	resource := logs.ResourceLogs().At(0)
+	require.Equal(t, map[string]any{
+		"cloud.provider":   "aws",
+		"cloud.account.id": "123456789012",
+		"cloud.region":     "us-east-1",
+	}, resource.Resource().Attributes().AsRaw())
+	first := resource.ScopeLogs().At(0).LogRecords().At(0)
+	require.Equal(t, time.Date(2021, 2, 1, 17, 0, 0, 0, time.UTC), first.Timestamp().AsTime())
+	require.Equal(t, plog.SeverityNumberInfo, first.SeverityNumber())
+	require.Equal(t, "Informational", first.SeverityText())
+	require.Equal(t, map[string]any{
+		"aws.s3.bucket":   info.bucket,
+		"aws.s3.key":      info.key,
+		"ocsf.class_uid":  int64(4001),
+		"ocsf.class_name": "Network Activity",
+		"source.address":  "172.31.16.139",
+		"source.port":     int64(20641),
+	}, first.Attributes().AsRaw())
+	require.Equal(t, map[string]any{
+		"time":        int64(1612198800000),
+		"class_uid":   int64(4001),
+		"class_name":  "Network Activity",
+		"severity_id": int64(1),
+		"severity":    "Informational",
+		"cloud": map[string]any{
+			"account": map[string]any{"uid": "123456789012"},
+			"region":  "us-east-1",
+		},
+		"src_endpoint": map[string]any{"ip": "172.31.16.139", "port": int64(20641)},
+		"resources":    []any{"i-0123456789abcdef0"},
+	}, first.Body().Map().AsRaw())
+
+	second := logs.ResourceLogs().At(1).ScopeLogs().At(0).LogRecords().At(0)
+	require.Equal(t, "eu-west-1", logs.ResourceLogs().At(1).Resource().Attributes().AsRaw()["cloud.region"])
+	require.Equal(t, plog.SeverityNumberError, second.SeverityNumber())
+	require.Equal(t, "Authentication", second.Attributes().AsRaw()["ocsf.class_name"])
+
+	_, err = ocsfDecoder{}.decodeLogs(info, []byte("not parquet"))
+	require.Error(t, err)
+}
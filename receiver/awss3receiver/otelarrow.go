@@ -0,0 +1,88 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	arrowpb "github.com/open-telemetry/otel-arrow/api/experimental/arrow/v1"
+	arrowRecord "github.com/open-telemetry/otel-arrow/pkg/otel/arrow_record"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.uber.org/multierr"
+	"google.golang.org/protobuf/proto"
+)
+
+// forEachOTelArrowBatch calls fn with each of the OpenTelemetry Arrow batches of
+// data, BatchArrowRecords protobuf messages each preceded by its size as a varint.
+// The batches of an object are a stream, decoded by a single consumer since their
+// Arrow schemas and dictionaries are only sent once.
+func forEachOTelArrowBatch(data []byte, fn func(consumer *arrowRecord.Consumer, batch *arrowpb.BatchArrowRecords) error) (err error) {
+	consumer := arrowRecord.NewConsumer()
+	defer func() {
+		err = multierr.Append(err, consumer.Close())
+	}()
+	for index := 0; len(data) > 0; index++ {
+		size, n := binary.Uvarint(data)
+		if n <= 0 || uint64(len(data)-n) < size {
+			return fmt.Errorf("unable to read OpenTelemetry Arrow batch %d: invalid size", index)
+		}
+		batch := &arrowpb.BatchArrowRecords{}
+		if err := proto.Unmarshal(data[n:n+int(size)], batch); err != nil {
+			return fmt.Errorf("unable to unmarshal OpenTelemetry Arrow batch %d: %w", index, err)
+		}
+		if err := fn(consumer, batch); err != nil {
+			return fmt.Errorf("unable to decode OpenTelemetry Arrow batch %d: %w", index, err)
+		}
+		data = data[n+int(size):]
+	}
+	return nil
+}
+
+// otelArrowTracesUnmarshaler unmarshals a stream of OpenTelemetry Arrow trace batches.
+type otelArrowTracesUnmarshaler struct{}
+
+func (otelArrowTracesUnmarshaler) UnmarshalTraces(buf []byte) (ptrace.Traces, error) {
+	traces := ptrace.NewTraces()
+	err := forEachOTelArrowBatch(buf, func(consumer *arrowRecord.Consumer, batch *arrowpb.BatchArrowRecords) error {
+		batchTraces, err := consumer.TracesFrom(batch)
+		for _, t := range batchTraces {
+			t.ResourceSpans().MoveAndAppendTo(traces.ResourceSpans())
+		}
+		return err
+	})
+	return traces, err
+}
+
+// otelArrowMetricsUnmarshaler unmarshals a stream of OpenTelemetry Arrow metric batches.
+type otelArrowMetricsUnmarshaler struct{}
+
+func (otelArrowMetricsUnmarshaler) UnmarshalMetrics(buf []byte) (pmetric.Metrics, error) {
+	metrics := pmetric.NewMetrics()
+	err := forEachOTelArrowBatch(buf, func(consumer *arrowRecord.Consumer, batch *arrowpb.BatchArrowRecords) error {
+		batchMetrics, err := consumer.MetricsFrom(batch)
+		for _, m := range batchMetrics {
+			m.ResourceMetrics().MoveAndAppendTo(metrics.ResourceMetrics())
+		}
+		return err
+	})
+	return metrics, err
+}
+
+// otelArrowLogsUnmarshaler unmarshals a stream of OpenTelemetry Arrow log batches.
+type otelArrowLogsUnmarshaler struct{}
+
+func (otelArrowLogsUnmarshaler) UnmarshalLogs(buf []byte) (plog.Logs, error) {
+	logs := plog.NewLogs()
+	err := forEachOTelArrowBatch(buf, func(consumer *arrowRecord.Consumer, batch *arrowpb.BatchArrowRecords) error {
+		batchLogs, err := consumer.LogsFrom(batch)
+		for _, l := range batchLogs {
+			l.ResourceLogs().MoveAndAppendTo(logs.ResourceLogs())
+		}
+		return err
+	})
+	return logs, err
+}
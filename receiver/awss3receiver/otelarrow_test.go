@@ -0,0 +1,87 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"encoding/binary"
+	"testing"
+
+	arrowpb "github.com/open-telemetry/otel-arrow/api/experimental/arrow/v1"
+	arrowRecord "github.com/open-telemetry/otel-arrow/pkg/otel/arrow_record"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"google.golang.org/protobuf/proto"
+)
+
+func appendOTelArrowBatch(t *testing.T, buf []byte, batch *arrowpb.BatchArrowRecords, err error) []byte {
+	require.NoError(t, err)
+	data, err := proto.Marshal(batch)
+	require.NoError(t, err)
+	buf = binary.AppendUvarint(buf, uint64(len(data)))
+	return append(buf, data...)
+}
+
+func Test_otelArrowTracesUnmarshaler(t *testing.T) {
+	producer := arrowRecord.NewProducer()
+	defer producer.Close()
+	var buf []byte
+	for _, name := range []string{"GET /cart", "POST /checkout"} {
+		traces := ptrace.NewTraces()
+		resourceSpans := traces.ResourceSpans().AppendEmpty()
+		resourceSpans.Resource().Attributes().PutStr("service.name", "checkout")
+		span := resourceSpans.ScopeSpans().AppendEmpty().Spans().AppendEmpty()
+		span.SetName(name)
+		span.SetTraceID([16]byte{1})
+		span.SetSpanID([8]byte{2})
+		batch, err := producer.BatchArrowRecordsFromTraces(traces)
+		buf = appendOTelArrowBatch(t, buf, batch, err)
+	}
+
+	traces, err := otelArrowTracesUnmarshaler{}.UnmarshalTraces(buf)
+	require.NoError(t, err)
+	require.Equal(t, 2, traces.ResourceSpans().Len())
+	require.Equal(t, "checkout", traces.ResourceSpans().At(0).Resource().Attributes().AsRaw()["service.name"])
+	require.Equal(t, "GET /cart", traces.ResourceSpans().At(0).ScopeSpans().At(0).Spans().At(0).Name())
+	require.Equal(t, "POST /checkout", traces.ResourceSpans().At(1).ScopeSpans().At(0).Spans().At(0).Name())
+
+	_, err = otelArrowTracesUnmarshaler{}.UnmarshalTraces(buf[:len(buf)-1])
+	require.EqualError(t, err, "unable to read OpenTelemetry Arrow batch 1: invalid size")
+}
+
+func Test_otelArrowMetricsUnmarshaler(t *testing.T) {
+	producer := arrowRecord.NewProducer()
+	defer producer.Close()
+	metrics := pmetric.NewMetrics()
+	metric := metrics.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics().AppendEmpty()
+	metric.SetName("requests")
+	metric.SetEmptyGauge().DataPoints().AppendEmpty().SetIntValue(42)
+	batch, err := producer.BatchArrowRecordsFromMetrics(metrics)
+	buf := appendOTelArrowBatch(t, nil, batch, err)
+
+	unmarshaled, err := otelArrowMetricsUnmarshaler{}.UnmarshalMetrics(buf)
+	require.NoError(t, err)
+	require.Equal(t, 1, unmarshaled.DataPointCount())
+	metric = unmarshaled.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(0)
+	require.Equal(t, "requests", metric.Name())
+	require.Equal(t, int64(42), metric.Gauge().DataPoints().At(0).IntValue())
+}
+
+func Test_otelArrowLogsUnmarshaler(t *testing.T) {
+	producer := arrowRecord.NewProducer()
+	defer producer.Close()
+	logs := plog.NewLogs()
+	logs.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords().AppendEmpty().Body().SetStr("order placed")
+	batch, err := producer.BatchArrowRecordsFromLogs(logs)
+	buf := appendOTelArrowBatch(t, nil, batch, err)
+
+	unmarshaled, err := otelArrowLogsUnmarshaler{}.UnmarshalLogs(buf)
+	require.NoError(t, err)
+	require.Equal(t, 1, unmarshaled.LogRecordCount())
+	require.Equal(t, "order placed", unmarshaled.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0).Body().Str())
+
+	_, err = otelArrowLogsUnmarshaler{}.UnmarshalLogs([]byte{3, 1, 2, 3})
+	require.ErrorContains(t, err, "OpenTelemetry Arrow batch 0")
+}
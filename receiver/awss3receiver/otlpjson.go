@@ -0,0 +1,88 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+)
+
+// forEachJSONMessage calls fn with each of the JSON messages of data, which holds
+// either a single message or several newline delimited ones.
+func forEachJSONMessage(data []byte, fn func(message []byte) error) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	for index := 0; ; index++ {
+		var message json.RawMessage
+		if err := decoder.Decode(&message); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return fmt.Errorf("unable to read JSON message %d: %w", index, err)
+		}
+		if err := fn(message); err != nil {
+			return fmt.Errorf("unable to unmarshal JSON message %d: %w", index, err)
+		}
+	}
+}
+
+// otlpJSONTracesUnmarshaler unmarshals one or several newline delimited OTLP JSON
+// trace messages.
+type otlpJSONTracesUnmarshaler struct{}
+
+func (otlpJSONTracesUnmarshaler) UnmarshalTraces(buf []byte) (ptrace.Traces, error) {
+	traces := ptrace.NewTraces()
+	unmarshaler := &ptrace.JSONUnmarshaler{}
+	err := forEachJSONMessage(buf, func(message []byte) error {
+		messageTraces, err := unmarshaler.UnmarshalTraces(message)
+		if err != nil {
+			return err
+		}
+		messageTraces.ResourceSpans().MoveAndAppendTo(traces.ResourceSpans())
+		return nil
+	})
+	return traces, err
+}
+
+// otlpJSONLogsUnmarshaler unmarshals one or several newline delimited OTLP JSON
+// log messages.
+type otlpJSONLogsUnmarshaler struct{}
+
+func (otlpJSONLogsUnmarshaler) UnmarshalLogs(buf []byte) (plog.Logs, error) {
+	logs := plog.NewLogs()
+	unmarshaler := &plog.JSONUnmarshaler{}
+	err := forEachJSONMessage(buf, func(message []byte) error {
+		messageLogs, err := unmarshaler.UnmarshalLogs(message)
+		if err != nil {
+			return err
+		}
+		messageLogs.ResourceLogs().MoveAndAppendTo(logs.ResourceLogs())
+		return nil
+	})
+	return logs, err
+}
+
+// otlpJSONMetricsUnmarshaler unmarshals one or several newline delimited OTLP JSON
+// metric messages.
+type otlpJSONMetricsUnmarshaler struct{}
+
+func (otlpJSONMetricsUnmarshaler) UnmarshalMetrics(buf []byte) (pmetric.Metrics, error) {
+	metrics := pmetric.NewMetrics()
+	unmarshaler := &pmetric.JSONUnmarshaler{}
+	err := forEachJSONMessage(buf, func(message []byte) error {
+		messageMetrics, err := unmarshaler.UnmarshalMetrics(message)
+		if err != nil {
+			return err
+		}
+		messageMetrics.ResourceMetrics().MoveAndAppendTo(metrics.ResourceMetrics())
+		return nil
+	})
+	return metrics, err
+}
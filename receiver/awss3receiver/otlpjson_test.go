@@ -0,0 +1,57 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.uber.org/zap"
+)
+
+func Test_otlpJSONUnmarshalers(t *testing.T) {
+	jsonTraces, err := (&ptrace.JSONMarshaler{}).MarshalTraces(generateTraceData())
+	require.NoError(t, err)
+	jsonLogs, err := (&plog.JSONMarshaler{}).MarshalLogs(generateLogsData())
+	require.NoError(t, err)
+	jsonMetrics, err := (&pmetric.JSONMarshaler{}).MarshalMetrics(generateMetricsData())
+	require.NoError(t, err)
+
+	traces, err := otlpJSONTracesUnmarshaler{}.UnmarshalTraces(jsonTraces)
+	require.NoError(t, err)
+	require.Equal(t, generateTraceData(), traces)
+
+	traces, err = otlpJSONTracesUnmarshaler{}.UnmarshalTraces(bytes.Join([][]byte{jsonTraces, jsonTraces, nil}, []byte("\n")))
+	require.NoError(t, err)
+	require.Equal(t, 2, traces.SpanCount())
+
+	logs, err := otlpJSONLogsUnmarshaler{}.UnmarshalLogs(bytes.Join([][]byte{jsonLogs, jsonLogs}, []byte("\n")))
+	require.NoError(t, err)
+	require.Equal(t, 2, logs.LogRecordCount())
+
+	metrics, err := otlpJSONMetricsUnmarshaler{}.UnmarshalMetrics(bytes.Join([][]byte{jsonMetrics, jsonMetrics}, []byte("\n")))
+	require.NoError(t, err)
+	require.Equal(t, 2, metrics.DataPointCount())
+
+	_, err = otlpJSONLogsUnmarshaler{}.UnmarshalLogs(append(jsonLogs, []byte("\n{")...))
+	require.ErrorContains(t, err, "unable to read JSON message 1")
+}
+
+func Test_receiveObject_Format(t *testing.T) {
+	jsonLogs, err := (&plog.JSONMarshaler{}).MarshalLogs(generateLogsData())
+	require.NoError(t, err)
+	sink := &consumertest.LogsSink{}
+	r := &awss3Receiver{
+		dataProcessor: newLogsProcessor(sink, LogsConfig{SignalConfig: SignalConfig{Format: FormatOTLPJSON}}, zap.NewNop()),
+		logger:        zap.NewNop(),
+	}
+	require.NoError(t, r.receiveObject(context.Background(), "logs_1.log.gz", bytes.NewReader(gzipCompress(jsonLogs))))
+	require.Equal(t, 1, sink.LogRecordCount())
+}
@@ -0,0 +1,312 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/apache/arrow/go/v15/arrow"
+	"github.com/apache/arrow/go/v15/arrow/array"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+// otlpParquetRow is a row of an Apache Parquet file holding OTLP data flattened to
+// a row per log record or data point, whose columns are looked up by name.
+type otlpParquetRow struct {
+	rec arrow.Record
+	i   int
+}
+
+// column returns the column of the given name, or nil if the file has no such
+// column or the row no value for it.
+func (r otlpParquetRow) column(name string) arrow.Array {
+	column := recordColumn(r.rec, name)
+	if column == nil || column.IsNull(r.i) {
+		return nil
+	}
+	return column
+}
+
+func (r otlpParquetRow) value(name string) any {
+	if column := r.column(name); column != nil {
+		return arrowRawValue(column, r.i)
+	}
+	return nil
+}
+
+func (r otlpParquetRow) str(name string) string {
+	value, _ := r.value(name).(string)
+	return value
+}
+
+func (r otlpParquetRow) int(name string) int64 {
+	value, _ := r.value(name).(int64)
+	return value
+}
+
+func (r otlpParquetRow) float(name string) (float64, bool) {
+	switch value := r.value(name).(type) {
+	case float64:
+		return value, true
+	case int64:
+		return float64(value), true
+	default:
+		return 0, false
+	}
+}
+
+// timestamp returns the timestamp of a column holding either Arrow timestamps or
+// nanoseconds since the epoch.
+func (r otlpParquetRow) timestamp(name string) pcommon.Timestamp {
+	column := r.column(name)
+	if ts, ok := column.(*array.Timestamp); ok {
+		return pcommon.NewTimestampFromTime(ts.Value(r.i).ToTime(ts.DataType().(*arrow.TimestampType).Unit))
+	}
+	return pcommon.Timestamp(r.int(name))
+}
+
+// id returns the bytes of a column holding either binary or hexadecimal IDs.
+func (r otlpParquetRow) id(name string) []byte {
+	switch column := r.column(name).(type) {
+	case *array.Binary:
+		return column.Value(r.i)
+	case *array.FixedSizeBinary:
+		return column.Value(r.i)
+	case *array.String:
+		id, _ := hex.DecodeString(column.Value(r.i))
+		return id
+	default:
+		return nil
+	}
+}
+
+// attributes sets attrs from a column holding either maps, structs or JSON
+// objects.
+func (r otlpParquetRow) attributes(name string, attrs pcommon.Map) error {
+	value := r.value(name)
+	if document, ok := value.(string); ok {
+		if err := json.Unmarshal([]byte(document), &value); err != nil {
+			return fmt.Errorf("invalid %s: %w", name, err)
+		}
+	}
+	if fields, ok := value.(map[string]any); ok {
+		return attrs.FromRaw(fields)
+	}
+	return nil
+}
+
+// groupKey returns the key grouping the rows with the same values of the given
+// columns.
+func (r otlpParquetRow) groupKey(names ...string) string {
+	values := make([]any, 0, len(names))
+	for _, name := range names {
+		values = append(values, r.value(name))
+	}
+	key, _ := json.Marshal(values)
+	return string(key)
+}
+
+// otlpParquetLogsUnmarshaler unmarshals Apache Parquet files holding a log record
+// per row. The records are grouped in resources and scopes by the values of their
+// resource and scope columns.
+type otlpParquetLogsUnmarshaler struct{}
+
+func (otlpParquetLogsUnmarshaler) UnmarshalLogs(buf []byte) (plog.Logs, error) {
+	logs := plog.NewLogs()
+	resources := map[string]plog.ResourceLogs{}
+	scopes := map[string]plog.ScopeLogs{}
+	var rowErr error
+	err := forEachParquetRecord(buf, func(rec arrow.Record) {
+		for i := 0; i < int(rec.NumRows()) && rowErr == nil; i++ {
+			row := otlpParquetRow{rec: rec, i: i}
+			resourceKey := row.groupKey("resource_attributes", "resource_schema_url")
+			resourceLogs, ok := resources[resourceKey]
+			if !ok {
+				resourceLogs = logs.ResourceLogs().AppendEmpty()
+				resourceLogs.SetSchemaUrl(row.str("resource_schema_url"))
+				if rowErr = row.attributes("resource_attributes", resourceLogs.Resource().Attributes()); rowErr != nil {
+					return
+				}
+				resources[resourceKey] = resourceLogs
+			}
+			scopeKey := resourceKey + row.groupKey("scope_name", "scope_version", "scope_attributes", "scope_schema_url")
+			scopeLogs, ok := scopes[scopeKey]
+			if !ok {
+				scopeLogs = resourceLogs.ScopeLogs().AppendEmpty()
+				scopeLogs.SetSchemaUrl(row.str("scope_schema_url"))
+				scopeLogs.Scope().SetName(row.str("scope_name"))
+				scopeLogs.Scope().SetVersion(row.str("scope_version"))
+				if rowErr = row.attributes("scope_attributes", scopeLogs.Scope().Attributes()); rowErr != nil {
+					return
+				}
+				scopes[scopeKey] = scopeLogs
+			}
+			rowErr = setOTLPParquetLogRecord(row, scopeLogs.LogRecords().AppendEmpty())
+		}
+	})
+	if err == nil {
+		err = rowErr
+	}
+	return logs, err
+}
+
+func setOTLPParquetLogRecord(row otlpParquetRow, record plog.LogRecord) error {
+	record.SetTimestamp(row.timestamp("time_unix_nano"))
+	record.SetObservedTimestamp(row.timestamp("observed_time_unix_nano"))
+	record.SetSeverityNumber(plog.SeverityNumber(row.int("severity_number")))
+	record.SetSeverityText(row.str("severity_text"))
+	record.SetFlags(plog.LogRecordFlags(row.int("flags")))
+	if traceID := row.id("trace_id"); len(traceID) == 16 {
+		record.SetTraceID(pcommon.TraceID(traceID))
+	}
+	if spanID := row.id("span_id"); len(spanID) == 8 {
+		record.SetSpanID(pcommon.SpanID(spanID))
+	}
+	if body := row.value("body"); body != nil {
+		if err := record.Body().FromRaw(body); err != nil {
+			return err
+		}
+	}
+	return row.attributes("attributes", record.Attributes())
+}
+
+// otlpParquetMetricsUnmarshaler unmarshals Apache Parquet files holding a data
+// point per row, of gauge, sum or histogram metrics according to their
+// metric_type. The data points are grouped in resources, scopes and metrics by the
+// values of their resource, scope and metric columns.
+type otlpParquetMetricsUnmarshaler struct{}
+
+func (otlpParquetMetricsUnmarshaler) UnmarshalMetrics(buf []byte) (pmetric.Metrics, error) {
+	metrics := pmetric.NewMetrics()
+	resources := map[string]pmetric.ResourceMetrics{}
+	scopes := map[string]pmetric.ScopeMetrics{}
+	series := map[string]pmetric.Metric{}
+	var rowErr error
+	err := forEachParquetRecord(buf, func(rec arrow.Record) {
+		for i := 0; i < int(rec.NumRows()) && rowErr == nil; i++ {
+			row := otlpParquetRow{rec: rec, i: i}
+			resourceKey := row.groupKey("resource_attributes", "resource_schema_url")
+			resourceMetrics, ok := resources[resourceKey]
+			if !ok {
+				resourceMetrics = metrics.ResourceMetrics().AppendEmpty()
+				resourceMetrics.SetSchemaUrl(row.str("resource_schema_url"))
+				if rowErr = row.attributes("resource_attributes", resourceMetrics.Resource().Attributes()); rowErr != nil {
+					return
+				}
+				resources[resourceKey] = resourceMetrics
+			}
+			scopeKey := resourceKey + row.groupKey("scope_name", "scope_version", "scope_attributes", "scope_schema_url")
+			scopeMetrics, ok := scopes[scopeKey]
+			if !ok {
+				scopeMetrics = resourceMetrics.ScopeMetrics().AppendEmpty()
+				scopeMetrics.SetSchemaUrl(row.str("scope_schema_url"))
+				scopeMetrics.Scope().SetName(row.str("scope_name"))
+				scopeMetrics.Scope().SetVersion(row.str("scope_version"))
+				if rowErr = row.attributes("scope_attributes", scopeMetrics.Scope().Attributes()); rowErr != nil {
+					return
+				}
+				scopes[scopeKey] = scopeMetrics
+			}
+			metricKey := scopeKey + row.groupKey("metric_name", "metric_description", "metric_unit", "metric_type",
+				"aggregation_temporality", "is_monotonic")
+			metric, ok := series[metricKey]
+			if !ok {
+				metric = scopeMetrics.Metrics().AppendEmpty()
+				if rowErr = newOTLPParquetMetric(row, metric); rowErr != nil {
+					return
+				}
+				series[metricKey] = metric
+			}
+			rowErr = appendOTLPParquetDataPoint(row, metric)
+		}
+	})
+	if err == nil {
+		err = rowErr
+	}
+	return metrics, err
+}
+
+// otlpParquetTemporalities are the aggregation temporalities, by name.
+var otlpParquetTemporalities = map[string]pmetric.AggregationTemporality{
+	"delta":      pmetric.AggregationTemporalityDelta,
+	"cumulative": pmetric.AggregationTemporalityCumulative,
+}
+
+func newOTLPParquetMetric(row otlpParquetRow, metric pmetric.Metric) error {
+	metric.SetName(row.str("metric_name"))
+	metric.SetDescription(row.str("metric_description"))
+	metric.SetUnit(row.str("metric_unit"))
+	temporality := pmetric.AggregationTemporality(row.int("aggregation_temporality"))
+	if name := row.str("aggregation_temporality"); name != "" {
+		temporality = otlpParquetTemporalities[strings.ToLower(name)]
+	}
+	switch metricType := strings.ToLower(row.str("metric_type")); metricType {
+	case "", "gauge":
+		metric.SetEmptyGauge()
+	case "sum":
+		sum := metric.SetEmptySum()
+		sum.SetAggregationTemporality(temporality)
+		isMonotonic, _ := row.value("is_monotonic").(bool)
+		sum.SetIsMonotonic(isMonotonic)
+	case "histogram":
+		metric.SetEmptyHistogram().SetAggregationTemporality(temporality)
+	default:
+		return fmt.Errorf("unsupported metric_type %q", metricType)
+	}
+	return nil
+}
+
+func appendOTLPParquetDataPoint(row otlpParquetRow, metric pmetric.Metric) error {
+	var dataPoint pmetric.NumberDataPoint
+	switch metric.Type() {
+	case pmetric.MetricTypeGauge:
+		dataPoint = metric.Gauge().DataPoints().AppendEmpty()
+	case pmetric.MetricTypeSum:
+		dataPoint = metric.Sum().DataPoints().AppendEmpty()
+	case pmetric.MetricTypeHistogram:
+		return appendOTLPParquetHistogramDataPoint(row, metric.Histogram().DataPoints().AppendEmpty())
+	}
+	dataPoint.SetStartTimestamp(row.timestamp("start_time_unix_nano"))
+	dataPoint.SetTimestamp(row.timestamp("time_unix_nano"))
+	dataPoint.SetFlags(pmetric.DataPointFlags(row.int("flags")))
+	switch value := row.value("value").(type) {
+	case int64:
+		dataPoint.SetIntValue(value)
+	case float64:
+		dataPoint.SetDoubleValue(value)
+	}
+	return row.attributes("attributes", dataPoint.Attributes())
+}
+
+func appendOTLPParquetHistogramDataPoint(row otlpParquetRow, dataPoint pmetric.HistogramDataPoint) error {
+	dataPoint.SetStartTimestamp(row.timestamp("start_time_unix_nano"))
+	dataPoint.SetTimestamp(row.timestamp("time_unix_nano"))
+	dataPoint.SetFlags(pmetric.DataPointFlags(row.int("flags")))
+	dataPoint.SetCount(uint64(row.int("count")))
+	if sum, ok := row.float("sum"); ok {
+		dataPoint.SetSum(sum)
+	}
+	if minimum, ok := row.float("min"); ok {
+		dataPoint.SetMin(minimum)
+	}
+	if maximum, ok := row.float("max"); ok {
+		dataPoint.SetMax(maximum)
+	}
+	bucketCounts, _ := row.value("bucket_counts").([]any)
+	for _, count := range bucketCounts {
+		bucketCount, _ := count.(int64)
+		dataPoint.BucketCounts().Append(uint64(bucketCount))
+	}
+	explicitBounds, _ := row.value("explicit_bounds").([]any)
+	for _, bound := range explicitBounds {
+		explicitBound, _ := bound.(float64)
+		dataPoint.ExplicitBounds().Append(explicitBound)
+	}
+	return row.attributes("attributes", dataPoint.Attributes())
+}
@@ -0,0 +1,166 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow/go/v15/arrow"
+	"github.com/apache/arrow/go/v15/arrow/array"
+	"github.com/apache/arrow/go/v15/arrow/memory"
+	"github.com/apache/arrow/go/v15/parquet/pqarrow"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+func writeTestParquet(t *testing.T, builder *array.RecordBuilder) []byte {
+	record := builder.NewRecord()
+	defer record.Release()
+	table := array.NewTableFromRecords(record.Schema(), []arrow.Record{record})
+	defer table.Release()
+	var buf bytes.Buffer
+	require.NoError(t, pqarrow.WriteTable(table, &buf, 1024, nil, pqarrow.DefaultWriterProps()))
+	return buf.Bytes()
+}
+
+func Test_otlpParquetLogsUnmarshaler(t *testing.T) {
+	schema := arrow.NewSchema([]arrow.Field{
+		{Name: "resource_attributes", Type: arrow.BinaryTypes.String, Nullable: true},
+		{Name: "scope_name", Type: arrow.BinaryTypes.String, Nullable: true},
+		{Name: "time_unix_nano", Type: &arrow.TimestampType{Unit: arrow.Nanosecond, TimeZone: "UTC"}, Nullable: true},
+		{Name: "severity_number", Type: arrow.PrimitiveTypes.Int32, Nullable: true},
+		{Name: "severity_text", Type: arrow.BinaryTypes.String, Nullable: true},
+		{Name: "trace_id", Type: arrow.BinaryTypes.String, Nullable: true},
+		{Name: "body", Type: arrow.BinaryTypes.String, Nullable: true},
+		{Name: "attributes", Type: arrow.MapOf(arrow.BinaryTypes.String, arrow.BinaryTypes.String), Nullable: true},
+	}, nil)
+	builder := array.NewRecordBuilder(memory.DefaultAllocator, schema)
+	defer builder.Release()
+	builder.Field(0).(*array.StringBuilder).AppendValues([]string{`{"service.name": "checkout"}`, `{"service.name": "checkout"}`, `{"service.name": "orders"}`}, nil)
+	builder.Field(1).(*array.StringBuilder).AppendValues([]string{"app", "app", "app"}, nil)
+	startTime := time.Date(2021, 2, 1, 17, 0, 0, 123456789, time.UTC)
+	builder.Field(2).(*array.TimestampBuilder).AppendValues([]arrow.Timestamp{arrow.Timestamp(startTime.UnixNano()), arrow.Timestamp(startTime.UnixNano() + 1), 0}, []bool{true, true, false})
+	builder.Field(3).(*array.Int32Builder).AppendValues([]int32{9, 17, 0}, []bool{true, true, false})
+	builder.Field(4).(*array.StringBuilder).AppendValues([]string{"INFO", "ERROR", ""}, []bool{true, true, false})
+	builder.Field(5).(*array.StringBuilder).AppendValues([]string{"5f84c7a1e7d1852db8c4fd35d88bf49a", "", ""}, []bool{true, false, false})
+	builder.Field(6).(*array.StringBuilder).AppendValues([]string{"order placed", "payment failed", "order stored"}, nil)
+	attributes := builder.Field(7).(*array.MapBuilder)
+	attributes.Append(true)
+	attributes.KeyBuilder().(*array.StringBuilder).Append("order.id")
+	attributes.ItemBuilder().(*array.StringBuilder).Append("1234")
+	attributes.AppendNull()
+	attributes.AppendNull()
+
+	logs, err := otlpParquetLogsUnmarshaler{}.UnmarshalLogs(writeTestParquet(t, builder))
+	require.NoError(t, err)
+	require.Equal(t, 2, logs.ResourceLogs().Len())
+	checkout := logs.ResourceLogs().At(0)
+	require.Equal(t, map[string]any{"service.name": "checkout"}, checkout.Resource().Attributes().AsRaw())
+	require.Equal(t, "app", checkout.ScopeLogs().At(0).Scope().Name())
+	records := checkout.ScopeLogs().At(0).LogRecords()
+	require.Equal(t, 2, records.Len())
+	require.Equal(t, startTime, records.At(0).Timestamp().AsTime())
+	require.Equal(t, plog.SeverityNumberInfo, records.At(0).SeverityNumber())
+	require.Equal(t, "INFO", records.At(0).SeverityText())
+	require.Equal(t, pcommon.TraceID([16]byte{0x5f, 0x84, 0xc7, 0xa1, 0xe7, 0xd1, 0x85, 0x2d, 0xb8, 0xc4, 0xfd, 0x35, 0xd8, 0x8b, 0xf4, 0x9a}), records.At(0).TraceID())
+	require.Equal(t, "order placed", records.At(0).Body().Str())
+	require.Equal(t, map[string]any{"order.id": "1234"}, records.At(0).Attributes().AsRaw())
+	require.Equal(t, plog.SeverityNumberError, records.At(1).SeverityNumber())
+	require.True(t, records.At(1).TraceID().IsEmpty())
+	orders := logs.ResourceLogs().At(1)
+	require.Equal(t, map[string]any{"service.name": "orders"}, orders.Resource().Attributes().AsRaw())
+	require.Equal(t, "order stored", orders.ScopeLogs().At(0).LogRecords().At(0).Body().Str())
+
+	schema = arrow.NewSchema([]arrow.Field{{Name: "resource_attributes", Type: arrow.BinaryTypes.String}}, nil)
+	builder = array.NewRecordBuilder(memory.DefaultAllocator, schema)
+	defer builder.Release()
+	builder.Field(0).(*array.StringBuilder).Append("{")
+	_, err = otlpParquetLogsUnmarshaler{}.UnmarshalLogs(writeTestParquet(t, builder))
+	require.ErrorContains(t, err, "invalid resource_attributes")
+
+	_, err = otlpParquetLogsUnmarshaler{}.UnmarshalLogs([]byte("not parquet"))
+	require.Error(t, err)
+}
+
+func Test_otlpParquetMetricsUnmarshaler(t *testing.T) {
+	attributesType := arrow.StructOf(arrow.Field{Name: "host", Type: arrow.BinaryTypes.String, Nullable: true})
+	schema := arrow.NewSchema([]arrow.Field{
+		{Name: "metric_name", Type: arrow.BinaryTypes.String},
+		{Name: "metric_type", Type: arrow.BinaryTypes.String},
+		{Name: "metric_unit", Type: arrow.BinaryTypes.String, Nullable: true},
+		{Name: "aggregation_temporality", Type: arrow.BinaryTypes.String, Nullable: true},
+		{Name: "is_monotonic", Type: arrow.FixedWidthTypes.Boolean, Nullable: true},
+		{Name: "time_unix_nano", Type: arrow.PrimitiveTypes.Int64},
+		{Name: "value", Type: arrow.PrimitiveTypes.Float64, Nullable: true},
+		{Name: "count", Type: arrow.PrimitiveTypes.Uint64, Nullable: true},
+		{Name: "sum", Type: arrow.PrimitiveTypes.Float64, Nullable: true},
+		{Name: "bucket_counts", Type: arrow.ListOf(arrow.PrimitiveTypes.Uint64), Nullable: true},
+		{Name: "explicit_bounds", Type: arrow.ListOf(arrow.PrimitiveTypes.Float64), Nullable: true},
+		{Name: "attributes", Type: attributesType, Nullable: true},
+	}, nil)
+	builder := array.NewRecordBuilder(memory.DefaultAllocator, schema)
+	defer builder.Release()
+	builder.Field(0).(*array.StringBuilder).AppendValues([]string{"requests", "requests", "latency"}, nil)
+	builder.Field(1).(*array.StringBuilder).AppendValues([]string{"sum", "sum", "histogram"}, nil)
+	builder.Field(2).(*array.StringBuilder).AppendValues([]string{"1", "1", "ms"}, nil)
+	builder.Field(3).(*array.StringBuilder).AppendValues([]string{"cumulative", "cumulative", "delta"}, nil)
+	builder.Field(4).(*array.BooleanBuilder).AppendValues([]bool{true, true, false}, []bool{true, true, false})
+	builder.Field(5).(*array.Int64Builder).AppendValues([]int64{1612198800000000000, 1612198860000000000, 1612198860000000000}, nil)
+	builder.Field(6).(*array.Float64Builder).AppendValues([]float64{10, 25, 0}, []bool{true, true, false})
+	builder.Field(7).(*array.Uint64Builder).AppendValues([]uint64{0, 0, 3}, []bool{false, false, true})
+	builder.Field(8).(*array.Float64Builder).AppendValues([]float64{0, 0, 42.5}, []bool{false, false, true})
+	bucketCounts := builder.Field(9).(*array.ListBuilder)
+	bucketCounts.AppendNull()
+	bucketCounts.AppendNull()
+	bucketCounts.Append(true)
+	bucketCounts.ValueBuilder().(*array.Uint64Builder).AppendValues([]uint64{1, 2}, nil)
+	explicitBounds := builder.Field(10).(*array.ListBuilder)
+	explicitBounds.AppendNull()
+	explicitBounds.AppendNull()
+	explicitBounds.Append(true)
+	explicitBounds.ValueBuilder().(*array.Float64Builder).AppendValues([]float64{10}, nil)
+	attributes := builder.Field(11).(*array.StructBuilder)
+	for _, host := range []string{"a", "b", "a"} {
+		attributes.Append(true)
+		attributes.FieldBuilder(0).(*array.StringBuilder).Append(host)
+	}
+
+	metrics, err := otlpParquetMetricsUnmarshaler{}.UnmarshalMetrics(writeTestParquet(t, builder))
+	require.NoError(t, err)
+	require.Equal(t, 1, metrics.ResourceMetrics().Len())
+	series := metrics.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics()
+	require.Equal(t, 2, series.Len())
+
+	requests := series.At(0)
+	require.Equal(t, "requests", requests.Name())
+	require.Equal(t, "1", requests.Unit())
+	require.Equal(t, pmetric.MetricTypeSum, requests.Type())
+	require.Equal(t, pmetric.AggregationTemporalityCumulative, requests.Sum().AggregationTemporality())
+	require.True(t, requests.Sum().IsMonotonic())
+	require.Equal(t, 2, requests.Sum().DataPoints().Len())
+	dataPoint := requests.Sum().DataPoints().At(1)
+	require.Equal(t, 25.0, dataPoint.DoubleValue())
+	require.Equal(t, time.Date(2021, 2, 1, 17, 1, 0, 0, time.UTC), dataPoint.Timestamp().AsTime())
+	require.Equal(t, map[string]any{"host": "b"}, dataPoint.Attributes().AsRaw())
+
+	latency := series.At(1)
+	require.Equal(t, pmetric.MetricTypeHistogram, latency.Type())
+	require.Equal(t, pmetric.AggregationTemporalityDelta, latency.Histogram().AggregationTemporality())
+	histogram := latency.Histogram().DataPoints().At(0)
+	require.Equal(t, uint64(3), histogram.Count())
+	require.Equal(t, 42.5, histogram.Sum())
+	require.Equal(t, []uint64{1, 2}, histogram.BucketCounts().AsRaw())
+	require.Equal(t, []float64{10}, histogram.ExplicitBounds().AsRaw())
+
+	schema = arrow.NewSchema([]arrow.Field{{Name: "metric_type", Type: arrow.BinaryTypes.String}}, nil)
+	builder = array.NewRecordBuilder(memory.DefaultAllocator, schema)
+	defer builder.Release()
+	builder.Field(0).(*array.StringBuilder).Append("summary")
+	_, err = otlpParquetMetricsUnmarshaler{}.UnmarshalMetrics(writeTestParquet(t, builder))
+	require.EqualError(t, err, `unsupported metric_type "summary"`)
+}
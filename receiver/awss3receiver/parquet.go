@@ -0,0 +1,163 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"io"
+
+	"github.com/apache/arrow/go/v15/arrow"
+	"github.com/apache/arrow/go/v15/arrow/array"
+	"github.com/apache/arrow/go/v15/arrow/memory"
+	"github.com/apache/arrow/go/v15/parquet/file"
+	"github.com/apache/arrow/go/v15/parquet/pqarrow"
+)
+
+// parquetMagic starts the Apache Parquet files.
+var parquetMagic = []byte("PAR1")
+
+// forEachParquetRecord calls fn with each of the Arrow records of an Apache Parquet
+// file.
+func forEachParquetRecord(data []byte, fn func(rec arrow.Record)) error {
+	pf, err := file.NewParquetReader(bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	defer pf.Close()
+	fr, err := pqarrow.NewFileReader(pf, pqarrow.ArrowReadProperties{}, memory.DefaultAllocator)
+	if err != nil {
+		return err
+	}
+	table, err := fr.ReadTable(context.Background())
+	if err != nil {
+		return err
+	}
+	defer table.Release()
+
+	tr := array.NewTableReader(table, 0)
+	defer tr.Release()
+	for tr.Next() {
+		fn(tr.Record())
+	}
+	return tr.Err()
+}
+
+// forEachTableRow calls fn with each row of a CSV file, whose first row names the
+// columns, or of an Apache Parquet file, as a map of the names of its columns, as
+// returned by columnName, to its non-empty values. The values of the CSV files are
+// strings and those of the Parquet files raw values, as returned by arrowRawValue.
+func forEachTableRow(data []byte, columnName func(string) string, fn func(row map[string]any)) error {
+	if bytes.HasPrefix(data, parquetMagic) {
+		return forEachParquetRecord(data, func(rec arrow.Record) {
+			columns := make([]string, rec.NumCols())
+			for c := range columns {
+				columns[c] = columnName(rec.ColumnName(c))
+			}
+			for i := 0; i < int(rec.NumRows()); i++ {
+				row := make(map[string]any, len(columns))
+				for c, column := range rec.Columns() {
+					if !column.IsNull(i) {
+						row[columns[c]] = arrowRawValue(column, i)
+					}
+				}
+				fn(row)
+			}
+		})
+	}
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	var columns []string
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if columns == nil {
+			columns = make([]string, len(record))
+			for c, name := range record {
+				columns[c] = columnName(name)
+			}
+			continue
+		}
+		row := make(map[string]any, len(columns))
+		for c, value := range record {
+			if c < len(columns) && value != "" {
+				row[columns[c]] = value
+			}
+		}
+		fn(row)
+	}
+}
+
+// arrowRawValue returns the value of an array at index i as a raw value: a map
+// for structs and maps, a slice for lists, an int64 for integers and for
+// timestamps, in milliseconds since the epoch, a float64 for floating point
+// numbers, a bool for booleans, and a string otherwise.
+func arrowRawValue(arr arrow.Array, i int) any {
+	switch a := arr.(type) {
+	case *array.Struct:
+		fields := a.DataType().(*arrow.StructType).Fields()
+		value := make(map[string]any, len(fields))
+		for f, field := range fields {
+			if column := a.Field(f); !column.IsNull(i) {
+				value[field.Name] = arrowRawValue(column, i)
+			}
+		}
+		return value
+	case *array.Map:
+		start, end := a.ValueOffsets(i)
+		keys, items := a.Keys(), a.Items()
+		value := make(map[string]any, end-start)
+		for j := int(start); j < int(end); j++ {
+			if !items.IsNull(j) {
+				value[keys.ValueStr(j)] = arrowRawValue(items, j)
+			}
+		}
+		return value
+	case array.ListLike:
+		start, end := a.ValueOffsets(i)
+		values := a.ListValues()
+		value := make([]any, 0, end-start)
+		for j := int(start); j < int(end); j++ {
+			if values.IsNull(j) {
+				value = append(value, nil)
+				continue
+			}
+			value = append(value, arrowRawValue(values, j))
+		}
+		return value
+	case *array.Int8:
+		return int64(a.Value(i))
+	case *array.Int16:
+		return int64(a.Value(i))
+	case *array.Int32:
+		return int64(a.Value(i))
+	case *array.Int64:
+		return a.Value(i)
+	case *array.Uint8:
+		return int64(a.Value(i))
+	case *array.Uint16:
+		return int64(a.Value(i))
+	case *array.Uint32:
+		return int64(a.Value(i))
+	case *array.Uint64:
+		return int64(a.Value(i))
+	case *array.Float32:
+		return float64(a.Value(i))
+	case *array.Float64:
+		return a.Value(i)
+	case *array.Boolean:
+		return a.Value(i)
+	case *array.Timestamp:
+		return a.Value(i).ToTime(a.DataType().(*arrow.TimestampType).Unit).UnixMilli()
+	default:
+		return arr.ValueStr(i)
+	}
+}
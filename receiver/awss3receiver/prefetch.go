@@ -0,0 +1,214 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"sync"
+	"time"
+)
+
+// The defaults of the prefetch section.
+const (
+	defaultPrefetchObjects   = 8
+	defaultPrefetchDownloads = 4
+)
+
+// objectRef is an object listed for retrieval.
+type objectRef struct {
+	key       string
+	versionID string
+	// archived is set when the object has to be restored before being retrieved.
+	archived bool
+}
+
+// retrievedObject is the outcome of the retrieval of an object.
+type retrievedObject struct {
+	ref objectRef
+	// contents is the buffer of the pool of the contents of the object.
+	contents *bytes.Buffer
+	info     objectInfo
+	err      error
+}
+
+// objectLister lists the objects to retrieve, handing them to emit in turn until
+// emit returns false.
+type objectLister func(ctx context.Context, emit func(objectRef) bool) error
+
+// objectRetriever retrieves a listed object, returning the body its contents are
+// read from.
+type objectRetriever func(ctx context.Context, ref objectRef) (io.ReadCloser, objectInfo, error)
+
+// objectConsumer consumes the contents of a retrieved object.
+type objectConsumer func(ctx context.Context, ref objectRef, body io.Reader, info objectInfo) error
+
+// prefetcher retrieves the listed objects ahead of their consumption: one
+// goroutine lists the objects, downloads goroutines retrieve them and the caller
+// consumes them in the order they were listed, so that the latency of S3 overlaps
+// with the time spent consuming the objects. At most objects objects are held
+// between their retrieval and their consumption.
+type prefetcher struct {
+	objects   int
+	downloads int
+	// controller, if set, adapts the number of objects retrieved at the same time
+	// up to its max downloads.
+	controller *downloadController
+}
+
+func newPrefetcher(cfg *S3PrefetchConfig) *prefetcher {
+	if cfg == nil {
+		return nil
+	}
+	p := &prefetcher{objects: cfg.Objects, downloads: cfg.Downloads}
+	if p.objects == 0 {
+		p.objects = defaultPrefetchObjects
+	}
+	if p.downloads == 0 {
+		p.downloads = defaultPrefetchDownloads
+	}
+	p.controller = newDownloadController(cfg.Adaptive, p.downloads)
+	return p
+}
+
+// run lists, retrieves and consumes the objects, stopping at the first error.
+// Without a prefetcher, each object is retrieved then consumed before the next
+// one is listed, its contents being consumed as they are received; otherwise the
+// contents of the objects are read in full ahead of their consumption.
+func (p *prefetcher) run(ctx context.Context, list objectLister, retrieve objectRetriever, consume objectConsumer) error {
+	if p == nil {
+		var err error
+		listErr := list(ctx, func(ref objectRef) bool {
+			var body io.ReadCloser
+			var info objectInfo
+			if body, info, err = retrieve(ctx, ref); err == nil {
+				err = consume(ctx, ref, body, info)
+				body.Close()
+			}
+			return err == nil
+		})
+		if err != nil {
+			return err
+		}
+		return listErr
+	}
+
+	pipelineCtx, cancel := context.WithCancel(ctx)
+	// Each listed object is queued for retrieval along with the channel its
+	// outcome is sent to, the channels being consumed in the order of the listing.
+	type download struct {
+		ref    objectRef
+		result chan retrievedObject
+	}
+	downloads := make(chan download)
+	results := make(chan chan retrievedObject, p.objects)
+	var wg sync.WaitGroup
+	workers := p.downloads
+	if p.controller != nil {
+		workers = p.controller.maxDownloads
+	}
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for d := range downloads {
+				if p.controller == nil {
+					contents, info, err := retrieveAll(pipelineCtx, retrieve, d.ref)
+					d.result <- retrievedObject{ref: d.ref, contents: contents, info: info, err: err}
+					continue
+				}
+				generation, ok := p.controller.acquire(pipelineCtx)
+				if !ok {
+					d.result <- retrievedObject{ref: d.ref, err: pipelineCtx.Err()}
+					continue
+				}
+				object, sample := retrieveSampled(pipelineCtx, retrieve, d.ref)
+				if sample != nil {
+					sample.backpressured = len(results) == cap(results)
+				}
+				p.controller.release(generation, sample)
+				d.result <- object
+			}
+		}()
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer close(results)
+		defer close(downloads)
+		err := list(pipelineCtx, func(ref objectRef) bool {
+			result := make(chan retrievedObject, 1)
+			select {
+			case results <- result:
+			case <-pipelineCtx.Done():
+				return false
+			}
+			select {
+			case downloads <- download{ref: ref, result: result}:
+				return true
+			case <-pipelineCtx.Done():
+				result <- retrievedObject{ref: ref, err: pipelineCtx.Err()}
+				return false
+			}
+		})
+		if err != nil {
+			result := make(chan retrievedObject, 1)
+			result <- retrievedObject{err: err}
+			select {
+			case results <- result:
+			case <-pipelineCtx.Done():
+			}
+		}
+	}()
+	defer func() {
+		cancel()
+		wg.Wait()
+	}()
+
+	for result := range results {
+		object := <-result
+		if object.err != nil {
+			return object.err
+		}
+		err := consume(ctx, object.ref, bytes.NewReader(object.contents.Bytes()), object.info)
+		// The body is only valid until the object is consumed.
+		objectBuffers.put(object.contents)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// retrieveSampled retrieves a listed object and reads its contents in full,
+// returning the outcome of the retrieval for the download controller unless the
+// retrieval failed, waited for the restoration of the object or retrieved it in
+// ranges, which the latency of S3 does not tell the time of.
+func retrieveSampled(ctx context.Context, retrieve objectRetriever, ref objectRef) (retrievedObject, *downloadSample) {
+	start := time.Now()
+	body, info, err := retrieve(ctx, ref)
+	if err != nil {
+		return retrievedObject{ref: ref, info: info, err: err}, nil
+	}
+	defer body.Close()
+	sample := &downloadSample{latency: time.Since(start), throttled: info.throttled}
+	contents, err := objectBuffers.readAll(body)
+	if err != nil || ref.archived || info.ranged {
+		sample = nil
+	}
+	return retrievedObject{ref: ref, contents: contents, info: info, err: err}, sample
+}
+
+// retrieveAll retrieves a listed object and reads its contents in full into a
+// buffer of the pool.
+func retrieveAll(ctx context.Context, retrieve objectRetriever, ref objectRef) (*bytes.Buffer, objectInfo, error) {
+	body, info, err := retrieve(ctx, ref)
+	if err != nil {
+		return nil, info, err
+	}
+	defer body.Close()
+	contents, err := objectBuffers.readAll(body)
+	return contents, info, err
+}
@@ -0,0 +1,158 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// listKeys returns a lister of count objects, named key_0 to key_<count-1>.
+func listKeys(count int) objectLister {
+	return func(_ context.Context, emit func(objectRef) bool) error {
+		for i := 0; i < count; i++ {
+			if !emit(objectRef{key: fmt.Sprintf("key_%d", i)}) {
+				return nil
+			}
+		}
+		return nil
+	}
+}
+
+func Test_newPrefetcher(t *testing.T) {
+	require.Nil(t, newPrefetcher(nil))
+	require.Equal(t, &prefetcher{objects: defaultPrefetchObjects, downloads: defaultPrefetchDownloads}, newPrefetcher(&S3PrefetchConfig{}))
+	require.Equal(t, &prefetcher{objects: 2, downloads: 3}, newPrefetcher(&S3PrefetchConfig{Objects: 2, Downloads: 3}))
+}
+
+func Test_prefetcher_run(t *testing.T) {
+	for _, p := range []*prefetcher{nil, {objects: 1, downloads: 1}, {objects: 4, downloads: 3}} {
+		var mu sync.Mutex
+		retrieving, maxRetrieving := 0, 0
+		retrieve := func(_ context.Context, ref objectRef) (io.ReadCloser, objectInfo, error) {
+			mu.Lock()
+			retrieving++
+			maxRetrieving = max(maxRetrieving, retrieving)
+			mu.Unlock()
+			// The retrievals complete out of order.
+			time.Sleep(time.Duration(ref.key[len(ref.key)-1]%3) * time.Millisecond)
+			mu.Lock()
+			retrieving--
+			mu.Unlock()
+			return io.NopCloser(strings.NewReader(ref.key)), objectInfo{key: ref.key}, nil
+		}
+		var consumed []string
+		require.NoError(t, p.run(context.Background(), listKeys(20), retrieve, func(_ context.Context, ref objectRef, body io.Reader, info objectInfo) error {
+			require.Equal(t, ref.key, readBody(t, body))
+			require.Equal(t, ref.key, info.key)
+			consumed = append(consumed, ref.key)
+			return nil
+		}))
+		// The objects are consumed in the order they were listed.
+		require.Len(t, consumed, 20)
+		for i, key := range consumed {
+			require.Equal(t, fmt.Sprintf("key_%d", i), key)
+		}
+		if p != nil {
+			require.LessOrEqual(t, maxRetrieving, p.downloads)
+		}
+	}
+}
+
+func Test_prefetcher_run_Adaptive(t *testing.T) {
+	p := newPrefetcher(&S3PrefetchConfig{Downloads: 1, Adaptive: &S3AdaptiveDownloadsConfig{MaxDownloads: 3, TargetLatency: time.Second}})
+	var mu sync.Mutex
+	retrieving, maxRetrieving := 0, 0
+	retrieve := func(_ context.Context, ref objectRef) (io.ReadCloser, objectInfo, error) {
+		mu.Lock()
+		retrieving++
+		maxRetrieving = max(maxRetrieving, retrieving)
+		mu.Unlock()
+		time.Sleep(time.Millisecond)
+		mu.Lock()
+		retrieving--
+		mu.Unlock()
+		return io.NopCloser(strings.NewReader(ref.key)), objectInfo{key: ref.key}, nil
+	}
+	var consumed []string
+	require.NoError(t, p.run(context.Background(), listKeys(50), retrieve, func(_ context.Context, ref objectRef, body io.Reader, _ objectInfo) error {
+		require.Equal(t, ref.key, readBody(t, body))
+		consumed = append(consumed, ref.key)
+		return nil
+	}))
+	require.Len(t, consumed, 50)
+	for i, key := range consumed {
+		require.Equal(t, fmt.Sprintf("key_%d", i), key)
+	}
+	// The retrievals on time raised the limit up to the max downloads.
+	require.LessOrEqual(t, maxRetrieving, 3)
+	require.Equal(t, 3, p.controller.downloads())
+}
+
+func Test_prefetcher_run_Errors(t *testing.T) {
+	p := &prefetcher{objects: 2, downloads: 2}
+	retrieve := func(_ context.Context, ref objectRef) (io.ReadCloser, objectInfo, error) {
+		if ref.key == "key_3" {
+			return nil, objectInfo{}, errors.New("unable to retrieve")
+		}
+		return io.NopCloser(strings.NewReader(ref.key)), objectInfo{}, nil
+	}
+	var consumed []string
+	consume := func(_ context.Context, ref objectRef, _ io.Reader, _ objectInfo) error {
+		consumed = append(consumed, ref.key)
+		if ref.key == "key_1" {
+			return errors.New("unable to consume")
+		}
+		return nil
+	}
+	require.EqualError(t, p.run(context.Background(), listKeys(10), retrieve, consume), "unable to consume")
+	require.Equal(t, []string{"key_0", "key_1"}, consumed)
+
+	// The objects retrieved before a failed retrieval are consumed.
+	consumed = nil
+	require.EqualError(t, p.run(context.Background(), listKeys(10), retrieve, func(_ context.Context, ref objectRef, _ io.Reader, _ objectInfo) error {
+		consumed = append(consumed, ref.key)
+		return nil
+	}), "unable to retrieve")
+	require.Equal(t, []string{"key_0", "key_1", "key_2"}, consumed)
+
+	// The listing fails once the objects listed before have been consumed.
+	failingList := func(ctx context.Context, emit func(objectRef) bool) error {
+		_ = listKeys(2)(ctx, emit)
+		return errors.New("unable to list")
+	}
+	consumed = nil
+	require.EqualError(t, p.run(context.Background(), failingList, retrieve, func(_ context.Context, ref objectRef, _ io.Reader, _ objectInfo) error {
+		consumed = append(consumed, ref.key)
+		return nil
+	}), "unable to list")
+	require.Equal(t, []string{"key_0", "key_1"}, consumed)
+}
+
+func Test_s3Reader_Prefetch(t *testing.T) {
+	reader := newCheckpointTestReader()
+	reader.prefetcher = &prefetcher{objects: 2, downloads: 2}
+	var keys []string
+	require.NoError(t, reader.readAll(context.Background(), "traces", func(_ context.Context, key string, _ io.Reader) error {
+		keys = append(keys, key)
+		return nil
+	}))
+	require.Equal(t, []string{
+		"year=2021/month=02/day=01/hour=17/minute=32/traces_1",
+		"year=2021/month=02/day=01/hour=17/minute=32/traces_2",
+		"year=2021/month=02/day=01/hour=17/minute=33/traces_1",
+		"year=2021/month=02/day=01/hour=17/minute=33/traces_2",
+		"year=2021/month=02/day=01/hour=17/minute=34/traces_1",
+		"year=2021/month=02/day=01/hour=17/minute=34/traces_2",
+	}, keys)
+	require.Equal(t, checkpoint{Position: testTime.Add(2 * time.Minute), Key: "year=2021/month=02/day=01/hour=17/minute=34/traces_2"}, reader.checkpoint())
+}
@@ -0,0 +1,120 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// progressReader is implemented by the readers able to tell how much of their
+// time range has been read.
+type progressReader interface {
+	// readProgress returns the share, between 0 and 1, of the time range of the
+	// current pass read in full, or false if the time range has no end.
+	readProgress() (float64, bool)
+}
+
+// ingestProgress counts the objects processed since the receiver started, and
+// estimates when the current pass completes. The zero value is ready to use.
+type ingestProgress struct {
+	objects  atomic.Int64
+	bytes    atomic.Int64
+	failures atomic.Int64
+	// lastObject is the time in nanoseconds since the epoch the last object was
+	// processed or failed at.
+	lastObject atomic.Int64
+	// mu guards passStart, set by the reader and read by the notifications.
+	mu        sync.Mutex
+	passStart time.Time
+	now       func() time.Time
+}
+
+func (p *ingestProgress) currentTime() time.Time {
+	if p.now != nil {
+		return p.now()
+	}
+	return time.Now()
+}
+
+// startPass records the start of a pass over the time range.
+func (p *ingestProgress) startPass() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.passStart = p.currentTime()
+}
+
+// countBytes returns a reader counting the bytes read from body.
+func (p *ingestProgress) countBytes(body io.Reader) io.Reader {
+	return &countingReader{reader: body, count: &p.bytes}
+}
+
+// recordObject records the outcome of the processing of an object, and reports
+// whether the object failed. The objects left to the next run, or interrupted, on
+// shutdown are neither processed nor failed.
+func (p *ingestProgress) recordObject(err error) bool {
+	if errors.Is(err, errStopping) || errors.Is(err, context.Canceled) {
+		return false
+	}
+	p.lastObject.Store(p.currentTime().UnixNano())
+	if err != nil {
+		p.failures.Add(1)
+		return true
+	}
+	p.objects.Add(1)
+	return false
+}
+
+// addTo sets the counters of the notification, along with the share of the time
+// range read and the estimated completion time of the pass if the reader tells
+// its progress.
+func (p *ingestProgress) addTo(notification *statusNotification, reader telemetryReader) {
+	notification.ObjectsProcessed = p.objects.Load()
+	notification.BytesDownloaded = p.bytes.Load()
+	notification.Failures = p.failures.Load()
+	if lastObject := p.lastObject.Load(); lastObject != 0 {
+		notification.LastProgress = time.Unix(0, lastObject).UTC()
+	}
+	progress, ok := reader.(progressReader)
+	if !ok {
+		return
+	}
+	read, ok := progress.readProgress()
+	if !ok {
+		return
+	}
+	percent := read * 100
+	notification.PercentComplete = &percent
+	p.mu.Lock()
+	passStart := p.passStart
+	p.mu.Unlock()
+	if passStart.IsZero() || read <= 0 || read >= 1 {
+		return
+	}
+	// The rest of the time range is expected to be read at the pace of the part
+	// read so far.
+	now := p.currentTime()
+	elapsed := now.Sub(passStart)
+	notification.ETA = now.Add(time.Duration(float64(elapsed) * (1 - read) / read))
+}
+
+// countingReader adds the number of bytes read from reader to count, and marks
+// the errors of reader as retrieval errors.
+type countingReader struct {
+	reader io.Reader
+	count  *atomic.Int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.count.Add(int64(n))
+	if err != nil && !errors.Is(err, io.EOF) {
+		return n, &retrievalError{err: err}
+	}
+	return n, err
+}
@@ -0,0 +1,113 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type mockProgressReader struct {
+	telemetryReader
+	read float64
+	ok   bool
+}
+
+func (r mockProgressReader) readProgress() (float64, bool) {
+	return r.read, r.ok
+}
+
+func Test_ingestProgress(t *testing.T) {
+	now := testTime
+	progress := &ingestProgress{now: func() time.Time { return now }}
+	progress.startPass()
+	var notification statusNotification
+	progress.addTo(&notification, mockProgressReader{})
+	require.True(t, notification.LastProgress.IsZero())
+	_, err := io.ReadAll(progress.countBytes(strings.NewReader("this is the body of the object")))
+	require.NoError(t, err)
+	progress.recordObject(nil)
+	progress.recordObject(errors.New("unable to decode"))
+	progress.recordObject(errStopping)
+	progress.recordObject(context.Canceled)
+
+	// A quarter of the time range read in 10 minutes leaves 30 minutes to go.
+	now = testTime.Add(10 * time.Minute)
+	progress.addTo(&notification, mockProgressReader{read: 0.25, ok: true})
+	require.Equal(t, int64(1), notification.ObjectsProcessed)
+	require.Equal(t, int64(30), notification.BytesDownloaded)
+	require.Equal(t, int64(1), notification.Failures)
+	require.Equal(t, 25.0, *notification.PercentComplete)
+	require.Equal(t, testTime.Add(40*time.Minute), notification.ETA)
+	require.True(t, notification.LastProgress.Equal(testTime))
+
+	// Nothing read yet, or everything read, tells no ETA.
+	notification = statusNotification{}
+	progress.addTo(&notification, mockProgressReader{read: 0, ok: true})
+	require.Equal(t, 0.0, *notification.PercentComplete)
+	require.True(t, notification.ETA.IsZero())
+	notification = statusNotification{}
+	progress.addTo(&notification, mockProgressReader{read: 1, ok: true})
+	require.Equal(t, 100.0, *notification.PercentComplete)
+	require.True(t, notification.ETA.IsZero())
+
+	// The progress of a time range without end, or of a reader which cannot
+	// tell, is not known.
+	notification = statusNotification{}
+	progress.addTo(&notification, mockProgressReader{})
+	require.Nil(t, notification.PercentComplete)
+	progress.addTo(&notification, &s3ManifestReader{})
+	require.Nil(t, notification.PercentComplete)
+	require.Equal(t, int64(1), notification.ObjectsProcessed)
+}
+
+func Test_s3Reader_readProgress(t *testing.T) {
+	for _, newestFirst := range []bool{false, true} {
+		reader := newCheckpointTestReader()
+		reader.newestFirst = newestFirst
+		read, ok := reader.readProgress()
+		require.True(t, ok)
+		require.Equal(t, 0.0, read)
+
+		progress := map[string]float64{}
+		require.NoError(t, reader.readAll(context.Background(), "traces", func(_ context.Context, key string, _ io.Reader) error {
+			progress[key[strings.Index(key, "minute="):]], _ = reader.readProgress()
+			return nil
+		}))
+		// The partition being read is not part of the progress.
+		first, last := "minute=32/traces_2", "minute=34/traces_2"
+		if newestFirst {
+			first, last = last, first
+		}
+		require.Equal(t, 0.0, progress[first])
+		require.InDelta(t, 1.0/3, progress["minute=33/traces_2"], 1e-9)
+		require.InDelta(t, 2.0/3, progress[last], 1e-9)
+		read, _ = reader.readProgress()
+		require.Equal(t, 1.0, read)
+	}
+
+	reader := newCheckpointTestReader()
+	reader.endTime = time.Time{}
+	_, ok := reader.readProgress()
+	require.False(t, ok)
+}
+
+func Test_s3MultiBucketReader_readProgress(t *testing.T) {
+	read := newCheckpointTestReader()
+	require.NoError(t, read.readAll(context.Background(), "traces", func(context.Context, string, io.Reader) error { return nil }))
+	reader := &s3MultiBucketReader{readers: []telemetryReader{read, newCheckpointTestReader()}}
+	progress, ok := reader.readProgress()
+	require.True(t, ok)
+	require.Equal(t, 0.5, progress)
+
+	reader.readers = append(reader.readers, mockProgressReader{})
+	_, ok = reader.readProgress()
+	require.False(t, ok)
+}
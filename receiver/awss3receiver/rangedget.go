@@ -0,0 +1,186 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/smithy-go"
+)
+
+// The defaults of the ranged_get section.
+const (
+	defaultRangedGetThreshold   = 16 << 20
+	defaultRangedGetPartSize    = 8 << 20
+	defaultRangedGetConcurrency = 4
+)
+
+// rangedGetter retrieves the objects larger than threshold as parts of partSize
+// bytes, up to concurrency of them at the same time, rather than as a single
+// stream.
+type rangedGetter struct {
+	threshold   int64
+	partSize    int64
+	concurrency int
+}
+
+func newRangedGetter(cfg *S3RangedGetConfig) *rangedGetter {
+	if cfg == nil {
+		return nil
+	}
+	g := &rangedGetter{threshold: cfg.Threshold, partSize: cfg.PartSize, concurrency: cfg.Concurrency}
+	if g.threshold == 0 {
+		g.threshold = defaultRangedGetThreshold
+	}
+	if g.partSize == 0 {
+		g.partSize = min(defaultRangedGetPartSize, g.threshold)
+	}
+	if g.concurrency == 0 {
+		g.concurrency = defaultRangedGetConcurrency
+	}
+	return g
+}
+
+// getObject retrieves the contents of an object along with its description. The
+// first part of the object is retrieved first, telling the size of the object.
+// The rest of the object is then retrieved in a single range unless the object is
+// larger than the threshold, in which case its parts are retrieved concurrently,
+// on condition that the object did not change meanwhile. Without a rangedGetter,
+// the object is retrieved as a single stream.
+func (g *rangedGetter) getObject(ctx context.Context, client GetObjectAPI, params *s3.GetObjectInput) ([]byte, objectInfo, error) {
+	if g == nil {
+		return getObject(ctx, client, params)
+	}
+	info := objectInfo{bucket: aws.ToString(params.Bucket), key: aws.ToString(params.Key)}
+	first, err := g.getRange(ctx, client, params, "", 0, g.partSize)
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) && apiErr.ErrorCode() == "InvalidRange" {
+		// Empty objects have no range to retrieve.
+		return getObject(ctx, client, params)
+	}
+	if err != nil {
+		return nil, info, err
+	}
+	info.lastModified = aws.ToTime(first.output.LastModified)
+	info.etag = aws.ToString(first.output.ETag)
+	info.throttled = isThrottled(first.output.ResultMetadata)
+	size, ok := objectSize(first.output.ContentRange)
+	if !ok || size <= int64(len(first.data)) {
+		// The whole object was retrieved, S3-compatible storages possibly
+		// ignoring the range.
+		return first.data, info, nil
+	}
+	info.ranged = true
+
+	data := make([]byte, size)
+	copy(data, first.data)
+	offset := int64(len(first.data))
+	partSize := g.partSize
+	if size <= g.threshold {
+		partSize = size - offset
+	}
+	var (
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		firstErr error
+	)
+	partsCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	sem := make(chan struct{}, g.concurrency)
+	for ; offset < size; offset += partSize {
+		select {
+		case <-partsCtx.Done():
+		case sem <- struct{}{}:
+		}
+		if partsCtx.Err() != nil {
+			break
+		}
+		length := min(partSize, size-offset)
+		wg.Add(1)
+		go func(offset, length int64) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			part, err := g.getRange(partsCtx, client, params, info.etag, offset, length)
+			if err == nil && int64(len(part.data)) != length {
+				err = fmt.Errorf("retrieved %d bytes instead of %d at offset %d", len(part.data), length, offset)
+			}
+			if err != nil {
+				errOnce.Do(func() {
+					firstErr = err
+					cancel()
+				})
+				return
+			}
+			copy(data[offset:], part.data)
+		}(offset, length)
+	}
+	wg.Wait()
+	if firstErr == nil {
+		firstErr = ctx.Err()
+	}
+	if firstErr != nil {
+		return nil, info, firstErr
+	}
+	return data, info, nil
+}
+
+// openObject retrieves an object along with its description, its contents being
+// read from the returned body. Without a rangedGetter, the contents are read as
+// they are received; otherwise the object is retrieved in full first, as by
+// getObject.
+func (g *rangedGetter) openObject(ctx context.Context, client GetObjectAPI, params *s3.GetObjectInput) (io.ReadCloser, objectInfo, error) {
+	if g == nil {
+		return openObject(ctx, client, params)
+	}
+	data, info, err := g.getObject(ctx, client, params)
+	if err != nil {
+		return nil, info, err
+	}
+	return io.NopCloser(bytes.NewReader(data)), info, nil
+}
+
+// rangeContents is a retrieved range of an object.
+type rangeContents struct {
+	output *s3.GetObjectOutput
+	data   []byte
+}
+
+// getRange retrieves length bytes of an object from offset, on condition that
+// the object still has the given entity tag if not empty.
+func (g *rangedGetter) getRange(ctx context.Context, client GetObjectAPI, params *s3.GetObjectInput, etag string, offset, length int64) (rangeContents, error) {
+	rangeParams := *params
+	rangeParams.Range = aws.String(fmt.Sprintf("bytes=%d-%d", offset, offset+length-1))
+	if etag != "" && rangeParams.VersionId == nil {
+		rangeParams.IfMatch = aws.String(etag)
+	}
+	output, err := client.GetObject(ctx, &rangeParams)
+	if err != nil {
+		return rangeContents{}, err
+	}
+	defer output.Body.Close()
+	data, err := io.ReadAll(output.Body)
+	return rangeContents{output: output, data: data}, err
+}
+
+// objectSize returns the size of the object from the content range of a
+// response to a range request, such as "bytes 0-8388607/134217728".
+func objectSize(contentRange *string) (int64, bool) {
+	_, total, ok := strings.Cut(aws.ToString(contentRange), "/")
+	if !ok {
+		return 0, false
+	}
+	size, err := strconv.ParseInt(total, 10, 64)
+	return size, err == nil
+}
@@ -0,0 +1,138 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/smithy-go"
+	"github.com/stretchr/testify/require"
+)
+
+// mockRangedObject is an object served with support for range requests.
+type mockRangedObject struct {
+	data []byte
+	etag string
+	// ignoreRange is set to serve the whole object regardless of the range.
+	ignoreRange bool
+
+	mu     sync.Mutex
+	ranges []string
+}
+
+func (m *mockRangedObject) GetObject(_ context.Context, params *s3.GetObjectInput, _ ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
+	m.mu.Lock()
+	m.ranges = append(m.ranges, aws.ToString(params.Range))
+	etag := m.etag
+	m.mu.Unlock()
+	output := &s3.GetObjectOutput{ETag: aws.String(etag), LastModified: aws.Time(testTime)}
+	if params.Range == nil || m.ignoreRange {
+		output.Body = io.NopCloser(bytes.NewReader(m.data))
+		return output, nil
+	}
+	if params.IfMatch != nil && *params.IfMatch != etag {
+		return nil, &smithy.GenericAPIError{Code: "PreconditionFailed"}
+	}
+	var start, end int
+	if _, err := fmt.Sscanf(*params.Range, "bytes=%d-%d", &start, &end); err != nil {
+		return nil, err
+	}
+	if start >= len(m.data) {
+		return nil, &smithy.GenericAPIError{Code: "InvalidRange"}
+	}
+	end = min(end, len(m.data)-1)
+	output.Body = io.NopCloser(bytes.NewReader(m.data[start : end+1]))
+	output.ContentRange = aws.String(fmt.Sprintf("bytes %d-%d/%d", start, end, len(m.data)))
+	return output, nil
+}
+
+func Test_newRangedGetter(t *testing.T) {
+	require.Nil(t, newRangedGetter(nil))
+	require.Equal(t, &rangedGetter{threshold: defaultRangedGetThreshold, partSize: defaultRangedGetPartSize, concurrency: defaultRangedGetConcurrency}, newRangedGetter(&S3RangedGetConfig{}))
+	require.Equal(t, &rangedGetter{threshold: 1024, partSize: 1024, concurrency: defaultRangedGetConcurrency}, newRangedGetter(&S3RangedGetConfig{Threshold: 1024}))
+}
+
+func Test_rangedGetter_getObject(t *testing.T) {
+	data := make([]byte, 100)
+	for i := range data {
+		data[i] = byte(i)
+	}
+	g := &rangedGetter{threshold: 50, partSize: 20, concurrency: 2}
+	tests := []struct {
+		name   string
+		object *mockRangedObject
+		ranges []string
+		ranged bool
+	}{
+		{
+			name:   "small object",
+			object: &mockRangedObject{data: data[:15], etag: "a"},
+			ranges: []string{"bytes=0-19"},
+		},
+		{
+			name:   "object below the threshold",
+			object: &mockRangedObject{data: data[:45], etag: "a"},
+			ranges: []string{"bytes=0-19", "bytes=20-44"},
+			ranged: true,
+		},
+		{
+			name:   "large object",
+			object: &mockRangedObject{data: data, etag: "a"},
+			ranges: []string{"bytes=0-19", "bytes=20-39", "bytes=40-59", "bytes=60-79", "bytes=80-99"},
+			ranged: true,
+		},
+		{
+			name:   "empty object",
+			object: &mockRangedObject{data: []byte{}, etag: "a"},
+			ranges: []string{"bytes=0-19", ""},
+		},
+		{
+			name:   "range ignored",
+			object: &mockRangedObject{data: data, etag: "a", ignoreRange: true},
+			ranges: []string{"bytes=0-19"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			contents, info, err := g.getObject(context.Background(), test.object, &s3.GetObjectInput{Bucket: aws.String("bucket"), Key: aws.String("key")})
+			require.NoError(t, err)
+			require.Equal(t, test.object.data, contents)
+			require.Equal(t, objectInfo{bucket: "bucket", key: "key", lastModified: testTime, etag: "a", ranged: test.ranged}, info)
+			require.ElementsMatch(t, test.ranges, test.object.ranges)
+		})
+	}
+}
+
+func Test_rangedGetter_getObject_Changed(t *testing.T) {
+	object := &mockRangedObject{data: make([]byte, 100), etag: "a"}
+	g := &rangedGetter{threshold: 10, partSize: 10, concurrency: 1}
+	client := mockGetObjectAPI(func(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
+		output, err := object.GetObject(ctx, params, optFns...)
+		// The object is overwritten once its first part has been retrieved.
+		object.mu.Lock()
+		object.etag = "b"
+		object.mu.Unlock()
+		return output, err
+	})
+	_, _, err := g.getObject(context.Background(), client, &s3.GetObjectInput{Bucket: aws.String("bucket"), Key: aws.String("key")})
+	require.ErrorContains(t, err, "PreconditionFailed")
+	require.Len(t, object.ranges, 2)
+}
+
+func Test_objectSize(t *testing.T) {
+	size, ok := objectSize(aws.String("bytes 0-8388607/134217728"))
+	require.True(t, ok)
+	require.Equal(t, int64(134217728), size)
+	_, ok = objectSize(nil)
+	require.False(t, ok)
+	_, ok = objectSize(aws.String("bytes 0-10/*"))
+	require.False(t, ok)
+}
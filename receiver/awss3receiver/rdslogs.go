@@ -0,0 +1,342 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"bytes"
+	"encoding/json"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	conventions "go.opentelemetry.io/collector/semconv/v1.25.0"
+)
+
+// rdsLogGroupPattern matches the names of the log groups RDS publishes the logs
+// of its instances and clusters to.
+var rdsLogGroupPattern = regexp.MustCompile(`^/aws/rds/(instance|cluster|proxy)/([^/]+)/([^/]+)$`)
+
+// rdsExportLinePattern matches the first line of the log events exported from
+// CloudWatch Logs to S3, the time of the event followed by its message.
+var rdsExportLinePattern = regexp.MustCompile(`^(\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}(?:\.\d+)?Z) `)
+
+// rdsPostgreSQLPattern matches the PostgreSQL log lines with the log_line_prefix
+// of RDS, %t:%r:%u@%d:[%p]:.
+var rdsPostgreSQLPattern = regexp.MustCompile(`(?s)^(\d{4}-\d{2}-\d{2} \d{2}:\d{2}:\d{2}(?:\.\d+)? \w+):([^:]*):([^@:]*)@([^:]*):\[(\d+)\]:(\w+):\s*(.*)$`)
+
+// rdsSeverities are the severities of the PostgreSQL message levels.
+var rdsSeverities = map[string]plog.SeverityNumber{
+	"DEBUG5": plog.SeverityNumberDebug, "DEBUG4": plog.SeverityNumberDebug, "DEBUG3": plog.SeverityNumberDebug2,
+	"DEBUG2": plog.SeverityNumberDebug3, "DEBUG1": plog.SeverityNumberDebug4, "DEBUG": plog.SeverityNumberDebug,
+	"INFO": plog.SeverityNumberInfo, "NOTICE": plog.SeverityNumberInfo2, "LOG": plog.SeverityNumberInfo,
+	"STATEMENT": plog.SeverityNumberInfo, "DETAIL": plog.SeverityNumberInfo, "HINT": plog.SeverityNumberInfo,
+	"CONTEXT": plog.SeverityNumberInfo, "WARNING": plog.SeverityNumberWarn, "ERROR": plog.SeverityNumberError,
+	"FATAL": plog.SeverityNumberFatal, "PANIC": plog.SeverityNumberFatal4,
+}
+
+// rdsEvent is a log event of an RDS log, along with the log group and stream it
+// was published to when known.
+type rdsEvent struct {
+	timestamp time.Time
+	message   string
+	logGroup  string
+	logStream string
+}
+
+// rdsDecoder decodes the logs RDS publishes to CloudWatch Logs, as exported to S3
+// by export tasks or delivered by Firehose from a subscription, into a log record
+// per event. The MySQL, MariaDB and Aurora MySQL audit and slow query logs and the
+// PostgreSQL logs, including their pgaudit entries, are parsed into db.*
+// attributes. The records are grouped by the account of their log group.
+type rdsDecoder struct{}
+
+func (rdsDecoder) decodeLogs(info objectInfo, data []byte) (plog.Logs, error) {
+	logs := newCloudResourceLogs()
+	if !bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")) {
+		for _, event := range splitRDSExport(string(data)) {
+			setRDSRecord(appendObjectRecord(logs.records("", ""), info), event)
+		}
+		return logs.logs, nil
+	}
+	err := forEachJSONMessage(data, func(message []byte) error {
+		var subscription cloudWatchLogsData
+		if err := json.Unmarshal(message, &subscription); err != nil {
+			return err
+		}
+		if subscription.MessageType == "CONTROL_MESSAGE" {
+			return nil
+		}
+		for _, logEvent := range subscription.LogEvents {
+			event := rdsEvent{
+				timestamp: time.UnixMilli(logEvent.Timestamp),
+				message:   logEvent.Message,
+				logGroup:  subscription.LogGroup,
+				logStream: subscription.LogStream,
+			}
+			setRDSRecord(appendObjectRecord(logs.records(subscription.Owner, ""), info), event)
+		}
+		return nil
+	})
+	return logs.logs, err
+}
+
+// splitRDSExport splits the contents of an object written by a CloudWatch Logs
+// export task into its events, each starting with a line prefixed by its time,
+// the next lines of multiline messages, such as slow queries, not being prefixed.
+// Lines that are not prefixed at the start of the object are events of their own.
+func splitRDSExport(data string) []rdsEvent {
+	var events []rdsEvent
+	var message []string
+	var timestamp time.Time
+	flush := func() {
+		if text := strings.TrimRight(strings.Join(message, "\n"), "\r\n "); strings.TrimSpace(text) != "" {
+			events = append(events, rdsEvent{timestamp: timestamp, message: text})
+		}
+		message = nil
+	}
+	for _, line := range strings.Split(data, "\n") {
+		line = strings.TrimSuffix(line, "\r")
+		if match := rdsExportLinePattern.FindStringSubmatch(line); match != nil {
+			flush()
+			timestamp, _ = time.Parse(time.RFC3339Nano, match[1])
+			line = line[len(match[0]):]
+		} else if message == nil {
+			flush()
+			timestamp = time.Time{}
+		}
+		message = append(message, line)
+	}
+	flush()
+	return events
+}
+
+// setRDSRecord sets the body, attributes and timestamp of a record from an event,
+// its message being its body.
+func setRDSRecord(record plog.LogRecord, event rdsEvent) {
+	record.Body().SetStr(event.message)
+	if !event.timestamp.IsZero() {
+		record.SetTimestamp(pcommon.NewTimestampFromTime(event.timestamp))
+	}
+	attributes := record.Attributes()
+	if event.logGroup != "" {
+		attributes.PutEmptySlice(conventions.AttributeAWSLogGroupNames).AppendEmpty().SetStr(event.logGroup)
+	}
+	if event.logStream != "" {
+		attributes.PutEmptySlice(conventions.AttributeAWSLogStreamNames).AppendEmpty().SetStr(event.logStream)
+	}
+	if match := rdsLogGroupPattern.FindStringSubmatch(event.logGroup); match != nil {
+		attributes.PutStr("aws.rds.db_"+match[1]+"_identifier", match[2])
+		attributes.PutStr("aws.rds.log_type", match[3])
+	}
+	switch message := event.message; {
+	case strings.HasPrefix(message, "# Time:"), strings.HasPrefix(message, "# User@Host:"):
+		setMySQLSlowQuery(record, message)
+	default:
+		if match := rdsPostgreSQLPattern.FindStringSubmatch(message); match != nil {
+			setPostgreSQLEntry(record, match)
+		} else if fields := splitAuditFields(message); isMySQLAudit(fields) {
+			setMySQLAudit(record, fields)
+		}
+	}
+}
+
+// setMySQLSlowQuery sets the attributes and timestamp of a record from a MySQL
+// slow query log entry, whose # lines describe the SQL statements that follow.
+func setMySQLSlowQuery(record plog.LogRecord, entry string) {
+	attributes := record.Attributes()
+	attributes.PutStr(conventions.AttributeDBSystem, conventions.AttributeDBSystemMySQL)
+	var statements []string
+	for _, line := range strings.Split(entry, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(line, "# Time:"):
+			if timestamp, err := time.Parse(time.RFC3339Nano, strings.TrimSpace(strings.TrimPrefix(line, "# Time:"))); err == nil {
+				record.SetTimestamp(pcommon.NewTimestampFromTime(timestamp))
+			}
+		case strings.HasPrefix(line, "# User@Host:"):
+			setMySQLSlowQueryUser(attributes, strings.TrimPrefix(line, "# User@Host:"))
+		case strings.HasPrefix(line, "#"):
+			setMySQLSlowQueryStatistics(attributes, strings.TrimPrefix(line, "#"))
+		case strings.HasPrefix(line, "use ") && strings.HasSuffix(line, ";"):
+			attributes.PutStr(conventions.AttributeDBName, strings.Trim(strings.TrimSuffix(strings.TrimPrefix(line, "use "), ";"), "`"))
+		case strings.HasPrefix(line, "SET timestamp="), line == "":
+		default:
+			statements = append(statements, line)
+		}
+	}
+	if len(statements) > 0 {
+		attributes.PutStr(conventions.AttributeDBStatement, strings.Join(statements, "\n"))
+	}
+}
+
+// setMySQLSlowQueryUser sets the attributes of the "user[user] @ host [ip]  Id: n"
+// line of a slow query.
+func setMySQLSlowQueryUser(attributes pcommon.Map, line string) {
+	account, rest, _ := strings.Cut(line, "@")
+	if user, _, ok := strings.Cut(strings.TrimSpace(account), "["); ok {
+		attributes.PutStr(conventions.AttributeDBUser, user)
+	}
+	host, id, _ := strings.Cut(rest, "Id:")
+	if start, end := strings.Index(host, "["), strings.Index(host, "]"); start >= 0 && end > start+1 {
+		attributes.PutStr(conventions.AttributeClientAddress, host[start+1:end])
+	} else if name := strings.TrimSpace(host); name != "" {
+		attributes.PutStr(conventions.AttributeClientAddress, name)
+	}
+	if connectionID, err := strconv.ParseInt(strings.TrimSpace(id), 10, 64); err == nil {
+		attributes.PutInt("aws.rds.connection_id", connectionID)
+	}
+}
+
+// setMySQLSlowQueryStatistics sets the attributes of the "Name: value" pairs of a
+// line of a slow query, such as Query_time and Rows_examined.
+func setMySQLSlowQueryStatistics(attributes pcommon.Map, line string) {
+	fields := strings.Fields(line)
+	for i := 0; i+1 < len(fields); i += 2 {
+		name, value := strings.TrimSuffix(fields[i], ":"), fields[i+1]
+		attribute := "aws.rds.slow_query." + strings.ToLower(name)
+		if strings.HasSuffix(name, "_time") {
+			if seconds, err := strconv.ParseFloat(value, 64); err == nil {
+				attributes.PutDouble(attribute, seconds)
+			}
+		} else if number, err := strconv.ParseInt(value, 10, 64); err == nil {
+			attributes.PutInt(attribute, number)
+		}
+	}
+}
+
+// setPostgreSQLEntry sets the attributes, severity and timestamp of a record from
+// the fields of a PostgreSQL log line, the time, remote host and port, user,
+// database, process ID, level and message.
+func setPostgreSQLEntry(record plog.LogRecord, match []string) {
+	attributes := record.Attributes()
+	attributes.PutStr(conventions.AttributeDBSystem, conventions.AttributeDBSystemPostgreSQL)
+	if timestamp, err := time.Parse("2006-01-02 15:04:05.999999999 MST", match[1]); err == nil {
+		record.SetTimestamp(pcommon.NewTimestampFromTime(timestamp))
+	}
+	if host, port, ok := strings.Cut(match[2], "("); ok {
+		attributes.PutStr(conventions.AttributeClientAddress, host)
+		if number, err := strconv.ParseInt(strings.TrimSuffix(port, ")"), 10, 64); err == nil {
+			attributes.PutInt(conventions.AttributeClientPort, number)
+		}
+	} else if match[2] != "" {
+		attributes.PutStr(conventions.AttributeClientAddress, match[2])
+	}
+	if match[3] != "" {
+		attributes.PutStr(conventions.AttributeDBUser, match[3])
+	}
+	if match[4] != "" {
+		attributes.PutStr(conventions.AttributeDBName, match[4])
+	}
+	if pid, err := strconv.ParseInt(match[5], 10, 64); err == nil {
+		attributes.PutInt(conventions.AttributeProcessPID, pid)
+	}
+	record.SetSeverityText(match[6])
+	record.SetSeverityNumber(rdsSeverities[match[6]])
+	message := match[7]
+	switch {
+	case strings.HasPrefix(message, "AUDIT: "):
+		// AUDIT_TYPE,STATEMENT_ID,SUBSTATEMENT_ID,CLASS,COMMAND,OBJECT_TYPE,OBJECT_NAME,STATEMENT,PARAMETER
+		fields := splitAuditFields(strings.TrimPrefix(message, "AUDIT: "))
+		if len(fields) >= 8 {
+			attributes.PutStr("aws.rds.audit.type", fields[0])
+			attributes.PutStr("aws.rds.audit.class", fields[3])
+			attributes.PutStr(conventions.AttributeDBOperation, fields[4])
+			if fields[6] != "" {
+				attributes.PutStr(conventions.AttributeDBSQLTable, fields[6])
+			}
+			attributes.PutStr(conventions.AttributeDBStatement, fields[7])
+		}
+	case strings.HasPrefix(message, "duration: "):
+		duration, statement, _ := strings.Cut(strings.TrimPrefix(message, "duration: "), " ms")
+		if milliseconds, err := strconv.ParseFloat(duration, 64); err == nil {
+			attributes.PutDouble("aws.rds.slow_query.query_time", milliseconds/1000)
+		}
+		if _, statement, ok := strings.Cut(statement, ": "); ok {
+			attributes.PutStr(conventions.AttributeDBStatement, strings.TrimSpace(statement))
+		}
+	}
+}
+
+// isMySQLAudit reports whether the fields of a line are the ones of a MySQL or
+// MariaDB audit log entry, whose seventh field is an upper case operation.
+func isMySQLAudit(fields []string) bool {
+	return len(fields) >= 10 && fields[6] != "" && strings.ToUpper(fields[6]) == fields[6] &&
+		strings.Trim(fields[6], "ABCDEFGHIJKLMNOPQRSTUVWXYZ_") == ""
+}
+
+// setMySQLAudit sets the attributes and timestamp of a record from the fields of
+// a MySQL or MariaDB audit log entry: timestamp, serverhost, username, host,
+// connectionid, queryid, operation, database, object and retcode.
+func setMySQLAudit(record plog.LogRecord, fields []string) {
+	attributes := record.Attributes()
+	attributes.PutStr(conventions.AttributeDBSystem, conventions.AttributeDBSystemMySQL)
+	if microseconds, err := strconv.ParseInt(fields[0], 10, 64); err == nil {
+		// The Aurora MySQL advanced auditing logs the time in microseconds.
+		record.SetTimestamp(pcommon.NewTimestampFromTime(time.UnixMicro(microseconds)))
+	} else if timestamp, err := time.Parse("20060102 15:04:05", fields[0]); err == nil {
+		record.SetTimestamp(pcommon.NewTimestampFromTime(timestamp))
+	}
+	putNonEmptyStr(attributes, conventions.AttributeServerAddress, fields[1])
+	putNonEmptyStr(attributes, conventions.AttributeDBUser, fields[2])
+	putNonEmptyStr(attributes, conventions.AttributeClientAddress, fields[3])
+	if connectionID, err := strconv.ParseInt(fields[4], 10, 64); err == nil {
+		attributes.PutInt("aws.rds.connection_id", connectionID)
+	}
+	if queryID, err := strconv.ParseInt(fields[5], 10, 64); err == nil {
+		attributes.PutInt("aws.rds.audit.query_id", queryID)
+	}
+	attributes.PutStr("aws.rds.audit.operation", fields[6])
+	putNonEmptyStr(attributes, conventions.AttributeDBName, fields[7])
+	if strings.HasPrefix(fields[6], "QUERY") {
+		putNonEmptyStr(attributes, conventions.AttributeDBStatement, fields[8])
+	} else {
+		putNonEmptyStr(attributes, "aws.rds.audit.object", fields[8])
+	}
+	if code, err := strconv.ParseInt(fields[9], 10, 64); err == nil {
+		attributes.PutInt("aws.rds.audit.return_code", code)
+	}
+}
+
+// splitAuditFields splits a line of an audit log into its comma separated fields.
+// Fields enclosed in single or double quotes, in which backslashes escape the next
+// character and doubled quotes stand for a quote, may contain commas. The
+// enclosing quotes are removed.
+func splitAuditFields(line string) []string {
+	var fields []string
+	var field strings.Builder
+	for i := 0; i <= len(line); i++ {
+		if i == len(line) || line[i] == ',' {
+			fields = append(fields, field.String())
+			field.Reset()
+			continue
+		}
+		quote := line[i]
+		if (quote != '\'' && quote != '"') || field.Len() > 0 {
+			field.WriteByte(line[i])
+			continue
+		}
+		for i++; i < len(line); i++ {
+			if line[i] == '\\' && i+1 < len(line) {
+				i++
+			} else if line[i] == quote {
+				if i+1 < len(line) && line[i+1] == quote {
+					i++
+				} else {
+					break
+				}
+			}
+			field.WriteByte(line[i])
+		}
+	}
+	return fields
+}
+
+func putNonEmptyStr(attributes pcommon.Map, key, value string) {
+	if value != "" {
+		attributes.PutStr(key, value)
+	}
+}
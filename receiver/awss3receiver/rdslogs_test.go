@@ -0,0 +1,144 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/plog"
+)
+
+func Test_rdsDecoder_export(t *testing.T) {
+	info := objectInfo{bucket: "bucket", key: "rds/0123-4567/mydb/000000.gz"}
+	data := []byte(`2024-01-01T10:00:00.000Z # Time: 2024-01-01T10:00:00.123456Z
+# User@Host: admin[admin] @  [10.0.0.5]  Id:    42
+# Query_time: 2.500000  Lock_time: 0.000100 Rows_sent: 1  Rows_examined: 100000
+use shop;
+SET timestamp=1704103200;
+SELECT COUNT(*)
+FROM orders;
+2024-01-01T10:00:01.000Z 20240101 10:00:01,ip-10-0-0-1,admin,10.0.0.5,42,1001,QUERY,shop,'SELECT * FROM orders WHERE note = \'a,b\'',0
+2024-01-01T10:00:02.000Z 1704103202000000,ip-10-0-0-1,admin,10.0.0.5,42,0,CONNECT,,,0
+2024-01-01T10:00:03.000Z 2024-01-01 10:00:03 UTC:10.0.0.7(51234):app@orders:[1234]:LOG:  AUDIT: SESSION,1,1,READ,SELECT,TABLE,public.orders,"SELECT * FROM orders",<not logged>
+2024-01-01T10:00:04.000Z 2024-01-01 10:00:04 UTC:10.0.0.7(51234):app@orders:[1234]:LOG:  duration: 1500.250 ms  statement: SELECT pg_sleep(1.5)
+2024-01-01T10:00:05.000Z 2024-01-01 10:00:05 UTC::@:[567]:ERROR:  could not connect
+2024-01-01T10:00:06.000Z something else
+`)
+
+	logs, err := rdsDecoder{}.decodeLogs(info, data)
+	require.NoError(t, err)
+	require.Equal(t, 1, logs.ResourceLogs().Len())
+	require.Equal(t, map[string]any{"cloud.provider": "aws"}, logs.ResourceLogs().At(0).Resource().Attributes().AsRaw())
+	records := logs.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords()
+	require.Equal(t, 7, records.Len())
+
+	record := records.At(0)
+	require.Equal(t, time.Date(2024, 1, 1, 10, 0, 0, 123456000, time.UTC), record.Timestamp().AsTime())
+	require.Contains(t, record.Body().Str(), "# User@Host: admin[admin]")
+	require.Equal(t, map[string]any{
+		"aws.s3.bucket":                    "bucket",
+		"aws.s3.key":                       info.key,
+		"db.system":                        "mysql",
+		"db.user":                          "admin",
+		"client.address":                   "10.0.0.5",
+		"aws.rds.connection_id":            int64(42),
+		"aws.rds.slow_query.query_time":    2.5,
+		"aws.rds.slow_query.lock_time":     0.0001,
+		"aws.rds.slow_query.rows_sent":     int64(1),
+		"aws.rds.slow_query.rows_examined": int64(100000),
+		"db.name":                          "shop",
+		"db.statement":                     "SELECT COUNT(*)\nFROM orders;",
+	}, record.Attributes().AsRaw())
+
+	record = records.At(1)
+	require.Equal(t, time.Date(2024, 1, 1, 10, 0, 1, 0, time.UTC), record.Timestamp().AsTime())
+	require.Equal(t, map[string]any{
+		"aws.s3.bucket":             "bucket",
+		"aws.s3.key":                info.key,
+		"db.system":                 "mysql",
+		"server.address":            "ip-10-0-0-1",
+		"db.user":                   "admin",
+		"client.address":            "10.0.0.5",
+		"aws.rds.connection_id":     int64(42),
+		"aws.rds.audit.query_id":    int64(1001),
+		"aws.rds.audit.operation":   "QUERY",
+		"db.name":                   "shop",
+		"db.statement":              "SELECT * FROM orders WHERE note = 'a,b'",
+		"aws.rds.audit.return_code": int64(0),
+	}, record.Attributes().AsRaw())
+
+	record = records.At(2)
+	require.Equal(t, time.Date(2024, 1, 1, 10, 0, 2, 0, time.UTC), record.Timestamp().AsTime().UTC())
+	require.Equal(t, "CONNECT", record.Attributes().AsRaw()["aws.rds.audit.operation"])
+	require.NotContains(t, record.Attributes().AsRaw(), "db.name")
+
+	record = records.At(3)
+	require.Equal(t, time.Date(2024, 1, 1, 10, 0, 3, 0, time.UTC), record.Timestamp().AsTime().UTC())
+	require.Equal(t, "LOG", record.SeverityText())
+	require.Equal(t, plog.SeverityNumberInfo, record.SeverityNumber())
+	require.Equal(t, map[string]any{
+		"aws.s3.bucket":       "bucket",
+		"aws.s3.key":          info.key,
+		"db.system":           "postgresql",
+		"client.address":      "10.0.0.7",
+		"client.port":         int64(51234),
+		"db.user":             "app",
+		"db.name":             "orders",
+		"process.pid":         int64(1234),
+		"aws.rds.audit.type":  "SESSION",
+		"aws.rds.audit.class": "READ",
+		"db.operation":        "SELECT",
+		"db.sql.table":        "public.orders",
+		"db.statement":        "SELECT * FROM orders",
+	}, record.Attributes().AsRaw())
+
+	record = records.At(4)
+	require.Equal(t, 1.50025, record.Attributes().AsRaw()["aws.rds.slow_query.query_time"])
+	require.Equal(t, "SELECT pg_sleep(1.5)", record.Attributes().AsRaw()["db.statement"])
+
+	record = records.At(5)
+	require.Equal(t, plog.SeverityNumberError, record.SeverityNumber())
+	require.Equal(t, int64(567), record.Attributes().AsRaw()["process.pid"])
+	require.NotContains(t, record.Attributes().AsRaw(), "db.user")
+
+	record = records.At(6)
+	require.Equal(t, "something else", record.Body().Str())
+	require.Equal(t, time.Date(2024, 1, 1, 10, 0, 6, 0, time.UTC), record.Timestamp().AsTime())
+	require.Len(t, record.Attributes().AsRaw(), 2)
+}
+
+func Test_rdsDecoder_subscription(t *testing.T) {
+	info := objectInfo{bucket: "bucket", key: "postgresql/2024/01/01/10/stream-1"}
+	data := []byte(`{"messageType":"CONTROL_MESSAGE","owner":"CloudwatchLogs","logGroup":"","logStream":"","logEvents":[{"timestamp":1704103200000,"message":"CWL CONTROL MESSAGE"}]}` +
+		`{"messageType":"DATA_MESSAGE","owner":"123456789012","logGroup":"/aws/rds/instance/mydb/postgresql","logStream":"mydb.0","logEvents":[` +
+		`{"timestamp":1704103200000,"message":"2024-01-01 10:00:00 UTC:10.0.0.7(51234):app@orders:[1234]:WARNING:  there is no transaction in progress"}]}`)
+
+	logs, err := rdsDecoder{}.decodeLogs(info, data)
+	require.NoError(t, err)
+	require.Equal(t, 1, logs.ResourceLogs().Len())
+	require.Equal(t, map[string]any{
+		"cloud.provider":   "aws",
+		"cloud.account.id": "123456789012",
+	}, logs.ResourceLogs().At(0).Resource().Attributes().AsRaw())
+	records := logs.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords()
+	require.Equal(t, 1, records.Len())
+	record := records.At(0)
+	require.Equal(t, plog.SeverityNumberWarn, record.SeverityNumber())
+	attributes := record.Attributes().AsRaw()
+	require.Equal(t, []any{"/aws/rds/instance/mydb/postgresql"}, attributes["aws.log.group.names"])
+	require.Equal(t, []any{"mydb.0"}, attributes["aws.log.stream.names"])
+	require.Equal(t, "mydb", attributes["aws.rds.db_instance_identifier"])
+	require.Equal(t, "postgresql", attributes["aws.rds.log_type"])
+	require.Equal(t, "orders", attributes["db.name"])
+
+	_, err = rdsDecoder{}.decodeLogs(info, []byte(`{"logEvents":`))
+	require.Error(t, err)
+}
+
+func Test_splitAuditFields(t *testing.T) {
+	require.Equal(t, []string{"a", "b c", "", "d,'e'", `x"y`}, splitAuditFields(`a,b c,,'d,\'e\'',"x""y"`))
+	require.Equal(t, []string{""}, splitAuditFields(""))
+}
@@ -4,85 +4,1302 @@
 package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
 
 import (
+	"bufio"
 	"bytes"
-	"compress/gzip"
 	"context"
+	"errors"
+	"fmt"
 	"io"
-	"strings"
+	"path"
+	"slices"
+	"sync"
+	"time"
 
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
 	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/collector/receiver"
+	"go.uber.org/multierr"
 	"go.uber.org/zap"
 )
 
-type awss3TraceReceiver struct {
-	s3Reader *s3Reader
-	consumer consumer.Traces
+// telemetryReader retrieves telemetry objects and hands their contents to the data callback.
+type telemetryReader interface {
+	readAll(ctx context.Context, telemetryType string, dataCallback s3ReaderDataCallback) error
+}
+
+// resumableReader is implemented by the readers able to tell, once stopped, where
+// reading should resume from.
+type resumableReader interface {
+	resumePosition() string
+}
+
+// pausingReader is implemented by the readers waiting themselves for the read to be
+// resumed, where no retrieved object is kept waiting, rather than once an object
+// has been processed.
+type pausingReader interface {
+	setPauseWait(wait func(ctx context.Context) error)
+}
+
+// telemetryProcessor unmarshals the uncompressed contents of an object and sends
+// the telemetry to the next consumer.
+type telemetryProcessor func(ctx context.Context, key string, data []byte) error
+
+// encodingProcessor returns the processor unmarshaling the contents of the objects
+// with an encoding extension, or false if the extension cannot unmarshal the
+// telemetry type.
+type encodingProcessor func(extension component.Component) (telemetryProcessor, bool)
+
+type awss3Receiver struct {
+	reader telemetryReader
+	// auth, if set, is the AWS configuration of the clients of the reader,
+	// retrieved from the auth extension on start.
+	auth          *extensionConfig
+	telemetryType string
+	dataProcessor telemetryProcessor
+	// encoding, if set, is the ID of the encoding extension replacing dataProcessor
+	// with the processor returned by encodingProcessor.
+	encoding          *component.ID
+	encodingProcessor encodingProcessor
+	// formatRules, if any, select the compression and processor of the objects
+	// whose key they match, instead of dataProcessor.
+	formatRules []formatRule
+	// framing, if set, splits the contents of the objects into records handed to
+	// dataProcessor one at a time.
+	framing string
+	// format is the format of the objects matching no format rule.
+	format string
+	// batchSize, if not zero, is the number of lines of the objects in one of
+	// lineFormats handed to the processor at a time, their contents being
+	// decompressed as a stream.
+	batchSize   int
+	lineFormats []string
+	// schedule, if set, restricts the retrieval of objects to its time windows.
+	schedule *ingestSchedule
+	// passes is the number of times the objects are read, unlimited if zero.
+	passes int
+	// shift, if set, is updated at the start of each pass with the offset between
+	// the pass start and rangeStart.
+	shift      *timestampShift
+	rangeStart time.Time
+	// maxDuration, if not zero, is the time after which the receiver stops reading.
+	maxDuration time.Duration
+	// rangeEnd is the end of the time range, reported in the status notifications.
+	rangeEnd time.Time
+	// notifier, if set, is sent the status of the ingest.
+	notifier statusNotifier
+	// progressInterval, if not zero, is the interval the ingesting status is sent
+	// on while reading, along with the progress of the ingest.
+	progressInterval time.Duration
+	// heartbeatInterval, if not zero, is the interval the status is sent on from
+	// start to shutdown, whatever the state of the ingest.
+	heartbeatInterval time.Duration
+	stopHeartbeats    func()
+	progress          ingestProgress
+	// objectFailures, if set, limits the rate of the notifications of the objects
+	// which failed to be processed.
+	objectFailures *objectFailures
+	// control pauses and cancels the read on the commands received by the notifier.
+	control ingestControl
+	// jobs, if set, are the time ranges submitted by the OpAMP server, read one
+	// after the other with the readers returned by newJobReader once the time
+	// range of the configuration, if any, has been read.
+	jobs         *jobQueue
+	newJobReader func(ctx context.Context, job ingestJob) (telemetryReader, error)
+	// jobMu guards reader, rangeStart, rangeEnd and jobID, replaced at the start
+	// of each job.
+	jobMu sync.Mutex
+	jobID string
+	// backpressure, if set, retries the telemetry refused by the next consumer,
+	// stopped on shutdown.
+	backpressure *backpressure
+	// batcher, if set, merges the telemetry of the objects, the last batch being
+	// sent on shutdown.
+	batcher *batcher
+	// operators, if set, run the log records through the stanza operators, the
+	// entries they buffer being sent on shutdown.
+	operators *logOperators
+	// completion, if set, is the group of receivers the collector is asked to shut
+	// down for once they have all finished reading.
+	completion *completionGroup
+	// processed are the objects already processed, skipped if retrieved again,
+	// if deduplication is enabled.
+	processed *processedObjects
+	// id identifies the receiver to the storage extension of the deduplication.
+	id component.ID
+	// lease is set when the objects are only read while the collector holds the
+	// lease.
+	lease *lease
+	// done is closed once the objects are no longer read.
+	done chan struct{}
+	// checkpointer is set when the position of the reader is checkpointed.
+	checkpointer *checkpointer
+	// state is where the processed objects are persisted.
+	state stateConfig
+	// inFlight is held for reading while an object is processed, and for writing
+	// on shutdown to set stopping once the objects in flight have been processed.
+	inFlight sync.RWMutex
+	// stopping is set on shutdown, for the objects retrieved since to be left to
+	// the next run.
+	stopping bool
 	logger   *zap.Logger
 	cancel   context.CancelFunc
 }
 
-func newAWSS3TraceReceiver(ctx context.Context, cfg *Config, traces consumer.Traces, logger *zap.Logger) (*awss3TraceReceiver, error) {
-	reader, err := newS3Reader(ctx, cfg)
+func newAWSS3TraceReceiver(ctx context.Context, cfg *Config, traces consumer.Traces, settings receiver.CreateSettings) (*awss3Receiver, error) {
+	logger := settings.Logger
+	pressure := newBackpressure(cfg.Backpressure, logger)
+	if pressure != nil {
+		var err error
+		if traces, err = pressure.traces(traces); err != nil {
+			return nil, err
+		}
+	}
+	batch := newBatcher(cfg.Batch, logger)
+	if batch != nil {
+		var err error
+		if traces, err = batch.traces(traces); err != nil {
+			return nil, err
+		}
+	}
+	shift := newTimestampShift(cfg)
+	if shift != nil {
+		next := traces
+		var err error
+		if traces, err = consumer.NewTraces(func(ctx context.Context, td ptrace.Traces) error {
+			shiftTraces(td, shift.get())
+			return next.ConsumeTraces(ctx, td)
+		}, consumer.WithCapabilities(consumer.Capabilities{MutatesData: true})); err != nil {
+			return nil, err
+		}
+	}
+	// The errors of the next consumer are told apart from the decoding errors in
+	// the object failures.
+	traces, err := consumerErrorTraces(traces)
+	if err != nil {
+		return nil, err
+	}
+	encodingProcessor := func(extension component.Component) (telemetryProcessor, bool) {
+		unmarshaler, ok := extension.(ptrace.Unmarshaler)
+		if !ok {
+			return nil, false
+		}
+		return newTracesUnmarshalerProcessor(traces, unmarshaler), true
+	}
+	newProcessor := func(format string) telemetryProcessor {
+		return newTracesProcessor(traces, format, logger)
+	}
+	return newAWSS3Receiver(ctx, cfg, settings.ID, "traces", newProcessor, encodingProcessor, shift, pressure, batch, logger)
+}
+
+func newAWSS3LogsReceiver(ctx context.Context, cfg *Config, logs consumer.Logs, settings receiver.CreateSettings) (*awss3Receiver, error) {
+	logger := settings.Logger
+	pressure := newBackpressure(cfg.Backpressure, logger)
+	if pressure != nil {
+		var err error
+		if logs, err = pressure.logs(logs); err != nil {
+			return nil, err
+		}
+	}
+	batch := newBatcher(cfg.Batch, logger)
+	if batch != nil {
+		var err error
+		if logs, err = batch.logs(logs); err != nil {
+			return nil, err
+		}
+	}
+	shift := newTimestampShift(cfg)
+	if shift != nil {
+		next := logs
+		var err error
+		if logs, err = consumer.NewLogs(func(ctx context.Context, ld plog.Logs) error {
+			shiftLogs(ld, shift.get())
+			return next.ConsumeLogs(ctx, ld)
+		}, consumer.WithCapabilities(consumer.Capabilities{MutatesData: true})); err != nil {
+			return nil, err
+		}
+	}
+	operators, err := newLogOperators(cfg.Logs.Operators, settings.TelemetrySettings)
+	if err != nil {
+		return nil, err
+	}
+	if operators != nil {
+		if logs, err = operators.logs(logs); err != nil {
+			return nil, err
+		}
+	}
+	// The errors of the next consumer are told apart from the decoding errors in
+	// the object failures.
+	logs, err = consumerErrorLogs(logs)
+	if err != nil {
+		return nil, err
+	}
+	encodingProcessor := func(extension component.Component) (telemetryProcessor, bool) {
+		unmarshaler, ok := extension.(plog.Unmarshaler)
+		if !ok {
+			return nil, false
+		}
+		return newLogsUnmarshalerProcessor(logs, unmarshaler), true
+	}
+	newProcessor := func(format string) telemetryProcessor {
+		logsCfg := cfg.Logs
+		logsCfg.Format = format
+		return newLogsProcessor(logs, logsCfg, logger)
+	}
+	r, err := newAWSS3Receiver(ctx, cfg, settings.ID, "logs", newProcessor, encodingProcessor, shift, pressure, batch, logger)
+	if err != nil {
+		return nil, err
+	}
+	r.operators = operators
+	return r, nil
+}
+
+func newAWSS3MetricsReceiver(ctx context.Context, cfg *Config, metrics consumer.Metrics, settings receiver.CreateSettings) (*awss3Receiver, error) {
+	logger := settings.Logger
+	pressure := newBackpressure(cfg.Backpressure, logger)
+	if pressure != nil {
+		var err error
+		if metrics, err = pressure.metrics(metrics); err != nil {
+			return nil, err
+		}
+	}
+	batch := newBatcher(cfg.Batch, logger)
+	if batch != nil {
+		var err error
+		if metrics, err = batch.metrics(metrics); err != nil {
+			return nil, err
+		}
+	}
+	shift := newTimestampShift(cfg)
+	if shift != nil {
+		next := metrics
+		var err error
+		if metrics, err = consumer.NewMetrics(func(ctx context.Context, md pmetric.Metrics) error {
+			shiftMetrics(md, shift.get())
+			return next.ConsumeMetrics(ctx, md)
+		}, consumer.WithCapabilities(consumer.Capabilities{MutatesData: true})); err != nil {
+			return nil, err
+		}
+	}
+	// The errors of the next consumer are told apart from the decoding errors in
+	// the object failures.
+	metrics, err := consumerErrorMetrics(metrics)
 	if err != nil {
 		return nil, err
 	}
-	return &awss3TraceReceiver{
-		s3Reader: reader,
-		consumer: traces,
-		logger:   logger,
-		cancel:   nil,
-	}, nil
+	encodingProcessor := func(extension component.Component) (telemetryProcessor, bool) {
+		unmarshaler, ok := extension.(pmetric.Unmarshaler)
+		if !ok {
+			return nil, false
+		}
+		return newMetricsUnmarshalerProcessor(metrics, unmarshaler), true
+	}
+	newProcessor := func(format string) telemetryProcessor {
+		return newMetricsProcessor(metrics, format, logger)
+	}
+	return newAWSS3Receiver(ctx, cfg, settings.ID, "metrics", newProcessor, encodingProcessor, shift, pressure, batch, logger)
+}
+
+// newTimestampShift returns the shift of the timestamps of the replayed telemetry,
+// or nil if the timestamps are not rewritten.
+func newTimestampShift(cfg *Config) *timestampShift {
+	if cfg.Loop == nil || !cfg.Loop.ShiftTimestamps {
+		return nil
+	}
+	return &timestampShift{}
+}
+
+// newAWSS3Receiver returns a receiver of the given telemetry type, decoding the
+// objects with the processors returned by newProcessor for their format.
+func newAWSS3Receiver(ctx context.Context, cfg *Config, id component.ID, telemetryType string, newProcessor func(format string) telemetryProcessor, encodingProcessor encodingProcessor, shift *timestampShift, pressure *backpressure, batch *batcher, logger *zap.Logger) (*awss3Receiver, error) {
+	if !cfg.signalConfig(telemetryType).enabled() {
+		// A receiver without a reader does not retrieve any object.
+		return &awss3Receiver{telemetryType: telemetryType, backpressure: pressure, batcher: batch, logger: logger}, nil
+	}
+	var auth *extensionConfig
+	if cfg.S3Downloader.Auth != nil {
+		auth = newExtensionConfig(*cfg.S3Downloader.Auth, cfg.S3Downloader.Region, logger)
+		authCfg := *cfg
+		authCfg.S3Downloader.authConfig = auth
+		cfg = &authCfg
+	}
+	if len(cfg.S3Downloader.SDKLog) > 0 {
+		logCfg := *cfg
+		logCfg.S3Downloader.sdkLogger = logger
+		cfg = &logCfg
+	}
+	var jobs *jobQueue
+	var newJobReader func(ctx context.Context, job ingestJob) (telemetryReader, error)
+	if cfg.Notifications.Jobs {
+		jobs = newJobQueue()
+		newJobReader = func(ctx context.Context, job ingestJob) (telemetryReader, error) {
+			jobCfg := *cfg
+			jobCfg.StartTime, jobCfg.EndTime = job.StartTime, job.EndTime
+			jobCfg.Traces.StartTime, jobCfg.Metrics.StartTime, jobCfg.Logs.StartTime = "", "", ""
+			return newTelemetryReader(ctx, &jobCfg, telemetryType, logger)
+		}
+	}
+	var reader telemetryReader
+	// With jobs, the time range of the configuration is optional.
+	if jobs == nil || cfg.forTelemetryType(telemetryType).StartTime != "" {
+		var err error
+		if reader, err = newTelemetryReader(ctx, cfg, telemetryType, logger); err != nil {
+			return nil, err
+		}
+	}
+	passes := 1
+	if cfg.Loop != nil {
+		passes = cfg.Loop.Count
+	}
+	var rangeStart, rangeEnd time.Time
+	var err error
+	if startTime := cfg.forTelemetryType(telemetryType).StartTime; startTime != "" {
+		if rangeStart, err = parseTime(startTime, "starttime"); err != nil {
+			return nil, err
+		}
+	}
+	if cfg.EndTime != "" {
+		if rangeEnd, err = parseTime(cfg.EndTime, "endtime"); err != nil {
+			return nil, err
+		}
+	}
+	var schedule *ingestSchedule
+	if cfg.Schedule != nil {
+		if schedule, err = newIngestSchedule(*cfg.Schedule); err != nil {
+			return nil, err
+		}
+	}
+	signalCfg := cfg.signalConfig(telemetryType)
+	var formatRules []formatRule
+	for _, rule := range signalCfg.FormatRules {
+		formatRules = append(formatRules, formatRule{FormatRule: rule, processor: newProcessor(rule.Format)})
+	}
+	var completion *completionGroup
+	if cfg.Completion != nil && cfg.Completion.ShutdownCollector {
+		completion = collectorCompletion
+		completion.add()
+	}
+	var checkpointer *checkpointer
+	if cfg.Checkpoint != nil {
+		if checkpointer, err = newCheckpointer(ctx, cfg, telemetryType, reader, logger); err != nil {
+			return nil, err
+		}
+	}
+	var lease *lease
+	if cfg.Lease != nil {
+		if lease, err = newLease(ctx, cfg.S3Downloader, *cfg.Lease, id, telemetryType, logger); err != nil {
+			return nil, err
+		}
+	}
+	var processed *processedObjects
+	state := stateConfig{store: cfg.StateStore, downloader: cfg.S3Downloader}
+	if cfg.Deduplication != nil {
+		processed = newProcessedObjects(*cfg.Deduplication, logger)
+		state.storage = cfg.Deduplication.Storage
+	}
+	r := &awss3Receiver{
+		reader:            reader,
+		auth:              auth,
+		telemetryType:     telemetryType,
+		dataProcessor:     newProcessor(signalCfg.Format),
+		encoding:          signalCfg.Encoding,
+		encodingProcessor: encodingProcessor,
+		formatRules:       formatRules,
+		framing:           signalCfg.Framing,
+		format:            signalCfg.Format,
+		batchSize:         signalCfg.BatchSize,
+		lineFormats:       cfg.lineFormats(telemetryType),
+		schedule:          schedule,
+		passes:            passes,
+		shift:             shift,
+		backpressure:      pressure,
+		batcher:           batch,
+		rangeStart:        rangeStart,
+		maxDuration:       cfg.MaxDuration,
+		rangeEnd:          rangeEnd,
+		progressInterval:  cfg.Notifications.ProgressInterval,
+		heartbeatInterval: cfg.Notifications.HeartbeatInterval,
+		objectFailures:    newObjectFailures(cfg.Notifications.ObjectFailures),
+		completion:        completion,
+		processed:         processed,
+		checkpointer:      checkpointer,
+		lease:             lease,
+		id:                id,
+		state:             state,
+		jobs:              jobs,
+		newJobReader:      newJobReader,
+		logger:            logger,
+	}
+	r.notifier = newNotifier(cfg, logger, r.handleCommand)
+	return r, nil
+}
+
+func newTelemetryReader(ctx context.Context, cfg *Config, telemetryType string, logger *zap.Logger) (telemetryReader, error) {
+	naming := cfg.objectNaming(telemetryType)
+	cfg = cfg.forTelemetryType(telemetryType)
+	if cfg.SQS != nil {
+		return newS3SQSNotificationReader(ctx, logger, cfg, naming)
+	}
+	if cfg.Manifest != nil {
+		return newS3ManifestReader(ctx, cfg, naming)
+	}
+	if len(cfg.S3Downloader.Buckets) > 0 {
+		return newS3MultiBucketReader(ctx, cfg, naming)
+	}
+	return newS3Reader(ctx, cfg, naming)
 }
 
-func (r *awss3TraceReceiver) Start(_ context.Context, _ component.Host) error {
-	var ctx context.Context
+func (r *awss3Receiver) Start(ctx context.Context, host component.Host) error {
+	if r.reader == nil && r.jobs == nil {
+		r.logger.Info("The ingestion of the telemetry type is disabled", zap.String("telemetry_type", r.telemetryType))
+		return nil
+	}
+	if r.encoding != nil {
+		extension, ok := host.GetExtensions()[*r.encoding]
+		if !ok {
+			return fmt.Errorf("unknown encoding %q", r.encoding)
+		}
+		dataProcessor, ok := r.encodingProcessor(extension)
+		if !ok {
+			return fmt.Errorf("encoding %q cannot unmarshal %s", r.encoding, r.telemetryType)
+		}
+		r.dataProcessor = dataProcessor
+	}
+	if r.auth != nil {
+		if err := r.auth.start(ctx, host); err != nil {
+			return err
+		}
+	}
+	if checker, ok := r.reader.(accessCheckingReader); ok {
+		if err := checker.checkAccess(ctx, r.logger); err != nil {
+			return fmt.Errorf("startup check failed: %w", err)
+		}
+	}
+	if r.lease == nil {
+		if err := r.startState(ctx, host); err != nil {
+			return err
+		}
+	}
+	if r.notifier != nil {
+		if err := r.notifier.Start(ctx, host); err != nil {
+			return err
+		}
+	}
+	if err := r.operators.start(); err != nil {
+		return err
+	}
 	ctx, r.cancel = context.WithCancel(context.Background())
+	r.done = make(chan struct{})
 	go func() {
-		_ = r.s3Reader.readAll(ctx, "traces", r.receiveBytes)
+		defer close(r.done)
+		if r.lease != nil {
+			r.readWithLease(ctx, host)
+			return
+		}
+		if r.reader != nil {
+			r.readAndReport(ctx)
+		}
+		r.readJobs(ctx)
 	}()
+	r.stopHeartbeats = r.notifyHeartbeats(ctx)
 	return nil
 }
 
-func (r *awss3TraceReceiver) Shutdown(_ context.Context) error {
-	if r.cancel != nil {
-		r.cancel()
+// startState loads the state of the receiver, the processed objects and the
+// checkpoint, and starts persisting it.
+func (r *awss3Receiver) startState(ctx context.Context, host component.Host) error {
+	if r.processed != nil {
+		client, err := newStateClient(ctx, host, r.state, r.id, r.telemetryType)
+		if err != nil {
+			return fmt.Errorf("unable to start the deduplication: %w", err)
+		}
+		if err := r.processed.start(ctx, client); err != nil {
+			return fmt.Errorf("unable to start the deduplication: %w", err)
+		}
+	}
+	if r.checkpointer != nil {
+		if err := r.checkpointer.start(ctx); err != nil {
+			return err
+		}
 	}
 	return nil
 }
 
-func (r *awss3TraceReceiver) receiveBytes(ctx context.Context, key string, data []byte) error {
-	if data == nil {
-		return nil
+// shutdownState stops persisting the state of the receiver, writing it one last
+// time unless the collector lost its lease.
+func (r *awss3Receiver) shutdownState(ctx context.Context, lostLease bool) error {
+	var errs error
+	if r.checkpointer != nil {
+		if lostLease {
+			r.checkpointer.stop()
+		} else {
+			errs = multierr.Append(errs, r.checkpointer.shutdown(ctx))
+		}
 	}
+	if r.processed != nil {
+		errs = multierr.Append(errs, r.processed.shutdown(ctx))
+	}
+	return errs
+}
 
-	if strings.HasSuffix(key, ".gz") {
-		reader, err := gzip.NewReader(bytes.NewReader(data))
+// readWithLease reads the objects while the collector holds the lease, loading
+// the state persisted by the collector that held it before. Once the lease is
+// lost, the collector stands by until it acquires the lease again.
+func (r *awss3Receiver) readWithLease(ctx context.Context, host component.Host) {
+	for {
+		leaseCtx, err := r.lease.acquire(ctx)
 		if err != nil {
-			return err
+			return
+		}
+		if err := r.startState(leaseCtx, host); err != nil {
+			r.logger.Error("Failed to load the state", zap.Error(err))
+			r.sendStatus(ctx, ingestStatusFailed, err.Error())
+			return
+		}
+		r.readAndReport(leaseCtx)
+		if ctx.Err() != nil || leaseCtx.Err() == nil {
+			// The state is written one last time on shutdown, while the lease is
+			// still held.
+			return
+		}
+		if err := r.shutdownState(ctx, true); err != nil {
+			r.logger.Warn("Failed to close the state", zap.Error(err))
+		}
+	}
+}
+
+// readAndReport reads the objects, then reports the outcome of the ingest unless
+// ctx was cancelled meanwhile.
+func (r *awss3Receiver) readAndReport(ctx context.Context) {
+	// The outcome is sent once the read has stopped, until shutdown.
+	statusCtx := ctx
+	if r.maxDuration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.maxDuration)
+		defer cancel()
+	}
+	ctx = r.control.start(ctx)
+	defer r.control.stop()
+	err := r.read(ctx)
+	command, cancelled := r.control.cancelledBy()
+	switch {
+	case errors.Is(err, errStopping):
+	case cancelled:
+		r.logger.Info("Cancelled reading telemetry", r.resumeFields()...)
+		notification := r.statusNotification(ingestStatusCancelled, "")
+		notification.ResumeToken = r.resumeToken()
+		notification.Command, notification.CommandID = command.Command, command.CommandID
+		r.notifier.SendStatus(statusCtx, notification)
+		r.complete()
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		r.logger.Info("Reached max_duration, stopped reading telemetry", append(r.resumeFields(), zap.Duration("max_duration", r.maxDuration))...)
+		if r.notifier != nil {
+			notification := r.statusNotification(ingestStatusStopped, "")
+			notification.ResumeToken = r.resumeToken()
+			r.notifier.SendStatus(statusCtx, notification)
 		}
-		key = strings.TrimSuffix(key, ".gz")
-		data, err = io.ReadAll(reader)
+		r.complete()
+	case ctx.Err() != nil:
+	case err != nil:
+		r.logger.Error("Failed to read telemetry", append(r.resumeFields(), zap.Error(err))...)
+		r.sendStatus(statusCtx, ingestStatusFailed, err.Error())
+		r.complete()
+	default:
+		r.logger.Info("Finished reading telemetry", zap.String("telemetry_type", r.telemetryType))
+		r.sendStatus(statusCtx, ingestStatusCompleted, "")
+		r.complete()
+	}
+}
+
+// resumeFields returns the log fields telling the telemetry type and, if the
+// reader is resumable, the position to resume reading it from, which can be set as
+// the starttime of its own section without reading the other telemetry types again.
+func (r *awss3Receiver) resumeFields() []zap.Field {
+	fields := []zap.Field{zap.String("telemetry_type", r.telemetryType)}
+	if reader, ok := r.currentReader().(resumableReader); ok {
+		fields = append(fields, zap.String("resume_position", reader.resumePosition()))
+	}
+	if token := r.resumeToken(); token != "" {
+		fields = append(fields, zap.String("resume_token", token))
+	}
+	return fields
+}
+
+// resumeToken returns the resume token of the reader, to be set as starttime, or
+// as endtime when reading the newest objects first, for the next run to resume
+// after the last object read. It is empty unless the reader reads a time range.
+func (r *awss3Receiver) resumeToken() string {
+	reader, ok := r.currentReader().(checkpointReader)
+	if !ok {
+		return ""
+	}
+	c := reader.checkpoint()
+	if c.Position.IsZero() {
+		return ""
+	}
+	return c.resumeToken()
+}
+
+// currentReader returns the reader of the time range being read, the one of the
+// configuration or of the current job.
+func (r *awss3Receiver) currentReader() telemetryReader {
+	r.jobMu.Lock()
+	defer r.jobMu.Unlock()
+	return r.reader
+}
+
+// readJobs reads the time ranges of the jobs submitted by the OpAMP server one
+// after the other, until shutdown.
+func (r *awss3Receiver) readJobs(ctx context.Context) {
+	if r.jobs == nil {
+		return
+	}
+	for {
+		job, err := r.jobs.next(ctx)
 		if err != nil {
+			if queued := r.jobs.len(); queued > 0 {
+				r.logger.Info("Shut down before reading the time range of the queued jobs", zap.String("telemetry_type", r.telemetryType), zap.Int("jobs", queued))
+			}
+			return
+		}
+		if err := r.startJob(ctx, job); err != nil {
+			r.logger.Error("Failed to start the job", zap.String("telemetry_type", r.telemetryType), zap.String("job_id", job.ID), zap.Error(err))
+			r.sendStatus(ctx, ingestStatusFailed, err.Error())
+			continue
+		}
+		r.logger.Info("Reading the time range of the job", zap.String("telemetry_type", r.telemetryType), zap.String("job_id", job.ID),
+			zap.String("starttime", job.StartTime), zap.String("endtime", job.EndTime))
+		r.readAndReport(ctx)
+	}
+}
+
+// startJob replaces the reader with the one of the time range of job, checking
+// the access to the bucket if startup_check is set.
+func (r *awss3Receiver) startJob(ctx context.Context, job ingestJob) error {
+	// The job is validated on submission.
+	rangeStart, _ := parseTime(job.StartTime, "starttime")
+	rangeEnd, _ := parseTime(job.EndTime, "endtime")
+	r.jobMu.Lock()
+	r.reader, r.jobID, r.rangeStart, r.rangeEnd = nil, job.ID, rangeStart, rangeEnd
+	r.jobMu.Unlock()
+	reader, err := r.newJobReader(ctx, job)
+	if err != nil {
+		return err
+	}
+	if checker, ok := reader.(accessCheckingReader); ok {
+		if err := checker.checkAccess(ctx, r.logger); err != nil {
+			return fmt.Errorf("startup check failed: %w", err)
+		}
+	}
+	r.jobMu.Lock()
+	r.reader = reader
+	r.jobMu.Unlock()
+	return nil
+}
+
+// submitJob queues the job of a submit command, and returns the error rejecting
+// it, if any.
+func (r *awss3Receiver) submitJob(command ingestCommand) string {
+	if r.jobs == nil {
+		return "the receiver does not accept jobs, see notifications jobs"
+	}
+	job := ingestJob{ID: command.CommandID, StartTime: command.StartTime, EndTime: command.EndTime}
+	if err := job.validate(); err != nil {
+		return err.Error()
+	}
+	queued := r.jobs.submit(job)
+	r.logger.Info("Queued the job", zap.String("telemetry_type", r.telemetryType), zap.String("job_id", job.ID), zap.Int("jobs", queued))
+	return ""
+}
+
+func (r *awss3Receiver) read(ctx context.Context) error {
+	dataCallback := r.receiveObject
+	if r.schedule != nil {
+		if err := r.schedule.wait(ctx, r.logger); err != nil {
+			return err
+		}
+		dataCallback = r.receiveObjectOnSchedule
+	}
+	if pausing, ok := r.currentReader().(pausingReader); ok && r.notifier != nil {
+		pausing.setPauseWait(r.control.wait)
+	} else if r.notifier != nil {
+		// The next object is not retrieved while the read is paused.
+		receive := dataCallback
+		dataCallback = func(ctx context.Context, key string, body io.Reader) error {
+			if err := receive(ctx, key, body); err != nil {
+				return err
+			}
+			return r.control.wait(ctx)
+		}
+	}
+	defer r.notifyProgress(ctx)()
+	for pass := 0; r.passes == 0 || pass < r.passes; pass++ {
+		if r.shift != nil {
+			r.shift.set(time.Since(r.rangeStart))
+		}
+		r.progress.startPass()
+		r.sendStatus(ctx, ingestStatusIngesting, "")
+		if err := r.currentReader().readAll(ctx, r.telemetryType, dataCallback); err != nil {
+			if r.passes != 1 && !errors.Is(err, errStopping) {
+				r.logger.Error("Failed to replay the objects, stopping the loop", zap.Int("pass", pass+1), zap.Error(err))
+			}
 			return err
 		}
+		if ctx.Err() != nil {
+			return nil
+		}
+	}
+	return nil
+}
+
+func (r *awss3Receiver) sendStatus(ctx context.Context, ingestStatus, failureMessage string) {
+	if r.notifier == nil {
+		return
+	}
+	r.notifier.SendStatus(ctx, r.statusNotification(ingestStatus, failureMessage))
+}
+
+func (r *awss3Receiver) statusNotification(ingestStatus, failureMessage string) statusNotification {
+	r.jobMu.Lock()
+	notification := statusNotification{
+		TelemetryType:  r.telemetryType,
+		IngestStatus:   ingestStatus,
+		StartTime:      r.rangeStart,
+		EndTime:        r.rangeEnd,
+		FailureMessage: failureMessage,
+		JobID:          r.jobID,
+	}
+	reader := r.reader
+	r.jobMu.Unlock()
+	r.progress.addTo(&notification, reader)
+	r.control.recordStatus(ingestStatus)
+	if ingestStatus == ingestStatusPaused {
+		// The pace of the pass no longer tells when it completes.
+		notification.ETA = time.Time{}
+	}
+	return notification
+}
+
+// currentStatus returns the status of the ingest while reading.
+func (r *awss3Receiver) currentStatus() string {
+	if r.control.paused() {
+		return ingestStatusPaused
+	}
+	return ingestStatusIngesting
+}
+
+// latestStatus returns the status of the ingest while reading, and the last
+// status notified otherwise, idle before the first ingest.
+func (r *awss3Receiver) latestStatus() string {
+	if r.control.reading() {
+		return r.currentStatus()
+	}
+	if status := r.control.lastStatus(); status != "" {
+		return status
+	}
+	return ingestStatusIdle
+}
+
+// handleCommand applies a command of the OpAMP server to the ingest of the
+// telemetry type, acknowledging it with the status of the ingest. The commands
+// sent while the objects are not being read are rejected, and the cancel command
+// is acknowledged once the read has stopped, with the resume token.
+func (r *awss3Receiver) handleCommand(ctx context.Context, command ingestCommand) {
+	if !command.appliesTo(r.telemetryType) {
+		return
+	}
+	var commandErr string
+	switch command.Command {
+	case commandPause:
+		if r.control.pause() {
+			r.logger.Info("Paused reading telemetry on command", r.resumeFields()...)
+		} else {
+			commandErr = "the objects are not being read"
+		}
+	case commandResume:
+		if r.control.paused() {
+			r.logger.Info("Resumed reading telemetry on command", zap.String("telemetry_type", r.telemetryType))
+		}
+		r.control.resume()
+		if !r.control.reading() {
+			commandErr = "the objects are not being read"
+		}
+	case commandCancel:
+		if r.control.cancelRead(command) {
+			return
+		}
+		commandErr = "the objects are not being read"
+	case commandSubmit:
+		commandErr = r.submitJob(command)
+	default:
+		commandErr = fmt.Sprintf("unknown command %q, expected one of '%s', '%s', '%s' or '%s'", command.Command, commandPause, commandResume, commandCancel, commandSubmit)
+	}
+	notification := r.statusNotification(r.latestStatus(), "")
+	notification.Command, notification.CommandID, notification.CommandError = command.Command, command.CommandID, commandErr
+	r.notifier.SendStatus(ctx, notification)
+}
+
+// notifyProgress sends the ingesting status, along with the progress of the
+// ingest, every progressInterval until the returned function is called.
+func (r *awss3Receiver) notifyProgress(ctx context.Context) func() {
+	return r.notifyOnInterval(ctx, r.progressInterval, func() statusNotification {
+		return r.statusNotification(r.currentStatus(), "")
+	})
+}
+
+// notifyHeartbeats sends the latest status of the ingest, along with its
+// progress, every heartbeatInterval until the returned function is called,
+// including while the objects are not being read.
+func (r *awss3Receiver) notifyHeartbeats(ctx context.Context) func() {
+	return r.notifyOnInterval(ctx, r.heartbeatInterval, func() statusNotification {
+		notification := r.statusNotification(r.latestStatus(), "")
+		notification.Heartbeat = true
+		return notification
+	})
+}
+
+// notifyOnInterval sends the notifications returned by notification every
+// interval until the returned function is called or ctx is done.
+func (r *awss3Receiver) notifyOnInterval(ctx context.Context, interval time.Duration, notification func() statusNotification) func() {
+	if r.notifier == nil || interval <= 0 {
+		return func() {}
+	}
+	stop := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				r.notifier.SendStatus(ctx, notification())
+			case <-stop:
+				return
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return func() {
+		close(stop)
+		<-stopped
+	}
+}
+
+// complete asks the collector to shut down, once all the receivers of the
+// completion group have finished reading.
+func (r *awss3Receiver) complete() {
+	if r.completion == nil {
+		return
+	}
+	stopped, err := r.completion.done()
+	if err != nil {
+		r.logger.Error("Failed to ask the collector to shut down", zap.Error(err))
+		return
+	}
+	if stopped {
+		r.logger.Info("All the objects have been read, asking the collector to shut down")
+	}
+}
+
+// receiveObjectOnSchedule processes the contents of an object, then waits for
+// the schedule to allow the retrieval of the next object.
+func (r *awss3Receiver) receiveObjectOnSchedule(ctx context.Context, key string, body io.Reader) error {
+	if err := r.receiveObject(ctx, key, body); err != nil {
+		return err
+	}
+	return r.schedule.wait(ctx, r.logger)
+}
+
+func (r *awss3Receiver) Shutdown(ctx context.Context) error {
+	// The telemetry refused by the next consumer is no longer retried, for the
+	// object in flight to be left to the next run.
+	r.backpressure.stop()
+	interrupted := false
+	if r.done != nil {
+		select {
+		case <-r.done:
+		default:
+			interrupted = true
+			r.stopReading(ctx)
+		}
+	}
+	if r.cancel != nil {
+		r.cancel()
+	}
+	var errs error
+	if r.done != nil {
+		// The state is written, then the lease released, once the objects are no
+		// longer read.
+		select {
+		case <-r.done:
+		case <-ctx.Done():
+		}
+	}
+	// The entries buffered by the operators are batched along with the rest.
+	if err := r.operators.shutdown(ctx); err != nil {
+		r.logger.Error("Failed to send the log records buffered by the operators", zap.Error(err))
+	}
+	if err := r.batcher.flush(ctx); err != nil {
+		r.logger.Error("Failed to send the batched telemetry", zap.String("telemetry_type", r.telemetryType), zap.Error(err))
+	}
+	if interrupted && (r.lease == nil || r.lease.isHeld()) {
+		r.reportResumeToken(ctx)
+	}
+	errs = multierr.Append(errs, r.shutdownState(ctx, false))
+	if r.lease != nil {
+		errs = multierr.Append(errs, r.lease.release(ctx))
+	}
+	if r.stopHeartbeats != nil {
+		r.stopHeartbeats()
+	}
+	if r.notifier != nil {
+		errs = multierr.Append(errs, r.notifier.Shutdown(ctx))
+	}
+	return errs
+}
+
+// stopReading waits for the objects in flight to be processed, unless ctx is
+// done first, and keeps the objects retrieved since from being processed.
+func (r *awss3Receiver) stopReading(ctx context.Context) {
+	if r.inFlight.TryLock() {
+		r.stopping = true
+		r.inFlight.Unlock()
+		return
+	}
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		r.inFlight.Lock()
+		defer r.inFlight.Unlock()
+		r.stopping = true
+	}()
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		r.logger.Warn("Shut down before the objects in flight were processed", zap.String("telemetry_type", r.telemetryType))
+	}
+}
+
+// reportResumeToken logs the resume token of the reader stopped on shutdown, and
+// sends it along with the stopped status.
+func (r *awss3Receiver) reportResumeToken(ctx context.Context) {
+	token := r.resumeToken()
+	if token == "" {
+		return
+	}
+	r.logger.Info("Stopped reading telemetry on shutdown", r.resumeFields()...)
+	if r.notifier != nil {
+		notification := r.statusNotification(ingestStatusStopped, "")
+		notification.ResumeToken = token
+		r.notifier.SendStatus(ctx, notification)
+	}
+}
+
+// errStopping is returned for the objects retrieved once the receiver is shutting
+// down, to stop the reader before they are recorded as read.
+var errStopping = errors.New("the receiver is shutting down")
+
+// receiveObject processes the contents of an object, read from body, unless the
+// object has already been processed.
+func (r *awss3Receiver) receiveObject(ctx context.Context, key string, body io.Reader) error {
+	r.inFlight.RLock()
+	defer r.inFlight.RUnlock()
+	if r.stopping {
+		return errStopping
+	}
+	if body == nil {
+		return nil
+	}
+	if r.processed == nil {
+		return r.receiveCounted(ctx, key, body)
+	}
+	info := objectInfoFromContext(ctx, key)
+	claimed, err := r.processed.claim(ctx, info)
+	if err != nil {
+		// The object is processed rather than skipped unread.
+		r.logger.Warn("Failed to claim the object", zap.String("key", info.key), zap.Error(err))
+		claimed = true
+	}
+	if !claimed {
+		r.logger.Debug("Skipping the object already processed or claimed", zap.String("bucket", info.bucket), zap.String("key", info.key))
+		return nil
+	}
+	err = r.receiveCounted(ctx, key, body)
+	if doneErr := r.processed.done(ctx, info, err == nil); doneErr != nil {
+		r.logger.Warn("Failed to persist the processed objects", zap.String("key", info.key), zap.Error(doneErr))
 	}
+	return err
+}
+
+// receiveCounted processes the contents of an object, counting the object and
+// the bytes read from body in the progress of the ingest.
+func (r *awss3Receiver) receiveCounted(ctx context.Context, key string, body io.Reader) error {
+	err := r.receiveBody(ctx, key, r.progress.countBytes(body))
+	if r.progress.recordObject(err) {
+		r.notifyObjectFailure(ctx, key, err)
+	}
+	return err
+}
 
-	var unmarshaler ptrace.Unmarshaler
-	if strings.HasSuffix(key, ".json") {
-		unmarshaler = &ptrace.JSONUnmarshaler{}
+// notifyObjectFailure sends the failure of the processing of the object stored
+// under key, unless beyond the rate of the notifications.
+func (r *awss3Receiver) notifyObjectFailure(ctx context.Context, key string, err error) {
+	if r.notifier == nil || r.objectFailures == nil {
+		return
 	}
-	if strings.HasSuffix(key, ".binpb") {
-		unmarshaler = &ptrace.ProtoUnmarshaler{}
+	suppressed, ok := r.objectFailures.allow(time.Now())
+	if !ok {
+		return
 	}
-	if unmarshaler == nil {
-		r.logger.Warn("Unsupported file format", zap.String("key", key))
+	info := objectInfoFromContext(ctx, key)
+	r.jobMu.Lock()
+	jobID := r.jobID
+	r.jobMu.Unlock()
+	r.notifier.SendObjectFailure(ctx, objectFailure{
+		TelemetryType: r.telemetryType,
+		Bucket:        info.bucket,
+		Key:           info.key,
+		ErrorClass:    failureClass(err),
+		ErrorMessage:  err.Error(),
+		JobID:         jobID,
+		Suppressed:    suppressed,
+	})
+}
+
+// receiveBody processes the contents of an object as they are read from body when
+// they are made of lines handed to the decoder in batches, and reads them in full
+// otherwise.
+func (r *awss3Receiver) receiveBody(ctx context.Context, key string, body io.Reader) error {
+	dataProcessor, format, compression := r.formatOf(ctx, key, r.dataProcessor)
+	if r.streamsLines(key, format) {
+		return r.receiveLines(ctx, key, body, compression, dataProcessor)
+	}
+	contents, err := objectBuffers.readAll(body)
+	if err != nil {
+		return err
+	}
+	// The contents are decompressed, or copied if not compressed, before being
+	// decoded, so that the buffer is no longer referenced once they are processed.
+	defer objectBuffers.put(contents)
+	return r.receiveContents(ctx, key, contents.Bytes(), r.dataProcessor)
+}
+
+// streamsLines reports whether the contents of the object stored under key, in
+// the given format, are handed to the decoder batchSize lines at a time.
+func (r *awss3Receiver) streamsLines(key, format string) bool {
+	return r.batchSize > 0 && r.framing == "" && r.encoding == nil && slices.Contains(r.lineFormats, format) && !hasArchiveExtension(key)
+}
+
+// formatOf returns the processor, format and compression of the object stored
+// under key, the ones of the format rule matching key if any. The records selected
+// with S3 Select are not compressed whatever the compression of the object.
+func (r *awss3Receiver) formatOf(ctx context.Context, key string, dataProcessor telemetryProcessor) (telemetryProcessor, string, string) {
+	format, compression := r.format, ""
+	if rule := matchFormatRule(r.formatRules, key); rule != nil {
+		dataProcessor, format, compression = rule.processor, rule.Format, rule.Compression
+	}
+	if objectInfoFromContext(ctx, key).selected {
+		compression = CompressionNone
+	}
+	return dataProcessor, format, compression
+}
+
+// receiveContents processes the contents of an object, or of a member of an
+// archive, with dataProcessor unless a format rule matches key. The members of
+// archives are processed in turn, as objects named after the archive and their
+// path in it.
+func (r *awss3Receiver) receiveContents(ctx context.Context, key string, data []byte, dataProcessor telemetryProcessor) error {
+	dataProcessor, format, compression := r.formatOf(ctx, key, dataProcessor)
+	if r.streamsLines(key, format) {
+		return r.receiveLines(ctx, key, bytes.NewReader(data), compression, dataProcessor)
+	}
+	key, data, err := decompressAs(compression, key, data)
+	if err != nil {
+		return err
+	}
+	members, isArchive, err := archiveMembers(key, data)
+	if err != nil {
+		return err
+	}
+	if isArchive {
+		for _, member := range members {
+			info := objectInfoFromContext(ctx, key)
+			info.member = path.Join(info.member, member.name)
+			if err := r.receiveContents(contextWithObjectInfo(ctx, info), key+"/"+member.name, member.data, dataProcessor); err != nil {
+				return err
+			}
+		}
 		return nil
 	}
-	traces, err := unmarshaler.UnmarshalTraces(data)
+	if r.framing == "" {
+		return dataProcessor(ctx, key, data)
+	}
+	records, err := splitRecords(r.framing, data)
+	if err != nil {
+		return err
+	}
+	for _, record := range records {
+		if err := dataProcessor(ctx, key, record); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// receiveLines hands the lines of the contents of an object, read from body, to
+// dataProcessor batchSize lines at a time, the contents being decompressed as
+// they are read so that only a batch of lines is held in memory at a time.
+func (r *awss3Receiver) receiveLines(ctx context.Context, key string, body io.Reader, compression string, dataProcessor telemetryProcessor) error {
+	key, reader, err := decompressReader(compression, key, body)
 	if err != nil {
 		return err
 	}
-	return r.consumer.ConsumeTraces(ctx, traces)
+	defer reader.Close()
+	buffered := bufio.NewReader(reader)
+	var batch []byte
+	lines := 0
+	for {
+		line, err := buffered.ReadBytes('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return err
+		}
+		if len(line) > 0 {
+			batch = append(batch, line...)
+			lines++
+		}
+		if (lines == r.batchSize || err != nil) && len(batch) > 0 {
+			if processErr := dataProcessor(ctx, key, batch); processErr != nil {
+				return processErr
+			}
+			batch, lines = nil, 0
+		}
+		if err != nil {
+			return nil
+		}
+	}
+}
+
+func newTracesProcessor(next consumer.Traces, format string, logger *zap.Logger) telemetryProcessor {
+	return func(ctx context.Context, key string, data []byte) error {
+		var unmarshaler ptrace.Unmarshaler
+		switch objectFormat(key, data, format) {
+		case FormatOTLPJSON:
+			unmarshaler = otlpJSONTracesUnmarshaler{}
+		case FormatOTLPProto:
+			unmarshaler = &ptrace.ProtoUnmarshaler{}
+		case FormatOTelArrow:
+			unmarshaler = otelArrowTracesUnmarshaler{}
+		case FormatXRay:
+			unmarshaler = xrayTracesUnmarshaler{}
+		case FormatJaegerProto:
+			unmarshaler = jaegerProtoTracesUnmarshaler{}
+		case FormatJaegerJSON:
+			unmarshaler = jaegerJSONTracesUnmarshaler{}
+		case FormatZipkinJSON:
+			unmarshaler = zipkinJSONTracesUnmarshaler{}
+		default:
+			logger.Warn("Unsupported file format", zap.String("key", key))
+			return nil
+		}
+		traces, err := unmarshaler.UnmarshalTraces(data)
+		if err != nil {
+			return err
+		}
+		return next.ConsumeTraces(ctx, traces)
+	}
+}
+
+func newLogsProcessor(next consumer.Logs, cfg LogsConfig, logger *zap.Logger) telemetryProcessor {
+	decoder := newLogsDecoder(cfg, logger)
+	return func(ctx context.Context, key string, data []byte) error {
+		var logs plog.Logs
+		var err error
+		switch format := objectFormat(key, data, cfg.Format); {
+		case format == FormatOTLPJSON:
+			logs, err = otlpJSONLogsUnmarshaler{}.UnmarshalLogs(data)
+		case format == FormatOTLPProto:
+			logs, err = (&plog.ProtoUnmarshaler{}).UnmarshalLogs(data)
+		case format == FormatOTLPParquet:
+			logs, err = otlpParquetLogsUnmarshaler{}.UnmarshalLogs(data)
+		case format == FormatOTelArrow:
+			logs, err = otelArrowLogsUnmarshaler{}.UnmarshalLogs(data)
+		case decoder != nil:
+			logs, err = decoder.decodeLogs(objectInfoFromContext(ctx, key), data)
+		default:
+			logger.Warn("Unsupported file format", zap.String("key", key))
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		return next.ConsumeLogs(ctx, logs)
+	}
+}
+
+func newMetricsProcessor(next consumer.Metrics, format string, logger *zap.Logger) telemetryProcessor {
+	return func(ctx context.Context, key string, data []byte) error {
+		var unmarshaler pmetric.Unmarshaler
+		switch objectFormat(key, data, format) {
+		case FormatOTLPJSON:
+			unmarshaler = otlpJSONMetricsUnmarshaler{}
+		case FormatOTLPProto:
+			unmarshaler = &pmetric.ProtoUnmarshaler{}
+		case FormatOTLPParquet:
+			unmarshaler = otlpParquetMetricsUnmarshaler{}
+		case FormatOTelArrow:
+			unmarshaler = otelArrowMetricsUnmarshaler{}
+		case FormatMetricStreamsJSON:
+			unmarshaler = metricStreamsJSONUnmarshaler{}
+		case FormatMetricStreamsOTLP:
+			unmarshaler = metricStreamsOTLPUnmarshaler{}
+		case FormatEMF:
+			unmarshaler = emfUnmarshaler{}
+		case FormatCUR:
+			unmarshaler = curUnmarshaler{}
+		case FormatStorageLens:
+			unmarshaler = storageLensUnmarshaler{}
+		default:
+			logger.Warn("Unsupported file format", zap.String("key", key))
+			return nil
+		}
+		metrics, err := unmarshaler.UnmarshalMetrics(data)
+		if err != nil {
+			return err
+		}
+		return next.ConsumeMetrics(ctx, metrics)
+	}
+}
+
+func newTracesUnmarshalerProcessor(next consumer.Traces, unmarshaler ptrace.Unmarshaler) telemetryProcessor {
+	return func(ctx context.Context, _ string, data []byte) error {
+		traces, err := unmarshaler.UnmarshalTraces(data)
+		if err != nil {
+			return err
+		}
+		return next.ConsumeTraces(ctx, traces)
+	}
+}
+
+func newLogsUnmarshalerProcessor(next consumer.Logs, unmarshaler plog.Unmarshaler) telemetryProcessor {
+	return func(ctx context.Context, _ string, data []byte) error {
+		logs, err := unmarshaler.UnmarshalLogs(data)
+		if err != nil {
+			return err
+		}
+		return next.ConsumeLogs(ctx, logs)
+	}
+}
+
+func newMetricsUnmarshalerProcessor(next consumer.Metrics, unmarshaler pmetric.Unmarshaler) telemetryProcessor {
+	return func(ctx context.Context, _ string, data []byte) error {
+		metrics, err := unmarshaler.UnmarshalMetrics(data)
+		if err != nil {
+			return err
+		}
+		return next.ConsumeMetrics(ctx, metrics)
+	}
 }
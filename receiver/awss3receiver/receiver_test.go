@@ -7,13 +7,29 @@ import (
 	"bytes"
 	"compress/gzip"
 	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
 	"testing"
+	"testing/iotest"
+	"time"
 
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
 	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
 	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/collector/receiver/receivertest"
 	conventions "go.opentelemetry.io/collector/semconv/v1.22.0"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
 )
 
 func generateTraceData() ptrace.Traces {
@@ -36,7 +52,7 @@ func gzipCompress(data []byte) []byte {
 	return buf.Bytes()
 }
 
-func Test_receiveBytes(t *testing.T) {
+func Test_receiveObject(t *testing.T) {
 	testTrace := generateTraceData()
 
 	jsonTrace, err := (&ptrace.JSONMarshaler{}).MarshalTraces(testTrace)
@@ -108,6 +124,33 @@ func Test_receiveBytes(t *testing.T) {
 			wantErr:   false,
 			wantTrace: true,
 		},
+		{
+			name: ".json.zst",
+			args: args{
+				key:  "test.json.zst",
+				data: zstdCompress(jsonTrace),
+			},
+			wantErr:   false,
+			wantTrace: true,
+		},
+		{
+			name: "no extension json",
+			args: args{
+				key:  "test",
+				data: gzipCompress(jsonTrace),
+			},
+			wantErr:   false,
+			wantTrace: true,
+		},
+		{
+			name: "no extension protobuf",
+			args: args{
+				key:  "test",
+				data: zstdCompress(protobufTrace),
+			},
+			wantErr:   false,
+			wantTrace: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -115,19 +158,884 @@ func Test_receiveBytes(t *testing.T) {
 			tracesConsumer, _ := consumer.NewTraces(func(_ context.Context, td ptrace.Traces) error {
 				t.Helper()
 				if !tt.wantTrace {
-					t.Errorf("receiveBytes() received unexpected trace")
+					t.Errorf("receiveObject() received unexpected trace")
 				} else {
 					require.Equal(t, testTrace, td)
 				}
 				return nil
 			})
-			r := &awss3TraceReceiver{
-				consumer: tracesConsumer,
-				logger:   zap.NewNop(),
+			r := &awss3Receiver{
+				dataProcessor: newTracesProcessor(tracesConsumer, "", zap.NewNop()),
+				logger:        zap.NewNop(),
+			}
+			var body io.Reader
+			if tt.args.data != nil {
+				body = bytes.NewReader(tt.args.data)
+			}
+			if err := r.receiveObject(context.Background(), tt.args.key, body); (err != nil) != tt.wantErr {
+				t.Errorf("receiveObject() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func generateLogsData() plog.Logs {
+	ld := plog.NewLogs()
+	rl := ld.ResourceLogs().AppendEmpty()
+	rl.Resource().Attributes().PutStr(conventions.AttributeServiceName, "test")
+	record := rl.ScopeLogs().AppendEmpty().LogRecords().AppendEmpty()
+	record.SetTimestamp(1581452772000000000)
+	record.Body().SetStr("test log")
+	return ld
+}
+
+func generateMetricsData() pmetric.Metrics {
+	md := pmetric.NewMetrics()
+	rm := md.ResourceMetrics().AppendEmpty()
+	rm.Resource().Attributes().PutStr(conventions.AttributeServiceName, "test")
+	metric := rm.ScopeMetrics().AppendEmpty().Metrics().AppendEmpty()
+	metric.SetName("test.metric")
+	dp := metric.SetEmptyGauge().DataPoints().AppendEmpty()
+	dp.SetTimestamp(1581452772000000000)
+	dp.SetIntValue(42)
+	return md
+}
+
+func Test_receiveObject_Logs(t *testing.T) {
+	testLogs := generateLogsData()
+	jsonLogs, err := (&plog.JSONMarshaler{}).MarshalLogs(testLogs)
+	require.NoError(t, err)
+	protobufLogs, err := (&plog.ProtoMarshaler{}).MarshalLogs(testLogs)
+	require.NoError(t, err)
+
+	for key, data := range map[string][]byte{
+		"test.json":     jsonLogs,
+		"test.binpb":    protobufLogs,
+		"test.binpb.gz": gzipCompress(protobufLogs),
+	} {
+		t.Run(key, func(t *testing.T) {
+			received := 0
+			logsConsumer, _ := consumer.NewLogs(func(_ context.Context, ld plog.Logs) error {
+				require.Equal(t, testLogs, ld)
+				received++
+				return nil
+			})
+			r := &awss3Receiver{
+				dataProcessor: newLogsProcessor(logsConsumer, LogsConfig{}, zap.NewNop()),
+				logger:        zap.NewNop(),
+			}
+			require.NoError(t, r.receiveObject(context.Background(), key, bytes.NewReader(data)))
+			require.Equal(t, 1, received)
+		})
+	}
+}
+
+func Test_receiveObject_Metrics(t *testing.T) {
+	testMetrics := generateMetricsData()
+	jsonMetrics, err := (&pmetric.JSONMarshaler{}).MarshalMetrics(testMetrics)
+	require.NoError(t, err)
+	protobufMetrics, err := (&pmetric.ProtoMarshaler{}).MarshalMetrics(testMetrics)
+	require.NoError(t, err)
+
+	for key, data := range map[string][]byte{
+		"test.json":    jsonMetrics,
+		"test.json.gz": gzipCompress(jsonMetrics),
+		"test.binpb":   protobufMetrics,
+	} {
+		t.Run(key, func(t *testing.T) {
+			received := 0
+			metricsConsumer, _ := consumer.NewMetrics(func(_ context.Context, md pmetric.Metrics) error {
+				require.Equal(t, testMetrics, md)
+				received++
+				return nil
+			})
+			r := &awss3Receiver{
+				dataProcessor: newMetricsProcessor(metricsConsumer, "", zap.NewNop()),
+				logger:        zap.NewNop(),
+			}
+			require.NoError(t, r.receiveObject(context.Background(), key, bytes.NewReader(data)))
+			require.Equal(t, 1, received)
+		})
+	}
+}
+
+func Test_receiveObject_Framing(t *testing.T) {
+	sink := &consumertest.LogsSink{}
+	r := &awss3Receiver{
+		dataProcessor: newLogsProcessor(sink, LogsConfig{SignalConfig: SignalConfig{Format: FormatText}}, zap.NewNop()),
+		framing:       FramingNewline,
+		logger:        zap.NewNop(),
+	}
+	// Firehose does not always name its gzip compressed deliveries after their compression.
+	require.NoError(t, r.receiveObject(context.Background(), "stream-1-2021-02-01-17-32-00-id", bytes.NewReader(gzipCompress([]byte("first\nsecond\n")))))
+	require.Len(t, sink.AllLogs(), 2)
+	require.Equal(t, "second", sink.AllLogs()[1].ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0).Body().Str())
+}
+
+func Test_receiveObject_FormatRules(t *testing.T) {
+	protobufLogs, err := (&plog.ProtoMarshaler{}).MarshalLogs(generateLogsData())
+	require.NoError(t, err)
+	sink := &consumertest.LogsSink{}
+	newProcessor := func(format string) telemetryProcessor {
+		return newLogsProcessor(sink, LogsConfig{SignalConfig: SignalConfig{Format: format}}, zap.NewNop())
+	}
+	r := &awss3Receiver{
+		dataProcessor: newProcessor(""),
+		formatRules: []formatRule{
+			{FormatRule: FormatRule{Pattern: "*.jsonl.gz", Format: FormatJSONLines}, processor: newProcessor(FormatJSONLines)},
+			{FormatRule: FormatRule{Pattern: "*.log", Format: FormatText, Compression: CompressionNone}, processor: newProcessor(FormatText)},
+		},
+		logger: zap.NewNop(),
+	}
+	require.NoError(t, r.receiveObject(context.Background(), "app/logs_1.jsonl.gz", bytes.NewReader(gzipCompress([]byte(`{"message":"a"}`)))))
+	require.NoError(t, r.receiveObject(context.Background(), "app/logs_1.log", bytes.NewReader([]byte("\x1f\x8bnot compressed"))))
+	require.NoError(t, r.receiveObject(context.Background(), "collector/logs_1.binpb", bytes.NewReader(protobufLogs)))
+	require.Len(t, sink.AllLogs(), 3)
+	require.Equal(t, map[string]any{"message": "a"}, sink.AllLogs()[0].ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0).Body().AsRaw())
+	require.Equal(t, "\x1f\x8bnot compressed", sink.AllLogs()[1].ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0).Body().Str())
+	require.Equal(t, generateLogsData(), sink.AllLogs()[2])
+}
+
+func Test_receiveObject_BatchSize(t *testing.T) {
+	sink := &consumertest.LogsSink{}
+	cfg := createDefaultConfig().(*Config)
+	cfg.Logs.Format = FormatJSONLines
+	r := &awss3Receiver{
+		dataProcessor: newLogsProcessor(sink, cfg.Logs, zap.NewNop()),
+		format:        FormatJSONLines,
+		batchSize:     2,
+		lineFormats:   cfg.lineFormats("logs"),
+		logger:        zap.NewNop(),
+	}
+	data := []byte("{\"n\":1}\n{\"n\":2}\n\n{\"n\":3}\n{\"n\":4}\n{\"n\":5}")
+	for key, compressed := range map[string][]byte{
+		"app/logs_1.jsonl.gz":  gzipCompress(data),
+		"app/logs_1.jsonl.zst": zstdCompress(data),
+		"app/logs_1.jsonl":     data,
+	} {
+		t.Run(key, func(t *testing.T) {
+			sink.Reset()
+			require.NoError(t, r.receiveObject(context.Background(), key, bytes.NewReader(compressed)))
+			var batches []int
+			for _, logs := range sink.AllLogs() {
+				batches = append(batches, logs.LogRecordCount())
+			}
+			// The empty line counts as one of the lines of the second batch.
+			require.Equal(t, []int{2, 1, 2}, batches)
+			record := sink.AllLogs()[2].ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(1)
+			require.Equal(t, int64(5), record.Body().Map().AsRaw()["n"])
+		})
+	}
+
+	require.ErrorContains(t, r.receiveObject(context.Background(), "app/logs_2.jsonl.gz", bytes.NewReader([]byte("\x1f\x8bnot compressed"))), "gzip")
+
+	cfg.Logs.Text.Multiline.LineStartPattern = `^\d`
+	require.Equal(t, []string{FormatOTLPJSON, FormatJSONLines, FormatSyslog, FormatFluentBit}, cfg.lineFormats("logs"))
+	require.Equal(t, []string{FormatOTLPJSON}, cfg.lineFormats("traces"))
+}
+
+// readerFunc reads through a function, to observe the reads of a body.
+type readerFunc func(p []byte) (int, error)
+
+func (f readerFunc) Read(p []byte) (int, error) {
+	return f(p)
+}
+
+func Test_receiveObject_Streaming(t *testing.T) {
+	sink := &consumertest.LogsSink{}
+	cfg := createDefaultConfig().(*Config)
+	cfg.Logs.Format = FormatJSONLines
+	r := &awss3Receiver{
+		dataProcessor: newLogsProcessor(sink, cfg.Logs, zap.NewNop()),
+		format:        FormatJSONLines,
+		batchSize:     2,
+		lineFormats:   cfg.lineFormats("logs"),
+		logger:        zap.NewNop(),
+	}
+	rest := strings.NewReader("{\"n\":3}\n")
+	body := io.MultiReader(strings.NewReader("{\"n\":1}\n{\"n\":2}\n"), readerFunc(func(p []byte) (int, error) {
+		// The first batch is processed before the rest of the object is read.
+		require.Equal(t, 2, sink.LogRecordCount())
+		return rest.Read(p)
+	}))
+	require.NoError(t, r.receiveObject(context.Background(), "app/logs_1.jsonl", body))
+	require.Equal(t, 3, sink.LogRecordCount())
+}
+
+func Test_receiveObject_SizeDelimitedFraming(t *testing.T) {
+	marshaled, err := (&ptrace.ProtoMarshaler{}).MarshalTraces(generateTraceData())
+	require.NoError(t, err)
+	var data []byte
+	for i := 0; i < 3; i++ {
+		data = binary.AppendUvarint(data, uint64(len(marshaled)))
+		data = append(data, marshaled...)
+	}
+	sink := &consumertest.TracesSink{}
+	r := &awss3Receiver{
+		dataProcessor: newTracesProcessor(sink, FormatOTLPProto, zap.NewNop()),
+		framing:       FramingSizeDelimited,
+		logger:        zap.NewNop(),
+	}
+	require.NoError(t, r.receiveObject(context.Background(), "traces/traces_1.binpb", bytes.NewReader(data)))
+	require.Len(t, sink.AllTraces(), 3)
+	require.Equal(t, generateTraceData(), sink.AllTraces()[2])
+}
+
+// resumableMockReader is a mockTelemetryReader reporting a fixed resume position.
+type resumableMockReader struct {
+	mockTelemetryReader
+	position string
+}
+
+func (r resumableMockReader) resumePosition() string {
+	return r.position
+}
+
+func Test_awss3Receiver_MaxDuration(t *testing.T) {
+	core, logs := observer.New(zap.InfoLevel)
+	notifier := &mockStatusNotifier{}
+	stopped := make(chan struct{})
+	r := &awss3Receiver{
+		reader:        newCheckpointTestReader(),
+		telemetryType: "traces",
+		dataProcessor: func(ctx context.Context, key string, _ []byte) error {
+			// The time range is read no further than its first object.
+			if !strings.HasSuffix(key, "minute=32/traces_1") {
+				<-ctx.Done()
+			}
+			return nil
+		},
+		passes:      1,
+		maxDuration: 10 * time.Millisecond,
+		notifier:    notifier,
+		completion: &completionGroup{pending: 1, stop: func() error {
+			close(stopped)
+			return nil
+		}},
+		logger: zap.New(core),
+	}
+	require.NoError(t, r.Start(context.Background(), nil))
+	// Reaching max_duration finishes the ingest, shutting down the collector.
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the collector was not asked to shut down")
+	}
+	require.NoError(t, r.Shutdown(context.Background()))
+	entries := logs.FilterMessage("Reached max_duration, stopped reading telemetry").AllUntimed()
+	require.Len(t, entries, 1)
+	fields := entries[0].ContextMap()
+	require.Equal(t, "traces", fields["telemetry_type"])
+	require.NotEmpty(t, fields["resume_position"])
+	require.Equal(t, []string{ingestStatusIngesting, ingestStatusStopped}, notifier.statuses)
+	require.Equal(t, []string{fields["resume_token"].(string)}, notifier.resumeTokens)
+}
+
+func Test_awss3Receiver_ReadFailure(t *testing.T) {
+	core, logs := observer.New(zap.InfoLevel)
+	r := &awss3Receiver{
+		reader: resumableMockReader{
+			mockTelemetryReader: func(_ context.Context, _ string, _ s3ReaderDataCallback) error {
+				return errors.New("unable to decode")
+			},
+			position: `starttime: "2024-01-01 05:00"`,
+		},
+		telemetryType: "logs",
+		passes:        1,
+		logger:        zap.New(core),
+	}
+	require.NoError(t, r.Start(context.Background(), nil))
+	require.Eventually(t, func() bool {
+		return logs.FilterMessage("Failed to read telemetry").Len() == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	require.NoError(t, r.Shutdown(context.Background()))
+	fields := logs.FilterMessage("Failed to read telemetry").AllUntimed()[0].ContextMap()
+	require.Equal(t, "logs", fields["telemetry_type"])
+	require.Equal(t, `starttime: "2024-01-01 05:00"`, fields["resume_position"])
+	require.Equal(t, "unable to decode", fields["error"])
+}
+
+type mockStatusNotifier struct {
+	// mu guards the notifications, sent on an interval while reading.
+	mu            sync.Mutex
+	statuses      []string
+	resumeTokens  []string
+	notifications []statusNotification
+	failures      []objectFailure
+}
+
+func (n *mockStatusNotifier) SendObjectFailure(_ context.Context, failure objectFailure) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.failures = append(n.failures, failure)
+}
+
+func (n *mockStatusNotifier) Start(_ context.Context, _ component.Host) error {
+	return nil
+}
+
+func (n *mockStatusNotifier) Shutdown(_ context.Context) error {
+	return nil
+}
+
+func (n *mockStatusNotifier) SendStatus(_ context.Context, notification statusNotification) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.notifications = append(n.notifications, notification)
+	n.statuses = append(n.statuses, notification.IngestStatus)
+	if notification.ResumeToken != "" {
+		n.resumeTokens = append(n.resumeTokens, notification.ResumeToken)
+	}
+}
+
+func Test_awss3Receiver_ShutdownResumeToken(t *testing.T) {
+	core, logs := observer.New(zap.InfoLevel)
+	notifier := &mockStatusNotifier{}
+	inFlight := make(chan struct{})
+	var keys []string
+	r := &awss3Receiver{
+		reader:        newCheckpointTestReader(),
+		telemetryType: "traces",
+		dataProcessor: func(ctx context.Context, key string, _ []byte) error {
+			keys = append(keys, key)
+			if strings.HasSuffix(key, "minute=33/traces_1") {
+				close(inFlight)
+				// The object in flight is processed before the receiver shuts down.
+				time.Sleep(50 * time.Millisecond)
+				return ctx.Err()
 			}
-			if err := r.receiveBytes(context.Background(), tt.args.key, tt.args.data); (err != nil) != tt.wantErr {
-				t.Errorf("receiveBytes() error = %v, wantErr %v", err, tt.wantErr)
+			return nil
+		},
+		passes:   1,
+		notifier: notifier,
+		logger:   zap.New(core),
+	}
+	require.NoError(t, r.Start(context.Background(), componenttest.NewNopHost()))
+	<-inFlight
+	require.NoError(t, r.Shutdown(context.Background()))
+	require.Equal(t, []string{
+		"year=2021/month=02/day=01/hour=17/minute=32/traces_1",
+		"year=2021/month=02/day=01/hour=17/minute=32/traces_2",
+		"year=2021/month=02/day=01/hour=17/minute=33/traces_1",
+	}, keys)
+	require.Equal(t, []string{ingestStatusIngesting, ingestStatusStopped}, notifier.statuses)
+	require.Len(t, notifier.resumeTokens, 1)
+	entries := logs.FilterMessage("Stopped reading telemetry on shutdown").AllUntimed()
+	require.Len(t, entries, 1)
+	require.Equal(t, notifier.resumeTokens[0], entries[0].ContextMap()["resume_token"])
+
+	// The token set as starttime resumes reading after the object in flight.
+	startTime, err := parseTime(notifier.resumeTokens[0], "starttime")
+	require.NoError(t, err)
+	require.Equal(t, testTime.Add(time.Minute), startTime)
+	c, ok, err := parseResumeToken(notifier.resumeTokens[0])
+	require.NoError(t, err)
+	require.True(t, ok)
+	reader := newCheckpointTestReader()
+	reader.startTime = startTime
+	require.True(t, reader.resumeFrom(c))
+	keys = nil
+	require.NoError(t, reader.readAll(context.Background(), "traces", func(_ context.Context, key string, _ io.Reader) error {
+		keys = append(keys, key)
+		return nil
+	}))
+	require.Equal(t, []string{
+		"year=2021/month=02/day=01/hour=17/minute=33/traces_2",
+		"year=2021/month=02/day=01/hour=17/minute=34/traces_1",
+		"year=2021/month=02/day=01/hour=17/minute=34/traces_2",
+	}, keys)
+}
+
+func Test_awss3Receiver_ShutdownBackpressure(t *testing.T) {
+	notifier := &mockStatusNotifier{}
+	pressure := newBackpressure(&BackpressureConfig{InitialInterval: time.Hour}, zap.NewNop())
+	refused := make(chan struct{})
+	r := &awss3Receiver{
+		reader:        newCheckpointTestReader(),
+		telemetryType: "traces",
+		dataProcessor: func(ctx context.Context, key string, _ []byte) error {
+			return pressure.consume(ctx, func(context.Context) error {
+				if strings.HasSuffix(key, "minute=33/traces_1") {
+					close(refused)
+					return errRefused
+				}
+				return nil
+			})
+		},
+		backpressure: pressure,
+		passes:       1,
+		notifier:     notifier,
+		logger:       zap.NewNop(),
+	}
+	require.NoError(t, r.Start(context.Background(), componenttest.NewNopHost()))
+	<-refused
+	// The refused object is no longer retried on shutdown, and left to the next run.
+	require.NoError(t, r.Shutdown(context.Background()))
+	require.Equal(t, []string{ingestStatusIngesting, ingestStatusStopped}, notifier.statuses)
+	require.Len(t, notifier.resumeTokens, 1)
+	c, ok, err := parseResumeToken(notifier.resumeTokens[0])
+	require.NoError(t, err)
+	require.True(t, ok)
+	reader := newCheckpointTestReader()
+	require.True(t, reader.resumeFrom(c))
+	var keys []string
+	require.NoError(t, reader.readAll(context.Background(), "traces", func(_ context.Context, key string, _ io.Reader) error {
+		keys = append(keys, key)
+		return nil
+	}))
+	require.Equal(t, "year=2021/month=02/day=01/hour=17/minute=33/traces_1", keys[0])
+}
+
+func Test_awss3Receiver_Completion(t *testing.T) {
+	tests := []struct {
+		name     string
+		readErr  error
+		statuses []string
+	}{
+		{
+			name:     "completed",
+			statuses: []string{ingestStatusIngesting, ingestStatusCompleted},
+		},
+		{
+			name:     "failed",
+			readErr:  errors.New("read failed"),
+			statuses: []string{ingestStatusIngesting, ingestStatusFailed},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			stopped := make(chan struct{})
+			notifier := &mockStatusNotifier{}
+			r := &awss3Receiver{
+				reader: mockTelemetryReader(func(_ context.Context, _ string, _ s3ReaderDataCallback) error {
+					return test.readErr
+				}),
+				telemetryType: "traces",
+				passes:        1,
+				notifier:      notifier,
+				completion: &completionGroup{pending: 1, stop: func() error {
+					close(stopped)
+					return nil
+				}},
+				logger: zap.NewNop(),
 			}
+			require.NoError(t, r.Start(context.Background(), componenttest.NewNopHost()))
+			<-stopped
+			require.NoError(t, r.Shutdown(context.Background()))
+			require.Equal(t, test.statuses, notifier.statuses)
 		})
 	}
 }
+
+func Test_awss3Receiver_ProgressNotifications(t *testing.T) {
+	stopped := make(chan struct{})
+	notifier := &mockStatusNotifier{}
+	r := &awss3Receiver{
+		reader:        newCheckpointTestReader(),
+		telemetryType: "traces",
+		dataProcessor: func(_ context.Context, key string, _ []byte) error {
+			time.Sleep(20 * time.Millisecond)
+			if strings.HasSuffix(key, "minute=33/traces_1") {
+				return errors.New("unable to decode")
+			}
+			return nil
+		},
+		passes:           1,
+		notifier:         notifier,
+		progressInterval: 10 * time.Millisecond,
+		completion: &completionGroup{pending: 1, stop: func() error {
+			close(stopped)
+			return nil
+		}},
+		logger: zap.NewNop(),
+	}
+	require.NoError(t, r.Start(context.Background(), componenttest.NewNopHost()))
+	<-stopped
+	require.NoError(t, r.Shutdown(context.Background()))
+
+	// The ingesting status is sent along with the progress while reading, until
+	// the read fails on the object which cannot be decoded.
+	notifications := notifier.notifications
+	require.Greater(t, len(notifications), 2)
+	require.Equal(t, ingestStatusIngesting, notifications[0].IngestStatus)
+	require.Equal(t, 0.0, *notifications[0].PercentComplete)
+	var progressed bool
+	for _, notification := range notifications[1 : len(notifications)-1] {
+		require.Equal(t, ingestStatusIngesting, notification.IngestStatus)
+		if notification.ObjectsProcessed > 0 {
+			progressed = true
+		}
+	}
+	require.True(t, progressed)
+	last := notifications[len(notifications)-1]
+	require.Equal(t, ingestStatusFailed, last.IngestStatus)
+	require.Equal(t, int64(2), last.ObjectsProcessed)
+	require.Equal(t, int64(3*len("this is the body of the object")), last.BytesDownloaded)
+	require.Equal(t, int64(1), last.Failures)
+	require.InDelta(t, 100.0/3, *last.PercentComplete, 1e-9)
+	require.False(t, last.ETA.IsZero())
+}
+
+func Test_awss3Receiver_ObjectFailures(t *testing.T) {
+	notifier := &mockStatusNotifier{}
+	r := &awss3Receiver{
+		telemetryType: "traces",
+		dataProcessor: func(_ context.Context, key string, _ []byte) error {
+			switch {
+			case strings.HasSuffix(key, "decode"):
+				return errors.New("unable to decode")
+			case strings.HasSuffix(key, "consumer"):
+				return &consumerError{err: errors.New("refused")}
+			}
+			return nil
+		},
+		notifier:       notifier,
+		objectFailures: newObjectFailures(&ObjectFailuresConfig{PerSecond: 0.001, Burst: 2}),
+		jobID:          "7",
+		logger:         zap.NewNop(),
+	}
+	ctx := contextWithObjectInfo(context.Background(), objectInfo{bucket: "bucket", key: "prefix/decode"})
+	require.Error(t, r.receiveCounted(ctx, "prefix/decode", strings.NewReader("body")))
+	require.NoError(t, r.receiveCounted(context.Background(), "ok", strings.NewReader("body")))
+	require.Error(t, r.receiveCounted(context.Background(), "consumer", strings.NewReader("body")))
+	// The failures beyond the rate of the notifications are only counted.
+	require.Error(t, r.receiveCounted(context.Background(), "decode", strings.NewReader("body")))
+	require.ErrorIs(t, r.receiveCounted(context.Background(), "stopping", iotest.ErrReader(errStopping)), errStopping)
+
+	require.Equal(t, []objectFailure{
+		{
+			TelemetryType: "traces",
+			Bucket:        "bucket",
+			Key:           "prefix/decode",
+			ErrorClass:    failureClassDecode,
+			ErrorMessage:  "unable to decode",
+			JobID:         "7",
+		},
+		{
+			TelemetryType: "traces",
+			Key:           "consumer",
+			ErrorClass:    failureClassConsumer,
+			ErrorMessage:  "refused",
+			JobID:         "7",
+		},
+	}, notifier.failures)
+	require.Equal(t, int64(3), r.progress.failures.Load())
+}
+
+func Test_awss3Receiver_Heartbeats(t *testing.T) {
+	stopped := make(chan struct{})
+	notifier := &mockStatusNotifier{}
+	release := make(chan struct{})
+	r := &awss3Receiver{
+		reader:        newCheckpointTestReader(),
+		telemetryType: "traces",
+		dataProcessor: func(ctx context.Context, _ string, _ []byte) error {
+			select {
+			case <-release:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		},
+		passes:            1,
+		notifier:          notifier,
+		heartbeatInterval: 10 * time.Millisecond,
+		completion: &completionGroup{pending: 1, stop: func() error {
+			close(stopped)
+			return nil
+		}},
+		logger: zap.NewNop(),
+	}
+	sentHeartbeats := func() []statusNotification {
+		notifier.mu.Lock()
+		defer notifier.mu.Unlock()
+		var heartbeats []statusNotification
+		for _, notification := range notifier.notifications {
+			if notification.Heartbeat {
+				heartbeats = append(heartbeats, notification)
+			}
+		}
+		return heartbeats
+	}
+	require.NoError(t, r.Start(context.Background(), componenttest.NewNopHost()))
+
+	// The heartbeats are sent while no object makes progress, which they tell.
+	require.Eventually(t, func() bool {
+		heartbeats := sentHeartbeats()
+		return len(heartbeats) >= 2 && heartbeats[len(heartbeats)-1].IngestStatus == ingestStatusIngesting
+	}, time.Second, 5*time.Millisecond)
+	for _, heartbeat := range sentHeartbeats() {
+		require.True(t, heartbeat.LastProgress.IsZero())
+	}
+	close(release)
+	<-stopped
+
+	// They are still sent once the objects have been read.
+	require.Eventually(t, func() bool {
+		heartbeats := sentHeartbeats()
+		return heartbeats[len(heartbeats)-1].IngestStatus == ingestStatusCompleted
+	}, time.Second, 5*time.Millisecond)
+	heartbeats := sentHeartbeats()
+	last := heartbeats[len(heartbeats)-1]
+	require.Equal(t, int64(6), last.ObjectsProcessed)
+	require.False(t, last.LastProgress.IsZero())
+	require.NoError(t, r.Shutdown(context.Background()))
+}
+
+func Test_awss3Receiver_Commands(t *testing.T) {
+	stopped := make(chan struct{})
+	notifier := &mockStatusNotifier{}
+	keys, next := make(chan string), make(chan struct{})
+	r := &awss3Receiver{
+		reader:        newCheckpointTestReader(),
+		telemetryType: "traces",
+		dataProcessor: func(ctx context.Context, key string, _ []byte) error {
+			select {
+			case keys <- key[strings.Index(key, "minute="):]:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+			select {
+			case <-next:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		},
+		passes:   1,
+		notifier: notifier,
+		completion: &completionGroup{pending: 1, stop: func() error {
+			close(stopped)
+			return nil
+		}},
+		logger: zap.NewNop(),
+	}
+	lastNotification := func() statusNotification {
+		notifier.mu.Lock()
+		defer notifier.mu.Unlock()
+		return notifier.notifications[len(notifier.notifications)-1]
+	}
+	require.NoError(t, r.Start(context.Background(), componenttest.NewNopHost()))
+	require.Equal(t, "minute=32/traces_1", <-keys)
+
+	// The next object is not retrieved until the read is resumed.
+	r.handleCommand(context.Background(), ingestCommand{Command: commandPause, CommandID: "1"})
+	next <- struct{}{}
+	select {
+	case key := <-keys:
+		require.Fail(t, "retrieved an object while paused", key)
+	case <-time.After(50 * time.Millisecond):
+	}
+	notification := lastNotification()
+	require.Equal(t, ingestStatusPaused, notification.IngestStatus)
+	require.Equal(t, commandPause, notification.Command)
+	require.Equal(t, "1", notification.CommandID)
+	require.Empty(t, notification.CommandError)
+
+	// The commands to the other telemetry types are ignored.
+	r.handleCommand(context.Background(), ingestCommand{Command: commandResume, TelemetryTypes: []string{"logs"}})
+	require.Equal(t, notification, lastNotification())
+
+	r.handleCommand(context.Background(), ingestCommand{Command: commandResume, TelemetryTypes: []string{"logs", "traces"}, CommandID: "2"})
+	require.Equal(t, "minute=32/traces_2", <-keys)
+	notification = lastNotification()
+	require.Equal(t, ingestStatusIngesting, notification.IngestStatus)
+	require.Equal(t, "2", notification.CommandID)
+
+	r.handleCommand(context.Background(), ingestCommand{Command: "stop", CommandID: "3"})
+	require.Equal(t, "unknown command \"stop\", expected one of 'pause', 'resume', 'cancel' or 'submit'", lastNotification().CommandError)
+
+	// The cancel command is acknowledged once the read has stopped, with the
+	// resume token.
+	r.handleCommand(context.Background(), ingestCommand{Command: commandCancel, CommandID: "4"})
+	<-stopped
+	require.NoError(t, r.Shutdown(context.Background()))
+	notification = lastNotification()
+	require.Equal(t, ingestStatusCancelled, notification.IngestStatus)
+	require.Equal(t, commandCancel, notification.Command)
+	require.Equal(t, "4", notification.CommandID)
+	require.NotEmpty(t, notification.ResumeToken)
+	require.Equal(t, int64(1), notification.ObjectsProcessed)
+	require.Zero(t, notification.Failures)
+
+	// The commands are rejected once the objects are no longer read.
+	r.handleCommand(context.Background(), ingestCommand{Command: commandPause, CommandID: "5"})
+	notification = lastNotification()
+	require.Equal(t, ingestStatusCancelled, notification.IngestStatus)
+	require.Equal(t, "5", notification.CommandID)
+	require.Equal(t, "the objects are not being read", notification.CommandError)
+	require.False(t, r.control.paused())
+}
+
+func Test_awss3Receiver_Jobs(t *testing.T) {
+	notifier := &mockStatusNotifier{}
+	var mu sync.Mutex
+	var keys []string
+	r := &awss3Receiver{
+		telemetryType: "traces",
+		dataProcessor: func(_ context.Context, key string, _ []byte) error {
+			mu.Lock()
+			defer mu.Unlock()
+			keys = append(keys, key[strings.Index(key, "minute="):])
+			return nil
+		},
+		passes:   1,
+		notifier: notifier,
+		jobs:     newJobQueue(),
+		newJobReader: func(_ context.Context, job ingestJob) (telemetryReader, error) {
+			if job.ID == "unreadable" {
+				return nil, errors.New("failed to load the AWS configuration")
+			}
+			reader := newCheckpointTestReader()
+			reader.startTime, _ = parseTime(job.StartTime, "starttime")
+			reader.endTime, _ = parseTime(job.EndTime, "endtime")
+			return reader, nil
+		},
+		logger: zap.NewNop(),
+	}
+	notifications := func() []statusNotification {
+		notifier.mu.Lock()
+		defer notifier.mu.Unlock()
+		return slices.Clone(notifier.notifications)
+	}
+	require.NoError(t, r.Start(context.Background(), componenttest.NewNopHost()))
+
+	// The jobs are read one after the other, in submission order.
+	r.handleCommand(context.Background(), ingestCommand{Command: commandSubmit, CommandID: "1", StartTime: "2021-02-01 17:33", EndTime: "2021-02-01 17:34"})
+	r.handleCommand(context.Background(), ingestCommand{Command: commandSubmit, CommandID: "unreadable", StartTime: "2021-02-01 17:33", EndTime: "2021-02-01 17:34"})
+	r.handleCommand(context.Background(), ingestCommand{Command: commandSubmit, CommandID: "2", StartTime: "2021-02-01 17:32", EndTime: "2021-02-01 17:33"})
+	r.handleCommand(context.Background(), ingestCommand{Command: commandSubmit, CommandID: "3", StartTime: "2021-02-01 17:32"})
+	require.Eventually(t, func() bool {
+		var completed []string
+		for _, notification := range notifications() {
+			if notification.IngestStatus == ingestStatusCompleted && notification.Command == "" {
+				completed = append(completed, notification.JobID)
+			}
+		}
+		return slices.Equal(completed, []string{"1", "2"})
+	}, 5*time.Second, 10*time.Millisecond)
+	require.NoError(t, r.Shutdown(context.Background()))
+	require.Equal(t, []string{"minute=33/traces_1", "minute=33/traces_2", "minute=32/traces_1", "minute=32/traces_2"}, keys)
+
+	var acknowledged, rejected []string
+	var failed []statusNotification
+	for _, notification := range notifications() {
+		switch {
+		case notification.Command == commandSubmit && notification.CommandError == "":
+			acknowledged = append(acknowledged, notification.CommandID)
+		case notification.Command == commandSubmit:
+			rejected = append(rejected, notification.CommandID+": "+notification.CommandError)
+		case notification.IngestStatus == ingestStatusFailed:
+			failed = append(failed, notification)
+		case notification.JobID == "2":
+			require.Equal(t, testTime, notification.StartTime)
+			require.Equal(t, testTime.Add(time.Minute), notification.EndTime)
+		}
+	}
+	require.Equal(t, []string{"1", "unreadable", "2"}, acknowledged)
+	require.Equal(t, []string{"3: the starttime and endtime of the job are required"}, rejected)
+	require.Len(t, failed, 1)
+	require.Equal(t, "unreadable", failed[0].JobID)
+	require.Equal(t, "failed to load the AWS configuration", failed[0].FailureMessage)
+
+	// The jobs are rejected unless accepted by the configuration.
+	r = &awss3Receiver{telemetryType: "traces", notifier: notifier, logger: zap.NewNop()}
+	r.handleCommand(context.Background(), ingestCommand{Command: commandSubmit, CommandID: "4", StartTime: "2021-02-01 17:32", EndTime: "2021-02-01 17:33"})
+	notification := notifications()[len(notifications())-1]
+	require.Equal(t, ingestStatusIdle, notification.IngestStatus)
+	require.Equal(t, "the receiver does not accept jobs, see notifications jobs", notification.CommandError)
+}
+
+func Test_newAWSS3Receiver_Jobs(t *testing.T) {
+	opampID := component.MustNewID("opamp")
+	cfg := createDefaultConfig().(*Config)
+	cfg.S3Downloader.S3Bucket = "abucket"
+	cfg.Notifications = NotificationsConfig{OpAMP: &opampID, Jobs: true}
+	cfg.Logs.StartTime = "2024-01-01"
+	cfg.EndTime = "2024-01-02"
+	// Without starttime, only the time ranges of the jobs are read.
+	r, err := newAWSS3TraceReceiver(context.Background(), cfg, &consumertest.TracesSink{}, receivertest.NewNopCreateSettings())
+	require.NoError(t, err)
+	require.Nil(t, r.reader)
+	require.NotNil(t, r.jobs)
+	reader, err := r.newJobReader(context.Background(), ingestJob{StartTime: "2024-02-01", EndTime: "2024-02-02 12:00"})
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC), reader.(*s3Reader).startTime)
+	require.Equal(t, time.Date(2024, 2, 2, 12, 0, 0, 0, time.UTC), reader.(*s3Reader).endTime)
+
+	// The job readers ignore the starttime of the telemetry type.
+	logs, err := newAWSS3LogsReceiver(context.Background(), cfg, &consumertest.LogsSink{}, receivertest.NewNopCreateSettings())
+	require.NoError(t, err)
+	require.NotNil(t, logs.reader)
+	reader, err = logs.newJobReader(context.Background(), ingestJob{StartTime: "2024-02-01", EndTime: "2024-02-02"})
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC), reader.(*s3Reader).startTime)
+}
+
+type mockTracesEncoding struct {
+	component.StartFunc
+	component.ShutdownFunc
+}
+
+func (mockTracesEncoding) UnmarshalTraces(_ []byte) (ptrace.Traces, error) {
+	return generateTraceData(), nil
+}
+
+func Test_awss3Receiver_Encoding(t *testing.T) {
+	encodingID := component.MustNewID("encoding")
+	cfg := createDefaultConfig().(*Config)
+	cfg.S3Downloader.S3Bucket = "abucket"
+	cfg.StartTime = "2024-01-01"
+	cfg.EndTime = "2024-01-02"
+	cfg.Traces.Encoding = &encodingID
+	sink := &consumertest.TracesSink{}
+	r, err := newAWSS3TraceReceiver(context.Background(), cfg, sink, receivertest.NewNopCreateSettings())
+	require.NoError(t, err)
+	r.reader = mockTelemetryReader(func(ctx context.Context, _ string, _ s3ReaderDataCallback) error {
+		<-ctx.Done()
+		return nil
+	})
+
+	host := mockHost{Host: componenttest.NewNopHost()}
+	require.EqualError(t, r.Start(context.Background(), host), `unknown encoding "encoding"`)
+
+	host.extensions = map[component.ID]component.Component{encodingID: struct {
+		component.StartFunc
+		component.ShutdownFunc
+	}{}}
+	require.EqualError(t, r.Start(context.Background(), host), `encoding "encoding" cannot unmarshal traces`)
+
+	host.extensions = map[component.ID]component.Component{encodingID: mockTracesEncoding{}}
+	require.NoError(t, r.Start(context.Background(), host))
+	require.NoError(t, r.receiveObject(context.Background(), "traces_1.txt.gz", bytes.NewReader(gzipCompress([]byte("spans")))))
+	require.NoError(t, r.Shutdown(context.Background()))
+	require.Equal(t, 1, sink.SpanCount())
+}
+
+func Test_awss3Receiver_Disabled(t *testing.T) {
+	disabled := false
+	cfg := createDefaultConfig().(*Config)
+	cfg.S3Downloader.S3Bucket = "abucket"
+	cfg.StartTime = "2024-01-01"
+	cfg.EndTime = "2024-01-02"
+	cfg.Logs.Enabled = &disabled
+	r, err := newAWSS3LogsReceiver(context.Background(), cfg, &consumertest.LogsSink{}, receivertest.NewNopCreateSettings())
+	require.NoError(t, err)
+	require.Nil(t, r.reader)
+	require.NoError(t, r.Start(context.Background(), componenttest.NewNopHost()))
+	require.NoError(t, r.Shutdown(context.Background()))
+}
+
+func Test_newAWSS3TraceReceiver_LoadError(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.S3Downloader.S3Bucket = "abucket"
+	cfg.StartTime = "2024-01-01"
+	cfg.EndTime = "2024-01-02"
+	cfg.S3Downloader.Credentials = &S3CredentialsConfig{
+		Profile:                "missing",
+		SharedCredentialsFiles: []string{filepath.Join(t.TempDir(), "credentials")},
+		SharedConfigFiles:      []string{filepath.Join(t.TempDir(), "config")},
+	}
+	_, err := newAWSS3TraceReceiver(context.Background(), cfg, &consumertest.TracesSink{}, receivertest.NewNopCreateSettings())
+	require.ErrorContains(t, err, "unable to load SDK config: ")
+}
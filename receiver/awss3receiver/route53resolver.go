@@ -0,0 +1,85 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	conventions "go.opentelemetry.io/collector/semconv/v1.25.0"
+	"go.uber.org/zap"
+)
+
+// route53ResolverDecoder decodes Route 53 Resolver query logs, holding a JSON
+// document per query, into a log record per query. The records are grouped by
+// the account and region of their queries.
+type route53ResolverDecoder struct {
+	logger *zap.Logger
+}
+
+func (d *route53ResolverDecoder) decodeLogs(info objectInfo, data []byte) (plog.Logs, error) {
+	logs := newCloudResourceLogs()
+	for index, line := range bytes.Split(data, []byte("\n")) {
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+		document, err := parseJSONDocument(line)
+		query, isObject := document.(map[string]any)
+		if err != nil || !isObject {
+			d.logger.Warn("Skipping invalid Route 53 Resolver query log", zap.String("key", info.key), zap.Int("line", index+1), zap.Error(err))
+			continue
+		}
+		record := appendObjectRecord(logs.records(stringField(query, "account_id"), stringField(query, "region")), info)
+		if err := setRoute53ResolverRecord(record, query); err != nil {
+			d.logger.Warn("Unable to parse the time of a Route 53 Resolver query", zap.String("key", info.key), zap.Int("line", index+1), zap.Error(err))
+		}
+	}
+	return logs.logs, nil
+}
+
+// setRoute53ResolverRecord sets the body, attributes and timestamp of a record
+// from a query, the body being the whole query log.
+func setRoute53ResolverRecord(record plog.LogRecord, query map[string]any) error {
+	attributes := record.Attributes()
+	putStringField(attributes, conventions.AttributeDNSQuestionName, query, "query_name")
+	putStringField(attributes, "aws.route53_resolver.query_type", query, "query_type")
+	putStringField(attributes, "aws.route53_resolver.query_class", query, "query_class")
+	putStringField(attributes, "aws.route53_resolver.rcode", query, "rcode")
+	putStringField(attributes, "aws.route53_resolver.vpc_id", query, "vpc_id")
+	putStringField(attributes, conventions.AttributeSourceAddress, query, "srcaddr")
+	if port, err := strconv.ParseInt(stringField(query, "srcport"), 10, 64); err == nil {
+		attributes.PutInt(conventions.AttributeSourcePort, port)
+	}
+	if transport := stringField(query, "transport"); transport != "" {
+		attributes.PutStr(conventions.AttributeNetworkTransport, strings.ToLower(transport))
+	}
+	if answers, ok := query["answers"].([]any); ok {
+		data := attributes.PutEmptySlice("aws.route53_resolver.answers")
+		for _, answer := range answers {
+			if answer, ok := answer.(map[string]any); ok {
+				data.AppendEmpty().SetStr(stringField(answer, "Rdata"))
+			}
+		}
+	}
+	if sources, ok := query["srcids"].(map[string]any); ok {
+		putStringField(attributes, conventions.AttributeHostID, sources, "instance")
+		putStringField(attributes, "aws.route53_resolver.resolver_endpoint", sources, "resolver_endpoint")
+	}
+	_ = record.Body().FromRaw(query)
+	queryTime := stringField(query, "query_timestamp")
+	if queryTime == "" {
+		return nil
+	}
+	timestamp, err := time.Parse(time.RFC3339Nano, queryTime)
+	if err != nil {
+		return err
+	}
+	record.SetTimestamp(pcommon.NewTimestampFromTime(timestamp))
+	return nil
+}
@@ -0,0 +1,52 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func Test_route53ResolverDecoder(t *testing.T) {
+	info := objectInfo{bucket: "bucket", key: "AWSLogs/123456789012/vpcdnsquerylogs/vpc-0123/2021/02/01/queries.log"}
+	data := []byte(`{"version":"1.100000","account_id":"123456789012","region":"us-east-1","vpc_id":"vpc-0123","query_timestamp":"2021-02-01T17:00:00Z","query_name":"example.com.","query_type":"A","query_class":"IN","rcode":"NOERROR","answers":[{"Rdata":"192.0.2.44","Type":"A","Class":"IN"}],"srcaddr":"10.0.0.12","srcport":"54321","transport":"UDP","srcids":{"instance":"i-0123456789abcdef0"}}
+not json
+{"account_id":"123456789012","region":"eu-west-1","query_timestamp":"2021-02-01T17:01:00Z","query_name":"missing.example.com.","rcode":"NXDOMAIN","answers":[],"srcids":{"resolver_endpoint":"rslvr-in-0123"}}
+`)
+
+	logs, err := (&route53ResolverDecoder{logger: zap.NewNop()}).decodeLogs(info, data)
+	require.NoError(t, err)
+	require.Equal(t, 2, logs.ResourceLogs().Len())
+	require.Equal(t, map[string]any{
+		"cloud.provider":   "aws",
+		"cloud.account.id": "123456789012",
+		"cloud.region":     "us-east-1",
+	}, logs.ResourceLogs().At(0).Resource().Attributes().AsRaw())
+
+	record := logs.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0)
+	require.Equal(t, time.Date(2021, 2, 1, 17, 0, 0, 0, time.UTC), record.Timestamp().AsTime())
+	require.Equal(t, "example.com.", record.Body().Map().AsRaw()["query_name"])
+	require.Equal(t, map[string]any{
+		"aws.s3.bucket":                    "bucket",
+		"aws.s3.key":                       info.key,
+		"dns.question.name":                "example.com.",
+		"aws.route53_resolver.query_type":  "A",
+		"aws.route53_resolver.query_class": "IN",
+		"aws.route53_resolver.rcode":       "NOERROR",
+		"aws.route53_resolver.vpc_id":      "vpc-0123",
+		"aws.route53_resolver.answers":     []any{"192.0.2.44"},
+		"source.address":                   "10.0.0.12",
+		"source.port":                      int64(54321),
+		"network.transport":                "udp",
+		"host.id":                          "i-0123456789abcdef0",
+	}, record.Attributes().AsRaw())
+
+	record = logs.ResourceLogs().At(1).ScopeLogs().At(0).LogRecords().At(0)
+	require.Equal(t, "NXDOMAIN", record.Attributes().AsRaw()["aws.route53_resolver.rcode"])
+	require.Equal(t, "rslvr-in-0123", record.Attributes().AsRaw()["aws.route53_resolver.resolver_endpoint"])
+	require.Equal(t, []any{}, record.Attributes().AsRaw()["aws.route53_resolver.answers"])
+}
@@ -0,0 +1,106 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	conventions "go.opentelemetry.io/collector/semconv/v1.25.0"
+	"go.uber.org/zap"
+)
+
+// s3AccessLogTimeLayout is the layout of the times of the S3 server access logs.
+const s3AccessLogTimeLayout = "02/Jan/2006:15:04:05 -0700"
+
+// s3AccessLogFields are the fields of the S3 server access log entries, in order.
+// S3 may append fields to its entries over time, so entries with more fields
+// than known are accepted.
+var s3AccessLogFields = []string{
+	"bucket_owner", "bucket", "time", "remote_ip", "requester", "request_id", "operation", "key", "request_uri",
+	"http_status", "error_code", "bytes_sent", "object_size", "total_time", "turn_around_time", "referer",
+	"user_agent", "version_id", "host_id", "signature_version", "cipher_suite", "authentication_type", "host_header",
+	"tls_version", "access_point_arn", "acl_required",
+}
+
+// s3AccessLogAttributes are the semantic convention attributes of the S3 server
+// access log fields that have one. The other fields are set as
+// aws.s3.access_log.<field>.
+var s3AccessLogAttributes = map[string]string{
+	"remote_ip":    conventions.AttributeClientAddress,
+	"requester":    conventions.AttributeEnduserID,
+	"request_id":   conventions.AttributeAWSRequestID,
+	"user_agent":   conventions.AttributeUserAgentOriginal,
+	"cipher_suite": conventions.AttributeTLSCipher,
+	"host_header":  conventions.AttributeServerAddress,
+}
+
+// s3AccessLogsDecoder decodes the server access logs S3 writes about the requests
+// made to a bucket into a log record per request.
+type s3AccessLogsDecoder struct {
+	logger *zap.Logger
+}
+
+func (d *s3AccessLogsDecoder) decodeLogs(info objectInfo, data []byte) (plog.Logs, error) {
+	logs, records := newObjectLogs()
+	for index, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		values := splitLogFields(line)
+		if len(values) < 3 {
+			d.logger.Warn("Skipping invalid S3 access log entry", zap.String("key", info.key), zap.Int("line", index+1))
+			continue
+		}
+		timestamp, err := time.Parse(s3AccessLogTimeLayout, values[2])
+		if err != nil {
+			d.logger.Warn("Skipping invalid S3 access log entry", zap.String("key", info.key), zap.Int("line", index+1), zap.Error(err))
+			continue
+		}
+		record := appendObjectRecord(records, info)
+		record.SetTimestamp(pcommon.NewTimestampFromTime(timestamp))
+		record.Body().SetStr(line)
+		for i, value := range values {
+			if i >= len(s3AccessLogFields) {
+				break
+			}
+			if value == "-" || value == "" {
+				continue
+			}
+			setS3AccessLogField(record.Attributes(), s3AccessLogFields[i], value)
+		}
+	}
+	return logs, nil
+}
+
+// setS3AccessLogField sets the attribute of a field of an entry.
+func setS3AccessLogField(attributes pcommon.Map, name, value string) {
+	switch name {
+	case "time":
+		return
+	case "request_uri":
+		if putHTTPRequestAttributes(attributes, value) {
+			return
+		}
+	case "http_status":
+		if code, err := strconv.ParseInt(value, 10, 64); err == nil {
+			attributes.PutInt(conventions.AttributeHTTPResponseStatusCode, code)
+			return
+		}
+	case "bytes_sent", "object_size", "total_time", "turn_around_time":
+		if integer, err := strconv.ParseInt(value, 10, 64); err == nil {
+			attributes.PutInt("aws.s3.access_log."+name, integer)
+			return
+		}
+	}
+	if attribute, ok := s3AccessLogAttributes[name]; ok {
+		attributes.PutStr(attribute, value)
+		return
+	}
+	attributes.PutStr("aws.s3.access_log."+name, value)
+}
@@ -0,0 +1,57 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func Test_s3AccessLogsDecoder(t *testing.T) {
+	info := objectInfo{bucket: "logs-bucket", key: "access/2021-02-01-17-32-10-A1B2C3D4E5F6"}
+	entry := `79a59df900b949e55d96a1e698fbacedfd6e09d98eacf8f8d5218e7cd47ef2be awsexamplebucket1 [01/Feb/2021:17:32:00 +0000] ` +
+		`192.0.2.3 arn:aws:iam::123456789012:user/alice 3E57427F3EXAMPLE REST.GET.OBJECT photos/cat.jpg ` +
+		`"GET /awsexamplebucket1/photos/cat.jpg?versionId=1 HTTP/1.1" 200 - 113 113 70 69 "-" "S3Console/0.4" - ` +
+		`s9lzHYrFp76ZVxRcpX9+5cjAnEH2ROuNkd2BHfIa6UkFVdtjf5mKR3/eTPFvsiP/XV/VLi31234= SigV4 ECDHE-RSA-AES128-GCM-SHA256 ` +
+		`AuthHeader awsexamplebucket1.s3.us-west-1.amazonaws.com TLSv1.2 - -`
+	data := []byte(entry + "\n" + "owner bucket [invalid time] 192.0.2.3\n")
+
+	logs, err := (&s3AccessLogsDecoder{logger: zap.NewNop()}).decodeLogs(info, data)
+	require.NoError(t, err)
+	records := logs.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords()
+	require.Equal(t, 1, records.Len())
+	require.Equal(t, entry, records.At(0).Body().Str())
+	require.Equal(t, time.Date(2021, 2, 1, 17, 32, 0, 0, time.UTC), records.At(0).Timestamp().AsTime().UTC())
+	require.Equal(t, map[string]any{
+		"aws.s3.bucket":                         "logs-bucket",
+		"aws.s3.key":                            info.key,
+		"aws.s3.access_log.bucket_owner":        "79a59df900b949e55d96a1e698fbacedfd6e09d98eacf8f8d5218e7cd47ef2be",
+		"aws.s3.access_log.bucket":              "awsexamplebucket1",
+		"client.address":                        "192.0.2.3",
+		"enduser.id":                            "arn:aws:iam::123456789012:user/alice",
+		"aws.request_id":                        "3E57427F3EXAMPLE",
+		"aws.s3.access_log.operation":           "REST.GET.OBJECT",
+		"aws.s3.access_log.key":                 "photos/cat.jpg",
+		"http.request.method":                   "GET",
+		"url.path":                              "/awsexamplebucket1/photos/cat.jpg",
+		"url.query":                             "versionId=1",
+		"network.protocol.name":                 "http",
+		"network.protocol.version":              "1.1",
+		"http.response.status_code":             int64(200),
+		"aws.s3.access_log.bytes_sent":          int64(113),
+		"aws.s3.access_log.object_size":         int64(113),
+		"aws.s3.access_log.total_time":          int64(70),
+		"aws.s3.access_log.turn_around_time":    int64(69),
+		"user_agent.original":                   "S3Console/0.4",
+		"aws.s3.access_log.host_id":             "s9lzHYrFp76ZVxRcpX9+5cjAnEH2ROuNkd2BHfIa6UkFVdtjf5mKR3/eTPFvsiP/XV/VLi31234=",
+		"aws.s3.access_log.signature_version":   "SigV4",
+		"tls.cipher":                            "ECDHE-RSA-AES128-GCM-SHA256",
+		"aws.s3.access_log.authentication_type": "AuthHeader",
+		"server.address":                        "awsexamplebucket1.s3.us-west-1.amazonaws.com",
+		"aws.s3.access_log.tls_version":         "TLSv1.2",
+	}, records.At(0).Attributes().AsRaw())
+}
@@ -0,0 +1,53 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/smithy-go/middleware"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+)
+
+// expectedBucketOwnerHeader is the header of the requests to S3 telling the
+// account the bucket must belong to.
+const expectedBucketOwnerHeader = "X-Amz-Expected-Bucket-Owner"
+
+// isAccountID tells whether id is an AWS account ID, 12 digits.
+func isAccountID(id string) bool {
+	if len(id) != 12 {
+		return false
+	}
+	for _, c := range id {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// expectedBucketOwnerOptions returns the options of the S3 client sending owner
+// as the expected owner of the bucket of each request, which S3 denies with a
+// 403 error if the bucket belongs to another account, such as a bucket deleted
+// and created again by another account under the same name.
+func expectedBucketOwnerOptions(owner string) func(*s3.Options) {
+	return func(o *s3.Options) {
+		o.APIOptions = append(o.APIOptions, expectedBucketOwnerMiddleware(owner))
+	}
+}
+
+// expectedBucketOwnerMiddleware returns the API option setting the expected
+// owner on the requests of all the operations, before they are signed.
+func expectedBucketOwnerMiddleware(owner string) func(*middleware.Stack) error {
+	return func(stack *middleware.Stack) error {
+		set := middleware.BuildMiddlewareFunc("ExpectedBucketOwner", func(ctx context.Context, in middleware.BuildInput, next middleware.BuildHandler) (middleware.BuildOutput, middleware.Metadata, error) {
+			if req, ok := in.Request.(*smithyhttp.Request); ok {
+				req.Header.Set(expectedBucketOwnerHeader, owner)
+			}
+			return next.HandleBuild(ctx, in)
+		})
+		return stack.Build.Add(set, middleware.After)
+	}
+}
@@ -0,0 +1,75 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func Test_newS3Client_ExpectedBucketOwner(t *testing.T) {
+	var mu sync.Mutex
+	owners := map[string]string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		owners[r.Method+" "+r.URL.Path] = r.Header.Get(expectedBucketOwnerHeader)
+		mu.Unlock()
+		if r.URL.Query().Get("list-type") == "2" {
+			writeListing("otel/year=2024/traces_1")(w)
+		}
+	}))
+	defer server.Close()
+
+	cfg := startupCheckConfig(server.URL)
+	cfg.ExpectedBucketOwner = "123456789012"
+	listObjectsClient, getObjectClient, err := newS3Client(context.Background(), cfg)
+	require.NoError(t, err)
+	_, err = listObjectsClient.NewListObjectsV2Paginator(&s3.ListObjectsV2Input{Bucket: aws.String("bucket")}).NextPage(context.Background())
+	require.NoError(t, err)
+	_, err = getObjectClient.GetObject(context.Background(), &s3.GetObjectInput{Bucket: aws.String("bucket"), Key: aws.String("otel/traces_1")})
+	require.NoError(t, err)
+	checker, err := newS3AccessChecker(cfg, listObjectsClient, getObjectClient)
+	require.NoError(t, err)
+	require.NoError(t, checker.check(context.Background(), zap.NewNop()))
+	require.Equal(t, map[string]string{
+		"GET /bucket":                          "123456789012",
+		"GET /bucket/otel/traces_1":            "123456789012",
+		"HEAD /bucket":                         "123456789012",
+		"HEAD /bucket/otel/year=2024/traces_1": "123456789012",
+	}, owners)
+
+	// Without expected_bucket_owner, the requests do not tell the owner.
+	cfg.ExpectedBucketOwner = ""
+	_, getObjectClient, err = newS3Client(context.Background(), cfg)
+	require.NoError(t, err)
+	_, err = getObjectClient.GetObject(context.Background(), &s3.GetObjectInput{Bucket: aws.String("bucket"), Key: aws.String("otel/traces_1")})
+	require.NoError(t, err)
+	require.Equal(t, "", owners["GET /bucket/otel/traces_1"])
+}
+
+func Test_s3AccessChecker_ExpectedBucketOwner(t *testing.T) {
+	cfg := startupCheckConfig(newStartupCheckServer(t, startupCheckResponses{headBucket: writeStatus(http.StatusForbidden)}).URL)
+	cfg.ExpectedBucketOwner = "123456789012"
+	listObjectsClient, getObjectClient, err := newS3Client(context.Background(), cfg)
+	require.NoError(t, err)
+	checker, err := newS3AccessChecker(cfg, listObjectsClient, getObjectClient)
+	require.NoError(t, err)
+	require.ErrorContains(t, checker.check(context.Background(), zap.NewNop()),
+		"HeadBucket of bucket bucket was denied, the bucket may not belong to account 123456789012 or the credentials need the s3:ListBucket permission: ")
+}
+
+func Test_isAccountID(t *testing.T) {
+	require.True(t, isAccountID("123456789012"))
+	require.False(t, isAccountID("12345678901"))
+	require.False(t, isAccountID("12345678901a"))
+	require.False(t, isAccountID("arn:aws:iam::123456789012:root"))
+}
@@ -0,0 +1,66 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	"golang.org/x/net/http/httpproxy"
+)
+
+// newHTTPClient returns the HTTP client of the requests to S3, the settings of
+// cfg overriding the defaults of the AWS SDK.
+func newHTTPClient(ctx context.Context, cfg S3HTTPClientConfig) (*awshttp.BuildableClient, error) {
+	client := awshttp.NewBuildableClient()
+	if cfg.Timeout > 0 {
+		client = client.WithTimeout(cfg.Timeout)
+	}
+	if cfg.ConnectTimeout > 0 {
+		client = client.WithDialerOptions(func(d *net.Dialer) {
+			d.Timeout = cfg.ConnectTimeout
+		})
+	}
+	var proxy func(*http.Request) (*url.URL, error)
+	if cfg.ProxyURL != "" {
+		proxyFunc := (&httpproxy.Config{HTTPProxy: cfg.ProxyURL, HTTPSProxy: cfg.ProxyURL, NoProxy: cfg.NoProxy}).ProxyFunc()
+		proxy = func(r *http.Request) (*url.URL, error) {
+			return proxyFunc(r.URL)
+		}
+	}
+	if cfg.TLS != nil {
+		tlsCfg, err := cfg.TLS.LoadTLSConfig(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("unable to load the TLS settings of http_client: %w", err)
+		}
+		if tlsCfg != nil {
+			client = client.WithTransportOptions(func(t *http.Transport) {
+				t.TLSClientConfig = tlsCfg
+			})
+		}
+	}
+	return client.WithTransportOptions(func(t *http.Transport) {
+		if cfg.ResponseHeaderTimeout > 0 {
+			t.ResponseHeaderTimeout = cfg.ResponseHeaderTimeout
+		}
+		if cfg.IdleConnTimeout > 0 {
+			t.IdleConnTimeout = cfg.IdleConnTimeout
+		}
+		if cfg.MaxIdleConnsPerHost > 0 {
+			t.MaxIdleConnsPerHost = cfg.MaxIdleConnsPerHost
+			// The pool of idle connections to all the hosts is not smaller than the
+			// one of each host.
+			t.MaxIdleConns = max(t.MaxIdleConns, cfg.MaxIdleConnsPerHost)
+		}
+		t.MaxConnsPerHost = cfg.MaxConnsPerHost
+		t.DisableKeepAlives = cfg.DisableKeepAlives
+		if proxy != nil {
+			t.Proxy = proxy
+		}
+	}), nil
+}
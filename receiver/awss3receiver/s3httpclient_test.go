@@ -0,0 +1,130 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"context"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/config/configtls"
+)
+
+func Test_newHTTPClient(t *testing.T) {
+	client, err := newHTTPClient(context.Background(), S3HTTPClientConfig{})
+	require.NoError(t, err)
+	require.Zero(t, client.GetTimeout())
+	require.Equal(t, awshttp.DefaultDialConnectTimeout, client.GetDialer().Timeout)
+	require.Equal(t, awshttp.DefaultHTTPTransportMaxIdleConnsPerHost, client.GetTransport().MaxIdleConnsPerHost)
+	require.Equal(t, awshttp.DefaultHTTPTransportIdleConnTimeout, client.GetTransport().IdleConnTimeout)
+
+	client, err = newHTTPClient(context.Background(), S3HTTPClientConfig{
+		Timeout:               time.Minute,
+		ConnectTimeout:        5 * time.Second,
+		ResponseHeaderTimeout: 10 * time.Second,
+		IdleConnTimeout:       time.Minute,
+		MaxIdleConnsPerHost:   200,
+		MaxConnsPerHost:       300,
+		DisableKeepAlives:     true,
+	})
+	require.NoError(t, err)
+	require.Equal(t, time.Minute, client.GetTimeout())
+	require.Equal(t, 5*time.Second, client.GetDialer().Timeout)
+	transport := client.GetTransport()
+	require.Equal(t, 10*time.Second, transport.ResponseHeaderTimeout)
+	require.Equal(t, time.Minute, transport.IdleConnTimeout)
+	require.Equal(t, 200, transport.MaxIdleConnsPerHost)
+	require.Equal(t, 200, transport.MaxIdleConns)
+	require.Equal(t, 300, transport.MaxConnsPerHost)
+	require.True(t, transport.DisableKeepAlives)
+}
+
+func Test_newHTTPClient_ResponseHeaderTimeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		<-release
+		_, _ = w.Write([]byte("object"))
+	}))
+	defer server.Close()
+	defer close(release)
+
+	httpClient, err := newHTTPClient(context.Background(), S3HTTPClientConfig{ResponseHeaderTimeout: 50 * time.Millisecond})
+	require.NoError(t, err)
+	client := s3.New(s3.Options{
+		Region:           "us-east-1",
+		BaseEndpoint:     aws.String(server.URL),
+		UsePathStyle:     true,
+		Credentials:      aws.AnonymousCredentials{},
+		HTTPClient:       httpClient,
+		RetryMaxAttempts: 1,
+	})
+	// The hung request fails rather than being left open.
+	_, _, err = getObject(context.Background(), client, &s3.GetObjectInput{Bucket: aws.String("bucket"), Key: aws.String("key")})
+	require.ErrorContains(t, err, "timeout awaiting response headers")
+}
+
+func Test_newHTTPClient_Proxy(t *testing.T) {
+	var proxied []string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = append(proxied, r.URL.String())
+		_, _ = w.Write([]byte("object"))
+	}))
+	defer proxy.Close()
+
+	client, err := newHTTPClient(context.Background(), S3HTTPClientConfig{ProxyURL: proxy.URL, NoProxy: "internal.example.com"})
+	require.NoError(t, err)
+	transport := client.GetTransport()
+	defer transport.CloseIdleConnections()
+	request, err := http.NewRequest(http.MethodGet, "http://s3.example.com/bucket/key", nil)
+	require.NoError(t, err)
+	response, err := client.Do(request)
+	require.NoError(t, err)
+	require.NoError(t, response.Body.Close())
+	require.Equal(t, []string{"http://s3.example.com/bucket/key"}, proxied)
+
+	// The hosts of no_proxy are reached directly.
+	request, err = http.NewRequest(http.MethodGet, "http://internal.example.com/bucket/key", nil)
+	require.NoError(t, err)
+	proxyURL, err := transport.Proxy(request)
+	require.NoError(t, err)
+	require.Nil(t, proxyURL)
+}
+
+func Test_newHTTPClient_TLS(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("object"))
+	}))
+	defer server.Close()
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	require.NoError(t, os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}), 0o600))
+
+	get := func(cfg S3HTTPClientConfig) error {
+		client, err := newHTTPClient(context.Background(), cfg)
+		require.NoError(t, err)
+		defer client.GetTransport().CloseIdleConnections()
+		request, err := http.NewRequest(http.MethodGet, server.URL, nil)
+		require.NoError(t, err)
+		response, err := client.Do(request)
+		if err != nil {
+			return err
+		}
+		return response.Body.Close()
+	}
+	// The certificate of the server is signed by a private CA.
+	require.ErrorContains(t, get(S3HTTPClientConfig{}), "certificate")
+	require.NoError(t, get(S3HTTPClientConfig{TLS: &configtls.ClientConfig{Config: configtls.Config{CAFile: caFile}}}))
+	require.NoError(t, get(S3HTTPClientConfig{TLS: &configtls.ClientConfig{InsecureSkipVerify: true}}))
+
+	_, err := newHTTPClient(context.Background(), S3HTTPClientConfig{TLS: &configtls.ClientConfig{Config: configtls.Config{CAFile: filepath.Join(t.TempDir(), "missing.pem")}}})
+	require.ErrorContains(t, err, "unable to load the TLS settings of http_client")
+}
@@ -0,0 +1,43 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials/ec2rolecreds"
+	"github.com/aws/aws-sdk-go-v2/feature/ec2/imds"
+)
+
+// imdsLoadOptions returns the load options of the SDK configuration applying cfg
+// to the lookups of the credentials of the EC2 instance role.
+func imdsLoadOptions(cfg *S3IMDSConfig) []func(*config.LoadOptions) error {
+	if cfg == nil {
+		return nil
+	}
+	if cfg.Disabled {
+		return []func(*config.LoadOptions) error{config.WithEC2IMDSClientEnableState(imds.ClientDisabled)}
+	}
+	return []func(*config.LoadOptions) error{config.WithEC2RoleCredentialOptions(func(o *ec2rolecreds.Options) {
+		o.Client = newIMDSClient(*cfg)
+	})}
+}
+
+// newIMDSClient returns the client of the instance metadata service, the
+// settings of cfg overriding the defaults of the AWS SDK. Unlike the client of
+// the default chain, it does not share the HTTP client and the retry policy of
+// the requests to S3.
+func newIMDSClient(cfg S3IMDSConfig) *imds.Client {
+	return imds.New(imds.Options{Endpoint: cfg.Endpoint}, func(o *imds.Options) {
+		if cfg.Timeout > 0 {
+			o.HTTPClient = awshttp.NewBuildableClient().WithTimeout(cfg.Timeout)
+		}
+		if cfg.MaxAttempts > 0 {
+			o.Retryer = retry.NewStandard(func(so *retry.StandardOptions) {
+				so.MaxAttempts = cfg.MaxAttempts
+			})
+		}
+	})
+}
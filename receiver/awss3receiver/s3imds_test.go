@@ -0,0 +1,88 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// setTestIMDSEnv clears the environment of the credential sources preceding the
+// instance role in the default chain, and of the instance metadata service.
+func setTestIMDSEnv(t *testing.T) {
+	setTestCredentialsEnv(t, nil)
+	t.Setenv("AWS_CONFIG_FILE", filepath.Join(t.TempDir(), "config"))
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", filepath.Join(t.TempDir(), "credentials"))
+	t.Setenv("AWS_EC2_METADATA_DISABLED", "")
+	t.Setenv("AWS_EC2_METADATA_SERVICE_ENDPOINT", "")
+}
+
+func Test_loadAWSConfig_IMDS(t *testing.T) {
+	setTestIMDSEnv(t)
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		switch r.URL.Path {
+		case "/latest/api/token":
+			w.Header().Set("X-Aws-Ec2-Metadata-Token-Ttl-Seconds", "21600")
+			_, _ = w.Write([]byte("token"))
+		case "/latest/meta-data/iam/security-credentials/":
+			_, _ = w.Write([]byte("reader"))
+		case "/latest/meta-data/iam/security-credentials/reader":
+			_, _ = w.Write([]byte(`{"Code":"Success","AccessKeyId":"AKID","SecretAccessKey":"secret","Token":"session","Expiration":"` +
+				time.Now().Add(time.Hour).UTC().Format(time.RFC3339) + `"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	awsCfg, err := loadAWSConfig(context.Background(), S3DownloaderConfig{
+		Region: "us-east-1",
+		IMDS:   &S3IMDSConfig{Endpoint: server.URL, Timeout: time.Second, MaxAttempts: 1},
+	})
+	require.NoError(t, err)
+	credentials, err := awsCfg.Credentials.Retrieve(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "AKID", credentials.AccessKeyID)
+	require.Equal(t, "session", credentials.SessionToken)
+
+	// The instance metadata is not looked up once disabled.
+	requests.Store(0)
+	awsCfg, err = loadAWSConfig(context.Background(), S3DownloaderConfig{
+		Region: "us-east-1",
+		IMDS:   &S3IMDSConfig{Disabled: true, Endpoint: server.URL},
+	})
+	require.NoError(t, err)
+	_, err = awsCfg.Credentials.Retrieve(context.Background())
+	require.Error(t, err)
+	require.Zero(t, requests.Load())
+}
+
+func Test_newIMDSClient_Timeout(t *testing.T) {
+	setTestIMDSEnv(t)
+	var requests atomic.Int32
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		// The responses are dropped, as when the hop limit of the instance is exceeded.
+		requests.Add(1)
+		<-release
+	}))
+	defer server.Close()
+	defer close(release)
+
+	start := time.Now()
+	_, err := newIMDSClient(S3IMDSConfig{Endpoint: server.URL, Timeout: 50 * time.Millisecond, MaxAttempts: 2}).
+		GetMetadata(context.Background(), nil)
+	require.Error(t, err)
+	require.Less(t, time.Since(start), 5*time.Second)
+	require.NotZero(t, requests.Load())
+}
@@ -5,14 +5,20 @@ package awss3receiver // import "github.com/open-telemetry/opentelemetry-collect
 
 import (
 	"context"
-	"log"
+	"fmt"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
 	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
 	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 )
 
+// defaultS3CompatibleSigningRegion is the region requests to S3-compatible services
+// are signed for when no region is configured.
+const defaultS3CompatibleSigningRegion = "us-east-1"
+
 var downloadManager *manager.Downloader //nolint:golint,unused
 
 type ListObjectsV2Pager interface {
@@ -24,6 +30,15 @@ type ListObjectsAPI interface {
 	NewListObjectsV2Paginator(params *s3.ListObjectsV2Input) ListObjectsV2Pager
 }
 
+type ListObjectVersionsPager interface {
+	HasMorePages() bool
+	NextPage(context.Context, ...func(*s3.Options)) (*s3.ListObjectVersionsOutput, error)
+}
+
+type ListObjectVersionsAPI interface {
+	NewListObjectVersionsPaginator(params *s3.ListObjectVersionsInput) ListObjectVersionsPager
+}
+
 type GetObjectAPI interface {
 	GetObject(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error)
 }
@@ -33,37 +48,134 @@ type s3ListObjectsAPIImpl struct {
 }
 
 func newS3Client(ctx context.Context, cfg S3DownloaderConfig) (ListObjectsAPI, GetObjectAPI, error) {
-	optionsFuncs := make([]func(*config.LoadOptions) error, 0)
-	if cfg.Region != "" {
-		optionsFuncs = append(optionsFuncs, config.WithRegion(cfg.Region))
-	}
-
-	if cfg.Endpoint != "" {
-		customResolver := aws.EndpointResolverWithOptionsFunc(func(_, _ string, _ ...any) (aws.Endpoint, error) {
-			return aws.Endpoint{
-				PartitionID:   cfg.EndpointPartitionID,
-				URL:           cfg.Endpoint,
-				SigningRegion: cfg.Region,
-			}, nil
-		})
-		optionsFuncs = append(optionsFuncs, config.WithEndpointResolverWithOptions(customResolver))
-	}
-	awsCfg, err := config.LoadDefaultConfig(ctx, optionsFuncs...)
+	awsCfg, err := loadAWSConfig(ctx, cfg)
 	if err != nil {
-		log.Fatalf("unable to load SDK config, %v", err)
 		return nil, nil, err
 	}
+	s3Compatible := cfg.CompatibilityProfile == CompatibilityProfileS3Compatible
 	s3OptionFuncs := make([]func(options *s3.Options), 0)
+	if cfg.Endpoint != "" {
+		// The requests are signed for the region, and the buckets are addressed as
+		// subdomains of the endpoint unless path-style addressing is used.
+		s3OptionFuncs = append(s3OptionFuncs, func(o *s3.Options) {
+			o.BaseEndpoint = aws.String(cfg.Endpoint)
+		})
+	}
 	if cfg.S3ForcePathStyle {
 		s3OptionFuncs = append(s3OptionFuncs, func(o *s3.Options) {
 			o.UsePathStyle = true
 		})
 	}
+	if s3Compatible {
+		// Disable the features specific to Amazon S3 and address buckets by path,
+		// which S3-compatible services support most widely.
+		s3OptionFuncs = append(s3OptionFuncs, func(o *s3.Options) {
+			if o.Region == "" {
+				// S3-compatible services commonly ignore the region, but requests must
+				// still be signed for one.
+				o.Region = defaultS3CompatibleSigningRegion
+			}
+			o.UsePathStyle = true
+			o.UseAccelerate = false
+			o.UseDualstack = false
+			o.DisableMultiRegionAccessPoints = true
+			o.DisableS3ExpressSessionAuth = aws.Bool(true)
+		})
+	}
+	if arn.IsARN(cfg.S3Bucket) {
+		// Access point ARNs carry their region, which may differ from the configured one.
+		s3OptionFuncs = append(s3OptionFuncs, func(o *s3.Options) {
+			o.UseARNRegion = true
+		})
+	}
+	if cfg.RateLimit != nil {
+		s3OptionFuncs = append(s3OptionFuncs, rateLimitOptions(*cfg.RateLimit))
+	}
+	if cfg.Retry != nil {
+		s3OptionFuncs = append(s3OptionFuncs, retryOptions(*cfg.Retry))
+	}
+	if invalidator, ok := awsCfg.Credentials.(credentialsInvalidator); ok {
+		s3OptionFuncs = append(s3OptionFuncs, credentialsRefreshOptions(invalidator))
+	}
+	if cfg.ExpectedBucketOwner != "" {
+		s3OptionFuncs = append(s3OptionFuncs, expectedBucketOwnerOptions(cfg.ExpectedBucketOwner))
+	}
 	client := s3.NewFromConfig(awsCfg, s3OptionFuncs...)
 
 	return &s3ListObjectsAPIImpl{client: client}, client, nil
 }
 
+// loadAWSConfig loads the SDK configuration of the clients of cfg, with its
+// credentials.
+func loadAWSConfig(ctx context.Context, cfg S3DownloaderConfig) (aws.Config, error) {
+	optionsFuncs := make([]func(*config.LoadOptions) error, 0)
+	if cfg.Region != "" {
+		optionsFuncs = append(optionsFuncs, config.WithRegion(cfg.Region))
+	}
+	if cfg.UseFIPSEndpoint {
+		optionsFuncs = append(optionsFuncs, config.WithUseFIPSEndpoint(aws.FIPSEndpointStateEnabled))
+	}
+	if cfg.UseDualStackEndpoint {
+		optionsFuncs = append(optionsFuncs, config.WithUseDualStackEndpoint(aws.DualStackEndpointStateEnabled))
+	}
+	if cfg.HTTPClient != nil {
+		httpClient, err := newHTTPClient(ctx, *cfg.HTTPClient)
+		if err != nil {
+			return aws.Config{}, err
+		}
+		optionsFuncs = append(optionsFuncs, config.WithHTTPClient(httpClient))
+	}
+	optionsFuncs = append(optionsFuncs, credentialsLoadOptions(cfg.Credentials)...)
+	optionsFuncs = append(optionsFuncs, imdsLoadOptions(cfg.IMDS)...)
+	optionsFuncs = append(optionsFuncs, sdkLogLoadOptions(cfg.SDKLog, cfg.sdkLogger)...)
+	awsCfg, err := config.LoadDefaultConfig(ctx, optionsFuncs...)
+	if err != nil {
+		return aws.Config{}, fmt.Errorf("unable to load SDK config: %w", err)
+	}
+	if awsCfg.Region == "" {
+		// The requests are sent to the region of the bucket ARN, or else to the
+		// default region of the partition of the ARNs.
+		awsCfg.Region = inferredRegion(cfg)
+	}
+	provider, err := credentialsProvider(awsCfg, cfg.Credentials, cfg.STS)
+	if err != nil {
+		return aws.Config{}, fmt.Errorf("unable to load the credentials: %w", err)
+	}
+	if provider != nil {
+		awsCfg.Credentials = provider
+	}
+	if cfg.authConfig != nil {
+		cfg.authConfig.apply(&awsCfg, cfg.HTTPClient != nil)
+	}
+	if cfg.RoleARN != "" {
+		awsCfg.Credentials = aws.NewCredentialsCache(stscreds.NewAssumeRoleProvider(newSTSClient(awsCfg, cfg.STS), cfg.RoleARN, assumeRoleOptions(cfg)),
+			func(o *aws.CredentialsCacheOptions) {
+				o.ExpiryWindow = credentialsExpiryWindow
+			})
+	}
+	return awsCfg, nil
+}
+
+// assumeRoleOptions sets the external ID, the session name and the duration of
+// the sessions of the role of cfg.
+func assumeRoleOptions(cfg S3DownloaderConfig) func(*stscreds.AssumeRoleOptions) {
+	return func(o *stscreds.AssumeRoleOptions) {
+		if cfg.ExternalID != "" {
+			o.ExternalID = aws.String(cfg.ExternalID)
+		}
+		if cfg.RoleSessionName != "" {
+			o.RoleSessionName = cfg.RoleSessionName
+		}
+		if cfg.RoleSessionDuration > 0 {
+			o.Duration = cfg.RoleSessionDuration
+		}
+	}
+}
+
 func (api *s3ListObjectsAPIImpl) NewListObjectsV2Paginator(params *s3.ListObjectsV2Input) ListObjectsV2Pager {
 	return s3.NewListObjectsV2Paginator(api.client, params)
 }
+
+func (api *s3ListObjectsAPIImpl) NewListObjectVersionsPaginator(params *s3.ListObjectVersionsInput) ListObjectVersionsPager {
+	return s3.NewListObjectVersionsPaginator(api.client, params)
+}
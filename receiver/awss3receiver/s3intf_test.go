@@ -0,0 +1,172 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_newS3Client_S3Compatible(t *testing.T) {
+	_, getObjectClient, err := newS3Client(context.Background(), S3DownloaderConfig{
+		S3Bucket:             "bucket",
+		Endpoint:             "http://localhost:9000",
+		EndpointPartitionID:  "aws",
+		CompatibilityProfile: CompatibilityProfileS3Compatible,
+	})
+	require.NoError(t, err)
+	options := getObjectClient.(*s3.Client).Options()
+	require.True(t, options.UsePathStyle)
+	require.True(t, options.DisableMultiRegionAccessPoints)
+	require.NotNil(t, options.DisableS3ExpressSessionAuth)
+	require.True(t, *options.DisableS3ExpressSessionAuth)
+	require.NotEmpty(t, options.Region)
+
+	_, getObjectClient, err = newS3Client(context.Background(), S3DownloaderConfig{
+		Region:   "us-west-2",
+		S3Bucket: "bucket",
+	})
+	require.NoError(t, err)
+	options = getObjectClient.(*s3.Client).Options()
+	require.False(t, options.UsePathStyle)
+	require.Nil(t, options.DisableS3ExpressSessionAuth)
+}
+
+func Test_assumeRoleOptions(t *testing.T) {
+	options := stscreds.AssumeRoleOptions{RoleSessionName: "default", Duration: stscreds.DefaultDuration}
+	assumeRoleOptions(S3DownloaderConfig{RoleARN: "arn:aws:iam::123456789012:role/reader"})(&options)
+	require.Equal(t, stscreds.AssumeRoleOptions{RoleSessionName: "default", Duration: stscreds.DefaultDuration}, options)
+
+	assumeRoleOptions(S3DownloaderConfig{
+		RoleARN:             "arn:aws:iam::123456789012:role/reader",
+		ExternalID:          "replay",
+		RoleSessionName:     "otel-replay",
+		RoleSessionDuration: time.Hour,
+	})(&options)
+	require.Equal(t, stscreds.AssumeRoleOptions{ExternalID: aws.String("replay"), RoleSessionName: "otel-replay", Duration: time.Hour}, options)
+}
+
+func Test_newS3Client_FIPSAndDualStack(t *testing.T) {
+	_, getObjectClient, err := newS3Client(context.Background(), S3DownloaderConfig{
+		Region:               "us-gov-west-1",
+		S3Bucket:             "bucket",
+		UseFIPSEndpoint:      true,
+		UseDualStackEndpoint: true,
+	})
+	require.NoError(t, err)
+	options := getObjectClient.(*s3.Client).Options()
+	require.Equal(t, aws.FIPSEndpointStateEnabled, options.EndpointOptions.UseFIPSEndpoint)
+	require.Equal(t, aws.DualStackEndpointStateEnabled, options.EndpointOptions.UseDualStackEndpoint)
+
+	_, getObjectClient, err = newS3Client(context.Background(), S3DownloaderConfig{Region: "us-west-2", S3Bucket: "bucket"})
+	require.NoError(t, err)
+	options = getObjectClient.(*s3.Client).Options()
+	require.Equal(t, aws.FIPSEndpointStateUnset, options.EndpointOptions.UseFIPSEndpoint)
+	require.Equal(t, aws.DualStackEndpointStateUnset, options.EndpointOptions.UseDualStackEndpoint)
+}
+
+func Test_newS3Client_LoadError(t *testing.T) {
+	// The failure to load the configuration is returned rather than exiting.
+	_, _, err := newS3Client(context.Background(), S3DownloaderConfig{
+		S3Bucket: "bucket",
+		Credentials: &S3CredentialsConfig{
+			Profile:                "missing",
+			SharedCredentialsFiles: []string{filepath.Join(t.TempDir(), "credentials")},
+			SharedConfigFiles:      []string{filepath.Join(t.TempDir(), "config")},
+		},
+	})
+	require.ErrorContains(t, err, "unable to load SDK config: ")
+	require.ErrorContains(t, err, "missing")
+}
+
+func Test_newS3Client_Endpoint(t *testing.T) {
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The signature carries the region the request was signed for.
+		authorization := r.Header.Get("Authorization")
+		region := strings.Split(authorization[strings.Index(authorization, "Credential="):], "/")[2]
+		requests = append(requests, fmt.Sprintf("%s %s %s", r.Host, r.URL.Path, region))
+		_, _ = w.Write([]byte("object"))
+	}))
+	defer server.Close()
+	serverURL, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	staticCredentials := &S3CredentialsConfig{AccessKeyID: "AKID", SecretAccessKey: "SECRET"}
+
+	tests := []struct {
+		name string
+		cfg  S3DownloaderConfig
+		want string
+	}{
+		{
+			name: "s3_compatible",
+			cfg:  S3DownloaderConfig{Endpoint: server.URL, CompatibilityProfile: CompatibilityProfileS3Compatible},
+			want: serverURL.Host + " /bucket/key us-east-1",
+		},
+		{
+			name: "path_style",
+			cfg:  S3DownloaderConfig{Region: "eu-west-1", Endpoint: server.URL, S3ForcePathStyle: true},
+			want: serverURL.Host + " /bucket/key eu-west-1",
+		},
+		{
+			// The bucket is a subdomain of the endpoint, reached through the server as a proxy.
+			name: "virtual_host",
+			cfg: S3DownloaderConfig{Region: "eu-west-1", Endpoint: "http://s3.internal:9000", HTTPClient: &S3HTTPClientConfig{
+				ProxyURL: server.URL,
+			}},
+			want: "bucket.s3.internal:9000 /key eu-west-1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests = nil
+			tt.cfg.S3Bucket = "bucket"
+			tt.cfg.Credentials = staticCredentials
+			_, getObjectClient, err := newS3Client(context.Background(), tt.cfg)
+			require.NoError(t, err)
+			data, _, err := getObject(context.Background(), getObjectClient, &s3.GetObjectInput{Bucket: aws.String("bucket"), Key: aws.String("key")})
+			require.NoError(t, err)
+			require.Equal(t, "object", string(data))
+			require.Equal(t, []string{tt.want}, requests)
+		})
+	}
+}
+
+func Test_newS3Client_Outposts(t *testing.T) {
+	setTestCredentialsEnv(t, nil)
+	bucket := "arn:aws:s3-outposts:us-west-2:123456789012:outpost/op-01234567890123456/accesspoint/traces"
+	listObjectsClient, getObjectClient, err := newS3Client(context.Background(), S3DownloaderConfig{
+		Region:      "us-east-1",
+		S3Bucket:    bucket,
+		Credentials: &S3CredentialsConfig{AccessKeyID: "AKID", SecretAccessKey: "secret"},
+	})
+	require.NoError(t, err)
+	recorder := &hostRecorder{}
+	withRecorder := func(o *s3.Options) {
+		o.HTTPClient = recorder
+	}
+	_, err = listObjectsClient.NewListObjectsV2Paginator(&s3.ListObjectsV2Input{Bucket: aws.String(bucket)}).NextPage(context.Background(), withRecorder)
+	require.NoError(t, err)
+	_, err = getObjectClient.GetObject(context.Background(), &s3.GetObjectInput{Bucket: aws.String(bucket), Key: aws.String("key")}, withRecorder)
+	require.NoError(t, err)
+	// The requests are sent to the Outpost in the region of the ARN, and signed for
+	// S3 on Outposts.
+	host := "traces-123456789012.op-01234567890123456.s3-outposts.us-west-2.amazonaws.com"
+	require.Equal(t, []string{host, host}, recorder.hosts)
+	for _, authorization := range recorder.authorizations {
+		require.Contains(t, authorization, "/s3-outposts/aws4_request")
+	}
+}
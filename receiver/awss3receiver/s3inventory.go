@@ -0,0 +1,364 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/apache/arrow/go/v15/arrow"
+	"github.com/apache/arrow/go/v15/arrow/array"
+	"github.com/apache/arrow/go/v15/arrow/memory"
+	"github.com/apache/arrow/go/v15/parquet/file"
+	"github.com/apache/arrow/go/v15/parquet/pqarrow"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+const inventoryPageSize = 1000
+
+// inventoryReportPrefix matches the per-report prefixes created by S3 Inventory below the
+// configured destination prefix, for example 2024-01-31T01-00Z/.
+var inventoryReportPrefix = regexp.MustCompile(`/\d{4}-\d{2}-\d{2}T\d{2}-\d{2}Z/$`)
+
+// inventoryManifest is the manifest.json file describing an S3 Inventory report.
+// See https://docs.aws.amazon.com/AmazonS3/latest/userguide/storage-inventory-location.html
+type inventoryManifest struct {
+	SourceBucket string `json:"sourceBucket"`
+	FileFormat   string `json:"fileFormat"`
+	FileSchema   string `json:"fileSchema"`
+	Files        []struct {
+		Key string `json:"key"`
+	} `json:"files"`
+}
+
+// s3InventoryListObjectsAPI lists objects from an S3 Inventory report rather than calling
+// ListObjectsV2. The report is loaded the first time a page is requested and the objects
+// below keyPrefix are kept sorted in memory.
+type s3InventoryListObjectsAPI struct {
+	listObjectsClient ListObjectsAPI
+	getObjectClient   GetObjectAPI
+	sourceBucket      string
+	inventoryBucket   string
+	manifestKey       string
+	manifestPrefix    string
+	keyPrefix         string
+
+	loadOnce sync.Once
+	objects  []types.Object
+	loadErr  error
+}
+
+func newS3InventoryListObjectsAPI(listObjectsClient ListObjectsAPI, getObjectClient GetObjectAPI, cfg S3DownloaderConfig) *s3InventoryListObjectsAPI {
+	keyPrefix := ""
+	if cfg.S3Prefix != "" {
+		keyPrefix = cfg.S3Prefix + "/"
+	}
+	return &s3InventoryListObjectsAPI{
+		listObjectsClient: listObjectsClient,
+		getObjectClient:   getObjectClient,
+		sourceBucket:      cfg.S3Bucket,
+		inventoryBucket:   cfg.Inventory.Bucket,
+		manifestKey:       cfg.Inventory.ManifestKey,
+		manifestPrefix:    strings.TrimSuffix(cfg.Inventory.Prefix, "/"),
+		keyPrefix:         keyPrefix,
+	}
+}
+
+func (api *s3InventoryListObjectsAPI) NewListObjectsV2Paginator(params *s3.ListObjectsV2Input) ListObjectsV2Pager {
+	return &s3InventoryPager{
+		api:    api,
+		prefix: aws.ToString(params.Prefix),
+	}
+}
+
+func (api *s3InventoryListObjectsAPI) load(ctx context.Context) error {
+	api.loadOnce.Do(func() {
+		api.objects, api.loadErr = api.loadInventory(ctx)
+	})
+	return api.loadErr
+}
+
+func (api *s3InventoryListObjectsAPI) loadInventory(ctx context.Context) ([]types.Object, error) {
+	manifestKey := api.manifestKey
+	if manifestKey == "" {
+		var err error
+		if manifestKey, err = api.findLatestManifest(ctx); err != nil {
+			return nil, err
+		}
+	}
+	data, err := api.getObject(ctx, manifestKey)
+	if err != nil {
+		return nil, fmt.Errorf("unable to retrieve inventory manifest %s: %w", manifestKey, err)
+	}
+	var manifest inventoryManifest
+	if err = json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("unable to parse inventory manifest %s: %w", manifestKey, err)
+	}
+	if manifest.SourceBucket != api.sourceBucket {
+		return nil, fmt.Errorf("inventory manifest %s describes bucket %s instead of %s", manifestKey, manifest.SourceBucket, api.sourceBucket)
+	}
+
+	var objects []types.Object
+	for _, f := range manifest.Files {
+		data, err = api.getObject(ctx, f.Key)
+		if err != nil {
+			return nil, fmt.Errorf("unable to retrieve inventory file %s: %w", f.Key, err)
+		}
+		var fileObjects []types.Object
+		switch manifest.FileFormat {
+		case "CSV":
+			fileObjects, err = parseInventoryCSV(data, manifest.FileSchema)
+		case "Parquet":
+			fileObjects, err = parseInventoryParquet(ctx, data)
+		default:
+			return nil, fmt.Errorf("unsupported inventory file format %q", manifest.FileFormat)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse inventory file %s: %w", f.Key, err)
+		}
+		for _, obj := range fileObjects {
+			if strings.HasPrefix(*obj.Key, api.keyPrefix) {
+				objects = append(objects, obj)
+			}
+		}
+	}
+	sort.Slice(objects, func(i, j int) bool {
+		return *objects[i].Key < *objects[j].Key
+	})
+	return objects, nil
+}
+
+// findLatestManifest returns the manifest of the most recent report below the configured prefix.
+func (api *s3InventoryListObjectsAPI) findLatestManifest(ctx context.Context) (string, error) {
+	prefix := api.manifestPrefix + "/"
+	p := api.listObjectsClient.NewListObjectsV2Paginator(&s3.ListObjectsV2Input{
+		Bucket:    &api.inventoryBucket,
+		Prefix:    &prefix,
+		Delimiter: aws.String("/"),
+	})
+	latest := ""
+	for p.HasMorePages() {
+		page, err := p.NextPage(ctx)
+		if err != nil {
+			return "", err
+		}
+		for _, commonPrefix := range page.CommonPrefixes {
+			reportPrefix := aws.ToString(commonPrefix.Prefix)
+			if inventoryReportPrefix.MatchString(reportPrefix) && reportPrefix > latest {
+				latest = reportPrefix
+			}
+		}
+	}
+	if latest == "" {
+		return "", fmt.Errorf("no inventory report found in %s/%s", api.inventoryBucket, prefix)
+	}
+	return latest + "manifest.json", nil
+}
+
+func (api *s3InventoryListObjectsAPI) getObject(ctx context.Context, key string) ([]byte, error) {
+	output, err := api.getObjectClient.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: &api.inventoryBucket,
+		Key:    &key,
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer output.Body.Close()
+	return io.ReadAll(output.Body)
+}
+
+type s3InventoryPager struct {
+	api     *s3InventoryListObjectsAPI
+	prefix  string
+	started bool
+	pos     int
+	done    bool
+}
+
+func (p *s3InventoryPager) HasMorePages() bool {
+	return !p.done
+}
+
+func (p *s3InventoryPager) NextPage(ctx context.Context, _ ...func(*s3.Options)) (*s3.ListObjectsV2Output, error) {
+	if p.done {
+		return nil, errors.New("no more pages")
+	}
+	if err := p.api.load(ctx); err != nil {
+		return nil, err
+	}
+	objects := p.api.objects
+	if !p.started {
+		p.started = true
+		p.pos = sort.Search(len(objects), func(i int) bool {
+			return *objects[i].Key >= p.prefix
+		})
+	}
+	contents := make([]types.Object, 0, inventoryPageSize)
+	for ; p.pos < len(objects) && len(contents) < inventoryPageSize; p.pos++ {
+		if !strings.HasPrefix(*objects[p.pos].Key, p.prefix) {
+			p.done = true
+			break
+		}
+		contents = append(contents, objects[p.pos])
+	}
+	if p.pos >= len(objects) {
+		p.done = true
+	}
+	return &s3.ListObjectsV2Output{
+		Contents: contents,
+		Prefix:   &p.prefix,
+	}, nil
+}
+
+// parseInventoryCSV parses a gzip compressed CSV inventory file described by fileSchema.
+func parseInventoryCSV(data []byte, fileSchema string) ([]types.Object, error) {
+	columns := make(map[string]int)
+	for i, name := range strings.Split(fileSchema, ",") {
+		columns[strings.TrimSpace(name)] = i
+	}
+	keyColumn, ok := columns["Key"]
+	if !ok {
+		return nil, errors.New("inventory schema has no Key field")
+	}
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer gz.Close()
+	r := csv.NewReader(gz)
+	r.FieldsPerRecord = len(columns)
+
+	var objects []types.Object
+	for {
+		record, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			return objects, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if !isCurrentInventoryEntry(csvField(record, columns, "IsLatest"), csvField(record, columns, "IsDeleteMarker")) {
+			continue
+		}
+		// Object keys are URL encoded in CSV inventory files.
+		key, err := url.QueryUnescape(record[keyColumn])
+		if err != nil {
+			return nil, fmt.Errorf("unable to decode object key %q: %w", record[keyColumn], err)
+		}
+		obj := types.Object{
+			Key:          &key,
+			StorageClass: types.ObjectStorageClass(csvField(record, columns, "StorageClass")),
+		}
+		if size, err := strconv.ParseInt(csvField(record, columns, "Size"), 10, 64); err == nil {
+			obj.Size = &size
+		}
+		if lastModified, err := time.Parse(time.RFC3339, csvField(record, columns, "LastModifiedDate")); err == nil {
+			obj.LastModified = &lastModified
+		}
+		if etag := csvField(record, columns, "ETag"); etag != "" {
+			obj.ETag = &etag
+		}
+		objects = append(objects, obj)
+	}
+}
+
+func csvField(record []string, columns map[string]int, name string) string {
+	if i, ok := columns[name]; ok {
+		return record[i]
+	}
+	return ""
+}
+
+// isCurrentInventoryEntry reports whether an entry describes the current version of an
+// object. Only inventories including all versions have IsLatest and IsDeleteMarker fields.
+func isCurrentInventoryEntry(isLatest, isDeleteMarker string) bool {
+	return isLatest != "false" && isDeleteMarker != "true"
+}
+
+// parseInventoryParquet parses an Apache Parquet inventory file.
+func parseInventoryParquet(ctx context.Context, data []byte) ([]types.Object, error) {
+	pf, err := file.NewParquetReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer pf.Close()
+	fr, err := pqarrow.NewFileReader(pf, pqarrow.ArrowReadProperties{}, memory.DefaultAllocator)
+	if err != nil {
+		return nil, err
+	}
+	table, err := fr.ReadTable(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer table.Release()
+
+	tr := array.NewTableReader(table, 0)
+	defer tr.Release()
+	var objects []types.Object
+	for tr.Next() {
+		rec := tr.Record()
+		keys := recordColumn(rec, "key")
+		if keys == nil {
+			return nil, errors.New("inventory schema has no key field")
+		}
+		isLatest := recordColumn(rec, "is_latest")
+		isDeleteMarker := recordColumn(rec, "is_delete_marker")
+		sizes := recordColumn(rec, "size")
+		lastModified := recordColumn(rec, "last_modified_date")
+		etags := recordColumn(rec, "e_tag")
+		storageClasses := recordColumn(rec, "storage_class")
+		for i := 0; i < int(rec.NumRows()); i++ {
+			if !isCurrentInventoryEntry(arrowValueString(isLatest, i), arrowValueString(isDeleteMarker, i)) {
+				continue
+			}
+			key := arrowValueString(keys, i)
+			obj := types.Object{
+				Key:          &key,
+				StorageClass: types.ObjectStorageClass(arrowValueString(storageClasses, i)),
+			}
+			if size, ok := sizes.(*array.Int64); ok && size.IsValid(i) {
+				obj.Size = aws.Int64(size.Value(i))
+			}
+			if ts, ok := lastModified.(*array.Timestamp); ok && ts.IsValid(i) {
+				unit := ts.DataType().(*arrow.TimestampType).Unit
+				obj.LastModified = aws.Time(ts.Value(i).ToTime(unit))
+			}
+			if etag := arrowValueString(etags, i); etag != "" {
+				obj.ETag = &etag
+			}
+			objects = append(objects, obj)
+		}
+	}
+	return objects, tr.Err()
+}
+
+func recordColumn(rec arrow.Record, name string) arrow.Array {
+	indices := rec.Schema().FieldIndices(name)
+	if len(indices) == 0 {
+		return nil
+	}
+	return rec.Column(indices[0])
+}
+
+func arrowValueString(arr arrow.Array, i int) string {
+	if arr == nil || arr.IsNull(i) {
+		return ""
+	}
+	return arr.ValueStr(i)
+}
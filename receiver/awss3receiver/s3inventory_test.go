@@ -0,0 +1,232 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow/go/v15/arrow"
+	"github.com/apache/arrow/go/v15/arrow/array"
+	"github.com/apache/arrow/go/v15/arrow/memory"
+	"github.com/apache/arrow/go/v15/parquet/pqarrow"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/stretchr/testify/require"
+)
+
+const testInventoryCSV = `"bucket","prefix/year%3D2021/month%3D02/day%3D01/hour%3D17/minute%3D33/traces_1","12","2021-02-01T17:33:10.000Z","STANDARD"
+"bucket","prefix/year%3D2021/month%3D02/day%3D01/hour%3D17/minute%3D32/traces_2","10","2021-02-01T17:32:20.000Z","STANDARD"
+"bucket","prefix/year%3D2021/month%3D02/day%3D01/hour%3D17/minute%3D32/traces_1","11","2021-02-01T17:32:10.000Z","GLACIER"
+"bucket","other/year%3D2021/month%3D02/day%3D01/hour%3D17/minute%3D32/traces_1","11","2021-02-01T17:32:10.000Z","STANDARD"
+`
+
+func newTestS3InventoryListObjectsAPI(t *testing.T, objects map[string][]byte, listObjectsClient ListObjectsAPI) *s3InventoryListObjectsAPI {
+	return &s3InventoryListObjectsAPI{
+		listObjectsClient: listObjectsClient,
+		getObjectClient: mockGetObjectAPI(func(_ context.Context, params *s3.GetObjectInput, _ ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
+			t.Helper()
+			require.Equal(t, "inventory", *params.Bucket)
+			data, ok := objects[*params.Key]
+			if !ok {
+				return nil, fmt.Errorf("no such key %s", *params.Key)
+			}
+			return &s3.GetObjectOutput{Body: io.NopCloser(bytes.NewReader(data))}, nil
+		}),
+		sourceBucket:    "bucket",
+		inventoryBucket: "inventory",
+		manifestKey:     "reports/bucket/all/2021-02-02T01-00Z/manifest.json",
+		keyPrefix:       "prefix/",
+	}
+}
+
+func listAllKeys(t *testing.T, api ListObjectsAPI, prefix string) []string {
+	keys := make([]string, 0)
+	p := api.NewListObjectsV2Paginator(&s3.ListObjectsV2Input{Bucket: aws.String("bucket"), Prefix: aws.String(prefix)})
+	for p.HasMorePages() {
+		page, err := p.NextPage(context.Background())
+		require.NoError(t, err)
+		for _, obj := range page.Contents {
+			keys = append(keys, *obj.Key)
+		}
+	}
+	return keys
+}
+
+func Test_s3InventoryListObjectsAPI_CSV(t *testing.T) {
+	api := newTestS3InventoryListObjectsAPI(t, map[string][]byte{
+		"reports/bucket/all/2021-02-02T01-00Z/manifest.json": []byte(`{
+  "sourceBucket": "bucket",
+  "destinationBucket": "arn:aws:s3:::inventory",
+  "version": "2016-11-30",
+  "fileFormat": "CSV",
+  "fileSchema": "Bucket, Key, Size, LastModifiedDate, StorageClass",
+  "files": [{"key": "reports/bucket/all/data/1.csv.gz", "size": 1, "MD5checksum": "0"}]
+}`),
+		"reports/bucket/all/data/1.csv.gz": gzipCompress([]byte(testInventoryCSV)),
+	}, nil)
+
+	require.Equal(t, []string{
+		"prefix/year=2021/month=02/day=01/hour=17/minute=32/traces_1",
+		"prefix/year=2021/month=02/day=01/hour=17/minute=32/traces_2",
+	}, listAllKeys(t, api, "prefix/year=2021/month=02/day=01/hour=17/minute=32/traces_"))
+	require.Equal(t, []string{
+		"prefix/year=2021/month=02/day=01/hour=17/minute=33/traces_1",
+	}, listAllKeys(t, api, "prefix/year=2021/month=02/day=01/hour=17/minute=33/traces_"))
+	require.Empty(t, listAllKeys(t, api, "prefix/year=2021/month=02/day=01/hour=17/minute=34/traces_"))
+	require.Empty(t, listAllKeys(t, api, "other/"))
+
+	require.Len(t, api.objects, 3)
+	obj := api.objects[0]
+	require.Equal(t, int64(11), *obj.Size)
+	require.Equal(t, time.Date(2021, 2, 1, 17, 32, 10, 0, time.UTC), *obj.LastModified)
+	require.Equal(t, types.ObjectStorageClassGlacier, obj.StorageClass)
+}
+
+func Test_s3InventoryListObjectsAPI_Parquet(t *testing.T) {
+	schema := arrow.NewSchema([]arrow.Field{
+		{Name: "bucket", Type: arrow.BinaryTypes.String},
+		{Name: "key", Type: arrow.BinaryTypes.String},
+		{Name: "is_latest", Type: arrow.FixedWidthTypes.Boolean, Nullable: true},
+		{Name: "size", Type: arrow.PrimitiveTypes.Int64, Nullable: true},
+		{Name: "last_modified_date", Type: &arrow.TimestampType{Unit: arrow.Millisecond}, Nullable: true},
+	}, nil)
+	builder := array.NewRecordBuilder(memory.DefaultAllocator, schema)
+	defer builder.Release()
+	builder.Field(0).(*array.StringBuilder).AppendValues([]string{"bucket", "bucket", "bucket"}, nil)
+	builder.Field(1).(*array.StringBuilder).AppendValues([]string{
+		"prefix/year=2021/month=02/day=01/hour=17/minute=32/traces_1",
+		"prefix/year=2021/month=02/day=01/hour=17/minute=32/traces_2",
+		"prefix/year=2021/month=02/day=01/hour=17/minute=32/traces_3",
+	}, nil)
+	builder.Field(2).(*array.BooleanBuilder).AppendValues([]bool{true, false, true}, nil)
+	builder.Field(3).(*array.Int64Builder).AppendValues([]int64{11, 12, 0}, []bool{true, true, false})
+	lastModified := arrow.Timestamp(time.Date(2021, 2, 1, 17, 32, 10, 0, time.UTC).UnixMilli())
+	builder.Field(4).(*array.TimestampBuilder).AppendValues([]arrow.Timestamp{lastModified, lastModified, lastModified}, nil)
+	record := builder.NewRecord()
+	defer record.Release()
+	table := array.NewTableFromRecords(schema, []arrow.Record{record})
+	defer table.Release()
+	var buf bytes.Buffer
+	require.NoError(t, pqarrow.WriteTable(table, &buf, 1024, nil, pqarrow.DefaultWriterProps()))
+
+	api := newTestS3InventoryListObjectsAPI(t, map[string][]byte{
+		"reports/bucket/all/2021-02-02T01-00Z/manifest.json": []byte(`{
+  "sourceBucket": "bucket",
+  "fileFormat": "Parquet",
+  "fileSchema": "message s3.inventory { required binary bucket (UTF8); required binary key (UTF8); optional boolean is_latest; optional int64 size; optional int64 last_modified_date (TIMESTAMP_MILLIS);}",
+  "files": [{"key": "reports/bucket/all/data/1.parquet"}]
+}`),
+		"reports/bucket/all/data/1.parquet": buf.Bytes(),
+	}, nil)
+
+	require.Equal(t, []string{
+		"prefix/year=2021/month=02/day=01/hour=17/minute=32/traces_1",
+		"prefix/year=2021/month=02/day=01/hour=17/minute=32/traces_3",
+	}, listAllKeys(t, api, "prefix/year=2021/month=02/day=01/hour=17/minute=32/traces_"))
+	require.Equal(t, int64(11), *api.objects[0].Size)
+	require.Equal(t, time.Date(2021, 2, 1, 17, 32, 10, 0, time.UTC), api.objects[0].LastModified.UTC())
+	require.Nil(t, api.objects[1].Size)
+}
+
+func Test_s3InventoryListObjectsAPI_Pagination(t *testing.T) {
+	var csvData bytes.Buffer
+	for i := 0; i < inventoryPageSize+1; i++ {
+		fmt.Fprintf(&csvData, "\"bucket\",\"prefix/year%%3D2021/month%%3D02/day%%3D01/hour%%3D17/minute%%3D32/traces_%05d\"\n", i)
+	}
+	api := newTestS3InventoryListObjectsAPI(t, map[string][]byte{
+		"reports/bucket/all/2021-02-02T01-00Z/manifest.json": []byte(`{
+  "sourceBucket": "bucket",
+  "fileFormat": "CSV",
+  "fileSchema": "Bucket, Key",
+  "files": [{"key": "reports/bucket/all/data/1.csv.gz"}]
+}`),
+		"reports/bucket/all/data/1.csv.gz": gzipCompress(csvData.Bytes()),
+	}, nil)
+
+	p := api.NewListObjectsV2Paginator(&s3.ListObjectsV2Input{Prefix: aws.String("prefix/")})
+	page, err := p.NextPage(context.Background())
+	require.NoError(t, err)
+	require.Len(t, page.Contents, inventoryPageSize)
+	require.True(t, p.HasMorePages())
+	page, err = p.NextPage(context.Background())
+	require.NoError(t, err)
+	require.Len(t, page.Contents, 1)
+	require.False(t, p.HasMorePages())
+}
+
+func Test_s3InventoryListObjectsAPI_LatestManifest(t *testing.T) {
+	api := newTestS3InventoryListObjectsAPI(t, map[string][]byte{
+		"reports/bucket/all/2021-02-02T01-00Z/manifest.json": []byte(`{
+  "sourceBucket": "bucket",
+  "fileFormat": "CSV",
+  "fileSchema": "Bucket, Key, Size, LastModifiedDate, StorageClass",
+  "files": [{"key": "reports/bucket/all/data/1.csv.gz"}]
+}`),
+		"reports/bucket/all/data/1.csv.gz": gzipCompress([]byte(testInventoryCSV)),
+	}, mockListObjectsAPI(func(params *s3.ListObjectsV2Input) ListObjectsV2Pager {
+		t.Helper()
+		require.Equal(t, "inventory", *params.Bucket)
+		require.Equal(t, "reports/bucket/all/", *params.Prefix)
+		require.Equal(t, "/", *params.Delimiter)
+		return &mockListObjectsV2Pager{
+			Pages: []*s3.ListObjectsV2Output{
+				{
+					CommonPrefixes: []types.CommonPrefix{
+						{Prefix: aws.String("reports/bucket/all/2021-02-01T01-00Z/")},
+						{Prefix: aws.String("reports/bucket/all/2021-02-02T01-00Z/")},
+						{Prefix: aws.String("reports/bucket/all/data/")},
+						{Prefix: aws.String("reports/bucket/all/hive/")},
+					},
+				},
+			},
+		}
+	}))
+	api.manifestKey = ""
+	api.manifestPrefix = "reports/bucket/all"
+
+	require.Len(t, listAllKeys(t, api, "prefix/"), 3)
+}
+
+func Test_s3InventoryListObjectsAPI_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		objects map[string][]byte
+		err     string
+	}{
+		{
+			name:    "missing manifest",
+			objects: map[string][]byte{},
+			err:     "unable to retrieve inventory manifest reports/bucket/all/2021-02-02T01-00Z/manifest.json: no such key reports/bucket/all/2021-02-02T01-00Z/manifest.json",
+		},
+		{
+			name: "other bucket",
+			objects: map[string][]byte{
+				"reports/bucket/all/2021-02-02T01-00Z/manifest.json": []byte(`{"sourceBucket": "other", "fileFormat": "CSV"}`),
+			},
+			err: "inventory manifest reports/bucket/all/2021-02-02T01-00Z/manifest.json describes bucket other instead of bucket",
+		},
+		{
+			name: "unsupported format",
+			objects: map[string][]byte{
+				"reports/bucket/all/2021-02-02T01-00Z/manifest.json": []byte(`{"sourceBucket": "bucket", "fileFormat": "ORC", "files": [{"key": "reports/bucket/all/data/1.orc"}]}`),
+				"reports/bucket/all/data/1.orc":                      []byte("orc"),
+			},
+			err: `unsupported inventory file format "ORC"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := newTestS3InventoryListObjectsAPI(t, tt.objects, nil)
+			p := api.NewListObjectsV2Paginator(&s3.ListObjectsV2Input{Prefix: aws.String("prefix/")})
+			_, err := p.NextPage(context.Background())
+			require.EqualError(t, err, tt.err)
+		})
+	}
+}
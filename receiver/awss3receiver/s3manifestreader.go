@@ -0,0 +1,232 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// s3ManifestReader retrieves the objects listed in a manifest object, in the
+// order in which they are listed.
+type s3ManifestReader struct {
+	getObjectClient GetObjectAPI
+	rangedGetter    *rangedGetter
+	selector        *objectSelector
+	sseCustomerKey  *sseCustomerKey
+	manifestBucket  string
+	manifestKey     string
+	manifestFormat  string
+	s3Bucket        string
+	filePrefix      string
+	naming          objectNaming
+	// position is the index of the manifest entry being read.
+	position int
+}
+
+// manifestEntry is an object listed in a manifest. The bucket defaults to the
+// configured s3_bucket and the version, if not set, to the current version.
+type manifestEntry struct {
+	Bucket    string `json:"bucket"`
+	Key       string `json:"key"`
+	VersionID string `json:"version_id"`
+}
+
+func newS3ManifestReader(ctx context.Context, cfg *Config, naming objectNaming) (*s3ManifestReader, error) {
+	_, getObjectClient, err := newS3Client(ctx, cfg.S3Downloader)
+	if err != nil {
+		return nil, err
+	}
+	format, err := cfg.Manifest.format()
+	if err != nil {
+		return nil, err
+	}
+	selector, err := newObjectSelector(getObjectClient, cfg.S3Downloader.Select)
+	if err != nil {
+		return nil, err
+	}
+	decrypter, err := newObjectDecrypter(ctx, cfg.S3Downloader)
+	if err != nil {
+		return nil, err
+	}
+	getObjectClient = decrypter.wrap(getObjectClient)
+	getObjectClient = newCallTimeouts(cfg.S3Downloader.Timeouts).wrapGetObject(getObjectClient)
+	manifestBucket := cfg.Manifest.Bucket
+	if manifestBucket == "" {
+		manifestBucket = cfg.S3Downloader.S3Bucket
+	}
+	return &s3ManifestReader{
+		getObjectClient: getObjectClient,
+		rangedGetter:    newRangedGetter(cfg.S3Downloader.RangedGet),
+		selector:        selector,
+		sseCustomerKey:  newSSECustomerKey(cfg.S3Downloader.SSECustomerKey),
+		manifestBucket:  manifestBucket,
+		manifestKey:     cfg.Manifest.Key,
+		manifestFormat:  format,
+		s3Bucket:        cfg.S3Downloader.S3Bucket,
+		filePrefix:      cfg.S3Downloader.FilePrefix,
+		naming:          naming,
+	}, nil
+}
+
+func (r *s3ManifestReader) readAll(ctx context.Context, telemetryType string, dataCallback s3ReaderDataCallback) error {
+	data, _, err := r.rangedGetter.getObject(ctx, r.getObjectClient, r.objectInput(r.manifestBucket, r.manifestKey, ""))
+	if err != nil {
+		return fmt.Errorf("unable to retrieve manifest %s: %w", r.manifestKey, err)
+	}
+	entries, err := parseManifest(data, r.manifestFormat)
+	if err != nil {
+		return fmt.Errorf("unable to parse manifest %s: %w", r.manifestKey, err)
+	}
+	for i, entry := range entries {
+		r.position = i
+		select {
+		case <-ctx.Done():
+			return nil
+		default:
+		}
+		// The manifests of the AWS reports only list the objects of a report.
+		reportManifest := r.manifestFormat == ManifestFormatCUR || r.manifestFormat == ManifestFormatStorageLens
+		if !reportManifest && !isTelemetryObject(entry.Key, r.filePrefix, r.naming, telemetryType) {
+			continue
+		}
+		bucket := entry.Bucket
+		if bucket == "" {
+			bucket = r.s3Bucket
+		}
+		body, info, err := r.openObject(ctx, r.objectInput(bucket, entry.Key, entry.VersionID))
+		if err != nil {
+			return err
+		}
+		err = dataCallback(contextWithObjectInfo(ctx, info), entry.Key, body)
+		body.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resumePosition returns the manifest entry to resume reading from.
+func (r *s3ManifestReader) resumePosition() string {
+	return fmt.Sprintf("manifest entry %d", r.position+1)
+}
+
+// openObject retrieves a listed object, selecting its records with S3 Select if
+// enabled for the object.
+func (r *s3ManifestReader) openObject(ctx context.Context, params *s3.GetObjectInput) (io.ReadCloser, objectInfo, error) {
+	r.sseCustomerKey.getObject(params)
+	if r.selector.selects(params) {
+		return r.selector.openObject(ctx, params)
+	}
+	return r.rangedGetter.openObject(ctx, r.getObjectClient, params)
+}
+
+// objectInput returns the parameters of the retrieval of an object, or of the
+// given version of the object if versionID is not empty.
+func (r *s3ManifestReader) objectInput(bucket, key, versionID string) *s3.GetObjectInput {
+	params := &s3.GetObjectInput{
+		Bucket: &bucket,
+		Key:    &key,
+	}
+	if versionID != "" {
+		params.VersionId = &versionID
+	}
+	return params
+}
+
+// parseManifest parses the entries of a manifest. A JSON manifest is an array of
+// objects with bucket, key and optional version_id fields, a CSV manifest has bucket,
+// key and optional version_id columns and may start with a header row. A CUR
+// manifest is the manifest of a Cost and Usage Report, listing the keys of its
+// files in reportKeys, or their S3 URIs in dataFiles for the 2.0 reports. A
+// Storage Lens manifest lists the keys of the files of a metrics export in
+// reportFiles, the bucket being the ARN of its destinationBucket.
+func parseManifest(data []byte, format string) ([]manifestEntry, error) {
+	var entries []manifestEntry
+	switch format {
+	case ManifestFormatJSON:
+		if err := json.Unmarshal(data, &entries); err != nil {
+			return nil, err
+		}
+	case ManifestFormatCUR:
+		var manifest struct {
+			Bucket     string   `json:"bucket"`
+			ReportKeys []string `json:"reportKeys"`
+			DataFiles  []string `json:"dataFiles"`
+		}
+		if err := json.Unmarshal(data, &manifest); err != nil {
+			return nil, err
+		}
+		for _, key := range manifest.ReportKeys {
+			entries = append(entries, manifestEntry{Bucket: manifest.Bucket, Key: key})
+		}
+		for _, uri := range manifest.DataFiles {
+			bucket, key, ok := strings.Cut(strings.TrimPrefix(uri, "s3://"), "/")
+			if !ok || !strings.HasPrefix(uri, "s3://") {
+				return nil, fmt.Errorf("invalid data file %q", uri)
+			}
+			entries = append(entries, manifestEntry{Bucket: bucket, Key: key})
+		}
+	case ManifestFormatStorageLens:
+		var manifest struct {
+			DestinationBucket string `json:"destinationBucket"`
+			ReportFiles       []struct {
+				Key string `json:"key"`
+			} `json:"reportFiles"`
+		}
+		if err := json.Unmarshal(data, &manifest); err != nil {
+			return nil, err
+		}
+		bucket := manifest.DestinationBucket
+		if bucketARN, err := arn.Parse(bucket); err == nil {
+			// The bucket ARN may be in any partition, such as arn:aws-cn:s3:::bucket.
+			bucket = bucketARN.Resource
+		}
+		for _, file := range manifest.ReportFiles {
+			entries = append(entries, manifestEntry{Bucket: bucket, Key: file.Key})
+		}
+	case ManifestFormatCSV:
+		r := csv.NewReader(bytes.NewReader(data))
+		r.FieldsPerRecord = -1
+		r.TrimLeadingSpace = true
+		for row := 0; ; row++ {
+			record, err := r.Read()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				return nil, err
+			}
+			if len(record) != 2 && len(record) != 3 {
+				return nil, fmt.Errorf("manifest row %d has %d columns, expected bucket, key and optionally version_id", row+1, len(record))
+			}
+			if row == 0 && strings.EqualFold(record[0], "bucket") && strings.EqualFold(record[1], "key") {
+				continue
+			}
+			entry := manifestEntry{Bucket: record[0], Key: record[1]}
+			if len(record) == 3 {
+				entry.VersionID = record[2]
+			}
+			entries = append(entries, entry)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported manifest format %q", format)
+	}
+	for i, entry := range entries {
+		if entry.Key == "" {
+			return nil, fmt.Errorf("manifest entry %d has no key", i)
+		}
+	}
+	return entries, nil
+}
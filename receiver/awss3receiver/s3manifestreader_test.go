@@ -0,0 +1,173 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_parseManifest(t *testing.T) {
+	expected := []manifestEntry{
+		{Bucket: "bucket", Key: "year=2021/month=02/day=01/hour=17/minute=32/traces_1"},
+		{Bucket: "", Key: "year=2021/month=02/day=01/hour=17/minute=33/traces_1"},
+	}
+
+	entries, err := parseManifest([]byte(`[
+  {"bucket": "bucket", "key": "year=2021/month=02/day=01/hour=17/minute=32/traces_1"},
+  {"key": "year=2021/month=02/day=01/hour=17/minute=33/traces_1"}
+]`), ManifestFormatJSON)
+	require.NoError(t, err)
+	require.Equal(t, expected, entries)
+
+	entries, err = parseManifest([]byte("bucket,key\nbucket,year=2021/month=02/day=01/hour=17/minute=32/traces_1\n,year=2021/month=02/day=01/hour=17/minute=33/traces_1\n"), ManifestFormatCSV)
+	require.NoError(t, err)
+	require.Equal(t, expected, entries)
+
+	entries, err = parseManifest([]byte("bucket,year=2021/month=02/day=01/hour=17/minute=32/traces_1\n,year=2021/month=02/day=01/hour=17/minute=33/traces_1\n"), ManifestFormatCSV)
+	require.NoError(t, err)
+	require.Equal(t, expected, entries)
+
+	entries, err = parseManifest([]byte(`{"bucket": "billing", "reportKeys": ["cur/report/20240101-20240201/0123/report-1.csv.gz"]}`), ManifestFormatCUR)
+	require.NoError(t, err)
+	require.Equal(t, []manifestEntry{{Bucket: "billing", Key: "cur/report/20240101-20240201/0123/report-1.csv.gz"}}, entries)
+
+	entries, err = parseManifest([]byte(`{"dataFiles": ["s3://billing/cur/export/data/BILLING_PERIOD=2024-01/export-00001.snappy.parquet"]}`), ManifestFormatCUR)
+	require.NoError(t, err)
+	require.Equal(t, []manifestEntry{{Bucket: "billing", Key: "cur/export/data/BILLING_PERIOD=2024-01/export-00001.snappy.parquet"}}, entries)
+
+	_, err = parseManifest([]byte(`{"dataFiles": ["billing/export-00001.snappy.parquet"]}`), ManifestFormatCUR)
+	require.EqualError(t, err, `invalid data file "billing/export-00001.snappy.parquet"`)
+
+	entries, err = parseManifest([]byte(`{"destinationBucket": "arn:aws:s3:::lens", "reportFiles": [{"key": "StorageLens/123456789012/default-account-dashboard/V_1/reports/dt=2024-01-01/0123.csv", "size": 1024}]}`), ManifestFormatStorageLens)
+	require.NoError(t, err)
+	require.Equal(t, []manifestEntry{{Bucket: "lens", Key: "StorageLens/123456789012/default-account-dashboard/V_1/reports/dt=2024-01-01/0123.csv"}}, entries)
+
+	// The destination bucket may be in any partition.
+	for _, partition := range []string{"aws-cn", "aws-us-gov", "aws-iso-b"} {
+		entries, err = parseManifest([]byte(`{"destinationBucket": "arn:`+partition+`:s3:::lens", "reportFiles": [{"key": "reports/0123.csv"}]}`), ManifestFormatStorageLens)
+		require.NoError(t, err)
+		require.Equal(t, []manifestEntry{{Bucket: "lens", Key: "reports/0123.csv"}}, entries)
+	}
+
+	_, err = parseManifest([]byte(`[{"bucket": "bucket"}]`), ManifestFormatJSON)
+	require.EqualError(t, err, "manifest entry 0 has no key")
+
+	_, err = parseManifest([]byte("bucket,key,version_id,extra\n"), ManifestFormatCSV)
+	require.EqualError(t, err, "manifest row 1 has 4 columns, expected bucket, key and optionally version_id")
+}
+
+func Test_parseManifest_VersionID(t *testing.T) {
+	expected := []manifestEntry{
+		{Bucket: "bucket", Key: "year=2021/month=02/day=01/hour=17/minute=32/traces_1", VersionID: "v1"},
+		{Bucket: "bucket", Key: "year=2021/month=02/day=01/hour=17/minute=33/traces_1"},
+	}
+
+	entries, err := parseManifest([]byte(`[
+  {"bucket": "bucket", "key": "year=2021/month=02/day=01/hour=17/minute=32/traces_1", "version_id": "v1"},
+  {"bucket": "bucket", "key": "year=2021/month=02/day=01/hour=17/minute=33/traces_1"}
+]`), ManifestFormatJSON)
+	require.NoError(t, err)
+	require.Equal(t, expected, entries)
+
+	entries, err = parseManifest([]byte("bucket,key,version_id\nbucket,year=2021/month=02/day=01/hour=17/minute=32/traces_1,v1\nbucket,year=2021/month=02/day=01/hour=17/minute=33/traces_1\n"), ManifestFormatCSV)
+	require.NoError(t, err)
+	require.Equal(t, expected, entries)
+}
+
+func Test_s3ManifestReader_readAll(t *testing.T) {
+	objects := map[string]string{
+		"manifests/manifest.json": `[
+  {"key": "year=2021/month=02/day=01/hour=17/minute=33/traces_1"},
+  {"key": "year=2021/month=02/day=01/hour=17/minute=33/logs_1"},
+  {"bucket": "other", "key": "year=2021/month=02/day=01/hour=17/minute=32/traces_1"},
+  {"key": "year=2021/month=02/day=01/hour=17/minute=34/traces_1", "version_id": "v1"}
+]`,
+		"bucket/year=2021/month=02/day=01/hour=17/minute=33/traces_1":              "object 1",
+		"other/year=2021/month=02/day=01/hour=17/minute=32/traces_1":               "object 2",
+		"bucket/year=2021/month=02/day=01/hour=17/minute=34/traces_1?versionId=v1": "object 3",
+	}
+	reader := s3ManifestReader{
+		getObjectClient: mockGetObjectAPI(func(_ context.Context, params *s3.GetObjectInput, _ ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
+			key := fmt.Sprintf("%s/%s", *params.Bucket, *params.Key)
+			if params.VersionId != nil {
+				key += "?versionId=" + *params.VersionId
+			}
+			data, ok := objects[key]
+			if !ok {
+				return nil, errors.New("no such key")
+			}
+			return &s3.GetObjectOutput{Body: io.NopCloser(bytes.NewReader([]byte(data)))}, nil
+		}),
+		manifestBucket: "manifests",
+		manifestKey:    "manifest.json",
+		manifestFormat: ManifestFormatJSON,
+		s3Bucket:       "bucket",
+	}
+
+	var received []string
+	err := reader.readAll(context.Background(), "traces", func(_ context.Context, key string, body io.Reader) error {
+		received = append(received, key+": "+readBody(t, body))
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"year=2021/month=02/day=01/hour=17/minute=33/traces_1: object 1",
+		"year=2021/month=02/day=01/hour=17/minute=32/traces_1: object 2",
+		"year=2021/month=02/day=01/hour=17/minute=34/traces_1: object 3",
+	}, received)
+
+	objects["manifests/lens.json"] = `{"destinationBucket": "arn:aws:s3:::bucket", "reportFiles": [{"key": "reports/dt=2024-01-01/0123.csv"}]}`
+	objects["bucket/reports/dt=2024-01-01/0123.csv"] = "report"
+	lensReader := reader
+	lensReader.manifestKey = "lens.json"
+	lensReader.manifestFormat = ManifestFormatStorageLens
+	received = nil
+	err = lensReader.readAll(context.Background(), "metrics", func(_ context.Context, key string, body io.Reader) error {
+		received = append(received, key+": "+readBody(t, body))
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"reports/dt=2024-01-01/0123.csv: report"}, received)
+
+	err = reader.readAll(context.Background(), "traces", func(_ context.Context, _ string, _ io.Reader) error {
+		return errors.New("consumer error")
+	})
+	require.EqualError(t, err, "consumer error")
+
+	reader.manifestKey = "missing.json"
+	err = reader.readAll(context.Background(), "traces", func(_ context.Context, _ string, _ io.Reader) error {
+		t.Helper()
+		t.Fail()
+		return nil
+	})
+	require.EqualError(t, err, "unable to retrieve manifest missing.json: no such key")
+}
+
+func Test_s3ManifestReader_readAll_ContextDone(t *testing.T) {
+	reader := s3ManifestReader{
+		getObjectClient: mockGetObjectAPI(func(_ context.Context, _ *s3.GetObjectInput, _ ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
+			return &s3.GetObjectOutput{Body: io.NopCloser(bytes.NewReader([]byte(`[{"key": "traces_1"}]`)))}, nil
+		}),
+		manifestBucket: "bucket",
+		manifestKey:    "manifest.json",
+		manifestFormat: ManifestFormatJSON,
+		s3Bucket:       "bucket",
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := reader.readAll(ctx, "traces", func(_ context.Context, _ string, _ io.Reader) error {
+		t.Helper()
+		t.Fail()
+		return nil
+	})
+	require.NoError(t, err)
+}
@@ -0,0 +1,126 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"go.uber.org/multierr"
+	"go.uber.org/zap"
+)
+
+// s3MultiBucketReader retrieves data from several buckets, each with its own reader.
+type s3MultiBucketReader struct {
+	buckets     []string
+	readers     []telemetryReader
+	concurrency int
+}
+
+func newS3MultiBucketReader(ctx context.Context, cfg *Config, naming objectNaming) (*s3MultiBucketReader, error) {
+	bucketConfigs := cfg.S3Downloader.bucketConfigs()
+	r := &s3MultiBucketReader{
+		buckets:     make([]string, 0, len(bucketConfigs)),
+		readers:     make([]telemetryReader, 0, len(bucketConfigs)),
+		concurrency: cfg.S3Downloader.BucketConcurrency,
+	}
+	for _, bucketCfg := range bucketConfigs {
+		readerCfg := *cfg
+		readerCfg.S3Downloader = bucketCfg
+		reader, err := newS3Reader(ctx, &readerCfg, naming)
+		if err != nil {
+			return nil, fmt.Errorf("bucket %s: %w", bucketCfg.S3Bucket, err)
+		}
+		r.buckets = append(r.buckets, bucketCfg.S3Bucket)
+		r.readers = append(r.readers, reader)
+	}
+	return r, nil
+}
+
+// resumePosition returns the positions to resume reading each bucket from.
+func (r *s3MultiBucketReader) resumePosition() string {
+	positions := make([]string, 0, len(r.readers))
+	for i, reader := range r.readers {
+		if resumable, ok := reader.(resumableReader); ok {
+			positions = append(positions, fmt.Sprintf("bucket %s: %s", r.buckets[i], resumable.resumePosition()))
+		}
+	}
+	return strings.Join(positions, ", ")
+}
+
+// readProgress returns the average share of the time range read from the
+// buckets.
+func (r *s3MultiBucketReader) readProgress() (float64, bool) {
+	if len(r.readers) == 0 {
+		return 0, false
+	}
+	var sum float64
+	for _, reader := range r.readers {
+		progress, ok := reader.(progressReader)
+		if !ok {
+			return 0, false
+		}
+		read, ok := progress.readProgress()
+		if !ok {
+			return 0, false
+		}
+		sum += read
+	}
+	return sum / float64(len(r.readers)), true
+}
+
+// checkAccess checks that each bucket can be read, if startup_check is set.
+func (r *s3MultiBucketReader) checkAccess(ctx context.Context, logger *zap.Logger) error {
+	for i, reader := range r.readers {
+		if checker, ok := reader.(accessCheckingReader); ok {
+			if err := checker.checkAccess(ctx, logger); err != nil {
+				return fmt.Errorf("bucket %s: %w", r.buckets[i], err)
+			}
+		}
+	}
+	return nil
+}
+
+// readAll reads the buckets one after the other, stopping at the first error, unless
+// a concurrency greater than one is configured, in which case up to that many buckets
+// are read at the same time and an error only stops the bucket it occurred in.
+func (r *s3MultiBucketReader) readAll(ctx context.Context, telemetryType string, dataCallback s3ReaderDataCallback) error {
+	if r.concurrency <= 1 {
+		for i, reader := range r.readers {
+			if err := reader.readAll(ctx, telemetryType, dataCallback); err != nil {
+				return fmt.Errorf("bucket %s: %w", r.buckets[i], err)
+			}
+		}
+		return nil
+	}
+
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs error
+	)
+	sem := make(chan struct{}, r.concurrency)
+	for i, reader := range r.readers {
+		select {
+		case <-ctx.Done():
+		case sem <- struct{}{}:
+			wg.Add(1)
+			go func(bucket string, reader telemetryReader) {
+				defer func() {
+					<-sem
+					wg.Done()
+				}()
+				if err := reader.readAll(ctx, telemetryType, dataCallback); err != nil {
+					mu.Lock()
+					errs = multierr.Append(errs, fmt.Errorf("bucket %s: %w", bucket, err))
+					mu.Unlock()
+				}
+			}(r.buckets[i], reader)
+		}
+	}
+	wg.Wait()
+	return errs
+}
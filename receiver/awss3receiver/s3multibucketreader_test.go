@@ -0,0 +1,139 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type mockTelemetryReader func(ctx context.Context, telemetryType string, dataCallback s3ReaderDataCallback) error
+
+func (m mockTelemetryReader) readAll(ctx context.Context, telemetryType string, dataCallback s3ReaderDataCallback) error {
+	return m(ctx, telemetryType, dataCallback)
+}
+
+func newMockBucketReader(key string, err error) mockTelemetryReader {
+	return func(ctx context.Context, _ string, dataCallback s3ReaderDataCallback) error {
+		if err != nil {
+			return err
+		}
+		return dataCallback(ctx, key, strings.NewReader(key))
+	}
+}
+
+func Test_s3MultiBucketReader_readAll(t *testing.T) {
+	for _, concurrency := range []int{0, 1, 2} {
+		reader := s3MultiBucketReader{
+			buckets: []string{"bucket1", "bucket2", "bucket3"},
+			readers: []telemetryReader{
+				newMockBucketReader("key1", nil),
+				newMockBucketReader("key2", nil),
+				newMockBucketReader("key3", nil),
+			},
+			concurrency: concurrency,
+		}
+		var (
+			mu   sync.Mutex
+			keys []string
+		)
+		err := reader.readAll(context.Background(), "traces", func(_ context.Context, key string, _ io.Reader) error {
+			mu.Lock()
+			defer mu.Unlock()
+			keys = append(keys, key)
+			return nil
+		})
+		require.NoError(t, err)
+		sort.Strings(keys)
+		require.Equal(t, []string{"key1", "key2", "key3"}, keys)
+	}
+}
+
+func Test_s3MultiBucketReader_readAll_Error(t *testing.T) {
+	testError := errors.New("test error")
+	newReader := func(concurrency int) s3MultiBucketReader {
+		return s3MultiBucketReader{
+			buckets: []string{"bucket1", "bucket2", "bucket3"},
+			readers: []telemetryReader{
+				newMockBucketReader("key1", nil),
+				newMockBucketReader("key2", testError),
+				newMockBucketReader("key3", nil),
+			},
+			concurrency: concurrency,
+		}
+	}
+
+	var keys []string
+	reader := newReader(1)
+	err := reader.readAll(context.Background(), "traces", func(_ context.Context, key string, _ io.Reader) error {
+		keys = append(keys, key)
+		return nil
+	})
+	require.EqualError(t, err, "bucket bucket2: test error")
+	require.Equal(t, []string{"key1"}, keys)
+
+	var mu sync.Mutex
+	keys = nil
+	reader = newReader(3)
+	err = reader.readAll(context.Background(), "traces", func(_ context.Context, key string, _ io.Reader) error {
+		mu.Lock()
+		defer mu.Unlock()
+		keys = append(keys, key)
+		return nil
+	})
+	require.EqualError(t, err, "bucket bucket2: test error")
+	sort.Strings(keys)
+	require.Equal(t, []string{"key1", "key3"}, keys)
+}
+
+func Test_S3DownloaderConfig_bucketConfigs(t *testing.T) {
+	cfg := S3DownloaderConfig{
+		Region:      "us-east-1",
+		S3Bucket:    "bucket",
+		S3Prefix:    "prefix",
+		S3Partition: S3PartitionHour,
+	}
+	require.Equal(t, []S3DownloaderConfig{cfg}, cfg.bucketConfigs())
+
+	cfg.S3Bucket = ""
+	cfg.Buckets = []S3BucketConfig{
+		{S3Bucket: "bucket1"},
+		{S3Bucket: "bucket2", S3Prefix: "other", Region: "eu-west-1", RoleARN: "arn:aws:iam::123456789012:role/reader"},
+	}
+	require.Equal(t, []S3DownloaderConfig{
+		{
+			Region:      "us-east-1",
+			S3Bucket:    "bucket1",
+			S3Prefix:    "prefix",
+			S3Partition: S3PartitionHour,
+		},
+		{
+			Region:      "eu-west-1",
+			S3Bucket:    "bucket2",
+			S3Prefix:    "other",
+			S3Partition: S3PartitionHour,
+			RoleARN:     "arn:aws:iam::123456789012:role/reader",
+		},
+	}, cfg.bucketConfigs())
+
+	// The external ID of the role of the downloader is not used with the role of a bucket.
+	cfg.RoleARN = "arn:aws:iam::123456789012:role/replay"
+	cfg.ExternalID = "replay"
+	cfg.Buckets = []S3BucketConfig{
+		{S3Bucket: "bucket1"},
+		{S3Bucket: "bucket2", RoleARN: "arn:aws:iam::210987654321:role/reader", ExternalID: "reader"},
+		{S3Bucket: "bucket3", RoleARN: "arn:aws:iam::210987654321:role/reader"},
+	}
+	configs := cfg.bucketConfigs()
+	require.Equal(t, "replay", configs[0].ExternalID)
+	require.Equal(t, "reader", configs[1].ExternalID)
+	require.Empty(t, configs[2].ExternalID)
+}
@@ -0,0 +1,125 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// s3PartitionIndex tells which time partitions of a prefix hold objects by listing
+// the partitions level by level, from years down to the partition granularity,
+// with a delimiter. Only the levels holding data are descended into, so that
+// the objects of empty partitions need not be listed one partition at a time.
+type s3PartitionIndex struct {
+	listObjectsClient ListObjectsAPI
+	s3Bucket          string
+	s3Prefix          string
+	s3Partition       string
+	now               func() time.Time
+	// listings holds, for each listed partition prefix, the prefixes of the
+	// partitions below it that hold objects.
+	listings map[string]partitionListing
+}
+
+type partitionListing struct {
+	listedAt time.Time
+	children map[string]struct{}
+}
+
+func newS3PartitionIndex(listObjectsClient ListObjectsAPI, s3Bucket, s3Prefix, s3Partition string, now func() time.Time) *s3PartitionIndex {
+	return &s3PartitionIndex{
+		listObjectsClient: listObjectsClient,
+		s3Bucket:          s3Bucket,
+		s3Prefix:          s3Prefix,
+		s3Partition:       s3Partition,
+		now:               now,
+		listings:          make(map[string]partitionListing),
+	}
+}
+
+// partitionLevel is a level of the partition hierarchy of a time.
+type partitionLevel struct {
+	name string
+	end  time.Time
+}
+
+func (idx *s3PartitionIndex) levels(t time.Time) []partitionLevel {
+	year, month, day := t.Date()
+	hour, minute, _ := t.Clock()
+	startOfDay := time.Date(year, month, day, 0, 0, 0, 0, t.Location())
+	levels := []partitionLevel{
+		{name: fmt.Sprintf("year=%d/", year), end: time.Date(year+1, 1, 1, 0, 0, 0, 0, t.Location())},
+		{name: fmt.Sprintf("month=%02d/", month), end: time.Date(year, month+1, 1, 0, 0, 0, 0, t.Location())},
+		{name: fmt.Sprintf("day=%02d/", day), end: startOfDay.AddDate(0, 0, 1)},
+	}
+	if idx.s3Partition == S3PartitionDay {
+		return levels
+	}
+	levels = append(levels, partitionLevel{
+		name: fmt.Sprintf("hour=%02d/", hour),
+		end:  startOfDay.Add(time.Duration(hour+1) * time.Hour),
+	})
+	if idx.s3Partition == S3PartitionMinute {
+		levels = append(levels, partitionLevel{
+			name: fmt.Sprintf("minute=%02d/", minute),
+			end:  startOfDay.Add(time.Duration(hour)*time.Hour + time.Duration(minute+1)*time.Minute),
+		})
+	}
+	return levels
+}
+
+// mayHoldObjects reports whether the partition starting at t may hold objects.
+// A partition that is not over yet may still be written to, so it is only
+// considered empty if it was missing from a listing made once it was over.
+func (idx *s3PartitionIndex) mayHoldObjects(ctx context.Context, t time.Time) (bool, error) {
+	parent := ""
+	if idx.s3Prefix != "" {
+		parent = idx.s3Prefix + "/"
+	}
+	now := idx.now()
+	for _, level := range idx.levels(t) {
+		prefix := parent + level.name
+		if !level.end.After(now) {
+			listing, ok := idx.listings[parent]
+			if !ok || listing.listedAt.Before(level.end) {
+				var err error
+				if listing, err = idx.list(ctx, parent, now); err != nil {
+					return false, err
+				}
+			}
+			if _, ok := listing.children[prefix]; !ok {
+				return false, nil
+			}
+		}
+		parent = prefix
+	}
+	return true, nil
+}
+
+// list lists the prefixes of the partitions directly below parent.
+func (idx *s3PartitionIndex) list(ctx context.Context, parent string, now time.Time) (partitionListing, error) {
+	children := make(map[string]struct{})
+	p := idx.listObjectsClient.NewListObjectsV2Paginator(&s3.ListObjectsV2Input{
+		Bucket:    &idx.s3Bucket,
+		Prefix:    &parent,
+		Delimiter: aws.String("/"),
+	})
+	for p.HasMorePages() {
+		page, err := p.NextPage(ctx)
+		if err != nil {
+			return partitionListing{}, err
+		}
+		for _, commonPrefix := range page.CommonPrefixes {
+			children[aws.ToString(commonPrefix.Prefix)] = struct{}{}
+		}
+	}
+	listing := partitionListing{listedAt: now, children: children}
+	idx.listings[parent] = listing
+	return listing, nil
+}
@@ -0,0 +1,149 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/stretchr/testify/require"
+)
+
+// newMockBucketListObjectsAPI lists the given keys, honoring the prefix and
+// delimiter of the requests, and records the prefix of each request.
+func newMockBucketListObjectsAPI(keys []string, requests *[]string) mockListObjectsAPI {
+	return func(params *s3.ListObjectsV2Input) ListObjectsV2Pager {
+		prefix := aws.ToString(params.Prefix)
+		delimiter := aws.ToString(params.Delimiter)
+		*requests = append(*requests, prefix+delimiter)
+		output := &s3.ListObjectsV2Output{}
+		commonPrefixes := make(map[string]struct{})
+		for _, key := range keys {
+			if !strings.HasPrefix(key, prefix) {
+				continue
+			}
+			if delimiter != "" {
+				if i := strings.Index(key[len(prefix):], delimiter); i >= 0 {
+					commonPrefixes[key[:len(prefix)+i+len(delimiter)]] = struct{}{}
+					continue
+				}
+			}
+			output.Contents = append(output.Contents, types.Object{Key: aws.String(key)})
+		}
+		for commonPrefix := range commonPrefixes {
+			output.CommonPrefixes = append(output.CommonPrefixes, types.CommonPrefix{Prefix: aws.String(commonPrefix)})
+		}
+		sort.Slice(output.CommonPrefixes, func(i, j int) bool {
+			return *output.CommonPrefixes[i].Prefix < *output.CommonPrefixes[j].Prefix
+		})
+		return &mockListObjectsV2Pager{Pages: []*s3.ListObjectsV2Output{output}}
+	}
+}
+
+func Test_s3PartitionIndex_mayHoldObjects(t *testing.T) {
+	var requests []string
+	keys := []string{
+		"prefix/year=2021/month=02/day=01/hour=17/minute=32/traces_1",
+		"prefix/year=2021/month=02/day=03/hour=02/minute=00/traces_1",
+	}
+	now := func() time.Time {
+		return time.Date(2021, 2, 3, 2, 30, 0, 0, time.UTC)
+	}
+	idx := newS3PartitionIndex(newMockBucketListObjectsAPI(keys, &requests), "bucket", "prefix", S3PartitionMinute, now)
+
+	for _, tt := range []struct {
+		time time.Time
+		want bool
+	}{
+		{time: testTime, want: true},
+		{time: testTime.Add(time.Minute), want: false},
+		{time: testTime.Add(time.Hour), want: false},
+		{time: testTime.Add(24 * time.Hour), want: false},
+		{time: time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC), want: false},
+		{time: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC), want: false},
+		// The day and hour being listed are not over yet.
+		{time: time.Date(2021, 2, 3, 2, 0, 0, 0, time.UTC), want: true},
+		{time: time.Date(2021, 2, 3, 2, 1, 0, 0, time.UTC), want: false},
+		{time: time.Date(2021, 2, 3, 2, 30, 0, 0, time.UTC), want: true},
+	} {
+		ok, err := idx.mayHoldObjects(context.Background(), tt.time)
+		require.NoError(t, err)
+		require.Equal(t, tt.want, ok, tt.time)
+	}
+	// The year and month being written to are only listed to check the
+	// partitions below them that are over.
+	require.Equal(t, []string{
+		"prefix/year=2021/month=02//",
+		"prefix/year=2021/month=02/day=01//",
+		"prefix/year=2021/month=02/day=01/hour=17//",
+		"prefix/year=2021//",
+		"prefix//",
+		"prefix/year=2021/month=02/day=03/hour=02//",
+	}, requests)
+}
+
+func Test_s3PartitionIndex_mayHoldObjects_Error(t *testing.T) {
+	idx := newS3PartitionIndex(mockListObjectsAPI(func(_ *s3.ListObjectsV2Input) ListObjectsV2Pager {
+		return &mockListObjectsV2Pager{
+			Pages: []*s3.ListObjectsV2Output{{}},
+			Error: errors.New("test error"),
+		}
+	}), "bucket", "", S3PartitionHour, time.Now)
+	_, err := idx.mayHoldObjects(context.Background(), testTime)
+	require.EqualError(t, err, "test error")
+}
+
+func Test_readAll_SkipEmptyPartitions(t *testing.T) {
+	var requests []string
+	keys := []string{
+		"year=2021/month=02/day=01/hour=17/traces_1",
+		"year=2021/month=02/day=02/hour=03/traces_1",
+		"year=2021/month=02/day=02/hour=03/logs_1",
+	}
+	listObjectsClient := newMockBucketListObjectsAPI(keys, &requests)
+	reader := s3Reader{
+		listObjectsClient: listObjectsClient,
+		getObjectClient: mockGetObjectAPI(func(_ context.Context, _ *s3.GetObjectInput, _ ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
+			return &s3.GetObjectOutput{
+				Body: io.NopCloser(bytes.NewReader([]byte("this is the body of the object"))),
+			}, nil
+		}),
+		partitionIndex: newS3PartitionIndex(listObjectsClient, "bucket", "", S3PartitionHour, time.Now),
+		s3Bucket:       "bucket",
+		s3Partition:    S3PartitionHour,
+		startTime:      time.Date(2021, 2, 1, 0, 0, 0, 0, time.UTC),
+		endTime:        time.Date(2021, 2, 8, 0, 0, 0, 0, time.UTC),
+	}
+
+	var received []string
+	err := reader.readAll(context.Background(), "traces", func(_ context.Context, key string, _ io.Reader) error {
+		received = append(received, key)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"year=2021/month=02/day=01/hour=17/traces_1",
+		"year=2021/month=02/day=02/hour=03/traces_1",
+	}, received)
+	// The partition prefixes are listed once per level, and only the partitions
+	// holding objects are listed in full.
+	require.Equal(t, []string{
+		"/",
+		"year=2021//",
+		"year=2021/month=02//",
+		"year=2021/month=02/day=01//",
+		"year=2021/month=02/day=01/hour=17/traces_",
+		"year=2021/month=02/day=02//",
+		"year=2021/month=02/day=02/hour=03/traces_",
+	}, requests)
+}
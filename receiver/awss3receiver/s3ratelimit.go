@@ -0,0 +1,99 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"context"
+	"io"
+	"math"
+	"net/http"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/smithy-go/middleware"
+	"golang.org/x/time/rate"
+)
+
+// rateLimitOptions returns the options of the S3 client limiting the rate of its
+// requests and of the data it receives.
+func rateLimitOptions(cfg S3RateLimitConfig) func(*s3.Options) {
+	return func(o *s3.Options) {
+		if cfg.RequestsPerSecond > 0 {
+			o.APIOptions = append(o.APIOptions, rateLimitMiddleware(newRateLimiter(cfg)))
+		}
+		if cfg.BytesPerSecond > 0 {
+			o.HTTPClient = &throttledHTTPClient{client: o.HTTPClient, limiter: newBandwidthLimiter(cfg)}
+		}
+	}
+}
+
+// newRateLimiter returns the token bucket limiting the requests to S3, its burst
+// defaulting to the number of requests allowed per second.
+func newRateLimiter(cfg S3RateLimitConfig) *rate.Limiter {
+	burst := cfg.Burst
+	if burst == 0 {
+		burst = max(1, int(math.Ceil(cfg.RequestsPerSecond)))
+	}
+	return rate.NewLimiter(rate.Limit(cfg.RequestsPerSecond), burst)
+}
+
+// newBandwidthLimiter returns the token bucket limiting the data received from
+// S3, a token per byte, holding up to the bytes allowed per second.
+func newBandwidthLimiter(cfg S3RateLimitConfig) *rate.Limiter {
+	return rate.NewLimiter(rate.Limit(cfg.BytesPerSecond), int(min(cfg.BytesPerSecond, math.MaxInt32)))
+}
+
+// rateLimitMiddleware returns the API option waiting for limiter to allow each
+// attempt of the requests, the retries of a throttled request included.
+func rateLimitMiddleware(limiter *rate.Limiter) func(*middleware.Stack) error {
+	return func(stack *middleware.Stack) error {
+		wait := middleware.FinalizeMiddlewareFunc("RateLimit", func(ctx context.Context, in middleware.FinalizeInput, next middleware.FinalizeHandler) (middleware.FinalizeOutput, middleware.Metadata, error) {
+			if err := limiter.Wait(ctx); err != nil {
+				return middleware.FinalizeOutput{}, middleware.Metadata{}, err
+			}
+			return next.HandleFinalize(ctx, in)
+		})
+		return stack.Finalize.Insert(wait, "Retry", middleware.After)
+	}
+}
+
+// throttledHTTPClient sends the requests to S3 through client, the bodies of the
+// responses being read no faster than limiter allows. Reading the bodies slowly
+// lets TCP flow control slow down the transfers.
+type throttledHTTPClient struct {
+	client  s3.HTTPClient
+	limiter *rate.Limiter
+}
+
+func (c *throttledHTTPClient) Do(req *http.Request) (*http.Response, error) {
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return resp, err
+	}
+	resp.Body = &throttledBody{ctx: req.Context(), body: resp.Body, limiter: c.limiter}
+	return resp, nil
+}
+
+// throttledBody is the body of a response read no faster than limiter allows.
+type throttledBody struct {
+	ctx     context.Context
+	body    io.ReadCloser
+	limiter *rate.Limiter
+}
+
+func (b *throttledBody) Read(p []byte) (int, error) {
+	if len(p) > b.limiter.Burst() {
+		p = p[:b.limiter.Burst()]
+	}
+	n, err := b.body.Read(p)
+	if n > 0 {
+		if waitErr := b.limiter.WaitN(b.ctx, n); waitErr != nil {
+			return n, waitErr
+		}
+	}
+	return n, err
+}
+
+func (b *throttledBody) Close() error {
+	return b.body.Close()
+}
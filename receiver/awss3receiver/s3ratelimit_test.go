@@ -0,0 +1,94 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/smithy-go/middleware"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/time/rate"
+)
+
+func Test_newRateLimiter(t *testing.T) {
+	limiter := newRateLimiter(S3RateLimitConfig{RequestsPerSecond: 2.5})
+	require.Equal(t, rate.Limit(2.5), limiter.Limit())
+	require.Equal(t, 3, limiter.Burst())
+	require.Equal(t, 1, newRateLimiter(S3RateLimitConfig{RequestsPerSecond: 0.1}).Burst())
+	require.Equal(t, 10, newRateLimiter(S3RateLimitConfig{RequestsPerSecond: 1, Burst: 10}).Burst())
+}
+
+func Test_rateLimitMiddleware(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		// The first attempt of each request is throttled.
+		if requests.Add(1)%2 == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = w.Write([]byte("<Error><Code>SlowDown</Code></Error>"))
+			return
+		}
+		_, _ = w.Write([]byte("object"))
+	}))
+	defer server.Close()
+
+	limiter := rate.NewLimiter(rate.Every(20*time.Millisecond), 1)
+	client := s3.New(s3.Options{
+		Region:       "us-east-1",
+		BaseEndpoint: aws.String(server.URL),
+		UsePathStyle: true,
+		Credentials:  aws.AnonymousCredentials{},
+		APIOptions:   []func(*middleware.Stack) error{rateLimitMiddleware(limiter)},
+		Retryer: retry.NewStandard(func(o *retry.StandardOptions) {
+			o.Backoff = retry.BackoffDelayerFunc(func(int, error) (time.Duration, error) {
+				return 0, nil
+			})
+		}),
+	})
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		data, _, err := getObject(context.Background(), client, &s3.GetObjectInput{Bucket: aws.String("bucket"), Key: aws.String("key")})
+		require.NoError(t, err)
+		require.Equal(t, "object", string(data))
+	}
+	// Each attempt waits for the limiter, the first one excepted.
+	require.Equal(t, int32(6), requests.Load())
+	require.GreaterOrEqual(t, time.Since(start), 5*20*time.Millisecond)
+
+	// The requests stop waiting once their context is done.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, _, err := getObject(ctx, client, &s3.GetObjectInput{Bucket: aws.String("bucket"), Key: aws.String("key")})
+	require.Error(t, err)
+	require.Equal(t, int32(6), requests.Load())
+}
+
+func Test_rateLimitOptions_BytesPerSecond(t *testing.T) {
+	object := bytes.Repeat([]byte("a"), 60000)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write(object)
+	}))
+	defer server.Close()
+
+	client := s3.New(s3.Options{
+		Region:       "us-east-1",
+		BaseEndpoint: aws.String(server.URL),
+		UsePathStyle: true,
+		Credentials:  aws.AnonymousCredentials{},
+	}, rateLimitOptions(S3RateLimitConfig{BytesPerSecond: 50000}))
+	start := time.Now()
+	data, _, err := getObject(context.Background(), client, &s3.GetObjectInput{Bucket: aws.String("bucket"), Key: aws.String("key")})
+	require.NoError(t, err)
+	require.Equal(t, object, data)
+	// The bytes beyond the first second worth of data wait for the limiter.
+	require.GreaterOrEqual(t, time.Since(start), 200*time.Millisecond)
+}
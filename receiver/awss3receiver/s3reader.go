@@ -8,9 +8,14 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"path"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/lestrrat-go/strftime"
+	"go.uber.org/zap"
 )
 
 type s3Reader struct {
@@ -19,119 +24,680 @@ type s3Reader struct {
 	s3Bucket          string
 	s3Prefix          string
 	s3Partition       string
-	filePrefix        string
-	startTime         time.Time
-	endTime           time.Time
+	// partitionFormat is set when the key prefixes of the partitions follow an
+	// s3_partition_format rather than the layout of the AWS S3 exporter.
+	partitionFormat *strftime.Strftime
+	filePrefix      string
+	naming          objectNaming
+	startTime       time.Time
+	endTime         time.Time
+	pollInterval    time.Duration
+	// newestFirst is set when the partitions of the time range are read from the
+	// most recent to the oldest.
+	newestFirst bool
+	// partitionConcurrency is the number of partitions read at the same time.
+	partitionConcurrency int
+	// mu guards position, lastKey and reading, read by the checkpoint while
+	// reading.
+	mu sync.Mutex
+	// position is the start of the oldest partition being read, in reading order,
+	// or of the last partition read once none is being read.
+	position time.Time
+	// lastKey is the key of the last object read from the partition at position.
+	lastKey string
+	// reading are the partitions being read, in reading order, along with the
+	// partitions read after them while they were being read.
+	reading []partitionProgress
+	// resumeKey is set when resuming from a checkpoint to the key of the last
+	// object read from the partition at resumePartition, or to start_after.
+	resumeKey       string
+	resumePartition time.Time
+	// maxKeys, if not zero, is the number of objects listed in each page.
+	maxKeys int32
+	now     func() time.Time
+	// listObjectVersionsClient is set when the versions of the objects current
+	// at versionsAsOf are read instead of the current objects. A zero versionsAsOf
+	// means the time of each listing.
+	listObjectVersionsClient ListObjectVersionsAPI
+	versionsAsOf             time.Time
+	// restorer is set when archived objects are restored before being retrieved.
+	restorer *s3ObjectRestorer
+	// partitionIndex is set when empty partitions are skipped without being listed.
+	partitionIndex *s3PartitionIndex
+	// prefetcher is set when the objects are retrieved ahead of their consumption.
+	prefetcher *prefetcher
+	// rangedGetter is set when the large objects are retrieved in concurrent ranges.
+	rangedGetter *rangedGetter
+	// sseCustomerKey is set when the objects are encrypted with a customer-provided
+	// key.
+	sseCustomerKey *sseCustomerKey
+	// selector is set when the records of the JSON and CSV objects are selected
+	// with S3 Select.
+	selector *objectSelector
+	// accessChecker is set when the access to the bucket is checked at startup.
+	accessChecker *s3AccessChecker
+	// lookback is how far back partitions are listed again in continuous mode to
+	// pick up the objects written to them late.
+	lookback time.Duration
+	// processedKeys holds, in continuous mode, the keys already read from the
+	// partition being polled and from the partitions of the lookback window, by
+	// partition start time.
+	processedKeys map[time.Time]map[string]struct{}
 }
 
-type s3ReaderDataCallback func(context.Context, string, []byte) error
+// resumeTimeLayout is the layout of the times in the reported resume positions,
+// one of the layouts accepted for starttime and endtime.
+const resumeTimeLayout = "2006-01-02 15:04"
 
-func newS3Reader(ctx context.Context, cfg *Config) (*s3Reader, error) {
+// s3ReaderDataCallback processes the contents of an object, read from body. The
+// body is only valid until the callback returns.
+type s3ReaderDataCallback func(ctx context.Context, key string, body io.Reader) error
+
+// partitionProgress is the progress of the read of a partition.
+type partitionProgress struct {
+	start   time.Time
+	lastKey string
+	done    bool
+}
+
+// objectNaming overrides the names of the objects holding telemetry, which by
+// default follow the layout of the AWS S3 exporter: {file_prefix}{telemetry type}_.
+type objectNaming struct {
+	telemetryName string
+	separator     *string
+}
+
+// namePrefix returns the prefix of the names of the objects holding telemetry of the given type.
+func (n objectNaming) namePrefix(filePrefix, telemetryType string) string {
+	name := telemetryType
+	if n.telemetryName != "" {
+		name = n.telemetryName
+	}
+	separator := "_"
+	if n.separator != nil {
+		separator = *n.separator
+	}
+	return filePrefix + name + separator
+}
+
+func newS3Reader(ctx context.Context, cfg *Config, naming objectNaming) (*s3Reader, error) {
 	listObjectsClient, getObjectClient, err := newS3Client(ctx, cfg.S3Downloader)
 	if err != nil {
 		return nil, err
 	}
+	if cfg.S3Downloader.Inventory != nil {
+		listObjectsClient = newS3InventoryListObjectsAPI(listObjectsClient, getObjectClient, cfg.S3Downloader)
+	}
 	startTime, err := parseTime(cfg.StartTime, "starttime")
 	if err != nil {
 		return nil, err
 	}
-	endTime, err := parseTime(cfg.EndTime, "endtime")
+	var endTime time.Time
+	if cfg.EndTime != "" {
+		if endTime, err = parseTime(cfg.EndTime, "endtime"); err != nil {
+			return nil, err
+		}
+	}
+	if !isValidPartition(cfg.S3Downloader.S3Partition) {
+		return nil, errInvalidPartition
+	}
+	var listObjectVersionsClient ListObjectVersionsAPI
+	versionsAsOf := endTime
+	if cfg.S3Downloader.Versions != nil {
+		var ok bool
+		if listObjectVersionsClient, ok = listObjectsClient.(ListObjectVersionsAPI); !ok {
+			return nil, errors.New("listing object versions is not supported together with inventory")
+		}
+		if cfg.S3Downloader.Versions.AsOf != "" {
+			if versionsAsOf, err = parseTime(cfg.S3Downloader.Versions.AsOf, "versions as_of"); err != nil {
+				return nil, err
+			}
+		}
+	}
+	var partitionFormat *strftime.Strftime
+	if cfg.S3Downloader.S3PartitionFormat != "" {
+		if partitionFormat, err = strftime.New(cfg.S3Downloader.S3PartitionFormat); err != nil {
+			return nil, fmt.Errorf("invalid s3_partition_format: %w", err)
+		}
+	}
+	var restorer *s3ObjectRestorer
+	if cfg.S3Downloader.Restore != nil {
+		restoreClient, ok := getObjectClient.(RestoreObjectAPI)
+		if !ok {
+			return nil, errors.New("restoring archived objects is not supported by the S3 client")
+		}
+		restorer = newS3ObjectRestorer(restoreClient, *cfg.S3Downloader.Restore)
+		restorer.sseCustomerKey = newSSECustomerKey(cfg.S3Downloader.SSECustomerKey)
+	}
+
+	selector, err := newObjectSelector(getObjectClient, cfg.S3Downloader.Select)
+	if err != nil {
+		return nil, err
+	}
+	accessChecker, err := newS3AccessChecker(cfg.S3Downloader, listObjectsClient, getObjectClient)
 	if err != nil {
 		return nil, err
 	}
-	if cfg.S3Downloader.S3Partition != S3PartitionHour && cfg.S3Downloader.S3Partition != S3PartitionMinute {
-		return nil, errors.New("s3_partition must be either 'hour' or 'minute'")
+	decrypter, err := newObjectDecrypter(ctx, cfg.S3Downloader)
+	if err != nil {
+		return nil, err
+	}
+	getObjectClient = decrypter.wrap(getObjectClient)
+	timeouts := newCallTimeouts(cfg.S3Downloader.Timeouts)
+	getObjectClient = timeouts.wrapGetObject(getObjectClient)
+	// The objects of an inventory report are listed from the report once
+	// retrieved, without calls to S3.
+	if cfg.S3Downloader.Inventory == nil {
+		listObjectsClient = timeouts.wrapListObjects(listObjectsClient)
+	}
+	listObjectVersionsClient = timeouts.wrapListObjectVersions(listObjectVersionsClient)
+
+	var partitionIndex *s3PartitionIndex
+	// The objects listed from an inventory report or as versions are not all
+	// current objects, so their partitions are listed in full.
+	if cfg.S3Downloader.SkipEmptyPartitions && cfg.S3Downloader.Inventory == nil && cfg.S3Downloader.Versions == nil {
+		partitionIndex = newS3PartitionIndex(listObjectsClient, cfg.S3Downloader.S3Bucket, cfg.S3Downloader.S3Prefix, cfg.S3Downloader.S3Partition, time.Now)
 	}
 
-	return &s3Reader{
+	reader := &s3Reader{
 		listObjectsClient: listObjectsClient,
 		getObjectClient:   getObjectClient,
 		s3Bucket:          cfg.S3Downloader.S3Bucket,
 		s3Prefix:          cfg.S3Downloader.S3Prefix,
 		filePrefix:        cfg.S3Downloader.FilePrefix,
+		naming:            naming,
 		s3Partition:       cfg.S3Downloader.S3Partition,
+		partitionFormat:   partitionFormat,
 		startTime:         startTime,
 		endTime:           endTime,
-	}, nil
+		pollInterval:      cfg.PollInterval,
+		lookback:          cfg.Lookback,
+		newestFirst:       cfg.ReplayOrder == ReplayOrderNewestFirst,
+		now:               time.Now,
+
+		listObjectVersionsClient: listObjectVersionsClient,
+		versionsAsOf:             versionsAsOf,
+		restorer:                 restorer,
+		partitionIndex:           partitionIndex,
+		prefetcher:               newPrefetcher(cfg.S3Downloader.Prefetch),
+		partitionConcurrency:     cfg.S3Downloader.PartitionConcurrency,
+		rangedGetter:             newRangedGetter(cfg.S3Downloader.RangedGet),
+		selector:                 selector,
+		accessChecker:            accessChecker,
+		sseCustomerKey:           newSSECustomerKey(cfg.S3Downloader.SSECustomerKey),
+		maxKeys:                  cfg.S3Downloader.MaxKeys,
+	}
+	if cfg.S3Downloader.StartAfter != "" {
+		reader.resumePartition, _ = reader.firstPartition()
+		reader.resumeKey = cfg.S3Downloader.StartAfter
+	}
+	// A resume token set as the bound the reader moves away from resumes reading
+	// after the last object read.
+	resumeSetting := cfg.StartTime
+	if reader.newestFirst {
+		resumeSetting = cfg.EndTime
+	}
+	if c, ok, _ := parseResumeToken(resumeSetting); ok {
+		if reader.newestFirst {
+			reader.endTime = c.Position.Add(partitionTimeStep(reader.s3Partition))
+		}
+		reader.resumeFrom(c)
+	}
+	return reader, nil
+}
+
+// checkAccess checks that the bucket can be read, if startup_check is set.
+func (s3Reader *s3Reader) checkAccess(ctx context.Context, logger *zap.Logger) error {
+	return s3Reader.accessChecker.check(ctx, logger)
 }
 
 func (s3Reader *s3Reader) readAll(ctx context.Context, telemetryType string, dataCallback s3ReaderDataCallback) error {
-	var timeStep time.Duration
-	if s3Reader.s3Partition == "hour" {
-		timeStep = time.Hour
-	} else {
-		timeStep = time.Minute
+	timeStep := partitionTimeStep(s3Reader.s3Partition)
+	currentTime, step := s3Reader.firstPartition()
+	if s3Reader.partitionConcurrency > 1 {
+		return s3Reader.readPartitions(ctx, currentTime, step, telemetryType, dataCallback)
 	}
-
-	for currentTime := s3Reader.startTime; currentTime.Before(s3Reader.endTime); currentTime = currentTime.Add(timeStep) {
+	for ; s3Reader.inTimeRange(currentTime); currentTime = currentTime.Add(step) {
 		select {
 		case <-ctx.Done():
 			return nil
 		default:
-			if err := s3Reader.readTelemetryForTime(ctx, currentTime, telemetryType, dataCallback); err != nil {
+			s3Reader.startPartition(currentTime)
+			if s3Reader.partitionIndex != nil {
+				ok, err := s3Reader.partitionIndex.mayHoldObjects(ctx, currentTime)
+				if err != nil {
+					return err
+				}
+				if !ok {
+					s3Reader.completePartition(currentTime)
+					continue
+				}
+			}
+			if s3Reader.pollInterval > 0 {
+				if err := s3Reader.pollTelemetryForTime(ctx, currentTime, timeStep, telemetryType, dataCallback); err != nil {
+					return err
+				}
+			} else if err := s3Reader.readTelemetryForTime(ctx, currentTime, telemetryType, dataCallback); err != nil {
 				return err
 			}
+			if ctx.Err() == nil {
+				s3Reader.completePartition(currentTime)
+			}
 		}
 	}
 	return nil
 }
 
+// firstPartition returns the start of the first partition read, and the step
+// from each partition to the next one read.
+func (s3Reader *s3Reader) firstPartition() (time.Time, time.Duration) {
+	timeStep := partitionTimeStep(s3Reader.s3Partition)
+	if s3Reader.newestFirst {
+		partitions := (s3Reader.endTime.Sub(s3Reader.startTime) + timeStep - 1) / timeStep
+		return s3Reader.startTime.Add((partitions - 1) * timeStep), -timeStep
+	}
+	return s3Reader.startTime, timeStep
+}
+
+// readPartitions reads up to partitionConcurrency partitions at the same time,
+// starting with the partition at currentTime and moving by step, and stops at
+// the first error once the partitions being read have been stopped.
+func (s3Reader *s3Reader) readPartitions(ctx context.Context, currentTime time.Time, step time.Duration, telemetryType string, dataCallback s3ReaderDataCallback) error {
+	readCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var (
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		firstErr error
+	)
+	fail := func(err error) {
+		errOnce.Do(func() {
+			firstErr = err
+			cancel()
+		})
+	}
+	sem := make(chan struct{}, s3Reader.partitionConcurrency)
+	for ; s3Reader.inTimeRange(currentTime); currentTime = currentTime.Add(step) {
+		select {
+		case <-readCtx.Done():
+		case sem <- struct{}{}:
+		}
+		if readCtx.Err() != nil {
+			break
+		}
+		s3Reader.startPartition(currentTime)
+		if s3Reader.partitionIndex != nil {
+			ok, err := s3Reader.partitionIndex.mayHoldObjects(readCtx, currentTime)
+			if err != nil {
+				<-sem
+				fail(err)
+				break
+			}
+			if !ok {
+				<-sem
+				s3Reader.completePartition(currentTime)
+				continue
+			}
+		}
+		wg.Add(1)
+		go func(t time.Time) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			if err := s3Reader.readTelemetryForTime(readCtx, t, telemetryType, dataCallback); err != nil {
+				fail(err)
+				return
+			}
+			if readCtx.Err() == nil {
+				s3Reader.completePartition(t)
+			}
+		}(currentTime)
+	}
+	wg.Wait()
+	return firstErr
+}
+
+// startPartition records the start of the read of the partition starting at t.
+func (s3Reader *s3Reader) startPartition(t time.Time) {
+	s3Reader.mu.Lock()
+	defer s3Reader.mu.Unlock()
+	s3Reader.reading = append(s3Reader.reading, partitionProgress{start: t})
+	if len(s3Reader.reading) == 1 {
+		s3Reader.position, s3Reader.lastKey = t, ""
+	}
+}
+
+// completePartition records that the partition starting at t has been read in
+// full. The position moves past the partitions read in full, up to the oldest
+// partition still being read.
+func (s3Reader *s3Reader) completePartition(t time.Time) {
+	s3Reader.mu.Lock()
+	defer s3Reader.mu.Unlock()
+	for i := range s3Reader.reading {
+		if s3Reader.reading[i].start.Equal(t) {
+			s3Reader.reading[i].done = true
+		}
+	}
+	for len(s3Reader.reading) > 0 && s3Reader.reading[0].done {
+		s3Reader.position, s3Reader.lastKey = s3Reader.reading[0].start, s3Reader.reading[0].lastKey
+		s3Reader.reading = s3Reader.reading[1:]
+	}
+	if len(s3Reader.reading) > 0 {
+		s3Reader.position, s3Reader.lastKey = s3Reader.reading[0].start, s3Reader.reading[0].lastKey
+	}
+}
+
+// resumePosition returns the time range setting to resume reading from the start
+// of the partition being read.
+func (s3Reader *s3Reader) resumePosition() string {
+	s3Reader.mu.Lock()
+	position := s3Reader.position
+	s3Reader.mu.Unlock()
+	if position.IsZero() {
+		position = s3Reader.startTime
+	}
+	if s3Reader.newestFirst {
+		return fmt.Sprintf("endtime: %q", position.Add(partitionTimeStep(s3Reader.s3Partition)).Format(resumeTimeLayout))
+	}
+	return fmt.Sprintf("starttime: %q", position.Format(resumeTimeLayout))
+}
+
+// checkpoint returns the position of the reader, along with the key of the last
+// object read from the partition at the position.
+func (s3Reader *s3Reader) checkpoint() checkpoint {
+	s3Reader.mu.Lock()
+	defer s3Reader.mu.Unlock()
+	if s3Reader.position.IsZero() {
+		return checkpoint{}
+	}
+	return checkpoint{Position: s3Reader.position, Key: s3Reader.lastKey}
+}
+
+// readProgress returns the share of the time range read in full, the partitions
+// before the position in reading order, or up to the position once no partition
+// is being read.
+func (s3Reader *s3Reader) readProgress() (float64, bool) {
+	if s3Reader.endTime.IsZero() {
+		return 0, false
+	}
+	s3Reader.mu.Lock()
+	position, reading := s3Reader.position, len(s3Reader.reading) > 0
+	s3Reader.mu.Unlock()
+	total := s3Reader.endTime.Sub(s3Reader.startTime)
+	if position.IsZero() || total <= 0 {
+		return 0, true
+	}
+	timeStep := partitionTimeStep(s3Reader.s3Partition)
+	var read time.Duration
+	switch {
+	case s3Reader.newestFirst && reading:
+		read = s3Reader.endTime.Sub(position.Add(timeStep))
+	case s3Reader.newestFirst:
+		read = s3Reader.endTime.Sub(position)
+	case reading:
+		read = position.Sub(s3Reader.startTime)
+	default:
+		read = position.Add(timeStep).Sub(s3Reader.startTime)
+	}
+	return float64(min(max(read, 0), total)) / float64(total), true
+}
+
+// resumeFrom restricts the time range to resume reading from the partition of
+// the checkpoint, after the last object read from it. It reports whether the
+// checkpoint is part of the time range.
+func (s3Reader *s3Reader) resumeFrom(c checkpoint) bool {
+	if !s3Reader.inTimeRange(c.Position) {
+		return false
+	}
+	if s3Reader.newestFirst {
+		s3Reader.endTime = c.Position.Add(partitionTimeStep(s3Reader.s3Partition))
+	} else {
+		s3Reader.startTime = c.Position
+	}
+	s3Reader.resumePartition, s3Reader.resumeKey = c.Position, c.Key
+	return true
+}
+
+// recordRead records the read of the object stored under key from the partition
+// starting at t.
+func (s3Reader *s3Reader) recordRead(t time.Time, key string) {
+	s3Reader.mu.Lock()
+	defer s3Reader.mu.Unlock()
+	for i := range s3Reader.reading {
+		if s3Reader.reading[i].start.Equal(t) {
+			s3Reader.reading[i].lastKey = key
+		}
+	}
+	if t.Equal(s3Reader.position) {
+		s3Reader.lastKey = key
+	}
+}
+
+// inTimeRange reports whether the partition starting at t is part of the time range.
+func (s3Reader *s3Reader) inTimeRange(t time.Time) bool {
+	return !t.Before(s3Reader.startTime) && (s3Reader.endTime.IsZero() || t.Before(s3Reader.endTime))
+}
+
+// pollTelemetryForTime repeatedly reads the partition starting at t until the
+// partition is complete, picking up objects written since the previous listing.
+// The partitions of the lookback window before t are read again along with it.
+func (s3Reader *s3Reader) pollTelemetryForTime(ctx context.Context, t time.Time, timeStep time.Duration, telemetryType string, dataCallback s3ReaderDataCallback) error {
+	if s3Reader.processedKeys == nil {
+		s3Reader.processedKeys = make(map[time.Time]map[string]struct{})
+	}
+	// The lookback window is rounded up to whole partitions.
+	lookbackPartitions := (s3Reader.lookback + timeStep - 1) / timeStep
+	windowStart := t.Add(-lookbackPartitions * timeStep)
+	if windowStart.Before(s3Reader.startTime) {
+		windowStart = s3Reader.startTime
+	}
+	// The keys of the partitions that left the lookback window cannot be listed again.
+	for partition := range s3Reader.processedKeys {
+		if partition.Before(windowStart) {
+			delete(s3Reader.processedKeys, partition)
+		}
+	}
+	for partition := windowStart; !partition.After(t); partition = partition.Add(timeStep) {
+		if _, ok := s3Reader.processedKeys[partition]; !ok {
+			s3Reader.processedKeys[partition] = make(map[string]struct{})
+		}
+	}
+	for {
+		// The partition is read one last time once it is over.
+		complete := !s3Reader.now().Before(t.Add(timeStep))
+		for partition := windowStart; partition.Before(t); partition = partition.Add(timeStep) {
+			if err := s3Reader.readTelemetryForTime(ctx, partition, telemetryType, dataCallback); err != nil {
+				return err
+			}
+		}
+		if err := s3Reader.readTelemetryForTime(ctx, t, telemetryType, dataCallback); err != nil {
+			return err
+		}
+		if complete {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(s3Reader.pollInterval):
+		}
+	}
+}
+
 func (s3Reader *s3Reader) readTelemetryForTime(ctx context.Context, t time.Time, telemetryType string, dataCallback s3ReaderDataCallback) error {
+	prefix := s3Reader.getObjectPrefixForTime(t, telemetryType)
+	processed := s3Reader.processedKeys[t]
+	var startAfter string
+	if t.Equal(s3Reader.resumePartition) {
+		startAfter = s3Reader.resumeKey
+	}
+	if s3Reader.listObjectVersionsClient != nil {
+		return s3Reader.readVersionsForPrefix(ctx, t, prefix, startAfter, processed, dataCallback)
+	}
 	params := &s3.ListObjectsV2Input{
 		Bucket: &s3Reader.s3Bucket,
+		Prefix: &prefix,
+	}
+	if startAfter != "" {
+		params.StartAfter = &startAfter
+	}
+	if s3Reader.maxKeys > 0 {
+		params.MaxKeys = &s3Reader.maxKeys
 	}
-	prefix := s3Reader.getObjectPrefixForTime(t, telemetryType)
-	params.Prefix = &prefix
 
-	p := s3Reader.listObjectsClient.NewListObjectsV2Paginator(params)
+	list := func(ctx context.Context, emit func(objectRef) bool) error {
+		p := s3Reader.listObjectsClient.NewListObjectsV2Paginator(params)
+		for p.HasMorePages() {
+			page, err := p.NextPage(ctx)
+			if err != nil {
+				return err
+			}
+			if s3Reader.restorer != nil {
+				// The restores of the archived objects of the page are all requested
+				// first so that they run in parallel.
+				for _, obj := range page.Contents {
+					if _, ok := processed[*obj.Key]; ok || !isArchived(obj.StorageClass) {
+						continue
+					}
+					if err := s3Reader.restorer.requestRestore(ctx, s3Reader.s3Bucket, *obj.Key); err != nil {
+						return err
+					}
+				}
+			}
+			for _, obj := range page.Contents {
+				if _, ok := processed[*obj.Key]; ok {
+					continue
+				}
+				if !emit(objectRef{key: *obj.Key, archived: s3Reader.restorer != nil && isArchived(obj.StorageClass)}) {
+					return nil
+				}
+			}
+		}
+		return nil
+	}
+	return s3Reader.readObjects(ctx, t, list, processed, dataCallback)
+}
 
-	for p.HasMorePages() {
-		page, err := p.NextPage(ctx)
+// readVersionsForPrefix reads the versions of the objects under prefix that were
+// current at the configured time, skipping the keys already processed and the
+// ones up to startAfter, of the partition starting at t.
+func (s3Reader *s3Reader) readVersionsForPrefix(ctx context.Context, t time.Time, prefix, startAfter string, processed map[string]struct{}, dataCallback s3ReaderDataCallback) error {
+	list := func(ctx context.Context, emit func(objectRef) bool) error {
+		asOf := s3Reader.versionsAsOf
+		if asOf.IsZero() {
+			asOf = s3Reader.now()
+		}
+		versions, err := listVersionsAsOf(ctx, s3Reader.listObjectVersionsClient, s3Reader.s3Bucket, prefix, asOf)
 		if err != nil {
 			return err
 		}
-		for _, obj := range page.Contents {
-			data, err := s3Reader.retrieveObject(ctx, *obj.Key)
-			if err != nil {
-				return err
+		for _, version := range versions {
+			if _, ok := processed[version.key]; ok || version.key <= startAfter {
+				continue
 			}
-			if err := dataCallback(ctx, *obj.Key, data); err != nil {
-				return err
+			if !emit(objectRef{key: version.key, versionID: version.versionID}) {
+				return nil
 			}
 		}
+		return nil
 	}
-	return nil
+	return s3Reader.readObjects(ctx, t, list, processed, dataCallback)
+}
+
+// readObjects retrieves the objects listed from the partition starting at t and
+// hands their contents to dataCallback, recording them as read. The keys read
+// are added to processed, if not nil, once the listed objects have all been read
+// or an error occurred.
+func (s3Reader *s3Reader) readObjects(ctx context.Context, t time.Time, list objectLister, processed map[string]struct{}, dataCallback s3ReaderDataCallback) error {
+	var read []string
+	defer func() {
+		if processed != nil {
+			for _, key := range read {
+				processed[key] = struct{}{}
+			}
+		}
+	}()
+	retrieve := func(ctx context.Context, ref objectRef) (io.ReadCloser, objectInfo, error) {
+		if ref.archived {
+			if err := s3Reader.restorer.waitForRestore(ctx, s3Reader.s3Bucket, ref.key); err != nil {
+				return nil, objectInfo{}, err
+			}
+		}
+		return s3Reader.retrieveObject(ctx, ref.key, ref.versionID)
+	}
+	consume := func(ctx context.Context, ref objectRef, body io.Reader, info objectInfo) error {
+		if err := dataCallback(contextWithObjectInfo(ctx, info), ref.key, body); err != nil {
+			return err
+		}
+		s3Reader.recordRead(t, ref.key)
+		read = append(read, ref.key)
+		return nil
+	}
+	return s3Reader.prefetcher.run(ctx, list, retrieve, consume)
 }
 
 func (s3Reader *s3Reader) getObjectPrefixForTime(t time.Time, telemetryType string) string {
+	if s3Reader.partitionFormat != nil {
+		if s3Reader.s3Prefix != "" {
+			return s3Reader.s3Prefix + "/" + s3Reader.partitionFormat.FormatString(t)
+		}
+		return s3Reader.partitionFormat.FormatString(t)
+	}
 	var timeKey string
 	switch s3Reader.s3Partition {
 	case S3PartitionMinute:
 		timeKey = getTimeKeyPartitionMinute(t)
 	case S3PartitionHour:
 		timeKey = getTimeKeyPartitionHour(t)
+	case S3PartitionDay:
+		timeKey = getTimeKeyPartitionDay(t)
 	}
+	namePrefix := s3Reader.naming.namePrefix(s3Reader.filePrefix, telemetryType)
 	if s3Reader.s3Prefix != "" {
-		return fmt.Sprintf("%s/%s/%s%s_", s3Reader.s3Prefix, timeKey, s3Reader.filePrefix, telemetryType)
+		return fmt.Sprintf("%s/%s/%s", s3Reader.s3Prefix, timeKey, namePrefix)
 	}
-	return fmt.Sprintf("%s/%s%s_", timeKey, s3Reader.filePrefix, telemetryType)
+	return fmt.Sprintf("%s/%s", timeKey, namePrefix)
+}
+
+// isTelemetryObject reports whether the name of the object stored under key follows
+// the naming used for objects holding telemetry of the given type.
+func isTelemetryObject(key, filePrefix string, naming objectNaming, telemetryType string) bool {
+	return strings.HasPrefix(path.Base(key), naming.namePrefix(filePrefix, telemetryType))
 }
 
-func (s3Reader *s3Reader) retrieveObject(ctx context.Context, key string) ([]byte, error) {
+// retrieveObject retrieves an object, or the given version of the object if
+// versionID is not empty, returning the body its contents are read from.
+func (s3Reader *s3Reader) retrieveObject(ctx context.Context, key, versionID string) (io.ReadCloser, objectInfo, error) {
 	params := s3.GetObjectInput{
 		Bucket: &s3Reader.s3Bucket,
 		Key:    &key,
 	}
-	output, err := s3Reader.getObjectClient.GetObject(ctx, &params)
-	if err != nil {
-		return nil, err
+	if versionID != "" {
+		params.VersionId = &versionID
 	}
-	defer output.Body.Close()
-	contents, err := io.ReadAll(output.Body)
-	if err != nil {
-		return nil, err
+	s3Reader.sseCustomerKey.getObject(&params)
+	if s3Reader.selector.selects(&params) {
+		return s3Reader.selector.openObject(ctx, &params)
 	}
-	return contents, nil
+	return s3Reader.rangedGetter.openObject(ctx, s3Reader.getObjectClient, &params)
+}
+
+// partitionTimeStep returns the time span of the partitions of the given granularity.
+func partitionTimeStep(partition string) time.Duration {
+	switch partition {
+	case S3PartitionDay:
+		return 24 * time.Hour
+	case S3PartitionHour:
+		return time.Hour
+	default:
+		return time.Minute
+	}
+}
+
+func getTimeKeyPartitionDay(t time.Time) string {
+	year, month, day := t.Date()
+	return fmt.Sprintf("year=%d/month=%02d/day=%02d", year, month, day)
 }
 
 func getTimeKeyPartitionHour(t time.Time) string {
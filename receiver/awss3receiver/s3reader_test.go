@@ -9,16 +9,25 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/lestrrat-go/strftime"
 	"github.com/stretchr/testify/require"
 )
 
 var testTime = time.Date(2021, 02, 01, 17, 32, 00, 00, time.UTC)
 
+func Test_getTimeKeyPartitionDay(t *testing.T) {
+	result := getTimeKeyPartitionDay(testTime)
+	require.Equal(t, "year=2021/month=02/day=01", result)
+}
+
 func Test_getTimeKeyPartitionHour(t *testing.T) {
 	result := getTimeKeyPartitionHour(testTime)
 	require.Equal(t, "year=2021/month=02/day=01/hour=17", result)
@@ -61,6 +70,16 @@ func Test_s3Reader_getObjectPrefixForTime(t *testing.T) {
 			},
 			want: "prefix/year=2021/month=02/day=01/hour=17/minute=32/filemetrics_",
 		},
+		{
+			name: "day, prefix and file prefix",
+			args: args{
+				s3Prefix:      "prefix",
+				s3Partition:   "day",
+				filePrefix:    "file",
+				telemetryType: "logs",
+			},
+			want: "prefix/year=2021/month=02/day=01/filelogs_",
+		},
 		{
 			name: "hour, prefix and no file prefix",
 			args: args{
@@ -95,12 +114,61 @@ func Test_s3Reader_getObjectPrefixForTime(t *testing.T) {
 	}
 }
 
+func Test_s3Reader_getObjectPrefixForTime_Naming(t *testing.T) {
+	dash := "-"
+	empty := ""
+	tests := []struct {
+		name   string
+		naming objectNaming
+		want   string
+	}{
+		{
+			name:   "default",
+			naming: objectNaming{},
+			want:   "prefix/year=2021/month=02/day=01/hour=17/filetraces_",
+		},
+		{
+			name:   "telemetry name",
+			naming: objectNaming{telemetryName: "spans"},
+			want:   "prefix/year=2021/month=02/day=01/hour=17/filespans_",
+		},
+		{
+			name:   "separator",
+			naming: objectNaming{separator: &dash},
+			want:   "prefix/year=2021/month=02/day=01/hour=17/filetraces-",
+		},
+		{
+			name:   "telemetry name and no separator",
+			naming: objectNaming{telemetryName: "spans", separator: &empty},
+			want:   "prefix/year=2021/month=02/day=01/hour=17/filespans",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			reader := s3Reader{
+				s3Prefix:    "prefix",
+				s3Partition: "hour",
+				filePrefix:  "file",
+				naming:      test.naming,
+			}
+			require.Equal(t, test.want, reader.getObjectPrefixForTime(testTime, "traces"))
+		})
+	}
+}
+
 type mockGetObjectAPI func(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error)
 
 func (m mockGetObjectAPI) GetObject(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
 	return m(ctx, params, optFns...)
 }
 
+// readBody reads the contents handed to a data callback.
+func readBody(t *testing.T, body io.Reader) string {
+	data, err := io.ReadAll(body)
+	require.NoError(t, err)
+	return string(data)
+}
+
 type mockListObjectsAPI func(params *s3.ListObjectsV2Input) ListObjectsV2Pager
 
 func (m mockListObjectsAPI) NewListObjectsV2Paginator(params *s3.ListObjectsV2Input) ListObjectsV2Pager {
@@ -130,6 +198,34 @@ func (m *mockListObjectsV2Pager) NextPage(_ context.Context, _ ...func(*s3.Optio
 	return output, nil
 }
 
+func Test_s3Reader_getObjectPrefixForTime_PartitionFormat(t *testing.T) {
+	partitionFormat, err := strftime.New("%Y/%m/%d/123456789012_elasticloadbalancing_us-east-1_app.my-lb_%Y%m%dT%H")
+	require.NoError(t, err)
+	reader := s3Reader{
+		s3Prefix:        "AWSLogs/123456789012/elasticloadbalancing/us-east-1",
+		s3Partition:     "hour",
+		partitionFormat: partitionFormat,
+		filePrefix:      "file",
+	}
+	require.Equal(t, "AWSLogs/123456789012/elasticloadbalancing/us-east-1/2021/02/01/123456789012_elasticloadbalancing_us-east-1_app.my-lb_20210201T17",
+		reader.getObjectPrefixForTime(testTime, "logs"))
+
+	reader.s3Prefix = ""
+	require.Equal(t, "2021/02/01/123456789012_elasticloadbalancing_us-east-1_app.my-lb_20210201T17", reader.getObjectPrefixForTime(testTime, "logs"))
+
+	// CloudFront writes the objects of a distribution flat, under an optional prefix.
+	reader.partitionFormat, err = strftime.New("EDFDVBD6EXAMPLE.%Y-%m-%d-%H.")
+	require.NoError(t, err)
+	require.Equal(t, "EDFDVBD6EXAMPLE.2021-02-01-17.", reader.getObjectPrefixForTime(testTime, "logs"))
+
+	// Security Lake partitions the objects of a source by region, account and day.
+	reader.s3Prefix = "aws/VPC_FLOW/1.0/region=us-east-1/accountId=123456789012"
+	reader.s3Partition = "day"
+	reader.partitionFormat, err = strftime.New("eventDay=%Y%m%d/")
+	require.NoError(t, err)
+	require.Equal(t, "aws/VPC_FLOW/1.0/region=us-east-1/accountId=123456789012/eventDay=20210201/", reader.getObjectPrefixForTime(testTime, "logs"))
+}
+
 func Test_readTelemetryForTime(t *testing.T) {
 	testKey1 := "year=2021/month=02/day=01/hour=17/minute=32/traces_1"
 	testKey2 := "year=2021/month=02/day=01/hour=17/minute=32/traces_2"
@@ -176,9 +272,9 @@ func Test_readTelemetryForTime(t *testing.T) {
 
 	dataCallbackKeys := make([]string, 0)
 
-	err := reader.readTelemetryForTime(context.Background(), testTime, "traces", func(_ context.Context, key string, data []byte) error {
+	err := reader.readTelemetryForTime(context.Background(), testTime, "traces", func(_ context.Context, key string, body io.Reader) error {
 		t.Helper()
-		require.Equal(t, "this is the body of the object", string(data))
+		require.Equal(t, "this is the body of the object", readBody(t, body))
 		dataCallbackKeys = append(dataCallbackKeys, key)
 		return nil
 	})
@@ -222,7 +318,7 @@ func Test_readTelemetryForTime_GetObjectError(t *testing.T) {
 		endTime:     testTime.Add(time.Minute),
 	}
 
-	err := reader.readTelemetryForTime(context.Background(), testTime, "traces", func(_ context.Context, _ string, _ []byte) error {
+	err := reader.readTelemetryForTime(context.Background(), testTime, "traces", func(_ context.Context, _ string, _ io.Reader) error {
 		t.Helper()
 		t.Fail()
 		return nil
@@ -256,7 +352,7 @@ func Test_readTelemetryForTime_ListObjectsNoResults(t *testing.T) {
 		endTime:     testTime.Add(time.Minute),
 	}
 
-	err := reader.readTelemetryForTime(context.Background(), testTime, "traces", func(_ context.Context, _ string, _ []byte) error {
+	err := reader.readTelemetryForTime(context.Background(), testTime, "traces", func(_ context.Context, _ string, _ io.Reader) error {
 		t.Helper()
 		t.Fail()
 		return nil
@@ -302,7 +398,7 @@ func Test_readTelemetryForTime_NextPageError(t *testing.T) {
 		endTime:     testTime.Add(time.Minute),
 	}
 
-	err := reader.readTelemetryForTime(context.Background(), testTime, "traces", func(_ context.Context, _ string, _ []byte) error {
+	err := reader.readTelemetryForTime(context.Background(), testTime, "traces", func(_ context.Context, _ string, _ io.Reader) error {
 		t.Helper()
 		t.Fail()
 		return nil
@@ -345,9 +441,9 @@ func Test_readAll(t *testing.T) {
 
 	dataCallbackKeys := make([]string, 0)
 
-	err := reader.readAll(context.Background(), "traces", func(_ context.Context, key string, data []byte) error {
+	err := reader.readAll(context.Background(), "traces", func(_ context.Context, key string, body io.Reader) error {
 		t.Helper()
-		require.Equal(t, "this is the body of the object", string(data))
+		require.Equal(t, "this is the body of the object", readBody(t, body))
 		dataCallbackKeys = append(dataCallbackKeys, key)
 		return nil
 	})
@@ -356,6 +452,156 @@ func Test_readAll(t *testing.T) {
 	require.Contains(t, dataCallbackKeys, "year=2021/month=02/day=01/hour=17/minute=33/traces_1")
 }
 
+func Test_readAll_NewestFirst(t *testing.T) {
+	var prefixes []string
+	reader := s3Reader{
+		listObjectsClient: mockListObjectsAPI(func(params *s3.ListObjectsV2Input) ListObjectsV2Pager {
+			prefixes = append(prefixes, *params.Prefix)
+			return &mockListObjectsV2Pager{}
+		}),
+		s3Bucket:    "bucket",
+		s3Partition: "minute",
+		startTime:   testTime,
+		endTime:     testTime.Add(150 * time.Second),
+		newestFirst: true,
+	}
+
+	err := reader.readAll(context.Background(), "traces", func(_ context.Context, _ string, _ io.Reader) error {
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"year=2021/month=02/day=01/hour=17/minute=34/traces_",
+		"year=2021/month=02/day=01/hour=17/minute=33/traces_",
+		"year=2021/month=02/day=01/hour=17/minute=32/traces_",
+	}, prefixes)
+}
+
+func Test_readAll_ResumePosition(t *testing.T) {
+	reader := s3Reader{
+		listObjectsClient: mockListObjectsAPI(func(params *s3.ListObjectsV2Input) ListObjectsV2Pager {
+			key := fmt.Sprintf("%s%s", *params.Prefix, "1")
+			return &mockListObjectsV2Pager{
+				Pages: []*s3.ListObjectsV2Output{{Contents: []types.Object{{Key: &key}}}},
+			}
+		}),
+		getObjectClient: mockGetObjectAPI(func(_ context.Context, _ *s3.GetObjectInput, _ ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
+			return &s3.GetObjectOutput{
+				Body: io.NopCloser(bytes.NewReader([]byte("this is the body of the object"))),
+			}, nil
+		}),
+		s3Bucket:    "bucket",
+		s3Partition: "minute",
+		startTime:   testTime,
+		endTime:     testTime.Add(time.Minute * 3),
+	}
+	require.Equal(t, `starttime: "2021-02-01 17:32"`, reader.resumePosition())
+
+	ctx, cancelFunc := context.WithCancel(context.Background())
+	err := reader.readAll(ctx, "traces", func(_ context.Context, key string, _ io.Reader) error {
+		if strings.Contains(key, "minute=33") {
+			cancelFunc()
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, `starttime: "2021-02-01 17:33"`, reader.resumePosition())
+
+	reader.newestFirst = true
+	require.Equal(t, `endtime: "2021-02-01 17:34"`, reader.resumePosition())
+}
+
+func Test_readAll_PartitionConcurrency(t *testing.T) {
+	reader := newCheckpointTestReader()
+	reader.partitionConcurrency = 2
+	var mu sync.Mutex
+	var keys []string
+	require.NoError(t, reader.readAll(context.Background(), "traces", func(_ context.Context, key string, _ io.Reader) error {
+		mu.Lock()
+		defer mu.Unlock()
+		keys = append(keys, key)
+		return nil
+	}))
+	require.ElementsMatch(t, []string{
+		"year=2021/month=02/day=01/hour=17/minute=32/traces_1",
+		"year=2021/month=02/day=01/hour=17/minute=32/traces_2",
+		"year=2021/month=02/day=01/hour=17/minute=33/traces_1",
+		"year=2021/month=02/day=01/hour=17/minute=33/traces_2",
+		"year=2021/month=02/day=01/hour=17/minute=34/traces_1",
+		"year=2021/month=02/day=01/hour=17/minute=34/traces_2",
+	}, keys)
+	require.Equal(t, checkpoint{Position: testTime.Add(2 * time.Minute), Key: "year=2021/month=02/day=01/hour=17/minute=34/traces_2"}, reader.checkpoint())
+
+	// The position stays at the oldest partition that was not read in full.
+	reader = newCheckpointTestReader()
+	reader.partitionConcurrency = 3
+	require.EqualError(t, reader.readAll(context.Background(), "traces", func(_ context.Context, key string, _ io.Reader) error {
+		if strings.HasSuffix(key, "minute=32/traces_2") {
+			return errors.New("failed to consume")
+		}
+		return nil
+	}), "failed to consume")
+	require.Equal(t, checkpoint{Position: testTime, Key: "year=2021/month=02/day=01/hour=17/minute=32/traces_1"}, reader.checkpoint())
+}
+
+func Test_newS3Reader_StartAfter(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.S3Downloader.Region = "us-east-1"
+	cfg.S3Downloader.S3Bucket = "bucket"
+	cfg.S3Downloader.S3Partition = "minute"
+	cfg.S3Downloader.MaxKeys = 10
+	cfg.S3Downloader.StartAfter = "year=2021/month=02/day=01/hour=17/minute=32/traces_1"
+	cfg.StartTime = "2021-02-01 17:32"
+	cfg.EndTime = "2021-02-01 17:35"
+	reader, err := newS3Reader(context.Background(), cfg, objectNaming{})
+	require.NoError(t, err)
+
+	var maxKeys []int32
+	lister := newCheckpointTestReader().listObjectsClient
+	reader.listObjectsClient = mockListObjectsAPI(func(params *s3.ListObjectsV2Input) ListObjectsV2Pager {
+		maxKeys = append(maxKeys, aws.ToInt32(params.MaxKeys))
+		return lister.NewListObjectsV2Paginator(params)
+	})
+	reader.getObjectClient = newCheckpointTestReader().getObjectClient
+	var keys []string
+	require.NoError(t, reader.readAll(context.Background(), "traces", func(_ context.Context, key string, _ io.Reader) error {
+		keys = append(keys, key)
+		return nil
+	}))
+	// Only the objects of the first partition are skipped.
+	require.Equal(t, []string{
+		"year=2021/month=02/day=01/hour=17/minute=32/traces_2",
+		"year=2021/month=02/day=01/hour=17/minute=33/traces_1",
+		"year=2021/month=02/day=01/hour=17/minute=33/traces_2",
+		"year=2021/month=02/day=01/hour=17/minute=34/traces_1",
+		"year=2021/month=02/day=01/hour=17/minute=34/traces_2",
+	}, keys)
+	require.Equal(t, []int32{10, 10, 10}, maxKeys)
+
+	cfg.ReplayOrder = ReplayOrderNewestFirst
+	cfg.S3Downloader.StartAfter = "year=2021/month=02/day=01/hour=17/minute=34/traces_1"
+	reader, err = newS3Reader(context.Background(), cfg, objectNaming{})
+	require.NoError(t, err)
+	require.Equal(t, testTime.Add(2*time.Minute), reader.resumePartition)
+	require.Equal(t, cfg.S3Downloader.StartAfter, reader.resumeKey)
+}
+
+func Test_s3Reader_completePartition(t *testing.T) {
+	reader := &s3Reader{}
+	for i := 0; i < 3; i++ {
+		reader.startPartition(testTime.Add(time.Duration(i) * time.Minute))
+	}
+	reader.recordRead(testTime, "key_1")
+	reader.recordRead(testTime.Add(2*time.Minute), "key_3")
+	reader.completePartition(testTime.Add(time.Minute))
+	require.Equal(t, checkpoint{Position: testTime, Key: "key_1"}, reader.checkpoint())
+	reader.completePartition(testTime)
+	require.Equal(t, checkpoint{Position: testTime.Add(2 * time.Minute), Key: "key_3"}, reader.checkpoint())
+	reader.completePartition(testTime.Add(2 * time.Minute))
+	require.Equal(t, checkpoint{Position: testTime.Add(2 * time.Minute), Key: "key_3"}, reader.checkpoint())
+	require.Empty(t, reader.reading)
+}
+
 func Test_readAll_ContextDone(t *testing.T) {
 	reader := s3Reader{
 		listObjectsClient: mockListObjectsAPI(func(params *s3.ListObjectsV2Input) ListObjectsV2Pager {
@@ -392,7 +638,7 @@ func Test_readAll_ContextDone(t *testing.T) {
 	dataCallbackKeys := make([]string, 0)
 	ctx, cancelFunc := context.WithCancel(context.Background())
 	cancelFunc()
-	err := reader.readAll(ctx, "traces", func(_ context.Context, key string, _ []byte) error {
+	err := reader.readAll(ctx, "traces", func(_ context.Context, key string, _ io.Reader) error {
 		t.Helper()
 		dataCallbackKeys = append(dataCallbackKeys, key)
 		return nil
@@ -400,3 +646,138 @@ func Test_readAll_ContextDone(t *testing.T) {
 	require.NoError(t, err)
 	require.Len(t, dataCallbackKeys, 0)
 }
+
+func Test_readAll_Continuous(t *testing.T) {
+	testKey1 := "year=2021/month=02/day=01/hour=17/minute=32/traces_1"
+	testKey2 := "year=2021/month=02/day=01/hour=17/minute=32/traces_2"
+	testKey3 := "year=2021/month=02/day=01/hour=17/minute=33/traces_1"
+	listings := map[string][][]string{
+		"year=2021/month=02/day=01/hour=17/minute=32/traces_": {{testKey1}, {testKey1, testKey2}},
+		"year=2021/month=02/day=01/hour=17/minute=33/traces_": {{testKey3}},
+	}
+	nowValues := []time.Time{
+		testTime.Add(30 * time.Second),
+		testTime.Add(70 * time.Second),
+		testTime.Add(2 * time.Minute),
+	}
+	reader := s3Reader{
+		listObjectsClient: mockListObjectsAPI(func(params *s3.ListObjectsV2Input) ListObjectsV2Pager {
+			t.Helper()
+			keys := listings[*params.Prefix][0]
+			listings[*params.Prefix] = listings[*params.Prefix][1:]
+			contents := make([]types.Object, 0, len(keys))
+			for i := range keys {
+				contents = append(contents, types.Object{Key: &keys[i]})
+			}
+			return &mockListObjectsV2Pager{
+				Pages: []*s3.ListObjectsV2Output{{Contents: contents}},
+			}
+		}),
+		getObjectClient: mockGetObjectAPI(func(_ context.Context, _ *s3.GetObjectInput, _ ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
+			return &s3.GetObjectOutput{
+				Body: io.NopCloser(bytes.NewReader([]byte("this is the body of the object"))),
+			}, nil
+		}),
+		s3Bucket:     "bucket",
+		s3Partition:  "minute",
+		startTime:    testTime,
+		endTime:      testTime.Add(time.Minute * 2),
+		pollInterval: time.Millisecond,
+		now: func() time.Time {
+			now := nowValues[0]
+			nowValues = nowValues[1:]
+			return now
+		},
+	}
+
+	dataCallbackKeys := make([]string, 0)
+	err := reader.readAll(context.Background(), "traces", func(_ context.Context, key string, _ io.Reader) error {
+		dataCallbackKeys = append(dataCallbackKeys, key)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{testKey1, testKey2, testKey3}, dataCallbackKeys)
+	require.Empty(t, nowValues)
+}
+
+func Test_readAll_ContinuousLookback(t *testing.T) {
+	testKey1 := "year=2021/month=02/day=01/hour=17/minute=32/traces_1"
+	testKey2 := "year=2021/month=02/day=01/hour=17/minute=32/traces_2"
+	lateKey := "year=2021/month=02/day=01/hour=17/minute=32/traces_3"
+	testKey3 := "year=2021/month=02/day=01/hour=17/minute=33/traces_1"
+	listings := map[string][][]string{
+		"year=2021/month=02/day=01/hour=17/minute=32/traces_": {{testKey1}, {testKey1, testKey2}, {testKey1, testKey2, lateKey}},
+		"year=2021/month=02/day=01/hour=17/minute=33/traces_": {{testKey3}},
+	}
+	nowValues := []time.Time{
+		testTime.Add(30 * time.Second),
+		testTime.Add(70 * time.Second),
+		testTime.Add(2 * time.Minute),
+	}
+	reader := s3Reader{
+		listObjectsClient: mockListObjectsAPI(func(params *s3.ListObjectsV2Input) ListObjectsV2Pager {
+			t.Helper()
+			keys := listings[*params.Prefix][0]
+			listings[*params.Prefix] = listings[*params.Prefix][1:]
+			contents := make([]types.Object, 0, len(keys))
+			for i := range keys {
+				contents = append(contents, types.Object{Key: &keys[i]})
+			}
+			return &mockListObjectsV2Pager{
+				Pages: []*s3.ListObjectsV2Output{{Contents: contents}},
+			}
+		}),
+		getObjectClient: mockGetObjectAPI(func(_ context.Context, _ *s3.GetObjectInput, _ ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
+			return &s3.GetObjectOutput{
+				Body: io.NopCloser(bytes.NewReader([]byte("this is the body of the object"))),
+			}, nil
+		}),
+		s3Bucket:     "bucket",
+		s3Partition:  "minute",
+		startTime:    testTime,
+		endTime:      testTime.Add(time.Minute * 2),
+		pollInterval: time.Millisecond,
+		lookback:     30 * time.Second,
+		now: func() time.Time {
+			now := nowValues[0]
+			nowValues = nowValues[1:]
+			return now
+		},
+	}
+
+	dataCallbackKeys := make([]string, 0)
+	err := reader.readAll(context.Background(), "traces", func(_ context.Context, key string, _ io.Reader) error {
+		dataCallbackKeys = append(dataCallbackKeys, key)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{testKey1, testKey2, lateKey, testKey3}, dataCallbackKeys)
+	require.Empty(t, nowValues)
+	for _, remaining := range listings {
+		require.Empty(t, remaining)
+	}
+}
+
+func Test_readAll_ContinuousContextDone(t *testing.T) {
+	ctx, cancelFunc := context.WithCancel(context.Background())
+	reader := s3Reader{
+		listObjectsClient: mockListObjectsAPI(func(_ *s3.ListObjectsV2Input) ListObjectsV2Pager {
+			cancelFunc()
+			return &mockListObjectsV2Pager{}
+		}),
+		s3Bucket:     "bucket",
+		s3Partition:  "minute",
+		startTime:    testTime,
+		pollInterval: time.Hour,
+		now: func() time.Time {
+			return testTime
+		},
+	}
+
+	err := reader.readAll(ctx, "traces", func(_ context.Context, _ string, _ io.Reader) error {
+		t.Helper()
+		t.Fail()
+		return nil
+	})
+	require.NoError(t, err)
+}
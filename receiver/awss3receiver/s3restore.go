@@ -0,0 +1,121 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
+)
+
+const (
+	defaultRestoreDays         = 1
+	defaultRestoreTimeout      = 48 * time.Hour
+	defaultRestorePollInterval = time.Minute
+)
+
+type RestoreObjectAPI interface {
+	RestoreObject(ctx context.Context, params *s3.RestoreObjectInput, optFns ...func(*s3.Options)) (*s3.RestoreObjectOutput, error)
+	HeadObject(ctx context.Context, params *s3.HeadObjectInput, optFns ...func(*s3.Options)) (*s3.HeadObjectOutput, error)
+}
+
+// s3ObjectRestorer restores objects archived in the S3 Glacier Flexible Retrieval
+// and S3 Glacier Deep Archive storage classes so that they can be retrieved.
+type s3ObjectRestorer struct {
+	client       RestoreObjectAPI
+	tier         types.Tier
+	days         int32
+	timeout      time.Duration
+	pollInterval time.Duration
+	// sseCustomerKey is set when the objects are encrypted with a customer-provided
+	// key, required to retrieve their restore status.
+	sseCustomerKey *sseCustomerKey
+}
+
+func newS3ObjectRestorer(client RestoreObjectAPI, cfg S3RestoreConfig) *s3ObjectRestorer {
+	r := &s3ObjectRestorer{
+		client:       client,
+		tier:         types.Tier(cfg.Tier),
+		days:         cfg.Days,
+		timeout:      cfg.Timeout,
+		pollInterval: cfg.PollInterval,
+	}
+	if r.tier == "" {
+		r.tier = types.TierStandard
+	}
+	if r.days == 0 {
+		r.days = defaultRestoreDays
+	}
+	if r.timeout == 0 {
+		r.timeout = defaultRestoreTimeout
+	}
+	if r.pollInterval == 0 {
+		r.pollInterval = defaultRestorePollInterval
+	}
+	return r
+}
+
+// isArchived reports whether objects of the storage class must be restored before
+// they can be retrieved.
+func isArchived(storageClass types.ObjectStorageClass) bool {
+	return storageClass == types.ObjectStorageClassGlacier || storageClass == types.ObjectStorageClassDeepArchive
+}
+
+// requestRestore requests a temporary copy of an archived object to be restored.
+// Requesting the restore of an object already being restored is not an error.
+func (r *s3ObjectRestorer) requestRestore(ctx context.Context, bucket, key string) error {
+	_, err := r.client.RestoreObject(ctx, &s3.RestoreObjectInput{
+		Bucket: &bucket,
+		Key:    &key,
+		RestoreRequest: &types.RestoreRequest{
+			Days: &r.days,
+			GlacierJobParameters: &types.GlacierJobParameters{
+				Tier: r.tier,
+			},
+		},
+	})
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) && apiErr.ErrorCode() == "RestoreAlreadyInProgress" {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("unable to request the restore of %s: %w", key, err)
+	}
+	return nil
+}
+
+// waitForRestore waits until the restore of an object has completed.
+func (r *s3ObjectRestorer) waitForRestore(ctx context.Context, bucket, key string) error {
+	deadline := time.After(r.timeout)
+	for {
+		params := &s3.HeadObjectInput{
+			Bucket: &bucket,
+			Key:    &key,
+		}
+		r.sseCustomerKey.headObject(params)
+		output, err := r.client.HeadObject(ctx, params)
+		if err != nil {
+			return fmt.Errorf("unable to retrieve the restore status of %s: %w", key, err)
+		}
+		// The restore header is either ongoing-request="true" while the object is
+		// being restored or ongoing-request="false", expiry-date="..." once it is.
+		if strings.Contains(aws.ToString(output.Restore), `ongoing-request="false"`) {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-deadline:
+			return fmt.Errorf("timed out waiting for the restore of %s", key)
+		case <-time.After(r.pollInterval):
+		}
+	}
+}
@@ -0,0 +1,114 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
+	"github.com/stretchr/testify/require"
+)
+
+type mockRestoreObjectAPI struct {
+	restoreRequests []string
+	restoreErr      error
+	// pendingChecks is the number of status checks of each object reporting
+	// the restore as ongoing.
+	pendingChecks map[string]int
+}
+
+func (m *mockRestoreObjectAPI) RestoreObject(_ context.Context, params *s3.RestoreObjectInput, _ ...func(*s3.Options)) (*s3.RestoreObjectOutput, error) {
+	m.restoreRequests = append(m.restoreRequests, *params.Key)
+	if m.restoreErr != nil {
+		return nil, m.restoreErr
+	}
+	return &s3.RestoreObjectOutput{}, nil
+}
+
+func (m *mockRestoreObjectAPI) HeadObject(_ context.Context, params *s3.HeadObjectInput, _ ...func(*s3.Options)) (*s3.HeadObjectOutput, error) {
+	if m.pendingChecks[*params.Key] > 0 {
+		m.pendingChecks[*params.Key]--
+		return &s3.HeadObjectOutput{Restore: aws.String(`ongoing-request="true"`)}, nil
+	}
+	return &s3.HeadObjectOutput{Restore: aws.String(`ongoing-request="false", expiry-date="Fri, 21 Dec 2012 00:00:00 GMT"`)}, nil
+}
+
+func Test_newS3ObjectRestorer_Defaults(t *testing.T) {
+	restorer := newS3ObjectRestorer(&mockRestoreObjectAPI{}, S3RestoreConfig{})
+	require.Equal(t, types.TierStandard, restorer.tier)
+	require.Equal(t, int32(defaultRestoreDays), restorer.days)
+	require.Equal(t, defaultRestoreTimeout, restorer.timeout)
+	require.Equal(t, defaultRestorePollInterval, restorer.pollInterval)
+}
+
+func Test_s3ObjectRestorer_requestRestore(t *testing.T) {
+	client := &mockRestoreObjectAPI{}
+	restorer := newS3ObjectRestorer(client, S3RestoreConfig{Tier: "Bulk"})
+	require.NoError(t, restorer.requestRestore(context.Background(), "bucket", "key"))
+
+	client.restoreErr = &smithy.GenericAPIError{Code: "RestoreAlreadyInProgress", Message: "Object restore is already in progress"}
+	require.NoError(t, restorer.requestRestore(context.Background(), "bucket", "key"))
+
+	client.restoreErr = errors.New("test error")
+	require.EqualError(t, restorer.requestRestore(context.Background(), "bucket", "key"), "unable to request the restore of key: test error")
+}
+
+func Test_s3ObjectRestorer_waitForRestore(t *testing.T) {
+	client := &mockRestoreObjectAPI{pendingChecks: map[string]int{"key": 2, "slow": 1000}}
+	restorer := newS3ObjectRestorer(client, S3RestoreConfig{Timeout: 50 * time.Millisecond, PollInterval: time.Millisecond})
+	require.NoError(t, restorer.waitForRestore(context.Background(), "bucket", "key"))
+	require.Equal(t, 0, client.pendingChecks["key"])
+
+	require.EqualError(t, restorer.waitForRestore(context.Background(), "bucket", "slow"), "timed out waiting for the restore of slow")
+}
+
+func Test_readTelemetryForTime_Restore(t *testing.T) {
+	archivedKey := "year=2021/month=02/day=01/hour=17/minute=32/traces_1"
+	deepArchivedKey := "year=2021/month=02/day=01/hour=17/minute=32/traces_2"
+	standardKey := "year=2021/month=02/day=01/hour=17/minute=32/traces_3"
+	client := &mockRestoreObjectAPI{pendingChecks: map[string]int{archivedKey: 1, deepArchivedKey: 3}}
+	reader := s3Reader{
+		listObjectsClient: mockListObjectsAPI(func(_ *s3.ListObjectsV2Input) ListObjectsV2Pager {
+			return &mockListObjectsV2Pager{
+				Pages: []*s3.ListObjectsV2Output{
+					{
+						Contents: []types.Object{
+							{Key: &archivedKey, StorageClass: types.ObjectStorageClassGlacier},
+							{Key: &deepArchivedKey, StorageClass: types.ObjectStorageClassDeepArchive},
+							{Key: &standardKey, StorageClass: types.ObjectStorageClassStandard},
+						},
+					},
+				},
+			}
+		}),
+		getObjectClient: mockGetObjectAPI(func(_ context.Context, params *s3.GetObjectInput, _ ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
+			if client.pendingChecks[*params.Key] > 0 {
+				return nil, &types.InvalidObjectState{}
+			}
+			return &s3.GetObjectOutput{
+				Body: io.NopCloser(bytes.NewReader([]byte("this is the body of the object"))),
+			}, nil
+		}),
+		restorer:    newS3ObjectRestorer(client, S3RestoreConfig{PollInterval: time.Millisecond}),
+		s3Bucket:    "bucket",
+		s3Partition: S3PartitionMinute,
+	}
+
+	var received []string
+	err := reader.readTelemetryForTime(context.Background(), testTime, "traces", func(_ context.Context, key string, _ io.Reader) error {
+		received = append(received, key)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{archivedKey, deepArchivedKey}, client.restoreRequests)
+	require.Equal(t, []string{archivedKey, deepArchivedKey, standardKey}, received)
+}
@@ -0,0 +1,38 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// retryOptions returns the option of the S3 client retrying its requests
+// according to cfg.
+func retryOptions(cfg S3RetryConfig) func(*s3.Options) {
+	return func(o *s3.Options) {
+		o.Retryer = newRetryer(cfg)
+	}
+}
+
+// newRetryer returns the retryer of the requests to S3, the settings of cfg
+// overriding the defaults of the AWS SDK.
+func newRetryer(cfg S3RetryConfig) aws.Retryer {
+	standardOptions := func(o *retry.StandardOptions) {
+		if cfg.MaxAttempts > 0 {
+			o.MaxAttempts = cfg.MaxAttempts
+		}
+		if cfg.MaxBackoff > 0 {
+			o.MaxBackoff = cfg.MaxBackoff
+			o.Backoff = retry.NewExponentialJitterBackoff(cfg.MaxBackoff)
+		}
+	}
+	if cfg.Mode == RetryModeAdaptive {
+		return retry.NewAdaptiveMode(func(o *retry.AdaptiveModeOptions) {
+			o.StandardOptions = append(o.StandardOptions, standardOptions)
+		})
+	}
+	return retry.NewStandard(standardOptions)
+}
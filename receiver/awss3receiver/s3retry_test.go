@@ -0,0 +1,59 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_newRetryer(t *testing.T) {
+	retryer := newRetryer(S3RetryConfig{})
+	require.IsType(t, &retry.Standard{}, retryer)
+	require.Equal(t, retry.DefaultMaxAttempts, retryer.MaxAttempts())
+
+	retryer = newRetryer(S3RetryConfig{MaxAttempts: 10, MaxBackoff: time.Millisecond})
+	require.Equal(t, 10, retryer.MaxAttempts())
+	delay, err := retryer.RetryDelay(8, nil)
+	require.NoError(t, err)
+	require.LessOrEqual(t, delay, time.Millisecond)
+
+	retryer = newRetryer(S3RetryConfig{Mode: RetryModeAdaptive, MaxAttempts: 5})
+	require.IsType(t, &retry.AdaptiveMode{}, retryer)
+	require.Equal(t, 5, retryer.MaxAttempts())
+}
+
+func Test_retryOptions(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		// The first attempts of the request fail.
+		if requests.Add(1) < 5 {
+			w.WriteHeader(http.StatusInternalServerError)
+			_, _ = w.Write([]byte("<Error><Code>InternalError</Code></Error>"))
+			return
+		}
+		_, _ = w.Write([]byte("object"))
+	}))
+	defer server.Close()
+
+	client := s3.New(s3.Options{
+		Region:       "us-east-1",
+		BaseEndpoint: aws.String(server.URL),
+		UsePathStyle: true,
+		Credentials:  aws.AnonymousCredentials{},
+	}, retryOptions(S3RetryConfig{MaxAttempts: 5, MaxBackoff: time.Millisecond}))
+	data, _, err := getObject(context.Background(), client, &s3.GetObjectInput{Bucket: aws.String("bucket"), Key: aws.String("key")})
+	require.NoError(t, err)
+	require.Equal(t, "object", string(data))
+	require.Equal(t, int32(5), requests.Load())
+}
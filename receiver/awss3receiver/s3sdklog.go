@@ -0,0 +1,52 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/smithy-go/logging"
+	"go.uber.org/zap"
+)
+
+// sdkLogModes are the logging modes of the AWS SDK of the events of sdk_log.
+var sdkLogModes = map[string]aws.ClientLogMode{
+	SDKLogSigning:          aws.LogSigning,
+	SDKLogRetries:          aws.LogRetries,
+	SDKLogRequest:          aws.LogRequest,
+	SDKLogRequestWithBody:  aws.LogRequestWithBody,
+	SDKLogResponse:         aws.LogResponse,
+	SDKLogResponseWithBody: aws.LogResponseWithBody,
+}
+
+// sdkLogLoadOptions returns the load options of the SDK configuration logging
+// the given events with logger.
+func sdkLogLoadOptions(events []string, logger *zap.Logger) []func(*config.LoadOptions) error {
+	if len(events) == 0 || logger == nil {
+		return nil
+	}
+	var mode aws.ClientLogMode
+	for _, event := range events {
+		mode |= sdkLogModes[event]
+	}
+	return []func(*config.LoadOptions) error{
+		config.WithClientLogMode(mode),
+		config.WithLogger(zapSDKLogger{logger: logger}),
+	}
+}
+
+// zapSDKLogger logs the messages of the AWS SDK at the debug level of logger,
+// whatever their classification.
+type zapSDKLogger struct {
+	logger *zap.Logger
+}
+
+func (l zapSDKLogger) Logf(classification logging.Classification, format string, v ...any) {
+	if !l.logger.Core().Enabled(zap.DebugLevel) {
+		return
+	}
+	l.logger.Debug(fmt.Sprintf(format, v...), zap.String("classification", string(classification)))
+}
@@ -0,0 +1,62 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func Test_sdkLogLoadOptions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("X-Amz-Request-Id", "4442587FB7D0A2F9")
+		_, _ = w.Write([]byte("object"))
+	}))
+	defer server.Close()
+
+	newConfig := func(events []string, logger *zap.Logger) S3DownloaderConfig {
+		return S3DownloaderConfig{
+			Region:           "us-east-1",
+			S3Bucket:         "bucket",
+			Endpoint:         server.URL,
+			S3ForcePathStyle: true,
+			Credentials:      &S3CredentialsConfig{AccessKeyID: "AKID", SecretAccessKey: "secret"},
+			SDKLog:           events,
+			sdkLogger:        logger,
+		}
+	}
+	core, logs := observer.New(zap.DebugLevel)
+	_, getObjectClient, err := newS3Client(context.Background(), newConfig([]string{SDKLogRequest, SDKLogResponse}, zap.New(core)))
+	require.NoError(t, err)
+	_, _, err = getObject(context.Background(), getObjectClient, &s3.GetObjectInput{Bucket: aws.String("bucket"), Key: aws.String("key")})
+	require.NoError(t, err)
+	require.Equal(t, 2, logs.Len())
+	require.Contains(t, logs.All()[0].Message, "GET /bucket/key")
+	require.Contains(t, logs.All()[1].Message, "4442587FB7D0A2F9")
+	require.NotContains(t, logs.All()[1].Message, "object")
+	require.Equal(t, "DEBUG", logs.All()[1].ContextMap()["classification"])
+
+	// Nothing is logged unless the events are listed, nor above the debug level.
+	infoCore, infoLogs := observer.New(zap.InfoLevel)
+	for _, cfg := range []S3DownloaderConfig{
+		newConfig(nil, zap.New(core)),
+		newConfig([]string{SDKLogResponse}, zap.New(infoCore)),
+	} {
+		logs.TakeAll()
+		_, getObjectClient, err = newS3Client(context.Background(), cfg)
+		require.NoError(t, err)
+		_, _, err = getObject(context.Background(), getObjectClient, &s3.GetObjectInput{Bucket: aws.String("bucket"), Key: aws.String("key")})
+		require.NoError(t, err)
+		require.Zero(t, logs.Len())
+		require.Zero(t, infoLogs.Len())
+	}
+}
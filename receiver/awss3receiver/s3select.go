@@ -0,0 +1,180 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// The input formats of the select section.
+const (
+	SelectInputJSONLines = "json_lines"
+	SelectInputJSON      = "json"
+	SelectInputCSV       = "csv"
+)
+
+type SelectObjectContentAPI interface {
+	SelectObjectContent(ctx context.Context, params *s3.SelectObjectContentInput, optFns ...func(*s3.Options)) (*s3.SelectObjectContentOutput, error)
+}
+
+// selectRecordsAPI selects the records of an object, returning the stream of the
+// events of the response.
+type selectRecordsAPI interface {
+	selectRecords(ctx context.Context, params *s3.SelectObjectContentInput) (s3.SelectObjectContentEventStreamReader, error)
+}
+
+type s3SelectRecordsAPIImpl struct {
+	client SelectObjectContentAPI
+}
+
+func (api s3SelectRecordsAPIImpl) selectRecords(ctx context.Context, params *s3.SelectObjectContentInput) (s3.SelectObjectContentEventStreamReader, error) {
+	output, err := api.client.SelectObjectContent(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	return output.GetStream(), nil
+}
+
+// objectSelector retrieves the records of the JSON and CSV objects matching an
+// S3 Select expression rather than the whole objects, S3 filtering the records
+// before they are transferred.
+type objectSelector struct {
+	client      selectRecordsAPI
+	expression  string
+	inputFormat string
+}
+
+func newObjectSelector(client GetObjectAPI, cfg *S3SelectConfig) (*objectSelector, error) {
+	if cfg == nil {
+		return nil, nil
+	}
+	selectClient, ok := client.(SelectObjectContentAPI)
+	if !ok {
+		return nil, errors.New("S3 Select is not supported by the S3 client")
+	}
+	s := &objectSelector{client: s3SelectRecordsAPIImpl{client: selectClient}, expression: cfg.Expression, inputFormat: cfg.InputFormat}
+	if s.inputFormat == "" {
+		s.inputFormat = SelectInputJSONLines
+	}
+	return s, nil
+}
+
+// selectExtensions are the extensions of the objects of each input format.
+var selectExtensions = map[string][]string{
+	SelectInputJSONLines: {".jsonl", ".ndjson", ".json"},
+	SelectInputJSON:      {".json"},
+	SelectInputCSV:       {".csv"},
+}
+
+// selects reports whether the records of the retrieved object are selected: the
+// objects of the input format, uncompressed or compressed with gzip or bzip2, are
+// selected unless a version of the object is retrieved, which S3 Select does not
+// support. Without an objectSelector, no object is selected.
+func (s *objectSelector) selects(params *s3.GetObjectInput) bool {
+	if s == nil || params.VersionId != nil {
+		return false
+	}
+	_, ok := s.compression(aws.ToString(params.Key))
+	return ok
+}
+
+// compression returns the compression of the object stored under key as told by
+// its extension, and whether the object is of the input format.
+func (s *objectSelector) compression(key string) (types.CompressionType, bool) {
+	compression := types.CompressionTypeNone
+	switch {
+	case strings.HasSuffix(key, ".gz"):
+		compression, key = types.CompressionTypeGzip, strings.TrimSuffix(key, ".gz")
+	case strings.HasSuffix(key, ".bz2"):
+		compression, key = types.CompressionTypeBzip2, strings.TrimSuffix(key, ".bz2")
+	}
+	for _, extension := range selectExtensions[s.inputFormat] {
+		if strings.HasSuffix(key, extension) {
+			return compression, true
+		}
+	}
+	return "", false
+}
+
+// openObject retrieves the records of a selected object matching the expression,
+// along with the description of the object. The records are uncompressed, as JSON
+// lines or as CSV rows without a header, and the entity tag and the time of the
+// last modification of the object are unknown.
+func (s *objectSelector) openObject(ctx context.Context, params *s3.GetObjectInput) (io.ReadCloser, objectInfo, error) {
+	compression, _ := s.compression(aws.ToString(params.Key))
+	info := objectInfo{bucket: aws.ToString(params.Bucket), key: aws.ToString(params.Key), selected: true}
+	input := &types.InputSerialization{CompressionType: compression}
+	output := &types.OutputSerialization{}
+	switch s.inputFormat {
+	case SelectInputCSV:
+		input.CSV = &types.CSVInput{FileHeaderInfo: types.FileHeaderInfoUse}
+		output.CSV = &types.CSVOutput{RecordDelimiter: aws.String("\n")}
+	case SelectInputJSON:
+		input.JSON = &types.JSONInput{Type: types.JSONTypeDocument}
+		output.JSON = &types.JSONOutput{RecordDelimiter: aws.String("\n")}
+	default:
+		input.JSON = &types.JSONInput{Type: types.JSONTypeLines}
+		output.JSON = &types.JSONOutput{RecordDelimiter: aws.String("\n")}
+	}
+	stream, err := s.client.selectRecords(ctx, &s3.SelectObjectContentInput{
+		Bucket:               params.Bucket,
+		Key:                  params.Key,
+		Expression:           aws.String(s.expression),
+		ExpressionType:       types.ExpressionTypeSql,
+		InputSerialization:   input,
+		OutputSerialization:  output,
+		SSECustomerAlgorithm: params.SSECustomerAlgorithm,
+		SSECustomerKey:       params.SSECustomerKey,
+		SSECustomerKeyMD5:    params.SSECustomerKeyMD5,
+	})
+	if err != nil {
+		return nil, info, err
+	}
+	return &selectedRecords{stream: stream}, info, nil
+}
+
+// selectedRecords reads the records sent in the events of an S3 Select response.
+type selectedRecords struct {
+	stream  s3.SelectObjectContentEventStreamReader
+	payload []byte
+	ended   bool
+}
+
+var errSelectIncomplete = errors.New("the S3 Select response ended before all the records were sent")
+
+func (r *selectedRecords) Read(p []byte) (int, error) {
+	for len(r.payload) == 0 {
+		if r.ended {
+			return 0, io.EOF
+		}
+		event, ok := <-r.stream.Events()
+		if !ok {
+			if err := r.stream.Err(); err != nil {
+				return 0, err
+			}
+			// The end event tells that all the records were sent.
+			return 0, errSelectIncomplete
+		}
+		switch e := event.(type) {
+		case *types.SelectObjectContentEventStreamMemberRecords:
+			r.payload = e.Value.Payload
+		case *types.SelectObjectContentEventStreamMemberEnd:
+			r.ended = true
+		}
+	}
+	n := copy(p, r.payload)
+	r.payload = r.payload[n:]
+	return n, nil
+}
+
+func (r *selectedRecords) Close() error {
+	return r.stream.Close()
+}
@@ -0,0 +1,163 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.uber.org/zap"
+)
+
+// mockSelectEvents is an S3 Select response made of the given events.
+type mockSelectEvents struct {
+	events chan types.SelectObjectContentEventStream
+	err    error
+}
+
+func newMockSelectEvents(err error, events ...types.SelectObjectContentEventStream) *mockSelectEvents {
+	m := &mockSelectEvents{events: make(chan types.SelectObjectContentEventStream, len(events)), err: err}
+	for _, event := range events {
+		m.events <- event
+	}
+	close(m.events)
+	return m
+}
+
+func (m *mockSelectEvents) Events() <-chan types.SelectObjectContentEventStream {
+	return m.events
+}
+
+func (m *mockSelectEvents) Close() error {
+	return nil
+}
+
+func (m *mockSelectEvents) Err() error {
+	return m.err
+}
+
+type mockSelectRecordsAPI func(ctx context.Context, params *s3.SelectObjectContentInput) (s3.SelectObjectContentEventStreamReader, error)
+
+func (m mockSelectRecordsAPI) selectRecords(ctx context.Context, params *s3.SelectObjectContentInput) (s3.SelectObjectContentEventStreamReader, error) {
+	return m(ctx, params)
+}
+
+type mockSelectObjectContentAPI struct {
+	mockGetObjectAPI
+}
+
+func (mockSelectObjectContentAPI) SelectObjectContent(context.Context, *s3.SelectObjectContentInput, ...func(*s3.Options)) (*s3.SelectObjectContentOutput, error) {
+	return nil, errors.New("not implemented")
+}
+
+func recordsEvent(payload string) types.SelectObjectContentEventStream {
+	return &types.SelectObjectContentEventStreamMemberRecords{Value: types.RecordsEvent{Payload: []byte(payload)}}
+}
+
+func Test_newObjectSelector(t *testing.T) {
+	selector, err := newObjectSelector(mockGetObjectAPI(nil), nil)
+	require.NoError(t, err)
+	require.Nil(t, selector)
+	_, err = newObjectSelector(mockGetObjectAPI(nil), &S3SelectConfig{Expression: "SELECT * FROM S3Object"})
+	require.EqualError(t, err, "S3 Select is not supported by the S3 client")
+	selector, err = newObjectSelector(mockSelectObjectContentAPI{}, &S3SelectConfig{Expression: "SELECT * FROM S3Object"})
+	require.NoError(t, err)
+	require.Equal(t, SelectInputJSONLines, selector.inputFormat)
+}
+
+func Test_objectSelector_selects(t *testing.T) {
+	var nilSelector *objectSelector
+	require.False(t, nilSelector.selects(&s3.GetObjectInput{Key: aws.String("logs_1.jsonl")}))
+
+	selector := &objectSelector{inputFormat: SelectInputJSONLines}
+	require.True(t, selector.selects(&s3.GetObjectInput{Key: aws.String("logs_1.jsonl")}))
+	require.True(t, selector.selects(&s3.GetObjectInput{Key: aws.String("logs_1.json.gz")}))
+	require.True(t, selector.selects(&s3.GetObjectInput{Key: aws.String("logs_1.ndjson.bz2")}))
+	require.False(t, selector.selects(&s3.GetObjectInput{Key: aws.String("logs_1.jsonl.zst")}))
+	require.False(t, selector.selects(&s3.GetObjectInput{Key: aws.String("logs_1.csv")}))
+	require.False(t, selector.selects(&s3.GetObjectInput{Key: aws.String("logs_1.jsonl"), VersionId: aws.String("v1")}))
+
+	selector = &objectSelector{inputFormat: SelectInputCSV}
+	require.True(t, selector.selects(&s3.GetObjectInput{Key: aws.String("logs_1.csv.gz")}))
+	require.False(t, selector.selects(&s3.GetObjectInput{Key: aws.String("logs_1.jsonl")}))
+}
+
+func Test_objectSelector_openObject(t *testing.T) {
+	expression := "SELECT * FROM S3Object s WHERE s.level = 'ERROR'"
+	var input *s3.SelectObjectContentInput
+	events := newMockSelectEvents(nil, recordsEvent("{\"level\":\"ERROR\",\"n\":1}\n"), &types.SelectObjectContentEventStreamMemberStats{}, recordsEvent("{\"level\":\"ERROR\",\"n\":3}\n"), &types.SelectObjectContentEventStreamMemberEnd{})
+	selector := &objectSelector{
+		client: mockSelectRecordsAPI(func(_ context.Context, params *s3.SelectObjectContentInput) (s3.SelectObjectContentEventStreamReader, error) {
+			input = params
+			return events, nil
+		}),
+		expression:  expression,
+		inputFormat: SelectInputJSONLines,
+	}
+	body, info, err := selector.openObject(context.Background(), &s3.GetObjectInput{Bucket: aws.String("bucket"), Key: aws.String("app/logs_1.jsonl.gz")})
+	require.NoError(t, err)
+	defer body.Close()
+	require.Equal(t, objectInfo{bucket: "bucket", key: "app/logs_1.jsonl.gz", selected: true}, info)
+	require.Equal(t, "{\"level\":\"ERROR\",\"n\":1}\n{\"level\":\"ERROR\",\"n\":3}\n", readBody(t, body))
+	require.Equal(t, expression, aws.ToString(input.Expression))
+	require.Equal(t, types.ExpressionTypeSql, input.ExpressionType)
+	require.Equal(t, &types.InputSerialization{CompressionType: types.CompressionTypeGzip, JSON: &types.JSONInput{Type: types.JSONTypeLines}}, input.InputSerialization)
+	require.Equal(t, &types.OutputSerialization{JSON: &types.JSONOutput{RecordDelimiter: aws.String("\n")}}, input.OutputSerialization)
+}
+
+func Test_objectSelector_openObject_Incomplete(t *testing.T) {
+	for _, test := range []struct {
+		name   string
+		events *mockSelectEvents
+		err    string
+	}{
+		{
+			name:   "without end event",
+			events: newMockSelectEvents(nil, recordsEvent("a,b\n")),
+			err:    errSelectIncomplete.Error(),
+		},
+		{
+			name:   "stream error",
+			events: newMockSelectEvents(errors.New("connection reset"), recordsEvent("a,b\n")),
+			err:    "connection reset",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			selector := &objectSelector{
+				client: mockSelectRecordsAPI(func(context.Context, *s3.SelectObjectContentInput) (s3.SelectObjectContentEventStreamReader, error) {
+					return test.events, nil
+				}),
+				inputFormat: SelectInputCSV,
+			}
+			body, _, err := selector.openObject(context.Background(), &s3.GetObjectInput{Bucket: aws.String("bucket"), Key: aws.String("logs_1.csv")})
+			require.NoError(t, err)
+			defer body.Close()
+			_, err = io.ReadAll(body)
+			require.EqualError(t, err, test.err)
+		})
+	}
+}
+
+func Test_receiveObject_Selected(t *testing.T) {
+	sink := &consumertest.LogsSink{}
+	cfg := createDefaultConfig().(*Config)
+	cfg.Logs.Format = FormatJSONLines
+	r := &awss3Receiver{
+		dataProcessor: newLogsProcessor(sink, cfg.Logs, zap.NewNop()),
+		format:        FormatJSONLines,
+		logger:        zap.NewNop(),
+	}
+	// The records selected from a compressed object are not compressed.
+	ctx := contextWithObjectInfo(context.Background(), objectInfo{bucket: "bucket", key: "app/logs_1.jsonl.gz", selected: true})
+	require.NoError(t, r.receiveObject(ctx, "app/logs_1.jsonl.gz", strings.NewReader("{\"level\":\"ERROR\"}\n")))
+	require.Equal(t, 1, sink.LogRecordCount())
+}
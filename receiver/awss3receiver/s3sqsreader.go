@@ -0,0 +1,292 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"go.uber.org/zap"
+)
+
+const sqsReceiveErrorRetryInterval = 5 * time.Second
+
+// s3SQSNotificationReader retrieves objects announced by S3 event notifications,
+// or EventBridge events, delivered to an SQS queue.
+type s3SQSNotificationReader struct {
+	logger              *zap.Logger
+	sqsClient           SQSAPI
+	getObjectClient     GetObjectAPI
+	rangedGetter        *rangedGetter
+	selector            *objectSelector
+	sseCustomerKey      *sseCustomerKey
+	queueURL            string
+	maxNumberOfMessages int32
+	waitTimeSeconds     int32
+	visibilityTimeout   int32
+	s3Bucket            string
+	s3Prefix            string
+	filePrefix          string
+	naming              objectNaming
+	retryInterval       time.Duration
+	// pauseWait, if set, waits for the read to be resumed while paused.
+	pauseWait func(ctx context.Context) error
+}
+
+// s3EventNotification is the payload of an S3 event notification.
+// See https://docs.aws.amazon.com/AmazonS3/latest/userguide/notification-content-structure.html
+type s3EventNotification struct {
+	Records []s3EventRecord `json:"Records"`
+}
+
+type s3EventRecord struct {
+	EventSource string `json:"eventSource"`
+	EventName   string `json:"eventName"`
+	S3          struct {
+		Bucket struct {
+			Name string `json:"name"`
+		} `json:"bucket"`
+		Object struct {
+			Key  string `json:"key"`
+			Size int64  `json:"size"`
+		} `json:"object"`
+	} `json:"s3"`
+}
+
+// eventBridgeS3Event is an Amazon EventBridge event emitted for an S3 bucket.
+// See https://docs.aws.amazon.com/AmazonS3/latest/userguide/ev-events.html
+type eventBridgeS3Event struct {
+	DetailType string `json:"detail-type"`
+	Source     string `json:"source"`
+	Detail     struct {
+		Bucket struct {
+			Name string `json:"name"`
+		} `json:"bucket"`
+		Object struct {
+			Key  string `json:"key"`
+			Size int64  `json:"size"`
+		} `json:"object"`
+	} `json:"detail"`
+}
+
+// s3ObjectRef identifies an object referenced by a notification.
+type s3ObjectRef struct {
+	bucket string
+	key    string
+}
+
+func newS3SQSNotificationReader(ctx context.Context, logger *zap.Logger, cfg *Config, naming objectNaming) (*s3SQSNotificationReader, error) {
+	sqsClient, err := newSQSClient(ctx, cfg.S3Downloader, *cfg.SQS)
+	if err != nil {
+		return nil, err
+	}
+	_, getObjectClient, err := newS3Client(ctx, cfg.S3Downloader)
+	if err != nil {
+		return nil, err
+	}
+	selector, err := newObjectSelector(getObjectClient, cfg.S3Downloader.Select)
+	if err != nil {
+		return nil, err
+	}
+	decrypter, err := newObjectDecrypter(ctx, cfg.S3Downloader)
+	if err != nil {
+		return nil, err
+	}
+	getObjectClient = decrypter.wrap(getObjectClient)
+	getObjectClient = newCallTimeouts(cfg.S3Downloader.Timeouts).wrapGetObject(getObjectClient)
+	maxNumberOfMessages := cfg.SQS.MaxNumberOfMessages
+	if maxNumberOfMessages == 0 {
+		maxNumberOfMessages = defaultSQSMaxNumberOfMessages
+	}
+	waitTimeSeconds := cfg.SQS.WaitTimeSeconds
+	if waitTimeSeconds == 0 {
+		waitTimeSeconds = defaultSQSWaitTimeSeconds
+	}
+	return &s3SQSNotificationReader{
+		logger:              logger,
+		sqsClient:           sqsClient,
+		getObjectClient:     getObjectClient,
+		rangedGetter:        newRangedGetter(cfg.S3Downloader.RangedGet),
+		selector:            selector,
+		sseCustomerKey:      newSSECustomerKey(cfg.S3Downloader.SSECustomerKey),
+		queueURL:            cfg.SQS.QueueURL,
+		maxNumberOfMessages: maxNumberOfMessages,
+		waitTimeSeconds:     waitTimeSeconds,
+		visibilityTimeout:   cfg.SQS.VisibilityTimeout,
+		s3Bucket:            cfg.S3Downloader.S3Bucket,
+		s3Prefix:            cfg.S3Downloader.S3Prefix,
+		filePrefix:          cfg.S3Downloader.FilePrefix,
+		naming:              naming,
+		retryInterval:       sqsReceiveErrorRetryInterval,
+	}, nil
+}
+
+// setPauseWait makes readAll wait for the read to be resumed before receiving the
+// next messages, once the ones received have been processed and deleted, so that
+// no message is left hidden until its visibility timeout expires while paused.
+func (r *s3SQSNotificationReader) setPauseWait(wait func(ctx context.Context) error) {
+	r.pauseWait = wait
+}
+
+func (r *s3SQSNotificationReader) readAll(ctx context.Context, telemetryType string, dataCallback s3ReaderDataCallback) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		default:
+		}
+		if r.pauseWait != nil {
+			if err := r.pauseWait(ctx); err != nil {
+				return nil
+			}
+		}
+		output, err := r.sqsClient.ReceiveMessage(ctx, &sqs.ReceiveMessageInput{
+			QueueUrl:            &r.queueURL,
+			MaxNumberOfMessages: r.maxNumberOfMessages,
+			WaitTimeSeconds:     r.waitTimeSeconds,
+			VisibilityTimeout:   r.visibilityTimeout,
+		})
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			r.logger.Error("Failed to receive messages from SQS", zap.String("queue_url", r.queueURL), zap.Error(err))
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-time.After(r.retryInterval):
+			}
+			continue
+		}
+		for _, message := range output.Messages {
+			if err := r.processMessage(ctx, message, telemetryType, dataCallback); err != nil {
+				if errors.Is(err, errStopping) {
+					// No more messages are received, for them not to be hidden until
+					// their visibility timeout expires while none would be processed.
+					return err
+				}
+				// The message is left on the queue and redelivered once its visibility timeout expires.
+				r.logger.Warn("Failed to process S3 event notification", zap.String("message_id", aws.ToString(message.MessageId)), zap.Error(err))
+				continue
+			}
+			if _, err := r.sqsClient.DeleteMessage(ctx, &sqs.DeleteMessageInput{
+				QueueUrl:      &r.queueURL,
+				ReceiptHandle: message.ReceiptHandle,
+			}); err != nil {
+				r.logger.Warn("Failed to delete SQS message", zap.String("message_id", aws.ToString(message.MessageId)), zap.Error(err))
+			}
+		}
+	}
+}
+
+func (r *s3SQSNotificationReader) processMessage(ctx context.Context, message types.Message, telemetryType string, dataCallback s3ReaderDataCallback) error {
+	refs, err := parseS3EventNotification(aws.ToString(message.Body))
+	if err != nil {
+		return err
+	}
+	for _, ref := range refs {
+		if !r.matches(ref, telemetryType) {
+			continue
+		}
+		body, info, err := r.retrieveObject(ctx, ref)
+		if err != nil {
+			return err
+		}
+		err = dataCallback(contextWithObjectInfo(ctx, info), ref.key, body)
+		body.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// matches reports whether the referenced object belongs to the configured
+// bucket and prefix and holds telemetry of the given type. Notifications name the
+// bucket behind an access point, so the bucket is not checked when s3_bucket is
+// an access point ARN.
+func (r *s3SQSNotificationReader) matches(ref s3ObjectRef, telemetryType string) bool {
+	if r.s3Bucket != "" && !arn.IsARN(r.s3Bucket) && ref.bucket != r.s3Bucket {
+		return false
+	}
+	if r.s3Prefix != "" && !strings.HasPrefix(ref.key, r.s3Prefix+"/") {
+		return false
+	}
+	return isTelemetryObject(ref.key, r.filePrefix, r.naming, telemetryType)
+}
+
+// retrieveObject retrieves a referenced object, through the access point if
+// s3_bucket is an access point ARN, returning the body its contents are read
+// from.
+func (r *s3SQSNotificationReader) retrieveObject(ctx context.Context, ref s3ObjectRef) (io.ReadCloser, objectInfo, error) {
+	bucket := ref.bucket
+	if arn.IsARN(r.s3Bucket) {
+		bucket = r.s3Bucket
+	}
+	params := &s3.GetObjectInput{
+		Bucket: &bucket,
+		Key:    &ref.key,
+	}
+	r.sseCustomerKey.getObject(params)
+	if r.selector.selects(params) {
+		return r.selector.openObject(ctx, params)
+	}
+	return r.rangedGetter.openObject(ctx, r.getObjectClient, params)
+}
+
+// parseS3EventNotification extracts the objects created according to an S3
+// event notification or an EventBridge "Object Created" event. Other events,
+// such as the s3:TestEvent sent when a notification is configured, yield no
+// objects.
+func parseS3EventNotification(body string) ([]s3ObjectRef, error) {
+	var message struct {
+		s3EventNotification
+		eventBridgeS3Event
+	}
+	if err := json.Unmarshal([]byte(body), &message); err != nil {
+		return nil, fmt.Errorf("unable to parse S3 event notification: %w", err)
+	}
+	if message.DetailType != "" {
+		if message.Source != "aws.s3" || message.DetailType != "Object Created" {
+			return nil, nil
+		}
+		ref, err := newS3ObjectRef(message.Detail.Bucket.Name, message.Detail.Object.Key)
+		if err != nil {
+			return nil, err
+		}
+		return []s3ObjectRef{ref}, nil
+	}
+	refs := make([]s3ObjectRef, 0, len(message.Records))
+	for _, record := range message.Records {
+		if record.EventSource != "aws:s3" || !strings.HasPrefix(record.EventName, "ObjectCreated:") {
+			continue
+		}
+		ref, err := newS3ObjectRef(record.S3.Bucket.Name, record.S3.Object.Key)
+		if err != nil {
+			return nil, err
+		}
+		refs = append(refs, ref)
+	}
+	return refs, nil
+}
+
+// newS3ObjectRef decodes the object key of an event, which S3 URL encodes.
+func newS3ObjectRef(bucket, encodedKey string) (s3ObjectRef, error) {
+	key, err := url.QueryUnescape(encodedKey)
+	if err != nil {
+		return s3ObjectRef{}, fmt.Errorf("unable to decode object key %q: %w", encodedKey, err)
+	}
+	return s3ObjectRef{bucket: bucket, key: key}, nil
+}
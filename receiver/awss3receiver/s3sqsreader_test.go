@@ -0,0 +1,371 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+const testS3Notification = `{
+  "Records": [
+    {
+      "eventVersion": "2.1",
+      "eventSource": "aws:s3",
+      "awsRegion": "us-east-1",
+      "eventName": "ObjectCreated:Put",
+      "s3": {
+        "bucket": {"name": "bucket"},
+        "object": {"key": "prefix/year%3D2021/month%3D02/day%3D01/hour%3D17/minute%3D32/traces_1.binpb", "size": 30}
+      }
+    },
+    {
+      "eventVersion": "2.1",
+      "eventSource": "aws:s3",
+      "awsRegion": "us-east-1",
+      "eventName": "ObjectCreated:Put",
+      "s3": {
+        "bucket": {"name": "bucket"},
+        "object": {"key": "prefix/year%3D2021/month%3D02/day%3D01/hour%3D17/minute%3D32/logs_1.binpb", "size": 30}
+      }
+    },
+    {
+      "eventVersion": "2.1",
+      "eventSource": "aws:s3",
+      "awsRegion": "us-east-1",
+      "eventName": "ObjectRemoved:Delete",
+      "s3": {
+        "bucket": {"name": "bucket"},
+        "object": {"key": "prefix/year%3D2021/month%3D02/day%3D01/hour%3D17/minute%3D32/traces_2.binpb"}
+      }
+    }
+  ]
+}`
+
+const testS3TestEvent = `{"Service":"Amazon S3","Event":"s3:TestEvent","Time":"2021-02-01T17:32:00.000Z","Bucket":"bucket"}`
+
+const testEventBridgeEvent = `{
+  "version": "0",
+  "id": "17793124-05d4-b198-2fde-7ededc63b103",
+  "detail-type": "Object Created",
+  "source": "aws.s3",
+  "account": "123456789012",
+  "time": "2021-02-01T17:32:10Z",
+  "region": "us-east-1",
+  "resources": ["arn:aws:s3:::bucket"],
+  "detail": {
+    "version": "0",
+    "bucket": {"name": "bucket"},
+    "object": {"key": "prefix/year=2021/month=02/day=01/hour=17/minute=32/traces_3.binpb", "size": 30, "etag": "b1946ac92492d2347c6235b4d2611184"},
+    "request-id": "N4N7GDK58NMKJ12R",
+    "requester": "123456789012",
+    "reason": "PutObject"
+  }
+}`
+
+const testEventBridgeDeleteEvent = `{
+  "version": "0",
+  "detail-type": "Object Deleted",
+  "source": "aws.s3",
+  "detail": {
+    "bucket": {"name": "bucket"},
+    "object": {"key": "prefix/year=2021/month=02/day=01/hour=17/minute=32/traces_3.binpb"}
+  }
+}`
+
+type mockSQSAPI struct {
+	messages [][]types.Message
+	deleted  []string
+	err      error
+	cancel   context.CancelFunc
+}
+
+func (m *mockSQSAPI) ReceiveMessage(_ context.Context, _ *sqs.ReceiveMessageInput, _ ...func(*sqs.Options)) (*sqs.ReceiveMessageOutput, error) {
+	if m.err != nil {
+		err := m.err
+		m.err = nil
+		return nil, err
+	}
+	if len(m.messages) == 0 {
+		m.cancel()
+		return &sqs.ReceiveMessageOutput{}, nil
+	}
+	messages := m.messages[0]
+	m.messages = m.messages[1:]
+	return &sqs.ReceiveMessageOutput{Messages: messages}, nil
+}
+
+func (m *mockSQSAPI) DeleteMessage(_ context.Context, params *sqs.DeleteMessageInput, _ ...func(*sqs.Options)) (*sqs.DeleteMessageOutput, error) {
+	m.deleted = append(m.deleted, *params.ReceiptHandle)
+	return &sqs.DeleteMessageOutput{}, nil
+}
+
+func newTestSQSNotificationReader(sqsClient SQSAPI, getObjectClient GetObjectAPI) *s3SQSNotificationReader {
+	return &s3SQSNotificationReader{
+		logger:              zap.NewNop(),
+		sqsClient:           sqsClient,
+		getObjectClient:     getObjectClient,
+		queueURL:            "https://sqs.us-east-1.amazonaws.com/123456789012/queue",
+		maxNumberOfMessages: defaultSQSMaxNumberOfMessages,
+		waitTimeSeconds:     defaultSQSWaitTimeSeconds,
+		s3Bucket:            "bucket",
+		s3Prefix:            "prefix",
+		retryInterval:       time.Millisecond,
+	}
+}
+
+func Test_parseS3EventNotification(t *testing.T) {
+	refs, err := parseS3EventNotification(testS3Notification)
+	require.NoError(t, err)
+	require.Equal(t, []s3ObjectRef{
+		{bucket: "bucket", key: "prefix/year=2021/month=02/day=01/hour=17/minute=32/traces_1.binpb"},
+		{bucket: "bucket", key: "prefix/year=2021/month=02/day=01/hour=17/minute=32/logs_1.binpb"},
+	}, refs)
+
+	refs, err = parseS3EventNotification(testS3TestEvent)
+	require.NoError(t, err)
+	require.Empty(t, refs)
+
+	_, err = parseS3EventNotification("not json")
+	require.Error(t, err)
+}
+
+func Test_parseS3EventNotification_EventBridge(t *testing.T) {
+	refs, err := parseS3EventNotification(testEventBridgeEvent)
+	require.NoError(t, err)
+	require.Equal(t, []s3ObjectRef{
+		{bucket: "bucket", key: "prefix/year=2021/month=02/day=01/hour=17/minute=32/traces_3.binpb"},
+	}, refs)
+
+	refs, err = parseS3EventNotification(testEventBridgeDeleteEvent)
+	require.NoError(t, err)
+	require.Empty(t, refs)
+}
+
+func Test_s3SQSNotificationReader_readAll(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sqsClient := &mockSQSAPI{
+		messages: [][]types.Message{
+			{
+				{MessageId: aws.String("1"), ReceiptHandle: aws.String("handle1"), Body: aws.String(testS3Notification)},
+				{MessageId: aws.String("2"), ReceiptHandle: aws.String("handle2"), Body: aws.String(testS3TestEvent)},
+			},
+			{
+				{MessageId: aws.String("3"), ReceiptHandle: aws.String("handle3"), Body: aws.String(testEventBridgeEvent)},
+			},
+		},
+		cancel: cancel,
+	}
+	reader := newTestSQSNotificationReader(sqsClient, mockGetObjectAPI(func(_ context.Context, params *s3.GetObjectInput, _ ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
+		t.Helper()
+		require.Equal(t, "bucket", *params.Bucket)
+		return &s3.GetObjectOutput{
+			Body: io.NopCloser(bytes.NewReader([]byte("this is the body of the object"))),
+		}, nil
+	}))
+
+	dataCallbackKeys := make([]string, 0)
+	err := reader.readAll(ctx, "traces", func(_ context.Context, key string, body io.Reader) error {
+		t.Helper()
+		require.Equal(t, "this is the body of the object", readBody(t, body))
+		dataCallbackKeys = append(dataCallbackKeys, key)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"prefix/year=2021/month=02/day=01/hour=17/minute=32/traces_1.binpb",
+		"prefix/year=2021/month=02/day=01/hour=17/minute=32/traces_3.binpb",
+	}, dataCallbackKeys)
+	require.Equal(t, []string{"handle1", "handle2", "handle3"}, sqsClient.deleted)
+}
+
+func Test_s3SQSNotificationReader_readAll_CallbackError(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sqsClient := &mockSQSAPI{
+		messages: [][]types.Message{
+			{
+				{MessageId: aws.String("1"), ReceiptHandle: aws.String("handle1"), Body: aws.String(testS3Notification)},
+			},
+		},
+		cancel: cancel,
+	}
+	reader := newTestSQSNotificationReader(sqsClient, mockGetObjectAPI(func(_ context.Context, _ *s3.GetObjectInput, _ ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
+		return &s3.GetObjectOutput{
+			Body: io.NopCloser(bytes.NewReader([]byte("this is the body of the object"))),
+		}, nil
+	}))
+
+	err := reader.readAll(ctx, "traces", func(_ context.Context, _ string, _ io.Reader) error {
+		return errors.New("consumer refused data")
+	})
+	require.NoError(t, err)
+	require.Empty(t, sqsClient.deleted)
+}
+
+func Test_s3SQSNotificationReader_readAll_Stopping(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sqsClient := &mockSQSAPI{
+		messages: [][]types.Message{
+			{
+				{MessageId: aws.String("1"), ReceiptHandle: aws.String("handle1"), Body: aws.String(testS3Notification)},
+			},
+			{
+				{MessageId: aws.String("2"), ReceiptHandle: aws.String("handle2"), Body: aws.String(testS3Notification)},
+			},
+		},
+		cancel: cancel,
+	}
+	reader := newTestSQSNotificationReader(sqsClient, mockGetObjectAPI(func(_ context.Context, _ *s3.GetObjectInput, _ ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
+		return &s3.GetObjectOutput{
+			Body: io.NopCloser(bytes.NewReader([]byte("this is the body of the object"))),
+		}, nil
+	}))
+
+	err := reader.readAll(ctx, "traces", func(_ context.Context, _ string, _ io.Reader) error {
+		return errStopping
+	})
+	require.ErrorIs(t, err, errStopping)
+	require.Empty(t, sqsClient.deleted)
+	// The messages are no longer received once the receiver is shutting down.
+	require.Len(t, sqsClient.messages, 1)
+}
+
+func Test_s3SQSNotificationReader_readAll_Paused(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sqsClient := &mockSQSAPI{
+		messages: [][]types.Message{
+			{
+				{MessageId: aws.String("1"), ReceiptHandle: aws.String("handle1"), Body: aws.String(testS3Notification)},
+			},
+			{
+				{MessageId: aws.String("2"), ReceiptHandle: aws.String("handle2"), Body: aws.String(testS3Notification)},
+			},
+		},
+		cancel: cancel,
+	}
+	reader := newTestSQSNotificationReader(sqsClient, mockGetObjectAPI(func(_ context.Context, _ *s3.GetObjectInput, _ ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
+		return &s3.GetObjectOutput{
+			Body: io.NopCloser(bytes.NewReader([]byte("this is the body of the object"))),
+		}, nil
+	}))
+	waits := 0
+	reader.setPauseWait(func(context.Context) error {
+		waits++
+		if waits == 2 {
+			// The messages received before the pause are deleted, and no more are
+			// received, while paused.
+			require.Equal(t, []string{"handle1"}, sqsClient.deleted)
+			require.Len(t, sqsClient.messages, 1)
+		}
+		return nil
+	})
+
+	require.NoError(t, reader.readAll(ctx, "traces", func(context.Context, string, io.Reader) error {
+		return nil
+	}))
+	require.Equal(t, 3, waits)
+	require.Equal(t, []string{"handle1", "handle2"}, sqsClient.deleted)
+
+	// The read stops when shutting down while paused.
+	sqsClient.messages = [][]types.Message{{{MessageId: aws.String("3"), ReceiptHandle: aws.String("handle3"), Body: aws.String(testS3Notification)}}}
+	reader.setPauseWait(func(context.Context) error {
+		return context.Canceled
+	})
+	require.NoError(t, reader.readAll(context.Background(), "traces", func(context.Context, string, io.Reader) error {
+		return nil
+	}))
+	require.Len(t, sqsClient.messages, 1)
+}
+
+func Test_s3SQSNotificationReader_readAll_GetObjectError(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sqsClient := &mockSQSAPI{
+		messages: [][]types.Message{
+			{
+				{MessageId: aws.String("1"), ReceiptHandle: aws.String("handle1"), Body: aws.String(testS3Notification)},
+			},
+		},
+		cancel: cancel,
+	}
+	reader := newTestSQSNotificationReader(sqsClient, mockGetObjectAPI(func(_ context.Context, _ *s3.GetObjectInput, _ ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
+		return nil, errors.New("test error")
+	}))
+
+	err := reader.readAll(ctx, "traces", func(_ context.Context, _ string, _ io.Reader) error {
+		t.Helper()
+		t.Fail()
+		return nil
+	})
+	require.NoError(t, err)
+	require.Empty(t, sqsClient.deleted)
+}
+
+func Test_s3SQSNotificationReader_readAll_ReceiveError(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sqsClient := &mockSQSAPI{
+		messages: [][]types.Message{
+			{
+				{MessageId: aws.String("1"), ReceiptHandle: aws.String("handle1"), Body: aws.String(testS3TestEvent)},
+			},
+		},
+		err:    errors.New("test receive error"),
+		cancel: cancel,
+	}
+	reader := newTestSQSNotificationReader(sqsClient, nil)
+
+	err := reader.readAll(ctx, "traces", func(_ context.Context, _ string, _ io.Reader) error {
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"handle1"}, sqsClient.deleted)
+}
+
+func Test_s3SQSNotificationReader_matches(t *testing.T) {
+	reader := s3SQSNotificationReader{
+		s3Bucket:   "bucket",
+		s3Prefix:   "prefix",
+		filePrefix: "file",
+	}
+	require.True(t, reader.matches(s3ObjectRef{bucket: "bucket", key: "prefix/year=2021/filetraces_1.json"}, "traces"))
+	require.False(t, reader.matches(s3ObjectRef{bucket: "other", key: "prefix/year=2021/filetraces_1.json"}, "traces"))
+	require.False(t, reader.matches(s3ObjectRef{bucket: "bucket", key: "other/year=2021/filetraces_1.json"}, "traces"))
+	require.False(t, reader.matches(s3ObjectRef{bucket: "bucket", key: "prefix/year=2021/filelogs_1.json"}, "traces"))
+	require.False(t, reader.matches(s3ObjectRef{bucket: "bucket", key: "prefix/year=2021/traces_1.json"}, "traces"))
+
+	reader.s3Bucket = "arn:aws:s3:us-east-1:123456789012:accesspoint/access-point"
+	require.True(t, reader.matches(s3ObjectRef{bucket: "bucket", key: "prefix/year=2021/filetraces_1.json"}, "traces"))
+}
+
+func Test_s3SQSNotificationReader_retrieveObject_AccessPoint(t *testing.T) {
+	accessPoint := "arn:aws:s3:us-east-1:123456789012:accesspoint/access-point"
+	reader := newTestSQSNotificationReader(nil, mockGetObjectAPI(func(_ context.Context, params *s3.GetObjectInput, _ ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
+		t.Helper()
+		require.Equal(t, accessPoint, *params.Bucket)
+		return &s3.GetObjectOutput{
+			Body: io.NopCloser(bytes.NewReader([]byte("this is the body of the object"))),
+		}, nil
+	}))
+	reader.s3Bucket = accessPoint
+	body, _, err := reader.retrieveObject(context.Background(), s3ObjectRef{bucket: "bucket", key: "prefix/traces_1.json"})
+	require.NoError(t, err)
+	defer body.Close()
+	require.Equal(t, "this is the body of the object", readBody(t, body))
+}
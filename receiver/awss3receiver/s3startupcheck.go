@@ -0,0 +1,136 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"go.uber.org/zap"
+)
+
+type AccessCheckAPI interface {
+	HeadBucket(ctx context.Context, params *s3.HeadBucketInput, optFns ...func(*s3.Options)) (*s3.HeadBucketOutput, error)
+	HeadObject(ctx context.Context, params *s3.HeadObjectInput, optFns ...func(*s3.Options)) (*s3.HeadObjectOutput, error)
+}
+
+// accessCheckingReader is implemented by the readers able to check at startup
+// that their buckets can be read.
+type accessCheckingReader interface {
+	checkAccess(ctx context.Context, logger *zap.Logger) error
+}
+
+// s3AccessChecker checks that the bucket exists in the region of the client and
+// that its objects can be listed and read, so that a missing permission, a wrong
+// region or a missing bucket is reported at startup rather than by the first
+// partition read.
+type s3AccessChecker struct {
+	client            AccessCheckAPI
+	listObjectsClient ListObjectsAPI
+	bucket            string
+	prefix            string
+	// region is the region of the requests, if known.
+	region         string
+	sseCustomerKey *sseCustomerKey
+	// expectedBucketOwner is the account the bucket must belong to, if set.
+	expectedBucketOwner string
+}
+
+// newS3AccessChecker returns the checker of the bucket of cfg, or nil unless
+// startup_check is set. The objects are listed with listObjectsClient, which
+// lists the inventory report rather than the bucket with inventory.
+func newS3AccessChecker(cfg S3DownloaderConfig, listObjectsClient ListObjectsAPI, getObjectClient GetObjectAPI) (*s3AccessChecker, error) {
+	if !cfg.StartupCheck {
+		return nil, nil
+	}
+	client, ok := getObjectClient.(AccessCheckAPI)
+	if !ok {
+		return nil, errors.New("checking the access to the bucket is not supported by the S3 client")
+	}
+	checker := &s3AccessChecker{
+		client:              client,
+		listObjectsClient:   listObjectsClient,
+		bucket:              cfg.S3Bucket,
+		sseCustomerKey:      newSSECustomerKey(cfg.SSECustomerKey),
+		expectedBucketOwner: cfg.ExpectedBucketOwner,
+	}
+	if cfg.S3Prefix != "" {
+		checker.prefix = cfg.S3Prefix + "/"
+	}
+	if s3Client, ok := getObjectClient.(*s3.Client); ok {
+		checker.region = s3Client.Options().Region
+	}
+	return checker, nil
+}
+
+// check retrieves the bucket, lists the first object below the prefix and
+// retrieves its metadata, returning an error telling what to fix on failure.
+// An empty prefix is only logged, as the objects may not be written yet.
+func (c *s3AccessChecker) check(ctx context.Context, logger *zap.Logger) error {
+	if c == nil {
+		return nil
+	}
+	// The access points do not all support HeadBucket, and are in the region of
+	// their ARN.
+	if !arn.IsARN(c.bucket) {
+		if _, err := c.client.HeadBucket(ctx, &s3.HeadBucketInput{Bucket: aws.String(c.bucket)}); err != nil {
+			return c.accessError("HeadBucket", "", err)
+		}
+	}
+	page, err := c.listObjectsClient.NewListObjectsV2Paginator(&s3.ListObjectsV2Input{
+		Bucket:  aws.String(c.bucket),
+		Prefix:  aws.String(c.prefix),
+		MaxKeys: aws.Int32(1),
+	}).NextPage(ctx)
+	if err != nil {
+		return c.accessError(fmt.Sprintf("ListObjectsV2 of prefix %q", c.prefix), "", err)
+	}
+	if len(page.Contents) == 0 {
+		logger.Warn("No object found below the prefix of the bucket, the partitions read are empty until objects are written",
+			zap.String("bucket", c.bucket), zap.String("prefix", c.prefix))
+		return nil
+	}
+	params := &s3.HeadObjectInput{Bucket: aws.String(c.bucket), Key: page.Contents[0].Key}
+	c.sseCustomerKey.headObject(params)
+	if _, err := c.client.HeadObject(ctx, params); err != nil {
+		return c.accessError("HeadObject", aws.ToString(params.Key), err)
+	}
+	return nil
+}
+
+// accessError returns the error of the failed request to the bucket, or to the
+// given object of the bucket, telling the likely cause of the failure.
+func (c *s3AccessChecker) accessError(request, key string, err error) error {
+	permission := "s3:ListBucket"
+	if key != "" {
+		request, permission = fmt.Sprintf("%s of object %s", request, key), "s3:GetObject"
+	}
+	var respErr *awshttp.ResponseError
+	if !errors.As(err, &respErr) {
+		return fmt.Errorf("%s of bucket %s failed: %w", request, c.bucket, err)
+	}
+	// The responses to the requests sent to another region than the one of the
+	// bucket tell the region of the bucket.
+	if region := respErr.Response.Header.Get("X-Amz-Bucket-Region"); region != "" && c.region != "" && region != c.region {
+		return fmt.Errorf("bucket %s is in region %s rather than %s, set region to %s", c.bucket, region, c.region, region)
+	}
+	switch {
+	case respErr.HTTPStatusCode() == http.StatusNotFound && key == "":
+		return fmt.Errorf("bucket %s does not exist: %w", c.bucket, err)
+	case respErr.HTTPStatusCode() == http.StatusForbidden && c.expectedBucketOwner != "":
+		return fmt.Errorf("%s of bucket %s was denied, the bucket may not belong to account %s or the credentials need the %s permission: %w",
+			request, c.bucket, c.expectedBucketOwner, permission, err)
+	case respErr.HTTPStatusCode() == http.StatusForbidden:
+		return fmt.Errorf("%s of bucket %s was denied, the credentials need the %s permission: %w", request, c.bucket, permission, err)
+	case respErr.HTTPStatusCode() == http.StatusBadRequest && key != "" && c.sseCustomerKey != nil:
+		return fmt.Errorf("%s of bucket %s failed, the object may be encrypted with another key than sse_customer_key: %w", request, c.bucket, err)
+	}
+	return fmt.Errorf("%s of bucket %s failed: %w", request, c.bucket, err)
+}
@@ -0,0 +1,179 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/receiver/receivertest"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// startupCheckResponses are the responses of the test bucket to the requests of
+// the startup check, by request.
+type startupCheckResponses struct {
+	headBucket  func(w http.ResponseWriter)
+	listObjects func(w http.ResponseWriter)
+	headObject  func(w http.ResponseWriter, r *http.Request)
+}
+
+func newStartupCheckServer(t *testing.T, responses startupCheckResponses) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodHead && r.URL.Path == "/bucket":
+			responses.headBucket(w)
+		case r.Method == http.MethodGet && r.URL.Path == "/bucket" && r.URL.Query().Get("list-type") == "2":
+			require.Equal(t, "otel/", r.URL.Query().Get("prefix"))
+			require.Equal(t, "1", r.URL.Query().Get("max-keys"))
+			responses.listObjects(w)
+		case r.Method == http.MethodHead && r.URL.Path == "/bucket/otel/year=2024/traces_1":
+			responses.headObject(w, r)
+		default:
+			w.WriteHeader(http.StatusNotImplemented)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func writeStatus(status int) func(w http.ResponseWriter) {
+	return func(w http.ResponseWriter) {
+		w.WriteHeader(status)
+	}
+}
+
+func writeListing(keys ...string) func(w http.ResponseWriter) {
+	return func(w http.ResponseWriter) {
+		listing := "<ListBucketResult><Name>bucket</Name><Prefix>otel/</Prefix><KeyCount>1</KeyCount>"
+		for _, key := range keys {
+			listing += "<Contents><Key>" + key + "</Key></Contents>"
+		}
+		_, _ = w.Write([]byte(listing + "</ListBucketResult>"))
+	}
+}
+
+func writeError(status int, code string) func(w http.ResponseWriter) {
+	return func(w http.ResponseWriter) {
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte("<Error><Code>" + code + "</Code></Error>"))
+	}
+}
+
+func startupCheckConfig(endpoint string) S3DownloaderConfig {
+	return S3DownloaderConfig{
+		Region:           "us-east-1",
+		S3Bucket:         "bucket",
+		S3Prefix:         "otel",
+		Endpoint:         endpoint,
+		S3ForcePathStyle: true,
+		Credentials:      &S3CredentialsConfig{AccessKeyID: "AKID", SecretAccessKey: "secret"},
+		Retry:            &S3RetryConfig{MaxAttempts: 1},
+		StartupCheck:     true,
+	}
+}
+
+func Test_s3AccessChecker(t *testing.T) {
+	headObjectOK := func(w http.ResponseWriter, _ *http.Request) {}
+	tests := []struct {
+		name      string
+		responses startupCheckResponses
+		sseC      bool
+		err       string
+	}{
+		{
+			name:      "readable",
+			responses: startupCheckResponses{headBucket: writeStatus(http.StatusOK), listObjects: writeListing("otel/year=2024/traces_1"), headObject: headObjectOK},
+		},
+		{
+			name:      "missing_bucket",
+			responses: startupCheckResponses{headBucket: writeStatus(http.StatusNotFound)},
+			err:       "bucket bucket does not exist: ",
+		},
+		{
+			name: "wrong_region",
+			responses: startupCheckResponses{headBucket: func(w http.ResponseWriter) {
+				w.Header().Set("X-Amz-Bucket-Region", "eu-west-1")
+				w.WriteHeader(http.StatusMovedPermanently)
+			}},
+			err: "bucket bucket is in region eu-west-1 rather than us-east-1, set region to eu-west-1",
+		},
+		{
+			name:      "denied_listing",
+			responses: startupCheckResponses{headBucket: writeStatus(http.StatusOK), listObjects: writeError(http.StatusForbidden, "AccessDenied")},
+			err:       `ListObjectsV2 of prefix "otel/" of bucket bucket was denied, the credentials need the s3:ListBucket permission: `,
+		},
+		{
+			name: "denied_object",
+			responses: startupCheckResponses{headBucket: writeStatus(http.StatusOK), listObjects: writeListing("otel/year=2024/traces_1"), headObject: func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusForbidden)
+			}},
+			err: "HeadObject of object otel/year=2024/traces_1 of bucket bucket was denied, the credentials need the s3:GetObject permission: ",
+		},
+		{
+			name: "customer_key",
+			responses: startupCheckResponses{headBucket: writeStatus(http.StatusOK), listObjects: writeListing("otel/year=2024/traces_1"), headObject: func(w http.ResponseWriter, r *http.Request) {
+				require.Equal(t, testSSECustomerKeyMD5, r.Header.Get("X-Amz-Server-Side-Encryption-Customer-Key-Md5"))
+				w.WriteHeader(http.StatusBadRequest)
+			}},
+			sseC: true,
+			err:  "HeadObject of object otel/year=2024/traces_1 of bucket bucket failed, the object may be encrypted with another key than sse_customer_key: ",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := startupCheckConfig(newStartupCheckServer(t, tt.responses).URL)
+			if tt.sseC {
+				cfg.SSECustomerKey = &S3SSECustomerKeyConfig{Key: testSSECustomerKey}
+			}
+			listObjectsClient, getObjectClient, err := newS3Client(context.Background(), cfg)
+			require.NoError(t, err)
+			checker, err := newS3AccessChecker(cfg, listObjectsClient, getObjectClient)
+			require.NoError(t, err)
+			err = checker.check(context.Background(), zap.NewNop())
+			if tt.err == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorContains(t, err, tt.err)
+		})
+	}
+}
+
+func Test_s3AccessChecker_EmptyPrefix(t *testing.T) {
+	cfg := startupCheckConfig(newStartupCheckServer(t, startupCheckResponses{headBucket: writeStatus(http.StatusOK), listObjects: writeListing()}).URL)
+	listObjectsClient, getObjectClient, err := newS3Client(context.Background(), cfg)
+	require.NoError(t, err)
+	checker, err := newS3AccessChecker(cfg, listObjectsClient, getObjectClient)
+	require.NoError(t, err)
+	core, logs := observer.New(zap.WarnLevel)
+	require.NoError(t, checker.check(context.Background(), zap.New(core)))
+	require.Equal(t, 1, logs.FilterField(zap.String("prefix", "otel/")).Len())
+
+	// Nothing is checked unless startup_check is set.
+	cfg.StartupCheck = false
+	checker, err = newS3AccessChecker(cfg, listObjectsClient, getObjectClient)
+	require.NoError(t, err)
+	require.Nil(t, checker)
+	require.NoError(t, checker.check(context.Background(), zap.NewNop()))
+}
+
+func Test_awss3Receiver_StartupCheck(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.S3Downloader = startupCheckConfig(newStartupCheckServer(t, startupCheckResponses{headBucket: writeStatus(http.StatusNotFound)}).URL)
+	cfg.S3Downloader.S3Partition = S3PartitionHour
+	cfg.StartTime = "2024-01-01"
+	cfg.EndTime = "2024-01-02"
+	require.NoError(t, cfg.Validate())
+	r, err := newAWSS3TraceReceiver(context.Background(), cfg, &consumertest.TracesSink{}, receivertest.NewNopCreateSettings())
+	require.NoError(t, err)
+	require.ErrorContains(t, r.Start(context.Background(), componenttest.NewNopHost()), "startup check failed: bucket bucket does not exist: ")
+	require.NoError(t, r.Shutdown(context.Background()))
+}
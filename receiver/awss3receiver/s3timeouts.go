@@ -0,0 +1,151 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// callTimeouts bounds the time of each call listing or retrieving objects, with
+// a deadline of the context of the call.
+type callTimeouts struct {
+	listObjects time.Duration
+	getObject   time.Duration
+}
+
+func newCallTimeouts(cfg *S3TimeoutsConfig) *callTimeouts {
+	if cfg == nil {
+		return nil
+	}
+	return &callTimeouts{listObjects: cfg.ListObjects, getObject: cfg.GetObject}
+}
+
+// timeoutError returns err, telling which timeout was exceeded if the deadline
+// of the call rather than of ctx was exceeded.
+func timeoutError(ctx context.Context, err error, setting string, timeout time.Duration) error {
+	if err != nil && errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil {
+		return fmt.Errorf("the call did not complete within the %s timeout of %s: %w", setting, timeout, err)
+	}
+	return err
+}
+
+// wrapListObjects returns a client bounding the time of each page of the
+// listings of client, or client itself without a list_objects timeout.
+func (t *callTimeouts) wrapListObjects(client ListObjectsAPI) ListObjectsAPI {
+	if t == nil || t.listObjects <= 0 {
+		return client
+	}
+	return &timeoutListObjectsAPI{client: client, timeout: t.listObjects}
+}
+
+// wrapListObjectVersions returns a client bounding the time of each page of the
+// listings of client, or client itself without a list_objects timeout.
+func (t *callTimeouts) wrapListObjectVersions(client ListObjectVersionsAPI) ListObjectVersionsAPI {
+	if t == nil || t.listObjects <= 0 || client == nil {
+		return client
+	}
+	return &timeoutListObjectVersionsAPI{client: client, timeout: t.listObjects}
+}
+
+// wrapGetObject returns a client bounding the time of each retrieval of an
+// object with client, the reading of its body included, or client itself
+// without a get_object timeout.
+func (t *callTimeouts) wrapGetObject(client GetObjectAPI) GetObjectAPI {
+	if t == nil || t.getObject <= 0 {
+		return client
+	}
+	return &timeoutGetObjectAPI{client: client, timeout: t.getObject}
+}
+
+type timeoutListObjectsAPI struct {
+	client  ListObjectsAPI
+	timeout time.Duration
+}
+
+func (api *timeoutListObjectsAPI) NewListObjectsV2Paginator(params *s3.ListObjectsV2Input) ListObjectsV2Pager {
+	return &timeoutListObjectsV2Pager{pager: api.client.NewListObjectsV2Paginator(params), timeout: api.timeout}
+}
+
+type timeoutListObjectsV2Pager struct {
+	pager   ListObjectsV2Pager
+	timeout time.Duration
+}
+
+func (p *timeoutListObjectsV2Pager) HasMorePages() bool {
+	return p.pager.HasMorePages()
+}
+
+func (p *timeoutListObjectsV2Pager) NextPage(ctx context.Context, optFns ...func(*s3.Options)) (*s3.ListObjectsV2Output, error) {
+	callCtx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+	output, err := p.pager.NextPage(callCtx, optFns...)
+	return output, timeoutError(ctx, err, "list_objects", p.timeout)
+}
+
+type timeoutListObjectVersionsAPI struct {
+	client  ListObjectVersionsAPI
+	timeout time.Duration
+}
+
+func (api *timeoutListObjectVersionsAPI) NewListObjectVersionsPaginator(params *s3.ListObjectVersionsInput) ListObjectVersionsPager {
+	return &timeoutListObjectVersionsPager{pager: api.client.NewListObjectVersionsPaginator(params), timeout: api.timeout}
+}
+
+type timeoutListObjectVersionsPager struct {
+	pager   ListObjectVersionsPager
+	timeout time.Duration
+}
+
+func (p *timeoutListObjectVersionsPager) HasMorePages() bool {
+	return p.pager.HasMorePages()
+}
+
+func (p *timeoutListObjectVersionsPager) NextPage(ctx context.Context, optFns ...func(*s3.Options)) (*s3.ListObjectVersionsOutput, error) {
+	callCtx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+	output, err := p.pager.NextPage(callCtx, optFns...)
+	return output, timeoutError(ctx, err, "list_objects", p.timeout)
+}
+
+type timeoutGetObjectAPI struct {
+	client  GetObjectAPI
+	timeout time.Duration
+}
+
+func (api *timeoutGetObjectAPI) GetObject(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
+	callCtx, cancel := context.WithTimeout(ctx, api.timeout)
+	output, err := api.client.GetObject(callCtx, params, optFns...)
+	if err != nil {
+		cancel()
+		return nil, timeoutError(ctx, err, "get_object", api.timeout)
+	}
+	// The deadline also bounds the reading of the body, until it is closed.
+	output.Body = &timeoutBody{ReadCloser: output.Body, ctx: ctx, cancel: cancel, timeout: api.timeout}
+	return output, nil
+}
+
+// timeoutBody is the body of an object read within the deadline of the call
+// retrieving it.
+type timeoutBody struct {
+	io.ReadCloser
+	ctx     context.Context
+	cancel  context.CancelFunc
+	timeout time.Duration
+}
+
+func (b *timeoutBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	return n, timeoutError(b.ctx, err, "get_object", b.timeout)
+}
+
+func (b *timeoutBody) Close() error {
+	defer b.cancel()
+	return b.ReadCloser.Close()
+}
@@ -0,0 +1,79 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/stretchr/testify/require"
+)
+
+// newHungServer returns a server whose listings never complete and whose
+// objects are sent up to their first byte, until their requests are canceled.
+func newHungServer(t *testing.T) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("list-type") != "2" {
+			w.Header().Set("Content-Length", "2")
+			_, _ = w.Write([]byte("a"))
+			w.(http.Flusher).Flush()
+		}
+		<-r.Context().Done()
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newTimeoutClients(t *testing.T, timeouts *S3TimeoutsConfig) (ListObjectsAPI, GetObjectAPI) {
+	cfg := startupCheckConfig(newHungServer(t).URL)
+	listObjectsClient, getObjectClient, err := newS3Client(context.Background(), cfg)
+	require.NoError(t, err)
+	callTimeouts := newCallTimeouts(timeouts)
+	return callTimeouts.wrapListObjects(listObjectsClient), callTimeouts.wrapGetObject(getObjectClient)
+}
+
+func Test_callTimeouts_ListObjects(t *testing.T) {
+	listObjectsClient, _ := newTimeoutClients(t, &S3TimeoutsConfig{ListObjects: 50 * time.Millisecond})
+	pager := listObjectsClient.NewListObjectsV2Paginator(&s3.ListObjectsV2Input{Bucket: aws.String("bucket")})
+	_, err := pager.NextPage(context.Background())
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.ErrorContains(t, err, "the call did not complete within the list_objects timeout of 50ms: ")
+
+	// The deadline of the caller is not reported as a timeout of the call.
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	pager = listObjectsClient.NewListObjectsV2Paginator(&s3.ListObjectsV2Input{Bucket: aws.String("bucket")})
+	_, err = pager.NextPage(ctx)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.NotContains(t, err.Error(), "list_objects timeout")
+}
+
+func Test_callTimeouts_GetObject(t *testing.T) {
+	_, getObjectClient := newTimeoutClients(t, &S3TimeoutsConfig{GetObject: 100 * time.Millisecond})
+	output, err := getObjectClient.GetObject(context.Background(), &s3.GetObjectInput{Bucket: aws.String("bucket"), Key: aws.String("traces_1")})
+	require.NoError(t, err)
+	// The body stalls after its first byte until the deadline of the call.
+	_, err = io.ReadAll(output.Body)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.ErrorContains(t, err, "the call did not complete within the get_object timeout of 100ms: ")
+	require.NoError(t, output.Body.Close())
+}
+
+func Test_callTimeouts_Unset(t *testing.T) {
+	client := mockGetObjectAPI(func(context.Context, *s3.GetObjectInput, ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
+		return &s3.GetObjectOutput{}, nil
+	})
+	var timeouts *callTimeouts
+	require.IsType(t, client, timeouts.wrapGetObject(client))
+	require.Nil(t, timeouts.wrapListObjectVersions(nil))
+
+	timeouts = newCallTimeouts(&S3TimeoutsConfig{ListObjects: time.Second})
+	require.IsType(t, client, timeouts.wrapGetObject(client))
+}
@@ -0,0 +1,75 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// objectVersion is a version of an object of a versioned bucket.
+type objectVersion struct {
+	key       string
+	versionID string
+}
+
+// listVersionsAsOf returns, for each key under prefix, the version that was the
+// current version of the object at asOf. Objects created after asOf, or deleted
+// at that time, are left out.
+func listVersionsAsOf(ctx context.Context, client ListObjectVersionsAPI, bucket, prefix string, asOf time.Time) ([]objectVersion, error) {
+	type candidate struct {
+		objectVersion
+		lastModified time.Time
+		deleted      bool
+	}
+	latest := make(map[string]candidate)
+	consider := func(c candidate) {
+		if c.lastModified.After(asOf) {
+			return
+		}
+		if current, ok := latest[c.key]; ok && !c.lastModified.After(current.lastModified) {
+			return
+		}
+		latest[c.key] = c
+	}
+
+	p := client.NewListObjectVersionsPaginator(&s3.ListObjectVersionsInput{
+		Bucket: &bucket,
+		Prefix: &prefix,
+	})
+	for p.HasMorePages() {
+		page, err := p.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, version := range page.Versions {
+			consider(candidate{
+				objectVersion: objectVersion{key: aws.ToString(version.Key), versionID: aws.ToString(version.VersionId)},
+				lastModified:  aws.ToTime(version.LastModified),
+			})
+		}
+		for _, marker := range page.DeleteMarkers {
+			consider(candidate{
+				objectVersion: objectVersion{key: aws.ToString(marker.Key), versionID: aws.ToString(marker.VersionId)},
+				lastModified:  aws.ToTime(marker.LastModified),
+				deleted:       true,
+			})
+		}
+	}
+
+	versions := make([]objectVersion, 0, len(latest))
+	for _, c := range latest {
+		if !c.deleted {
+			versions = append(versions, c.objectVersion)
+		}
+	}
+	sort.Slice(versions, func(i, j int) bool {
+		return versions[i].key < versions[j].key
+	})
+	return versions, nil
+}
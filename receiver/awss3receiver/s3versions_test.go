@@ -0,0 +1,150 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/stretchr/testify/require"
+)
+
+type mockListObjectVersionsAPI func(params *s3.ListObjectVersionsInput) ListObjectVersionsPager
+
+func (m mockListObjectVersionsAPI) NewListObjectVersionsPaginator(params *s3.ListObjectVersionsInput) ListObjectVersionsPager {
+	return m(params)
+}
+
+type mockListObjectVersionsPager struct {
+	PageNum int
+	Pages   []*s3.ListObjectVersionsOutput
+	Error   error
+}
+
+func (m *mockListObjectVersionsPager) HasMorePages() bool {
+	return m.PageNum < len(m.Pages)
+}
+
+func (m *mockListObjectVersionsPager) NextPage(_ context.Context, _ ...func(*s3.Options)) (*s3.ListObjectVersionsOutput, error) {
+	if m.Error != nil {
+		return nil, m.Error
+	}
+	output := m.Pages[m.PageNum]
+	m.PageNum++
+	return output, nil
+}
+
+func newTestObjectVersionsPager() *mockListObjectVersionsPager {
+	prefix := "year=2021/month=02/day=01/hour=17/minute=32/"
+	return &mockListObjectVersionsPager{
+		Pages: []*s3.ListObjectVersionsOutput{
+			{
+				Versions: []types.ObjectVersion{
+					{Key: aws.String(prefix + "traces_1"), VersionId: aws.String("v3"), LastModified: aws.Time(testTime.Add(2 * time.Hour))},
+					{Key: aws.String(prefix + "traces_1"), VersionId: aws.String("v2"), LastModified: aws.Time(testTime.Add(time.Hour))},
+				},
+			},
+			{
+				Versions: []types.ObjectVersion{
+					{Key: aws.String(prefix + "traces_1"), VersionId: aws.String("v1"), LastModified: aws.Time(testTime)},
+					{Key: aws.String(prefix + "traces_2"), VersionId: aws.String("v1"), LastModified: aws.Time(testTime)},
+					{Key: aws.String(prefix + "traces_3"), VersionId: aws.String("v1"), LastModified: aws.Time(testTime.Add(3 * time.Hour))},
+				},
+				DeleteMarkers: []types.DeleteMarkerEntry{
+					{Key: aws.String(prefix + "traces_2"), VersionId: aws.String("v2"), LastModified: aws.Time(testTime.Add(time.Minute))},
+				},
+			},
+		},
+	}
+}
+
+func Test_listVersionsAsOf(t *testing.T) {
+	prefix := "year=2021/month=02/day=01/hour=17/minute=32/"
+	client := mockListObjectVersionsAPI(func(params *s3.ListObjectVersionsInput) ListObjectVersionsPager {
+		t.Helper()
+		require.Equal(t, "bucket", *params.Bucket)
+		require.Equal(t, prefix, *params.Prefix)
+		return newTestObjectVersionsPager()
+	})
+
+	versions, err := listVersionsAsOf(context.Background(), client, "bucket", prefix, testTime)
+	require.NoError(t, err)
+	require.Equal(t, []objectVersion{
+		{key: prefix + "traces_1", versionID: "v1"},
+		{key: prefix + "traces_2", versionID: "v1"},
+	}, versions)
+
+	versions, err = listVersionsAsOf(context.Background(), client, "bucket", prefix, testTime.Add(90*time.Minute))
+	require.NoError(t, err)
+	require.Equal(t, []objectVersion{
+		{key: prefix + "traces_1", versionID: "v2"},
+	}, versions)
+
+	versions, err = listVersionsAsOf(context.Background(), client, "bucket", prefix, testTime.Add(4*time.Hour))
+	require.NoError(t, err)
+	require.Equal(t, []objectVersion{
+		{key: prefix + "traces_1", versionID: "v3"},
+		{key: prefix + "traces_3", versionID: "v1"},
+	}, versions)
+}
+
+func Test_listVersionsAsOf_Error(t *testing.T) {
+	client := mockListObjectVersionsAPI(func(_ *s3.ListObjectVersionsInput) ListObjectVersionsPager {
+		return &mockListObjectVersionsPager{
+			Pages: []*s3.ListObjectVersionsOutput{{}},
+			Error: errors.New("test error"),
+		}
+	})
+	_, err := listVersionsAsOf(context.Background(), client, "bucket", "prefix", testTime)
+	require.EqualError(t, err, "test error")
+}
+
+func Test_readTelemetryForTime_Versions(t *testing.T) {
+	prefix := "year=2021/month=02/day=01/hour=17/minute=32/"
+	reader := s3Reader{
+		listObjectVersionsClient: mockListObjectVersionsAPI(func(params *s3.ListObjectVersionsInput) ListObjectVersionsPager {
+			t.Helper()
+			require.Equal(t, prefix+"traces_", *params.Prefix)
+			return newTestObjectVersionsPager()
+		}),
+		getObjectClient: mockGetObjectAPI(func(_ context.Context, params *s3.GetObjectInput, _ ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
+			t.Helper()
+			require.Equal(t, "bucket", *params.Bucket)
+			require.NotNil(t, params.VersionId)
+			return &s3.GetObjectOutput{
+				Body: io.NopCloser(bytes.NewReader([]byte(*params.Key + "@" + *params.VersionId))),
+			}, nil
+		}),
+		s3Bucket:     "bucket",
+		s3Partition:  S3PartitionMinute,
+		versionsAsOf: testTime.Add(90 * time.Minute),
+	}
+
+	var received []string
+	err := reader.readTelemetryForTime(context.Background(), testTime, "traces", func(_ context.Context, _ string, body io.Reader) error {
+		received = append(received, readBody(t, body))
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{prefix + "traces_1@v2"}, received)
+
+	reader.versionsAsOf = time.Time{}
+	reader.now = func() time.Time {
+		return testTime
+	}
+	received = nil
+	err = reader.readTelemetryForTime(context.Background(), testTime, "traces", func(_ context.Context, _ string, body io.Reader) error {
+		received = append(received, readBody(t, body))
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{prefix + "traces_1@v1", prefix + "traces_2@v1"}, received)
+}
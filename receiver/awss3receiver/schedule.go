@@ -0,0 +1,148 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const scheduleTimeLayout = "15:04"
+
+var scheduleDays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// ingestSchedule is the set of time windows during which objects are read.
+type ingestSchedule struct {
+	location *time.Location
+	windows  []scheduleWindow
+	now      func() time.Time
+}
+
+// scheduleWindow is a daily time window, from start to end after midnight. A
+// window whose end is not after its start ends the following day.
+type scheduleWindow struct {
+	start time.Duration
+	end   time.Duration
+	// days are the days of the week on which the window starts, all days if empty.
+	days map[time.Weekday]struct{}
+}
+
+func newIngestSchedule(cfg ScheduleConfig) (*ingestSchedule, error) {
+	location := time.Local
+	if cfg.Timezone != "" {
+		var err error
+		if location, err = time.LoadLocation(cfg.Timezone); err != nil {
+			return nil, fmt.Errorf("invalid schedule timezone %q: %w", cfg.Timezone, err)
+		}
+	}
+	schedule := &ingestSchedule{
+		location: location,
+		windows:  make([]scheduleWindow, 0, len(cfg.Windows)),
+		now:      time.Now,
+	}
+	for i, windowCfg := range cfg.Windows {
+		window, err := newScheduleWindow(windowCfg)
+		if err != nil {
+			return nil, fmt.Errorf("schedule windows[%d]: %w", i, err)
+		}
+		schedule.windows = append(schedule.windows, window)
+	}
+	return schedule, nil
+}
+
+func newScheduleWindow(cfg ScheduleWindowConfig) (scheduleWindow, error) {
+	start, err := parseTimeOfDay(cfg.Start)
+	if err != nil {
+		return scheduleWindow{}, fmt.Errorf("invalid start: %w", err)
+	}
+	end, err := parseTimeOfDay(cfg.End)
+	if err != nil {
+		return scheduleWindow{}, fmt.Errorf("invalid end: %w", err)
+	}
+	if end <= start {
+		end += 24 * time.Hour
+	}
+	window := scheduleWindow{start: start, end: end}
+	if len(cfg.Days) > 0 {
+		window.days = make(map[time.Weekday]struct{}, len(cfg.Days))
+		for _, day := range cfg.Days {
+			weekday, ok := scheduleDays[strings.ToLower(day)]
+			if !ok {
+				return scheduleWindow{}, fmt.Errorf("invalid day %q, accepted days: sun, mon, tue, wed, thu, fri, sat", day)
+			}
+			window.days[weekday] = struct{}{}
+		}
+	}
+	return window, nil
+}
+
+func parseTimeOfDay(value string) (time.Duration, error) {
+	t, err := time.Parse(scheduleTimeLayout, value)
+	if err != nil {
+		return 0, fmt.Errorf("unable to parse %q, accepted format: %s", value, scheduleTimeLayout)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// nextOpening returns the zero time if t is within a window of the schedule,
+// and otherwise the time at which the next window opens.
+func (s *ingestSchedule) nextOpening(t time.Time) time.Time {
+	t = t.In(s.location)
+	year, month, day := t.Date()
+	var next time.Time
+	// Windows started the previous day may still be open.
+	for offset := -1; offset <= 7; offset++ {
+		midnight := time.Date(year, month, day+offset, 0, 0, 0, 0, s.location)
+		for _, window := range s.windows {
+			if _, ok := window.days[midnight.Weekday()]; window.days != nil && !ok {
+				continue
+			}
+			start := atTimeOfDay(midnight, window.start)
+			end := atTimeOfDay(midnight, window.end)
+			if !t.Before(start) && t.Before(end) {
+				return time.Time{}
+			}
+			if start.After(t) && (next.IsZero() || start.Before(next)) {
+				next = start
+			}
+		}
+	}
+	return next
+}
+
+// atTimeOfDay returns the wall clock time d after midnight, which differs from
+// midnight.Add(d) on the days the clocks change.
+func atTimeOfDay(midnight time.Time, d time.Duration) time.Time {
+	year, month, day := midnight.Date()
+	minutes := int(d / time.Minute)
+	return time.Date(year, month, day, minutes/60, minutes%60, 0, 0, midnight.Location())
+}
+
+// wait blocks until the time is within a window of the schedule or ctx is done.
+func (s *ingestSchedule) wait(ctx context.Context, logger *zap.Logger) error {
+	for {
+		next := s.nextOpening(s.now())
+		if next.IsZero() {
+			return nil
+		}
+		logger.Info("Outside of the ingest schedule, pausing the retrieval of objects", zap.Time("resume_at", next))
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(next.Sub(s.now())):
+		}
+	}
+}
@@ -0,0 +1,85 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func Test_ingestSchedule_nextOpening(t *testing.T) {
+	schedule, err := newIngestSchedule(ScheduleConfig{
+		Timezone: "UTC",
+		Windows: []ScheduleWindowConfig{
+			{Start: "01:00", End: "06:00"},
+			{Start: "22:00", End: "02:00", Days: []string{"Sat"}},
+		},
+	})
+	require.NoError(t, err)
+
+	// 2021-02-06 is a Saturday.
+	for _, tt := range []struct {
+		now  time.Time
+		want time.Time
+	}{
+		{now: time.Date(2021, 2, 5, 0, 30, 0, 0, time.UTC), want: time.Date(2021, 2, 5, 1, 0, 0, 0, time.UTC)},
+		{now: time.Date(2021, 2, 5, 1, 0, 0, 0, time.UTC)},
+		{now: time.Date(2021, 2, 5, 5, 59, 0, 0, time.UTC)},
+		{now: time.Date(2021, 2, 5, 6, 0, 0, 0, time.UTC), want: time.Date(2021, 2, 6, 1, 0, 0, 0, time.UTC)},
+		{now: time.Date(2021, 2, 5, 23, 0, 0, 0, time.UTC), want: time.Date(2021, 2, 6, 1, 0, 0, 0, time.UTC)},
+		{now: time.Date(2021, 2, 6, 21, 0, 0, 0, time.UTC), want: time.Date(2021, 2, 6, 22, 0, 0, 0, time.UTC)},
+		{now: time.Date(2021, 2, 6, 23, 0, 0, 0, time.UTC)},
+		{now: time.Date(2021, 2, 7, 0, 30, 0, 0, time.UTC)},
+	} {
+		require.Equal(t, tt.want, schedule.nextOpening(tt.now), tt.now)
+	}
+}
+
+func Test_ingestSchedule_nextOpening_Timezone(t *testing.T) {
+	schedule, err := newIngestSchedule(ScheduleConfig{
+		Windows: []ScheduleWindowConfig{{Start: "01:00", End: "06:00"}},
+	})
+	require.NoError(t, err)
+	require.Equal(t, time.Local, schedule.location)
+
+	schedule.location = time.FixedZone("UTC+2", 2*60*60)
+	require.True(t, schedule.nextOpening(time.Date(2021, 2, 5, 0, 30, 0, 0, time.UTC)).IsZero())
+	require.Equal(t, time.Date(2021, 2, 5, 23, 0, 0, 0, time.UTC),
+		schedule.nextOpening(time.Date(2021, 2, 5, 4, 0, 0, 0, time.UTC)).UTC())
+}
+
+func Test_newIngestSchedule_Errors(t *testing.T) {
+	_, err := newIngestSchedule(ScheduleConfig{Timezone: "Nowhere/City"})
+	require.ErrorContains(t, err, `invalid schedule timezone "Nowhere/City"`)
+
+	_, err = newIngestSchedule(ScheduleConfig{Windows: []ScheduleWindowConfig{{Start: "1am", End: "06:00"}}})
+	require.EqualError(t, err, `schedule windows[0]: invalid start: unable to parse "1am", accepted format: 15:04`)
+
+	_, err = newIngestSchedule(ScheduleConfig{Windows: []ScheduleWindowConfig{{Start: "01:00", End: "06:00", Days: []string{"funday"}}}})
+	require.EqualError(t, err, `schedule windows[0]: invalid day "funday", accepted days: sun, mon, tue, wed, thu, fri, sat`)
+}
+
+func Test_ingestSchedule_wait(t *testing.T) {
+	schedule, err := newIngestSchedule(ScheduleConfig{
+		Timezone: "UTC",
+		Windows:  []ScheduleWindowConfig{{Start: "01:00", End: "06:00"}},
+	})
+	require.NoError(t, err)
+
+	schedule.now = func() time.Time {
+		return time.Date(2021, 2, 5, 2, 0, 0, 0, time.UTC)
+	}
+	require.NoError(t, schedule.wait(context.Background(), zap.NewNop()))
+
+	schedule.now = func() time.Time {
+		return time.Date(2021, 2, 5, 7, 0, 0, 0, time.UTC)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	require.ErrorIs(t, schedule.wait(ctx, zap.NewNop()), context.DeadlineExceeded)
+}
@@ -0,0 +1,34 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+)
+
+type SQSAPI interface {
+	ReceiveMessage(ctx context.Context, params *sqs.ReceiveMessageInput, optFns ...func(*sqs.Options)) (*sqs.ReceiveMessageOutput, error)
+	DeleteMessage(ctx context.Context, params *sqs.DeleteMessageInput, optFns ...func(*sqs.Options)) (*sqs.DeleteMessageOutput, error)
+}
+
+// newSQSClient returns the client of the queue of cfg, with the credentials and
+// transport of the clients of downloaderCfg.
+func newSQSClient(ctx context.Context, downloaderCfg S3DownloaderConfig, cfg SQSConfig) (SQSAPI, error) {
+	if cfg.Region != "" {
+		downloaderCfg.Region = cfg.Region
+	}
+	awsCfg, err := loadAWSConfig(ctx, downloaderCfg)
+	if err != nil {
+		return nil, err
+	}
+	sqsOptionFuncs := make([]func(options *sqs.Options), 0)
+	if cfg.Endpoint != "" {
+		sqsOptionFuncs = append(sqsOptionFuncs, func(o *sqs.Options) {
+			o.BaseEndpoint = &cfg.Endpoint
+		})
+	}
+	return sqs.NewFromConfig(awsCfg, sqsOptionFuncs...), nil
+}
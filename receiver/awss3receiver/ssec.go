@@ -0,0 +1,70 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"crypto/md5" // #nosec G501 -- S3 requires the MD5 digest of the customer-provided keys
+	"encoding/base64"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// defaultSSECustomerAlgorithm is the only algorithm of the objects encrypted
+// with a customer-provided key supported by S3.
+const defaultSSECustomerAlgorithm = "AES256"
+
+// sseCustomerKey is the customer-provided key the objects were encrypted with
+// (SSE-C), sent along with the requests reading the objects.
+type sseCustomerKey struct {
+	algorithm string
+	key       string
+	keyMD5    string
+}
+
+// newSSECustomerKey returns the key of cfg, or nil if the objects are not
+// encrypted with a customer-provided key.
+func newSSECustomerKey(cfg *S3SSECustomerKeyConfig) *sseCustomerKey {
+	if cfg == nil {
+		return nil
+	}
+	k := &sseCustomerKey{algorithm: cfg.Algorithm, key: string(cfg.Key), keyMD5: cfg.KeyMD5}
+	if k.algorithm == "" {
+		k.algorithm = defaultSSECustomerAlgorithm
+	}
+	if k.keyMD5 == "" {
+		k.keyMD5 = sseCustomerKeyMD5(k.key)
+	}
+	return k
+}
+
+// sseCustomerKeyMD5 returns the base64-encoded MD5 digest of the base64-encoded
+// key, or an empty string if the key is not base64-encoded.
+func sseCustomerKeyMD5(key string) string {
+	decoded, err := base64.StdEncoding.DecodeString(key)
+	if err != nil {
+		return ""
+	}
+	digest := md5.Sum(decoded) // #nosec G401
+	return base64.StdEncoding.EncodeToString(digest[:])
+}
+
+// getObject sets the key of the retrieval of an object.
+func (k *sseCustomerKey) getObject(params *s3.GetObjectInput) {
+	if k == nil {
+		return
+	}
+	params.SSECustomerAlgorithm = &k.algorithm
+	params.SSECustomerKey = &k.key
+	params.SSECustomerKeyMD5 = &k.keyMD5
+}
+
+// headObject sets the key of the retrieval of the metadata of an object.
+func (k *sseCustomerKey) headObject(params *s3.HeadObjectInput) {
+	if k == nil {
+		return
+	}
+	params.SSECustomerAlgorithm = &k.algorithm
+	params.SSECustomerKey = &k.key
+	params.SSECustomerKeyMD5 = &k.keyMD5
+}
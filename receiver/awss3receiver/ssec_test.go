@@ -0,0 +1,88 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/stretchr/testify/require"
+)
+
+// testSSECustomerKey is a base64-encoded 256-bit key, and testSSECustomerKeyMD5
+// the base64-encoded MD5 digest of the key.
+const (
+	testSSECustomerKey    = "MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY="
+	testSSECustomerKeyMD5 = "hRasmdxgYDKV3nvbahU1MA=="
+)
+
+func Test_newSSECustomerKey(t *testing.T) {
+	require.Nil(t, newSSECustomerKey(nil))
+
+	key := newSSECustomerKey(&S3SSECustomerKeyConfig{Key: testSSECustomerKey})
+	require.Equal(t, &sseCustomerKey{algorithm: "AES256", key: testSSECustomerKey, keyMD5: testSSECustomerKeyMD5}, key)
+
+	params := &s3.GetObjectInput{}
+	key.getObject(params)
+	require.Equal(t, "AES256", aws.ToString(params.SSECustomerAlgorithm))
+	require.Equal(t, testSSECustomerKey, aws.ToString(params.SSECustomerKey))
+	require.Equal(t, testSSECustomerKeyMD5, aws.ToString(params.SSECustomerKeyMD5))
+
+	// The requests are left unchanged without a key.
+	params = &s3.GetObjectInput{}
+	(*sseCustomerKey)(nil).getObject(params)
+	require.Nil(t, params.SSECustomerKey)
+	headParams := &s3.HeadObjectInput{}
+	(*sseCustomerKey)(nil).headObject(headParams)
+	require.Nil(t, headParams.SSECustomerKey)
+}
+
+func Test_s3Reader_retrieveObject_SSECustomerKey(t *testing.T) {
+	var received *s3.GetObjectInput
+	reader := &s3Reader{
+		getObjectClient: mockGetObjectAPI(func(_ context.Context, params *s3.GetObjectInput, _ ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
+			received = params
+			return &s3.GetObjectOutput{Body: io.NopCloser(bytes.NewReader([]byte("object")))}, nil
+		}),
+		s3Bucket:       "bucket",
+		sseCustomerKey: newSSECustomerKey(&S3SSECustomerKeyConfig{Key: testSSECustomerKey}),
+	}
+	body, _, err := reader.retrieveObject(context.Background(), "traces_1", "")
+	require.NoError(t, err)
+	require.NoError(t, body.Close())
+	require.Equal(t, testSSECustomerKey, aws.ToString(received.SSECustomerKey))
+	require.Equal(t, testSSECustomerKeyMD5, aws.ToString(received.SSECustomerKeyMD5))
+}
+
+func Test_s3ManifestReader_SSECustomerKey(t *testing.T) {
+	keys := map[string]string{}
+	reader := s3ManifestReader{
+		getObjectClient: mockGetObjectAPI(func(_ context.Context, params *s3.GetObjectInput, _ ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
+			keys[*params.Key] = aws.ToString(params.SSECustomerKey)
+			data := "object"
+			if *params.Key == "manifest.json" {
+				data = `[{"key": "year=2021/month=02/day=01/hour=17/minute=33/traces_1"}]`
+			}
+			return &s3.GetObjectOutput{Body: io.NopCloser(bytes.NewReader([]byte(data)))}, nil
+		}),
+		sseCustomerKey: newSSECustomerKey(&S3SSECustomerKeyConfig{Key: testSSECustomerKey}),
+		manifestBucket: "bucket",
+		manifestKey:    "manifest.json",
+		manifestFormat: ManifestFormatJSON,
+		s3Bucket:       "bucket",
+	}
+	require.NoError(t, reader.readAll(context.Background(), "traces", func(_ context.Context, _ string, body io.Reader) error {
+		_, err := io.Copy(io.Discard, body)
+		return err
+	}))
+	// The manifest is not encrypted with the key of the objects it lists.
+	require.Equal(t, map[string]string{
+		"manifest.json": "",
+		"year=2021/month=02/day=01/hour=17/minute=33/traces_1": testSSECustomerKey,
+	}, keys)
+}
@@ -0,0 +1,169 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/extension/experimental/storage"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.uber.org/multierr"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/stanza/adapter" // Registers the operators of the stanza-based log receivers.
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/stanza/entry"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/stanza/operator"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/stanza/operator/helper"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/stanza/pipeline"
+)
+
+var errOperatorsStopped = errors.New("the operators have been stopped")
+
+// entryCollector is the output of the operators, collecting the entries they emit.
+type entryCollector struct {
+	helper.OutputOperator
+	mu      sync.Mutex
+	entries []*entry.Entry
+}
+
+func newEntryCollector(set component.TelemetrySettings) (*entryCollector, error) {
+	output, err := helper.NewOutputConfig("awss3_collector", "awss3_collector").Build(set)
+	if err != nil {
+		return nil, err
+	}
+	return &entryCollector{OutputOperator: output}, nil
+}
+
+func (c *entryCollector) Process(_ context.Context, e *entry.Entry) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = append(c.entries, e)
+	return nil
+}
+
+// take returns the entries collected since the last call.
+func (c *entryCollector) take() []*entry.Entry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entries := c.entries
+	c.entries = nil
+	return entries
+}
+
+// buildOperators builds the pipeline of operators, its entries collected by the
+// returned collector.
+func buildOperators(operators []operator.Config, set component.TelemetrySettings) (*pipeline.DirectedPipeline, *entryCollector, error) {
+	collector, err := newEntryCollector(set)
+	if err != nil {
+		return nil, nil, err
+	}
+	pipe, err := pipeline.Config{Operators: operators, DefaultOutput: collector}.Build(set)
+	if err != nil {
+		return nil, nil, err
+	}
+	return pipe, collector, nil
+}
+
+// logOperators runs the log records through the operators before they are sent
+// on. The operators are built once, and the entries they emit while a batch of
+// records is run through them are sent on with the batch. The entries buffered by
+// operators such as recombine are sent on with a later batch, or at shutdown.
+type logOperators struct {
+	pipe          *pipeline.DirectedPipeline
+	collector     *entryCollector
+	fromConverter *adapter.FromPdataConverter
+	converter     *adapter.Converter
+	// mu serializes the batches, run through the operators one at a time.
+	mu   sync.Mutex
+	send func(ctx context.Context, ld plog.Logs) error
+}
+
+func newLogOperators(operators []operator.Config, set component.TelemetrySettings) (*logOperators, error) {
+	if len(operators) == 0 {
+		return nil, nil
+	}
+	pipe, collector, err := buildOperators(operators, set)
+	if err != nil {
+		return nil, err
+	}
+	return &logOperators{
+		pipe:      pipe,
+		collector: collector,
+		// A single worker keeps the entries in the order of the records.
+		fromConverter: adapter.NewFromPdataConverter(1, set.Logger),
+		converter:     adapter.NewConverter(set.Logger),
+	}, nil
+}
+
+func (o *logOperators) start() error {
+	if o == nil {
+		return nil
+	}
+	o.fromConverter.Start()
+	o.converter.Start()
+	return o.pipe.Start(storage.NewNopClient())
+}
+
+// shutdown stops the operators, which flush the entries they buffer, and sends on
+// the entries emitted since the last batch.
+func (o *logOperators) shutdown(ctx context.Context) error {
+	if o == nil {
+		return nil
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	err := o.pipe.Stop()
+	err = multierr.Append(err, o.flushLocked(ctx))
+	o.fromConverter.Stop()
+	o.converter.Stop()
+	return err
+}
+
+func (o *logOperators) logs(next consumer.Logs) (consumer.Logs, error) {
+	o.send = next.ConsumeLogs
+	return consumer.NewLogs(func(ctx context.Context, ld plog.Logs) error {
+		o.mu.Lock()
+		defer o.mu.Unlock()
+		scopes := 0
+		for i := 0; i < ld.ResourceLogs().Len(); i++ {
+			scopes += ld.ResourceLogs().At(i).ScopeLogs().Len()
+		}
+		// The converter hands out the entries of a scope at a time, once read.
+		go func() {
+			_ = o.fromConverter.Batch(ld)
+		}()
+		first := o.pipe.Operators()[0]
+		for i := 0; i < scopes; i++ {
+			entries, ok := <-o.fromConverter.OutChannel()
+			if !ok {
+				return errOperatorsStopped
+			}
+			for _, e := range entries {
+				// The operators log the entries they fail to process, which are then
+				// sent on or dropped according to their on_error setting.
+				_ = first.Process(ctx, e)
+			}
+		}
+		return o.flushLocked(ctx)
+	}, consumer.WithCapabilities(consumer.Capabilities{MutatesData: true}))
+}
+
+// flushLocked sends on the entries emitted by the operators.
+func (o *logOperators) flushLocked(ctx context.Context) error {
+	entries := o.collector.take()
+	if len(entries) == 0 {
+		return nil
+	}
+	if err := o.converter.Batch(entries); err != nil {
+		return err
+	}
+	ld, ok := <-o.converter.OutChannel()
+	if !ok {
+		return errOperatorsStopped
+	}
+	return o.send(ctx, ld)
+}
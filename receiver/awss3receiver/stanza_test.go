@@ -0,0 +1,167 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/confmap"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/pdatatest/plogtest"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/stanza/operator"
+)
+
+func unmarshalOperators(t *testing.T, operators []any) []operator.Config {
+	var cfg struct {
+		Operators []operator.Config `mapstructure:"operators"`
+	}
+	require.NoError(t, confmap.NewFromStringMap(map[string]any{"operators": operators}).Unmarshal(&cfg))
+	return cfg.Operators
+}
+
+func Test_logOperators(t *testing.T) {
+	operators := unmarshalOperators(t, []any{
+		map[string]any{
+			"type":           "recombine",
+			"combine_field":  "body",
+			"is_first_entry": `body matches "^\\d{4}-"`,
+		},
+		map[string]any{
+			"type":  "regex_parser",
+			"regex": `^(?P<time>\S+) (?P<sev>[A-Z]+) (?s:(?P<msg>.*))$`,
+			"timestamp": map[string]any{
+				"parse_from": "attributes.time",
+				"layout":     "%Y-%m-%dT%H:%M:%SZ",
+			},
+			"severity": map[string]any{
+				"parse_from": "attributes.sev",
+			},
+		},
+		map[string]any{
+			"type": "move",
+			"from": "attributes.msg",
+			"to":   "body",
+		},
+	})
+	ops, err := newLogOperators(operators, componenttest.NewNopTelemetrySettings())
+	require.NoError(t, err)
+	sink := &consumertest.LogsSink{}
+	next, err := ops.logs(sink)
+	require.NoError(t, err)
+	require.NoError(t, ops.start())
+
+	newBatch := func(lines ...string) plog.Logs {
+		ld := plog.NewLogs()
+		rl := ld.ResourceLogs().AppendEmpty()
+		rl.Resource().Attributes().PutStr("aws.s3.bucket", "mybucket")
+		records := rl.ScopeLogs().AppendEmpty().LogRecords()
+		for _, line := range lines {
+			records.AppendEmpty().Body().SetStr(line)
+		}
+		return ld
+	}
+	require.NoError(t, next.ConsumeLogs(context.Background(), newBatch(
+		"2024-01-01T01:00:00Z INFO started",
+		"2024-01-01T01:00:01Z ERROR failed",
+	)))
+	require.NoError(t, next.ConsumeLogs(context.Background(), newBatch(
+		"  at main.go:12",
+		"not matching",
+	)))
+
+	// The first record is flushed by recombine once the second starts an entry.
+	require.Len(t, sink.AllLogs(), 1)
+	got := sink.AllLogs()[0]
+	require.Equal(t, 1, got.ResourceLogs().Len())
+	require.Equal(t, map[string]any{"aws.s3.bucket": "mybucket"}, got.ResourceLogs().At(0).Resource().Attributes().AsRaw())
+	logRecords := got.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords()
+	require.Equal(t, 1, logRecords.Len())
+	require.Equal(t, "started", logRecords.At(0).Body().Str())
+	require.Equal(t, plog.SeverityNumberInfo, logRecords.At(0).SeverityNumber())
+	require.Equal(t, "INFO", logRecords.At(0).SeverityText())
+	require.Equal(t, pcommon.NewTimestampFromTime(time.Date(2024, 1, 1, 1, 0, 0, 0, time.UTC)), logRecords.At(0).Timestamp())
+
+	// The records of the next batch do not start an entry, so they are combined with
+	// the previous one, which is flushed on shutdown.
+	require.NoError(t, ops.shutdown(context.Background()))
+	require.Len(t, sink.AllLogs(), 2)
+	logRecords = sink.AllLogs()[1].ResourceLogs().At(0).ScopeLogs().At(0).LogRecords()
+	require.Equal(t, 1, logRecords.Len())
+	require.Equal(t, "failed\n  at main.go:12\nnot matching", logRecords.At(0).Body().Str())
+	require.Equal(t, plog.SeverityNumberError, logRecords.At(0).SeverityNumber())
+}
+
+func Test_logOperators_Conversion(t *testing.T) {
+	ld := plog.NewLogs()
+	rl := ld.ResourceLogs().AppendEmpty()
+	rl.Resource().Attributes().PutStr("cloud.region", "us-east-1")
+	sl := rl.ScopeLogs().AppendEmpty()
+	sl.Scope().SetName("scope")
+	record := sl.LogRecords().AppendEmpty()
+	record.SetTimestamp(1581452772000000000)
+	record.SetObservedTimestamp(1581452773000000000)
+	record.SetSeverityNumber(plog.SeverityNumberWarn2)
+	record.SetSeverityText("W")
+	record.SetTraceID([16]byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16})
+	record.SetSpanID([8]byte{1, 2, 3, 4, 5, 6, 7, 8})
+	record.SetFlags(1)
+	record.Attributes().PutInt("count", 3)
+	_ = record.Body().SetEmptyMap().FromRaw(map[string]any{"message": "test", "tags": []any{"a", "b"}})
+
+	ops, err := newLogOperators(unmarshalOperators(t, []any{
+		map[string]any{"type": "noop"},
+	}), componenttest.NewNopTelemetrySettings())
+	require.NoError(t, err)
+	sink := &consumertest.LogsSink{}
+	next, err := ops.logs(sink)
+	require.NoError(t, err)
+	require.NoError(t, ops.start())
+	defer func() { require.NoError(t, ops.shutdown(context.Background())) }()
+
+	require.NoError(t, next.ConsumeLogs(context.Background(), ld))
+	require.Len(t, sink.AllLogs(), 1)
+	require.NoError(t, plogtest.CompareLogs(ld, sink.AllLogs()[0]))
+}
+
+func TestLogsConfig_Validate_Operators(t *testing.T) {
+	cfg := LogsConfig{Operators: unmarshalOperators(t, []any{
+		map[string]any{"type": "regex_parser", "regex": "(?P<a"},
+	})}
+	require.ErrorContains(t, cfg.validate(), "operators: ")
+
+	cfg.Operators = unmarshalOperators(t, []any{
+		map[string]any{"type": "json_parser"},
+	})
+	require.NoError(t, cfg.validate())
+}
+
+func Test_receiveObject_Operators(t *testing.T) {
+	ops, err := newLogOperators(unmarshalOperators(t, []any{
+		map[string]any{"type": "json_parser"},
+	}), componenttest.NewNopTelemetrySettings())
+	require.NoError(t, err)
+	sink := &consumertest.LogsSink{}
+	next, err := ops.logs(sink)
+	require.NoError(t, err)
+	require.NoError(t, ops.start())
+	defer func() { require.NoError(t, ops.shutdown(context.Background())) }()
+	r := &awss3Receiver{
+		dataProcessor: newLogsProcessor(next, LogsConfig{SignalConfig: SignalConfig{Format: FormatText}}, zap.NewNop()),
+		logger:        zap.NewNop(),
+	}
+	require.NoError(t, r.receiveObject(context.Background(), "app/logs_1.log", bytes.NewReader([]byte("{\"level\":\"info\"}\n"))))
+	require.Len(t, sink.AllLogs(), 1)
+	level, ok := sink.AllLogs()[0].ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0).Attributes().Get("level")
+	require.True(t, ok)
+	require.Equal(t, "info", level.Str())
+}
@@ -0,0 +1,51 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/extension/experimental/storage"
+)
+
+// stateConfig describes where the state of a receiver is persisted.
+type stateConfig struct {
+	// storage is the storage extension persisting the state.
+	storage *component.ID
+	// store persists the state instead of a storage extension.
+	store *StateStoreConfig
+	// downloader is the configuration whose credentials and transport the client
+	// of the store uses.
+	downloader S3DownloaderConfig
+}
+
+// newStateClient returns the client persisting the state of the receiver of the
+// telemetry type, or nil if the state is only held in memory.
+func newStateClient(ctx context.Context, host component.Host, cfg stateConfig, id component.ID, telemetryType string) (storage.Client, error) {
+	switch {
+	case cfg.storage != nil:
+		extension, ok := host.GetExtensions()[*cfg.storage]
+		if !ok {
+			return nil, fmt.Errorf("storage extension %q not found", cfg.storage)
+		}
+		storageExtension, ok := extension.(storage.Extension)
+		if !ok {
+			return nil, fmt.Errorf("extension %q is not a storage extension", cfg.storage)
+		}
+		return storageExtension.GetClient(ctx, component.KindReceiver, id, telemetryType)
+	case cfg.store != nil && cfg.store.DynamoDB != nil:
+		client, err := newDynamoDBClient(ctx, cfg.downloader, *cfg.store.DynamoDB)
+		if err != nil {
+			return nil, err
+		}
+		// The keys are prefixed like the ones of the storage extensions, for the
+		// receivers sharing a table to keep their own state.
+		prefix := fmt.Sprintf("%sreceiver_%s_%s_", cfg.store.DynamoDB.KeyPrefix, id, telemetryType)
+		return newDynamoDBStorageClient(client, cfg.store.DynamoDB.TableName, prefix), nil
+	default:
+		return nil, nil
+	}
+}
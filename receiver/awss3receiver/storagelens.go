@@ -0,0 +1,109 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	conventions "go.opentelemetry.io/collector/semconv/v1.25.0"
+)
+
+// storageLensAttributes are the columns of the Storage Lens metrics set as
+// attributes of their data points.
+var storageLensAttributes = []struct {
+	column    string
+	attribute string
+}{
+	{column: "bucket_name", attribute: conventions.AttributeAWSS3Bucket},
+	{column: "storage_class", attribute: "aws.s3.storage_class"},
+	{column: "record_type", attribute: "aws.s3.storage_lens.record_type"},
+	{column: "record_value", attribute: "aws.s3.storage_lens.record_value"},
+}
+
+// storageLensResource identifies the configuration, account and region of Storage
+// Lens metrics.
+type storageLensResource struct {
+	configurationID string
+	accountID       string
+	region          string
+}
+
+// storageLensUnmarshaler unmarshals the CSV or Parquet files of the S3 Storage Lens
+// metrics exports into a gauge data point per row, see
+// https://docs.aws.amazon.com/AmazonS3/latest/userguide/storage_lens_understanding_metrics_export_schema.html.
+// The gauges are named after the metric_name of the rows, StorageBytes becoming
+// aws.s3.storage_lens.storage_bytes, and grouped in resources by configuration,
+// account and region.
+type storageLensUnmarshaler struct{}
+
+func (storageLensUnmarshaler) UnmarshalMetrics(buf []byte) (pmetric.Metrics, error) {
+	metrics := pmetric.NewMetrics()
+	resources := map[storageLensResource]pmetric.MetricSlice{}
+	gauges := map[storageLensResource]map[string]pmetric.Metric{}
+	err := forEachTableRow(buf, curColumnName, func(row map[string]any) {
+		name := curString(row["metric_name"])
+		if name == "" {
+			return
+		}
+		resource := storageLensResource{
+			configurationID: curString(row["configuration_id"]),
+			accountID:       curString(row["aws_account_number"]),
+			region:          curString(row["aws_region"]),
+		}
+		metricSlice, ok := resources[resource]
+		if !ok {
+			resourceMetrics := metrics.ResourceMetrics().AppendEmpty()
+			attributes := resourceMetrics.Resource().Attributes()
+			attributes.PutStr(conventions.AttributeCloudProvider, conventions.AttributeCloudProviderAWS)
+			putNonEmptyStr(attributes, conventions.AttributeCloudAccountID, resource.accountID)
+			putNonEmptyStr(attributes, conventions.AttributeCloudRegion, resource.region)
+			putNonEmptyStr(attributes, "aws.s3.storage_lens.configuration_id", resource.configurationID)
+			metricSlice = resourceMetrics.ScopeMetrics().AppendEmpty().Metrics()
+			resources[resource] = metricSlice
+			gauges[resource] = map[string]pmetric.Metric{}
+		}
+		gauge, ok := gauges[resource][name]
+		if !ok {
+			gauge = metricSlice.AppendEmpty()
+			gauge.SetName("aws.s3.storage_lens." + curColumnName(name))
+			gauge.SetUnit(storageLensUnit(name))
+			gauge.SetEmptyGauge()
+			gauges[resource][name] = gauge
+		}
+		dataPoint := gauge.Gauge().DataPoints().AppendEmpty()
+		if date, err := time.Parse(time.DateOnly, curString(row["report_date"])); err == nil {
+			dataPoint.SetTimestamp(pcommon.NewTimestampFromTime(date))
+		}
+		for _, column := range storageLensAttributes {
+			putNonEmptyStr(dataPoint.Attributes(), column.attribute, curString(row[column.column]))
+		}
+		switch value := row["metric_value"].(type) {
+		case int64:
+			dataPoint.SetIntValue(value)
+		case float64:
+			dataPoint.SetDoubleValue(value)
+		default:
+			text := curString(value)
+			if integer, err := strconv.ParseInt(text, 10, 64); err == nil {
+				dataPoint.SetIntValue(integer)
+			} else if number, err := strconv.ParseFloat(text, 64); err == nil {
+				dataPoint.SetDoubleValue(number)
+			}
+		}
+	})
+	return metrics, err
+}
+
+// storageLensUnit returns the UCUM unit of a Storage Lens metric, bytes for the
+// metrics of storage and transfers and a count otherwise.
+func storageLensUnit(name string) string {
+	if strings.Contains(name, "Bytes") {
+		return "By"
+	}
+	return "1"
+}
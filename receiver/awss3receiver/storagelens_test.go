@@ -0,0 +1,92 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow/go/v15/arrow"
+	"github.com/apache/arrow/go/v15/arrow/array"
+	"github.com/apache/arrow/go/v15/arrow/memory"
+	"github.com/apache/arrow/go/v15/parquet/pqarrow"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+func Test_storageLensUnmarshaler_CSV(t *testing.T) {
+	data := []byte(`version_number,configuration_id,report_date,aws_account_number,aws_region,storage_class,record_type,record_value,bucket_name,metric_name,metric_value
+V_1,default-account-dashboard,2024-01-01,123456789012,us-east-1,STANDARD,BUCKET,,mybucket,StorageBytes,1073741824
+V_1,default-account-dashboard,2024-01-01,123456789012,us-east-1,STANDARD,BUCKET,,mybucket,ObjectCount,42
+V_1,default-account-dashboard,2024-01-01,123456789012,us-east-1,GLACIER,PREFIX,logs/,mybucket,StorageBytes,2048
+V_1,default-account-dashboard,2024-01-01,123456789012,eu-west-1,STANDARD,BUCKET,,otherbucket,PercentIncompleteMPUBytes,0.5
+`)
+
+	metrics, err := storageLensUnmarshaler{}.UnmarshalMetrics(data)
+	require.NoError(t, err)
+	require.Equal(t, 2, metrics.ResourceMetrics().Len())
+	resourceMetrics := metrics.ResourceMetrics().At(0)
+	require.Equal(t, map[string]any{
+		"cloud.provider":                       "aws",
+		"cloud.account.id":                     "123456789012",
+		"cloud.region":                         "us-east-1",
+		"aws.s3.storage_lens.configuration_id": "default-account-dashboard",
+	}, resourceMetrics.Resource().Attributes().AsRaw())
+
+	metricSlice := resourceMetrics.ScopeMetrics().At(0).Metrics()
+	require.Equal(t, 2, metricSlice.Len())
+	storageBytes := metricSlice.At(0)
+	require.Equal(t, "aws.s3.storage_lens.storage_bytes", storageBytes.Name())
+	require.Equal(t, "By", storageBytes.Unit())
+	require.Equal(t, pmetric.MetricTypeGauge, storageBytes.Type())
+	dataPoints := storageBytes.Gauge().DataPoints()
+	require.Equal(t, 2, dataPoints.Len())
+	require.Equal(t, int64(1073741824), dataPoints.At(0).IntValue())
+	require.Equal(t, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), dataPoints.At(0).Timestamp().AsTime())
+	require.Equal(t, map[string]any{
+		"aws.s3.bucket":                   "mybucket",
+		"aws.s3.storage_class":            "STANDARD",
+		"aws.s3.storage_lens.record_type": "BUCKET",
+	}, dataPoints.At(0).Attributes().AsRaw())
+	require.Equal(t, "logs/", dataPoints.At(1).Attributes().AsRaw()["aws.s3.storage_lens.record_value"])
+
+	require.Equal(t, "aws.s3.storage_lens.object_count", metricSlice.At(1).Name())
+	require.Equal(t, "1", metricSlice.At(1).Unit())
+
+	percent := metrics.ResourceMetrics().At(1).ScopeMetrics().At(0).Metrics().At(0)
+	require.Equal(t, "aws.s3.storage_lens.percent_incomplete_mpu_bytes", percent.Name())
+	require.Equal(t, 0.5, percent.Gauge().DataPoints().At(0).DoubleValue())
+}
+
+func Test_storageLensUnmarshaler_Parquet(t *testing.T) {
+	schema := arrow.NewSchema([]arrow.Field{
+		{Name: "report_date", Type: arrow.BinaryTypes.String},
+		{Name: "aws_account_number", Type: arrow.BinaryTypes.String},
+		{Name: "bucket_name", Type: arrow.BinaryTypes.String, Nullable: true},
+		{Name: "metric_name", Type: arrow.BinaryTypes.String},
+		{Name: "metric_value", Type: arrow.PrimitiveTypes.Int64},
+	}, nil)
+	builder := array.NewRecordBuilder(memory.DefaultAllocator, schema)
+	defer builder.Release()
+	builder.Field(0).(*array.StringBuilder).AppendValues([]string{"2024-01-02"}, nil)
+	builder.Field(1).(*array.StringBuilder).AppendValues([]string{"123456789012"}, nil)
+	builder.Field(2).(*array.StringBuilder).AppendValues([]string{""}, []bool{false})
+	builder.Field(3).(*array.StringBuilder).AppendValues([]string{"ObjectCount"}, nil)
+	builder.Field(4).(*array.Int64Builder).AppendValues([]int64{7}, nil)
+	record := builder.NewRecord()
+	defer record.Release()
+	table := array.NewTableFromRecords(schema, []arrow.Record{record})
+	defer table.Release()
+	var buf bytes.Buffer
+	require.NoError(t, pqarrow.WriteTable(table, &buf, 1024, nil, pqarrow.DefaultWriterProps()))
+
+	metrics, err := storageLensUnmarshaler{}.UnmarshalMetrics(buf.Bytes())
+	require.NoError(t, err)
+	require.Equal(t, 1, metrics.ResourceMetrics().Len())
+	dataPoint := metrics.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(0).Gauge().DataPoints().At(0)
+	require.Equal(t, int64(7), dataPoint.IntValue())
+	require.Equal(t, time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), dataPoint.Timestamp().AsTime())
+	require.Equal(t, 0, dataPoint.Attributes().Len())
+}
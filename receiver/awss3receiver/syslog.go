@@ -0,0 +1,139 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"bytes"
+	"context"
+	"regexp"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/extension/experimental/storage"
+	"go.opentelemetry.io/collector/pdata/plog"
+	conventions "go.opentelemetry.io/collector/semconv/v1.25.0"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/stanza/adapter"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/stanza/entry"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/stanza/operator"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/stanza/operator/helper"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/stanza/operator/parser/syslog"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/stanza/pipeline"
+)
+
+// rfc5424Pattern matches the start of RFC 5424 messages, their priority followed
+// by their version.
+var rfc5424Pattern = regexp.MustCompile(`^<\d{1,3}>\d{1,2} `)
+
+// syslogDecoder decodes objects holding a syslog message per line into a log
+// record per message, parsed by the syslog parser of the syslog receiver: the
+// message is the body, its fields the attributes and its timestamp and severity
+// those of the record. The lines that cannot be parsed are kept as body only.
+type syslogDecoder struct {
+	cfg    SyslogConfig
+	logger *zap.Logger
+}
+
+func newSyslogDecoder(cfg SyslogConfig, logger *zap.Logger) *syslogDecoder {
+	return &syslogDecoder{cfg: cfg, logger: logger}
+}
+
+// syslogParser is the pipeline parsing the messages of a protocol, whose entries
+// are collected by collector.
+type syslogParser struct {
+	pipe      *pipeline.DirectedPipeline
+	collector *entryCollector
+}
+
+// buildParsers builds the parsers of the configured protocol, or of both of them
+// if it is to be detected.
+func (d *syslogDecoder) buildParsers() (map[string]syslogParser, error) {
+	protocols := []string{syslog.RFC3164, syslog.RFC5424}
+	if d.cfg.Protocol != "" {
+		protocols = []string{d.cfg.Protocol}
+	}
+	parsers := make(map[string]syslogParser, len(protocols))
+	for _, protocol := range protocols {
+		cfg := syslog.NewConfig()
+		cfg.Protocol = protocol
+		cfg.Location = d.cfg.Location
+		cfg.OnError = helper.SendOnErrorQuiet
+		pipe, collector, err := buildOperators([]operator.Config{{Builder: cfg}}, component.TelemetrySettings{Logger: d.logger})
+		if err != nil {
+			return nil, err
+		}
+		parsers[protocol] = syslogParser{pipe: pipe, collector: collector}
+	}
+	return parsers, nil
+}
+
+func (d *syslogDecoder) decodeLogs(info objectInfo, data []byte) (plog.Logs, error) {
+	logs, records := newObjectLogs()
+	parsers, err := d.buildParsers()
+	if err != nil {
+		return logs, err
+	}
+	for _, parser := range parsers {
+		if err := parser.pipe.Start(storage.NewNopClient()); err != nil {
+			return logs, err
+		}
+		defer func(pipe *pipeline.DirectedPipeline) { _ = pipe.Stop() }(parser.pipe)
+	}
+	var entries []*entry.Entry
+	for index, line := range bytes.Split(data, []byte("\n")) {
+		line = bytes.TrimRight(line, "\r\x00")
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		protocol := d.cfg.Protocol
+		if protocol == "" {
+			protocol = syslog.RFC3164
+			if rfc5424Pattern.Match(line) {
+				protocol = syslog.RFC5424
+			}
+		}
+		parser := parsers[protocol]
+		e := entry.New()
+		e.ObservedTimestamp = info.lastModified
+		e.Body = string(line)
+		if err := parser.pipe.Operators()[0].Process(context.Background(), e); err != nil {
+			d.logger.Warn("Unable to parse syslog message", zap.String("key", info.key), zap.Int("line", index+1), zap.Error(err))
+		}
+		entries = append(entries, parser.collector.take()...)
+	}
+	if len(entries) == 0 {
+		return logs, nil
+	}
+	for _, e := range entries {
+		setObjectAttributes(e, info)
+	}
+	converter := adapter.NewConverter(d.logger)
+	converter.Start()
+	defer converter.Stop()
+	if err := converter.Batch(entries); err != nil {
+		return logs, err
+	}
+	converted := <-converter.OutChannel()
+	converted.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().MoveAndAppendTo(records)
+	if info.lastModified.IsZero() {
+		// The records of objects without a modification time have no observed
+		// timestamp, as with appendObjectRecord.
+		for i := 0; i < records.Len(); i++ {
+			records.At(i).SetObservedTimestamp(0)
+		}
+	}
+	return logs, nil
+}
+
+// setObjectAttributes sets the attributes of the object an entry comes from, as
+// those of the records appended with appendObjectRecord.
+func setObjectAttributes(e *entry.Entry, info objectInfo) {
+	if info.bucket != "" {
+		e.AddAttribute(conventions.AttributeAWSS3Bucket, info.bucket)
+	}
+	e.AddAttribute(conventions.AttributeAWSS3Key, info.key)
+	if info.member != "" {
+		e.AddAttribute("aws.s3.archive.member", info.member)
+	}
+}
@@ -0,0 +1,70 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.uber.org/zap"
+)
+
+func Test_syslogDecoder(t *testing.T) {
+	info := objectInfo{bucket: "bucket", key: "network/2024/01/01/router.log", lastModified: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)}
+	data := []byte(`<34>1 2024-01-01T10:00:00.000Z router1 sshd 1234 ID47 [exampleSDID@32473 iut="3"] Failed password for root
+<13>Jan  1 10:00:01 switch2 kernel: link down on port 7
+
+not a syslog message
+`)
+
+	logs, err := newSyslogDecoder(SyslogConfig{}, zap.NewNop()).decodeLogs(info, data)
+	require.NoError(t, err)
+	records := logs.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords()
+	require.Equal(t, 3, records.Len())
+
+	record := records.At(0)
+	require.Equal(t, `<34>1 2024-01-01T10:00:00.000Z router1 sshd 1234 ID47 [exampleSDID@32473 iut="3"] Failed password for root`, record.Body().Str())
+	require.Equal(t, time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC), record.Timestamp().AsTime())
+	require.Equal(t, info.lastModified, record.ObservedTimestamp().AsTime())
+	require.Equal(t, plog.SeverityNumberError2, record.SeverityNumber())
+	attributes := record.Attributes().AsRaw()
+	require.Equal(t, "bucket", attributes["aws.s3.bucket"])
+	require.Equal(t, info.key, attributes["aws.s3.key"])
+	require.Equal(t, "router1", attributes["hostname"])
+	require.Equal(t, "sshd", attributes["appname"])
+	require.Equal(t, "1234", attributes["proc_id"])
+	require.Equal(t, "ID47", attributes["msg_id"])
+	require.Equal(t, "Failed password for root", attributes["message"])
+	require.Equal(t, map[string]any{"exampleSDID@32473": map[string]any{"iut": "3"}}, attributes["structured_data"])
+
+	record = records.At(1)
+	require.Equal(t, time.Date(time.Now().Year(), 1, 1, 10, 0, 1, 0, time.UTC), record.Timestamp().AsTime())
+	require.Equal(t, "switch2", record.Attributes().AsRaw()["hostname"])
+	require.Equal(t, "link down on port 7", record.Attributes().AsRaw()["message"])
+
+	record = records.At(2)
+	require.Equal(t, "not a syslog message", record.Body().Str())
+	require.Equal(t, map[string]any{"aws.s3.bucket": "bucket", "aws.s3.key": info.key}, record.Attributes().AsRaw())
+}
+
+func Test_syslogDecoder_Protocol(t *testing.T) {
+	info := objectInfo{key: "syslog.log"}
+	logs, err := newSyslogDecoder(SyslogConfig{Protocol: "rfc3164", Location: "America/New_York"}, zap.NewNop()).decodeLogs(info, []byte("<13>Jan  1 10:00:01 switch2 kernel: link down\n"))
+	require.NoError(t, err)
+	record := logs.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0)
+	location, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+	require.Equal(t, time.Date(time.Now().Year(), 1, 1, 10, 0, 1, 0, location).UTC(), record.Timestamp().AsTime())
+
+	cfg := createDefaultConfig().(*Config)
+	cfg.S3Downloader.S3Bucket = "abucket"
+	cfg.StartTime = "2024-01-01"
+	cfg.EndTime = "2024-01-02"
+	cfg.Logs.Format = FormatSyslog
+	cfg.Logs.Syslog.Protocol = "rfc1234"
+	assert.EqualError(t, cfg.Validate(), "logs: syslog: unsupported protocol version: rfc1234")
+}
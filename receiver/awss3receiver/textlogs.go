@@ -0,0 +1,92 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"bufio"
+	"bytes"
+	"regexp"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.uber.org/zap"
+	"golang.org/x/text/encoding/unicode"
+)
+
+// textDecoder decodes objects holding text into a log record per non-empty line
+// or, with split set, per group of lines it splits the text into. With
+// timestampPattern set, the first capturing group of its first match in each
+// record is the time of the record.
+type textDecoder struct {
+	split            bufio.SplitFunc
+	timestampPattern *regexp.Regexp
+	timestamps       timestampParser
+	logger           *zap.Logger
+}
+
+// newTextDecoder returns the decoder of the text objects, whose multiline and
+// timestamp settings have been validated.
+func newTextDecoder(cfg TextConfig, logger *zap.Logger) textDecoder {
+	decoder := textDecoder{logger: logger}
+	if cfg.Multiline.LineStartPattern != "" || cfg.Multiline.LineEndPattern != "" {
+		decoder.split, _ = cfg.splitFunc()
+	}
+	if cfg.TimestampPattern != "" {
+		decoder.timestampPattern = regexp.MustCompile(cfg.TimestampPattern)
+		decoder.timestamps, _ = cfg.parser()
+	}
+	return decoder
+}
+
+// splitFunc returns the function splitting the text into groups of lines, the
+// lines between two matches of the start or end pattern.
+func (c TextConfig) splitFunc() (bufio.SplitFunc, error) {
+	return c.Multiline.Func(unicode.UTF8, true, 0)
+}
+
+func (d textDecoder) decodeLogs(info objectInfo, data []byte) (plog.Logs, error) {
+	logs, records := newObjectLogs()
+	if d.split == nil {
+		for _, line := range bytes.Split(data, []byte("\n")) {
+			line = bytes.TrimSuffix(line, []byte("\r"))
+			if len(bytes.TrimSpace(line)) == 0 {
+				continue
+			}
+			d.appendRecord(records, info, string(line))
+		}
+		return logs, nil
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	// A group of lines may be as large as the whole object.
+	scanner.Buffer(nil, len(data)+1)
+	scanner.Split(d.split)
+	for scanner.Scan() {
+		group := bytes.Trim(scanner.Bytes(), "\r\n")
+		if len(bytes.TrimSpace(group)) == 0 {
+			continue
+		}
+		d.appendRecord(records, info, string(group))
+	}
+	return logs, scanner.Err()
+}
+
+// appendRecord appends the record of some text, with the time matched by the
+// timestamp pattern if any.
+func (d textDecoder) appendRecord(records plog.LogRecordSlice, info objectInfo, text string) {
+	record := appendObjectRecord(records, info)
+	record.Body().SetStr(text)
+	if d.timestampPattern == nil {
+		return
+	}
+	match := d.timestampPattern.FindStringSubmatch(text)
+	if len(match) < 2 {
+		return
+	}
+	timestamp, err := d.timestamps.parse(match[1])
+	if err != nil {
+		d.logger.Warn("Unable to parse the timestamp of a text record", zap.String("key", info.key), zap.Error(err))
+		return
+	}
+	record.SetTimestamp(pcommon.NewTimestampFromTime(timestamp))
+}
@@ -0,0 +1,86 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/stanza/split"
+)
+
+func Test_textDecoder(t *testing.T) {
+	info := objectInfo{bucket: "bucket", key: "archive/logs_1.log", lastModified: testTime}
+	logs, err := textDecoder{}.decodeLogs(info, []byte("first line\r\n\n  second line\n"))
+	require.NoError(t, err)
+	records := logs.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords()
+	require.Equal(t, 2, records.Len())
+	require.Equal(t, "first line", records.At(0).Body().Str())
+	require.Equal(t, "  second line", records.At(1).Body().Str())
+	require.Equal(t, pcommon.NewTimestampFromTime(testTime), records.At(1).ObservedTimestamp())
+	require.Equal(t, map[string]any{
+		"aws.s3.bucket": "bucket",
+		"aws.s3.key":    "archive/logs_1.log",
+	}, records.At(1).Attributes().AsRaw())
+}
+
+func Test_textDecoder_Multiline(t *testing.T) {
+	data := []byte("2024-01-01 INFO started\n2024-01-01 ERROR failed\n  at main.go:12\r\n  at main.go:3\n\n2024-01-01 INFO stopped\n")
+	for name, tt := range map[string]struct {
+		multiline split.Config
+		want      []string
+	}{
+		"line_start_pattern": {
+			multiline: split.Config{LineStartPattern: `^\d{4}-`},
+			want:      []string{"2024-01-01 INFO started", "2024-01-01 ERROR failed\n  at main.go:12\r\n  at main.go:3", "2024-01-01 INFO stopped"},
+		},
+		"line_end_pattern": {
+			multiline: split.Config{LineEndPattern: `main\.go:3\n|(INFO \w+)\n`},
+			want:      []string{"2024-01-01 INFO started", "2024-01-01 ERROR failed\n  at main.go:12\r\n  at main.go:3", "2024-01-01 INFO stopped"},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			logs, err := newTextDecoder(TextConfig{Multiline: tt.multiline}, zap.NewNop()).decodeLogs(objectInfo{key: "logs_1.log"}, data)
+			require.NoError(t, err)
+			records := logs.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords()
+			var got []string
+			for i := 0; i < records.Len(); i++ {
+				got = append(got, records.At(i).Body().Str())
+			}
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_textDecoder_TimestampPattern(t *testing.T) {
+	cfg := TextConfig{TimestampPattern: `^\[([^\]]+)\]`}
+	cfg.TimestampLayout = "%d/%b/%Y:%H:%M:%S"
+	cfg.TimestampLayoutType = TimestampLayoutTypeStrptime
+	cfg.TimestampLocation = "Europe/Paris"
+	data := []byte("[01/Feb/2021:18:00:00] started\n[not a time] failed\nno time\n")
+	logs, err := newTextDecoder(cfg, zap.NewNop()).decodeLogs(objectInfo{key: "logs_1.log"}, data)
+	require.NoError(t, err)
+	records := logs.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords()
+	require.Equal(t, 3, records.Len())
+	require.Equal(t, "[01/Feb/2021:18:00:00] started", records.At(0).Body().Str())
+	require.Equal(t, time.Date(2021, 2, 1, 17, 0, 0, 0, time.UTC), records.At(0).Timestamp().AsTime())
+	require.Zero(t, records.At(1).Timestamp())
+	require.Zero(t, records.At(2).Timestamp())
+}
+
+func TestLogsConfig_Validate_Multiline(t *testing.T) {
+	cfg := LogsConfig{SignalConfig: SignalConfig{Format: FormatText}}
+	cfg.Text.Multiline = split.Config{LineStartPattern: "^a", LineEndPattern: "b$"}
+	require.EqualError(t, cfg.validate(), "text multiline: only one of line_start_pattern or line_end_pattern can be set")
+
+	cfg.Text.Multiline = split.Config{LineStartPattern: "(a"}
+	require.ErrorContains(t, cfg.validate(), "text multiline: compile line start regex: ")
+
+	cfg.Text.Multiline = split.Config{LineStartPattern: `^\d{4}-`}
+	require.NoError(t, cfg.validate())
+}
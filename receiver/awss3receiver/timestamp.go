@@ -0,0 +1,97 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/coreinternal/timeutils"
+)
+
+// epochUnits are the durations of the units of the epoch timestamp layouts.
+var epochUnits = map[string]time.Duration{
+	"s":  time.Second,
+	"ms": time.Millisecond,
+	"us": time.Microsecond,
+	"ns": time.Nanosecond,
+}
+
+// timestampParser parses the timestamps of log records, either strings in its
+// Go layout, RFC 3339 by default, or numbers of seconds since the epoch. With an
+// epoch unit, the strings are numbers of that unit since the epoch as well. The
+// string timestamps without a time zone are in its location, UTC by default.
+type timestampParser struct {
+	layout    string
+	epochUnit time.Duration
+	location  *time.Location
+}
+
+// parser returns the parser of the timestamps of the configured layout.
+func (c TimestampLayoutConfig) parser() (timestampParser, error) {
+	parser := timestampParser{layout: c.TimestampLayout}
+	switch c.TimestampLayoutType {
+	case "", TimestampLayoutTypeGotime:
+	case TimestampLayoutTypeStrptime:
+		layout, err := timeutils.StrptimeToGotime(c.TimestampLayout)
+		if err != nil {
+			return parser, fmt.Errorf("invalid strptime timestamp_layout: %w", err)
+		}
+		parser.layout = layout
+	case TimestampLayoutTypeEpoch:
+		unit := c.TimestampLayout
+		if unit == "" {
+			unit = "s"
+		}
+		var ok bool
+		if parser.epochUnit, ok = epochUnits[unit]; !ok {
+			return parser, errors.New("epoch timestamp_layout must be one of 's', 'ms', 'us' or 'ns'")
+		}
+	default:
+		return parser, fmt.Errorf("timestamp_layout_type must be one of '%s', '%s' or '%s'", TimestampLayoutTypeGotime, TimestampLayoutTypeStrptime, TimestampLayoutTypeEpoch)
+	}
+	if c.TimestampLocation != "" {
+		location, err := time.LoadLocation(c.TimestampLocation)
+		if err != nil {
+			return parser, fmt.Errorf("invalid timestamp_location: %w", err)
+		}
+		parser.location = location
+	}
+	return parser, nil
+}
+
+func (p timestampParser) parse(value any) (time.Time, error) {
+	unit := p.epochUnit
+	if text, ok := value.(string); ok && unit != 0 {
+		number, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			return time.Time{}, err
+		}
+		value = number
+	}
+	if unit == 0 {
+		unit = time.Second
+	}
+	switch v := value.(type) {
+	case string:
+		layout, location := p.layout, p.location
+		if layout == "" {
+			layout = time.RFC3339Nano
+		}
+		if location == nil {
+			location = time.UTC
+		}
+		return time.ParseInLocation(layout, v, location)
+	case int64:
+		return time.Unix(0, 0).Add(time.Duration(v) * unit).UTC(), nil
+	case float64:
+		whole, fraction := math.Modf(v)
+		return time.Unix(0, 0).Add(time.Duration(whole)*unit + time.Duration(fraction*float64(unit))).UTC(), nil
+	default:
+		return time.Time{}, fmt.Errorf("unsupported timestamp %v", value)
+	}
+}
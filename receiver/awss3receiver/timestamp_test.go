@@ -0,0 +1,96 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_timestampParser(t *testing.T) {
+	paris, err := time.LoadLocation("Europe/Paris")
+	require.NoError(t, err)
+	for name, tt := range map[string]struct {
+		cfg   TimestampLayoutConfig
+		value any
+		want  time.Time
+	}{
+		"default": {
+			value: "2021-02-01T17:00:00.5+01:00",
+			want:  time.Date(2021, 2, 1, 16, 0, 0, 5e8, time.UTC),
+		},
+		"default seconds": {
+			value: 1612198800.5,
+			want:  time.Date(2021, 2, 1, 17, 0, 0, 5e8, time.UTC),
+		},
+		"gotime": {
+			cfg:   TimestampLayoutConfig{TimestampLayout: time.DateTime, TimestampLayoutType: TimestampLayoutTypeGotime},
+			value: "2021-02-01 17:00:00",
+			want:  time.Date(2021, 2, 1, 17, 0, 0, 0, time.UTC),
+		},
+		"gotime location": {
+			cfg:   TimestampLayoutConfig{TimestampLayout: time.DateTime, TimestampLocation: "Europe/Paris"},
+			value: "2021-02-01 17:00:00",
+			want:  time.Date(2021, 2, 1, 17, 0, 0, 0, paris),
+		},
+		"gotime location with zone": {
+			cfg:   TimestampLayoutConfig{TimestampLocation: "Europe/Paris"},
+			value: "2021-02-01T17:00:00Z",
+			want:  time.Date(2021, 2, 1, 17, 0, 0, 0, time.UTC),
+		},
+		"strptime": {
+			cfg:   TimestampLayoutConfig{TimestampLayout: "%Y-%m-%d %H:%M:%S.%f", TimestampLayoutType: TimestampLayoutTypeStrptime},
+			value: "2021-02-01 17:00:00.250",
+			want:  time.Date(2021, 2, 1, 17, 0, 0, 25e7, time.UTC),
+		},
+		"epoch milliseconds": {
+			cfg:   TimestampLayoutConfig{TimestampLayout: "ms", TimestampLayoutType: TimestampLayoutTypeEpoch},
+			value: "1612198800250",
+			want:  time.Date(2021, 2, 1, 17, 0, 0, 25e7, time.UTC),
+		},
+		"epoch default unit": {
+			cfg:   TimestampLayoutConfig{TimestampLayoutType: TimestampLayoutTypeEpoch},
+			value: int64(1612198800),
+			want:  time.Date(2021, 2, 1, 17, 0, 0, 0, time.UTC),
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			parser, err := tt.cfg.parser()
+			require.NoError(t, err)
+			got, err := parser.parse(tt.value)
+			require.NoError(t, err)
+			require.True(t, tt.want.Equal(got), "want %v, got %v", tt.want, got)
+		})
+	}
+}
+
+func Test_timestampParser_Invalid(t *testing.T) {
+	_, err := TimestampLayoutConfig{TimestampLayoutType: "native"}.parser()
+	require.EqualError(t, err, "timestamp_layout_type must be one of 'gotime', 'strptime' or 'epoch'")
+	_, err = TimestampLayoutConfig{TimestampLayout: "%Y-%Q", TimestampLayoutType: TimestampLayoutTypeStrptime}.parser()
+	require.ErrorContains(t, err, "invalid strptime timestamp_layout: ")
+	_, err = TimestampLayoutConfig{TimestampLayout: "minutes", TimestampLayoutType: TimestampLayoutTypeEpoch}.parser()
+	require.EqualError(t, err, "epoch timestamp_layout must be one of 's', 'ms', 'us' or 'ns'")
+	_, err = TimestampLayoutConfig{TimestampLocation: "Mars/Olympus_Mons"}.parser()
+	require.ErrorContains(t, err, "invalid timestamp_location: ")
+
+	parser, err := TimestampLayoutConfig{TimestampLayoutType: TimestampLayoutTypeEpoch}.parser()
+	require.NoError(t, err)
+	_, err = parser.parse("yesterday")
+	require.Error(t, err)
+	_, err = timestampParser{}.parse(true)
+	require.EqualError(t, err, "unsupported timestamp true")
+
+	cfg := LogsConfig{SignalConfig: SignalConfig{Format: FormatJSONLines}}
+	cfg.JSONLines.TimestampLayoutType = "native"
+	require.EqualError(t, cfg.validate(), "json_lines: timestamp_layout_type must be one of 'gotime', 'strptime' or 'epoch'")
+	cfg = LogsConfig{SignalConfig: SignalConfig{Format: FormatCSV}}
+	cfg.CSV.TimestampLocation = "Mars/Olympus_Mons"
+	require.ErrorContains(t, cfg.validate(), "csv: invalid timestamp_location: ")
+	cfg = LogsConfig{SignalConfig: SignalConfig{Format: FormatText}}
+	cfg.Text.TimestampPattern = "(a"
+	require.ErrorContains(t, cfg.validate(), "text: invalid timestamp_pattern: ")
+}
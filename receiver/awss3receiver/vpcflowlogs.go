@@ -0,0 +1,166 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/apache/arrow/go/v15/arrow"
+	"github.com/apache/arrow/go/v15/arrow/array"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	conventions "go.opentelemetry.io/collector/semconv/v1.25.0"
+	"go.uber.org/zap"
+)
+
+// vpcFlowLogsIntegerFields are the VPC flow log fields holding integers.
+var vpcFlowLogsIntegerFields = map[string]bool{
+	"version":      true,
+	"srcport":      true,
+	"dstport":      true,
+	"protocol":     true,
+	"packets":      true,
+	"bytes":        true,
+	"start":        true,
+	"end":          true,
+	"tcp_flags":    true,
+	"traffic_path": true,
+}
+
+// vpcFlowLogsAttributes are the semantic convention attributes of the VPC flow
+// log fields that have one. The other fields are set as aws.vpc.flow.<field>.
+var vpcFlowLogsAttributes = map[string]string{
+	"account_id": conventions.AttributeCloudAccountID,
+	"region":     conventions.AttributeCloudRegion,
+	"srcaddr":    conventions.AttributeSourceAddress,
+	"srcport":    conventions.AttributeSourcePort,
+	"dstaddr":    conventions.AttributeDestinationAddress,
+	"dstport":    conventions.AttributeDestinationPort,
+}
+
+// vpcFlowLogsField is a field of a VPC flow log record, named as in the Parquet
+// files, with underscores.
+type vpcFlowLogsField struct {
+	name  string
+	value any
+}
+
+// vpcFlowLogsDecoder decodes VPC flow log files into a log record per flow. The
+// files are either space separated text, whose first line names the fields, or
+// Apache Parquet.
+type vpcFlowLogsDecoder struct {
+	logger *zap.Logger
+}
+
+func (d *vpcFlowLogsDecoder) decodeLogs(info objectInfo, data []byte) (plog.Logs, error) {
+	if bytes.HasPrefix(data, parquetMagic) {
+		return d.decodeParquet(info, data)
+	}
+	return d.decodeText(info, data), nil
+}
+
+func (d *vpcFlowLogsDecoder) decodeText(info objectInfo, data []byte) plog.Logs {
+	logs, records := newObjectLogs()
+	var names []string
+	for index, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		values := strings.Fields(line)
+		if names == nil {
+			names = make([]string, len(values))
+			for i, value := range values {
+				names[i] = strings.ReplaceAll(value, "-", "_")
+			}
+			continue
+		}
+		if len(values) != len(names) {
+			d.logger.Warn("Skipping invalid VPC flow log record", zap.String("key", info.key), zap.Int("line", index+1))
+			continue
+		}
+		fields := make([]vpcFlowLogsField, 0, len(values))
+		for i, value := range values {
+			if value == "-" {
+				continue
+			}
+			field := vpcFlowLogsField{name: names[i], value: value}
+			if vpcFlowLogsIntegerFields[field.name] {
+				if integer, err := strconv.ParseInt(value, 10, 64); err == nil {
+					field.value = integer
+				}
+			}
+			fields = append(fields, field)
+		}
+		record := appendObjectRecord(records, info)
+		record.Body().SetStr(line)
+		setVPCFlowLogsRecord(record, fields)
+	}
+	return logs
+}
+
+func (d *vpcFlowLogsDecoder) decodeParquet(info objectInfo, data []byte) (plog.Logs, error) {
+	logs, records := newObjectLogs()
+	err := forEachParquetRecord(data, func(rec arrow.Record) {
+		for i := 0; i < int(rec.NumRows()); i++ {
+			fields := make([]vpcFlowLogsField, 0, rec.NumCols())
+			values := make([]string, 0, rec.NumCols())
+			for c, column := range rec.Columns() {
+				if column.IsNull(i) {
+					values = append(values, "-")
+					continue
+				}
+				field := vpcFlowLogsField{name: rec.ColumnName(c), value: arrowValue(column, i)}
+				values = append(values, column.ValueStr(i))
+				fields = append(fields, field)
+			}
+			record := appendObjectRecord(records, info)
+			record.Body().SetStr(strings.Join(values, " "))
+			setVPCFlowLogsRecord(record, fields)
+		}
+	})
+	return logs, err
+}
+
+// setVPCFlowLogsRecord sets the attributes and timestamp of a record from the
+// fields of a flow, the timestamp being the start of the flow.
+func setVPCFlowLogsRecord(record plog.LogRecord, fields []vpcFlowLogsField) {
+	attributes := record.Attributes()
+	for _, field := range fields {
+		name, ok := vpcFlowLogsAttributes[field.name]
+		if !ok {
+			name = "aws.vpc.flow." + field.name
+		}
+		_ = attributes.PutEmpty(name).FromRaw(field.value)
+		switch field.name {
+		case "start":
+			if start, ok := field.value.(int64); ok {
+				record.SetTimestamp(pcommon.NewTimestampFromTime(time.Unix(start, 0)))
+			}
+		case "protocol":
+			switch field.value {
+			case int64(6):
+				attributes.PutStr(conventions.AttributeNetworkTransport, conventions.AttributeNetworkTransportTCP)
+			case int64(17):
+				attributes.PutStr(conventions.AttributeNetworkTransport, conventions.AttributeNetworkTransportUDP)
+			}
+		}
+	}
+}
+
+// arrowValue returns the value of an array at index i, as an int64 for integers
+// and as a string otherwise.
+func arrowValue(arr arrow.Array, i int) any {
+	switch a := arr.(type) {
+	case *array.Int32:
+		return int64(a.Value(i))
+	case *array.Int64:
+		return a.Value(i)
+	default:
+		return arr.ValueStr(i)
+	}
+}
@@ -0,0 +1,108 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/apache/arrow/go/v15/arrow"
+	"github.com/apache/arrow/go/v15/arrow/array"
+	"github.com/apache/arrow/go/v15/arrow/memory"
+	"github.com/apache/arrow/go/v15/parquet/pqarrow"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func Test_vpcFlowLogsDecoder_Text(t *testing.T) {
+	info := objectInfo{bucket: "bucket", key: "AWSLogs/123456789012/vpcflowlogs/us-east-1/2021/02/01/flow.log"}
+	data := []byte(`version account-id interface-id srcaddr dstaddr srcport dstport protocol packets bytes start end action log-status
+2 123456789012 eni-1235b8ca123456789 172.31.16.139 172.31.16.21 20641 22 6 20 4249 1612198800 1612198860 ACCEPT OK
+2 123456789012 eni-1235b8ca123456789 - - - - - - - 1612198800 1612198860 - NODATA
+invalid
+`)
+
+	logs, err := (&vpcFlowLogsDecoder{logger: zap.NewNop()}).decodeLogs(info, data)
+	require.NoError(t, err)
+	records := logs.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords()
+	require.Equal(t, 2, records.Len())
+	require.Equal(t, "2 123456789012 eni-1235b8ca123456789 172.31.16.139 172.31.16.21 20641 22 6 20 4249 1612198800 1612198860 ACCEPT OK", records.At(0).Body().Str())
+	require.Equal(t, time.Date(2021, 2, 1, 17, 0, 0, 0, time.UTC), records.At(0).Timestamp().AsTime())
+	require.Equal(t, map[string]any{
+		"aws.s3.bucket":             "bucket",
+		"aws.s3.key":                info.key,
+		"aws.vpc.flow.version":      int64(2),
+		"cloud.account.id":          "123456789012",
+		"aws.vpc.flow.interface_id": "eni-1235b8ca123456789",
+		"source.address":            "172.31.16.139",
+		"destination.address":       "172.31.16.21",
+		"source.port":               int64(20641),
+		"destination.port":          int64(22),
+		"aws.vpc.flow.protocol":     int64(6),
+		"network.transport":         "tcp",
+		"aws.vpc.flow.packets":      int64(20),
+		"aws.vpc.flow.bytes":        int64(4249),
+		"aws.vpc.flow.start":        int64(1612198800),
+		"aws.vpc.flow.end":          int64(1612198860),
+		"aws.vpc.flow.action":       "ACCEPT",
+		"aws.vpc.flow.log_status":   "OK",
+	}, records.At(0).Attributes().AsRaw())
+	require.Equal(t, map[string]any{
+		"aws.s3.bucket":             "bucket",
+		"aws.s3.key":                info.key,
+		"aws.vpc.flow.version":      int64(2),
+		"cloud.account.id":          "123456789012",
+		"aws.vpc.flow.interface_id": "eni-1235b8ca123456789",
+		"aws.vpc.flow.start":        int64(1612198800),
+		"aws.vpc.flow.end":          int64(1612198860),
+		"aws.vpc.flow.log_status":   "NODATA",
+	}, records.At(1).Attributes().AsRaw())
+}
+
+func Test_vpcFlowLogsDecoder_Parquet(t *testing.T) {
+	schema := arrow.NewSchema([]arrow.Field{
+		{Name: "version", Type: arrow.PrimitiveTypes.Int32},
+		{Name: "srcaddr", Type: arrow.BinaryTypes.String, Nullable: true},
+		{Name: "protocol", Type: arrow.PrimitiveTypes.Int32, Nullable: true},
+		{Name: "start", Type: arrow.PrimitiveTypes.Int64},
+		{Name: "action", Type: arrow.BinaryTypes.String, Nullable: true},
+	}, nil)
+	builder := array.NewRecordBuilder(memory.DefaultAllocator, schema)
+	defer builder.Release()
+	builder.Field(0).(*array.Int32Builder).AppendValues([]int32{5, 5}, nil)
+	builder.Field(1).(*array.StringBuilder).AppendValues([]string{"172.31.16.139", ""}, []bool{true, false})
+	builder.Field(2).(*array.Int32Builder).AppendValues([]int32{17, 0}, []bool{true, false})
+	builder.Field(3).(*array.Int64Builder).AppendValues([]int64{1612198800, 1612198860}, nil)
+	builder.Field(4).(*array.StringBuilder).AppendValues([]string{"REJECT", ""}, []bool{true, false})
+	record := builder.NewRecord()
+	defer record.Release()
+	table := array.NewTableFromRecords(schema, []arrow.Record{record})
+	defer table.Release()
+	var buf bytes.Buffer
+	require.NoError(t, pqarrow.WriteTable(table, &buf, 1024, nil, pqarrow.DefaultWriterProps()))
+
+	info := objectInfo{key: "AWSLogs/123456789012/vpcflowlogs/us-east-1/2021/02/01/flow.log.parquet"}
+	logs, err := (&vpcFlowLogsDecoder{logger: zap.NewNop()}).decodeLogs(info, buf.Bytes())
+	require.NoError(t, err)
+	records := logs.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords()
+	require.Equal(t, 2, records.Len())
+	require.Equal(t, "5 172.31.16.139 17 1612198800 REJECT", records.At(0).Body().Str())
+	require.Equal(t, time.Date(2021, 2, 1, 17, 0, 0, 0, time.UTC), records.At(0).Timestamp().AsTime())
+	require.Equal(t, map[string]any{
+		"aws.s3.key":            info.key,
+		"aws.vpc.flow.version":  int64(5),
+		"source.address":        "172.31.16.139",
+		"aws.vpc.flow.protocol": int64(17),
+		"network.transport":     "udp",
+		"aws.vpc.flow.start":    int64(1612198800),
+		"aws.vpc.flow.action":   "REJECT",
+	}, records.At(0).Attributes().AsRaw())
+	require.Equal(t, "5 - - 1612198860 -", records.At(1).Body().Str())
+	require.Equal(t, map[string]any{
+		"aws.s3.key":           info.key,
+		"aws.vpc.flow.version": int64(5),
+		"aws.vpc.flow.start":   int64(1612198860),
+	}, records.At(1).Attributes().AsRaw())
+}
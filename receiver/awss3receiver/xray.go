@@ -0,0 +1,432 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	conventions "go.opentelemetry.io/collector/semconv/v1.6.1"
+
+	awsxray "github.com/open-telemetry/opentelemetry-collector-contrib/internal/aws/xray"
+)
+
+// xrayTracesUnmarshaler unmarshals X-Ray segment documents into traces, translated
+// like the awsxrayreceiver does, a resource per segment. The objects hold either
+// the output of the BatchGetTraces API, with the documents of the segments of
+// each trace, or a stream of documents, optionally preceded by the headers of the
+// X-Ray daemon protocol. The attributes follow the conventions of the awsxrayreceiver,
+// so that replayed traces match the ones received live.
+type xrayTracesUnmarshaler struct{}
+
+// xrayBatchGetTracesOutput is the output of the BatchGetTraces API.
+type xrayBatchGetTracesOutput struct {
+	Traces []struct {
+		Segments []struct {
+			Document string `json:"Document"`
+		} `json:"Segments"`
+	} `json:"Traces"`
+}
+
+// xrayHeader is the header preceding the segment documents of the X-Ray daemon
+// protocol.
+type xrayHeader struct {
+	Format  string `json:"format"`
+	Version int    `json:"version"`
+}
+
+func (xrayTracesUnmarshaler) UnmarshalTraces(buf []byte) (ptrace.Traces, error) {
+	traces := ptrace.NewTraces()
+	var output xrayBatchGetTracesOutput
+	if bytes.HasPrefix(bytes.TrimSpace(buf), []byte(`{"Traces"`)) {
+		if err := json.Unmarshal(buf, &output); err != nil {
+			return traces, fmt.Errorf("unable to parse X-Ray traces: %w", err)
+		}
+		for _, trace := range output.Traces {
+			for _, segment := range trace.Segments {
+				if err := appendXRaySegment(traces, []byte(segment.Document)); err != nil {
+					return traces, err
+				}
+			}
+		}
+		return traces, nil
+	}
+	err := forEachJSONMessage(buf, func(message []byte) error {
+		var header xrayHeader
+		if err := json.Unmarshal(message, &header); err == nil && header.Format == "json" && header.Version == 1 {
+			return nil
+		}
+		return appendXRaySegment(traces, message)
+	})
+	return traces, err
+}
+
+// appendXRaySegment translates a segment document, with its embedded subsegments,
+// into a resource of traces.
+func appendXRaySegment(traces ptrace.Traces, document []byte) error {
+	var seg awsxray.Segment
+	if err := json.Unmarshal(document, &seg); err != nil {
+		return fmt.Errorf("unable to parse X-Ray segment: %w", err)
+	}
+	if err := seg.Validate(); err != nil {
+		return fmt.Errorf("invalid X-Ray segment: %w", err)
+	}
+	resourceSpans := ptrace.NewResourceSpans()
+	populateXRayResource(&seg, resourceSpans.Resource())
+	// Embedded subsegments have no trace ID, the one of the segment is used for
+	// the spans of its whole tree.
+	if _, err := xraySegmentToSpans(seg, seg.TraceID, nil, resourceSpans.ScopeSpans().AppendEmpty().Spans()); err != nil {
+		return fmt.Errorf("invalid X-Ray segment: %w", err)
+	}
+	resourceSpans.MoveTo(traces.ResourceSpans().AppendEmpty())
+	return nil
+}
+
+func xraySegmentToSpans(seg awsxray.Segment, traceID, parentID *string, spans ptrace.SpanSlice) (ptrace.Span, error) {
+	span := spans.AppendEmpty()
+	if err := populateXRaySpan(&seg, traceID, parentID, span); err != nil {
+		return ptrace.Span{}, err
+	}
+	for _, subsegment := range seg.Subsegments {
+		child, err := xraySegmentToSpans(subsegment, traceID, seg.ID, spans)
+		if err != nil {
+			return ptrace.Span{}, err
+		}
+		// A segment with a cause has the error status set by addXRayCause, which
+		// is refined with the status of its subsegments.
+		if seg.Cause != nil && child.Status().Code() != ptrace.StatusCodeUnset &&
+			span.Status().Code() == ptrace.StatusCodeError {
+			span.Status().SetCode(child.Status().Code())
+		}
+	}
+	return span, nil
+}
+
+func populateXRaySpan(seg *awsxray.Segment, traceID, parentID *string, span ptrace.Span) error {
+	if err := addXRayNameAndNamespace(seg, span); err != nil {
+		return err
+	}
+	if seg.TraceID != nil {
+		traceID = seg.TraceID
+	}
+	traceIDBytes, err := decodeXRayTraceID(traceID)
+	if err != nil {
+		return err
+	}
+	if parentID == nil {
+		parentID = seg.ParentID
+	}
+	var parentIDBytes [8]byte
+	if parentID != nil {
+		if parentIDBytes, err = decodeXRaySpanID(parentID); err != nil {
+			return err
+		}
+	}
+	spanIDBytes, err := decodeXRaySpanID(seg.ID)
+	if err != nil {
+		return err
+	}
+	span.SetTraceID(traceIDBytes)
+	span.SetSpanID(spanIDBytes)
+	if parentIDBytes != [8]byte{} {
+		span.SetParentSpanID(parentIDBytes)
+	} else {
+		span.SetKind(ptrace.SpanKindServer)
+	}
+
+	span.SetStartTimestamp(xrayTimestamp(*seg.StartTime))
+	if seg.EndTime != nil {
+		span.SetEndTimestamp(xrayTimestamp(*seg.EndTime))
+	}
+	attrs := span.Attributes()
+	putXRayBool(attrs, awsxray.AWSXRayInProgressAttribute, seg.InProgress)
+	putXRayString(attrs, conventions.AttributeEnduserID, seg.User)
+	addXRayHTTP(seg, span)
+	addXRayCause(seg, span)
+	if aws := seg.AWS; aws != nil {
+		putXRayString(attrs, awsxray.AWSAccountAttribute, aws.AccountID)
+		putXRayString(attrs, awsxray.AWSOperationAttribute, aws.Operation)
+		putXRayString(attrs, awsxray.AWSRegionAttribute, aws.RemoteRegion)
+		putXRayString(attrs, awsxray.AWSRequestIDAttribute, aws.RequestID)
+		putXRayString(attrs, awsxray.AWSQueueURLAttribute, aws.QueueURL)
+		putXRayString(attrs, awsxray.AWSTableNameAttribute, aws.TableName)
+		putXRayInt(attrs, awsxray.AWSXrayRetriesAttribute, aws.Retries)
+	}
+	if err := addXRaySQL(seg.SQL, attrs); err != nil {
+		return err
+	}
+	putXRayBool(attrs, awsxray.AWSXRayTracedAttribute, seg.Traced)
+	addXRayAnnotations(seg.Annotations, attrs)
+	for namespace, metadata := range seg.Metadata {
+		value, err := json.Marshal(metadata)
+		if err != nil {
+			return err
+		}
+		attrs.PutStr(awsxray.AWSXraySegmentMetadataAttributePrefix+namespace, string(value))
+	}
+	return nil
+}
+
+func populateXRayResource(seg *awsxray.Segment, resource pcommon.Resource) {
+	attrs := resource.Attributes()
+	putXRayString(attrs, conventions.AttributeServiceName, seg.Name)
+	if aws := seg.AWS; aws == nil {
+		// The segment was not generated by an AWS entity.
+		attrs.PutStr(conventions.AttributeCloudProvider, "unknown")
+	} else {
+		attrs.PutStr(conventions.AttributeCloudProvider, conventions.AttributeCloudProviderAWS)
+		putXRayString(attrs, conventions.AttributeCloudAccountID, aws.AccountID)
+		for _, logGroup := range aws.CWLogs {
+			appendXRayString(attrs, conventions.AttributeAWSLogGroupARNs, logGroup.Arn)
+			appendXRayString(attrs, conventions.AttributeAWSLogGroupNames, logGroup.LogGroup)
+		}
+		if ec2 := aws.EC2; ec2 != nil {
+			putXRayString(attrs, conventions.AttributeCloudAvailabilityZone, ec2.AvailabilityZone)
+			putXRayString(attrs, conventions.AttributeHostID, ec2.InstanceID)
+			putXRayString(attrs, conventions.AttributeHostType, ec2.InstanceSize)
+			putXRayString(attrs, conventions.AttributeHostImageID, ec2.AmiID)
+		}
+		if ecs := aws.ECS; ecs != nil {
+			putXRayString(attrs, conventions.AttributeContainerName, ecs.ContainerName)
+			putXRayString(attrs, conventions.AttributeCloudAvailabilityZone, ecs.AvailabilityZone)
+			putXRayString(attrs, conventions.AttributeContainerID, ecs.ContainerID)
+		}
+		if beanstalk := aws.Beanstalk; beanstalk != nil {
+			putXRayString(attrs, conventions.AttributeServiceNamespace, beanstalk.Environment)
+			if beanstalk.DeploymentID != nil {
+				attrs.PutStr(conventions.AttributeServiceInstanceID, strconv.FormatInt(*beanstalk.DeploymentID, 10))
+			}
+			putXRayString(attrs, conventions.AttributeServiceVersion, beanstalk.VersionLabel)
+		}
+		if eks := aws.EKS; eks != nil {
+			putXRayString(attrs, conventions.AttributeContainerID, eks.ContainerID)
+			putXRayString(attrs, conventions.AttributeK8SClusterName, eks.ClusterName)
+			putXRayString(attrs, conventions.AttributeK8SPodName, eks.Pod)
+		}
+		if xr := aws.XRay; xr != nil {
+			putXRayString(attrs, conventions.AttributeTelemetrySDKVersion, xr.SDKVersion)
+			if xr.SDK != nil {
+				attrs.PutStr(conventions.AttributeTelemetrySDKName, *xr.SDK)
+				// The X-Ray exporter only supports Java stack traces.
+				if seg.Cause != nil && len(seg.Cause.Exceptions) > 0 {
+					attrs.PutStr(conventions.AttributeTelemetrySDKLanguage, "java")
+				} else if _, language, ok := strings.Cut(*xr.SDK, "for "); ok {
+					attrs.PutStr(conventions.AttributeTelemetrySDKLanguage, language)
+				}
+			}
+		}
+	}
+	if seg.Service != nil {
+		putXRayString(attrs, conventions.AttributeServiceVersion, seg.Service.Version)
+	}
+	putXRayString(attrs, awsxray.AWSXRayResourceARNAttribute, seg.ResourceARN)
+}
+
+func addXRayNameAndNamespace(seg *awsxray.Segment, span ptrace.Span) error {
+	span.SetName(*seg.Name)
+	// A client IP means that the segment was generated by a server serving an
+	// incoming request.
+	if seg.HTTP != nil && seg.HTTP.Request != nil && seg.HTTP.Request.ClientIP != nil {
+		span.SetKind(ptrace.SpanKindServer)
+	}
+	if seg.Namespace == nil || *seg.Namespace == "local" {
+		if span.Kind() == ptrace.SpanKindUnspecified {
+			span.SetKind(ptrace.SpanKindInternal)
+		}
+		return nil
+	}
+	span.SetKind(ptrace.SpanKindClient)
+	switch *seg.Namespace {
+	case "aws":
+		span.Attributes().PutStr(awsxray.AWSServiceAttribute, *seg.Name)
+	case "remote":
+	default:
+		return fmt.Errorf("unexpected namespace: %s", *seg.Namespace)
+	}
+	return nil
+}
+
+func addXRayHTTP(seg *awsxray.Segment, span ptrace.Span) {
+	if seg.HTTP == nil {
+		return
+	}
+	attrs := span.Attributes()
+	if req := seg.HTTP.Request; req != nil {
+		putXRayString(attrs, conventions.AttributeHTTPMethod, req.Method)
+		putXRayString(attrs, conventions.AttributeHTTPClientIP, req.ClientIP)
+		putXRayString(attrs, conventions.AttributeHTTPUserAgent, req.UserAgent)
+		putXRayString(attrs, conventions.AttributeHTTPURL, req.URL)
+		putXRayBool(attrs, awsxray.AWSXRayXForwardedForAttribute, req.XForwardedFor)
+	}
+	if resp := seg.HTTP.Response; resp != nil {
+		if resp.Status != nil {
+			if *resp.Status < 100 || *resp.Status >= 400 {
+				span.Status().SetCode(ptrace.StatusCodeError)
+			}
+			attrs.PutInt(conventions.AttributeHTTPStatusCode, *resp.Status)
+		}
+		switch length := resp.ContentLength.(type) {
+		case string:
+			attrs.PutStr(conventions.AttributeHTTPResponseContentLength, length)
+		case float64:
+			attrs.PutInt(conventions.AttributeHTTPResponseContentLength, int64(length))
+		}
+	}
+}
+
+func addXRayCause(seg *awsxray.Segment, span ptrace.Span) {
+	if seg.Cause == nil {
+		return
+	}
+	// The HTTP status of a segment with a cause may only be set on one of its
+	// subsegments, the status is refined once they are translated.
+	if span.Status().Code() == ptrace.StatusCodeUnset {
+		span.Status().SetCode(ptrace.StatusCodeError)
+	}
+	switch seg.Cause.Type {
+	case awsxray.CauseTypeExceptionID:
+		span.Status().SetMessage(*seg.Cause.ExceptionID)
+	case awsxray.CauseTypeObject:
+		for _, exception := range seg.Cause.Exceptions {
+			event := span.Events().AppendEmpty()
+			event.SetName("exception")
+			attrs := event.Attributes()
+			attrs.PutStr(awsxray.AWSXrayExceptionIDAttribute, *exception.ID)
+			putXRayString(attrs, conventions.AttributeExceptionMessage, exception.Message)
+			putXRayString(attrs, conventions.AttributeExceptionType, exception.Type)
+			putXRayBool(attrs, awsxray.AWSXrayExceptionRemoteAttribute, exception.Remote)
+			putXRayInt(attrs, awsxray.AWSXrayExceptionTruncatedAttribute, exception.Truncated)
+			putXRayInt(attrs, awsxray.AWSXrayExceptionSkippedAttribute, exception.Skipped)
+			putXRayString(attrs, awsxray.AWSXrayExceptionCauseAttribute, exception.Cause)
+			if len(exception.Stack) > 0 {
+				attrs.PutStr(conventions.AttributeExceptionStacktrace, xrayStackTrace(exception))
+			}
+		}
+	}
+}
+
+// xrayStackTrace returns the Java like stack trace of an exception.
+func xrayStackTrace(exception awsxray.Exception) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s: %s\n", awsxray.StringOrEmpty(exception.Type), awsxray.StringOrEmpty(exception.Message))
+	for _, frame := range exception.Stack {
+		line := "<unknown>"
+		if frame.Line != nil {
+			line = strconv.Itoa(*frame.Line)
+		}
+		fmt.Fprintf(&b, "\tat %s(%s: %s)\n", awsxray.StringOrEmpty(frame.Label), awsxray.StringOrEmpty(frame.Path), line)
+	}
+	return b.String()
+}
+
+// xraySQLURLPattern matches the SQL URLs, protocol+transport://host:port/dbName?queryParam.
+var xraySQLURLPattern = regexp.MustCompile(`^(.+\/\/.+)\/([^\?]+)\??.*$`)
+
+func addXRaySQL(sql *awsxray.SQLData, attrs pcommon.Map) error {
+	if sql == nil {
+		return nil
+	}
+	if sql.URL != nil {
+		m := xraySQLURLPattern.FindStringSubmatch(*sql.URL)
+		if m == nil {
+			return fmt.Errorf("failed to parse out the database name in the \"sql.url\" field, rawUrl: %s", *sql.URL)
+		}
+		attrs.PutStr(conventions.AttributeDBConnectionString, m[1])
+		attrs.PutStr(conventions.AttributeDBName, m[2])
+	}
+	putXRayString(attrs, conventions.AttributeDBSystem, sql.DatabaseType)
+	putXRayString(attrs, conventions.AttributeDBStatement, sql.SanitizedQuery)
+	putXRayString(attrs, conventions.AttributeDBUser, sql.User)
+	return nil
+}
+
+func addXRayAnnotations(annotations map[string]any, attrs pcommon.Map) {
+	if len(annotations) == 0 {
+		return
+	}
+	keys := attrs.PutEmptySlice(awsxray.AWSXraySegmentAnnotationsAttribute)
+	for key, value := range annotations {
+		keys.AppendEmpty().SetStr(key)
+		switch v := value.(type) {
+		case string:
+			attrs.PutStr(key, v)
+		case bool:
+			attrs.PutBool(key, v)
+		case float64:
+			attrs.PutDouble(key, v)
+		}
+	}
+}
+
+// decodeXRayTraceID decodes an X-Ray trace ID, such as 1-5f84c7a1-e7d1852db8c4fd35d88bf49a,
+// from the hexadecimal time and identifier parts.
+func decodeXRayTraceID(traceID *string) ([16]byte, error) {
+	var tid [16]byte
+	if traceID == nil {
+		return tid, errors.New("traceID is null")
+	}
+	if len(*traceID) < 35 {
+		return tid, errors.New("traceID length is wrong")
+	}
+	_, err := hex.Decode(tid[:], []byte((*traceID)[2:10]+(*traceID)[11:]))
+	return tid, err
+}
+
+func decodeXRaySpanID(spanID *string) ([8]byte, error) {
+	var sid [8]byte
+	if spanID == nil {
+		return sid, errors.New("spanid is null")
+	}
+	if len(*spanID) != 16 {
+		return sid, errors.New("spanID length is wrong")
+	}
+	_, err := hex.Decode(sid[:], []byte(*spanID))
+	return sid, err
+}
+
+// xrayTimestamp returns the timestamp of X-Ray times, in seconds since the epoch.
+func xrayTimestamp(seconds float64) pcommon.Timestamp {
+	return pcommon.Timestamp(seconds * float64(time.Second))
+}
+
+func putXRayString(attrs pcommon.Map, attr string, value *string) {
+	if value != nil {
+		attrs.PutStr(attr, *value)
+	}
+}
+
+func putXRayBool(attrs pcommon.Map, attr string, value *bool) {
+	if value != nil {
+		attrs.PutBool(attr, *value)
+	}
+}
+
+func putXRayInt(attrs pcommon.Map, attr string, value *int64) {
+	if value != nil {
+		attrs.PutInt(attr, *value)
+	}
+}
+
+// appendXRayString appends a value to the slice of an attribute.
+func appendXRayString(attrs pcommon.Map, attr string, value *string) {
+	if value == nil {
+		return
+	}
+	var slice pcommon.Slice
+	if existing, ok := attrs.Get(attr); ok && existing.Type() == pcommon.ValueTypeSlice {
+		slice = existing.Slice()
+	} else {
+		slice = attrs.PutEmptySlice(attr)
+	}
+	slice.AppendEmpty().SetStr(*value)
+}
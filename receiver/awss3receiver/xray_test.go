@@ -0,0 +1,122 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+)
+
+const testXRaySegment = `{
+  "trace_id": "1-5f84c7a1-e7d1852db8c4fd35d88bf49a",
+  "id": "defdfd9912dc5a56",
+  "name": "checkout",
+  "start_time": 1612198800.5,
+  "end_time": 1612198801.0,
+  "user": "alice",
+  "http": {
+    "request": {"method": "POST", "url": "https://example.com/checkout", "client_ip": "192.0.2.10"},
+    "response": {"status": 500, "content_length": 42}
+  },
+  "aws": {"account_id": "123456789012", "ec2": {"instance_id": "i-0123456789abcdef0"}},
+  "annotations": {"order": "1234"},
+  "cause": {"exceptions": [{"id": "3e9e11e3ab3fba60", "message": "failed", "type": "Error"}]},
+  "subsegments": [
+    {
+      "id": "53995c3f42cd8ad8",
+      "name": "DynamoDB",
+      "namespace": "aws",
+      "start_time": 1612198800.6,
+      "end_time": 1612198800.9,
+      "aws": {"operation": "PutItem", "table_name": "orders", "request_id": "UBQNSO5AEM8T4FDA4RQDEB94OVTDRVV4K4HIRGVJF66Q9ASUAAJG"},
+      "http": {"response": {"status": 400}}
+    }
+  ]
+}`
+
+func Test_xrayTracesUnmarshaler(t *testing.T) {
+	compact := func(document string) string {
+		var segment map[string]any
+		require.NoError(t, json.Unmarshal([]byte(document), &segment))
+		data, err := json.Marshal(segment)
+		require.NoError(t, err)
+		return string(data)
+	}
+
+	traces, err := xrayTracesUnmarshaler{}.UnmarshalTraces([]byte(`{"format": "json", "version": 1}` + "\n" + compact(testXRaySegment) + "\n"))
+	require.NoError(t, err)
+	require.Equal(t, 1, traces.ResourceSpans().Len())
+	resourceSpans := traces.ResourceSpans().At(0)
+	require.Equal(t, map[string]any{
+		"service.name":     "checkout",
+		"cloud.provider":   "aws",
+		"cloud.account.id": "123456789012",
+		"host.id":          "i-0123456789abcdef0",
+	}, resourceSpans.Resource().Attributes().AsRaw())
+
+	spans := resourceSpans.ScopeSpans().At(0).Spans()
+	require.Equal(t, 2, spans.Len())
+	segment := spans.At(0)
+	require.Equal(t, "checkout", segment.Name())
+	require.Equal(t, ptrace.SpanKindServer, segment.Kind())
+	require.Equal(t, pcommon.TraceID([16]byte{0x5f, 0x84, 0xc7, 0xa1, 0xe7, 0xd1, 0x85, 0x2d, 0xb8, 0xc4, 0xfd, 0x35, 0xd8, 0x8b, 0xf4, 0x9a}), segment.TraceID())
+	require.Equal(t, pcommon.SpanID([8]byte{0xde, 0xfd, 0xfd, 0x99, 0x12, 0xdc, 0x5a, 0x56}), segment.SpanID())
+	require.True(t, segment.ParentSpanID().IsEmpty())
+	require.Equal(t, time.Date(2021, 2, 1, 17, 0, 0, 500000000, time.UTC), segment.StartTimestamp().AsTime())
+	require.Equal(t, time.Date(2021, 2, 1, 17, 0, 1, 0, time.UTC), segment.EndTimestamp().AsTime())
+	require.Equal(t, ptrace.StatusCodeError, segment.Status().Code())
+	require.Equal(t, map[string]any{
+		"enduser.id":                   "alice",
+		"http.method":                  "POST",
+		"http.url":                     "https://example.com/checkout",
+		"http.client_ip":               "192.0.2.10",
+		"http.status_code":             int64(500),
+		"http.response_content_length": int64(42),
+		"aws.account_id":               "123456789012",
+		"aws.xray.annotations":         []any{"order"},
+		"order":                        "1234",
+	}, segment.Attributes().AsRaw())
+	require.Equal(t, 1, segment.Events().Len())
+	require.Equal(t, "exception", segment.Events().At(0).Name())
+	require.Equal(t, map[string]any{
+		"aws.xray.exception.id": "3e9e11e3ab3fba60",
+		"exception.message":     "failed",
+		"exception.type":        "Error",
+	}, segment.Events().At(0).Attributes().AsRaw())
+
+	subsegment := spans.At(1)
+	require.Equal(t, segment.TraceID(), subsegment.TraceID())
+	require.Equal(t, segment.SpanID(), subsegment.ParentSpanID())
+	require.Equal(t, ptrace.SpanKindClient, subsegment.Kind())
+	require.Equal(t, ptrace.StatusCodeError, subsegment.Status().Code())
+	require.Equal(t, map[string]any{
+		"aws.service":      "DynamoDB",
+		"aws.operation":    "PutItem",
+		"aws.table_name":   "orders",
+		"aws.request_id":   "UBQNSO5AEM8T4FDA4RQDEB94OVTDRVV4K4HIRGVJF66Q9ASUAAJG",
+		"http.status_code": int64(400),
+	}, subsegment.Attributes().AsRaw())
+
+	// The output of BatchGetTraces holds the documents of the segments as strings.
+	output, err := json.Marshal(map[string]any{
+		"Traces": []any{map[string]any{
+			"Id":       "1-5f84c7a1-e7d1852db8c4fd35d88bf49a",
+			"Segments": []any{map[string]any{"Id": "defdfd9912dc5a56", "Document": compact(testXRaySegment)}},
+		}},
+	})
+	require.NoError(t, err)
+	traces, err = xrayTracesUnmarshaler{}.UnmarshalTraces(output)
+	require.NoError(t, err)
+	require.Equal(t, 2, traces.SpanCount())
+
+	_, err = xrayTracesUnmarshaler{}.UnmarshalTraces([]byte(`{"id": "defdfd9912dc5a56", "name": "checkout"}`))
+	require.ErrorContains(t, err, "invalid X-Ray segment")
+	_, err = xrayTracesUnmarshaler{}.UnmarshalTraces([]byte(`{"Traces": [`))
+	require.ErrorContains(t, err, "unable to parse X-Ray traces")
+}
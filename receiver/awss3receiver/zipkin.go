@@ -0,0 +1,28 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"go.opentelemetry.io/collector/pdata/ptrace"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/translator/zipkin/zipkinv2"
+)
+
+// zipkinJSONTracesUnmarshaler unmarshals one or several newline delimited Zipkin
+// v2 JSON lists of spans.
+type zipkinJSONTracesUnmarshaler struct{}
+
+func (zipkinJSONTracesUnmarshaler) UnmarshalTraces(buf []byte) (ptrace.Traces, error) {
+	traces := ptrace.NewTraces()
+	unmarshaler := zipkinv2.NewJSONTracesUnmarshaler(false)
+	err := forEachJSONMessage(buf, func(message []byte) error {
+		messageTraces, err := unmarshaler.UnmarshalTraces(message)
+		if err != nil {
+			return err
+		}
+		messageTraces.ResourceSpans().MoveAndAppendTo(traces.ResourceSpans())
+		return nil
+	})
+	return traces, err
+}
@@ -0,0 +1,32 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3receiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/awss3receiver"
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_zipkinJSONTracesUnmarshaler(t *testing.T) {
+	data := []byte(`[{"traceId": "5f84c7a1e7d1852db8c4fd35d88bf49a", "id": "defdfd9912dc5a56", "name": "get /cart", "kind": "SERVER", "timestamp": 1612198800000000, "duration": 1000000, "localEndpoint": {"serviceName": "checkout"}}]
+[{"traceId": "5f84c7a1e7d1852db8c4fd35d88bf49a", "parentId": "defdfd9912dc5a56", "id": "53995c3f42cd8ad8", "name": "select", "kind": "CLIENT", "timestamp": 1612198800200000, "duration": 500000, "localEndpoint": {"serviceName": "orders"}}]
+`)
+	traces, err := zipkinJSONTracesUnmarshaler{}.UnmarshalTraces(data)
+	require.NoError(t, err)
+	require.Equal(t, 2, traces.ResourceSpans().Len())
+	checkout := traces.ResourceSpans().At(0)
+	require.Equal(t, "checkout", checkout.Resource().Attributes().AsRaw()["service.name"])
+	span := checkout.ScopeSpans().At(0).Spans().At(0)
+	require.Equal(t, "get /cart", span.Name())
+	require.Equal(t, time.Date(2021, 2, 1, 17, 0, 0, 0, time.UTC), span.StartTimestamp().AsTime())
+	require.Equal(t, time.Date(2021, 2, 1, 17, 0, 1, 0, time.UTC), span.EndTimestamp().AsTime())
+	orders := traces.ResourceSpans().At(1)
+	require.Equal(t, "orders", orders.Resource().Attributes().AsRaw()["service.name"])
+	require.Equal(t, span.SpanID(), orders.ScopeSpans().At(0).Spans().At(0).ParentSpanID())
+
+	_, err = zipkinJSONTracesUnmarshaler{}.UnmarshalTraces([]byte(`[{"traceId": 1}]`))
+	require.ErrorContains(t, err, "unable to unmarshal JSON message 0")
+}